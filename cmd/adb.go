@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/metrics"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/parfenovvs/loglion/internal/source"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var adbCmd = &cobra.Command{
+	Use:   "adb [event_patterns...]",
+	Short: "Analyze live logcat output from a connected Android device",
+	Long: `Adb command streams "adb logcat" from a connected device and analyzes it live,
+without capturing logcat to a file first. With --funnel-config it runs funnel analysis,
+otherwise the given event patterns are counted.
+
+Results are printed when capture stops: on Ctrl+C, when adb exits, or after --duration.
+Use --interval to also print intermediate results while capturing, and --metrics-addr
+to expose current counts and conversions at /metrics in Prometheus format, so a long
+running capture can be scraped during a soak test.
+
+Every update analyzes the captured entries again, so only the latest --max-entries
+entries (1,000,000) are kept, with a warning once older ones are dropped; --window
+also drops entries captured longer ago than the window. This bounds the memory and
+the cost of every update of a long capture.
+
+Examples:
+  loglion adb --parser-config logcat-parser.yaml --funnel-config funnel.yaml
+  loglion adb -p logcat-parser.yaml -f funnel.yaml --device emulator-5554 --tag Analytics
+  loglion adb -p logcat-parser.yaml --duration 2m --interval 10s "login" "purchase"
+  loglion adb -p logcat-parser.yaml -f funnel.yaml --metrics-addr :9090`,
+	Run: func(cmd *cobra.Command, args []string) {
+		parserConfigFile, _ := cmd.Flags().GetString("parser-config")
+		funnelConfigFile, _ := cmd.Flags().GetString("funnel-config")
+		device, _ := cmd.Flags().GetString("device")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		duration, _ := cmd.Flags().GetDuration("duration")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		metricsAddr, _ := cmd.Flags().GetString("metrics-addr")
+		metricsInterval, _ := cmd.Flags().GetDuration("metrics-interval")
+		maxEntries, _ := cmd.Flags().GetInt("max-entries")
+		window, _ := cmd.Flags().GetDuration("window")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		outputOptions, err := outputOptionsFromFlags(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		maxLineBytes, _ := cmd.Flags().GetInt("max-line-bytes")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+
+		logrus.WithFields(logrus.Fields{
+			"parser_config_file": parserConfigFile,
+			"funnel_config_file": funnelConfigFile,
+			"device":             device,
+			"tags":               tags,
+			"duration":           duration,
+			"interval":           interval,
+			"metrics_addr":       metricsAddr,
+			"output_format":      outputFormat,
+			"event_patterns":     args,
+		}).Info("Starting live adb analysis")
+
+		if funnelConfigFile == "" && len(args) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: Either --funnel-config or at least one event pattern must be specified.\n")
+			os.Exit(1)
+		}
+		if maxLineBytes < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --max-line-bytes must be at least 1, got %d\n", maxLineBytes)
+			os.Exit(1)
+		}
+
+		if metricsAddr != "" && metricsInterval <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --metrics-interval must be positive.\n")
+			os.Exit(1)
+		}
+		if maxEntries < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --max-entries must be at least 1, got %d\n", maxEntries)
+			os.Exit(1)
+		}
+		if window < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --window cannot be negative, got %s\n", window)
+			os.Exit(1)
+		}
+
+		// Load parser configuration
+		logrus.Debug("Loading parser configuration file")
+		parserCfg, err := config.LoadParserConfig(parserConfigFile)
+		if err != nil {
+			logrus.WithError(err).WithField("parser_config_file", parserConfigFile).Error("Failed to load parser config")
+			fmt.Fprintf(os.Stderr, "Error loading parser config: %v\n", err)
+			os.Exit(1)
+		}
+
+		entryFilter, err := entryFilterFromFlags(cmd, parserCfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Prepare the analysis to run over captured entries
+		var analyze func(captured *capturedEntries) *metrics.Snapshot
+		formatter := newOutputFormatter(outputFormat, outputOptions)
+		if funnelConfigFile != "" {
+			logrus.Debug("Loading funnel configuration file")
+			funnelCfgs, err := config.LoadFunnelConfigs(funnelConfigFile)
+			if err != nil {
+				logrus.WithError(err).WithField("funnel_config_file", funnelConfigFile).Error("Failed to load funnel config")
+				fmt.Fprintf(os.Stderr, "Error loading funnel config: %v\n", err)
+				os.Exit(1)
+			}
+
+			analyze = func(captured *capturedEntries) *metrics.Snapshot {
+				return &metrics.Snapshot{
+					EntriesParsed: captured.total,
+					Funnels:       analyzeFunnels(context.Background(), funnelCfgs, captured.entries, analyzer.FunnelOptions{Limit: limit}, nil),
+					UpdatedAt:     time.Now(),
+				}
+			}
+		} else {
+			countAnalyzer, err := analyzer.NewCountAnalyzerWithOptions(args, analyzer.CountOptions{IgnoreCase: ignoreCase})
+			if err != nil {
+				logrus.WithError(err).Error("Failed to create count analyzer")
+				fmt.Fprintf(os.Stderr, "Error creating count analyzer: %v\n", err)
+				os.Exit(1)
+			}
+
+			analyze = func(captured *capturedEntries) *metrics.Snapshot {
+				return &metrics.Snapshot{
+					EntriesParsed: captured.total,
+					Count:         countAnalyzer.AnalyzeCount(captured.entries),
+					UpdatedAt:     time.Now(),
+				}
+			}
+		}
+
+		logParser, err := parserCfg.NewParser()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating log parser: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Stop capturing on Ctrl+C or when the requested duration elapses
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if duration > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, duration)
+			defer cancel()
+		}
+
+		captured := &capturedEntries{maxEntries: maxEntries, window: window}
+
+		// Serve live metrics while capturing
+		var exporter *metrics.Exporter
+		var metricsTicker <-chan time.Time
+		if metricsAddr != "" {
+			var closeMetrics func()
+			exporter, closeMetrics, err = serveMetrics(metricsAddr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error starting metrics server: %v\n", err)
+				os.Exit(1)
+			}
+			defer closeMetrics()
+			exporter.Update(analyze(captured))
+
+			t := time.NewTicker(metricsInterval)
+			defer t.Stop()
+			metricsTicker = t.C
+		}
+
+		adb := source.NewADBSource(source.ADBOptions{Serial: device, Tags: tags})
+		stdout, err := adb.Start(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting adb logcat: %v\n", err)
+			os.Exit(1)
+		}
+
+		lines := make(chan string)
+		go func() {
+			defer close(lines)
+			reader := parser.NewLineReader(stdout, maxLineBytes)
+			for {
+				line, oversized, err := reader.ReadLine()
+				if err != nil {
+					if err != io.EOF {
+						logrus.WithError(err).Error("Failed to read adb logcat output")
+					}
+					return
+				}
+				if oversized {
+					fmt.Fprintf(os.Stderr, "Warning: skipped a logcat line longer than %d bytes (use --max-line-bytes to raise the limit)\n", maxLineBytes)
+					continue
+				}
+				lines <- line
+			}
+		}()
+
+		var ticker <-chan time.Time
+		if interval > 0 {
+			t := time.NewTicker(interval)
+			defer t.Stop()
+			ticker = t.C
+		}
+
+		fmt.Fprintf(os.Stderr, "Capturing adb logcat, press Ctrl+C to stop...\n")
+
+		// The capture count of the last metrics update, which is skipped when
+		// nothing was captured since
+		metricsTotal := 0
+	capture:
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					logrus.Info("adb logcat output ended")
+					break capture
+				}
+				entry, err := logParser.Parse(line)
+				if err != nil {
+					logrus.WithError(err).WithField("line", line).Debug("Failed to parse logcat line, skipping")
+					continue
+				}
+				if !entryFilter.Matches(entry) {
+					continue
+				}
+				if captured.add(entry, time.Now()) {
+					fmt.Fprintf(os.Stderr, "Warning: dropping the oldest captured entries, results cover the latest %d (use --max-entries or --window to change this)\n", maxEntries)
+				}
+			case <-metricsTicker:
+				captured.expire(time.Now())
+				if captured.total == metricsTotal && window == 0 {
+					continue
+				}
+				metricsTotal = captured.total
+				logrus.WithField("entry_count", len(captured.entries)).Debug("Updating metrics snapshot")
+				exporter.Update(analyze(captured))
+			case <-ticker:
+				captured.expire(time.Now())
+				logrus.WithField("entry_count", len(captured.entries)).Debug("Printing intermediate results")
+				formattedOutput, err := formatSnapshot(formatter, analyze(captured))
+				if err != nil {
+					logrus.WithError(err).Error("Failed to format intermediate output")
+					continue
+				}
+				fmt.Print(formattedOutput)
+				fmt.Println()
+			case <-ctx.Done():
+				logrus.Info("Stopping adb capture")
+				break capture
+			}
+		}
+
+		// Drain remaining output so the reader goroutine can finish
+		go func() {
+			for range lines {
+			}
+		}()
+		if err := adb.Wait(); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Warn("adb logcat exited with error")
+			fmt.Fprintf(os.Stderr, "Warning: adb logcat exited: %v\n", err)
+		}
+
+		captured.expire(time.Now())
+		logrus.WithField("entry_count", len(captured.entries)).Debug("Formatting final results")
+		snapshot := analyze(captured)
+		if exporter != nil {
+			exporter.Update(snapshot)
+		}
+		formattedOutput, err := formatSnapshot(formatter, snapshot)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format analysis output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Live analysis completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adbCmd)
+
+	adbCmd.Flags().StringP("parser-config", "p", "", "Path to parser configuration file (required)")
+	adbCmd.Flags().StringP("funnel-config", "f", "", "Path to funnel configuration file (enables funnel analysis)")
+	addConfigFlag(adbCmd)
+	adbCmd.Flags().StringP("device", "s", "", "Serial of the device to capture from (see 'adb devices')")
+	adbCmd.Flags().StringSlice("tag", nil, "Only capture log lines with these tags (repeatable)")
+	adbCmd.Flags().String("level", "", "Only analyze entries with this log level or more severe (V, D, I, W, E, F)")
+	adbCmd.Flags().StringSlice("exclude-tag", nil, "Skip entries with these tags (repeatable)")
+	adbCmd.Flags().IntSlice("pid", nil, "Only analyze entries from these process IDs (repeatable)")
+	adbCmd.Flags().Duration("duration", 0, "Stop capturing after this duration (0 = until interrupted)")
+	adbCmd.Flags().Int("max-line-bytes", parser.DefaultMaxLineBytes, "Skip log lines longer than this many bytes, with a warning")
+	adbCmd.Flags().Duration("interval", 0, "Print intermediate results at this interval (0 = only final results)")
+	adbCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics at /metrics on this address (e.g. :9090)")
+	adbCmd.Flags().Duration("metrics-interval", 5*time.Second, "How often to refresh the metrics served at /metrics")
+	adbCmd.Flags().Int("max-entries", defaultMaxCapturedEntries, "Keep and analyze only the latest this many captured entries")
+	adbCmd.Flags().Duration("window", 0, "Keep and analyze only entries captured within this duration (0 = no time limit)")
+	adbCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	adbCmd.Flags().StringSlice("only", nil, "Only output these sections (summary, steps, chart, drop_offs, anomalies, exclusions, timings, assertions, counts, overlaps, groups, sessions, distinct, aggregate, conversions)")
+	adbCmd.Flags().StringSlice("hide", nil, "Hide these sections, or 'zero-count' to hide steps and patterns without matches")
+	adbCmd.Flags().Int("max-name-width", 0, "Truncate step and pattern names longer than this in text output (0 = no limit)")
+	adbCmd.Flags().Bool("table", false, "Render step and pattern breakdowns as tables with aligned columns in text output")
+	adbCmd.Flags().Bool("borders", false, "Frame text output tables with unicode borders (implies --table)")
+	adbCmd.Flags().Bool("wide", false, "Add first and last match times and the median time to each step to text output tables (implies --table)")
+	adbCmd.Flags().Int("limit", 0, "Maximum number of successful funnels to analyze (0 = analyze all funnels)")
+	adbCmd.Flags().BoolP("ignore-case", "i", false, "Match count patterns regardless of case")
+
+	adbCmd.MarkFlagRequired("parser-config")
+}
+
+// formatSnapshot formats the results of a live analysis snapshot
+func formatSnapshot(formatter output.Formatter, snapshot *metrics.Snapshot) (string, error) {
+	if snapshot.Count != nil {
+		return formatter.FormatCount(snapshot.Count)
+	}
+	return formatFunnels(formatter, snapshot.Funnels)
+}
+
+// defaultMaxCapturedEntries is the number of captured entries kept by default
+const defaultMaxCapturedEntries = 1_000_000
+
+// capturedEntries holds the latest entries of a live capture. Every update
+// analyzes them again, so older entries are dropped past maxEntries entries
+// or, if set, once they were captured longer ago than window.
+type capturedEntries struct {
+	maxEntries int
+	window     time.Duration
+
+	entries []*parser.LogEntry
+	// times holds the capture time of each entry while window is set
+	times []time.Time
+	// total counts all captured entries, and full is set once maxEntries
+	// was exceeded
+	total int
+	full  bool
+}
+
+// add keeps entry, captured at now, and reports whether it is the first
+// entry for which an older one was dropped to stay within maxEntries
+func (c *capturedEntries) add(entry *parser.LogEntry, now time.Time) bool {
+	c.total++
+	c.entries = append(c.entries, entry)
+	if c.window > 0 {
+		c.times = append(c.times, now)
+	}
+	if len(c.entries) <= c.maxEntries {
+		return false
+	}
+	c.drop(len(c.entries) - c.maxEntries)
+	first := !c.full
+	c.full = true
+	return first
+}
+
+// expire drops the entries captured longer than window before now
+func (c *capturedEntries) expire(now time.Time) {
+	if c.window <= 0 {
+		return
+	}
+	cutoff := now.Add(-c.window)
+	n := sort.Search(len(c.times), func(i int) bool {
+		return c.times[i].After(cutoff)
+	})
+	c.drop(n)
+}
+
+// drop removes the n oldest entries. Appending reallocates the slices from
+// the kept entries only, so dropped entries are released over time.
+func (c *capturedEntries) drop(n int) {
+	if n <= 0 {
+		return
+	}
+	clear(c.entries[:n])
+	c.entries = c.entries[n:]
+	if c.window > 0 {
+		c.times = c.times[n:]
+	}
+}
+
+// serveMetrics serves an exporter at /metrics on addr, returning it with a
+// function stopping the server
+func serveMetrics(addr string) (*metrics.Exporter, func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	exporter := metrics.NewExporter()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logrus.WithError(err).Error("Metrics server stopped")
+		}
+	}()
+
+	logrus.WithField("addr", listener.Addr().String()).Info("Serving metrics")
+	fmt.Fprintf(os.Stderr, "Serving metrics at http://%s/metrics\n", listener.Addr())
+	return exporter, func() { server.Close() }, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+
+	"github.com/spf13/cobra"
+)
+
+func TestAdbCommandFlags(t *testing.T) {
+	cmd := adbCmd
+
+	expectedFlags := map[string]struct {
+		shorthand  string
+		valueType  string
+		defaultVal string
+	}{
+		"parser-config":    {"p", "string", ""},
+		"config":           {"c", "string", ""},
+		"funnel-config":    {"f", "string", ""},
+		"device":           {"s", "string", ""},
+		"tag":              {"", "stringSlice", "[]"},
+		"duration":         {"", "duration", "0s"},
+		"interval":         {"", "duration", "0s"},
+		"metrics-addr":     {"", "string", ""},
+		"metrics-interval": {"", "duration", "5s"},
+		"max-entries":      {"", "int", "1000000"},
+		"window":           {"", "duration", "0s"},
+		"output":           {"o", "string", "text"},
+		"limit":            {"", "int", "0"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Shorthand != expected.shorthand {
+			t.Errorf("Expected %s shorthand to be %q, got %q", flagName, expected.shorthand, flag.Shorthand)
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+}
+
+func TestAdbCommandProperties(t *testing.T) {
+	cmd := adbCmd
+
+	if cmd.Use != "adb [event_patterns...]" {
+		t.Errorf("Expected Use to be 'adb [event_patterns...]', got %q", cmd.Use)
+	}
+
+	if !strings.Contains(cmd.Long, "Examples:") {
+		t.Error("Expected Long description to contain examples")
+	}
+
+	if cmd.Run == nil {
+		t.Error("Run function should not be nil")
+	}
+
+	required := cmd.Flag("parser-config").Annotations[cobra.BashCompOneRequiredFlag]
+	if len(required) == 0 {
+		t.Error("Expected parser-config flag to be marked as required")
+	}
+}
+
+func TestCapturedEntries(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	captured := &capturedEntries{maxEntries: 3, window: time.Minute}
+
+	var full []bool
+	for i := 0; i < 5; i++ {
+		full = append(full, captured.add(&parser.LogEntry{Line: i + 1}, start.Add(time.Duration(i)*20*time.Second)))
+	}
+	if want := []bool{false, false, false, true, false}; !reflect.DeepEqual(full, want) {
+		t.Errorf("add() = %v, want %v", full, want)
+	}
+	if captured.total != 5 || len(captured.entries) != 3 || captured.entries[0].Line != 3 {
+		t.Fatalf("Expected the latest 3 of 5 entries, got %d of %d starting at line %d", len(captured.entries), captured.total, captured.entries[0].Line)
+	}
+
+	// Entries 3 to 5 were captured after 40s, 60s and 80s
+	captured.expire(start.Add(110 * time.Second))
+	if len(captured.entries) != 2 || captured.entries[0].Line != 4 {
+		t.Errorf("Expected the entries of the last minute to be kept, got %d", len(captured.entries))
+	}
+	captured.expire(start.Add(time.Hour))
+	if len(captured.entries) != 0 || captured.total != 5 {
+		t.Errorf("Expected all entries to expire, got %d of %d", len(captured.entries), captured.total)
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var anomaliesCmd = &cobra.Command{
+	Use:   "anomalies [event_patterns...]",
+	Short: "Flag time windows where event rates deviate from their baseline",
+	Long: `Anomalies command counts the events matching each pattern in fixed time windows
+and compares every window to a rolling baseline, the average count of the
+--baseline windows before it, leaving out anomalous windows. Windows with at least --factor times the baseline
+are flagged as spikes, such as error storms, and windows with at most the
+baseline divided by --factor as drops. Use it to correlate funnel drop-offs with
+bursts of errors.
+
+Spikes need at least --min-count events and drops a baseline of at least
+--min-count, so rare events are not flagged on noise. Windows span the whole
+log, so quiet periods count as zero events.
+
+Examples:
+  loglion anomalies -p parser.yaml -l logcat.txt "error" "crash"
+  loglion anomalies -p parser.yaml -l logcat.txt --window 5m --baseline 12 --factor 4 "timeout"
+  loglion anomalies -p parser.yaml -l logcat.txt --patterns-file patterns.txt -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+		patternsFile, _ := cmd.Flags().GetString("patterns-file")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		window, _ := cmd.Flags().GetDuration("window")
+		baseline, _ := cmd.Flags().GetInt("baseline")
+		factor, _ := cmd.Flags().GetFloat64("factor")
+		minCount, _ := cmd.Flags().GetInt("min-count")
+
+		labels := make([]string, len(args))
+		if patternsFile != "" {
+			filePatterns, fileLabels, err := loadPatternsFile(patternsFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			args = append(args, filePatterns...)
+			labels = append(labels, fileLabels...)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":      logPatterns,
+			"output_format":  outputFormat,
+			"event_patterns": args,
+			"window":         window,
+			"baseline":       baseline,
+			"factor":         factor,
+		}).Info("Starting anomaly detection")
+
+		anomalyAnalyzer, err := analyzer.NewAnomalyAnalyzer(args, analyzer.AnomalyOptions{
+			Window:          window,
+			BaselineWindows: baseline,
+			Factor:          factor,
+			MinCount:        minCount,
+		}, analyzer.CountOptions{IgnoreCase: ignoreCase, Labels: labels})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		outputOptions := output.Options{MaxNameWidth: maxNameWidth}
+		if err := outputOptions.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries := loadLogEntries(cmd)
+
+		logrus.Debug("Starting anomaly detection")
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := anomalyAnalyzer.AnalyzeAnomaliesContext(ctx, entries)
+		stop()
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, outputOptions)
+
+		logrus.Debug("Formatting anomaly results")
+		formattedOutput, err := formatter.FormatAnomalies(result)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format anomaly output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Anomaly detection completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(anomaliesCmd)
+
+	addLogInputFlags(anomaliesCmd)
+	anomaliesCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	anomaliesCmd.Flags().Int("max-name-width", 0, "Truncate pattern names longer than this in text output (0 = no limit)")
+
+	anomaliesCmd.Flags().String("patterns-file", "", "Read event patterns from this file, one per line as pattern or label<TAB>pattern")
+	anomaliesCmd.Flags().BoolP("ignore-case", "i", false, "Match patterns regardless of case")
+	anomaliesCmd.Flags().Duration("window", time.Minute, "Length of the windows events are counted in (e.g. 30s, 5m)")
+	anomaliesCmd.Flags().Int("baseline", 10, "Number of preceding windows averaged into the baseline")
+	anomaliesCmd.Flags().Float64("factor", 3, "Flag windows with this many times more, or fewer, events than the baseline")
+	anomaliesCmd.Flags().Int("min-count", 5, "Events a spike needs, and the baseline a drop needs")
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check the properties of events against an expected schema",
+	Long: `Audit command checks every occurrence of the events declared in a schema file
+against the properties they are expected to carry, and reports each violation
+with the file and line of the offending entry. This catches malformed analytics
+payloads before they reach the warehouse.
+
+A property can be required, have a type (string, number, integer, boolean,
+object, array) and a regex pattern its value must match:
+
+  events:
+    - event_pattern: "^purchase$"
+      properties:
+        order_id: {type: string, required: true, pattern: "^ord_[0-9]+$"}
+        amount: {type: number, required: true}
+        cart.items: {type: integer}
+
+The command exits with code 2 when --fail-on-violation is set and an event
+violates the schema.
+
+Examples:
+  loglion audit -p parser.yaml -l app.log --schema events.yaml
+  loglion audit -p parser.yaml -l "logs/*.log" --schema events.yaml --fail-on-violation
+  loglion audit -p parser.yaml -l app.log --schema events.yaml -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+		schemaFile, _ := cmd.Flags().GetString("schema")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		failOnViolation, _ := cmd.Flags().GetBool("fail-on-violation")
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":     logPatterns,
+			"output_format": outputFormat,
+			"schema_file":   schemaFile,
+		}).Info("Starting event audit")
+
+		auditCfg, err := config.LoadAuditConfig(schemaFile)
+		if err != nil {
+			logrus.WithError(err).WithField("schema_file", schemaFile).Error("Failed to load audit config")
+			fmt.Fprintf(os.Stderr, "Error loading schema: %v\n", err)
+			os.Exit(1)
+		}
+
+		auditAnalyzer, err := analyzer.NewAuditAnalyzer(auditCfg, analyzer.CountOptions{IgnoreCase: ignoreCase})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		outputOptions := output.Options{MaxNameWidth: maxNameWidth}
+		if err := outputOptions.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries := loadLogEntries(cmd)
+
+		logrus.Debug("Starting event audit")
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := auditAnalyzer.AnalyzeAuditContext(ctx, entries)
+		stop()
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, outputOptions)
+
+		logrus.Debug("Formatting audit results")
+		formattedOutput, err := formatter.FormatAudit(result)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format audit output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Event audit completed successfully")
+		fmt.Print(formattedOutput)
+
+		if failOnViolation && result.InvalidEvents > 0 {
+			fmt.Fprintf(os.Stderr, "Audit failed: %d of %d events violate the schema\n", result.InvalidEvents, result.AuditedEvents)
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	addLogInputFlags(auditCmd)
+	auditCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	auditCmd.Flags().Int("max-name-width", 0, "Truncate event pattern names if longer than this in text output (0 = no limit)")
+
+	auditCmd.Flags().String("schema", "", "Path to the file declaring the expected event properties (required)")
+	auditCmd.Flags().BoolP("ignore-case", "i", false, "Match the event patterns regardless of case")
+	auditCmd.Flags().Bool("fail-on-violation", false, "Exit with code 2 if an event violates the schema")
+
+	auditCmd.MarkFlagRequired("schema")
+}
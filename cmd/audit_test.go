@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAuditCommandFlags(t *testing.T) {
+	cmd := auditCmd
+
+	expectedFlags := map[string]struct {
+		shorthand  string
+		valueType  string
+		defaultVal string
+	}{
+		"parser-config":     {"p", "string", ""},
+		"config":            {"c", "string", ""},
+		"log":               {"l", "stringSlice", "[]"},
+		"output":            {"o", "string", "text"},
+		"max-name-width":    {"", "int", "0"},
+		"schema":            {"", "string", ""},
+		"ignore-case":       {"i", "bool", "false"},
+		"fail-on-violation": {"", "bool", "false"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Shorthand != expected.shorthand {
+			t.Errorf("Expected %s shorthand to be %q, got %q", flagName, expected.shorthand, flag.Shorthand)
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+}
+
+func TestAuditCommandProperties(t *testing.T) {
+	cmd := auditCmd
+
+	if cmd.Use != "audit" {
+		t.Errorf("Expected Use to be 'audit', got %q", cmd.Use)
+	}
+
+	if !strings.Contains(cmd.Long, "Examples:") {
+		t.Error("Expected Long description to contain examples")
+	}
+
+	for _, required := range []string{"parser-config", "log", "schema"} {
+		flag := cmd.Flags().Lookup(required)
+		if flag == nil || len(flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]) == 0 {
+			t.Errorf("Expected %s flag to be required", required)
+		}
+	}
+}
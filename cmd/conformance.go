@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/parfenovvs/loglion/internal/conformance"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var conformanceCmd = &cobra.Command{
+	Use:   "conformance",
+	Short: "Verify log parsing against a corpus of golden outputs",
+	Long: `Conformance command parses every sample log of a corpus and compares the
+parsed entries with the golden NDJSON stored next to it. Each case is a
+directory with a parser config (parser.yaml), a sample log (input.log) and
+the expected entries (golden.ndjson).
+
+Examples:
+  loglion conformance --corpus conformance
+  loglion conformance --corpus conformance --update`,
+	Run: func(cmd *cobra.Command, args []string) {
+		corpusDir, _ := cmd.Flags().GetString("corpus")
+		update, _ := cmd.Flags().GetBool("update")
+
+		logrus.WithFields(logrus.Fields{
+			"corpus": corpusDir,
+			"update": update,
+		}).Info("Starting conformance check")
+
+		cases, err := conformance.FindCases(corpusDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading conformance corpus: %v\n", err)
+			os.Exit(1)
+		}
+
+		if update {
+			for _, c := range cases {
+				if err := conformance.Update(c); err != nil {
+					fmt.Fprintf(os.Stderr, "Error updating golden output: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf(textOutput("📝 %s: golden output updated\n"), c.Name)
+			}
+			return
+		}
+
+		failed := 0
+		for _, c := range cases {
+			result, err := conformance.Verify(c)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error verifying conformance case: %v\n", err)
+				os.Exit(1)
+			}
+
+			if result.Passed() {
+				fmt.Printf(textOutput("✅ %s: %d entries match\n"), result.Case, result.Entries)
+				continue
+			}
+
+			failed++
+			fmt.Printf(textOutput("❌ %s: %d mismatched entries\n"), result.Case, len(result.Mismatches))
+			for _, mismatch := range result.Mismatches {
+				fmt.Printf("   entry %d:\n", mismatch.Line)
+				fmt.Printf("     expected: %s\n", orMissing(mismatch.Expected))
+				fmt.Printf("     actual:   %s\n", orMissing(mismatch.Actual))
+			}
+		}
+
+		if failed > 0 {
+			fmt.Fprintf(os.Stderr, "Conformance failed: %d of %d cases differ from the golden output\n", failed, len(cases))
+			os.Exit(1)
+		}
+		fmt.Printf("All %d conformance cases passed\n", len(cases))
+	},
+}
+
+func orMissing(line string) string {
+	if line == "" {
+		return "(missing)"
+	}
+	return line
+}
+
+func init() {
+	rootCmd.AddCommand(conformanceCmd)
+
+	conformanceCmd.Flags().String("corpus", "conformance", "Directory with the conformance cases")
+	conformanceCmd.Flags().Bool("update", false, "Rewrite the golden outputs from the current parser instead of verifying them")
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConformanceCommandFlags(t *testing.T) {
+	cmd := conformanceCmd
+
+	expectedFlags := map[string]struct {
+		valueType  string
+		defaultVal string
+	}{
+		"corpus": {"string", "conformance"},
+		"update": {"bool", "false"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+}
+
+func TestConformanceCommandProperties(t *testing.T) {
+	cmd := conformanceCmd
+
+	if cmd.Use != "conformance" {
+		t.Errorf("Expected Use to be 'conformance', got %q", cmd.Use)
+	}
+
+	if !strings.Contains(cmd.Long, "Examples:") {
+		t.Error("Expected Long description to contain examples")
+	}
+
+	if cmd.Run == nil {
+		t.Error("Run function should not be nil")
+	}
+}
+
+func TestOrMissing(t *testing.T) {
+	if got := orMissing(""); got != "(missing)" {
+		t.Errorf("orMissing(\"\") = %q, want (missing)", got)
+	}
+	if got := orMissing(`{"message":"login"}`); got != `{"message":"login"}` {
+		t.Errorf("orMissing() = %q, want line unchanged", got)
+	}
+}
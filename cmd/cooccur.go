@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var cooccurCmd = &cobra.Command{
+	Use:   "cooccur [event_patterns...]",
+	Short: "Report how often event patterns occur in the same session or time window",
+	Long: `Cooccur command splits log files into sessions or time windows and reports, for
+every pair of event patterns, in how many of them both patterns matched, the
+conditional probability of one pattern given the other and how often one matched
+before the other. Use it to discover which events actually precede a purchase.
+
+Sessions are split like in the sessions command, by the --session-key event
+property and --idle-timeout. With --window the log is split into fixed time
+windows instead, e.g. 5m.
+
+Examples:
+  loglion cooccur -p parser.yaml -l events.log --session-key user_id "view" "add_cart" "purchase"
+  loglion cooccur -p parser.yaml -l logcat.txt --window 5m "crash" "low_memory" "gc_pause"
+  loglion cooccur -p parser.yaml -l events.log --session-key session_id --patterns-file patterns.txt -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+		patternsFile, _ := cmd.Flags().GetString("patterns-file")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		sessionKey, _ := cmd.Flags().GetString("session-key")
+		idleTimeout, _ := cmd.Flags().GetDuration("idle-timeout")
+		window, _ := cmd.Flags().GetDuration("window")
+
+		labels := make([]string, len(args))
+		if patternsFile != "" {
+			filePatterns, fileLabels, err := loadPatternsFile(patternsFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			args = append(args, filePatterns...)
+			labels = append(labels, fileLabels...)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":      logPatterns,
+			"output_format":  outputFormat,
+			"event_patterns": args,
+			"session_key":    sessionKey,
+			"window":         window,
+		}).Info("Starting co-occurrence analysis")
+
+		if window > 0 && cmd.Flags().Changed("session-key") {
+			fmt.Fprintf(os.Stderr, "Error: --window and --session-key cannot be combined\n")
+			os.Exit(1)
+		}
+
+		options := analyzer.CooccurrenceOptions{
+			IgnoreCase: ignoreCase,
+			Labels:     labels,
+			Window:     window,
+		}
+		if window == 0 {
+			sessions, err := analyzer.NewSessionAnalyzer(sessionKey, idleTimeout)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			options.Sessions = sessions
+		}
+
+		cooccurrenceAnalyzer, err := analyzer.NewCooccurrenceAnalyzer(args, options)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		outputOptions := output.Options{MaxNameWidth: maxNameWidth}
+		if err := outputOptions.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries := loadLogEntries(cmd)
+
+		logrus.Debug("Starting co-occurrence analysis")
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := cooccurrenceAnalyzer.AnalyzeCooccurrenceContext(ctx, entries)
+		stop()
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, outputOptions)
+
+		logrus.Debug("Formatting co-occurrence results")
+		formattedOutput, err := formatter.FormatCooccurrence(result)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format co-occurrence output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Co-occurrence analysis completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cooccurCmd)
+
+	addLogInputFlags(cooccurCmd)
+	cooccurCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	cooccurCmd.Flags().Int("max-name-width", 0, "Truncate pattern names longer than this in text output (0 = no limit)")
+
+	cooccurCmd.Flags().String("patterns-file", "", "Read event patterns from this file, one per line as pattern or label<TAB>pattern")
+	cooccurCmd.Flags().BoolP("ignore-case", "i", false, "Match patterns regardless of case")
+	cooccurCmd.Flags().String("session-key", "", "Event property that identifies a session, e.g. session_id (default: whole log)")
+	cooccurCmd.Flags().Duration("idle-timeout", 30*time.Minute, "Start a new session after this long without events (0 to disable)")
+	cooccurCmd.Flags().Duration("window", 0, "Split the log into time windows of this length instead of sessions, e.g. 5m")
+}
@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/parfenovvs/loglion/internal/analyzer"
 	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/metrics"
 	"github.com/parfenovvs/loglion/internal/output"
-	"github.com/parfenovvs/loglion/internal/parser"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -21,78 +27,203 @@ It accepts multiple event patterns as arguments and outputs the count for each p
 Examples:
   loglion count --parser-config parser.yaml --log logcat.txt "login" "logout" "error"
   loglion count -p parser.yaml -l logcat.txt --output json "user_action" "network_request"
-  loglion count -p parser.yaml -l logcat.txt "memory_warning"`,
-	Args: cobra.MinimumNArgs(1),
+  loglion count -p parser.yaml -l logcat.txt "memory_warning"
+  loglion count -p parser.yaml -l events.log --group-by screen "tap" "scroll"
+  loglion count -p parser.yaml -l events.log --per-session --session-key session_id "share"
+  loglion count -p parser.yaml -l events.log --distinct user_id --distinct-top 5 "purchase"
+  loglion count -p parser.yaml -l events.log --aggregate amount --group-by currency "purchase"
+  loglion count -p parser.yaml -l logcat.txt --patterns-file patterns.txt
+  adb logcat -d | loglion count -p parser.yaml "login" "logout"
+
+Without --log the log is read from stdin, so the output of adb logcat, grep or
+zcat can be piped in.
+
+With --patterns-file the patterns are read from a file, one per line, after those
+given as arguments. A line "label<TAB>pattern" reports the pattern under the label;
+blank lines and lines starting with # are skipped, and a leading \# stands for a
+pattern starting with #.
+
+With --aggregate the sum, average, minimum and maximum of a numeric event data
+property are reported for each pattern, such as the revenue of purchases, and the
+sums are broken down by the --group-by property if given.
+
+A warning is printed when a log entry matches more than one pattern, since the counts
+and percentages of overlapping patterns are not independent. Use --no-overlap to
+treat overlapping patterns as an error.
+
+With --watch, the counts are printed again whenever a log file changes, e.g. while
+reproducing a bug with logcat redirected to the file. With --metrics-addr, the
+pattern matches of the latest analysis are also served at /metrics in Prometheus
+format.`,
+	// Checked in Run, since --patterns-file may come from the project defaults
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		parserConfigFile, _ := cmd.Flags().GetString("parser-config")
-		logFile, _ := cmd.Flags().GetString("log")
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
 		outputFormat, _ := cmd.Flags().GetString("output")
+		noOverlap, _ := cmd.Flags().GetBool("no-overlap")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		perSession, _ := cmd.Flags().GetBool("per-session")
+		distinct, _ := cmd.Flags().GetString("distinct")
+		distinctTop, _ := cmd.Flags().GetInt("distinct-top")
+		aggregate, _ := cmd.Flags().GetString("aggregate")
+		sessionKey, _ := cmd.Flags().GetString("session-key")
+		idleTimeout, _ := cmd.Flags().GetDuration("idle-timeout")
+		watch, _ := cmd.Flags().GetBool("watch")
+		metricsAddr, _ := cmd.Flags().GetString("metrics-addr")
+		patternsFile, _ := cmd.Flags().GetString("patterns-file")
+
+		labels := make([]string, len(args))
+		if patternsFile != "" {
+			filePatterns, fileLabels, err := loadPatternsFile(patternsFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			args = append(args, filePatterns...)
+			labels = append(labels, fileLabels...)
+		}
+		if len(args) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: requires at least 1 arg(s) or --patterns-file\n")
+			os.Exit(1)
+		}
+
+		// Without --log the log is piped in
+		readStdin := len(logPatterns) == 0 || slices.Contains(logPatterns, stdinLog)
+		if readStdin && watch {
+			fmt.Fprintf(os.Stderr, "Error: --watch requires --log\n")
+			os.Exit(1)
+		}
+		if metricsAddr != "" && !watch {
+			fmt.Fprintf(os.Stderr, "Error: --metrics-addr requires --watch\n")
+			os.Exit(1)
+		}
+		logSources := logPatterns
+		if len(logSources) == 0 {
+			logSources = []string{stdinLog}
+		}
+
+		outputOptions, err := outputOptionsFromFlags(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		outputTargets, err := outputTargetsFromFlags(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, target := range outputTargets {
+			if target.Format == "sankey" {
+				fmt.Fprintf(os.Stderr, "Error: sankey output is only supported by funnel, count supports json, text, html and template\n")
+				os.Exit(1)
+			}
+		}
 
 		logrus.WithFields(logrus.Fields{
 			"parser_config_file": parserConfigFile,
-			"log_file":           logFile,
+			"log_files":          logPatterns,
 			"output_format":      outputFormat,
 			"event_patterns":     args,
 		}).Info("Starting count analysis")
 
-		// Load parser configuration
-		logrus.Debug("Loading parser configuration file")
-		parserCfg, err := config.LoadParserConfig(parserConfigFile)
-		if err != nil {
-			logrus.WithError(err).WithField("parser_config_file", parserConfigFile).Error("Failed to load parser config")
-			fmt.Fprintf(os.Stderr, "Error loading parser config: %v\n", err)
-			os.Exit(1)
+		var sessions *analyzer.SessionAnalyzer
+		if perSession {
+			if sessionKey == "" {
+				fmt.Fprintf(os.Stderr, "Error: --per-session requires --session-key\n")
+				os.Exit(1)
+			}
+			sessions, err = analyzer.NewSessionAnalyzer(sessionKey, idleTimeout)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
 		}
 
-		// Create parser
-		logrus.Debug("Creating log parser")
-		logParser := parser.NewParserWithConfig(
-			parserCfg.TimestampFormat,
-			parserCfg.EventRegex,
-			parserCfg.JSONExtraction,
-			parserCfg.LogLineRegex)
+		parserCfg, entryFilter := loadParserConfig(cmd)
+
+		printConfigWarnings(config.CountWarnings(args, []string{groupBy, distinct, sessionKey}, parserCfg))
 
 		// Create count analyzer
 		logrus.Debug("Creating count analyzer")
-		countAnalyzer, err := analyzer.NewCountAnalyzer(args)
+		countAnalyzer, err := analyzer.NewCountAnalyzerWithOptions(args, analyzer.CountOptions{
+			IgnoreCase:  ignoreCase,
+			GroupBy:     groupBy,
+			Sessions:    sessions,
+			Distinct:    distinct,
+			DistinctTop: distinctTop,
+			Aggregate:   aggregate,
+			Labels:      labels,
+		})
 		if err != nil {
 			logrus.WithError(err).Error("Failed to create count analyzer")
 			fmt.Fprintf(os.Stderr, "Error creating count analyzer: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Parse log file
-		logrus.WithField("log_file", logFile).Debug("Starting log file parsing")
-		entries, err := logParser.ParseFile(logFile)
-		if err != nil {
-			logrus.WithError(err).WithField("log_file", logFile).Error("Failed to parse log file")
-			fmt.Fprintf(os.Stderr, "Error parsing log file: %v\n", err)
-			os.Exit(1)
+		var metricsExporter *metrics.Exporter
+		if metricsAddr != "" {
+			var closeMetrics func()
+			if metricsExporter, closeMetrics, err = serveMetrics(metricsAddr); err != nil {
+				fmt.Fprintf(os.Stderr, "Error starting metrics server: %v\n", err)
+				os.Exit(1)
+			}
+			defer closeMetrics()
 		}
 
-		logrus.Debug("Starting count analysis")
-		result := countAnalyzer.AnalyzeCount(entries)
+		// analyze parses the log files and outputs the counts, returning the exit code
+		analyze := func(ctx context.Context) int {
+			entries, _, ok := readLogFiles(cmd, parserCfg, entryFilter, logSources, false)
+			if !ok {
+				return 1
+			}
 
-		// Format and output results
-		logrus.WithField("output_format", outputFormat).Debug("Creating output formatter")
-		var formatter output.Formatter
-		switch outputFormat {
-		case "json":
-			formatter = output.NewFormatter(output.JSONFormat)
-		default:
-			formatter = output.NewFormatter(output.TextFormat)
-		}
+			logrus.Debug("Starting count analysis")
+			result := countAnalyzer.AnalyzeCountContext(ctx, entries)
+			if metricsExporter != nil {
+				metricsExporter.Update(&metrics.Snapshot{EntriesParsed: len(entries), Count: result, UpdatedAt: time.Now()})
+			}
 
-		logrus.Debug("Formatting count analysis results")
-		formattedOutput, err := formatter.FormatCount(result)
-		if err != nil {
-			logrus.WithError(err).Error("Failed to format count analysis output")
-			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
-			os.Exit(1)
+			if result.OverlappingEntries > 0 {
+				logrus.WithFields(logrus.Fields{
+					"overlapping_entries": result.OverlappingEntries,
+					"overlaps":            result.Overlaps,
+				}).Warn("Count patterns overlap")
+				if noOverlap {
+					fmt.Fprintf(os.Stderr, "Error: %s\n", describeOverlaps(result))
+					return 1
+				}
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", describeOverlaps(result))
+			}
+
+			// Format and output results
+			logrus.Debug("Formatting count analysis results")
+			err = emitOutput(outputTargets, outputOptions, func(formatter output.Formatter) (string, error) {
+				return formatter.FormatCount(result)
+			})
+			if err != nil {
+				logrus.WithError(err).Error("Failed to output count analysis results")
+				fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+				return 1
+			}
+			return 0
 		}
 
-		logrus.WithField("output_length", len(formattedOutput)).Info("Count analysis completed successfully")
-		fmt.Print(formattedOutput)
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if watch {
+			if err := watchLogFiles(ctx, logPatterns, analyze); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if code := analyze(ctx); code != 0 {
+			os.Exit(code)
+		}
+		logrus.Info("Count analysis completed successfully")
 	},
 }
 
@@ -100,9 +231,80 @@ func init() {
 	rootCmd.AddCommand(countCmd)
 
 	countCmd.Flags().StringP("parser-config", "p", "", "Path to parser configuration file (required)")
-	countCmd.Flags().StringP("log", "l", "", "Path to log file (required)")
-	countCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	addConfigFlag(countCmd)
+	countCmd.Flags().StringSliceP("log", "l", nil, "Path or glob pattern of log files, merged in order (repeatable; stdin if omitted)")
+	addLogReadingFlags(countCmd)
+	countCmd.Flags().StringP("output", "o", "text", "Output format (json, text, html, template; sankey is funnel-only), or format=path pairs to write several, e.g. json=result.json,text=-")
+	countCmd.Flags().String("output-file", "", "Write the formatted results to this file instead of stdout")
+	countCmd.Flags().String("template-file", "", "Go text/template file rendering the results with --output template")
+	countCmd.Flags().StringSlice("only", nil, "Only output these sections (summary, steps, chart, drop_offs, anomalies, exclusions, timings, assertions, counts, overlaps, groups, sessions, distinct, aggregate, conversions)")
+	countCmd.Flags().StringSlice("hide", nil, "Hide these sections, or 'zero-count' to hide steps and patterns without matches")
+	countCmd.Flags().Int("max-name-width", 0, "Truncate step and pattern names longer than this in text output (0 = no limit)")
+	countCmd.Flags().Bool("table", false, "Render step and pattern breakdowns as tables with aligned columns in text output")
+	countCmd.Flags().Bool("borders", false, "Frame text output tables with unicode borders (implies --table)")
+	countCmd.Flags().Bool("wide", false, "Add first and last match times and the median time to each step to text output tables (implies --table)")
+
+	countCmd.Flags().String("patterns-file", "", "Read event patterns from this file, one per line as pattern or label<TAB>pattern")
+	countCmd.Flags().Bool("no-overlap", false, "Fail if a log entry matches more than one pattern")
+	countCmd.Flags().BoolP("ignore-case", "i", false, "Match patterns regardless of case")
+	countCmd.Flags().String("group-by", "", "Break matches down by this event data property (e.g. user_id)")
+	countCmd.Flags().String("distinct", "", "Also report the number of unique values of this event data property among the matches (e.g. user_id)")
+	countCmd.Flags().Int("distinct-top", 0, "List this many most frequent values of the --distinct property per pattern")
+	countCmd.Flags().String("aggregate", "", "Also report the sum, average, minimum and maximum of this numeric event data property over the matches (e.g. amount)")
+	countCmd.Flags().Bool("per-session", false, "Also report how many sessions matched each pattern (requires --session-key)")
+	countCmd.Flags().String("session-key", "", "Event property that identifies a session with --per-session, e.g. session_id")
+	countCmd.Flags().Duration("idle-timeout", 30*time.Minute, "Start a new session after this long without events (0 to disable)")
+	countCmd.Flags().Bool("watch", false, "Analyze again whenever a log file changes, e.g. while logcat is redirected to it, until Ctrl+C")
+	countCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics of the latest analysis at /metrics on this address with --watch (e.g. :9090)")
 
 	countCmd.MarkFlagRequired("parser-config")
-	countCmd.MarkFlagRequired("log")
+}
+
+// loadPatternsFile reads the event patterns of a patterns file and their
+// labels, empty for patterns without one. A tab separates the label from the
+// pattern, since tabs are written \t in regular expressions, and a leading \#
+// escapes a pattern that would otherwise be a comment.
+func loadPatternsFile(path string) ([]string, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read patterns file: %w", err)
+	}
+
+	var patterns, labels []string
+	for i, line := range strings.Split(string(data), "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		label, pattern, ok := strings.Cut(strings.TrimRight(line, "\r"), "\t")
+		if !ok {
+			label, pattern = "", line
+		}
+		label, pattern = strings.TrimSpace(label), strings.TrimSpace(pattern)
+		if strings.HasPrefix(pattern, `\#`) {
+			pattern = pattern[1:]
+		}
+		if pattern == "" {
+			return nil, nil, fmt.Errorf("patterns file %s:%d: empty pattern for label '%s'", path, i+1, label)
+		}
+		patterns = append(patterns, pattern)
+		labels = append(labels, label)
+	}
+
+	if len(patterns) == 0 {
+		return nil, nil, fmt.Errorf("patterns file %s has no patterns", path)
+	}
+	logrus.WithFields(logrus.Fields{
+		"patterns_file": path,
+		"pattern_count": len(patterns),
+	}).Debug("Loaded patterns file")
+	return patterns, labels, nil
+}
+
+// describeOverlaps summarizes which patterns matched the same log entries
+func describeOverlaps(result *analyzer.CountResult) string {
+	pairs := make([]string, 0, len(result.Overlaps))
+	for _, overlap := range result.Overlaps {
+		pairs = append(pairs, fmt.Sprintf("%s (%d)", strings.Join(overlap.Patterns, " & "), overlap.Count))
+	}
+	return fmt.Sprintf("%d entries matched more than one pattern: %s", result.OverlappingEntries, strings.Join(pairs, ", "))
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/parfenovvs/loglion/internal/analyzer"
 	"github.com/spf13/cobra"
 )
 
@@ -237,18 +238,18 @@ purchase item456`
 func TestCountCommandArgumentValidation(t *testing.T) {
 	// Test that MinimumNArgs(1) is enforced by cobra
 	cmd := createCountCommand()
-	
+
 	// Test Args validation function directly
 	if cmd.Args == nil {
 		t.Fatal("Args validation function should not be nil")
 	}
-	
+
 	// Test with no args - should fail
 	err := cmd.Args(cmd, []string{})
 	if err == nil {
 		t.Error("Expected Args validation to fail with no arguments")
 	}
-	
+
 	// Test with args - should pass
 	err = cmd.Args(cmd, []string{"pattern1"})
 	if err != nil {
@@ -256,6 +257,35 @@ func TestCountCommandArgumentValidation(t *testing.T) {
 	}
 }
 
+func TestLoadPatternsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.txt")
+	content := "# Checkout\n^checkout_start$\n\nPaid\t^purchase amount=(\\d+)$\n  Status = 500  \n\\#retry\nHashtag\t\\#sale\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write patterns file: %v", err)
+	}
+
+	patterns, labels, err := loadPatternsFile(path)
+	if err != nil {
+		t.Fatalf("loadPatternsFile() unexpected error: %v", err)
+	}
+	// " = " is part of the pattern, and \# escapes a leading #
+	wantPatterns := []string{"^checkout_start$", `^purchase amount=(\d+)$`, "Status = 500", "#retry", "#sale"}
+	wantLabels := []string{"", "Paid", "", "", "Hashtag"}
+	if strings.Join(patterns, "|") != strings.Join(wantPatterns, "|") || strings.Join(labels, "|") != strings.Join(wantLabels, "|") {
+		t.Errorf("loadPatternsFile() = %q, %q, want %q, %q", patterns, labels, wantPatterns, wantLabels)
+	}
+
+	for name, content := range map[string]string{"empty": "# nothing\n", "label only": "Paid\t\n"} {
+		path := filepath.Join(t.TempDir(), "patterns.txt")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write patterns file: %v", err)
+		}
+		if _, _, err := loadPatternsFile(path); err == nil {
+			t.Errorf("Expected an error for a %s patterns file", name)
+		}
+	}
+}
+
 func createCountCommand() *cobra.Command {
 	// Create a simplified version of countCmd for testing
 	cmd := &cobra.Command{
@@ -270,11 +300,11 @@ Examples:
   loglion count -p parser.yaml -l logcat.txt "memory_warning"`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Check args first 
+			// Check args first
 			if len(args) == 0 {
 				return fmt.Errorf("requires at least 1 arg(s), only received 0")
 			}
-			
+
 			// Simplified run function for testing
 			parserConfigFile, _ := cmd.Flags().GetString("parser-config")
 			logFile, _ := cmd.Flags().GetString("log")
@@ -318,4 +348,19 @@ Examples:
 	cmd.MarkFlagRequired("log")
 
 	return cmd
-}
\ No newline at end of file
+}
+
+func TestDescribeOverlaps(t *testing.T) {
+	result := &analyzer.CountResult{
+		OverlappingEntries: 3,
+		Overlaps: []analyzer.PatternOverlap{
+			{Patterns: []string{"login", "login_failed"}, Count: 2},
+			{Patterns: []string{"error", "login_failed"}, Count: 1},
+		},
+	}
+
+	expected := "3 entries matched more than one pattern: login & login_failed (2), error & login_failed (1)"
+	if got := describeOverlaps(result); got != expected {
+		t.Errorf("describeOverlaps() = %q, want %q", got, expected)
+	}
+}
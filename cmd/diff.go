@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare a funnel between two sets of log files",
+	Long: `Diff command runs the same funnel configuration against a baseline and a
+candidate set of log files, e.g. before and after a release or control versus
+treatment, and reports the change of every step's event count and conversion,
+of the drop-off rates between steps and of the conversion rate. Deltas are
+candidate minus baseline; conversion changes are in percentage points (pp).
+
+Examples:
+  loglion diff -p parser.yaml -f funnel.yaml --baseline before.txt --candidate after.txt
+  loglion diff -p parser.yaml -f funnel.yaml --baseline "control/*.txt" --candidate "treatment/*.txt"
+  loglion diff -p parser.yaml -f funnel.yaml --baseline v1.txt --candidate v2.txt --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		funnelConfigFile, _ := cmd.Flags().GetString("funnel-config")
+		baselinePatterns, _ := cmd.Flags().GetStringSlice("baseline")
+		candidatePatterns, _ := cmd.Flags().GetStringSlice("candidate")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+
+		logrus.WithFields(logrus.Fields{
+			"funnel_config_file": funnelConfigFile,
+			"baseline":           baselinePatterns,
+			"candidate":          candidatePatterns,
+			"output_format":      outputFormat,
+		}).Info("Starting funnel diff")
+
+		outputOptions := output.Options{MaxNameWidth: maxNameWidth}
+		if err := outputOptions.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Load funnel configuration
+		logrus.Debug("Loading funnel configuration file")
+		funnelCfgs, err := config.LoadFunnelConfigs(funnelConfigFile)
+		if err != nil {
+			logrus.WithError(err).WithField("funnel_config_file", funnelConfigFile).Error("Failed to load funnel config")
+			fmt.Fprintf(os.Stderr, "Error loading funnel config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Stop analysis on Ctrl+C and still report the partial results
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		var results [2][]*analyzer.FunnelResult
+		parserCfg, entryFilter := loadParserConfig(cmd)
+		for i, patterns := range [][]string{baselinePatterns, candidatePatterns} {
+			entries, _, ok := readLogFiles(cmd, parserCfg, entryFilter, patterns, false)
+			if !ok {
+				os.Exit(1)
+			}
+			results[i] = analyzeFunnels(ctx, funnelCfgs, entries, analyzer.FunnelOptions{}, nil)
+		}
+		stop()
+
+		for _, result := range append(results[0], results[1]...) {
+			if result.Partial {
+				fmt.Fprintf(os.Stderr, "Warning: analysis was cancelled, funnel '%s' is based on %d entries\n", result.FunnelName, result.TotalEventsAnalyzed)
+			}
+		}
+
+		report := analyzer.NewDiffReport(strings.Join(baselinePatterns, ","), strings.Join(candidatePatterns, ","), results[0], results[1])
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, outputOptions)
+
+		logrus.Debug("Formatting funnel diff")
+		formattedOutput, err := formatter.FormatDiff(report)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format diff output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Funnel diff completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringP("parser-config", "p", "", "Path to parser configuration file (required)")
+	diffCmd.Flags().StringP("funnel-config", "f", "", "Path to funnel configuration file (required)")
+	addConfigFlag(diffCmd)
+	diffCmd.Flags().StringSlice("baseline", nil, "Path or glob pattern of the baseline log files, merged in order (required, repeatable)")
+	diffCmd.Flags().StringSlice("candidate", nil, "Path or glob pattern of the candidate log files, merged in order (required, repeatable)")
+	addLogReadingFlags(diffCmd)
+	diffCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	diffCmd.Flags().Int("max-name-width", 0, "Truncate step names longer than this in text output (0 = no limit)")
+
+	diffCmd.MarkFlagRequired("parser-config")
+	diffCmd.MarkFlagRequired("funnel-config")
+	diffCmd.MarkFlagRequired("baseline")
+	diffCmd.MarkFlagRequired("candidate")
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffCommandFlags(t *testing.T) {
+	cmd := diffCmd
+
+	expectedFlags := map[string]struct {
+		shorthand  string
+		valueType  string
+		defaultVal string
+	}{
+		"parser-config":     {"p", "string", ""},
+		"config":            {"c", "string", ""},
+		"funnel-config":     {"f", "string", ""},
+		"baseline":          {"", "stringSlice", "[]"},
+		"candidate":         {"", "stringSlice", "[]"},
+		"level":             {"", "string", ""},
+		"tag":               {"", "stringSlice", "[]"},
+		"output":            {"o", "string", "text"},
+		"max-name-width":    {"", "int", "0"},
+		"parse-stats":       {"", "bool", "false"},
+		"strict":            {"", "bool", "false"},
+		"workers":           {"", "int", "1"},
+		"sort-by-timestamp": {"", "bool", "false"},
+		"dedupe":            {"", "bool", "false"},
+		"keep-duplicates":   {"", "bool", "false"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Shorthand != expected.shorthand {
+			t.Errorf("Expected %s shorthand to be %q, got %q", flagName, expected.shorthand, flag.Shorthand)
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+
+	if cmd.Flags().Lookup("log") != nil {
+		t.Error("Expected no log flag, the log files are given with --baseline and --candidate")
+	}
+}
+
+func TestDiffCommandProperties(t *testing.T) {
+	cmd := diffCmd
+
+	if cmd.Use != "diff" {
+		t.Errorf("Expected Use to be 'diff', got %q", cmd.Use)
+	}
+
+	if !strings.Contains(cmd.Long, "Examples:") {
+		t.Error("Expected Long description to contain examples")
+	}
+
+	if cmd.Run == nil {
+		t.Error("Run function should not be nil")
+	}
+
+	for _, required := range []string{"parser-config", "funnel-config", "baseline", "candidate"} {
+		flag := cmd.Flags().Lookup(required)
+		if flag == nil || len(flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]) == 0 {
+			t.Errorf("Expected %s flag to be required", required)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Suggest funnels from frequent event sequences in log files",
+	Long: `Discover command splits log files into sessions and mines the event sequences
+that occur in at least --min-support percent of them, in order though not
+necessarily one after another. The sequences are candidate funnels, listed by
+the number of sessions they occur in. Sequences contained in a longer sequence
+occurring in as many sessions are left out.
+
+Sessions are split like in the sessions command, by the --session-key event
+property and --idle-timeout. Events are the "event" field of structured entries,
+or the full message otherwise.
+
+With --output yaml the candidates are printed as a funnel config, to bootstrap
+funnels from real traffic:
+
+Examples:
+  loglion discover -p parser.yaml -l events.log --session-key user_id
+  loglion discover -p parser.yaml -l events.log --session-key session_id --min-support 25 --min-length 3
+  loglion discover -p parser.yaml -l events.log --session-key user_id -n 3 -o yaml > funnels.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+		sessionKey, _ := cmd.Flags().GetString("session-key")
+		idleTimeout, _ := cmd.Flags().GetDuration("idle-timeout")
+		minSupport, _ := cmd.Flags().GetFloat64("min-support")
+		minLength, _ := cmd.Flags().GetInt("min-length")
+		maxLength, _ := cmd.Flags().GetInt("max-length")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":     logPatterns,
+			"output_format": outputFormat,
+			"session_key":   sessionKey,
+			"min_support":   minSupport,
+		}).Info("Starting sequence discovery")
+
+		sessions, err := analyzer.NewSessionAnalyzer(sessionKey, idleTimeout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		discoverAnalyzer, err := analyzer.NewDiscoverAnalyzer(sessions, analyzer.DiscoverOptions{
+			MinSupport: minSupport,
+			MinLength:  minLength,
+			MaxLength:  maxLength,
+			Limit:      limit,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		outputOptions := output.Options{MaxNameWidth: maxNameWidth}
+		if err := outputOptions.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries := loadLogEntries(cmd)
+
+		logrus.Debug("Starting sequence discovery")
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := discoverAnalyzer.AnalyzeDiscoverContext(ctx, entries)
+		stop()
+
+		// Format and output results
+		var formattedOutput string
+		if outputFormat == "yaml" {
+			formattedOutput, err = formatDiscoveredFunnels(result)
+		} else {
+			formattedOutput, err = newOutputFormatter(outputFormat, outputOptions).FormatDiscover(result)
+		}
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format discover output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Sequence discovery completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+// formatDiscoveredFunnels renders the discovered sequences as a funnel config
+// with one funnel per sequence, grouped by the session key
+func formatDiscoveredFunnels(result *analyzer.DiscoverResult) (string, error) {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("# %d candidate funnels from %d sessions, occurring in at least %d sessions each\n",
+		len(result.Sequences), result.SessionCount, result.MinSupport))
+	if len(result.Sequences) == 0 {
+		return out.String(), nil
+	}
+
+	file := struct {
+		Funnels []config.FunnelConfig `yaml:"funnels"`
+	}{}
+	for _, sequence := range result.Sequences {
+		funnel := config.FunnelConfig{
+			Name:    fmt.Sprintf("%s (%d sessions)", strings.Join(sequence.Events, " → "), sequence.Support),
+			GroupBy: result.SessionKey,
+		}
+		occurrences := make(map[string]int)
+		for _, event := range sequence.Events {
+			occurrences[event]++
+			name := event
+			if occurrences[event] > 1 {
+				name = fmt.Sprintf("%s (%d)", event, occurrences[event])
+			}
+			funnel.Steps = append(funnel.Steps, config.Step{
+				Name:         name,
+				EventPattern: "^" + regexp.QuoteMeta(event) + "$",
+			})
+		}
+		file.Funnels = append(file.Funnels, funnel)
+	}
+
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal funnel config: %w", err)
+	}
+	out.Write(data)
+	return out.String(), nil
+}
+
+func init() {
+	rootCmd.AddCommand(discoverCmd)
+
+	addLogInputFlags(discoverCmd)
+	discoverCmd.Flags().StringP("output", "o", "text", "Output format (json, text, yaml for a funnel config)")
+	discoverCmd.Flags().Int("max-name-width", 0, "Truncate event names longer than this in text output (0 = no limit)")
+
+	discoverCmd.Flags().String("session-key", "", "Event property that identifies a session, e.g. session_id (default: whole log)")
+	discoverCmd.Flags().Duration("idle-timeout", 30*time.Minute, "Start a new session after this long without events (0 to disable)")
+	discoverCmd.Flags().Float64("min-support", 10, "Percentage of sessions a sequence must occur in")
+	discoverCmd.Flags().Int("min-length", 2, "Minimum number of events of a sequence")
+	discoverCmd.Flags().Int("max-length", 5, "Maximum number of events of a sequence")
+	discoverCmd.Flags().IntP("limit", "n", 10, "Number of sequences to report (0 = all sequences)")
+}
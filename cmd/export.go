@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/parfenovvs/loglion/internal/export"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export parsed log entries as CSV or Parquet",
+	Long: `Export command writes the parsed log entries as a table, one row per entry, for
+analysis in tools such as pandas, DuckDB or BigQuery. The columns are the timestamp,
+level, tag, pid, tid, event and message of each entry, followed by the event data
+properties named with --properties. Nested properties are selected with dots, e.g.
+properties.cart.total, and values that are not strings are written as JSON.
+
+Missing values are empty in CSV and null in Parquet. Parquet files are written
+uncompressed, with timestamps as microseconds since the epoch. Set assume_year in
+the parser config for logs whose timestamps have no year, such as logcat. Entry
+filters such as --level and --tag apply as in the other commands.
+
+Examples:
+  loglion export -p parser.yaml -l logcat.txt > events.csv
+  loglion export -p parser.yaml -l logcat.txt --properties screen,user_id -O events.csv
+  loglion export -p parser.yaml -l "logs/*.txt" --format parquet -O events.parquet`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		format, _ := cmd.Flags().GetString("format")
+		properties, _ := cmd.Flags().GetStringSlice("properties")
+		outputFile, _ := cmd.Flags().GetString("output-file")
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":   logPatterns,
+			"format":      format,
+			"properties":  properties,
+			"output_file": outputFile,
+		}).Info("Starting export")
+
+		if format != export.CSVFormat && format != export.ParquetFormat {
+			fmt.Fprintf(os.Stderr, "Error: unsupported export format: %s (supported: csv, parquet)\n", format)
+			os.Exit(1)
+		}
+		toStdout := outputFile == "" || outputFile == "-"
+		if format == export.ParquetFormat && toStdout && isTerminal(os.Stdout) {
+			fmt.Fprintf(os.Stderr, "Error: parquet export requires --output-file or redirected output\n")
+			os.Exit(1)
+		}
+
+		entries := loadLogEntries(cmd)
+
+		var buf bytes.Buffer
+		if err := export.Write(&buf, format, entries, properties); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := writeOutputTo(outputFile, buf.String()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"entries": len(entries),
+			"bytes":   buf.Len(),
+		}).Info("Export completed successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	addLogInputFlags(exportCmd)
+	exportCmd.Flags().String("format", export.CSVFormat, "Export format (csv, parquet)")
+	exportCmd.Flags().StringSlice("properties", nil, "Event data properties to add as columns, nested with dots (repeatable)")
+	exportCmd.Flags().StringP("output-file", "O", "", "Write the export to this file instead of stdout")
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var extractCmd = &cobra.Command{
+	Use:   "extract [event_patterns...]",
+	Short: "Print the log entries matching event patterns or a funnel step",
+	Long: `Extract command prints the log entries matching any of the given event patterns,
+or the step of a funnel config named with --step, exactly as they appear in the log.
+Patterns are matched like those of the count command. Use --context to include the
+entries before and after each match, and --output json for the entries with their
+parsed fields, e.g. to attach the log lines behind a funnel failure to a bug report.
+
+Examples:
+  loglion extract -p parser.yaml -l logcat.txt "purchase_failed"
+  loglion extract -p parser.yaml -l logcat.txt --context 5 "error" "timeout"
+  loglion extract -p parser.yaml -l logcat.txt -f funnel.yaml --step "Checkout"
+  loglion extract -p parser.yaml -l logcat.txt --output json "purchase"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		funnelConfigFile, _ := cmd.Flags().GetString("funnel-config")
+		stepName, _ := cmd.Flags().GetString("step")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		contextEntries, _ := cmd.Flags().GetInt("context")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":      logPatterns,
+			"output_format":  outputFormat,
+			"event_patterns": args,
+			"step":           stepName,
+			"context":        contextEntries,
+		}).Info("Starting extraction")
+
+		var extractor *analyzer.Extractor
+		var err error
+		switch {
+		case stepName != "" && len(args) > 0:
+			err = fmt.Errorf("event patterns cannot be combined with --step")
+		case stepName != "":
+			extractor, err = newStepExtractor(funnelConfigFile, stepName, contextEntries)
+		case len(args) > 0:
+			extractor, err = analyzer.NewPatternExtractor(args, ignoreCase, contextEntries)
+		default:
+			err = fmt.Errorf("event patterns or --step are required")
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries, _ := readLogEntries(cmd, true)
+
+		logrus.Debug("Starting extraction")
+		// Stop extraction on Ctrl+C and still print the entries extracted so far
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := extractor.ExtractContext(ctx, entries)
+		stop()
+
+		if result.Partial {
+			fmt.Fprintf(os.Stderr, "Warning: extraction was cancelled after %d of %d entries\n", result.TotalEventsAnalyzed, len(entries))
+		}
+		if result.Matches == 0 {
+			fmt.Fprintf(os.Stderr, "No entries matched\n")
+		}
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, output.Options{})
+
+		logrus.Debug("Formatting extracted entries")
+		formattedOutput, err := formatter.FormatExtract(result)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format extract output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Extraction completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+// newStepExtractor loads the funnel config file and creates an extractor for
+// the step named stepName of the first funnel that has one
+func newStepExtractor(funnelConfigFile, stepName string, contextEntries int) (*analyzer.Extractor, error) {
+	if funnelConfigFile == "" {
+		return nil, fmt.Errorf("--step requires --funnel-config")
+	}
+	funnels, err := config.LoadFunnelConfigs(funnelConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, funnel := range funnels {
+		for _, step := range funnel.Steps {
+			if step.Name == stepName {
+				return analyzer.NewStepExtractor(funnel, stepName, contextEntries)
+			}
+		}
+	}
+	return nil, fmt.Errorf("no funnel in '%s' has a step named '%s'", funnelConfigFile, stepName)
+}
+
+func init() {
+	rootCmd.AddCommand(extractCmd)
+
+	addLogInputFlags(extractCmd)
+	extractCmd.Flags().StringP("funnel-config", "f", "", "Path to funnel configuration file defining the step of --step")
+	extractCmd.Flags().String("step", "", "Extract the entries matching this funnel step instead of event patterns")
+	extractCmd.Flags().IntP("context", "C", 0, "Number of entries to print before and after each match")
+	extractCmd.Flags().BoolP("ignore-case", "i", false, "Match patterns regardless of case")
+	extractCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+}
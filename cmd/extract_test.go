@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractCommandFlags(t *testing.T) {
+	cmd := extractCmd
+
+	expectedFlags := map[string]struct {
+		shorthand  string
+		valueType  string
+		defaultVal string
+	}{
+		"parser-config":     {"p", "string", ""},
+		"config":            {"c", "string", ""},
+		"log":               {"l", "stringSlice", "[]"},
+		"funnel-config":     {"f", "string", ""},
+		"step":              {"", "string", ""},
+		"context":           {"C", "int", "0"},
+		"ignore-case":       {"i", "bool", "false"},
+		"tag":               {"", "stringSlice", "[]"},
+		"output":            {"o", "string", "text"},
+		"parse-stats":       {"", "bool", "false"},
+		"strict":            {"", "bool", "false"},
+		"workers":           {"", "int", "1"},
+		"max-line-bytes":    {"", "int", "1048576"},
+		"sort-by-timestamp": {"", "bool", "false"},
+		"dedupe":            {"", "bool", "false"},
+		"dedupe-window":     {"", "duration", "1s"},
+		"dedupe-key":        {"", "stringSlice", "[]"},
+		"monotonicize":      {"", "bool", "false"},
+		"keep-duplicates":   {"", "bool", "false"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Shorthand != expected.shorthand {
+			t.Errorf("Expected %s shorthand to be %q, got %q", flagName, expected.shorthand, flag.Shorthand)
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+}
+
+func TestExtractCommandProperties(t *testing.T) {
+	cmd := extractCmd
+
+	if cmd.Use != "extract [event_patterns...]" {
+		t.Errorf("Expected Use to be 'extract [event_patterns...]', got %q", cmd.Use)
+	}
+
+	if !strings.Contains(cmd.Long, "Examples:") {
+		t.Error("Expected Long description to contain examples")
+	}
+
+	if cmd.Run == nil {
+		t.Error("Run function should not be nil")
+	}
+
+	for _, required := range []string{"parser-config", "log"} {
+		flag := cmd.Flags().Lookup(required)
+		if flag == nil || len(flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]) == 0 {
+			t.Errorf("Expected %s flag to be required", required)
+		}
+	}
+}
+
+func TestNewStepExtractor(t *testing.T) {
+	funnelPath := filepath.Join(t.TempDir(), "funnel.yaml")
+	funnelConfig := "name: Flow\nsteps:\n  - name: Login\n    event_pattern: login\n"
+	if err := os.WriteFile(funnelPath, []byte(funnelConfig), 0644); err != nil {
+		t.Fatalf("Failed to write funnel config: %v", err)
+	}
+
+	if _, err := newStepExtractor("", "Login", 0); err == nil || !strings.Contains(err.Error(), "--step requires --funnel-config") {
+		t.Errorf("Expected missing funnel config error, got %v", err)
+	}
+	if _, err := newStepExtractor(funnelPath, "Login", 0); err != nil {
+		t.Errorf("newStepExtractor() unexpected error: %v", err)
+	}
+	if _, err := newStepExtractor(funnelPath, "Checkout", 0); err == nil || !strings.Contains(err.Error(), "has a step named 'Checkout'") {
+		t.Errorf("Expected unknown step error, got %v", err)
+	}
+}
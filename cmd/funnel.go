@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/parfenovvs/loglion/internal/analyzer"
 	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/metrics"
+	"github.com/parfenovvs/loglion/internal/otlp"
 	"github.com/parfenovvs/loglion/internal/output"
 	"github.com/parfenovvs/loglion/internal/parser"
 	"github.com/sirupsen/logrus"
@@ -18,20 +25,80 @@ var funnelCmd = &cobra.Command{
 	Long: `Funnel command processes log files according to the funnel configuration
 and outputs completion rates and drop-off analysis.
 
+The command exits with code 2 when --fail-on-incomplete or --min-conversion-rate
+is given and the funnel does not meet the requirement, or when a step misses
+the min_count or min_conversion_pct threshold declared in the funnel config.
+
 Examples:
   loglion funnel --parser-config parser.yaml --funnel-config funnel.yaml --log logcat.txt
-  loglion funnel -p parser.yaml -f funnel.yaml -l logcat.txt --limit 5`,
+  loglion funnel -c loglion.yaml -l logcat.txt
+  loglion funnel -p parser.yaml -f funnel.yaml -l logcat.txt --limit 5
+  loglion funnel -p parser.yaml -f funnel.yaml -l logcat.txt --fail-on-incomplete --min-conversion-rate 80
+  loglion funnel -p parser.yaml -f funnel.yaml --label device=pixel7:logs/pixel7/*.txt --label device=s23:logs/s23/*.txt
+
+With --label, the log files of each label are analyzed separately and the results
+are shown side by side in a comparison table.
+
+With --otlp-endpoint, every conversion is also sent as a trace to an
+OpenTelemetry collector over OTLP/HTTP, with one span per step covering the
+time from the previous step.
+
+With --watch, the analysis runs again whenever a log file changes and the results
+are redrawn, e.g. while reproducing a bug with logcat redirected to the file.
+Failed checks are reported but do not stop watching. With --metrics-addr, the step
+counts and conversion ratios of the latest analysis are also served at /metrics in
+Prometheus format.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		parserConfigFile, _ := cmd.Flags().GetString("parser-config")
 		funnelConfigFile, _ := cmd.Flags().GetString("funnel-config")
-		logFile, _ := cmd.Flags().GetString("log")
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		labels, _ := cmd.Flags().GetStringArray("label")
+		sortByMTime, _ := cmd.Flags().GetBool("sort-by-mtime")
 		outputFormat, _ := cmd.Flags().GetString("output")
+		outputOptions, err := outputOptionsFromFlags(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		outputTargets, err := outputTargetsFromFlags(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 		limit, _ := cmd.Flags().GetInt("limit")
+		failOnIncomplete, _ := cmd.Flags().GetBool("fail-on-incomplete")
+		minConversionRate, _ := cmd.Flags().GetFloat64("min-conversion-rate")
+		samples, _ := cmd.Flags().GetInt("show-samples")
+		showConversions, _ := cmd.Flags().GetBool("show-conversions")
+		watch, _ := cmd.Flags().GetBool("watch")
+		metricsAddr, _ := cmd.Flags().GetString("metrics-addr")
+		if samples < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --show-samples cannot be negative, got %d\n", samples)
+			os.Exit(1)
+		}
+		exporter, err := otlpExporterFromFlags(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if watch && exporter != nil {
+			fmt.Fprintf(os.Stderr, "Error: --watch cannot be combined with --otlp-endpoint\n")
+			os.Exit(1)
+		}
+		if metricsAddr != "" && !watch {
+			fmt.Fprintf(os.Stderr, "Error: --metrics-addr requires --watch\n")
+			os.Exit(1)
+		}
+		var recorder *otlp.Recorder
+		if exporter != nil {
+			recorder = otlp.NewRecorder()
+		}
 
 		logrus.WithFields(logrus.Fields{
 			"parser_config_file": parserConfigFile,
 			"funnel_config_file": funnelConfigFile,
-			"log_file":           logFile,
+			"log_files":          logPatterns,
+			"labels":             labels,
 			"output_format":      outputFormat,
 			"limit":              limit,
 		}).Info("Starting funnel analysis")
@@ -45,60 +112,264 @@ Examples:
 			os.Exit(1)
 		}
 
+		entryFilter, err := entryFilterFromFlags(cmd, parserCfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 		// Load funnel configuration
 		logrus.Debug("Loading funnel configuration file")
-		funnelCfg, err := config.LoadFunnelConfig(funnelConfigFile)
+		funnelCfgs, err := config.LoadFunnelConfigs(funnelConfigFile)
 		if err != nil {
 			logrus.WithError(err).WithField("funnel_config_file", funnelConfigFile).Error("Failed to load funnel config")
 			fmt.Fprintf(os.Stderr, "Error loading funnel config: %v\n", err)
 			os.Exit(1)
 		}
+		for _, funnelCfg := range funnelCfgs {
+			printConfigWarnings(funnelCfg.Warnings(parserCfg))
+		}
 
-		// Create parser
-		logrus.Debug("Creating log parser")
-		logParser := parser.NewParserWithConfig(
-			parserCfg.TimestampFormat,
-			parserCfg.EventRegex,
-			parserCfg.JSONExtraction,
-			parserCfg.LogLineRegex)
+		var metricsExporter *metrics.Exporter
+		if metricsAddr != "" {
+			var closeMetrics func()
+			if metricsExporter, closeMetrics, err = serveMetrics(metricsAddr); err != nil {
+				fmt.Fprintf(os.Stderr, "Error starting metrics server: %v\n", err)
+				os.Exit(1)
+			}
+			defer closeMetrics()
+		}
 
-		// Create analyzer
-		logrus.Debug("Creating funnel analyzer")
-		funnelAnalyzer := analyzer.NewFunnelAnalyzer(funnelCfg)
+		inputs := []labeledInput{{Patterns: logPatterns}}
+		if len(labels) > 0 {
+			if inputs, err = parseLabels(labels); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
-		// Parse log file
-		logrus.WithField("log_file", logFile).Debug("Starting log file parsing")
-		entries, err := logParser.ParseFile(logFile)
-		if err != nil {
-			logrus.WithError(err).WithField("log_file", logFile).Error("Failed to parse log file")
-			fmt.Fprintf(os.Stderr, "Error parsing log file: %v\n", err)
-			os.Exit(1)
+		// analyze parses and analyzes the log files of every label and outputs the
+		// results, returning the exit code
+		analyze := func(ctx context.Context) int {
+			// Create parser
+			logrus.Debug("Creating log parser")
+			logParser, err := parserCfg.NewParser()
+			if err == nil {
+				err = configureParser(cmd, logParser)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating log parser: %v\n", err)
+				return 1
+			}
+
+			labeled := make([]analyzer.LabeledResult, len(inputs))
+			entryCount := 0
+			for i, input := range inputs {
+				// Parse log file
+				logFiles, err := parser.ResolveLogFiles(input.Patterns, sortByMTime)
+				if err == nil {
+					logFiles, err = dedupeLogFiles(cmd, logFiles)
+				}
+				if err != nil {
+					logrus.WithError(err).WithField("log_files", input.Patterns).Error("Failed to resolve log files")
+					fmt.Fprintf(os.Stderr, "Error resolving log files: %v\n", err)
+					return 1
+				}
+
+				logrus.WithFields(logrus.Fields{
+					"label":     input.Label,
+					"log_files": logFiles,
+				}).Debug("Starting log file parsing")
+				entries, err := parseLogFiles(cmd, logParser, logFiles)
+				if err != nil {
+					logrus.WithError(err).WithField("log_files", logFiles).Error("Failed to parse log file")
+					fmt.Fprintf(os.Stderr, "Error parsing log file: %v\n", err)
+					return 1
+				}
+				entries, err = dedupeEntries(cmd, parser.FilterEntries(entries, entryFilter))
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					return 1
+				}
+
+				entryCount += len(entries)
+
+				logrus.WithField("funnel_count", len(funnelCfgs)).Debug("Starting funnel analysis")
+				labeled[i] = analyzer.LabeledResult{Label: input.Label, Funnels: analyzeFunnels(ctx, funnelCfgs, entries, analyzer.FunnelOptions{Samples: samples, ConversionSteps: showConversions, Limit: limit}, recorder)}
+				if ctx.Err() != nil {
+					// Report the labels analyzed so far
+					labeled = labeled[:i+1]
+					break
+				}
+			}
+			reportParseStats(cmd, logParser)
+			if metricsExporter != nil {
+				metricsExporter.Update(&metrics.Snapshot{
+					EntriesParsed: entryCount,
+					Funnels:       allFunnelResults(labeled),
+					UpdatedAt:     time.Now(),
+				})
+			}
+
+			// Format and output results
+			logrus.Debug("Formatting analysis results")
+			err = emitOutput(outputTargets, outputOptions, func(formatter output.Formatter) (string, error) {
+				if len(labels) > 0 {
+					return formatter.FormatLabeled(analyzer.NewLabeledReport(labeled))
+				}
+				return formatFunnels(formatter, labeled[0].Funnels)
+			})
+			if err != nil {
+				logrus.WithError(err).Error("Failed to output analysis results")
+				fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+				return 1
+			}
+			logrus.Info("Analysis completed successfully")
+
+			if exporter != nil {
+				exported, err := exporter.Export(context.Background(), recorder.Traces())
+				if err != nil {
+					logrus.WithError(err).Error("Failed to export traces")
+					fmt.Fprintf(os.Stderr, "Error exporting traces: %v\n", err)
+					return 1
+				}
+				fmt.Fprintf(os.Stderr, "Exported %d of %d conversions as traces\n", exported, len(recorder.Traces()))
+			}
+
+			policyFailed := false
+			for _, result := range allFunnelResults(labeled) {
+				if err := checkFunnelPolicy(result, failOnIncomplete, minConversionRate); err != nil {
+					logrus.WithError(err).Info("Funnel did not meet exit-code policy")
+					fmt.Fprintf(os.Stderr, "Funnel check failed: %v\n", err)
+					policyFailed = true
+				}
+				for _, assertion := range result.FailedAssertions() {
+					fmt.Fprintf(os.Stderr, "Assertion failed in funnel '%s': %s\n", result.FunnelName, assertion)
+					policyFailed = true
+				}
+			}
+			if policyFailed {
+				return 2
+			}
+			return 0
+		}
+
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if watch {
+			var watchPatterns []string
+			for _, input := range inputs {
+				watchPatterns = append(watchPatterns, input.Patterns...)
+			}
+			if err := watchLogFiles(ctx, watchPatterns, analyze); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if code := analyze(ctx); code != 0 {
+			os.Exit(code)
 		}
+	},
+}
+
+// labeledInput is a set of log files analyzed under one label
+type labeledInput struct {
+	Label    string
+	Patterns []string
+}
 
-		logrus.Debug("Starting funnel analysis")
-		result := funnelAnalyzer.AnalyzeFunnel(entries, limit)
+// parseLabels parses --label values of the form key=value:pattern. Values
+// with the same label add patterns to it; labels keep their first-seen order.
+func parseLabels(values []string) ([]labeledInput, error) {
+	var inputs []labeledInput
+	index := make(map[string]int)
 
-		// Format and output results
-		logrus.WithField("output_format", outputFormat).Debug("Creating output formatter")
-		var formatter output.Formatter
-		switch outputFormat {
-		case "json":
-			formatter = output.NewFormatter(output.JSONFormat)
-		default:
-			formatter = output.NewFormatter(output.TextFormat)
+	for _, value := range values {
+		label, pattern, found := strings.Cut(value, ":")
+		key, name, isPair := strings.Cut(label, "=")
+		if !found || !isPair || key == "" || name == "" || pattern == "" {
+			return nil, fmt.Errorf("invalid label '%s' (expected key=value:pattern)", value)
 		}
 
-		logrus.Debug("Formatting analysis results")
-		formattedOutput, err := formatter.FormatFunnel(result)
-		if err != nil {
-			logrus.WithError(err).Error("Failed to format analysis output")
-			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
-			os.Exit(1)
+		if i, exists := index[label]; exists {
+			inputs[i].Patterns = append(inputs[i].Patterns, pattern)
+			continue
 		}
+		index[label] = len(inputs)
+		inputs = append(inputs, labeledInput{Label: label, Patterns: []string{pattern}})
+	}
 
-		logrus.WithField("output_length", len(formattedOutput)).Info("Analysis completed successfully")
-		fmt.Print(formattedOutput)
-	},
+	return inputs, nil
+}
+
+// allFunnelResults returns the funnel results of all labels
+func allFunnelResults(labeled []analyzer.LabeledResult) []*analyzer.FunnelResult {
+	var results []*analyzer.FunnelResult
+	for _, result := range labeled {
+		results = append(results, result.Funnels...)
+	}
+	return results
+}
+
+// analyzeFunnels runs every configured funnel over the same parsed entries.
+// A non-nil recorder records the conversions of every funnel.
+func analyzeFunnels(ctx context.Context, funnelCfgs []*config.FunnelConfig, entries []*parser.LogEntry, options analyzer.FunnelOptions, recorder *otlp.Recorder) []*analyzer.FunnelResult {
+	results := make([]*analyzer.FunnelResult, len(funnelCfgs))
+	for i, funnelCfg := range funnelCfgs {
+		logrus.WithField("funnel_name", funnelCfg.Name).Debug("Creating funnel analyzer")
+		if recorder != nil {
+			options.Hooks = recorder.Hooks(funnelCfg.Name)
+		}
+		results[i] = analyzer.NewFunnelAnalyzerWithOptions(funnelCfg, options).Analyze(ctx, entries)
+	}
+	return results
+}
+
+// formatFunnels renders a single funnel as before and several funnels as a combined report
+func formatFunnels(formatter output.Formatter, results []*analyzer.FunnelResult) (string, error) {
+	if len(results) == 1 {
+		return formatter.FormatFunnel(results[0])
+	}
+	return formatter.FormatFunnels(results)
+}
+
+// otlpExporterFromFlags creates the trace exporter requested with
+// --otlp-endpoint, or returns nil without it
+func otlpExporterFromFlags(cmd *cobra.Command) (*otlp.Exporter, error) {
+	endpoint, _ := cmd.Flags().GetString("otlp-endpoint")
+	headerValues, _ := cmd.Flags().GetStringArray("otlp-header")
+	serviceName, _ := cmd.Flags().GetString("otlp-service-name")
+	if endpoint == "" {
+		if len(headerValues) > 0 {
+			return nil, fmt.Errorf("--otlp-header requires --otlp-endpoint")
+		}
+		return nil, nil
+	}
+
+	headers := make(map[string]string, len(headerValues))
+	for _, value := range headerValues {
+		key, headerValue, found := strings.Cut(value, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid OTLP header '%s' (expected key=value)", value)
+		}
+		headers[strings.TrimSpace(key)] = headerValue
+	}
+	return otlp.NewExporter(endpoint, headers, serviceName)
+}
+
+// checkFunnelPolicy returns an error when the result violates the requested exit-code policy
+func checkFunnelPolicy(result *analyzer.FunnelResult, failOnIncomplete bool, minConversionRate float64) error {
+	if failOnIncomplete && !result.FunnelCompleted {
+		return fmt.Errorf("funnel '%s' was not completed", result.FunnelName)
+	}
+
+	if minConversionRate > 0 && result.ConversionRate() < minConversionRate {
+		return fmt.Errorf("conversion rate %.1f%% is below the required %.1f%%", result.ConversionRate(), minConversionRate)
+	}
+
+	return nil
 }
 
 func init() {
@@ -106,11 +377,32 @@ func init() {
 
 	funnelCmd.Flags().StringP("parser-config", "p", "", "Path to parser configuration file (required)")
 	funnelCmd.Flags().StringP("funnel-config", "f", "", "Path to funnel configuration file (required)")
-	funnelCmd.Flags().StringP("log", "l", "", "Path to log file (required)")
-	funnelCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	addConfigFlag(funnelCmd)
+	funnelCmd.Flags().StringSliceP("log", "l", nil, "Path or glob pattern of log files, merged in order (required unless --label is given, repeatable)")
+	funnelCmd.Flags().StringArray("label", nil, "Analyze log files separately per label and compare them, as key=value:pattern (repeatable)")
+	addLogReadingFlags(funnelCmd)
+	funnelCmd.Flags().StringP("output", "o", "text", "Output format (json, text, html, template, sankey), or format=path pairs to write several, e.g. json=result.json,text=-")
+	funnelCmd.Flags().String("output-file", "", "Write the formatted results to this file instead of stdout")
+	funnelCmd.Flags().String("template-file", "", "Go text/template file rendering the results with --output template")
+	funnelCmd.Flags().StringSlice("only", nil, "Only output these sections (summary, steps, chart, drop_offs, anomalies, exclusions, timings, assertions, counts, overlaps, groups, sessions, distinct, aggregate, conversions)")
+	funnelCmd.Flags().StringSlice("hide", nil, "Hide these sections, or 'zero-count' to hide steps and patterns without matches")
+	funnelCmd.Flags().Int("max-name-width", 0, "Truncate step and pattern names longer than this in text output (0 = no limit)")
+	funnelCmd.Flags().Bool("table", false, "Render step and pattern breakdowns as tables with aligned columns in text output")
+	funnelCmd.Flags().Bool("borders", false, "Frame text output tables with unicode borders (implies --table)")
+	funnelCmd.Flags().Bool("wide", false, "Add first and last match times and the median time to each step to text output tables (implies --table)")
+	funnelCmd.Flags().Int("show-samples", 0, "Show the first and last N entries that matched each step (0 = none)")
+	funnelCmd.Flags().Bool("show-conversions", false, "List every conversion with the entry that matched each step")
 	funnelCmd.Flags().Int("limit", 0, "Maximum number of successful funnels to analyze (0 = analyze all funnels)")
+	funnelCmd.Flags().Bool("fail-on-incomplete", false, "Exit with code 2 if the funnel was not completed")
+	funnelCmd.Flags().Float64("min-conversion-rate", 0, "Exit with code 2 if the conversion rate to the last step is below this percentage")
+	funnelCmd.Flags().Bool("watch", false, "Analyze again whenever a log file changes, e.g. while logcat is redirected to it, until Ctrl+C")
+	funnelCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics of the latest analysis at /metrics on this address with --watch (e.g. :9090)")
+	funnelCmd.Flags().String("otlp-endpoint", "", "Send every conversion as a trace to this OTLP/HTTP endpoint, e.g. http://localhost:4318")
+	funnelCmd.Flags().StringArray("otlp-header", nil, "Header added to OTLP requests, as key=value (repeatable)")
+	funnelCmd.Flags().String("otlp-service-name", otlp.DefaultServiceName, "Service name of the exported traces")
 
 	funnelCmd.MarkFlagRequired("parser-config")
 	funnelCmd.MarkFlagRequired("funnel-config")
-	funnelCmd.MarkFlagRequired("log")
+	funnelCmd.MarkFlagsOneRequired("log", "label")
+	funnelCmd.MarkFlagsMutuallyExclusive("log", "label")
 }
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/parfenovvs/loglion/internal/analyzer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -45,7 +47,7 @@ func TestFunnelCommandFlags(t *testing.T) {
 		if logFlag.Shorthand != "l" {
 			t.Errorf("Expected log shorthand to be 'l', got %q", logFlag.Shorthand)
 		}
-		if logFlag.Usage != "Path to log file (required)" {
+		if logFlag.Usage != "Path or glob pattern of log files, merged in order (required unless --label is given, repeatable)" {
 			t.Errorf("Expected log usage description mismatch")
 		}
 	}
@@ -58,7 +60,7 @@ func TestFunnelCommandFlags(t *testing.T) {
 		if outputFlag.Shorthand != "o" {
 			t.Errorf("Expected output shorthand to be 'o', got %q", outputFlag.Shorthand)
 		}
-		if outputFlag.Usage != "Output format (json, text)" {
+		if outputFlag.Usage != "Output format (json, text, html, template, sankey), or format=path pairs to write several, e.g. json=result.json,text=-" {
 			t.Errorf("Expected output usage description mismatch")
 		}
 		if outputFlag.DefValue != "text" {
@@ -78,6 +80,48 @@ func TestFunnelCommandFlags(t *testing.T) {
 			t.Errorf("Expected limit default value to be '0', got %q", limitFlag.DefValue)
 		}
 	}
+
+	// Test show-samples flag
+	samplesFlag := cmd.Flags().Lookup("show-samples")
+	if samplesFlag == nil {
+		t.Error("Expected show-samples flag to exist")
+	} else if samplesFlag.Value.Type() != "int" || samplesFlag.DefValue != "0" {
+		t.Errorf("Expected show-samples to be an int defaulting to 0, got %s %q", samplesFlag.Value.Type(), samplesFlag.DefValue)
+	}
+
+	// Test show-conversions flag
+	conversionsFlag := cmd.Flags().Lookup("show-conversions")
+	if conversionsFlag == nil {
+		t.Error("Expected show-conversions flag to exist")
+	} else if conversionsFlag.Value.Type() != "bool" || conversionsFlag.DefValue != "false" {
+		t.Errorf("Expected show-conversions to be a bool defaulting to false, got %s %q", conversionsFlag.Value.Type(), conversionsFlag.DefValue)
+	}
+
+	// Test otlp-header flag
+	headerFlag := cmd.Flags().Lookup("otlp-header")
+	if headerFlag == nil {
+		t.Error("Expected otlp-header flag to exist")
+	} else if headerFlag.Value.Type() != "stringArray" {
+		t.Errorf("Expected otlp-header to be a stringArray, got %s", headerFlag.Value.Type())
+	}
+
+	// Test watch flag
+	watchFlag := cmd.Flags().Lookup("watch")
+	if watchFlag == nil {
+		t.Error("Expected watch flag to exist")
+	} else if watchFlag.Value.Type() != "bool" || watchFlag.DefValue != "false" {
+		t.Errorf("Expected watch to be a bool defaulting to false, got %s %q", watchFlag.Value.Type(), watchFlag.DefValue)
+	}
+
+	// Test table layout flags
+	for _, name := range []string{"table", "borders", "wide"} {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", name)
+		} else if flag.Value.Type() != "bool" || flag.DefValue != "false" {
+			t.Errorf("Expected %s to be a bool defaulting to false, got %s %q", name, flag.Value.Type(), flag.DefValue)
+		}
+	}
 }
 
 func TestFunnelCommandProperties(t *testing.T) {
@@ -112,15 +156,15 @@ func TestFunnelCommandRequiredFlags(t *testing.T) {
 	cmd := funnelCmd
 
 	// Check if required flags are marked as required
-	requiredFlags := []string{"parser-config", "funnel-config", "log"}
-	
+	requiredFlags := []string{"parser-config", "funnel-config"}
+
 	for _, flagName := range requiredFlags {
 		flag := cmd.Flags().Lookup(flagName)
 		if flag == nil {
 			t.Errorf("Required flag %s not found", flagName)
 			continue
 		}
-		
+
 		// Check if flag is in required flags list
 		requiredAnnotation := flag.Annotations[cobra.BashCompOneRequiredFlag]
 		if len(requiredAnnotation) == 0 {
@@ -129,15 +173,58 @@ func TestFunnelCommandRequiredFlags(t *testing.T) {
 	}
 }
 
+func TestFunnelCommandLogOrLabelRequired(t *testing.T) {
+	for _, flagName := range []string{"log", "label"} {
+		flag := funnelCmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Flag %s not found", flagName)
+			continue
+		}
+		if got := flag.Annotations["cobra_annotation_one_required"]; len(got) != 1 || got[0] != "log label" {
+			t.Errorf("Expected %s to be in the log/label one-required group, got %v", flagName, got)
+		}
+		if got := flag.Annotations["cobra_annotation_mutually_exclusive"]; len(got) != 1 || got[0] != "log label" {
+			t.Errorf("Expected %s to be mutually exclusive with the other, got %v", flagName, got)
+		}
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	inputs, err := parseLabels([]string{
+		"device=pixel7:logs/pixel7/*.txt",
+		"device=s23:logs/s23/*.txt",
+		"device=pixel7:logs/extra.txt",
+	})
+	if err != nil {
+		t.Fatalf("parseLabels() unexpected error: %v", err)
+	}
+
+	expected := []labeledInput{
+		{Label: "device=pixel7", Patterns: []string{"logs/pixel7/*.txt", "logs/extra.txt"}},
+		{Label: "device=s23", Patterns: []string{"logs/s23/*.txt"}},
+	}
+	if !reflect.DeepEqual(inputs, expected) {
+		t.Errorf("parseLabels() = %+v, want %+v", inputs, expected)
+	}
+
+	for _, invalid := range []string{"pixel7:logs/*.txt", "device=pixel7", "=pixel7:logs/*.txt", "device=:logs/*.txt", "device=pixel7:"} {
+		if _, err := parseLabels([]string{invalid}); err == nil {
+			t.Errorf("Expected error for label %q", invalid)
+		}
+	}
+}
+
 func TestFunnelCommandFlagTypes(t *testing.T) {
 	cmd := funnelCmd
 
 	// Test string flags
 	stringFlags := map[string]string{
-		"parser-config": "",
-		"funnel-config": "",
-		"log":           "",
-		"output":        "text",
+		"parser-config":     "",
+		"funnel-config":     "",
+		"config":            "",
+		"output":            "text",
+		"otlp-endpoint":     "",
+		"otlp-service-name": "loglion",
 	}
 
 	for flagName, expectedDefault := range stringFlags {
@@ -146,16 +233,29 @@ func TestFunnelCommandFlagTypes(t *testing.T) {
 			t.Errorf("Flag %s not found", flagName)
 			continue
 		}
-		
+
 		if flag.Value.Type() != "string" {
 			t.Errorf("Expected flag %s to be of type string, got %s", flagName, flag.Value.Type())
 		}
-		
+
 		if flag.DefValue != expectedDefault {
 			t.Errorf("Expected flag %s default value to be %q, got %q", flagName, expectedDefault, flag.DefValue)
 		}
 	}
 
+	// Test log flag accepting multiple files
+	logFlag := cmd.Flags().Lookup("log")
+	if logFlag == nil {
+		t.Error("Log flag not found")
+	} else {
+		if logFlag.Value.Type() != "stringSlice" {
+			t.Errorf("Expected log flag to be of type stringSlice, got %s", logFlag.Value.Type())
+		}
+		if logFlag.DefValue != "[]" {
+			t.Errorf("Expected log flag default value to be '[]', got %q", logFlag.DefValue)
+		}
+	}
+
 	// Test int flag
 	limitFlag := cmd.Flags().Lookup("limit")
 	if limitFlag == nil {
@@ -199,6 +299,7 @@ func TestFunnelCommandFlagShorthands(t *testing.T) {
 	expectedShorthands := map[string]string{
 		"parser-config": "p",
 		"funnel-config": "f",
+		"config":        "c",
 		"log":           "l",
 		"output":        "o",
 	}
@@ -209,7 +310,7 @@ func TestFunnelCommandFlagShorthands(t *testing.T) {
 			t.Errorf("Flag %s not found", flagName)
 			continue
 		}
-		
+
 		if flag.Shorthand != expectedShorthand {
 			t.Errorf("Expected flag %s shorthand to be %q, got %q", flagName, expectedShorthand, flag.Shorthand)
 		}
@@ -223,30 +324,30 @@ func TestFunnelCommandStructure(t *testing.T) {
 	if cmd.Use == "" {
 		t.Error("Command Use should not be empty")
 	}
-	
+
 	if cmd.Short == "" {
 		t.Error("Command Short description should not be empty")
 	}
-	
+
 	if cmd.Long == "" {
 		t.Error("Command Long description should not be empty")
 	}
-	
+
 	if cmd.Run == nil {
 		t.Error("Command Run function should not be nil")
 	}
-	
+
 	// Test that required flags are present
 	flags := cmd.Flags()
 	if flags == nil {
 		t.Error("Command should have flags")
 	}
-	
+
 	flagCount := 0
 	flags.VisitAll(func(flag *pflag.Flag) {
 		flagCount++
 	})
-	
+
 	if flagCount < 5 {
 		t.Errorf("Expected at least 5 flags, got %d", flagCount)
 	}
@@ -271,4 +372,48 @@ func TestFunnelCommandExamples(t *testing.T) {
 			t.Errorf("Expected to find example: %s", example)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestCheckFunnelPolicy(t *testing.T) {
+	completed := &analyzer.FunnelResult{
+		FunnelName:      "Checkout",
+		FunnelCompleted: true,
+		Steps: []analyzer.StepResult{
+			{Name: "View", EventCount: 10, Percentage: 100.0},
+			{Name: "Pay", EventCount: 6, Percentage: 60.0},
+		},
+	}
+	incomplete := &analyzer.FunnelResult{
+		FunnelName:      "Checkout",
+		FunnelCompleted: false,
+		Steps: []analyzer.StepResult{
+			{Name: "View", EventCount: 10, Percentage: 100.0},
+			{Name: "Pay", EventCount: 0, Percentage: 0.0},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		result            *analyzer.FunnelResult
+		failOnIncomplete  bool
+		minConversionRate float64
+		expectError       bool
+	}{
+		{name: "no_policy_incomplete", result: incomplete},
+		{name: "fail_on_incomplete_completed", result: completed, failOnIncomplete: true},
+		{name: "fail_on_incomplete_incomplete", result: incomplete, failOnIncomplete: true, expectError: true},
+		{name: "conversion_rate_met", result: completed, minConversionRate: 60},
+		{name: "conversion_rate_below", result: completed, minConversionRate: 75, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFunnelPolicy(tt.result, tt.failOnIncomplete, tt.minConversionRate)
+			if tt.expectError && err == nil {
+				t.Error("Expected policy violation")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected policy violation: %v", err)
+			}
+		})
+	}
+}
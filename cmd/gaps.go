@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var gapsCmd = &cobra.Command{
+	Use:   "gaps [event_pattern]",
+	Short: "Report gaps between periodic events longer than a threshold",
+	Long: `Gaps command checks a heartbeat-style event pattern, such as a periodic ping of an
+analytics SDK, and reports every period longer than --max-gap without a matching
+event, with its start and end timestamps. This detects when events silently stop
+being sent.
+
+Gaps before the first and after the last matching event are measured from the
+start and to the end of the log, so events that stop for good are reported too.
+Matching entries without a timestamp are counted but cannot be placed.
+
+The command exits with code 2 when --fail-on-gap is set and a gap was found.
+
+Examples:
+  loglion gaps -p parser.yaml -l logcat.txt "session_ping"
+  loglion gaps -p parser.yaml -l logcat.txt --max-gap 5m "heartbeat" --fail-on-gap
+  loglion gaps -p parser.yaml -l logcat.txt --max-gap 30s -o json "ping"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+		maxGap, _ := cmd.Flags().GetDuration("max-gap")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		failOnGap, _ := cmd.Flags().GetBool("fail-on-gap")
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":     logPatterns,
+			"output_format": outputFormat,
+			"event_pattern": args[0],
+			"max_gap":       maxGap,
+		}).Info("Starting gap analysis")
+
+		gapAnalyzer, err := analyzer.NewGapAnalyzer(args[0], maxGap, analyzer.CountOptions{IgnoreCase: ignoreCase})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		outputOptions := output.Options{MaxNameWidth: maxNameWidth}
+		if err := outputOptions.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries := loadLogEntries(cmd)
+
+		logrus.Debug("Starting gap analysis")
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := gapAnalyzer.AnalyzeGapsContext(ctx, entries)
+		stop()
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, outputOptions)
+
+		logrus.Debug("Formatting gap results")
+		formattedOutput, err := formatter.FormatGaps(result)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format gap output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Gap analysis completed successfully")
+		fmt.Print(formattedOutput)
+
+		if failOnGap && len(result.Gaps) > 0 {
+			fmt.Fprintf(os.Stderr, "Gap check failed: %d gaps longer than %s\n", len(result.Gaps), maxGap)
+			os.Exit(2)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gapsCmd)
+
+	addLogInputFlags(gapsCmd)
+	gapsCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	gapsCmd.Flags().Int("max-name-width", 0, "Truncate the pattern name if longer than this in text output (0 = no limit)")
+
+	gapsCmd.Flags().Duration("max-gap", time.Minute, "Report periods without a matching event longer than this (e.g. 30s, 5m)")
+	gapsCmd.Flags().BoolP("ignore-case", "i", false, "Match the pattern regardless of case")
+	gapsCmd.Flags().Bool("fail-on-gap", false, "Exit with code 2 if a gap was found")
+}
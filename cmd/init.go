@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/parfenovvs/loglion/internal/scaffold"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate starter parser and funnel configs",
+	Long: `Init command writes a starter parser.yaml and funnel.yaml for a common log
+format. The generated regexes are checked against sample lines of the format
+before the files are written; edit the funnel steps to match your events.
+
+Presets:
+` + presetList() + `
+Without --preset, the preset is chosen interactively. Existing files are only
+overwritten with --force.
+
+Examples:
+  loglion init
+  loglion init --preset android-logcat
+  loglion init --preset jsonl --dir configs`,
+	Run: func(cmd *cobra.Command, args []string) {
+		presetName, _ := cmd.Flags().GetString("preset")
+		dir, _ := cmd.Flags().GetString("dir")
+		force, _ := cmd.Flags().GetBool("force")
+
+		logrus.WithFields(logrus.Fields{
+			"preset": presetName,
+			"dir":    dir,
+			"force":  force,
+		}).Info("Starting config scaffolding")
+
+		if presetName == "" {
+			if !isTerminal(os.Stdin) {
+				fmt.Fprintf(os.Stderr, "Error: --preset is required when not running interactively (valid: %s)\n", strings.Join(scaffold.Names(), ", "))
+				os.Exit(1)
+			}
+			var err error
+			presetName, err = promptPreset(os.Stdin, os.Stdout)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		preset, ok := scaffold.Lookup(presetName)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: invalid preset '%s' (valid: %s)\n", presetName, strings.Join(scaffold.Names(), ", "))
+			os.Exit(1)
+		}
+
+		paths, err := scaffold.Write(preset, dir, force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, path := range paths {
+			fmt.Printf("Created %s\n", path)
+		}
+		fmt.Printf("\nNext: edit the funnel steps, then run\n  loglion funnel -p %s -f %s -l <log file>\n", paths[0], paths[1])
+	},
+}
+
+// presetList describes the presets, one per line
+func presetList() string {
+	var list strings.Builder
+	for _, preset := range scaffold.Presets() {
+		list.WriteString(fmt.Sprintf("  %-16s %s\n", preset.Name, preset.Description))
+	}
+	return list.String()
+}
+
+// promptPreset asks on out for a preset by number or name until a valid one
+// is read from in, and returns its name
+func promptPreset(in io.Reader, out io.Writer) (string, error) {
+	presets := scaffold.Presets()
+	fmt.Fprintln(out, "Which log format do you want to analyze?")
+	for i, preset := range presets {
+		fmt.Fprintf(out, "  %d. %-16s %s\n", i+1, preset.Name, preset.Description)
+	}
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprintf(out, "Preset [1-%d]: ", len(presets))
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", fmt.Errorf("failed to read preset: %w", err)
+			}
+			return "", fmt.Errorf("no preset chosen")
+		}
+		answer := strings.TrimSpace(scanner.Text())
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(presets) {
+			return presets[n-1].Name, nil
+		}
+		if _, ok := scaffold.Lookup(answer); ok {
+			return answer, nil
+		}
+		fmt.Fprintf(out, "Invalid preset '%s'\n", answer)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().String("preset", "", "Log format preset: "+strings.Join(scaffold.Names(), ", ")+" (prompted when empty)")
+	initCmd.Flags().String("dir", ".", "Directory to write parser.yaml and funnel.yaml to")
+	initCmd.Flags().Bool("force", false, "Overwrite existing config files")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestInitCommandFlags(t *testing.T) {
+	cmd := initCmd
+
+	expectedFlags := map[string]struct {
+		valueType  string
+		defaultVal string
+	}{
+		"preset": {"string", ""},
+		"dir":    {"string", "."},
+		"force":  {"bool", "false"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+}
+
+func TestPromptPreset(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"by number", "2\n", "ios-oslog"},
+		{"by name", "jsonl\n", "jsonl"},
+		{"after invalid answers", "7\nwindows\n1\n", "android-logcat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := promptPreset(strings.NewReader(tt.input), &out)
+			if err != nil {
+				t.Fatalf("promptPreset() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("promptPreset() = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "3. plain") {
+				t.Errorf("Expected the numbered presets in the prompt, got %q", out.String())
+			}
+		})
+	}
+
+	if _, err := promptPreset(strings.NewReader("9\n"), &bytes.Buffer{}); err == nil {
+		t.Error("Expected an error when input ends without a valid preset")
+	}
+}
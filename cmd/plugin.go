@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// Symbols a parser plugin exports
+const (
+	pluginFormatSymbol    = "Format"
+	pluginNewParserSymbol = "NewParser"
+)
+
+var parserPlugins []string
+
+// loadParserPlugins opens the parser plugins and registers their log formats
+func loadParserPlugins(paths []string) error {
+	for _, path := range paths {
+		logrus.WithField("plugin", path).Debug("Loading parser plugin")
+		lookup, err := openPlugin(path)
+		if err != nil {
+			return fmt.Errorf("failed to open parser plugin %s: %w", path, err)
+		}
+		if err := registerPluginFormat(path, lookup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerPluginFormat registers the format a plugin exports. The plugin
+// declares the format name in a Format string variable and creates parsers
+// with a NewParser function:
+//
+//	var Format = "my-format"
+//
+//	func NewParser(options loglion.FormatOptions) loglion.Parser
+func registerPluginFormat(path string, lookup func(string) (plugin.Symbol, error)) error {
+	formatSymbol, err := lookup(pluginFormatSymbol)
+	if err != nil {
+		return fmt.Errorf("parser plugin %s: %w", path, err)
+	}
+	format, ok := formatSymbol.(*string)
+	if !ok || *format == "" {
+		return fmt.Errorf("parser plugin %s: %s must be a non-empty string variable", path, pluginFormatSymbol)
+	}
+
+	newParserSymbol, err := lookup(pluginNewParserSymbol)
+	if err != nil {
+		return fmt.Errorf("parser plugin %s: %w", path, err)
+	}
+	newParser, ok := newParserSymbol.(func(parser.FormatOptions) parser.Parser)
+	if !ok {
+		return fmt.Errorf("parser plugin %s: %s must be a func(loglion.FormatOptions) loglion.Parser", path, pluginNewParserSymbol)
+	}
+
+	for _, name := range parser.Formats() {
+		if name == *format {
+			return fmt.Errorf("parser plugin %s: format '%s' is already registered", path, name)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"plugin": path,
+		"format": *format,
+	}).Debug("Registering parser plugin format")
+	parser.RegisterFormat(*format, newParser)
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build cgo && (linux || darwin || freebsd)
+
+package cmd
+
+import "plugin"
+
+// openPlugin opens a Go plugin, which needs cgo
+func openPlugin(path string) (func(string) (plugin.Symbol, error), error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Lookup, nil
+}
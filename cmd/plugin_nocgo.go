@@ -0,0 +1,18 @@
+//go:build !cgo || !(linux || darwin || freebsd)
+
+package cmd
+
+import (
+	"errors"
+	"plugin"
+)
+
+// errPluginsNotSupported explains why a static build cannot load parser
+// plugins instead of the "plugin: not implemented" of the plugin package
+var errPluginsNotSupported = errors.New("parser plugins need a loglion binary built with cgo (CGO_ENABLED=1) on Linux, macOS or FreeBSD, " +
+	"and this one was built without it; build loglion from source with cgo, " +
+	"or register the format with loglion.RegisterFormat in a program built on github.com/parfenovvs/loglion/pkg/loglion")
+
+func openPlugin(path string) (func(string) (plugin.Symbol, error), error) {
+	return nil, errPluginsNotSupported
+}
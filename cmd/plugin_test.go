@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"plugin"
+	"strings"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestRegisterPluginFormat(t *testing.T) {
+	newParser := func(o parser.FormatOptions) parser.Parser {
+		return parser.NewJSONLParser(o.TimestampFormat, o.Fields)
+	}
+	stringPointer := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		symbols     map[string]plugin.Symbol
+		errContains string
+	}{
+		{
+			name: "valid plugin",
+			symbols: map[string]plugin.Symbol{
+				pluginFormatSymbol:    stringPointer("plugin-test-format"),
+				pluginNewParserSymbol: newParser,
+			},
+		},
+		{
+			name:        "missing format",
+			symbols:     map[string]plugin.Symbol{pluginNewParserSymbol: newParser},
+			errContains: "symbol Format not found",
+		},
+		{
+			name: "empty format",
+			symbols: map[string]plugin.Symbol{
+				pluginFormatSymbol:    stringPointer(""),
+				pluginNewParserSymbol: newParser,
+			},
+			errContains: "Format must be a non-empty string variable",
+		},
+		{
+			name: "wrong parser constructor",
+			symbols: map[string]plugin.Symbol{
+				pluginFormatSymbol:    stringPointer("plugin-wrong-format"),
+				pluginNewParserSymbol: func() parser.Parser { return nil },
+			},
+			errContains: "NewParser must be a func",
+		},
+		{
+			name: "built-in format",
+			symbols: map[string]plugin.Symbol{
+				pluginFormatSymbol:    stringPointer(parser.JSONLFormat),
+				pluginNewParserSymbol: newParser,
+			},
+			errContains: "format 'jsonl' is already registered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookup := func(name string) (plugin.Symbol, error) {
+				if symbol, exists := tt.symbols[name]; exists {
+					return symbol, nil
+				}
+				return nil, fmt.Errorf("symbol %s not found", name)
+			}
+
+			err := registerPluginFormat("test.so", lookup)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("registerPluginFormat() error = %v, want it to contain %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("registerPluginFormat() unexpected error: %v", err)
+			}
+
+			logParser, err := parser.NewParserForFormat("plugin-test-format", parser.FormatOptions{})
+			if err != nil {
+				t.Fatalf("NewParserForFormat() unexpected error: %v", err)
+			}
+			if _, ok := logParser.(*parser.JSONLParser); !ok {
+				t.Errorf("NewParserForFormat() = %T, want the plugin's parser", logParser)
+			}
+		})
+	}
+}
+
+func TestLoadParserPlugins_MissingFile(t *testing.T) {
+	err := loadParserPlugins([]string{"/nonexistent/parser.so"})
+	if err == nil || !strings.Contains(err.Error(), "failed to open parser plugin") {
+		t.Errorf("loadParserPlugins() error = %v, want an open error", err)
+	}
+}
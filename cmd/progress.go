@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+const (
+	// progressThreshold is the size from which parsing a log file reports
+	// its progress, so small files stay silent
+	progressThreshold = 64 << 20
+	// progressInterval limits how often the progress line of a terminal is redrawn
+	progressInterval = 200 * time.Millisecond
+)
+
+// progressReporter prints how much of each large log file was parsed. On a
+// terminal a single line is redrawn, otherwise a line is printed every 10%.
+type progressReporter struct {
+	out       io.Writer
+	terminal  bool
+	threshold int64
+	now       func() time.Time
+
+	path       string
+	done       bool
+	lastDrawn  time.Time
+	lastStep   int64
+	lineLength int
+}
+
+func newProgressReporter(out io.Writer, terminal bool) *progressReporter {
+	return &progressReporter{out: out, terminal: terminal, threshold: progressThreshold, now: time.Now}
+}
+
+// startProgress reports the progress of parsing large log files on stderr
+// until the returned function is called, unless --quiet is set
+func startProgress() (stop func()) {
+	if quiet {
+		return func() {}
+	}
+	reporter := newProgressReporter(os.Stderr, isTerminal(os.Stderr))
+	parser.SetProgress(reporter.update)
+	return func() {
+		parser.SetProgress(nil)
+		reporter.finish()
+	}
+}
+
+func (r *progressReporter) update(path string, read, size int64) {
+	if size < r.threshold {
+		return
+	}
+	if path != r.path {
+		r.finish()
+		r.path, r.done, r.lastStep = path, false, -1
+	}
+	if r.done {
+		return
+	}
+	r.done = read >= size
+
+	percent := read * 100 / size
+	if !r.terminal {
+		if step := percent / 10 * 10; step > r.lastStep {
+			r.lastStep = step
+			fmt.Fprintf(r.out, "Parsing %s: %d%%\n", path, step)
+		}
+		return
+	}
+
+	now := r.now()
+	if !r.done && now.Sub(r.lastDrawn) < progressInterval {
+		return
+	}
+	r.lastDrawn = now
+	line := fmt.Sprintf("Parsing %s: %3d%% (%s of %s)", filepath.Base(path), percent, formatBytes(read), formatBytes(size))
+	fmt.Fprintf(r.out, "\r%-*s", r.lineLength, line)
+	r.lineLength = len(line)
+}
+
+// finish erases the progress line of a terminal
+func (r *progressReporter) finish() {
+	if r.terminal && r.lineLength > 0 {
+		fmt.Fprintf(r.out, "\r%s\r", strings.Repeat(" ", r.lineLength))
+	}
+	r.path, r.lineLength = "", 0
+}
+
+// formatBytes formats a size in bytes with a binary unit, e.g. 1.5 GB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, exp := float64(n)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGT"[exp])
+}
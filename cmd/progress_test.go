@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReporter_Lines(t *testing.T) {
+	var out bytes.Buffer
+	reporter := newProgressReporter(&out, false)
+	reporter.threshold = 100
+
+	reporter.update("small.log", 10, 50)
+	for _, read := range []int64{5, 12, 19, 55, 100, 100} {
+		reporter.update("app.log", read, 100)
+	}
+	reporter.finish()
+
+	want := "Parsing app.log: 0%\nParsing app.log: 10%\nParsing app.log: 50%\nParsing app.log: 100%\n"
+	if out.String() != want {
+		t.Errorf("Expected progress every 10%% of large files only, got %q", out.String())
+	}
+}
+
+func TestProgressReporter_Terminal(t *testing.T) {
+	var out bytes.Buffer
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	reporter := newProgressReporter(&out, true)
+	reporter.threshold = 100
+	reporter.now = func() time.Time { return now }
+
+	reporter.update("logs/app.log", 1024, 4096)
+	reporter.update("logs/app.log", 2048, 4096)
+	now = now.Add(progressInterval)
+	reporter.update("logs/app.log", 4096, 4096)
+	reporter.finish()
+
+	want := "\rParsing app.log:  25% (1.0 KB of 4.0 KB)" +
+		"\rParsing app.log: 100% (4.0 KB of 4.0 KB)" +
+		"\r" + strings.Repeat(" ", 40) + "\r"
+	if out.String() != want {
+		t.Errorf("Expected a throttled progress line erased at the end, got %q", out.String())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:      "512 B",
+		1536:     "1.5 KB",
+		64 << 20: "64.0 MB",
+		2 << 30:  "2.0 GB",
+		3 << 40:  "3.0 TB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
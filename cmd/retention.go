@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var retentionCmd = &cobra.Command{
+	Use:   "retention [cohort_event_pattern] [return_event_pattern]",
+	Short: "Compute day-N or hour-N retention of users after a cohort event",
+	Long: `Retention command computes how many users come back after a cohort-defining
+event, such as signup or first_launch. Users are identified by an event data
+property (--user-key, user_id by default) and join their cohort with their
+first cohort event. Period N of a user spans from N to N+1 periods after it,
+and the user is retained in period N when a return event falls into it.
+
+Users are only counted for the periods the log reaches, so retention on the
+last days of a multi-day log is not understated. The result lists the curve
+over all users and a row per cohort, grouped by the day or hour users joined in.
+
+Examples:
+  loglion retention -p parser.yaml -l "farm/*.log" "signup" "app_open"
+  loglion retention -p parser.yaml -l app.log --period hour --periods 24 "first_launch" "session_start"
+  loglion retention -p parser.yaml -l app.log --user-key device_id -o json "install" "app_open"`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+		userKey, _ := cmd.Flags().GetString("user-key")
+		periodFlag, _ := cmd.Flags().GetString("period")
+		periods, _ := cmd.Flags().GetInt("periods")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":      logPatterns,
+			"output_format":  outputFormat,
+			"cohort_pattern": args[0],
+			"return_pattern": args[1],
+			"user_key":       userKey,
+			"period":         periodFlag,
+		}).Info("Starting retention analysis")
+
+		period, err := parseRetentionPeriod(periodFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		retentionAnalyzer, err := analyzer.NewRetentionAnalyzer(args[0], args[1],
+			analyzer.RetentionOptions{UserKey: userKey, Period: period, Periods: periods},
+			analyzer.CountOptions{IgnoreCase: ignoreCase})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		outputOptions := output.Options{MaxNameWidth: maxNameWidth}
+		if err := outputOptions.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries := loadLogEntries(cmd)
+
+		logrus.Debug("Starting retention analysis")
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := retentionAnalyzer.AnalyzeRetentionContext(ctx, entries)
+		stop()
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, outputOptions)
+
+		logrus.Debug("Formatting retention results")
+		formattedOutput, err := formatter.FormatRetention(result)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format retention output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Retention analysis completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+// parseRetentionPeriod parses the --period flag, day, hour or a duration such
+// as 30m
+func parseRetentionPeriod(value string) (time.Duration, error) {
+	switch value {
+	case "day":
+		return 24 * time.Hour, nil
+	case "hour":
+		return time.Hour, nil
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid period '%s' (valid: day, hour or a duration such as 30m)", value)
+	}
+	return period, nil
+}
+
+func init() {
+	rootCmd.AddCommand(retentionCmd)
+
+	addLogInputFlags(retentionCmd)
+	retentionCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	retentionCmd.Flags().Int("max-name-width", 0, "Truncate event pattern names if longer than this in text output (0 = no limit)")
+
+	retentionCmd.Flags().String("user-key", analyzer.DefaultRetentionUserKey, "Event property that identifies a user")
+	retentionCmd.Flags().String("period", "day", "Length of the retention periods: day, hour or a duration such as 30m")
+	retentionCmd.Flags().Int("periods", 7, "Number of periods after the cohort period to compute retention for")
+	retentionCmd.Flags().BoolP("ignore-case", "i", false, "Match the patterns regardless of case")
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetentionCommandFlags(t *testing.T) {
+	cmd := retentionCmd
+
+	expectedFlags := map[string]struct {
+		shorthand  string
+		valueType  string
+		defaultVal string
+	}{
+		"parser-config":  {"p", "string", ""},
+		"config":         {"c", "string", ""},
+		"log":            {"l", "stringSlice", "[]"},
+		"output":         {"o", "string", "text"},
+		"max-name-width": {"", "int", "0"},
+		"user-key":       {"", "string", "user_id"},
+		"period":         {"", "string", "day"},
+		"periods":        {"", "int", "7"},
+		"ignore-case":    {"i", "bool", "false"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Shorthand != expected.shorthand {
+			t.Errorf("Expected %s shorthand to be %q, got %q", flagName, expected.shorthand, flag.Shorthand)
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+}
+
+func TestRetentionCommandProperties(t *testing.T) {
+	cmd := retentionCmd
+
+	if cmd.Use != "retention [cohort_event_pattern] [return_event_pattern]" {
+		t.Errorf("Expected Use to be 'retention [cohort_event_pattern] [return_event_pattern]', got %q", cmd.Use)
+	}
+
+	if !strings.Contains(cmd.Long, "Examples:") {
+		t.Error("Expected Long description to contain examples")
+	}
+
+	for _, required := range []string{"parser-config", "log"} {
+		flag := cmd.Flags().Lookup(required)
+		if flag == nil || len(flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]) == 0 {
+			t.Errorf("Expected %s flag to be required", required)
+		}
+	}
+}
+
+func TestParseRetentionPeriod(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"30m", 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := parseRetentionPeriod(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("parseRetentionPeriod(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+		}
+	}
+
+	if _, err := parseRetentionPeriod("week"); err == nil || !strings.Contains(err.Error(), "invalid period 'week'") {
+		t.Errorf("Expected an invalid period error, got %v", err)
+	}
+}
@@ -3,12 +3,30 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/parfenovvs/loglion/internal/parser"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
-var verbose bool
+var (
+	verbose         bool
+	noProjectConfig bool
+	configOverrides []string
+	configVars      []string
+	configHeaders   []string
+	configTimeout   time.Duration
+	noColor         bool
+	asciiOutput     bool
+	quiet           bool
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "loglion",
@@ -17,9 +35,53 @@ var rootCmd = &cobra.Command{
 analytics event funnels for automated testing.
 
 It helps you track user conversion funnels by parsing log files
-and checking if users complete expected sequences of analytics events.`,
+and checking if users complete expected sequences of analytics events.
+
+Default flags per command can be declared in a .loglion.yaml file in the
+current directory or any parent up to the repository root. Flags given on
+the command line take precedence.
+
+Parser and funnel configs can also be given as http or https URLs, with an
+optional #sha256=<hex digest> fragment to verify their content.
+
+Any parser or funnel config field can be overridden with --set, e.g.
+--set parser.json_extraction=true, or with environment variables such as
+LOGLION_PARSER_EVENT_REGEX. Funnel configs can reference variables as ${NAME},
+set in their vars block, in the environment or with --var.
+
+Text output uses emoji and ANSI colors only on interactive terminals. When
+output is piped to a file, or on legacy Windows consoles, it falls back to
+plain ASCII with PASS/FAIL wording; force either with --ascii or --ascii=false.
+Colors are disabled with --no-color or the NO_COLOR environment variable.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupTerminalOutput(cmd)
 		setupLogging()
+		if noProjectConfig {
+			logrus.Debug("Project config disabled")
+		} else if err := loadProjectDefaults(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading project config: %v\n", err)
+			os.Exit(1)
+		}
+		if err := loadConfigOverrides(os.Environ(), configOverrides); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := setConfigVars(configVars); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := setRemoteConfigOptions(configHeaders, configTimeout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := applyCombinedConfig(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		if err := loadParserPlugins(parserPlugins); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading parser plugin: %v\n", err)
+			os.Exit(1)
+		}
 	},
 }
 
@@ -32,16 +94,658 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
+	rootCmd.PersistentFlags().BoolVar(&noProjectConfig, "no-project-config", false, "Ignore default flags from "+config.ProjectConfigFile)
+	rootCmd.PersistentFlags().StringArrayVar(&configOverrides, "set", nil, "Override a parser or funnel config field, e.g. parser.json_extraction=true (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&configVars, "var", nil, "Set a variable referenced as ${NAME} in funnel configs, e.g. PACKAGE=com.example.debug (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&configHeaders, "config-header", nil, "HTTP header sent when fetching configs from the scheme and host of the given URLs, e.g. \"Authorization: Bearer $TOKEN\" (repeatable)")
+	rootCmd.PersistentFlags().DurationVar(&configTimeout, "config-timeout", config.DefaultRemoteTimeout, "Timeout for fetching configs from URLs")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Do not report the progress of parsing large log files on stderr")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable ANSI colors in output (also set by the NO_COLOR environment variable)")
+	rootCmd.PersistentFlags().BoolVar(&asciiOutput, "ascii", false, "Replace emoji and symbols in text output with plain ASCII and PASS/FAIL wording (default when output is not a terminal)")
+	rootCmd.PersistentFlags().StringSliceVar(&parserPlugins, "parser-plugin", nil, "Go plugin (.so) adding a log format for parser configs (can be repeated); needs a binary built with cgo")
 }
 
 func setupLogging() {
 	if verbose {
 		logrus.SetLevel(logrus.DebugLevel)
 		logrus.SetFormatter(&logrus.TextFormatter{
-			ForceColors:   true,
+			ForceColors:   colorEnabled(os.Stderr),
+			DisableColors: !colorEnabled(os.Stderr),
 			FullTimestamp: true,
 		})
 	} else {
 		logrus.SetLevel(logrus.PanicLevel)
 	}
 }
+
+// setupTerminalOutput decides whether output uses colors and emoji. Unless
+// set with flags, colors follow NO_COLOR and text output falls back to ASCII
+// when stdout is not a terminal or is a legacy Windows console.
+func setupTerminalOutput(cmd *cobra.Command) {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok && !cmd.Flags().Changed("no-color") {
+		noColor = true
+	}
+	if !cmd.Flags().Changed("ascii") {
+		asciiOutput = !isTerminal(os.Stdout) || legacyWindowsConsole()
+	}
+}
+
+// legacyWindowsConsole reports whether output goes to a Windows console that
+// mangles emoji and ANSI sequences, unlike Windows Terminal
+func legacyWindowsConsole() bool {
+	return runtime.GOOS == "windows" && os.Getenv("WT_SESSION") == ""
+}
+
+// colorEnabled reports whether ANSI sequences can be written to file
+func colorEnabled(file *os.File) bool {
+	return !noColor && !legacyWindowsConsole() && isTerminal(file)
+}
+
+// textOutput returns text printed by commands, converted to ASCII when emoji
+// are disabled
+func textOutput(s string) string {
+	if asciiOutput {
+		return output.ASCII(s)
+	}
+	return s
+}
+
+// projectPathFlags are flags holding file paths, which are resolved relative
+// to the project file so defaults work from any subdirectory
+var projectPathFlags = map[string]bool{
+	"config":        true,
+	"parser-config": true,
+	"funnel-config": true,
+	"log":           true,
+	"parser-plugin": true,
+	"patterns-file": true,
+	"template-file": true,
+}
+
+// loadProjectDefaults finds the project file and applies its defaults to cmd
+func loadProjectDefaults(cmd *cobra.Command) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	projectFile, err := config.FindProjectConfig(cwd)
+	if err != nil || projectFile == "" {
+		return err
+	}
+
+	projectCfg, err := config.LoadProjectConfig(projectFile)
+	if err != nil {
+		return err
+	}
+	return applyProjectDefaults(cmd, projectCfg)
+}
+
+// applyProjectDefaults sets flags of cmd that were not given on the command
+// line to the values declared for the command in the project file
+func applyProjectDefaults(cmd *cobra.Command, projectCfg *config.ProjectConfig) error {
+	defaults := projectCfg.Defaults(cmd.Name())
+	projectDir := filepath.Dir(projectCfg.Path)
+
+	for name, value := range defaults {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("%s: unknown flag '%s' for command '%s'", projectCfg.Path, name, cmd.Name())
+		}
+		if flag.Changed {
+			logrus.WithField("flag", name).Debug("Flag given on command line, ignoring project default")
+			continue
+		}
+
+		if projectPathFlags[name] {
+			value = resolveProjectPaths(projectDir, value)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"command": cmd.Name(),
+			"flag":    name,
+			"value":   value,
+		}).Debug("Applying project default")
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("%s: invalid value for flag '%s': %w", projectCfg.Path, name, err)
+		}
+	}
+
+	return nil
+}
+
+// loadConfigOverrides sets the config overrides of the LOGLION_PARSER_* and
+// LOGLION_FUNNEL_* environment variables and of --set, which take precedence
+func loadConfigOverrides(environ, sets []string) error {
+	overrides, err := config.OverridesFromEnv(environ)
+	if err != nil {
+		return err
+	}
+	for _, set := range sets {
+		override, err := config.ParseOverride(set)
+		if err != nil {
+			return err
+		}
+		overrides = append(overrides, override)
+	}
+
+	if len(overrides) > 0 {
+		logrus.WithField("overrides", len(overrides)).Debug("Loaded config overrides")
+	}
+	config.SetOverrides(overrides)
+	return nil
+}
+
+// setConfigVars sets the funnel config variables of --var flags
+func setConfigVars(vars []string) error {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		name, value, err := config.ParseVar(v)
+		if err != nil {
+			return err
+		}
+		values[name] = value
+	}
+	config.SetVars(values)
+	return nil
+}
+
+// setRemoteConfigOptions sets how configs given as URLs are fetched, from
+// headers in the "Name: Value" form
+func setRemoteConfigOptions(headers []string, timeout time.Duration) error {
+	options := config.RemoteOptions{Timeout: timeout}
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("invalid --config-header '%s': expected Name: Value", header)
+		}
+		if options.Headers == nil {
+			options.Headers = make(map[string]string)
+		}
+		options.Headers[name] = strings.TrimSpace(value)
+	}
+	config.SetRemoteOptions(options)
+	return nil
+}
+
+// addConfigFlag registers the --config flag of commands reading parser or
+// funnel configs
+func addConfigFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("config", "c", "", "Path to a config file with parser and funnel sections, instead of --parser-config and --funnel-config")
+}
+
+// applyCombinedConfig points the --parser-config and --funnel-config flags of
+// cmd that were not given to the sections of the --config file, so the
+// separate files keep precedence over the combined one
+func applyCombinedConfig(cmd *cobra.Command) error {
+	configFile, _ := cmd.Flags().GetString("config")
+	if configFile == "" {
+		return nil
+	}
+
+	hasParser, hasFunnel, err := config.CombinedSections(configFile)
+	if err != nil {
+		return err
+	}
+	sections := map[string]bool{"parser-config": hasParser, "funnel-config": hasFunnel}
+	for name, present := range sections {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed || !present {
+			continue
+		}
+		logrus.WithFields(logrus.Fields{
+			"flag":        name,
+			"config_file": configFile,
+		}).Debug("Using section of combined config file")
+		if err := cmd.Flags().Set(name, configFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveProjectPaths resolves comma-separated relative paths against the project directory
+func resolveProjectPaths(projectDir, value string) string {
+	paths := strings.Split(value, ",")
+	for i, path := range paths {
+		if !filepath.IsAbs(path) && !config.IsURL(path) {
+			paths[i] = filepath.Join(projectDir, path)
+		}
+	}
+	return strings.Join(paths, ",")
+}
+
+// newOutputFormatter maps the --output flag value to a formatter
+func newOutputFormatter(outputFormat string, options output.Options) output.Formatter {
+	logrus.WithField("output_format", outputFormat).Debug("Creating output formatter")
+	switch outputFormat {
+	case "json":
+		return output.NewFormatterWithOptions(output.JSONFormat, options)
+	case "html":
+		return output.NewFormatterWithOptions(output.HTMLFormat, options)
+	case "template":
+		return output.NewFormatterWithOptions(output.TemplateFormat, options)
+	case "sankey":
+		return output.NewFormatterWithOptions(output.SankeyFormat, options)
+	default:
+		options.ASCII = asciiOutput
+		return output.NewFormatterWithOptions(output.TextFormat, options)
+	}
+}
+
+// writeOutput prints formatted results, or writes them to the file given with
+// --output-file
+func writeOutput(cmd *cobra.Command, formatted string) error {
+	outputFile, _ := cmd.Flags().GetString("output-file")
+	return writeOutputTo(outputFile, formatted)
+}
+
+// writeOutputTo writes formatted results to path, or prints them when path is
+// empty or "-"
+func writeOutputTo(path, formatted string) error {
+	if path == "" || path == "-" {
+		fmt.Print(formatted)
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(formatted), 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	logrus.WithField("output_file", path).Info("Wrote results to output file")
+	return nil
+}
+
+// outputTarget is a format to emit results in and the file to write them to,
+// empty or "-" for stdout
+type outputTarget struct {
+	Format string
+	Path   string
+}
+
+// outputTargetsFromFlags reads the --output and --output-file flags. --output
+// is either one format, written to --output-file or stdout, or a list of
+// format=path pairs emitting several formats at once, e.g.
+// json=result.json,text=-
+func outputTargetsFromFlags(cmd *cobra.Command) ([]outputTarget, error) {
+	value, _ := cmd.Flags().GetString("output")
+	outputFile, _ := cmd.Flags().GetString("output-file")
+
+	if !strings.Contains(value, "=") {
+		if err := checkTemplateFile(cmd, value); err != nil {
+			return nil, err
+		}
+		return []outputTarget{{Format: value, Path: outputFile}}, nil
+	}
+	if outputFile != "" {
+		return nil, fmt.Errorf("--output-file cannot be combined with format=path outputs, give the path in --output instead")
+	}
+
+	var targets []outputTarget
+	for _, pair := range strings.Split(value, ",") {
+		format, path, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		if path == "" {
+			return nil, fmt.Errorf("invalid output '%s' (expected format=path, '-' for stdout)", pair)
+		}
+		switch format {
+		case "json", "text", "html", "template", "sankey":
+		default:
+			return nil, fmt.Errorf("unknown output format '%s' (expected json, text, html, template or sankey)", format)
+		}
+		if err := checkTemplateFile(cmd, format); err != nil {
+			return nil, err
+		}
+		targets = append(targets, outputTarget{Format: format, Path: path})
+	}
+
+	logrus.WithField("output_targets", targets).Debug("Resolved output targets")
+	return targets, nil
+}
+
+// checkTemplateFile checks that the template output format comes with --template-file
+func checkTemplateFile(cmd *cobra.Command, format string) error {
+	if templateFile, _ := cmd.Flags().GetString("template-file"); format == "template" && templateFile == "" {
+		return fmt.Errorf("--output template requires --template-file")
+	}
+	return nil
+}
+
+// emitOutput formats results with format in every target format and writes
+// them to the target paths
+func emitOutput(targets []outputTarget, options output.Options, format func(output.Formatter) (string, error)) error {
+	for _, target := range targets {
+		formatted, err := format(newOutputFormatter(target.Format, options))
+		if err != nil {
+			return fmt.Errorf("failed to format %s output: %w", target.Format, err)
+		}
+		if err := writeOutputTo(target.Path, formatted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputOptionsFromFlags reads the --only, --hide, --max-name-width, table
+// layout and --template-file flags of a command
+func outputOptionsFromFlags(cmd *cobra.Command) (output.Options, error) {
+	only, _ := cmd.Flags().GetStringSlice("only")
+	hide, _ := cmd.Flags().GetStringSlice("hide")
+	maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+	table, _ := cmd.Flags().GetBool("table")
+	borders, _ := cmd.Flags().GetBool("borders")
+	wide, _ := cmd.Flags().GetBool("wide")
+	templateFile, _ := cmd.Flags().GetString("template-file")
+
+	options := output.Options{Only: only, Hide: hide, MaxNameWidth: maxNameWidth, Table: table, Borders: borders, Wide: wide, TemplateFile: templateFile}
+	if err := options.Validate(); err != nil {
+		return output.Options{}, err
+	}
+	return options, nil
+}
+
+// reportParseStats prints parse statistics to stderr with --parse-stats, and
+// otherwise only warns when log lines were skipped or repaired. Skipped lines
+// are listed with a few samples; with --strict they fail the command.
+func reportParseStats(cmd *cobra.Command, logParser parser.Parser) {
+	stats := logParser.Stats()
+	logrus.WithFields(logrus.Fields{
+		"total_lines":    stats.TotalLines,
+		"parsed_entries": stats.ParsedEntries,
+		"skipped_lines":  stats.Skipped(),
+		"parse_problems": stats.Problems(),
+	}).Debug("Reporting parse stats")
+
+	if strict, _ := cmd.Flags().GetBool("strict"); strict && stats.Skipped() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %d log line(s) could not be parsed (--strict):\n", stats.Skipped())
+		printSkippedLines(parser.SkippedLines(logParser), stats.Skipped())
+		os.Exit(1)
+	}
+
+	showStats, _ := cmd.Flags().GetBool("parse-stats")
+	if showStats {
+		fmt.Fprintf(os.Stderr, "Parse stats: %d lines, %d entries parsed, %d empty, %d unmatched; %s\n",
+			stats.TotalLines, stats.ParsedEntries, stats.EmptyLines, stats.UnmatchedLines, stats.Summary())
+	} else if stats.Problems() > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s while parsing logs (use --parse-stats for details)\n", stats.Summary())
+	}
+
+	if stats.OversizedLines > 0 {
+		maxLineBytes, _ := cmd.Flags().GetInt("max-line-bytes")
+		fmt.Fprintf(os.Stderr, "Warning: %d line(s) longer than %d bytes were skipped (use --max-line-bytes to raise the limit)\n", stats.OversizedLines, maxLineBytes)
+	}
+
+	if stats.Skipped() > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d log line(s) that could not be parsed (use --strict to fail instead):\n", stats.Skipped())
+		printSkippedLines(parser.SkippedLines(logParser), stats.Skipped())
+	}
+
+	fileOptions := fileOptionsFromFlags(cmd)
+	if !showStats && stats.TimestampRegressions > 0 && !fileOptions.Monotonicize && !fileOptions.SortByTimestamp {
+		fmt.Fprintf(os.Stderr, "Warning: log timestamps went backwards, durations may be negative (use --monotonicize to clamp them or --sort-by-timestamp to reorder entries)\n")
+	}
+}
+
+// printConfigWarnings prints the warnings about suspicious config settings to
+// stderr before an analysis starts
+func printConfigWarnings(warnings []config.Warning) {
+	for _, warning := range warnings {
+		logrus.WithField("warning", warning.String()).Debug("Suspicious config setting")
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
+
+// printSkippedLines prints samples of skipped log lines to stderr, noting how
+// many more lines were skipped
+func printSkippedLines(samples []parser.SkippedLine, skipped int) {
+	for _, sample := range samples {
+		fmt.Fprintf(os.Stderr, "  %s\n", sample)
+	}
+	if more := skipped - len(samples); more > 0 && len(samples) > 0 {
+		fmt.Fprintf(os.Stderr, "  ... and %d more\n", more)
+	}
+}
+
+// configureParser applies the parsing flags of a command to logParser
+func configureParser(cmd *cobra.Command, logParser parser.Parser) error {
+	workers, _ := cmd.Flags().GetInt("workers")
+	if workers < 1 {
+		return fmt.Errorf("--workers must be at least 1, got %d", workers)
+	}
+	maxLineBytes, _ := cmd.Flags().GetInt("max-line-bytes")
+	if maxLineBytes < 1 {
+		return fmt.Errorf("--max-line-bytes must be at least 1, got %d", maxLineBytes)
+	}
+	parser.SetWorkers(logParser, workers)
+	parser.SetMaxLineBytes(logParser, maxLineBytes)
+	return nil
+}
+
+// dedupeLogFiles drops log files with the same content as an earlier file,
+// noting each on stderr, unless --keep-duplicates is set
+func dedupeLogFiles(cmd *cobra.Command, files []string) ([]string, error) {
+	if keepDuplicates, _ := cmd.Flags().GetBool("keep-duplicates"); keepDuplicates {
+		return files, nil
+	}
+
+	unique, duplicates, err := parser.DedupeFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	for _, duplicate := range duplicates {
+		fmt.Fprintf(os.Stderr, "Skipping duplicate log file %s (same content as %s)\n", duplicate.Path, duplicate.SameAs)
+	}
+	return unique, nil
+}
+
+// dedupeEntries collapses duplicate events when --dedupe is set, noting on
+// stderr how many were removed
+func dedupeEntries(cmd *cobra.Command, entries []*parser.LogEntry) ([]*parser.LogEntry, error) {
+	if dedupe, _ := cmd.Flags().GetBool("dedupe"); !dedupe {
+		return entries, nil
+	}
+
+	window, _ := cmd.Flags().GetDuration("dedupe-window")
+	if window <= 0 {
+		return nil, fmt.Errorf("--dedupe-window must be positive, got %s", window)
+	}
+	keys, _ := cmd.Flags().GetStringSlice("dedupe-key")
+
+	entries, removed := parser.DedupeEntries(entries, parser.DedupeOptions{Window: window, Keys: keys})
+	if removed > 0 {
+		fmt.Fprintf(os.Stderr, "Removed %d duplicate event(s) repeated within %s (--dedupe)\n", removed, window)
+	}
+	return entries, nil
+}
+
+// parseLogFiles parses the log files with the file options of cmd, reporting
+// the progress of large files
+func parseLogFiles(cmd *cobra.Command, logParser parser.Parser, logFiles []string) ([]*parser.LogEntry, error) {
+	stopProgress := startProgress()
+	defer stopProgress()
+	return parser.ParseFilesWithOptions(logParser, logFiles, fileOptionsFromFlags(cmd))
+}
+
+// fileOptionsFromFlags reads the --monotonicize and --sort-by-timestamp flags
+// of a command
+func fileOptionsFromFlags(cmd *cobra.Command) parser.FileOptions {
+	monotonicize, _ := cmd.Flags().GetBool("monotonicize")
+	sortByTimestamp, _ := cmd.Flags().GetBool("sort-by-timestamp")
+	return parser.FileOptions{Monotonicize: monotonicize, SortByTimestamp: sortByTimestamp}
+}
+
+// entryFilterFromFlags merges the --level, --tag, --exclude-tag and --pid
+// flags over the filter declared in the parser config
+func entryFilterFromFlags(cmd *cobra.Command, parserCfg *config.ParserConfig) (parser.EntryFilter, error) {
+	filter := parserCfg.EntryFilter()
+
+	if cmd.Flags().Changed("level") {
+		level, _ := cmd.Flags().GetString("level")
+		minLevel, err := parser.ParseLevel(level)
+		if err != nil {
+			return parser.EntryFilter{}, err
+		}
+		filter.MinLevel = minLevel
+	}
+	if cmd.Flags().Changed("tag") {
+		filter.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	}
+	if cmd.Flags().Changed("exclude-tag") {
+		filter.ExcludeTags, _ = cmd.Flags().GetStringSlice("exclude-tag")
+	}
+	if cmd.Flags().Changed("pid") {
+		filter.PIDs, _ = cmd.Flags().GetIntSlice("pid")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"min_level":    filter.MinLevel,
+		"tags":         filter.Tags,
+		"exclude_tags": filter.ExcludeTags,
+		"pids":         filter.PIDs,
+	}).Debug("Resolved entry filter")
+
+	return filter, nil
+}
+
+// addLogInputFlags registers the flags that select, parse and filter the log
+// files of an analysis command
+func addLogInputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("parser-config", "p", "", "Path to parser configuration file (required)")
+	addConfigFlag(cmd)
+	cmd.Flags().StringSliceP("log", "l", nil, "Path or glob pattern of log files, merged in order (required, repeatable)")
+	addLogReadingFlags(cmd)
+
+	cmd.MarkFlagRequired("parser-config")
+	cmd.MarkFlagRequired("log")
+}
+
+// addLogReadingFlags registers the flags that order, parse and filter log
+// files, for commands that select the files themselves
+func addLogReadingFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("sort-by-mtime", false, "Analyze log files in order of modification time instead of the given order")
+	cmd.Flags().Bool("keep-duplicates", false, "Analyze log files with identical content separately instead of once")
+	cmd.Flags().String("level", "", "Only analyze entries with this log level or more severe (V, D, I, W, E, F)")
+	cmd.Flags().StringSlice("tag", nil, "Only analyze entries with these tags (repeatable)")
+	cmd.Flags().StringSlice("exclude-tag", nil, "Skip entries with these tags (repeatable)")
+	cmd.Flags().IntSlice("pid", nil, "Only analyze entries from these process IDs (repeatable)")
+	cmd.Flags().Bool("parse-stats", false, "Print statistics about parsed, skipped and repaired log lines to stderr")
+	cmd.Flags().Bool("strict", false, "Fail if any log line cannot be parsed instead of skipping it")
+	cmd.Flags().Int("workers", 1, "Number of goroutines parsing each log file; entries keep their order")
+	cmd.Flags().Int("max-line-bytes", parser.DefaultMaxLineBytes, "Skip log lines longer than this many bytes, with a warning")
+	cmd.Flags().Bool("monotonicize", false, "Clamp timestamps that go backwards within a log file to the latest earlier one")
+	cmd.Flags().Bool("sort-by-timestamp", false, "Sort the entries of all log files by timestamp before analysis, e.g. for merged logcat buffers")
+	cmd.Flags().Bool("dedupe", false, "Drop events repeating an earlier event within --dedupe-window, e.g. duplicates sent by SDK retries")
+	cmd.Flags().Duration("dedupe-window", time.Second, "Time after an event in which the same event is a duplicate (with --dedupe)")
+	cmd.Flags().StringSlice("dedupe-key", nil, "Event data properties that must also be equal for duplicates (with --dedupe, repeatable)")
+}
+
+// loadLogEntries parses the log files selected by the flags of addLogInputFlags
+// and returns the entries that pass the entry filter. Errors are printed to
+// stderr and exit the command.
+func loadLogEntries(cmd *cobra.Command) []*parser.LogEntry {
+	entries, _ := readLogEntries(cmd, false)
+	return entries
+}
+
+// readLogEntries is like loadLogEntries and also returns the statistics of the
+// parser that read the log files. With keepRaw the entries keep the lines they
+// were parsed from.
+func readLogEntries(cmd *cobra.Command, keepRaw bool) ([]*parser.LogEntry, parser.ParseStats) {
+	logPatterns, _ := cmd.Flags().GetStringSlice("log")
+	parserCfg, entryFilter := loadParserConfig(cmd)
+	entries, stats, ok := readLogFiles(cmd, parserCfg, entryFilter, logPatterns, keepRaw)
+	if !ok {
+		os.Exit(1)
+	}
+	return entries, stats
+}
+
+// loadParserConfig loads the config of the --parser-config flag and merges
+// the entry filter flags over its filter. Errors are printed to stderr and
+// exit the command.
+func loadParserConfig(cmd *cobra.Command) (*config.ParserConfig, parser.EntryFilter) {
+	parserConfigFile, _ := cmd.Flags().GetString("parser-config")
+
+	logrus.Debug("Loading parser configuration file")
+	parserCfg, err := config.LoadParserConfig(parserConfigFile)
+	if err != nil {
+		logrus.WithError(err).WithField("parser_config_file", parserConfigFile).Error("Failed to load parser config")
+		fmt.Fprintf(os.Stderr, "Error loading parser config: %v\n", err)
+		os.Exit(1)
+	}
+
+	entryFilter, err := entryFilterFromFlags(cmd, parserCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return parserCfg, entryFilter
+}
+
+// stdinLog is the log pattern that reads the log from stdin
+const stdinLog = "-"
+
+// readLogFiles parses the log files of logPatterns, or stdin for the pattern
+// "-", with a new parser of parserCfg and returns the entries that pass
+// entryFilter along with the statistics of the parser. Errors are printed to
+// stderr and reported with ok false, so watch modes can keep going.
+func readLogFiles(cmd *cobra.Command, parserCfg *config.ParserConfig, entryFilter parser.EntryFilter, logPatterns []string, keepRaw bool) (entries []*parser.LogEntry, stats parser.ParseStats, ok bool) {
+	readStdin := slices.Contains(logPatterns, stdinLog)
+	if readStdin && len(logPatterns) > 1 {
+		fmt.Fprintf(os.Stderr, "Error: the log cannot be read from stdin and from log files at once\n")
+		return nil, stats, false
+	}
+	if readStdin && isTerminal(os.Stdin) {
+		// A terminal cannot pipe a log in, so reading it would only wait
+		fmt.Fprintf(os.Stderr, "Error: no log to analyze: pass --log or pipe a log to stdin, e.g. adb logcat -d | %s -p parser.yaml ...\n", cmd.CommandPath())
+		return nil, stats, false
+	}
+
+	// Create parser
+	logrus.Debug("Creating log parser")
+	logParser, err := parserCfg.NewParser()
+	if err == nil {
+		err = configureParser(cmd, logParser)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating log parser: %v\n", err)
+		return nil, stats, false
+	}
+	parser.SetKeepRaw(logParser, keepRaw)
+
+	if readStdin {
+		logrus.Debug("Reading log from stdin")
+		entries, err = parser.ParseReaderWithOptions(logParser, os.Stdin, fileOptionsFromFlags(cmd))
+		if err != nil {
+			logrus.WithError(err).Error("Failed to parse log from stdin")
+			fmt.Fprintf(os.Stderr, "Error parsing log from stdin: %v\n", err)
+			return nil, stats, false
+		}
+	} else {
+		sortByMTime, _ := cmd.Flags().GetBool("sort-by-mtime")
+		logFiles, err := parser.ResolveLogFiles(logPatterns, sortByMTime)
+		if err == nil {
+			logFiles, err = dedupeLogFiles(cmd, logFiles)
+		}
+		if err != nil {
+			logrus.WithError(err).WithField("log_files", logPatterns).Error("Failed to resolve log files")
+			fmt.Fprintf(os.Stderr, "Error resolving log files: %v\n", err)
+			return nil, stats, false
+		}
+
+		logrus.WithField("log_files", logFiles).Debug("Starting log file parsing")
+		entries, err = parseLogFiles(cmd, logParser, logFiles)
+		if err != nil {
+			logrus.WithError(err).WithField("log_files", logFiles).Error("Failed to parse log file")
+			fmt.Fprintf(os.Stderr, "Error parsing log file: %v\n", err)
+			return nil, stats, false
+		}
+	}
+	reportParseStats(cmd, logParser)
+
+	entries, err = dedupeEntries(cmd, parser.FilterEntries(entries, entryFilter))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, stats, false
+	}
+	return entries, logParser.Stats(), true
+}
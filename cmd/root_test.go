@@ -3,9 +3,16 @@ package cmd
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/parfenovvs/loglion/internal/parser"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -41,6 +48,11 @@ func TestRootCommandFlags(t *testing.T) {
 	if flag.DefValue != "false" {
 		t.Errorf("Expected verbose flag default to be 'false', got %q", flag.DefValue)
 	}
+
+	quietFlag := rootCmd.PersistentFlags().Lookup("quiet")
+	if quietFlag == nil || quietFlag.Shorthand != "q" || quietFlag.DefValue != "false" {
+		t.Errorf("Expected a quiet flag with shorthand 'q' defaulting to false, got %+v", quietFlag)
+	}
 }
 
 func TestSetupLogging(t *testing.T) {
@@ -88,18 +100,20 @@ func TestSetupLogging(t *testing.T) {
 func TestSetupLoggingFormatter(t *testing.T) {
 	// Save original values
 	originalVerbose := verbose
+	originalNoColor := noColor
 	originalFormatter := logrus.StandardLogger().Formatter
 
 	// Test verbose mode formatter
 	verbose = true
+	noColor = true
 	setupLogging()
 
 	formatter, ok := logrus.StandardLogger().Formatter.(*logrus.TextFormatter)
 	if !ok {
 		t.Error("Expected TextFormatter when verbose is enabled")
 	} else {
-		if !formatter.ForceColors {
-			t.Error("Expected ForceColors to be true when verbose is enabled")
+		if formatter.ForceColors || !formatter.DisableColors {
+			t.Error("Expected colors to be disabled with --no-color")
 		}
 		if !formatter.FullTimestamp {
 			t.Error("Expected FullTimestamp to be true when verbose is enabled")
@@ -108,6 +122,7 @@ func TestSetupLoggingFormatter(t *testing.T) {
 
 	// Restore original values
 	verbose = originalVerbose
+	noColor = originalNoColor
 	logrus.StandardLogger().Formatter = originalFormatter
 }
 
@@ -297,3 +312,620 @@ func TestVerboseFlagParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestNewOutputFormatter(t *testing.T) {
+	tests := map[string]string{
+		"json":     "*output.JSONFormatter",
+		"text":     "*output.TextFormatter",
+		"html":     "*output.HTMLFormatter",
+		"template": "*output.TemplateFormatter",
+		"sankey":   "*output.SankeyFormatter",
+		"invalid":  "*output.TextFormatter",
+	}
+
+	originalASCII := asciiOutput
+	defer func() { asciiOutput = originalASCII }()
+	asciiOutput = false
+
+	for format, want := range tests {
+		got := reflect.TypeOf(newOutputFormatter(format, output.Options{})).String()
+		if got != want {
+			t.Errorf("newOutputFormatter(%q) = %s, want %s", format, got, want)
+		}
+	}
+}
+
+func TestNewOutputFormatterASCII(t *testing.T) {
+	originalASCII := asciiOutput
+	defer func() { asciiOutput = originalASCII }()
+
+	asciiOutput = true
+	if got := reflect.TypeOf(newOutputFormatter("text", output.Options{})).String(); got != "output.asciiFormatter" {
+		t.Errorf("newOutputFormatter(text) with --ascii = %s, want output.asciiFormatter", got)
+	}
+	if got := reflect.TypeOf(newOutputFormatter("json", output.Options{})).String(); got != "*output.JSONFormatter" {
+		t.Errorf("newOutputFormatter(json) with --ascii = %s, want *output.JSONFormatter", got)
+	}
+}
+
+func TestSetupTerminalOutput(t *testing.T) {
+	originalNoColor, originalASCII := noColor, asciiOutput
+	defer func() { noColor, asciiOutput = originalNoColor, originalASCII }()
+
+	newCmd := func(args ...string) *cobra.Command {
+		testCmd := &cobra.Command{Use: "test"}
+		testCmd.Flags().BoolVar(&noColor, "no-color", false, "")
+		testCmd.Flags().BoolVar(&asciiOutput, "ascii", false, "")
+		if err := testCmd.ParseFlags(args); err != nil {
+			t.Fatalf("ParseFlags() unexpected error: %v", err)
+		}
+		return testCmd
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	setupTerminalOutput(newCmd())
+	if !noColor {
+		t.Error("Expected NO_COLOR to disable colors")
+	}
+	if want := !isTerminal(os.Stdout) || legacyWindowsConsole(); asciiOutput != want {
+		t.Errorf("Expected ASCII output to be %t without a terminal, got %t", want, asciiOutput)
+	}
+
+	setupTerminalOutput(newCmd("--ascii=false", "--no-color=false"))
+	if noColor || asciiOutput {
+		t.Errorf("Expected flags to take precedence, got noColor=%t ascii=%t", noColor, asciiOutput)
+	}
+}
+
+func TestTextOutput(t *testing.T) {
+	originalASCII := asciiOutput
+	defer func() { asciiOutput = originalASCII }()
+
+	asciiOutput = false
+	if got := textOutput("✅ valid"); got != "✅ valid" {
+		t.Errorf("textOutput() = %q, want emoji kept", got)
+	}
+	asciiOutput = true
+	if got := textOutput("✅ valid"); got != "PASS valid" {
+		t.Errorf("textOutput() with --ascii = %q, want %q", got, "PASS valid")
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "report.html")
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("output-file", "", "")
+	if err := cmd.ParseFlags([]string{"--output-file", outputFile}); err != nil {
+		t.Fatalf("ParseFlags() unexpected error: %v", err)
+	}
+
+	if err := writeOutput(cmd, "<html></html>"); err != nil {
+		t.Fatalf("writeOutput() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if string(data) != "<html></html>" {
+		t.Errorf("Expected output file to contain the formatted output, got %q", data)
+	}
+
+	cmd.Flags().Set("output-file", filepath.Join(t.TempDir(), "missing", "report.html"))
+	if err := writeOutput(cmd, "<html></html>"); err == nil {
+		t.Error("Expected error for output file in missing directory")
+	}
+}
+
+func TestOutputTargetsFromFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    []outputTarget
+		expectError string
+	}{
+		{
+			name:     "default",
+			expected: []outputTarget{{Format: "text"}},
+		},
+		{
+			name:     "single format to file",
+			args:     []string{"-o", "html", "--output-file", "report.html"},
+			expected: []outputTarget{{Format: "html", Path: "report.html"}},
+		},
+		{
+			name:     "several formats",
+			args:     []string{"-o", "json=result.json,text=-"},
+			expected: []outputTarget{{Format: "json", Path: "result.json"}, {Format: "text", Path: "-"}},
+		},
+		{
+			name:        "missing path",
+			args:        []string{"-o", "json=result.json,text"},
+			expectError: "invalid output 'text'",
+		},
+		{
+			name:        "unknown format",
+			args:        []string{"-o", "xml=result.xml"},
+			expectError: "unknown output format 'xml'",
+		},
+		{
+			name:     "template",
+			args:     []string{"-o", "template", "--template-file", "slack.tmpl"},
+			expected: []outputTarget{{Format: "template"}},
+		},
+		{
+			name:     "template among several formats",
+			args:     []string{"-o", "json=result.json,template=-", "--template-file", "slack.tmpl"},
+			expected: []outputTarget{{Format: "json", Path: "result.json"}, {Format: "template", Path: "-"}},
+		},
+		{
+			name:        "template without template file",
+			args:        []string{"-o", "text=-,template=message.txt"},
+			expectError: "--output template requires --template-file",
+		},
+		{
+			name:        "pairs with output file",
+			args:        []string{"-o", "json=result.json", "--output-file", "report.html"},
+			expectError: "--output-file cannot be combined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().StringP("output", "o", "text", "")
+			cmd.Flags().String("output-file", "", "")
+			cmd.Flags().String("template-file", "", "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() unexpected error: %v", err)
+			}
+
+			targets, err := outputTargetsFromFlags(cmd)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("outputTargetsFromFlags() error = %v, want error containing %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("outputTargetsFromFlags() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(targets, tt.expected) {
+				t.Errorf("outputTargetsFromFlags() = %+v, want %+v", targets, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEmitOutput(t *testing.T) {
+	dir := t.TempDir()
+	targets := []outputTarget{
+		{Format: "json", Path: filepath.Join(dir, "result.json")},
+		{Format: "html", Path: filepath.Join(dir, "report.html")},
+	}
+
+	err := emitOutput(targets, output.Options{}, func(formatter output.Formatter) (string, error) {
+		return reflect.TypeOf(formatter).String(), nil
+	})
+	if err != nil {
+		t.Fatalf("emitOutput() unexpected error: %v", err)
+	}
+
+	for path, want := range map[string]string{
+		"result.json": "*output.JSONFormatter",
+		"report.html": "*output.HTMLFormatter",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if string(data) != want {
+			t.Errorf("Expected %s to be written by %s, got %q", path, want, data)
+		}
+	}
+}
+
+func TestOutputOptionsFromFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantOnly    []string
+		wantHide    []string
+		wantWidth   int
+		wantTable   output.Options
+		expectError bool
+	}{
+		{
+			name: "no_filters",
+			args: []string{},
+		},
+		{
+			name:      "max_name_width",
+			args:      []string{"--max-name-width", "30"},
+			wantWidth: 30,
+		},
+		{
+			name:        "negative_max_name_width",
+			args:        []string{"--max-name-width", "-5"},
+			expectError: true,
+		},
+		{
+			name:     "only_and_hide",
+			args:     []string{"--only", "steps,drop_offs", "--hide", "zero-count"},
+			wantOnly: []string{"steps", "drop_offs"},
+			wantHide: []string{"zero-count"},
+		},
+		{
+			name:        "unknown_section",
+			args:        []string{"--only", "everything"},
+			expectError: true,
+		},
+		{
+			name:      "table_layout",
+			args:      []string{"--borders", "--wide"},
+			wantTable: output.Options{Borders: true, Wide: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().StringSlice("only", nil, "")
+			cmd.Flags().StringSlice("hide", nil, "")
+			cmd.Flags().Int("max-name-width", 0, "")
+			cmd.Flags().Bool("table", false, "")
+			cmd.Flags().Bool("borders", false, "")
+			cmd.Flags().Bool("wide", false, "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+
+			options, err := outputOptionsFromFlags(cmd)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error for invalid output options")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if strings.Join(options.Only, ",") != strings.Join(tt.wantOnly, ",") {
+				t.Errorf("Only = %v, want %v", options.Only, tt.wantOnly)
+			}
+			if strings.Join(options.Hide, ",") != strings.Join(tt.wantHide, ",") {
+				t.Errorf("Hide = %v, want %v", options.Hide, tt.wantHide)
+			}
+			if options.MaxNameWidth != tt.wantWidth {
+				t.Errorf("MaxNameWidth = %d, want %d", options.MaxNameWidth, tt.wantWidth)
+			}
+			if options.Table != tt.wantTable.Table || options.Borders != tt.wantTable.Borders || options.Wide != tt.wantTable.Wide {
+				t.Errorf("Table layout = %t/%t/%t, want %t/%t/%t", options.Table, options.Borders, options.Wide,
+					tt.wantTable.Table, tt.wantTable.Borders, tt.wantTable.Wide)
+			}
+		})
+	}
+}
+
+func TestApplyProjectDefaults(t *testing.T) {
+	newCommand := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "funnel"}
+		cmd.Flags().StringP("parser-config", "p", "", "")
+		cmd.Flags().StringP("output", "o", "text", "")
+		cmd.Flags().StringSlice("hide", nil, "")
+		cmd.Flags().Float64("min-conversion-rate", 0, "")
+		return cmd
+	}
+
+	projectCfg := &config.ProjectConfig{
+		Path: filepath.Join("/repo", config.ProjectConfigFile),
+		Commands: map[string]map[string]string{
+			"funnel": {
+				"parser-config":       "configs/parser.yaml",
+				"output":              "json",
+				"hide":                "zero-count,summary",
+				"min-conversion-rate": "75.5",
+			},
+		},
+	}
+
+	t.Run("applies defaults", func(t *testing.T) {
+		cmd := newCommand()
+		if err := applyProjectDefaults(cmd, projectCfg); err != nil {
+			t.Fatalf("applyProjectDefaults() unexpected error: %v", err)
+		}
+
+		parserConfig, _ := cmd.Flags().GetString("parser-config")
+		if parserConfig != filepath.Join("/repo", "configs", "parser.yaml") {
+			t.Errorf("parser-config = %q, want path relative to project file", parserConfig)
+		}
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat != "json" {
+			t.Errorf("output = %q, want %q", outputFormat, "json")
+		}
+		hide, _ := cmd.Flags().GetStringSlice("hide")
+		if strings.Join(hide, ",") != "zero-count,summary" {
+			t.Errorf("hide = %v, want [zero-count summary]", hide)
+		}
+		rate, _ := cmd.Flags().GetFloat64("min-conversion-rate")
+		if rate != 75.5 {
+			t.Errorf("min-conversion-rate = %v, want 75.5", rate)
+		}
+	})
+
+	t.Run("command line takes precedence", func(t *testing.T) {
+		cmd := newCommand()
+		if err := cmd.ParseFlags([]string{"--output", "text"}); err != nil {
+			t.Fatalf("ParseFlags() unexpected error: %v", err)
+		}
+		if err := applyProjectDefaults(cmd, projectCfg); err != nil {
+			t.Fatalf("applyProjectDefaults() unexpected error: %v", err)
+		}
+
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if outputFormat != "text" {
+			t.Errorf("output = %q, want command line value %q", outputFormat, "text")
+		}
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		unknownCfg := &config.ProjectConfig{
+			Path:     config.ProjectConfigFile,
+			Commands: map[string]map[string]string{"funnel": {"no-such-flag": "1"}},
+		}
+		err := applyProjectDefaults(newCommand(), unknownCfg)
+		if err == nil || !strings.Contains(err.Error(), "unknown flag 'no-such-flag'") {
+			t.Errorf("applyProjectDefaults() error = %v, want unknown flag error", err)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		invalidCfg := &config.ProjectConfig{
+			Path:     config.ProjectConfigFile,
+			Commands: map[string]map[string]string{"funnel": {"min-conversion-rate": "high"}},
+		}
+		err := applyProjectDefaults(newCommand(), invalidCfg)
+		if err == nil || !strings.Contains(err.Error(), "invalid value for flag 'min-conversion-rate'") {
+			t.Errorf("applyProjectDefaults() error = %v, want invalid value error", err)
+		}
+	})
+}
+
+func TestApplyCombinedConfig(t *testing.T) {
+	newCommand := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "funnel"}
+		cmd.Flags().StringP("parser-config", "p", "", "")
+		cmd.Flags().StringP("funnel-config", "f", "", "")
+		addConfigFlag(cmd)
+		return cmd
+	}
+
+	dir := t.TempDir()
+	combined := filepath.Join(dir, "loglion.yaml")
+	os.WriteFile(combined, []byte("parser:\n  event_regex: \"^(.*)$\"\nfunnel:\n  name: Flow\n  steps:\n    - name: A\n      event_pattern: a\n"), 0644)
+	parserOnly := filepath.Join(dir, "parser-only.yaml")
+	os.WriteFile(parserOnly, []byte("parser:\n  event_regex: \"^(.*)$\"\n"), 0644)
+
+	t.Run("sets both config flags", func(t *testing.T) {
+		cmd := newCommand()
+		cmd.ParseFlags([]string{"--config", combined})
+		if err := applyCombinedConfig(cmd); err != nil {
+			t.Fatalf("applyCombinedConfig() unexpected error: %v", err)
+		}
+		for _, name := range []string{"parser-config", "funnel-config"} {
+			if value, _ := cmd.Flags().GetString(name); value != combined {
+				t.Errorf("%s = %q, want %q", name, value, combined)
+			}
+		}
+	})
+
+	t.Run("separate files take precedence", func(t *testing.T) {
+		cmd := newCommand()
+		cmd.ParseFlags([]string{"--config", combined, "-f", "funnel.yaml"})
+		if err := applyCombinedConfig(cmd); err != nil {
+			t.Fatalf("applyCombinedConfig() unexpected error: %v", err)
+		}
+		if value, _ := cmd.Flags().GetString("funnel-config"); value != "funnel.yaml" {
+			t.Errorf("funnel-config = %q, want the command line value", value)
+		}
+	})
+
+	t.Run("missing section", func(t *testing.T) {
+		cmd := newCommand()
+		cmd.ParseFlags([]string{"--config", parserOnly})
+		if err := applyCombinedConfig(cmd); err != nil {
+			t.Fatalf("applyCombinedConfig() unexpected error: %v", err)
+		}
+		if cmd.Flags().Changed("funnel-config") {
+			t.Error("Expected funnel-config to stay unset without a funnel section")
+		}
+	})
+
+	t.Run("not a combined config", func(t *testing.T) {
+		separate := filepath.Join(dir, "parser.yaml")
+		os.WriteFile(separate, []byte("event_regex: \"^(.*)$\"\n"), 0644)
+		cmd := newCommand()
+		cmd.ParseFlags([]string{"--config", separate})
+		if err := applyCombinedConfig(cmd); err == nil {
+			t.Error("Expected an error for a config file without sections")
+		}
+	})
+}
+
+func TestLoadConfigOverrides(t *testing.T) {
+	t.Cleanup(func() { config.SetOverrides(nil) })
+
+	funnelPath := filepath.Join(t.TempDir(), "funnel.yaml")
+	os.WriteFile(funnelPath, []byte("name: Flow\nsteps:\n  - name: A\n    event_pattern: a\n"), 0644)
+
+	// --set takes precedence over the environment
+	environ := []string{"LOGLION_FUNNEL_NAME=From Env", "LOGLION_FUNNEL_STEPS__0__NAME=Open"}
+	if err := loadConfigOverrides(environ, []string{"funnel.name=From Flag"}); err != nil {
+		t.Fatalf("loadConfigOverrides() unexpected error: %v", err)
+	}
+	funnelCfg, err := config.LoadFunnelConfig(funnelPath)
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error: %v", err)
+	}
+	if funnelCfg.Name != "From Flag" || funnelCfg.Steps[0].Name != "Open" {
+		t.Errorf("Expected the overridden name and step, got %q and %q", funnelCfg.Name, funnelCfg.Steps[0].Name)
+	}
+
+	if err := loadConfigOverrides(nil, []string{"funnel.name"}); err == nil {
+		t.Error("Expected an error for an override without a value")
+	}
+}
+
+func TestEntryFilterFromFlags(t *testing.T) {
+	newCommand := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().String("level", "", "")
+		cmd.Flags().StringSlice("tag", nil, "")
+		cmd.Flags().StringSlice("exclude-tag", nil, "")
+		cmd.Flags().IntSlice("pid", nil, "")
+		return cmd
+	}
+
+	parserCfg := &config.ParserConfig{
+		Filter: config.FilterConfig{Level: "I", Tags: []string{"Analytics"}, ExcludeTags: []string{"chatty"}},
+	}
+
+	t.Run("uses parser config without flags", func(t *testing.T) {
+		filter, err := entryFilterFromFlags(newCommand(), parserCfg)
+		if err != nil {
+			t.Fatalf("entryFilterFromFlags() unexpected error: %v", err)
+		}
+		if filter.MinLevel != "I" || strings.Join(filter.Tags, ",") != "Analytics" || strings.Join(filter.ExcludeTags, ",") != "chatty" {
+			t.Errorf("entryFilterFromFlags() = %+v, want parser config filter", filter)
+		}
+	})
+
+	t.Run("flags override parser config", func(t *testing.T) {
+		cmd := newCommand()
+		if err := cmd.ParseFlags([]string{"--level", "error", "--tag", "Network,Http", "--pid", "12", "--pid", "34"}); err != nil {
+			t.Fatalf("ParseFlags() unexpected error: %v", err)
+		}
+
+		filter, err := entryFilterFromFlags(cmd, parserCfg)
+		if err != nil {
+			t.Fatalf("entryFilterFromFlags() unexpected error: %v", err)
+		}
+		if filter.MinLevel != "E" {
+			t.Errorf("MinLevel = %q, want %q", filter.MinLevel, "E")
+		}
+		if strings.Join(filter.Tags, ",") != "Network,Http" {
+			t.Errorf("Tags = %v, want [Network Http]", filter.Tags)
+		}
+		if strings.Join(filter.ExcludeTags, ",") != "chatty" {
+			t.Errorf("ExcludeTags = %v, want parser config value", filter.ExcludeTags)
+		}
+		if !reflect.DeepEqual(filter.PIDs, []int{12, 34}) {
+			t.Errorf("PIDs = %v, want [12 34]", filter.PIDs)
+		}
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		cmd := newCommand()
+		if err := cmd.ParseFlags([]string{"--level", "loud"}); err != nil {
+			t.Fatalf("ParseFlags() unexpected error: %v", err)
+		}
+		if _, err := entryFilterFromFlags(cmd, parserCfg); err == nil {
+			t.Error("entryFilterFromFlags() expected error for unknown level")
+		}
+	})
+}
+
+func TestReadLogFilesFromStdin(t *testing.T) {
+	readStdin := func(t *testing.T, terminal bool, logPatterns []string) (bool, string) {
+		original := isTerminal
+		isTerminal = func(*os.File) bool { return terminal }
+		defer func() { isTerminal = original }()
+
+		stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+		if err != nil {
+			t.Fatalf("Failed to create stderr file: %v", err)
+		}
+		originalStderr := os.Stderr
+		os.Stderr = stderr
+		defer func() { os.Stderr = originalStderr }()
+
+		root := &cobra.Command{Use: "loglion"}
+		cmd := &cobra.Command{Use: "count"}
+		addLogReadingFlags(cmd)
+		root.AddCommand(cmd)
+
+		_, _, ok := readLogFiles(cmd, &config.ParserConfig{}, parser.EntryFilter{}, logPatterns, false)
+		message, err := os.ReadFile(stderr.Name())
+		if err != nil {
+			t.Fatalf("Failed to read stderr: %v", err)
+		}
+		return ok, string(message)
+	}
+
+	t.Run("stdin is a terminal", func(t *testing.T) {
+		ok, message := readStdin(t, true, []string{stdinLog})
+		if ok {
+			t.Fatal("readLogFiles() expected to fail when stdin is a terminal")
+		}
+		for _, want := range []string{"no log to analyze", "pipe a log to stdin", "loglion count -p parser.yaml"} {
+			if !strings.Contains(message, want) {
+				t.Errorf("Expected error containing %q, got %q", want, message)
+			}
+		}
+	})
+
+	t.Run("stdin with log files", func(t *testing.T) {
+		ok, message := readStdin(t, false, []string{stdinLog, "app.log"})
+		if ok || !strings.Contains(message, "cannot be read from stdin and from log files at once") {
+			t.Errorf("readLogFiles() = %t, %q, want an error for stdin with log files", ok, message)
+		}
+	})
+}
+
+func TestResolveProjectPaths(t *testing.T) {
+	got := resolveProjectPaths("/repo", "logs/a.txt,/abs/b.txt")
+	want := filepath.Join("/repo", "logs", "a.txt") + ",/abs/b.txt"
+	if got != want {
+		t.Errorf("resolveProjectPaths() = %q, want %q", got, want)
+	}
+
+	url := "https://configs.example.com/funnel.yaml"
+	if got := resolveProjectPaths("/repo", url); got != url {
+		t.Errorf("resolveProjectPaths() = %q, want the URL unchanged", got)
+	}
+}
+
+func TestSetConfigVars(t *testing.T) {
+	t.Cleanup(func() { config.SetVars(nil) })
+
+	funnelPath := filepath.Join(t.TempDir(), "funnel.yaml")
+	os.WriteFile(funnelPath, []byte("name: Flow\nsteps:\n  - name: Open\n    event_pattern: \"^${LOGLION_TEST_PACKAGE} open$\"\n"), 0644)
+
+	if err := setConfigVars([]string{"LOGLION_TEST_PACKAGE=com.example.debug"}); err != nil {
+		t.Fatalf("setConfigVars() unexpected error: %v", err)
+	}
+	funnelCfg, err := config.LoadFunnelConfig(funnelPath)
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error: %v", err)
+	}
+	if funnelCfg.Steps[0].EventPattern != "^com.example.debug open$" {
+		t.Errorf("Expected the --var value, got %q", funnelCfg.Steps[0].EventPattern)
+	}
+
+	if err := setConfigVars([]string{"no-value"}); err == nil {
+		t.Error("Expected an error for a variable without a value")
+	}
+}
+
+func TestSetRemoteConfigOptions(t *testing.T) {
+	t.Cleanup(func() { config.SetRemoteOptions(config.RemoteOptions{}) })
+
+	if err := setRemoteConfigOptions([]string{"Authorization: Bearer a:b", "X-Team:growth"}, time.Second); err != nil {
+		t.Fatalf("setRemoteConfigOptions() unexpected error: %v", err)
+	}
+	for _, invalid := range []string{"Authorization", ": value"} {
+		if err := setRemoteConfigOptions([]string{invalid}, time.Second); err == nil {
+			t.Errorf("Expected an error for header %q", invalid)
+		}
+	}
+}
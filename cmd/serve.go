@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/server"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a REST API and web UI to upload logs and analyze them",
+	Long: `Serve command starts an HTTP server that analyzes logs uploaded by its clients, so a
+team can run analyses on one machine instead of installing LogLion everywhere.
+
+Every funnel config given with --funnel-config and patterns file given with
+--patterns-file is available under the name of its file without the extension,
+e.g. checkout for checkout.yaml. Uploaded logs are parsed with --parser-config.
+
+Open the server address in a browser for a web UI to upload logs, run configs and
+browse the results of analysis runs as charts and tables. Every analysis is kept as
+a run whose results can be downloaded as JSON or CSV; the latest 100 runs are kept.
+A cancelled run, or one whose client disconnected, keeps the result of the entries
+analyzed so far marked with "partial": true.
+
+At most --max-jobs analyses run at once, each holding its parsed log in memory;
+up to --queue-size more wait for a free job and further ones are rejected with
+503 Service Unavailable. Parsing stops with 413 Request Entity Too Large once a log
+decompresses to more than --max-log-bytes or has more than --max-entries entries,
+so the memory of every job is bounded by these limits rather than by the size of
+a possibly compressed upload.
+
+An uploaded log is deleted once a run analyzed it; upload it again to run another
+config. With --keep-logs, logs are kept for further runs until the last run of a
+log is dropped from the run history, or until they are deleted with
+DELETE /logs/{id}.
+
+Endpoints:
+  GET    /configs                    names of the funnel and count configs
+  POST   /logs?name=app.log          upload the request body as a log, returns its id
+  GET    /logs                       uploaded logs
+  GET    /logs/{id}                  an uploaded log
+  DELETE /logs/{id}                  delete an uploaded log
+  GET    /logs/{id}/funnels/{name}   run a funnel config, returns the JSON result
+  GET    /logs/{id}/counts/{name}    run a patterns file, returns the JSON result
+  GET    /runs                       analysis runs, latest first, including runs in progress
+  GET    /runs/{id}?format=csv       results of a run as JSON, or CSV with format=csv
+  DELETE /runs/{id}                  cancel a run in progress, returns its partial result
+  GET    /metrics                    latest results of every funnel and count run for Prometheus
+
+With --token, or comma-separated tokens in LOGLION_SERVE_TOKENS, API requests must
+send one of the tokens as "Authorization: Bearer <token>"; the web UI asks for it.
+Tokens are required unless the server listens on a loopback address such as the
+default localhost:8080. API requests are limited to --rate-limit per second per
+token, or per address without tokens, with bursts of --rate-burst; further
+requests are rejected with 429 Too Many Requests.
+
+Examples:
+  loglion serve -p parser.yaml --funnel-config checkout.yaml --patterns-file errors.txt
+  LOGLION_SERVE_TOKENS=s3cret loglion serve -p parser.yaml --funnel-config "funnels/*.yaml" --addr :8080
+
+  curl -H "Authorization: Bearer s3cret" --data-binary @app.log "localhost:8080/logs?name=app.log"
+  curl -H "Authorization: Bearer s3cret" localhost:8080/logs/<id>/funnels/checkout`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		parserConfigFile, _ := cmd.Flags().GetString("parser-config")
+		funnelConfigFiles, _ := cmd.Flags().GetStringSlice("funnel-config")
+		patternsFiles, _ := cmd.Flags().GetStringSlice("patterns-file")
+		addr, _ := cmd.Flags().GetString("addr")
+		dataDir, _ := cmd.Flags().GetString("data-dir")
+		maxUploadBytes, _ := cmd.Flags().GetInt64("max-upload-bytes")
+		maxJobs, _ := cmd.Flags().GetInt("max-jobs")
+		queueSize, _ := cmd.Flags().GetInt("queue-size")
+		maxLogBytes, _ := cmd.Flags().GetInt64("max-log-bytes")
+		maxEntries, _ := cmd.Flags().GetInt("max-entries")
+		keepLogs, _ := cmd.Flags().GetBool("keep-logs")
+		tokens, _ := cmd.Flags().GetStringSlice("token")
+		rateLimit, _ := cmd.Flags().GetFloat64("rate-limit")
+		rateBurst, _ := cmd.Flags().GetInt("rate-burst")
+		tokens = append(tokens, tokensFromEnv()...)
+
+		parserCfg, err := config.LoadParserConfig(parserConfigFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading parser config: %v\n", err)
+			os.Exit(1)
+		}
+		funnels, counts, err := loadNamedConfigs(funnelConfigFiles, patternsFiles)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if dataDir == "" {
+			if dataDir, err = os.MkdirTemp("", "loglion-serve-"); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to create data directory: %v\n", err)
+				os.Exit(1)
+			}
+			defer os.RemoveAll(dataDir)
+		} else if err := os.MkdirAll(dataDir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create data directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		handler, err := server.New(server.Options{
+			Parser:         parserCfg,
+			Funnels:        funnels,
+			Counts:         counts,
+			DataDir:        dataDir,
+			MaxUploadBytes: maxUploadBytes,
+			MaxJobs:        maxJobs,
+			QueueSize:      queueSize,
+			MaxLogBytes:    maxLogBytes,
+			MaxEntries:     maxEntries,
+			KeepLogs:       keepLogs,
+			Tokens:         tokens,
+			RateLimit:      rateLimit,
+			RateBurst:      rateBurst,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(tokens) == 0 && !isLoopback(listener.Addr()) {
+			listener.Close()
+			fmt.Fprintf(os.Stderr, "Error: serving on %s, which is reachable from other machines, requires --token or %s\n", listener.Addr(), serveTokensEnv)
+			os.Exit(1)
+		}
+		logrus.WithFields(logrus.Fields{
+			"addr":     listener.Addr().String(),
+			"data_dir": dataDir,
+			"funnels":  len(funnels),
+			"counts":   len(counts),
+			"auth":     len(tokens) > 0,
+		}).Info("Starting server")
+		fmt.Fprintf(os.Stderr, "Serving on http://%s\n", listener.Addr())
+
+		// Stop serving on Ctrl+C, letting running requests finish
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		httpServer := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			httpServer.Shutdown(shutdownCtx)
+		}()
+
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		logrus.Info("Server stopped")
+	},
+}
+
+// serveTokensEnv holds comma-separated bearer tokens of serve, which keeps
+// them out of the process list unlike --token
+const serveTokensEnv = "LOGLION_SERVE_TOKENS"
+
+// tokensFromEnv returns the tokens of serveTokensEnv
+func tokensFromEnv() []string {
+	var tokens []string
+	for _, token := range strings.Split(os.Getenv(serveTokensEnv), ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// isLoopback reports whether addr only accepts connections from this machine
+func isLoopback(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	return ok && tcpAddr.IP.IsLoopback()
+}
+
+// loadNamedConfigs loads the funnel configs and patterns files of serve,
+// named after their files. Paths may be glob patterns.
+func loadNamedConfigs(funnelConfigFiles, patternsFiles []string) (map[string][]*config.FunnelConfig, map[string]server.CountConfig, error) {
+	funnels := map[string][]*config.FunnelConfig{}
+	counts := map[string]server.CountConfig{}
+	names := map[string]string{}
+
+	add := func(path string) (string, error) {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if previous, exists := names[name]; exists {
+			return "", fmt.Errorf("%s and %s have the same config name '%s'", previous, path, name)
+		}
+		names[name] = path
+		return name, nil
+	}
+
+	for _, pattern := range funnelConfigFiles {
+		paths, err := expandConfigPattern(pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, path := range paths {
+			name, err := add(path)
+			if err != nil {
+				return nil, nil, err
+			}
+			if funnels[name], err = config.LoadFunnelConfigs(path); err != nil {
+				return nil, nil, fmt.Errorf("failed to load funnel config %s: %w", path, err)
+			}
+		}
+	}
+
+	for _, pattern := range patternsFiles {
+		paths, err := expandConfigPattern(pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, path := range paths {
+			name, err := add(path)
+			if err != nil {
+				return nil, nil, err
+			}
+			patterns, labels, err := loadPatternsFile(path)
+			if err != nil {
+				return nil, nil, err
+			}
+			counts[name] = server.CountConfig{Patterns: patterns, Labels: labels}
+		}
+	}
+
+	if len(funnels) == 0 && len(counts) == 0 {
+		return nil, nil, fmt.Errorf("at least one --funnel-config or --patterns-file is required")
+	}
+	return funnels, counts, nil
+}
+
+// expandConfigPattern returns the files matching a glob pattern, or the path
+// itself if it is not a pattern
+func expandConfigPattern(pattern string) ([]string, error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config pattern '%s': %w", pattern, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no config files match '%s'", pattern)
+	}
+	return paths, nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringP("parser-config", "p", "", "Path to parser configuration file (required)")
+	serveCmd.Flags().StringSlice("funnel-config", nil, "Funnel config file or glob pattern to serve, named after the file (repeatable)")
+	serveCmd.Flags().StringSlice("patterns-file", nil, "Patterns file or glob pattern to serve as a count config, named after the file (repeatable)")
+	serveCmd.Flags().String("addr", "localhost:8080", "Address to listen on")
+	serveCmd.Flags().String("data-dir", "", "Directory to store uploaded logs in (default: a temporary directory removed on exit)")
+	serveCmd.Flags().Int64("max-upload-bytes", server.DefaultMaxUploadBytes, "Reject uploaded logs larger than this many bytes")
+	serveCmd.Flags().Int("max-jobs", server.DefaultMaxJobs, "Number of analyses run at once")
+	serveCmd.Flags().Int("queue-size", server.DefaultQueueSize, "Number of analyses waiting for a free job; further analyses are rejected with 503")
+	serveCmd.Flags().Int64("max-log-bytes", server.DefaultMaxLogBytes, "Stop analyses of logs larger than this many bytes once decompressed, with 413")
+	serveCmd.Flags().Int("max-entries", server.DefaultMaxEntries, "Stop analyses of logs with more entries than this, with 413")
+	serveCmd.Flags().Bool("keep-logs", false, "Keep uploaded logs for further runs until their runs leave the run history, instead of deleting them once analyzed")
+	serveCmd.Flags().StringSlice("token", nil, "Bearer token accepted by the API (repeatable); required unless listening on a loopback address, also read from "+serveTokensEnv)
+	serveCmd.Flags().Float64("rate-limit", server.DefaultRateLimit, "API requests per second allowed per token, or per address without tokens (0 for no limit)")
+	serveCmd.Flags().Int("rate-burst", server.DefaultRateBurst, "API requests a client can make at once before --rate-limit applies")
+
+	serveCmd.MarkFlagRequired("parser-config")
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServeCommandFlags(t *testing.T) {
+	cmd := serveCmd
+
+	expectedFlags := map[string]struct {
+		shorthand  string
+		valueType  string
+		defaultVal string
+	}{
+		"parser-config":    {"p", "string", ""},
+		"funnel-config":    {"", "stringSlice", "[]"},
+		"patterns-file":    {"", "stringSlice", "[]"},
+		"addr":             {"", "string", "localhost:8080"},
+		"data-dir":         {"", "string", ""},
+		"max-upload-bytes": {"", "int64", "268435456"},
+		"max-jobs":         {"", "int", "2"},
+		"queue-size":       {"", "int", "16"},
+		"max-log-bytes":    {"", "int64", "1073741824"},
+		"max-entries":      {"", "int", "2000000"},
+		"keep-logs":        {"", "bool", "false"},
+		"token":            {"", "stringSlice", "[]"},
+		"rate-limit":       {"", "float64", "10"},
+		"rate-burst":       {"", "int", "20"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Shorthand != expected.shorthand {
+			t.Errorf("Expected %s shorthand to be %q, got %q", flagName, expected.shorthand, flag.Shorthand)
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+}
+
+func TestServeCommandProperties(t *testing.T) {
+	cmd := serveCmd
+
+	if cmd.Use != "serve" {
+		t.Errorf("Expected Use to be 'serve', got %q", cmd.Use)
+	}
+
+	if !strings.Contains(cmd.Long, "Examples:") {
+		t.Error("Expected Long description to contain examples")
+	}
+
+	flag := cmd.Flags().Lookup("parser-config")
+	if flag == nil || len(flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]) == 0 {
+		t.Error("Expected parser-config flag to be required")
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:8080", true},
+		{"[::1]:8080", true},
+		{"0.0.0.0:8080", false},
+		{"[::]:8080", false},
+		{"192.168.1.10:8080", false},
+	}
+	for _, tt := range tests {
+		addr, err := net.ResolveTCPAddr("tcp", tt.addr)
+		if err != nil {
+			t.Fatalf("ResolveTCPAddr(%s) unexpected error: %v", tt.addr, err)
+		}
+		if got := isLoopback(addr); got != tt.want {
+			t.Errorf("isLoopback(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestTokensFromEnv(t *testing.T) {
+	t.Setenv(serveTokensEnv, " first, ,second ")
+	tokens := tokensFromEnv()
+	if len(tokens) != 2 || tokens[0] != "first" || tokens[1] != "second" {
+		t.Errorf("tokensFromEnv() = %q, want [first second]", tokens)
+	}
+}
+
+func TestLoadNamedConfigs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	funnel := "name: Checkout\nsteps:\n  - name: Cart\n    event_pattern: cart\n  - name: Pay\n    event_pattern: pay\n"
+	writeFile("checkout.yaml", funnel)
+	writeFile("signup.yaml", strings.ReplaceAll(funnel, "Checkout", "Signup"))
+	errors := writeFile("errors.txt", "Crashes\tcrash\ntimeout\n")
+
+	funnels, counts, err := loadNamedConfigs([]string{filepath.Join(dir, "*.yaml")}, []string{errors})
+	if err != nil {
+		t.Fatalf("loadNamedConfigs() unexpected error: %v", err)
+	}
+	if len(funnels) != 2 || funnels["checkout"][0].Name != "Checkout" || funnels["signup"][0].Name != "Signup" {
+		t.Errorf("Unexpected funnels: %v", funnels)
+	}
+	if count := counts["errors"]; len(count.Patterns) != 2 || count.Labels[0] != "Crashes" {
+		t.Errorf("Unexpected counts: %+v", counts)
+	}
+
+	duplicate := writeFile("checkout.txt", "cart\n")
+	if _, _, err := loadNamedConfigs([]string{filepath.Join(dir, "checkout.yaml")}, []string{duplicate}); err == nil || !strings.Contains(err.Error(), "same config name 'checkout'") {
+		t.Errorf("loadNamedConfigs() error = %v, want duplicate name error", err)
+	}
+	if _, _, err := loadNamedConfigs(nil, nil); err == nil {
+		t.Error("Expected error without configs")
+	}
+	if _, _, err := loadNamedConfigs([]string{filepath.Join(dir, "*.yml")}, nil); err == nil || !strings.Contains(err.Error(), "no config files match") {
+		t.Errorf("loadNamedConfigs() error = %v, want no match error", err)
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Report session statistics for log files",
+	Long: `Sessions command groups log entries into sessions and reports the session count,
+the distribution of session durations and events per session.
+
+Entries are grouped by the --session-key event property (e.g. session_id or user_id),
+or treated as one stream without it. A gap longer than --idle-timeout between two
+events of the same key starts a new session.
+
+Examples:
+  loglion sessions --parser-config parser.yaml --log logcat.txt --session-key session_id
+  loglion sessions -p parser.yaml -l logcat.txt --idle-timeout 10m
+  loglion sessions -p parser.yaml -l logcat.txt --session-key user_id --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		parserConfigFile, _ := cmd.Flags().GetString("parser-config")
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		sessionKey, _ := cmd.Flags().GetString("session-key")
+		idleTimeout, _ := cmd.Flags().GetDuration("idle-timeout")
+
+		logrus.WithFields(logrus.Fields{
+			"parser_config_file": parserConfigFile,
+			"log_files":          logPatterns,
+			"output_format":      outputFormat,
+			"session_key":        sessionKey,
+			"idle_timeout":       idleTimeout,
+		}).Info("Starting session analysis")
+
+		sessionAnalyzer, err := analyzer.NewSessionAnalyzer(sessionKey, idleTimeout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries := loadLogEntries(cmd)
+
+		logrus.Debug("Starting session analysis")
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := sessionAnalyzer.AnalyzeSessionsContext(ctx, entries)
+		stop()
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, output.Options{})
+
+		logrus.Debug("Formatting session analysis results")
+		formattedOutput, err := formatter.FormatSessions(result)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format session analysis output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Session analysis completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionsCmd)
+
+	addLogInputFlags(sessionsCmd)
+	sessionsCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+
+	sessionsCmd.Flags().String("session-key", "", "Event property that identifies a session, e.g. session_id (default: whole log)")
+	sessionsCmd.Flags().Duration("idle-timeout", 30*time.Minute, "Start a new session after this long without events (0 to disable)")
+}
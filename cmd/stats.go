@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report an overview of log files",
+	Long: `Stats command parses log files and reports an overview of them: total lines,
+parsed versus skipped lines, the time span covered, entries per level and tag,
+the number of distinct events and the rate of entries per minute. Use it as a
+quick health check before deeper analysis.
+
+Examples:
+  loglion stats --parser-config parser.yaml --log logcat.txt
+  loglion stats -p parser.yaml -l "logs/*.txt" -n 25
+  loglion stats -p parser.yaml -l logcat.txt --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		limit, _ := cmd.Flags().GetInt("limit")
+		maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":     logPatterns,
+			"output_format": outputFormat,
+			"limit":         limit,
+		}).Info("Starting log overview analysis")
+
+		overviewAnalyzer, err := analyzer.NewOverviewAnalyzer(limit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		outputOptions := output.Options{MaxNameWidth: maxNameWidth}
+		if err := outputOptions.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries, parseStats := readLogEntries(cmd, false)
+
+		logrus.Debug("Starting log overview analysis")
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := overviewAnalyzer.AnalyzeOverviewContext(ctx, entries, parseStats)
+		stop()
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, outputOptions)
+
+		logrus.Debug("Formatting log overview results")
+		formattedOutput, err := formatter.FormatOverview(result)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format log overview output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Log overview analysis completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	addLogInputFlags(statsCmd)
+	statsCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	statsCmd.Flags().Int("max-name-width", 0, "Truncate tag names longer than this in text output (0 = no limit)")
+
+	statsCmd.Flags().IntP("limit", "n", 10, "Number of tags to report (0 = all tags)")
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/parfenovvs/loglion/internal/suggest"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest a parser config from sample events",
+	Long: `Suggest command finds log lines containing an event you know is in the log
+and proposes parser configs for them: a log_line_regex for the line format and
+an event_regex extracting the event JSON, with special characters escaped.
+Every suggestion is checked against the sample lines before it is printed.
+
+Examples:
+  loglion suggest --log logcat.txt --event purchase_completed
+  loglion suggest -l logcat.txt -e purchase_completed --samples 20 > parser.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		event, _ := cmd.Flags().GetString("event")
+		sampleLimit, _ := cmd.Flags().GetInt("samples")
+
+		logrus.WithFields(logrus.Fields{
+			"log_files": logPatterns,
+			"event":     event,
+			"samples":   sampleLimit,
+		}).Info("Starting parser config suggestion")
+
+		if event == "" {
+			fmt.Fprintf(os.Stderr, "Error: --event must not be empty\n")
+			os.Exit(1)
+		}
+		if sampleLimit < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --samples must be at least 1, got %d\n", sampleLimit)
+			os.Exit(1)
+		}
+
+		logFiles, err := parser.ResolveLogFiles(logPatterns, false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving log files: %v\n", err)
+			os.Exit(1)
+		}
+
+		samples, total, err := suggest.FindSamples(logFiles, event, sampleLimit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading log file: %v\n", err)
+			os.Exit(1)
+		}
+		if total == 0 {
+			fmt.Fprintf(os.Stderr, "Error: no log lines contain '%s'\n", event)
+			os.Exit(1)
+		}
+
+		result := suggest.Suggest(event, samples)
+		if len(result.Suggestions) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: no parser config parses all %d sample lines\n", len(samples))
+			os.Exit(1)
+		}
+
+		output, err := formatSuggestions(result, total)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("suggestions", len(result.Suggestions)).Info("Parser config suggestion completed successfully")
+		fmt.Print(output)
+	},
+}
+
+// formatSuggestions renders the suggestions as commented YAML documents, so
+// the output can be redirected into a parser config and trimmed to one
+func formatSuggestions(result *suggest.Result, total int) (string, error) {
+	var out strings.Builder
+
+	out.WriteString(fmt.Sprintf("# %d lines contain '%s', suggestions are based on %d:\n", total, result.Event, len(result.Samples)))
+	for _, sample := range result.Samples {
+		out.WriteString(fmt.Sprintf("#   %s\n", sample))
+	}
+
+	for i, suggestion := range result.Suggestions {
+		data, err := yaml.Marshal(suggestion.Config)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal parser config: %w", err)
+		}
+
+		out.WriteString("---\n")
+		out.WriteString(fmt.Sprintf("# Suggestion %d: %s\n", i+1, suggestion.Layout))
+		out.WriteString(fmt.Sprintf("# Matches the event with event_pattern: %s\n", strconv.Quote(suggestion.EventPattern)))
+		out.Write(data)
+	}
+
+	return out.String(), nil
+}
+
+func init() {
+	rootCmd.AddCommand(suggestCmd)
+
+	suggestCmd.Flags().StringSliceP("log", "l", nil, "Path or glob pattern of log files to search (repeatable)")
+	suggestCmd.Flags().StringP("event", "e", "", "Event name or other text that appears in the lines to parse")
+	suggestCmd.Flags().Int("samples", 5, "Number of matching lines to base the suggestions on")
+
+	suggestCmd.MarkFlagRequired("log")
+	suggestCmd.MarkFlagRequired("event")
+}
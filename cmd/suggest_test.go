@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/suggest"
+)
+
+func TestSuggestCommandFlags(t *testing.T) {
+	cmd := suggestCmd
+
+	expectedFlags := map[string]struct {
+		shorthand  string
+		valueType  string
+		defaultVal string
+	}{
+		"log":     {"l", "stringSlice", "[]"},
+		"event":   {"e", "string", ""},
+		"samples": {"", "int", "5"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Shorthand != expected.shorthand {
+			t.Errorf("Expected %s shorthand to be %q, got %q", flagName, expected.shorthand, flag.Shorthand)
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+
+	for _, required := range []string{"log", "event"} {
+		flag := cmd.Flags().Lookup(required)
+		if flag == nil || len(flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]) == 0 {
+			t.Errorf("Expected %s flag to be required", required)
+		}
+	}
+}
+
+func TestFormatSuggestions(t *testing.T) {
+	result := suggest.Suggest("login", []string{`12:00:01 INFO login "quoted"`})
+
+	output, err := formatSuggestions(result, 3)
+	if err != nil {
+		t.Fatalf("formatSuggestions() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"# 3 lines contain 'login', suggestions are based on 1:",
+		`#   12:00:01 INFO login "quoted"`,
+		"# Suggestion 1: Time of day and level",
+		`# Matches the event with event_pattern: "login"`,
+		`timestamp_format: "15:04:05"`,
+		`log_line_regex: ^(\d{2}:\d{2}:\d{2}(?:\.\d+)?)\s+()()\[?([A-Z]+)\]?\s+()(.*)$`,
+		"# Suggestion 2: Whole line",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var timelineCmd = &cobra.Command{
+	Use:   "timeline [event_patterns...]",
+	Short: "Show how matching events are distributed over time",
+	Long: `Timeline command buckets events matching any of the given patterns into fixed
+time intervals and renders them as a histogram, or as a JSON series with --output json.
+Without patterns every entry is included. Intervals without events are shown too,
+which makes it easy to spot when events stopped flowing during a test run.
+
+Entries without a timestamp are counted but not placed on the timeline. Long logs are
+bucketed into a multiple of --interval to keep at most 1000 intervals.
+
+Examples:
+  loglion timeline --parser-config parser.yaml --log logcat.txt
+  loglion timeline -p parser.yaml -l logcat.txt --interval 5m "purchase" "error"
+  loglion timeline -p parser.yaml -l logcat.txt --interval 1h --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":      logPatterns,
+			"output_format":  outputFormat,
+			"interval":       interval,
+			"event_patterns": args,
+		}).Info("Starting timeline analysis")
+
+		timelineAnalyzer, err := analyzer.NewTimelineAnalyzer(args, interval, analyzer.CountOptions{IgnoreCase: ignoreCase})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating timeline analyzer: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries := loadLogEntries(cmd)
+
+		logrus.Debug("Starting timeline analysis")
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := timelineAnalyzer.AnalyzeTimelineContext(ctx, entries)
+		stop()
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, output.Options{})
+
+		logrus.Debug("Formatting timeline results")
+		formattedOutput, err := formatter.FormatTimeline(result)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format timeline output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Timeline analysis completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(timelineCmd)
+
+	addLogInputFlags(timelineCmd)
+	timelineCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+
+	timelineCmd.Flags().Duration("interval", time.Minute, "Length of each timeline interval (e.g. 1m, 5m, 1h)")
+	timelineCmd.Flags().BoolP("ignore-case", "i", false, "Match patterns regardless of case")
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTimelineCommandFlags(t *testing.T) {
+	cmd := timelineCmd
+
+	expectedFlags := map[string]struct {
+		shorthand  string
+		valueType  string
+		defaultVal string
+	}{
+		"parser-config":     {"p", "string", ""},
+		"config":            {"c", "string", ""},
+		"log":               {"l", "stringSlice", "[]"},
+		"interval":          {"", "duration", "1m0s"},
+		"ignore-case":       {"i", "bool", "false"},
+		"output":            {"o", "string", "text"},
+		"parse-stats":       {"", "bool", "false"},
+		"strict":            {"", "bool", "false"},
+		"workers":           {"", "int", "1"},
+		"max-line-bytes":    {"", "int", "1048576"},
+		"sort-by-timestamp": {"", "bool", "false"},
+		"dedupe":            {"", "bool", "false"},
+		"dedupe-window":     {"", "duration", "1s"},
+		"dedupe-key":        {"", "stringSlice", "[]"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Shorthand != expected.shorthand {
+			t.Errorf("Expected %s shorthand to be %q, got %q", flagName, expected.shorthand, flag.Shorthand)
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+}
+
+func TestTimelineCommandProperties(t *testing.T) {
+	cmd := timelineCmd
+
+	if cmd.Use != "timeline [event_patterns...]" {
+		t.Errorf("Expected Use to be 'timeline [event_patterns...]', got %q", cmd.Use)
+	}
+
+	if !strings.Contains(cmd.Long, "Examples:") {
+		t.Error("Expected Long description to contain examples")
+	}
+
+	if cmd.Run == nil {
+		t.Error("Run function should not be nil")
+	}
+
+	for _, required := range []string{"parser-config", "log"} {
+		flag := cmd.Flags().Lookup(required)
+		if flag == nil || len(flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]) == 0 {
+			t.Errorf("Expected %s flag to be required", required)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Report the most frequent events in log files",
+	Long: `Top command parses log files and reports the most frequent events with their
+counts and percentages, without any predefined patterns. Events are the "event"
+field of structured entries, or the full message otherwise. Use it to explore an
+unfamiliar log before writing funnel configs or count patterns.
+
+Examples:
+  loglion top --parser-config parser.yaml --log logcat.txt
+  loglion top -p parser.yaml -l logcat.txt -n 25 --tag Analytics
+  loglion top -p parser.yaml -l logcat.txt --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logPatterns, _ := cmd.Flags().GetStringSlice("log")
+		outputFormat, _ := cmd.Flags().GetString("output")
+		limit, _ := cmd.Flags().GetInt("limit")
+		maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+
+		logrus.WithFields(logrus.Fields{
+			"log_files":     logPatterns,
+			"output_format": outputFormat,
+			"limit":         limit,
+		}).Info("Starting top events analysis")
+
+		topAnalyzer, err := analyzer.NewTopAnalyzer(limit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		outputOptions := output.Options{MaxNameWidth: maxNameWidth}
+		if err := outputOptions.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries := loadLogEntries(cmd)
+
+		logrus.Debug("Starting top events analysis")
+		// Stop analysis on Ctrl+C and still report the partial result
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		result := topAnalyzer.AnalyzeTopContext(ctx, entries)
+		stop()
+
+		// Format and output results
+		formatter := newOutputFormatter(outputFormat, outputOptions)
+
+		logrus.Debug("Formatting top events results")
+		formattedOutput, err := formatter.FormatTop(result)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to format top events output")
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			os.Exit(1)
+		}
+
+		logrus.WithField("output_length", len(formattedOutput)).Info("Top events analysis completed successfully")
+		fmt.Print(formattedOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+
+	addLogInputFlags(topCmd)
+	topCmd.Flags().StringP("output", "o", "text", "Output format (json, text)")
+	topCmd.Flags().Int("max-name-width", 0, "Truncate event names longer than this in text output (0 = no limit)")
+
+	topCmd.Flags().IntP("limit", "n", 10, "Number of events to report (0 = all events)")
+}
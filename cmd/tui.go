@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/parfenovvs/loglion/internal/source"
+	"github.com/parfenovvs/loglion/internal/tui"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// tuiBatchInterval is how often parsed entries are handed to the dashboard
+const tuiBatchInterval = 100 * time.Millisecond
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Show a live funnel dashboard in the terminal",
+	Long: `TUI command shows an interactive dashboard of funnel analysis that updates as
+log lines arrive: the progress of every step, the most recent events matching a
+step and the drop-offs between steps.
+
+With --log, the log file is followed like tail -f, e.g. while logcat is redirected
+to it. Otherwise "adb logcat" is streamed from a connected device as with the adb
+command.
+
+Keys: ←/→ or 1-9 filter recent events by step, 0 or Esc shows all steps, Tab
+switches between funnels and q quits.
+
+Examples:
+  loglion tui -p logcat-parser.yaml -f funnel.yaml
+  loglion tui -p logcat-parser.yaml -f funnel.yaml --device emulator-5554 --tag Analytics
+  loglion tui -p logcat-parser.yaml -f funnel.yaml -l repro.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		parserConfigFile, _ := cmd.Flags().GetString("parser-config")
+		funnelConfigFile, _ := cmd.Flags().GetString("funnel-config")
+		logFile, _ := cmd.Flags().GetString("log")
+		device, _ := cmd.Flags().GetString("device")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		maxLineBytes, _ := cmd.Flags().GetInt("max-line-bytes")
+
+		logrus.WithFields(logrus.Fields{
+			"parser_config_file": parserConfigFile,
+			"funnel_config_file": funnelConfigFile,
+			"log_file":           logFile,
+			"device":             device,
+			"tags":               tags,
+		}).Info("Starting dashboard")
+
+		if logFile != "" && device != "" {
+			fmt.Fprintf(os.Stderr, "Error: --device cannot be combined with --log\n")
+			os.Exit(1)
+		}
+		if maxLineBytes < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --max-line-bytes must be at least 1, got %d\n", maxLineBytes)
+			os.Exit(1)
+		}
+
+		// Load parser configuration
+		logrus.Debug("Loading parser configuration file")
+		parserCfg, err := config.LoadParserConfig(parserConfigFile)
+		if err != nil {
+			logrus.WithError(err).WithField("parser_config_file", parserConfigFile).Error("Failed to load parser config")
+			fmt.Fprintf(os.Stderr, "Error loading parser config: %v\n", err)
+			os.Exit(1)
+		}
+
+		entryFilter, err := entryFilterFromFlags(cmd, parserCfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Load funnel configuration
+		logrus.Debug("Loading funnel configuration file")
+		funnelCfgs, err := config.LoadFunnelConfigs(funnelConfigFile)
+		if err != nil {
+			logrus.WithError(err).WithField("funnel_config_file", funnelConfigFile).Error("Failed to load funnel config")
+			fmt.Fprintf(os.Stderr, "Error loading funnel config: %v\n", err)
+			os.Exit(1)
+		}
+
+		logParser, err := parserCfg.NewParser()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating log parser: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// Start the log source
+		var lines io.ReadCloser
+		var wait func() error
+		sourceName := "adb logcat"
+		if logFile != "" {
+			sourceName = logFile
+			lines, err = source.NewFileSource(logFile, 0).Start(ctx)
+			wait = func() error { return nil }
+		} else {
+			adb := source.NewADBSource(source.ADBOptions{Serial: device, Tags: tags})
+			lines, err = adb.Start(ctx)
+			wait = adb.Wait
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting log source: %v\n", err)
+			os.Exit(1)
+		}
+
+		program := tea.NewProgram(tui.New(sourceName, funnelCfgs), tea.WithAltScreen(), tea.WithContext(ctx))
+		go func() {
+			err := streamEntries(lines, logParser, entryFilter, maxLineBytes, func(entries []*parser.LogEntry) {
+				program.Send(tui.EntriesMsg(entries))
+			})
+			if err == nil && ctx.Err() == nil {
+				err = wait()
+			}
+			program.Send(tui.SourceEndedMsg{Err: err})
+		}()
+
+		if _, err := program.Run(); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Error("Dashboard failed")
+			fmt.Fprintf(os.Stderr, "Error running dashboard: %v\n", err)
+			os.Exit(1)
+		}
+		stop()
+		lines.Close()
+		logrus.Info("Dashboard closed")
+	},
+}
+
+// streamEntries parses the log lines read from r and passes the entries
+// matching filter to send in batches, at most every tuiBatchInterval. It
+// returns when r ends.
+func streamEntries(r io.Reader, logParser parser.Parser, filter parser.EntryFilter, maxLineBytes int, send func([]*parser.LogEntry)) error {
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		reader := parser.NewLineReader(r, maxLineBytes)
+		for {
+			line, oversized, err := reader.ReadLine()
+			if err != nil {
+				if err != io.EOF {
+					readErr <- err
+				}
+				return
+			}
+			if oversized {
+				logrus.WithField("max_line_bytes", maxLineBytes).Warn("Skipped an oversized log line")
+				continue
+			}
+			lines <- line
+		}
+	}()
+
+	ticker := time.NewTicker(tuiBatchInterval)
+	defer ticker.Stop()
+
+	var batch []*parser.LogEntry
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if len(batch) > 0 {
+					send(batch)
+				}
+				select {
+				case err := <-readErr:
+					return fmt.Errorf("failed to read log lines: %w", err)
+				default:
+					return nil
+				}
+			}
+			entry, err := logParser.Parse(line)
+			if err != nil {
+				logrus.WithError(err).WithField("line", line).Debug("Failed to parse log line, skipping")
+				continue
+			}
+			if filter.Matches(entry) {
+				batch = append(batch, entry)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				send(batch)
+				batch = nil
+			}
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+
+	tuiCmd.Flags().StringP("parser-config", "p", "", "Path to parser configuration file (required)")
+	tuiCmd.Flags().StringP("funnel-config", "f", "", "Path to funnel configuration file (required)")
+	addConfigFlag(tuiCmd)
+	tuiCmd.Flags().StringP("log", "l", "", "Follow this log file instead of streaming adb logcat")
+	tuiCmd.Flags().StringP("device", "s", "", "Serial of the device to capture from (see 'adb devices')")
+	tuiCmd.Flags().StringSlice("tag", nil, "Only analyze log lines with these tags (repeatable)")
+	tuiCmd.Flags().String("level", "", "Only analyze entries with this log level or more severe (V, D, I, W, E, F)")
+	tuiCmd.Flags().StringSlice("exclude-tag", nil, "Skip entries with these tags (repeatable)")
+	tuiCmd.Flags().IntSlice("pid", nil, "Only analyze entries from these process IDs (repeatable)")
+	tuiCmd.Flags().Int("max-line-bytes", parser.DefaultMaxLineBytes, "Skip log lines longer than this many bytes")
+
+	tuiCmd.MarkFlagRequired("parser-config")
+	tuiCmd.MarkFlagRequired("funnel-config")
+}
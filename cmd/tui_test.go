@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestTUICommandFlags(t *testing.T) {
+	cmd := tuiCmd
+
+	expectedFlags := map[string]struct {
+		shorthand  string
+		valueType  string
+		defaultVal string
+	}{
+		"parser-config": {"p", "string", ""},
+		"config":        {"c", "string", ""},
+		"funnel-config": {"f", "string", ""},
+		"log":           {"l", "string", ""},
+		"device":        {"s", "string", ""},
+		"tag":           {"", "stringSlice", "[]"},
+		"level":         {"", "string", ""},
+		"exclude-tag":   {"", "stringSlice", "[]"},
+		"pid":           {"", "intSlice", "[]"},
+	}
+
+	for flagName, expected := range expectedFlags {
+		flag := cmd.Flags().Lookup(flagName)
+		if flag == nil {
+			t.Errorf("Expected %s flag to exist", flagName)
+			continue
+		}
+		if flag.Shorthand != expected.shorthand {
+			t.Errorf("Expected %s shorthand to be %q, got %q", flagName, expected.shorthand, flag.Shorthand)
+		}
+		if flag.Value.Type() != expected.valueType {
+			t.Errorf("Expected %s to be of type %s, got %s", flagName, expected.valueType, flag.Value.Type())
+		}
+		if flag.DefValue != expected.defaultVal {
+			t.Errorf("Expected %s default value to be %q, got %q", flagName, expected.defaultVal, flag.DefValue)
+		}
+	}
+}
+
+func TestTUICommandProperties(t *testing.T) {
+	cmd := tuiCmd
+
+	if cmd.Use != "tui" {
+		t.Errorf("Expected Use to be 'tui', got %q", cmd.Use)
+	}
+
+	if !strings.Contains(cmd.Long, "Examples:") {
+		t.Error("Expected Long description to contain examples")
+	}
+
+	if cmd.Run == nil {
+		t.Error("Run function should not be nil")
+	}
+
+	for _, required := range []string{"parser-config", "funnel-config"} {
+		flag := cmd.Flags().Lookup(required)
+		if flag == nil || len(flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]) == 0 {
+			t.Errorf("Expected %s flag to be required", required)
+		}
+	}
+}
+
+func TestStreamEntries(t *testing.T) {
+	parserCfg := &config.ParserConfig{EventRegex: `^(\w+)`}
+	logParser, err := parserCfg.NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() unexpected error: %v", err)
+	}
+
+	var received []string
+	input := strings.NewReader("login\n" + strings.Repeat("x", 100) + "\npurchase\n")
+	err = streamEntries(input, logParser, parser.EntryFilter{}, 50, func(entries []*parser.LogEntry) {
+		for _, entry := range entries {
+			received = append(received, entry.Message)
+		}
+	})
+	if err != nil {
+		t.Fatalf("streamEntries() unexpected error: %v", err)
+	}
+
+	// Oversized lines are skipped
+	if strings.Join(received, ",") != "login,purchase" {
+		t.Errorf("Expected entries login and purchase, got %v", received)
+	}
+}
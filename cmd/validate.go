@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"github.com/parfenovvs/loglion/internal/analyzer"
 	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -18,29 +22,67 @@ and contain all required fields.
 Examples:
   loglion validate --parser-config parser.yaml
   loglion validate --funnel-config funnel.yaml
-  loglion validate --parser-config parser.yaml --funnel-config funnel.yaml`,
+  loglion validate --parser-config parser.yaml --funnel-config funnel.yaml
+  loglion validate --config loglion.yaml
+  loglion validate --funnel-config funnel.yaml --graph dot | dot -Tpng -o funnel.png
+  loglion validate -p parser.yaml -f funnel.yaml --log app.log --lines 5000
+
+Funnel configs are also checked for a step graph without cycles in which every
+step can be reached from the first one. With --graph dot the step graph is
+printed in the Graphviz DOT language instead of the validation summary.
+
+Settings that are valid but likely mistakes are reported as warnings without
+failing validation: a step pattern matching every event, the same pattern in two
+steps, or required properties and group_by with a parser config that extracts
+no event data. The funnel and count commands print these warnings too.
+
+With --log the first --lines lines of a log file are parsed with the parser
+config and every step pattern and required property of the funnels is matched
+against each entry on its own, reporting the number of hits. Steps that never
+match the sample are marked, catching valid configs that match nothing.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		parserConfigFile, _ := cmd.Flags().GetString("parser-config")
 		funnelConfigFile, _ := cmd.Flags().GetString("funnel-config")
+		graphFormat, _ := cmd.Flags().GetString("graph")
+		logFile, _ := cmd.Flags().GetString("log")
+		lines, _ := cmd.Flags().GetInt("lines")
 
 		if parserConfigFile == "" && funnelConfigFile == "" {
-			fmt.Fprintf(os.Stderr, "Error: At least one of --parser-config or --funnel-config must be specified.\n")
+			fmt.Fprintf(os.Stderr, "Error: At least one of --config, --parser-config or --funnel-config must be specified.\n")
+			os.Exit(1)
+		}
+
+		if graphFormat != "" {
+			printStepGraphs(funnelConfigFile, graphFormat)
+			return
+		}
+
+		if logFile != "" && (parserConfigFile == "" || funnelConfigFile == "") {
+			fmt.Fprintf(os.Stderr, "Error: --log requires both a parser and a funnel config\n")
+			os.Exit(1)
+		}
+		if lines <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: --lines must be positive, got %d\n", lines)
 			os.Exit(1)
 		}
 
 		logrus.Info("Starting configuration validation")
 
+		var parserCfg *config.ParserConfig
+		var funnelCfgs []*config.FunnelConfig
+
 		// Validate parser config if specified
 		if parserConfigFile != "" {
 			fmt.Printf("Validating parser config file: %s\n", parserConfigFile)
 			logrus.Debug("Attempting to load and validate parser configuration")
-			parserCfg, err := config.LoadParserConfig(parserConfigFile)
+			var err error
+			parserCfg, err = config.LoadParserConfig(parserConfigFile)
 			if err != nil {
 				logrus.WithError(err).WithField("parser_config_file", parserConfigFile).Error("Parser configuration validation failed")
-				fmt.Fprintf(os.Stderr, "❌ Parser configuration validation failed: %v\n", err)
+				fmt.Fprintf(os.Stderr, textOutput("❌ Parser configuration validation failed: %v\n"), err)
 				os.Exit(1)
 			}
-			fmt.Printf("✅ Parser configuration is valid!\n")
+			fmt.Print(textOutput("✅ Parser configuration is valid!\n"))
 			fmt.Printf("Event Regex: %s\n", parserCfg.EventRegex)
 			fmt.Printf("JSON Extraction: %t\n", parserCfg.JSONExtraction)
 		}
@@ -49,24 +91,150 @@ Examples:
 		if funnelConfigFile != "" {
 			fmt.Printf("Validating funnel config file: %s\n", funnelConfigFile)
 			logrus.Debug("Attempting to load and validate funnel configuration")
-			funnelCfg, err := config.LoadFunnelConfig(funnelConfigFile)
+			var err error
+			funnelCfgs, err = config.LoadFunnelConfigs(funnelConfigFile)
 			if err != nil {
 				logrus.WithError(err).WithField("funnel_config_file", funnelConfigFile).Error("Funnel configuration validation failed")
-				fmt.Fprintf(os.Stderr, "❌ Funnel configuration validation failed: %v\n", err)
+				fmt.Fprintf(os.Stderr, textOutput("❌ Funnel configuration validation failed: %v\n"), err)
 				os.Exit(1)
 			}
-			fmt.Printf("✅ Funnel configuration is valid!\n")
-			fmt.Printf("Funnel: %s\n", funnelCfg.Name)
-			fmt.Printf("Steps: %d\n", len(funnelCfg.Steps))
+			fmt.Print(textOutput("✅ Funnel configuration is valid!\n"))
+			for _, funnelCfg := range funnelCfgs {
+				fmt.Printf("Funnel: %s\n", funnelCfg.Name)
+				fmt.Printf("Steps: %d\n", len(funnelCfg.Steps))
+				if funnelCfg.GroupBy != "" {
+					fmt.Printf("Grouped By: %s\n", funnelCfg.GroupBy)
+				}
+			}
+		}
+
+		// Warnings do not fail validation, since the configs may be intended
+		warnings := 0
+		for _, funnelCfg := range funnelCfgs {
+			for _, warning := range funnelCfg.Warnings(parserCfg) {
+				fmt.Printf(textOutput("⚠️  %s\n"), warning)
+				warnings++
+			}
+		}
+		if warnings > 0 {
+			fmt.Printf("%d warning(s)\n", warnings)
+		}
+
+		if logFile != "" {
+			printStepHits(parserCfg, funnelCfgs, logFile, lines)
 		}
 
 		logrus.Info("Configuration validation completed successfully")
 	},
 }
 
+// printStepHits prints how many entries in the first lines of the log file
+// match each step of the funnels
+func printStepHits(parserCfg *config.ParserConfig, funnelCfgs []*config.FunnelConfig, logFile string, lines int) {
+	entries, read, err := parseLogSample(parserCfg, logFile, lines)
+	if err != nil {
+		logrus.WithError(err).WithField("log_file", logFile).Error("Failed to parse log sample")
+		fmt.Fprintf(os.Stderr, "Error: failed to parse log sample: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\nDry run against the first %d lines of %s (%d entries):\n", read, logFile, len(entries))
+	unmatched := 0
+	for _, funnelCfg := range funnelCfgs {
+		fmt.Printf("Funnel: %s\n", funnelCfg.Name)
+		for i, hits := range analyzer.NewFunnelAnalyzer(funnelCfg).AnalyzeStepHits(entries) {
+			mark := ""
+			if hits.Hits == 0 {
+				mark = textOutput("  ⚠️  never matches")
+				unmatched++
+			}
+			if hits.PatternHits != hits.Hits {
+				fmt.Printf("  %d. %s: %d hits (pattern: %d)%s\n", i+1, hits.Step, hits.Hits, hits.PatternHits, mark)
+			} else {
+				fmt.Printf("  %d. %s: %d hits%s\n", i+1, hits.Step, hits.Hits, mark)
+			}
+			for _, property := range hits.Properties {
+				fmt.Printf("       %s: %d of %d\n", property.Property, property.Hits, hits.PatternHits)
+			}
+		}
+	}
+
+	if unmatched > 0 {
+		fmt.Printf(textOutput("⚠️  %d step(s) never match the log sample\n"), unmatched)
+	} else {
+		fmt.Print(textOutput("✅ Every step matches the log sample\n"))
+	}
+}
+
+// parseLogSample parses the first lines of the log file, returning the
+// entries selected by the filter of the parser config and the lines read
+func parseLogSample(parserCfg *config.ParserConfig, logFile string, lines int) ([]*parser.LogEntry, int, error) {
+	logParser, err := parserCfg.NewParser()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	file, err := parser.OpenLogFile(logFile)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var sample strings.Builder
+	reader := parser.NewLineReader(file, parser.DefaultMaxLineBytes)
+	read := 0
+	for read < lines {
+		line, oversized, err := reader.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		read++
+		if !oversized {
+			sample.WriteString(line)
+			sample.WriteByte('\n')
+		}
+	}
+
+	entries, err := logParser.ParseReader(strings.NewReader(sample.String()))
+	if err != nil {
+		return nil, 0, err
+	}
+	return parser.FilterEntries(entries, parserCfg.EntryFilter()), read, nil
+}
+
+// printStepGraphs prints the step graph of every funnel in the config file
+func printStepGraphs(funnelConfigFile, graphFormat string) {
+	if graphFormat != "dot" {
+		fmt.Fprintf(os.Stderr, "Error: unsupported graph format '%s' (supported: dot)\n", graphFormat)
+		os.Exit(1)
+	}
+	if funnelConfigFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: --graph requires --funnel-config\n")
+		os.Exit(1)
+	}
+
+	funnelCfgs, err := config.LoadFunnelConfigs(funnelConfigFile)
+	if err != nil {
+		logrus.WithError(err).WithField("funnel_config_file", funnelConfigFile).Error("Funnel configuration validation failed")
+		fmt.Fprintf(os.Stderr, textOutput("❌ Funnel configuration validation failed: %v\n"), err)
+		os.Exit(1)
+	}
+
+	for _, funnelCfg := range funnelCfgs {
+		fmt.Print(config.BuildStepGraph(funnelCfg).DOT())
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(validateCmd)
 
 	validateCmd.Flags().StringP("parser-config", "p", "", "Path to parser configuration file")
 	validateCmd.Flags().StringP("funnel-config", "f", "", "Path to funnel configuration file")
+	addConfigFlag(validateCmd)
+	validateCmd.Flags().String("graph", "", "Print the step graph of the funnel config in this format instead (dot)")
+	validateCmd.Flags().StringP("log", "l", "", "Log file to dry-run the step patterns against")
+	validateCmd.Flags().Int("lines", 1000, "Number of lines of the log file to dry-run against")
 }
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// watchDebounce is how long log files must stay unchanged before a change
+// triggers a new analysis, so a burst of appended lines is analyzed once
+const watchDebounce = 300 * time.Millisecond
+
+// watchLogFiles calls run once and again whenever a log file matching
+// logPatterns is written, created or replaced, until ctx is done. When stdout
+// is a terminal it is cleared before each run, so the latest results replace
+// the previous ones. The exit code of run is ignored: errors are reported by
+// run and the next change is analyzed anyway.
+func watchLogFiles(ctx context.Context, logPatterns []string, run func(ctx context.Context) int) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch log files: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch the directories, as files may be created or replaced by tools
+	// writing them, e.g. a rotated logcat capture
+	dirs := make(map[string]bool)
+	for _, pattern := range logPatterns {
+		dir := filepath.Dir(pattern)
+		if strings.ContainsAny(dir, "*?[") {
+			return fmt.Errorf("--watch does not support wildcards in directories: %s", pattern)
+		}
+		if dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+		dirs[dir] = true
+	}
+	logrus.WithField("log_files", logPatterns).Info("Watching log files for changes")
+
+	analyze := func() {
+		if colorEnabled(os.Stdout) {
+			fmt.Print("\033[H\033[2J")
+		}
+		run(ctx)
+		if ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "\nWatching %s for changes, press Ctrl+C to stop...\n", strings.Join(logPatterns, ", "))
+		}
+	}
+	analyze()
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod || !matchesLogPattern(logPatterns, event.Name) {
+				continue
+			}
+			logrus.WithFields(logrus.Fields{
+				"file": event.Name,
+				"op":   event.Op.String(),
+			}).Debug("Log file changed")
+			debounce.Reset(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logrus.WithError(err).Warn("Error watching log files")
+		case <-debounce.C:
+			analyze()
+		case <-ctx.Done():
+			logrus.Info("Stopped watching log files")
+			return nil
+		}
+	}
+}
+
+// matchesLogPattern reports whether the file name matches one of the log file
+// patterns
+func matchesLogPattern(logPatterns []string, name string) bool {
+	for _, pattern := range logPatterns {
+		if matched, _ := filepath.Match(filepath.Clean(pattern), filepath.Clean(name)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isTerminal reports whether file is an interactive terminal rather than a
+// pipe, a regular file or another character device such as /dev/null.
+// Tests replace it to act as if run from a terminal.
+var isTerminal = func(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
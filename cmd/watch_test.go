@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "logcat.txt")
+	if err := os.WriteFile(logPath, []byte("login\n"), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := make(chan struct{}, 10)
+	done := make(chan error)
+	go func() {
+		done <- watchLogFiles(ctx, []string{filepath.Join(dir, "*.txt")}, func(ctx context.Context) int {
+			runs <- struct{}{}
+			return 1
+		})
+	}()
+
+	waitForRun := func(what string) {
+		t.Helper()
+		select {
+		case <-runs:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected a run %s", what)
+		}
+	}
+	waitForRun("on start")
+
+	// Files not matching the patterns are ignored
+	if err := os.WriteFile(filepath.Join(dir, "notes.md"), []byte("todo\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	// A burst of appends triggers a single run
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	for _, line := range []string{"purchase\n", "logout\n"} {
+		if _, err := file.WriteString(line); err != nil {
+			t.Fatalf("Failed to append to log file: %v", err)
+		}
+	}
+	file.Close()
+	waitForRun("after the log file changed")
+
+	select {
+	case <-runs:
+		t.Error("Expected a single run for a burst of changes")
+	case <-time.After(2 * watchDebounce):
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("watchLogFiles() unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected watchLogFiles() to return when the context is done")
+	}
+}
+
+func TestWatchLogFilesWildcardDirectory(t *testing.T) {
+	err := watchLogFiles(context.Background(), []string{"logs/*/logcat.txt"}, func(ctx context.Context) int {
+		t.Error("Expected no run")
+		return 0
+	})
+	if err == nil {
+		t.Error("Expected an error for a wildcard in the directory")
+	}
+}
+
+func TestMatchesLogPattern(t *testing.T) {
+	patterns := []string{"logs/*.txt", "./app.log"}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"logs/logcat.txt", true},
+		{"app.log", true},
+		{"logs/logcat.txt.swp", false},
+		{"other/logcat.txt", false},
+	}
+	for _, tt := range tests {
+		if got := matchesLogPattern(patterns, tt.name); got != tt.want {
+			t.Errorf("matchesLogPattern(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds of anomalies in the rate of an event pattern
+const (
+	// AnomalySpike is a window with far more events than the baseline
+	AnomalySpike = "spike"
+	// AnomalyDrop is a window with far fewer events than the baseline
+	AnomalyDrop = "drop"
+)
+
+// AnomalyAnalyzer flags time windows in which the rate of an event pattern
+// deviates from its rolling baseline
+type AnomalyAnalyzer struct {
+	matcher *CountAnalyzer
+	options AnomalyOptions
+}
+
+// AnomalyOptions configures the windows, the baseline and the deviation an
+// anomaly needs
+type AnomalyOptions struct {
+	// Window is the length of the windows events are counted in
+	Window time.Duration
+	// BaselineWindows is the number of preceding windows whose average count
+	// is the baseline of a window, not counting anomalous windows
+	BaselineWindows int
+	// Factor is how many times the count of a window must be above or below
+	// the baseline to be an anomaly
+	Factor float64
+	// MinCount is the number of events a spike needs, and the baseline a drop
+	// needs, which keeps rare events from being flagged on noise
+	MinCount int
+}
+
+// AnomalyResult lists the anomalies of all patterns in the order they occurred
+type AnomalyResult struct {
+	Patterns            []string `json:"patterns"`
+	WindowSeconds       float64  `json:"window_seconds"`
+	BaselineWindows     int      `json:"baseline_windows"`
+	Factor              float64  `json:"factor"`
+	MinCount            int      `json:"min_count"`
+	TotalEventsAnalyzed int      `json:"total_events_analyzed"`
+	// Windows counts the windows from the earliest to the latest timestamp
+	Windows int `json:"windows"`
+	// UntimedEvents counts matching entries without a timestamp, which are
+	// not in any window
+	UntimedEvents int           `json:"untimed_events,omitempty"`
+	Anomalies     []RateAnomaly `json:"anomalies"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+// RateAnomaly is a window in which a pattern's count deviated from its baseline
+type RateAnomaly struct {
+	Pattern string    `json:"pattern"`
+	Kind    string    `json:"kind"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Count   int       `json:"count"`
+	// Baseline is the average count of the preceding normal windows
+	Baseline float64 `json:"baseline"`
+	// Magnitude is the count relative to the baseline, unset for spikes over
+	// a baseline of zero
+	Magnitude float64 `json:"magnitude,omitempty"`
+}
+
+// NewAnomalyAnalyzer creates an analyzer flagging anomalies in the rates of
+// eventPatterns
+func NewAnomalyAnalyzer(eventPatterns []string, options AnomalyOptions, countOptions CountOptions) (*AnomalyAnalyzer, error) {
+	logrus.WithFields(logrus.Fields{
+		"pattern_count":    len(eventPatterns),
+		"window":           options.Window,
+		"baseline_windows": options.BaselineWindows,
+		"factor":           options.Factor,
+	}).Debug("Creating new anomaly analyzer")
+
+	if len(eventPatterns) == 0 {
+		return nil, fmt.Errorf("at least 1 event pattern is required")
+	}
+	if options.Window <= 0 {
+		return nil, fmt.Errorf("window must be positive, got %s", options.Window)
+	}
+	if options.BaselineWindows < 1 {
+		return nil, fmt.Errorf("baseline must span at least 1 window, got %d", options.BaselineWindows)
+	}
+	if options.Factor <= 1 {
+		return nil, fmt.Errorf("factor must be greater than 1, got %g", options.Factor)
+	}
+	if options.MinCount < 1 {
+		return nil, fmt.Errorf("minimum count must be at least 1, got %d", options.MinCount)
+	}
+
+	matcher, err := NewCountAnalyzerWithOptions(eventPatterns, countOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &AnomalyAnalyzer{matcher: matcher, options: options}, nil
+}
+
+func (aa *AnomalyAnalyzer) AnalyzeAnomalies(entries []*parser.LogEntry) *AnomalyResult {
+	return aa.AnalyzeAnomaliesContext(context.Background(), entries)
+}
+
+// AnalyzeAnomaliesContext is like AnalyzeAnomalies but stops when ctx is
+// cancelled, returning the anomalies of the entries analyzed so far marked as
+// partial
+func (aa *AnomalyAnalyzer) AnalyzeAnomaliesContext(ctx context.Context, entries []*parser.LogEntry) *AnomalyResult {
+	patterns := aa.matcher.patterns
+	window := aa.options.Window
+	logrus.WithFields(logrus.Fields{
+		"entry_count":   len(entries),
+		"pattern_count": len(patterns),
+		"window":        window,
+	}).Info("Starting anomaly detection")
+
+	result := &AnomalyResult{
+		WindowSeconds:       window.Seconds(),
+		BaselineWindows:     aa.options.BaselineWindows,
+		Factor:              aa.options.Factor,
+		MinCount:            aa.options.MinCount,
+		TotalEventsAnalyzed: len(entries),
+		Anomalies:           []RateAnomaly{},
+	}
+	for _, pattern := range patterns {
+		result.Patterns = append(result.Patterns, pattern.Name)
+	}
+
+	// Windows span all timed entries, so quiet periods count as zero
+	var first, last time.Time
+	counts := make([]map[int64]int, len(patterns))
+	for i := range counts {
+		counts[i] = make(map[int64]int)
+	}
+	type matchedEvent struct {
+		timestamp time.Time
+		pattern   int
+	}
+	var matches []matchedEvent
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Anomaly detection cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+
+		for patternIndex, pattern := range patterns {
+			if !aa.matcher.eventMatchesPattern(entry, pattern) {
+				continue
+			}
+			if entry.Timestamp.IsZero() {
+				result.UntimedEvents++
+				continue
+			}
+			matches = append(matches, matchedEvent{timestamp: entry.Timestamp, pattern: patternIndex})
+		}
+		if entry.Timestamp.IsZero() {
+			continue
+		}
+		if first.IsZero() || entry.Timestamp.Before(first) {
+			first = entry.Timestamp
+		}
+		if last.IsZero() || entry.Timestamp.After(last) {
+			last = entry.Timestamp
+		}
+	}
+	if first.IsZero() {
+		return result
+	}
+
+	start := first.Truncate(window)
+	result.Windows = int(last.Sub(start)/window) + 1
+	for _, match := range matches {
+		counts[match.pattern][int64(match.timestamp.Sub(start)/window)]++
+	}
+
+	baselineWindows := aa.options.BaselineWindows
+	for patternIndex, pattern := range patterns {
+		// Anomalous windows are left out of the baseline, so an error storm
+		// does not make the windows after it look like drops
+		var history []int
+		sum := 0
+		for w := 0; w < result.Windows; w++ {
+			count := counts[patternIndex][int64(w)]
+			if len(history) >= baselineWindows {
+				if anomaly, ok := aa.detect(count, float64(sum)/float64(baselineWindows)); ok {
+					anomaly.Pattern = pattern.Name
+					anomaly.Start = start.Add(time.Duration(w) * window)
+					anomaly.End = anomaly.Start.Add(window)
+					result.Anomalies = append(result.Anomalies, anomaly)
+					continue
+				}
+				sum -= history[len(history)-baselineWindows]
+			}
+			history = append(history, count)
+			sum += count
+		}
+	}
+
+	sort.SliceStable(result.Anomalies, func(i, j int) bool {
+		return result.Anomalies[i].Start.Before(result.Anomalies[j].Start)
+	})
+
+	logrus.WithFields(logrus.Fields{
+		"windows":   result.Windows,
+		"anomalies": len(result.Anomalies),
+		"partial":   result.Partial,
+	}).Info("Anomaly detection completed")
+
+	return result
+}
+
+// detect compares the count of a window to its baseline
+func (aa *AnomalyAnalyzer) detect(count int, baseline float64) (RateAnomaly, bool) {
+	anomaly := RateAnomaly{Count: count, Baseline: baseline}
+	if baseline > 0 {
+		anomaly.Magnitude = float64(count) / baseline
+	}
+
+	switch {
+	case count >= aa.options.MinCount && float64(count) >= baseline*aa.options.Factor:
+		anomaly.Kind = AnomalySpike
+	case baseline >= float64(aa.options.MinCount) && float64(count) <= baseline/aa.options.Factor:
+		anomaly.Kind = AnomalyDrop
+	default:
+		return RateAnomaly{}, false
+	}
+	return anomaly, true
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestAnomalyAnalyzer_SpikesAndDrops(t *testing.T) {
+	analyzer, err := NewAnomalyAnalyzer([]string{"error", "ping"}, AnomalyOptions{
+		Window:          time.Minute,
+		BaselineWindows: 3,
+		Factor:          3,
+		MinCount:        2,
+	}, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewAnomalyAnalyzer() unexpected error: %v", err)
+	}
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	var entries []*parser.LogEntry
+	add := func(minute int, message string, n int) {
+		for i := 0; i < n; i++ {
+			entries = append(entries, &parser.LogEntry{Timestamp: start.Add(time.Duration(minute)*time.Minute + time.Duration(i)*time.Second), Message: message})
+		}
+	}
+	// error: 1 per minute, then a storm of 9 in minute 4
+	// ping: 6 per minute, then silence in minute 5
+	for minute := 0; minute < 6; minute++ {
+		switch minute {
+		case 4:
+			add(minute, "error", 9)
+		default:
+			add(minute, "error", 1)
+		}
+		if minute != 5 {
+			add(minute, "ping", 6)
+		}
+	}
+	entries = append(entries, &parser.LogEntry{Message: "error"})
+
+	result := analyzer.AnalyzeAnomalies(entries)
+	if result.Windows != 6 || result.UntimedEvents != 1 {
+		t.Fatalf("Expected 6 windows and 1 untimed event, got %+v", result)
+	}
+
+	want := []RateAnomaly{
+		{Pattern: "error", Kind: AnomalySpike, Start: start.Add(4 * time.Minute), End: start.Add(5 * time.Minute), Count: 9, Baseline: 1, Magnitude: 9},
+		{Pattern: "ping", Kind: AnomalyDrop, Start: start.Add(5 * time.Minute), End: start.Add(6 * time.Minute), Count: 0, Baseline: 6},
+	}
+	if len(result.Anomalies) != len(want) {
+		t.Fatalf("Expected %d anomalies, got %+v", len(want), result.Anomalies)
+	}
+	for i := range want {
+		if result.Anomalies[i] != want[i] {
+			t.Errorf("Anomalies[%d] = %+v, want %+v", i, result.Anomalies[i], want[i])
+		}
+	}
+}
+
+func TestAnomalyAnalyzer_MinCount(t *testing.T) {
+	analyzer, err := NewAnomalyAnalyzer([]string{"crash"}, AnomalyOptions{
+		Window:          time.Minute,
+		BaselineWindows: 2,
+		Factor:          2,
+		MinCount:        5,
+	}, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewAnomalyAnalyzer() unexpected error: %v", err)
+	}
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	entries := []*parser.LogEntry{
+		{Timestamp: start, Message: "app_start"},
+		{Timestamp: start.Add(2 * time.Minute), Message: "crash"},
+		{Timestamp: start.Add(2*time.Minute + time.Second), Message: "crash"},
+	}
+	if result := analyzer.AnalyzeAnomalies(entries); len(result.Anomalies) != 0 {
+		t.Errorf("Expected no anomalies below the minimum count, got %+v", result.Anomalies)
+	}
+}
+
+func TestNewAnomalyAnalyzer_InvalidOptions(t *testing.T) {
+	valid := AnomalyOptions{Window: time.Minute, BaselineWindows: 10, Factor: 3, MinCount: 5}
+	tests := map[string]AnomalyOptions{
+		"zero window":    {Window: 0, BaselineWindows: 10, Factor: 3, MinCount: 5},
+		"no baseline":    {Window: time.Minute, BaselineWindows: 0, Factor: 3, MinCount: 5},
+		"factor of 1":    {Window: time.Minute, BaselineWindows: 10, Factor: 1, MinCount: 5},
+		"zero min count": {Window: time.Minute, BaselineWindows: 10, Factor: 3, MinCount: 0},
+	}
+	for name, options := range tests {
+		if _, err := NewAnomalyAnalyzer([]string{"error"}, options, CountOptions{}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := NewAnomalyAnalyzer(nil, valid, CountOptions{}); err == nil {
+		t.Error("Expected an error without patterns")
+	}
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import "fmt"
+
+// Thresholds a funnel step can assert
+const (
+	ThresholdMinCount         = "min_count"
+	ThresholdMinConversionPct = "min_conversion_pct"
+)
+
+// Assertion is the outcome of a min_count or min_conversion_pct threshold
+// declared on a funnel step. Conversion is the percentage of the first step.
+type Assertion struct {
+	Step      string  `json:"step"`
+	Threshold string  `json:"threshold"`
+	Expected  float64 `json:"expected"`
+	Actual    float64 `json:"actual"`
+	Passed    bool    `json:"passed"`
+}
+
+// String describes the assertion and the value the step reached
+func (a Assertion) String() string {
+	if a.Threshold == ThresholdMinConversionPct {
+		return fmt.Sprintf("%s: %s %.1f%%, got %.1f%%", a.Step, a.Threshold, a.Expected, a.Actual)
+	}
+	return fmt.Sprintf("%s: %s %g, got %g", a.Step, a.Threshold, a.Expected, a.Actual)
+}
+
+// FailedAssertions returns the step assertions the result does not meet
+func (r *FunnelResult) FailedAssertions() []Assertion {
+	var failed []Assertion
+	for _, assertion := range r.Assertions {
+		if !assertion.Passed {
+			failed = append(failed, assertion)
+		}
+	}
+	return failed
+}
+
+// checkAssertions evaluates the thresholds of the funnel steps against their
+// results and marks the steps that fail one. Steps without a result, as in
+// results without events, count as never reached.
+func (fa *FunnelAnalyzer) checkAssertions(stepResults []StepResult) []Assertion {
+	var assertions []Assertion
+	for i, step := range fa.config.Steps {
+		var result StepResult
+		if i < len(stepResults) {
+			result = stepResults[i]
+		}
+
+		failed := false
+		if step.MinCount > 0 {
+			assertion := Assertion{
+				Step:      step.Name,
+				Threshold: ThresholdMinCount,
+				Expected:  float64(step.MinCount),
+				Actual:    float64(result.EventCount),
+				Passed:    result.EventCount >= step.MinCount,
+			}
+			failed = failed || !assertion.Passed
+			assertions = append(assertions, assertion)
+		}
+		if step.MinConversionPct > 0 {
+			assertion := Assertion{
+				Step:      step.Name,
+				Threshold: ThresholdMinConversionPct,
+				Expected:  step.MinConversionPct,
+				Actual:    result.Percentage,
+				Passed:    result.Percentage >= step.MinConversionPct,
+			}
+			failed = failed || !assertion.Passed
+			assertions = append(assertions, assertion)
+		}
+
+		if failed && i < len(stepResults) {
+			stepResults[i].AssertionFailed = true
+		}
+	}
+	return assertions
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestFunnelAssertions(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "checkout",
+		Steps: []config.Step{
+			{Name: "view", EventPattern: "view", MinCount: 2},
+			{Name: "cart", EventPattern: "cart", MinConversionPct: 40},
+			{Name: "buy", EventPattern: "buy", MinCount: 2, MinConversionPct: 10},
+		},
+	}
+	entries := []*parser.LogEntry{
+		{Message: "view"}, {Message: "cart"}, {Message: "buy"},
+		{Message: "view"}, {Message: "cart"},
+	}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+
+	expected := []Assertion{
+		{Step: "view", Threshold: ThresholdMinCount, Expected: 2, Actual: 2, Passed: true},
+		{Step: "cart", Threshold: ThresholdMinConversionPct, Expected: 40, Actual: 100, Passed: true},
+		{Step: "buy", Threshold: ThresholdMinCount, Expected: 2, Actual: 1, Passed: false},
+		{Step: "buy", Threshold: ThresholdMinConversionPct, Expected: 10, Actual: 50, Passed: true},
+	}
+	if !reflect.DeepEqual(result.Assertions, expected) {
+		t.Errorf("Expected assertions %+v, got %+v", expected, result.Assertions)
+	}
+
+	failed := result.FailedAssertions()
+	if len(failed) != 1 || failed[0].String() != "buy: min_count 2, got 1" {
+		t.Errorf("Expected the min_count assertion of buy to fail, got %+v", failed)
+	}
+	for i, step := range result.Steps {
+		if step.AssertionFailed != (i == 2) {
+			t.Errorf("Step %s: expected assertion_failed %t, got %t", step.Name, i == 2, step.AssertionFailed)
+		}
+	}
+}
+
+func TestFunnelAssertionsWithoutEvents(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name:  "checkout",
+		Steps: []config.Step{{Name: "view", EventPattern: "view", MinConversionPct: 100}, {Name: "buy", EventPattern: "buy"}},
+	}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(nil, 0)
+
+	failed := result.FailedAssertions()
+	if len(failed) != 1 || failed[0].String() != "view: min_conversion_pct 100.0%, got 0.0%" {
+		t.Errorf("Expected an empty log to fail the assertion, got %+v", result.Assertions)
+	}
+}
@@ -0,0 +1,187 @@
+package analyzer
+
+import (
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// funnelAttempt follows funnel attempts through entries one at a time,
+// according to the mode of the funnel. A new attempt starts when the previous
+// one converted or was aborted.
+type funnelAttempt struct {
+	fa      *FunnelAnalyzer
+	details *stepDetails
+	// matched holds the entry that matched each step in the current attempt,
+	// nil for steps not matched yet
+	matched []*groupedEntry
+	// reached counts the matched steps, which in ordered modes are the first
+	// reached steps
+	reached int
+	// start is the timestamp of the first entry of the attempt
+	start time.Time
+	// counts holds how often the event of each step not reached yet fired in
+	// the attempt, for steps with min_occurrences
+	counts      []int
+	occurrences []int
+	anomalies   []Anomaly
+	// entered counts the attempts that reached a step, which entered the funnel
+	entered int
+}
+
+// attemptMatch is an entry that matched a step of the current attempt
+type attemptMatch struct {
+	step int
+	// branch is the any_of branch the entry matched, -1 for steps without any_of
+	branch int
+}
+
+func (fa *FunnelAnalyzer) newAttempt(details *stepDetails) *funnelAttempt {
+	return &funnelAttempt{
+		fa:          fa,
+		details:     details,
+		matched:     make([]*groupedEntry, len(fa.config.Steps)),
+		counts:      make([]int, len(fa.config.Steps)),
+		occurrences: make([]int, len(fa.config.Steps)),
+	}
+}
+
+// add feeds the entry at entryIndex to the current attempt and reports the
+// step it matched, if any. Anomalies and exclusions abort the attempt.
+func (a *funnelAttempt) add(entryIndex int, entry *parser.LogEntry) (attemptMatch, bool) {
+	fa := a.fa
+	if anomaly := fa.trackOccurrences(entry, entryIndex, a.occurrences); anomaly != nil {
+		a.anomalies = append(a.anomalies, *anomaly)
+		a.reset()
+		return attemptMatch{}, false
+	}
+
+	if fa.mode == config.ModeUnordered {
+		return a.addUnordered(entryIndex, entry)
+	}
+
+	step := fa.config.Steps[a.reached]
+	if matched, branch := fa.matchStep(entry, step); matched {
+		if !a.occurred(a.reached, entry) {
+			return attemptMatch{}, false
+		}
+		return a.match(a.reached, branch, entryIndex, entry), true
+	}
+	if fa.eventMatchesExclusion(entry, entryIndex, step) {
+		a.details.exclusions[a.reached]++
+		a.reset()
+		return attemptMatch{}, false
+	}
+	if fa.mode == config.ModeStrict && a.started() && a.matchesOtherStep(entry) {
+		// Another funnel event broke the sequence, though it may start a
+		// new attempt
+		logrus.WithFields(logrus.Fields{
+			"entry_index": entryIndex + 1,
+			"step_name":   step.Name,
+		}).Debug("Funnel event out of sequence aborted strict attempt")
+		a.reset()
+		if matched, branch := fa.matchStep(entry, fa.config.Steps[0]); matched && a.occurred(0, entry) {
+			return a.match(0, branch, entryIndex, entry), true
+		}
+	}
+	return attemptMatch{}, false
+}
+
+// addUnordered matches the entry against the steps the attempt has not
+// matched yet, in step order, and aborts the attempt on the exclusion of any
+// of them
+func (a *funnelAttempt) addUnordered(entryIndex int, entry *parser.LogEntry) (attemptMatch, bool) {
+	// Anchored attempts wait for the first step before the others count
+	steps := a.fa.config.Steps
+	if a.fa.config.AnchorFirstStep && a.matched[0] == nil {
+		steps = steps[:1]
+	}
+	for i, step := range steps {
+		if a.matched[i] != nil {
+			continue
+		}
+		if matched, branch := a.fa.matchStep(entry, step); matched {
+			if !a.occurred(i, entry) {
+				return attemptMatch{}, false
+			}
+			return a.match(i, branch, entryIndex, entry), true
+		}
+	}
+	for i, step := range steps {
+		if a.matched[i] == nil && a.fa.eventMatchesExclusion(entry, entryIndex, step) {
+			a.details.exclusions[i]++
+			a.reset()
+			break
+		}
+	}
+	return attemptMatch{}, false
+}
+
+// matchesOtherStep reports whether entry matches any step of the funnel
+func (a *funnelAttempt) matchesOtherStep(entry *parser.LogEntry) bool {
+	for _, step := range a.fa.config.Steps {
+		if a.fa.eventMatchesStep(entry, step) {
+			return true
+		}
+	}
+	return false
+}
+
+// occurred counts an event of step and reports whether the step fired as
+// often as its min_occurrences requires. The first event starts the attempt.
+func (a *funnelAttempt) occurred(step int, entry *parser.LogEntry) bool {
+	if !a.started() {
+		a.start = entry.Timestamp
+	}
+	a.counts[step]++
+	return a.counts[step] >= a.fa.config.Steps[step].MinOccurrences
+}
+
+// started reports whether an event of the funnel counted toward the current
+// attempt
+func (a *funnelAttempt) started() bool {
+	if a.reached > 0 {
+		return true
+	}
+	for _, count := range a.counts {
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *funnelAttempt) match(step, branch, entryIndex int, entry *parser.LogEntry) attemptMatch {
+	if a.reached == 0 {
+		a.entered++
+	}
+	a.matched[step] = &groupedEntry{index: entryIndex, entry: entry}
+	a.reached++
+	return attemptMatch{step: step, branch: branch}
+}
+
+// converted reports whether the current attempt matched all steps
+func (a *funnelAttempt) converted() bool {
+	return a.reached == len(a.matched)
+}
+
+// entries returns the entries that matched the steps of a converted attempt,
+// in step order
+func (a *funnelAttempt) entries() []groupedEntry {
+	entries := make([]groupedEntry, len(a.matched))
+	for i, matched := range a.matched {
+		entries[i] = *matched
+	}
+	return entries
+}
+
+// reset aborts the current attempt or starts a new one after a conversion
+func (a *funnelAttempt) reset() {
+	clear(a.matched)
+	clear(a.counts)
+	clear(a.occurrences)
+	a.reached = 0
+	a.start = time.Time{}
+}
@@ -0,0 +1,266 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func modeFunnelConfig(mode string) *config.FunnelConfig {
+	return &config.FunnelConfig{
+		Name: "Checkout",
+		Mode: mode,
+		Steps: []config.Step{
+			{Name: "View", EventPattern: "view"},
+			{Name: "Cart", EventPattern: "cart"},
+			{Name: "Buy", EventPattern: "buy"},
+		},
+	}
+}
+
+func messages(lines ...string) []*parser.LogEntry {
+	entries := make([]*parser.LogEntry, len(lines))
+	for i, line := range lines {
+		entries[i] = &parser.LogEntry{Message: line}
+	}
+	return entries
+}
+
+func TestAnalyzeFunnel_Modes(t *testing.T) {
+	tests := []struct {
+		name        string
+		entries     []*parser.LogEntry
+		conversions map[string]int
+	}{
+		{
+			name:        "consecutive steps",
+			entries:     messages("view", "noise", "cart", "buy"),
+			conversions: map[string]int{config.ModeStrict: 1, config.ModeLoose: 1, config.ModeUnordered: 1},
+		},
+		{
+			name:        "other funnel event in between",
+			entries:     messages("view", "cart", "view", "buy"),
+			conversions: map[string]int{config.ModeStrict: 0, config.ModeLoose: 1, config.ModeUnordered: 1},
+		},
+		{
+			name:        "out of order",
+			entries:     messages("buy", "cart", "view"),
+			conversions: map[string]int{config.ModeStrict: 0, config.ModeLoose: 0, config.ModeUnordered: 1},
+		},
+		{
+			name:        "broken sequence restarts",
+			entries:     messages("view", "view", "cart", "buy"),
+			conversions: map[string]int{config.ModeStrict: 1, config.ModeLoose: 1, config.ModeUnordered: 1},
+		},
+		{
+			name:        "missing step",
+			entries:     messages("view", "buy", "view", "buy"),
+			conversions: map[string]int{config.ModeStrict: 0, config.ModeLoose: 0, config.ModeUnordered: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range []string{config.ModeStrict, config.ModeLoose, config.ModeUnordered} {
+			for _, limit := range []int{0, 1} {
+				result := NewFunnelAnalyzer(modeFunnelConfig(mode)).AnalyzeFunnel(tt.entries, limit)
+				if result.ConversionsFound != tt.conversions[mode] {
+					t.Errorf("%s, mode %s, limit %d: expected %d conversions, got %d",
+						tt.name, mode, limit, tt.conversions[mode], result.ConversionsFound)
+				}
+			}
+		}
+	}
+}
+
+func TestAnalyze_OptionsOverrideModeAndLimit(t *testing.T) {
+	entries := messages("view", "cart", "view", "buy", "view", "cart", "buy")
+
+	strict := NewFunnelAnalyzerWithOptions(modeFunnelConfig(config.ModeLoose), FunnelOptions{Mode: config.ModeStrict})
+	if result := strict.Analyze(context.Background(), entries); result.ConversionsFound != 1 {
+		t.Errorf("strict mode option: expected 1 conversion, got %d", result.ConversionsFound)
+	}
+
+	limited := NewFunnelAnalyzerWithOptions(modeFunnelConfig(""), FunnelOptions{Limit: 1})
+	if result := limited.Analyze(context.Background(), entries); result.ConversionsFound != 1 {
+		t.Errorf("limit option: expected 1 conversion, got %d", result.ConversionsFound)
+	}
+
+	unlimited := NewFunnelAnalyzer(modeFunnelConfig(""))
+	if result := unlimited.Analyze(context.Background(), entries); result.ConversionsFound != 2 {
+		t.Errorf("config mode: expected 2 conversions, got %d", result.ConversionsFound)
+	}
+}
+
+func TestAnalyzeFunnel_LooseIsDefault(t *testing.T) {
+	entries := messages("view", "cart", "view", "buy", "buy", "cart", "view")
+	loose := NewFunnelAnalyzer(modeFunnelConfig(config.ModeLoose)).AnalyzeFunnel(entries, 0)
+	unset := NewFunnelAnalyzer(modeFunnelConfig("")).AnalyzeFunnel(entries, 0)
+
+	for i := range loose.Steps {
+		if loose.Steps[i].EventCount != unset.Steps[i].EventCount {
+			t.Errorf("Step %s: expected %d events without a mode, got %d",
+				loose.Steps[i].Name, loose.Steps[i].EventCount, unset.Steps[i].EventCount)
+		}
+	}
+}
+
+func TestAnalyzeFunnel_UnorderedConversion(t *testing.T) {
+	cfg := modeFunnelConfig(config.ModeUnordered)
+	entries := messages("noise", "buy", "view", "buy", "cart")
+
+	result := NewFunnelAnalyzerWithOptions(cfg, FunnelOptions{ConversionSteps: true}).AnalyzeFunnel(entries, 0)
+
+	if len(result.Conversions) != 1 {
+		t.Fatalf("Expected 1 conversion, got %+v", result.Conversions)
+	}
+	conversion := result.Conversions[0]
+	if conversion.StartEntry != 2 || conversion.EndEntry != 5 {
+		t.Errorf("Expected conversion from entry 2 to 5, got %+v", conversion)
+	}
+	// Steps are listed in step order with the entry that matched them
+	expected := []int{3, 5, 2}
+	for i, step := range conversion.Steps {
+		if step.Step != cfg.Steps[i].Name || step.EntryIndex != expected[i] {
+			t.Errorf("Step %d: expected %s at entry %d, got %+v", i+1, cfg.Steps[i].Name, expected[i], step)
+		}
+	}
+	for i, step := range result.Steps {
+		if step.EventCount != 1 {
+			t.Errorf("Step %d: expected 1 event, got %d", i+1, step.EventCount)
+		}
+	}
+}
+
+func TestAnalyzeFunnel_GroupByModes(t *testing.T) {
+	entries := []*parser.LogEntry{
+		userEvent("cart", "alice"),
+		userEvent("view", "bob"),
+		userEvent("buy", "alice"),
+		userEvent("cart", "bob"),
+		userEvent("view", "alice"),
+		userEvent("buy", "bob"),
+	}
+
+	for mode, expected := range map[string]int{config.ModeStrict: 1, config.ModeLoose: 1, config.ModeUnordered: 2} {
+		cfg := groupedFunnelConfig()
+		cfg.Mode = mode
+		result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+		if result.Groups.CompletedGroups != expected {
+			t.Errorf("Mode %s: expected %d converted groups, got %d", mode, expected, result.Groups.CompletedGroups)
+		}
+	}
+}
+
+func TestAnalyzeFunnel_MinOccurrences(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "Checkout",
+		Steps: []config.Step{
+			{Name: "Open", EventPattern: "open"},
+			{Name: "Product Views", EventPattern: "view_product", MinOccurrences: 3},
+			{Name: "Checkout", EventPattern: "checkout"},
+		},
+	}
+	entries := messages(
+		"open", "view_product", "view_product", "checkout", // too few views yet
+		"view_product", "checkout",
+		"open", "view_product", "checkout", // counts start over in a new attempt
+	)
+
+	result := NewFunnelAnalyzerWithOptions(cfg, FunnelOptions{ConversionSteps: true}).AnalyzeFunnel(entries, 0)
+	if result.ConversionsFound != 1 {
+		t.Fatalf("Expected 1 conversion, got %d", result.ConversionsFound)
+	}
+	// The step is reached by the event that fired the required number of times
+	if steps := result.Conversions[0].Steps; steps[1].EntryIndex != 5 || steps[2].EntryIndex != 6 {
+		t.Errorf("Expected views reached at entry 5 and checkout at 6, got %+v", steps)
+	}
+	expectedCounts := []int{2, 1, 1}
+	for i, expected := range expectedCounts {
+		if result.Steps[i].EventCount != expected {
+			t.Errorf("Step %s: expected %d events, got %d", result.Steps[i].Name, expected, result.Steps[i].EventCount)
+		}
+	}
+
+	// Unordered funnels take the earlier checkout, the views only need to
+	// add up within the attempt
+	cfg.Mode = config.ModeUnordered
+	result = NewFunnelAnalyzerWithOptions(cfg, FunnelOptions{ConversionSteps: true}).AnalyzeFunnel(entries, 0)
+	if result.ConversionsFound != 1 {
+		t.Fatalf("Unordered mode: expected 1 conversion, got %d", result.ConversionsFound)
+	}
+	if steps := result.Conversions[0].Steps; steps[1].EntryIndex != 5 || steps[2].EntryIndex != 4 {
+		t.Errorf("Unordered mode: expected views reached at entry 5 and checkout at 4, got %+v", steps)
+	}
+
+	// In strict mode repeated events of the step do not break the sequence
+	cfg.Mode = config.ModeStrict
+	result = NewFunnelAnalyzer(cfg).AnalyzeFunnel(messages("open", "view_product", "view_product", "view_product", "checkout"), 0)
+	if result.ConversionsFound != 1 {
+		t.Errorf("Strict mode: expected 1 conversion, got %d", result.ConversionsFound)
+	}
+}
+
+func TestAnalyzeFunnel_AnchorFirstStep(t *testing.T) {
+	entries := messages("cart", "buy", "view", "cart", "noise", "buy")
+
+	for _, tt := range []struct {
+		name          string
+		anchored      bool
+		entered       int
+		conversion    [2]int
+		expectedSteps []int
+	}{
+		// The cart and buy before the view complete the first attempt
+		{name: "unanchored", anchored: false, entered: 2, conversion: [2]int{1, 3}, expectedSteps: []int{1, 2, 2}},
+		// Anchored attempts only start at the view
+		{name: "anchored", anchored: true, entered: 1, conversion: [2]int{3, 6}, expectedSteps: []int{1, 1, 1}},
+	} {
+		cfg := modeFunnelConfig(config.ModeUnordered)
+		cfg.AnchorFirstStep = tt.anchored
+		result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+
+		if result.EnteredFunnel != tt.entered {
+			t.Errorf("%s: expected %d attempts to enter the funnel, got %d", tt.name, tt.entered, result.EnteredFunnel)
+		}
+		if len(result.Conversions) == 0 || result.Conversions[0].StartEntry != tt.conversion[0] || result.Conversions[0].EndEntry != tt.conversion[1] {
+			t.Errorf("%s: expected first conversion from entry %d to %d, got %+v", tt.name, tt.conversion[0], tt.conversion[1], result.Conversions)
+		}
+		for i, expected := range tt.expectedSteps {
+			if result.Steps[i].EventCount != expected {
+				t.Errorf("%s, step %s: expected %d events, got %d", tt.name, result.Steps[i].Name, expected, result.Steps[i].EventCount)
+			}
+		}
+		if result.Steps[1].Percentage != float64(tt.expectedSteps[1])/float64(tt.entered)*100 {
+			t.Errorf("%s: expected cart percentage relative to entered attempts, got %.1f%%", tt.name, result.Steps[1].Percentage)
+		}
+	}
+}
+
+func TestAnalyzeFunnel_EnteredFunnel(t *testing.T) {
+	// Ordered funnels enter at the first step
+	result := NewFunnelAnalyzer(modeFunnelConfig(config.ModeLoose)).AnalyzeFunnel(messages("cart", "view", "view", "cart", "view"), 0)
+	if result.EnteredFunnel != result.Steps[0].EventCount || result.EnteredFunnel != 1 {
+		t.Errorf("Expected 1 attempt to enter at the first step, got %d (first step %d)", result.EnteredFunnel, result.Steps[0].EventCount)
+	}
+
+	entries := []*parser.LogEntry{
+		userEvent("view", "alice"),
+		userEvent("cart", "bob"),
+		userEvent("view", "carol"),
+		userEvent("cart", "alice"),
+	}
+	cfg := groupedFunnelConfig()
+	cfg.Mode = config.ModeUnordered
+	grouped := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+	if grouped.EnteredFunnel != 3 || fmt.Sprintf("%.1f", grouped.Steps[1].Percentage) != "66.7" {
+		t.Errorf("Expected 3 groups to enter and 2 of them to reach cart, got %d and %.1f%%", grouped.EnteredFunnel, grouped.Steps[1].Percentage)
+	}
+	cfg.AnchorFirstStep = true
+	grouped = NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+	if grouped.EnteredFunnel != 2 || grouped.Steps[1].Percentage != 50 {
+		t.Errorf("Expected bob not to enter an anchored funnel, got %d groups and %.1f%% at cart", grouped.EnteredFunnel, grouped.Steps[1].Percentage)
+	}
+}
@@ -0,0 +1,249 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds of property schema violations
+const (
+	// ViolationMissing is a required property the event does not have
+	ViolationMissing = "missing"
+	// ViolationType is a property value of another type than expected
+	ViolationType = "type"
+	// ViolationPattern is a property value that does not match the pattern
+	ViolationPattern = "pattern"
+)
+
+// AuditAnalyzer checks every occurrence of events against the properties
+// their schema expects
+type AuditAnalyzer struct {
+	matcher *CountAnalyzer
+	// events holds the checked properties of each event of the schema, in
+	// the order of the matcher's patterns
+	events [][]auditProperty
+}
+
+// auditProperty is a property of an event schema with its compiled pattern
+type auditProperty struct {
+	name   string
+	schema config.PropertySchema
+	regex  *regexp.Regexp
+}
+
+// AuditResult lists the schema violations of all audited events in log order
+type AuditResult struct {
+	TotalEventsAnalyzed int `json:"total_events_analyzed"`
+	// AuditedEvents counts the entries matching an event of the schema
+	AuditedEvents int `json:"audited_events"`
+	// InvalidEvents counts the audited entries with at least one violation
+	InvalidEvents int                 `json:"invalid_events"`
+	Events        []EventAudit        `json:"events"`
+	Violations    []PropertyViolation `json:"violations"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+// EventAudit is how many occurrences of an event of the schema were audited
+// and how many of them violate it
+type EventAudit struct {
+	Pattern            string `json:"pattern"`
+	Occurrences        int    `json:"occurrences"`
+	InvalidOccurrences int    `json:"invalid_occurrences"`
+}
+
+// PropertyViolation is a property of an event occurrence that does not match
+// the schema
+type PropertyViolation struct {
+	Event    string `json:"event"`
+	Property string `json:"property"`
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+	// Value is the offending value, unset for missing properties
+	Value interface{} `json:"value,omitempty"`
+	// EntryIndex is the 1-based position of the entry among the analyzed
+	// entries, Source and Line its position in the log
+	EntryIndex int    `json:"entry_index"`
+	Source     string `json:"source,omitempty"`
+	Line       int    `json:"line,omitempty"`
+}
+
+// NewAuditAnalyzer creates an analyzer checking events against the property
+// schema of auditConfig
+func NewAuditAnalyzer(auditConfig *config.AuditConfig, options CountOptions) (*AuditAnalyzer, error) {
+	logrus.WithField("event_count", len(auditConfig.Events)).Debug("Creating new audit analyzer")
+
+	if len(auditConfig.Events) == 0 {
+		return nil, fmt.Errorf("at least 1 event is required")
+	}
+
+	patterns := make([]string, len(auditConfig.Events))
+	events := make([][]auditProperty, len(auditConfig.Events))
+	for i, event := range auditConfig.Events {
+		patterns[i] = event.EventPattern
+		for name, schema := range event.Properties {
+			property := auditProperty{name: name, schema: schema}
+			if schema.Pattern != "" {
+				regex, err := regexp.Compile(schema.Pattern)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pattern of property '%s': %w", name, err)
+				}
+				property.regex = regex
+			}
+			events[i] = append(events[i], property)
+		}
+		sort.Slice(events[i], func(a, b int) bool {
+			return events[i][a].name < events[i][b].name
+		})
+	}
+
+	matcher, err := NewCountAnalyzerWithOptions(patterns, options)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditAnalyzer{matcher: matcher, events: events}, nil
+}
+
+func (aa *AuditAnalyzer) AnalyzeAudit(entries []*parser.LogEntry) *AuditResult {
+	return aa.AnalyzeAuditContext(context.Background(), entries)
+}
+
+// AnalyzeAuditContext is like AnalyzeAudit but stops when ctx is cancelled,
+// returning the violations of the entries analyzed so far marked as partial
+func (aa *AuditAnalyzer) AnalyzeAuditContext(ctx context.Context, entries []*parser.LogEntry) *AuditResult {
+	patterns := aa.matcher.patterns
+	logrus.WithFields(logrus.Fields{
+		"entry_count": len(entries),
+		"event_count": len(patterns),
+	}).Info("Starting event audit")
+
+	result := &AuditResult{
+		TotalEventsAnalyzed: len(entries),
+		Events:              make([]EventAudit, len(patterns)),
+		Violations:          []PropertyViolation{},
+	}
+	for i, pattern := range patterns {
+		result.Events[i].Pattern = pattern.Name
+	}
+
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Event audit cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+
+		audited, invalid := false, false
+		for eventIndex, pattern := range patterns {
+			if !aa.matcher.eventMatchesPattern(entry, pattern) {
+				continue
+			}
+			audited = true
+			result.Events[eventIndex].Occurrences++
+
+			violations := 0
+			for _, property := range aa.events[eventIndex] {
+				violation, ok := property.check(entry)
+				if !ok {
+					continue
+				}
+				violation.Event = pattern.Name
+				violation.EntryIndex = entryIndex + 1
+				violation.Source = entry.Source
+				violation.Line = entry.Line
+				result.Violations = append(result.Violations, violation)
+				violations++
+			}
+			if violations > 0 {
+				result.Events[eventIndex].InvalidOccurrences++
+				invalid = true
+				logrus.WithFields(logrus.Fields{
+					"entry_index": entryIndex + 1,
+					"event":       pattern.Name,
+					"violations":  violations,
+				}).Debug("Event violates its property schema")
+			}
+		}
+		if audited {
+			result.AuditedEvents++
+		}
+		if invalid {
+			result.InvalidEvents++
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"audited_events": result.AuditedEvents,
+		"invalid_events": result.InvalidEvents,
+		"violations":     len(result.Violations),
+		"partial":        result.Partial,
+	}).Info("Event audit completed")
+
+	return result
+}
+
+// check returns the violation of the property by entry, if any
+func (p auditProperty) check(entry *parser.LogEntry) (PropertyViolation, bool) {
+	violation := PropertyViolation{Property: p.name}
+	value, exists := parser.LookupProperty(entry.EventData, p.name)
+	if !exists {
+		if !p.schema.Required {
+			return violation, false
+		}
+		violation.Kind = ViolationMissing
+		violation.Message = "missing required property"
+		return violation, true
+	}
+
+	violation.Value = value
+	if p.schema.Type != "" && !hasPropertyType(value, p.schema.Type) {
+		violation.Kind = ViolationType
+		violation.Message = fmt.Sprintf("expected %s, got %s", p.schema.Type, propertyType(value))
+		return violation, true
+	}
+	if p.regex != nil && (value == nil || !p.regex.MatchString(fmt.Sprint(value))) {
+		violation.Kind = ViolationPattern
+		violation.Message = fmt.Sprintf("does not match %s", p.schema.Pattern)
+		return violation, true
+	}
+	return violation, false
+}
+
+// hasPropertyType reports whether value is of the schema type want. Whole
+// numbers are integers and numbers alike.
+func hasPropertyType(value interface{}, want string) bool {
+	actual := propertyType(value)
+	if want == config.PropertyInteger {
+		number, ok := value.(float64)
+		return actual == config.PropertyNumber && (!ok || number == math.Trunc(number))
+	}
+	return actual == want
+}
+
+// propertyType returns the schema type of an event data value, or null
+func propertyType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return config.PropertyString
+	case float64, float32, int, int64:
+		return config.PropertyNumber
+	case bool:
+		return config.PropertyBoolean
+	case map[string]interface{}:
+		return config.PropertyObject
+	case []interface{}:
+		return config.PropertyArray
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestAuditAnalyzer_Violations(t *testing.T) {
+	auditConfig := &config.AuditConfig{Events: []config.EventSchema{
+		{
+			EventPattern: "^purchase$",
+			Properties: map[string]config.PropertySchema{
+				"order_id":   {Type: config.PropertyString, Required: true, Pattern: "^ord_[0-9]+$"},
+				"amount":     {Type: config.PropertyNumber, Required: true},
+				"cart.items": {Type: config.PropertyInteger},
+			},
+		},
+		{
+			EventPattern: "^login$",
+			Properties: map[string]config.PropertySchema{
+				"user_id": {Required: true},
+			},
+		},
+	}}
+	analyzer, err := NewAuditAnalyzer(auditConfig, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewAuditAnalyzer() unexpected error: %v", err)
+	}
+
+	event := func(data map[string]interface{}, line int) *parser.LogEntry {
+		return &parser.LogEntry{EventData: data, Source: "app.log", Line: line}
+	}
+	entries := []*parser.LogEntry{
+		event(map[string]interface{}{"event": "login", "user_id": "alice"}, 1),
+		event(map[string]interface{}{"event": "purchase", "order_id": "ord_1", "amount": 9.99, "cart": map[string]interface{}{"items": 2.0}}, 2),
+		event(map[string]interface{}{"event": "purchase", "order_id": "1234", "amount": "12,50"}, 3),
+		event(map[string]interface{}{"event": "view_product"}, 4),
+		event(map[string]interface{}{"event": "purchase", "amount": 5.0, "cart": map[string]interface{}{"items": 1.5}}, 6),
+		event(map[string]interface{}{"event": "login"}, 7),
+	}
+
+	result := analyzer.AnalyzeAudit(entries)
+	if result.AuditedEvents != 5 || result.InvalidEvents != 3 {
+		t.Fatalf("Expected 5 audited and 3 invalid events, got %+v", result)
+	}
+	wantEvents := []EventAudit{
+		{Pattern: "^purchase$", Occurrences: 3, InvalidOccurrences: 2},
+		{Pattern: "^login$", Occurrences: 2, InvalidOccurrences: 1},
+	}
+	for i, want := range wantEvents {
+		if result.Events[i] != want {
+			t.Errorf("Events[%d] = %+v, want %+v", i, result.Events[i], want)
+		}
+	}
+
+	want := []PropertyViolation{
+		{Event: "^purchase$", Property: "amount", Kind: ViolationType, Message: "expected number, got string", Value: "12,50", EntryIndex: 3, Source: "app.log", Line: 3},
+		{Event: "^purchase$", Property: "order_id", Kind: ViolationPattern, Message: "does not match ^ord_[0-9]+$", Value: "1234", EntryIndex: 3, Source: "app.log", Line: 3},
+		{Event: "^purchase$", Property: "cart.items", Kind: ViolationType, Message: "expected integer, got number", Value: 1.5, EntryIndex: 5, Source: "app.log", Line: 6},
+		{Event: "^purchase$", Property: "order_id", Kind: ViolationMissing, Message: "missing required property", EntryIndex: 5, Source: "app.log", Line: 6},
+		{Event: "^login$", Property: "user_id", Kind: ViolationMissing, Message: "missing required property", EntryIndex: 6, Source: "app.log", Line: 7},
+	}
+	if len(result.Violations) != len(want) {
+		t.Fatalf("Expected %d violations, got %+v", len(want), result.Violations)
+	}
+	for i := range want {
+		if result.Violations[i] != want[i] {
+			t.Errorf("Violations[%d] = %+v, want %+v", i, result.Violations[i], want[i])
+		}
+	}
+}
+
+func TestAuditAnalyzer_Cancelled(t *testing.T) {
+	auditConfig := &config.AuditConfig{Events: []config.EventSchema{
+		{EventPattern: "login", Properties: map[string]config.PropertySchema{"user_id": {Required: true}}},
+	}}
+	analyzer, err := NewAuditAnalyzer(auditConfig, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewAuditAnalyzer() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := analyzer.AnalyzeAuditContext(ctx, []*parser.LogEntry{{Message: "login"}})
+	if !result.Partial || result.TotalEventsAnalyzed != 0 || len(result.Violations) != 0 {
+		t.Errorf("Expected a partial result without analyzed events, got %+v", result)
+	}
+}
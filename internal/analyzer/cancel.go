@@ -0,0 +1,15 @@
+package analyzer
+
+import (
+	"context"
+)
+
+// cancelCheckInterval is the number of entries analyzed between context checks
+const cancelCheckInterval = 1024
+
+// cancelled reports whether analysis should stop before the entry at
+// entryIndex. The context is only checked every cancelCheckInterval entries
+// to keep the per-entry overhead low.
+func cancelled(ctx context.Context, entryIndex int) bool {
+	return entryIndex%cancelCheckInterval == 0 && ctx.Err() != nil
+}
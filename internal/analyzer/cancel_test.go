@@ -0,0 +1,26 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if cancelled(ctx, 0) {
+		t.Error("cancelled() should be false for an active context")
+	}
+
+	cancel()
+
+	if !cancelled(ctx, 0) {
+		t.Error("cancelled() should be true for a cancelled context at a check point")
+	}
+	if cancelled(ctx, 1) {
+		t.Error("cancelled() should only check the context every cancelCheckInterval entries")
+	}
+	if !cancelled(ctx, cancelCheckInterval) {
+		t.Error("cancelled() should be true for a cancelled context at the next check point")
+	}
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+// LabeledResult holds the funnel results of the logs given for one label
+type LabeledResult struct {
+	Label   string          `json:"label"`
+	Funnels []*FunnelResult `json:"funnels"`
+}
+
+// LabeledReport holds the results of every label together with a comparison
+// of each funnel across labels
+type LabeledReport struct {
+	Labels      []LabeledResult    `json:"labels"`
+	Comparisons []FunnelComparison `json:"comparisons"`
+}
+
+// FunnelComparison lists the step results of one funnel side by side for all
+// labels. Slices are in the order of Labels.
+type FunnelComparison struct {
+	FunnelName string           `json:"funnel_name"`
+	Labels     []string         `json:"labels"`
+	Completed  []bool           `json:"completed"`
+	Steps      []StepComparison `json:"steps"`
+}
+
+// StepComparison is the event count and conversion of one step per label
+type StepComparison struct {
+	Name        string    `json:"name"`
+	EventCounts []int     `json:"event_counts"`
+	Percentages []float64 `json:"percentages"`
+}
+
+// NewLabeledReport compares the funnels of labeled results. Every label must
+// have been analyzed with the same funnels in the same order.
+func NewLabeledReport(results []LabeledResult) *LabeledReport {
+	report := &LabeledReport{Labels: results, Comparisons: []FunnelComparison{}}
+	if len(results) == 0 {
+		return report
+	}
+
+	labels := make([]string, len(results))
+	for i, result := range results {
+		labels[i] = result.Label
+	}
+
+	for funnelIndex, funnel := range results[0].Funnels {
+		comparison := FunnelComparison{
+			FunnelName: funnel.FunnelName,
+			Labels:     labels,
+			Completed:  make([]bool, len(results)),
+			Steps:      make([]StepComparison, len(funnel.Steps)),
+		}
+		for stepIndex, step := range funnel.Steps {
+			comparison.Steps[stepIndex] = StepComparison{
+				Name:        step.Name,
+				EventCounts: make([]int, len(results)),
+				Percentages: make([]float64, len(results)),
+			}
+		}
+
+		for labelIndex, result := range results {
+			labelFunnel := result.Funnels[funnelIndex]
+			comparison.Completed[labelIndex] = labelFunnel.FunnelCompleted
+			for stepIndex, step := range labelFunnel.Steps {
+				comparison.Steps[stepIndex].EventCounts[labelIndex] = step.EventCount
+				comparison.Steps[stepIndex].Percentages[labelIndex] = step.Percentage
+			}
+		}
+
+		report.Comparisons = append(report.Comparisons, comparison)
+	}
+
+	return report
+}
@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewLabeledReport(t *testing.T) {
+	funnel := func(completed bool, counts ...int) *FunnelResult {
+		result := &FunnelResult{FunnelName: "Checkout", FunnelCompleted: completed}
+		for i, count := range counts {
+			result.Steps = append(result.Steps, StepResult{
+				Name:       []string{"View", "Buy"}[i],
+				EventCount: count,
+				Percentage: float64(count) / float64(counts[0]) * 100,
+			})
+		}
+		return result
+	}
+
+	report := NewLabeledReport([]LabeledResult{
+		{Label: "device=pixel7", Funnels: []*FunnelResult{funnel(true, 10, 4)}},
+		{Label: "device=s23", Funnels: []*FunnelResult{funnel(false, 8, 0)}},
+	})
+
+	if len(report.Labels) != 2 {
+		t.Errorf("Expected 2 labels, got %d", len(report.Labels))
+	}
+	if len(report.Comparisons) != 1 {
+		t.Fatalf("Expected 1 comparison, got %d", len(report.Comparisons))
+	}
+
+	comparison := report.Comparisons[0]
+	if comparison.FunnelName != "Checkout" {
+		t.Errorf("Expected funnel name Checkout, got %q", comparison.FunnelName)
+	}
+	if !reflect.DeepEqual(comparison.Labels, []string{"device=pixel7", "device=s23"}) {
+		t.Errorf("Unexpected labels %v", comparison.Labels)
+	}
+	if !reflect.DeepEqual(comparison.Completed, []bool{true, false}) {
+		t.Errorf("Unexpected completion %v", comparison.Completed)
+	}
+
+	buy := comparison.Steps[1]
+	if buy.Name != "Buy" || !reflect.DeepEqual(buy.EventCounts, []int{4, 0}) || !reflect.DeepEqual(buy.Percentages, []float64{40, 0}) {
+		t.Errorf("Unexpected step comparison %+v", buy)
+	}
+}
+
+func TestNewLabeledReportEmpty(t *testing.T) {
+	report := NewLabeledReport(nil)
+	if report.Comparisons == nil || len(report.Comparisons) != 0 {
+		t.Errorf("Expected empty comparisons, got %+v", report.Comparisons)
+	}
+}
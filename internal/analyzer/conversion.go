@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"time"
+)
+
+// FunnelConversion is a funnel attempt that reached the last step
+type FunnelConversion struct {
+	// Number counts conversions from 1 in the order they completed
+	Number int `json:"number"`
+	// Group is the group_by value of the attempt, empty for ungrouped funnels
+	Group string `json:"group,omitempty"`
+	// StartEntry and EndEntry are the 1-based indexes of the entries that
+	// matched the first and the last step
+	StartEntry int        `json:"start_entry"`
+	EndEntry   int        `json:"end_entry"`
+	StartTime  *time.Time `json:"start_time,omitempty"`
+	EndTime    *time.Time `json:"end_time,omitempty"`
+	// DurationSeconds is the time from the first to the last step, unset
+	// unless both entries have a timestamp
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	// Steps are the entries that matched each step, in step order, when
+	// conversion steps were requested
+	Steps []ConversionStep `json:"steps,omitempty"`
+}
+
+// ConversionStep is the entry that matched a step of a conversion
+type ConversionStep struct {
+	Step string `json:"step"`
+	StepSample
+}
+
+// newConversion describes the conversion of an attempt from the entries that
+// matched its steps, in step order
+func (fa *FunnelAnalyzer) newConversion(number int, group string, attempt []groupedEntry) FunnelConversion {
+	// Steps of unordered funnels may match in any order
+	start, end := attempt[0], attempt[0]
+	for _, matched := range attempt[1:] {
+		if matched.index < start.index {
+			start = matched
+		}
+		if matched.index > end.index {
+			end = matched
+		}
+	}
+	conversion := FunnelConversion{
+		Number:     number,
+		Group:      group,
+		StartEntry: start.index + 1,
+		EndEntry:   end.index + 1,
+	}
+	if !start.entry.Timestamp.IsZero() {
+		startTime := start.entry.Timestamp
+		conversion.StartTime = &startTime
+	}
+	if !end.entry.Timestamp.IsZero() {
+		endTime := end.entry.Timestamp
+		conversion.EndTime = &endTime
+	}
+	if conversion.StartTime != nil && conversion.EndTime != nil {
+		conversion.DurationSeconds = end.entry.Timestamp.Sub(start.entry.Timestamp).Seconds()
+	}
+	if fa.conversionSteps {
+		conversion.Steps = make([]ConversionStep, len(attempt))
+		for i, matched := range attempt {
+			conversion.Steps[i] = ConversionStep{Step: fa.config.Steps[i].Name, StepSample: newStepSample(matched.index, matched.entry)}
+		}
+	}
+	return conversion
+}
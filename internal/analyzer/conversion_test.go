@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestAnalyzeFunnel_Conversions(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "Login",
+		Steps: []config.Step{
+			{Name: "Start", EventPattern: "start"},
+			{Name: "Done", EventPattern: "done"},
+		},
+	}
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(message string, seconds int) *parser.LogEntry {
+		return &parser.LogEntry{Message: message, Timestamp: base.Add(time.Duration(seconds) * time.Second)}
+	}
+	entries := []*parser.LogEntry{
+		at("start", 0),
+		at("noise", 1),
+		at("done", 5),
+		at("start", 60),
+		at("start", 70), // the attempt waits for done
+		at("done", 90),
+		at("start", 120),
+		at("done", 121),
+	}
+
+	for _, tt := range []struct {
+		limit    int
+		expected int
+	}{
+		{limit: 0, expected: 3},
+		{limit: 2, expected: 2},
+		{limit: 5, expected: 3},
+	} {
+		result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, tt.limit)
+
+		if result.ConversionsFound != tt.expected || len(result.Conversions) != tt.expected {
+			t.Fatalf("limit=%d: expected %d conversions, got %d: %+v", tt.limit, tt.expected, result.ConversionsFound, result.Conversions)
+		}
+		first := result.Conversions[0]
+		if first.Number != 1 || first.StartEntry != 1 || first.EndEntry != 3 || first.DurationSeconds != 5 {
+			t.Errorf("limit=%d: unexpected first conversion %+v", tt.limit, first)
+		}
+		second := result.Conversions[1]
+		if second.Number != 2 || second.StartEntry != 4 || second.EndEntry != 6 || second.DurationSeconds != 30 ||
+			!second.StartTime.Equal(base.Add(60*time.Second)) || !second.EndTime.Equal(base.Add(90*time.Second)) {
+			t.Errorf("limit=%d: unexpected second conversion %+v", tt.limit, second)
+		}
+	}
+}
+
+func TestAnalyzeFunnel_ConversionsWithoutTimestamps(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "Login",
+		Steps: []config.Step{
+			{Name: "Start", EventPattern: "start"},
+			{Name: "Done", EventPattern: "done"},
+		},
+	}
+	entries := []*parser.LogEntry{{Message: "start"}, {Message: "done"}}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+
+	if len(result.Conversions) != 1 {
+		t.Fatalf("Expected 1 conversion, got %+v", result.Conversions)
+	}
+	conversion := result.Conversions[0]
+	if conversion.StartTime != nil || conversion.EndTime != nil || conversion.DurationSeconds != 0 ||
+		conversion.StartEntry != 1 || conversion.EndEntry != 2 {
+		t.Errorf("Expected only entry indexes without timestamps, got %+v", conversion)
+	}
+}
+
+func TestAnalyzeFunnel_GroupByConversions(t *testing.T) {
+	entries := []*parser.LogEntry{
+		userEvent("view", "alice"),
+		userEvent("view", "bob"),
+		userEvent("cart", "bob"),
+		userEvent("cart", "alice"),
+		userEvent("buy", "bob"),
+		userEvent("buy", "alice"),
+	}
+
+	result := NewFunnelAnalyzer(groupedFunnelConfig()).AnalyzeFunnel(entries, 0)
+
+	if result.ConversionsFound != 2 || len(result.Conversions) != 2 {
+		t.Fatalf("Expected 2 converted groups, got %d: %+v", result.ConversionsFound, result.Conversions)
+	}
+	// Bob converted first, though alice appeared first
+	bob := result.Conversions[0]
+	if bob.Number != 1 || bob.Group != "bob" || bob.StartEntry != 2 || bob.EndEntry != 5 {
+		t.Errorf("Unexpected conversion of bob: %+v", bob)
+	}
+	alice := result.Conversions[1]
+	if alice.Number != 2 || alice.Group != "alice" || alice.StartEntry != 1 || alice.EndEntry != 6 {
+		t.Errorf("Unexpected conversion of alice: %+v", alice)
+	}
+}
+
+func TestAnalyzeFunnel_ConversionSteps(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "Checkout",
+		Steps: []config.Step{
+			{Name: "View", EventPattern: "view"},
+			{Name: "Cart", EventPattern: "cart"},
+			{Name: "Buy", EventPattern: "buy"},
+		},
+	}
+	entries := []*parser.LogEntry{
+		{Message: "view"},
+		{Message: "cart"},
+		{Message: "view"}, // the attempt waits for buy
+		{Message: "buy"},
+		{Message: "view"},
+		{Message: "cart"},
+		{Message: "buy"},
+	}
+
+	for _, limit := range []int{0, 2} {
+		result := NewFunnelAnalyzerWithOptions(cfg, FunnelOptions{ConversionSteps: true}).AnalyzeFunnel(entries, limit)
+
+		if len(result.Conversions) != 2 {
+			t.Fatalf("limit=%d: expected 2 conversions, got %+v", limit, result.Conversions)
+		}
+		expected := [][]int{{1, 2, 4}, {5, 6, 7}}
+		for i, conversion := range result.Conversions {
+			if len(conversion.Steps) != len(cfg.Steps) {
+				t.Fatalf("limit=%d: expected an entry per step, got %+v", limit, conversion.Steps)
+			}
+			for j, step := range conversion.Steps {
+				if step.Step != cfg.Steps[j].Name || step.EntryIndex != expected[i][j] || step.Message != entries[expected[i][j]-1].Message {
+					t.Errorf("limit=%d: conversion %d step %d: unexpected %+v", limit, i+1, j+1, step)
+				}
+			}
+		}
+	}
+
+	grouped := NewFunnelAnalyzerWithOptions(groupedFunnelConfig(), FunnelOptions{ConversionSteps: true}).AnalyzeFunnel([]*parser.LogEntry{
+		userEvent("view", "alice"),
+		userEvent("view", "bob"),
+		userEvent("cart", "alice"),
+		userEvent("buy", "alice"),
+	}, 0)
+	if len(grouped.Conversions) != 1 || len(grouped.Conversions[0].Steps) != 3 || grouped.Conversions[0].Steps[1].EntryIndex != 3 {
+		t.Errorf("Expected the steps of alice's conversion, got %+v", grouped.Conversions)
+	}
+
+	// Steps are only kept when requested
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+	if len(result.Conversions) != 2 || result.Conversions[0].Steps != nil {
+		t.Errorf("Expected conversions without steps, got %+v", result.Conversions)
+	}
+}
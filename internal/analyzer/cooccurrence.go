@@ -0,0 +1,242 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// Units the co-occurrence analyzer splits a log into
+const (
+	CooccurrenceSession = "session"
+	CooccurrenceWindow  = "window"
+)
+
+// CooccurrenceAnalyzer counts how often pairs of event patterns occur in the
+// same session or time window
+type CooccurrenceAnalyzer struct {
+	counter  *CountAnalyzer
+	sessions *SessionAnalyzer
+	window   time.Duration
+}
+
+// CooccurrenceOptions changes how the co-occurrence analyzer matches patterns
+// and splits the log
+type CooccurrenceOptions struct {
+	// IgnoreCase matches all patterns regardless of case
+	IgnoreCase bool
+	// Labels names the patterns of the same index in the results instead of
+	// the pattern itself, unless empty
+	Labels []string
+	// Sessions splits entries into sessions
+	Sessions *SessionAnalyzer
+	// Window splits entries into fixed time windows instead of sessions
+	Window time.Duration
+}
+
+// CooccurrenceResult holds the co-occurrence matrix of the patterns. Matrix
+// rows and columns are in the order of Patterns.
+type CooccurrenceResult struct {
+	TotalEventsAnalyzed int    `json:"total_events_analyzed"`
+	Unit                string `json:"unit"`
+	SessionKey          string `json:"session_key,omitempty"`
+	// IdleTimeoutSeconds is the gap between events that starts a new session
+	IdleTimeoutSeconds float64 `json:"idle_timeout_seconds,omitempty"`
+	WindowSeconds      float64 `json:"window_seconds,omitempty"`
+	// Units counts the sessions or windows
+	Units int `json:"units"`
+	// SkippedEntries are entries without the session key, or without a
+	// timestamp for windows
+	SkippedEntries int                   `json:"skipped_entries,omitempty"`
+	Patterns       []CooccurrencePattern `json:"patterns"`
+	// Matrix counts the units in which both patterns matched; the diagonal
+	// counts the units in which the pattern matched
+	Matrix [][]int `json:"matrix"`
+	// Conditional is the percentage of units with the row pattern in which the
+	// column pattern matched too, P(column | row)
+	Conditional [][]float64 `json:"conditional"`
+	// Before counts the units in which the row pattern matched before the
+	// column pattern matched
+	Before [][]int `json:"before"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+// CooccurrencePattern is the number of units a pattern matched in
+type CooccurrencePattern struct {
+	Pattern    string  `json:"pattern"`
+	Units      int     `json:"units"`
+	Percentage float64 `json:"percentage"`
+}
+
+// unitMatches holds the first and last entry index of each pattern in a
+// unit, -1 if the pattern did not match
+type unitMatches struct {
+	first []int
+	last  []int
+}
+
+// NewCooccurrenceAnalyzer creates a co-occurrence analyzer for eventPatterns
+// splitting the log into sessions, or into windows with options.Window
+func NewCooccurrenceAnalyzer(eventPatterns []string, options CooccurrenceOptions) (*CooccurrenceAnalyzer, error) {
+	logrus.WithFields(logrus.Fields{
+		"pattern_count": len(eventPatterns),
+		"ignore_case":   options.IgnoreCase,
+		"window":        options.Window,
+	}).Debug("Creating new co-occurrence analyzer")
+
+	if len(eventPatterns) < 2 {
+		return nil, fmt.Errorf("at least 2 event patterns are required, got %d", len(eventPatterns))
+	}
+	if options.Window < 0 {
+		return nil, fmt.Errorf("window cannot be negative")
+	}
+	if options.Window == 0 && options.Sessions == nil {
+		return nil, fmt.Errorf("either sessions or a window are required")
+	}
+
+	counter, err := NewCountAnalyzerWithOptions(eventPatterns, CountOptions{
+		IgnoreCase: options.IgnoreCase,
+		Labels:     options.Labels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	analyzer := &CooccurrenceAnalyzer{counter: counter, window: options.Window}
+	if options.Window == 0 {
+		analyzer.sessions = options.Sessions
+	}
+	return analyzer, nil
+}
+
+func (ca *CooccurrenceAnalyzer) AnalyzeCooccurrence(entries []*parser.LogEntry) *CooccurrenceResult {
+	return ca.AnalyzeCooccurrenceContext(context.Background(), entries)
+}
+
+// AnalyzeCooccurrenceContext is like AnalyzeCooccurrence but stops when ctx
+// is cancelled, returning the result for the entries analyzed so far marked
+// as partial
+func (ca *CooccurrenceAnalyzer) AnalyzeCooccurrenceContext(ctx context.Context, entries []*parser.LogEntry) *CooccurrenceResult {
+	patterns := ca.counter.patterns
+	logrus.WithFields(logrus.Fields{
+		"entry_count":   len(entries),
+		"pattern_count": len(patterns),
+		"window":        ca.window,
+	}).Info("Starting co-occurrence analysis")
+
+	result := &CooccurrenceResult{TotalEventsAnalyzed: len(entries)}
+	var tracker *sessionTracker
+	if ca.sessions != nil {
+		tracker = ca.sessions.newTracker()
+		result.Unit = CooccurrenceSession
+		result.SessionKey = ca.sessions.sessionKey
+		result.IdleTimeoutSeconds = ca.sessions.idleTimeout.Seconds()
+	} else {
+		result.Unit = CooccurrenceWindow
+		result.WindowSeconds = ca.window.Seconds()
+	}
+
+	units := make(map[any]*unitMatches)
+	var unitOrder []*unitMatches
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Co-occurrence analysis cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+
+		var key any
+		if tracker != nil {
+			s := tracker.add(entryIndex, entry)
+			if s == nil {
+				continue
+			}
+			key = s
+		} else {
+			if entry.Timestamp.IsZero() {
+				result.SkippedEntries++
+				continue
+			}
+			key = entry.Timestamp.Truncate(ca.window)
+		}
+
+		unit, exists := units[key]
+		if !exists {
+			unit = &unitMatches{first: make([]int, len(patterns)), last: make([]int, len(patterns))}
+			for i := range patterns {
+				unit.first[i], unit.last[i] = -1, -1
+			}
+			units[key] = unit
+			unitOrder = append(unitOrder, unit)
+		}
+
+		for patternIndex, pattern := range patterns {
+			if !ca.counter.eventMatchesPattern(entry, pattern) {
+				continue
+			}
+			if unit.first[patternIndex] < 0 {
+				unit.first[patternIndex] = entryIndex
+			}
+			unit.last[patternIndex] = entryIndex
+		}
+	}
+
+	if tracker != nil {
+		result.SkippedEntries = tracker.unkeyed
+		result.Units = len(tracker.sessions())
+	} else {
+		result.Units = len(unitOrder)
+	}
+
+	result.Matrix = make([][]int, len(patterns))
+	result.Before = make([][]int, len(patterns))
+	for i := range patterns {
+		result.Matrix[i] = make([]int, len(patterns))
+		result.Before[i] = make([]int, len(patterns))
+	}
+	for _, unit := range unitOrder {
+		for i := range patterns {
+			if unit.first[i] < 0 {
+				continue
+			}
+			for j := range patterns {
+				if unit.first[j] < 0 {
+					continue
+				}
+				result.Matrix[i][j]++
+				if i != j && unit.first[i] < unit.last[j] {
+					result.Before[i][j]++
+				}
+			}
+		}
+	}
+
+	result.Patterns = make([]CooccurrencePattern, len(patterns))
+	result.Conditional = make([][]float64, len(patterns))
+	for i, pattern := range patterns {
+		result.Patterns[i] = CooccurrencePattern{Pattern: pattern.Name, Units: result.Matrix[i][i]}
+		if result.Units > 0 {
+			result.Patterns[i].Percentage = float64(result.Matrix[i][i]) / float64(result.Units) * 100.0
+		}
+		result.Conditional[i] = make([]float64, len(patterns))
+		for j := range patterns {
+			if result.Matrix[i][i] > 0 {
+				result.Conditional[i][j] = float64(result.Matrix[i][j]) / float64(result.Matrix[i][i]) * 100.0
+			}
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"unit":            result.Unit,
+		"units":           result.Units,
+		"skipped_entries": result.SkippedEntries,
+		"partial":         result.Partial,
+	}).Info("Co-occurrence analysis completed")
+
+	return result
+}
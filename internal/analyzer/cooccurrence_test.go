@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestCooccurrenceAnalyzer_Sessions(t *testing.T) {
+	sessions, err := NewSessionAnalyzer("user_id", 0)
+	if err != nil {
+		t.Fatalf("NewSessionAnalyzer() unexpected error: %v", err)
+	}
+	analyzer, err := NewCooccurrenceAnalyzer([]string{"view", "cart", "purchase"}, CooccurrenceOptions{
+		Labels:   []string{"", "", "Buy"},
+		Sessions: sessions,
+	})
+	if err != nil {
+		t.Fatalf("NewCooccurrenceAnalyzer() unexpected error: %v", err)
+	}
+
+	entries := []*parser.LogEntry{
+		{EventData: map[string]interface{}{"event": "view", "user_id": "alice"}},
+		{EventData: map[string]interface{}{"event": "view", "user_id": "bob"}},
+		{EventData: map[string]interface{}{"event": "cart", "user_id": "alice"}},
+		{EventData: map[string]interface{}{"event": "purchase", "user_id": "alice"}},
+		{EventData: map[string]interface{}{"event": "purchase", "user_id": "bob"}},
+		{EventData: map[string]interface{}{"event": "view", "user_id": "bob"}},
+		{EventData: map[string]interface{}{"event": "cart", "user_id": "carol"}},
+		{EventData: map[string]interface{}{"event": "view"}},
+	}
+
+	result := analyzer.AnalyzeCooccurrence(entries)
+	if result.Unit != CooccurrenceSession || result.Units != 3 || result.SkippedEntries != 1 {
+		t.Fatalf("Expected 3 sessions and 1 skipped entry, got %+v", result)
+	}
+	if result.Patterns[2].Pattern != "Buy" || result.Patterns[2].Units != 2 {
+		t.Errorf("Expected the labeled purchase pattern in 2 sessions, got %+v", result.Patterns[2])
+	}
+
+	wantMatrix := [][]int{{2, 1, 2}, {1, 2, 1}, {2, 1, 2}}
+	wantBefore := [][]int{{0, 1, 2}, {0, 0, 1}, {1, 0, 0}}
+	for i := range wantMatrix {
+		for j := range wantMatrix[i] {
+			if result.Matrix[i][j] != wantMatrix[i][j] {
+				t.Errorf("Matrix[%d][%d] = %d, want %d", i, j, result.Matrix[i][j], wantMatrix[i][j])
+			}
+			if result.Before[i][j] != wantBefore[i][j] {
+				t.Errorf("Before[%d][%d] = %d, want %d", i, j, result.Before[i][j], wantBefore[i][j])
+			}
+		}
+	}
+	if result.Conditional[1][2] != 50 || result.Conditional[0][2] != 100 {
+		t.Errorf("Expected P(purchase | cart) = 50%% and P(purchase | view) = 100%%, got %v", result.Conditional)
+	}
+}
+
+func TestCooccurrenceAnalyzer_Windows(t *testing.T) {
+	analyzer, err := NewCooccurrenceAnalyzer([]string{"crash", "low_memory"}, CooccurrenceOptions{Window: time.Minute})
+	if err != nil {
+		t.Fatalf("NewCooccurrenceAnalyzer() unexpected error: %v", err)
+	}
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	entries := []*parser.LogEntry{
+		{Timestamp: start.Add(10 * time.Second), Message: "low_memory"},
+		{Timestamp: start.Add(50 * time.Second), Message: "crash"},
+		{Timestamp: start.Add(90 * time.Second), Message: "crash"},
+		{Message: "low_memory"},
+	}
+
+	result := analyzer.AnalyzeCooccurrence(entries)
+	if result.Unit != CooccurrenceWindow || result.WindowSeconds != 60 || result.Units != 2 || result.SkippedEntries != 1 {
+		t.Fatalf("Expected 2 windows of 60s and 1 untimed entry, got %+v", result)
+	}
+	if result.Matrix[0][1] != 1 || result.Before[1][0] != 1 || result.Before[0][1] != 0 {
+		t.Errorf("Expected low_memory before crash in one window, got %v and %v", result.Matrix, result.Before)
+	}
+	if result.Conditional[0][1] != 50 || result.Conditional[1][0] != 100 {
+		t.Errorf("Expected P(low_memory | crash) = 50%% and P(crash | low_memory) = 100%%, got %v", result.Conditional)
+	}
+}
+
+func TestNewCooccurrenceAnalyzerErrors(t *testing.T) {
+	sessions, _ := NewSessionAnalyzer("", 0)
+	tests := map[string]struct {
+		patterns []string
+		options  CooccurrenceOptions
+	}{
+		"single pattern":  {[]string{"view"}, CooccurrenceOptions{Sessions: sessions}},
+		"no unit":         {[]string{"view", "buy"}, CooccurrenceOptions{}},
+		"negative window": {[]string{"view", "buy"}, CooccurrenceOptions{Window: -time.Second}},
+		"invalid pattern": {[]string{"view", "("}, CooccurrenceOptions{Sessions: sessions}},
+	}
+	for name, tt := range tests {
+		if _, err := NewCooccurrenceAnalyzer(tt.patterns, tt.options); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package analyzer
 
 import (
-	"github.com/parfenovvs/loglion/internal/parser"
+	"context"
 	"regexp"
+	"strconv"
+	"time"
 
+	"github.com/parfenovvs/loglion/internal/parser"
 	"github.com/sirupsen/logrus"
 )
 
 type CountAnalyzer struct {
-	patterns []EventPattern
+	patterns    []EventPattern
+	groupBy     string
+	sessions    *SessionAnalyzer
+	distinct    string
+	distinctTop int
+	aggregate   string
 }
 
 type EventPattern struct {
@@ -18,28 +26,101 @@ type EventPattern struct {
 }
 
 type CountResult struct {
-	TotalEventsAnalyzed int            `json:"total_events_analyzed"`
-	PatternCounts       []PatternCount `json:"pattern_counts"`
+	TotalEventsAnalyzed int        `json:"total_events_analyzed"`
+	FirstTimestamp      *time.Time `json:"first_timestamp,omitempty"`
+	LastTimestamp       *time.Time `json:"last_timestamp,omitempty"`
+	DurationSeconds     float64    `json:"duration_seconds,omitempty"`
+	// EventsPerMinute is the rate of entries with a timestamp over the duration
+	EventsPerMinute    float64          `json:"events_per_minute,omitempty"`
+	PatternCounts      []PatternCount   `json:"pattern_counts"`
+	OverlappingEntries int              `json:"overlapping_entries,omitempty"`
+	Overlaps           []PatternOverlap `json:"overlaps,omitempty"`
+	// Groups breaks the counts down by an event data property when grouping is enabled
+	Groups *CountGroups `json:"groups,omitempty"`
+	// Sessions reports how many sessions matched each pattern when counting per session
+	Sessions *CountSessions `json:"sessions,omitempty"`
+	// Distinct reports the unique values of an event data property among the
+	// matches of each pattern when enabled
+	Distinct *CountDistinct `json:"distinct,omitempty"`
+	// Aggregate sums up a numeric event data property over the matches of each
+	// pattern when enabled
+	Aggregate *CountAggregate `json:"aggregate,omitempty"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
 }
 
 type PatternCount struct {
 	Pattern string `json:"pattern"`
 	Count   int    `json:"count"`
+	// PerMinute is the rate of matches over the duration of the result
+	PerMinute float64     `json:"per_minute,omitempty"`
+	Values    *ValueStats `json:"values,omitempty"`
+	// FirstTimestamp and LastTimestamp are the times of the first and last
+	// matches, if they have timestamps
+	FirstTimestamp *time.Time `json:"first_timestamp,omitempty"`
+	LastTimestamp  *time.Time `json:"last_timestamp,omitempty"`
+}
+
+// PatternOverlap counts entries matched by both patterns of a pair, which
+// means these entries are included in more than one pattern count
+type PatternOverlap struct {
+	Patterns []string `json:"patterns"`
+	Count    int      `json:"count"`
+}
+
+// CountOptions changes how the count analyzer matches patterns
+type CountOptions struct {
+	// IgnoreCase matches all patterns regardless of case
+	IgnoreCase bool
+	// GroupBy names the event data property the matches are broken down by
+	GroupBy string
+	// Sessions splits entries into sessions to report how many sessions
+	// matched each pattern
+	Sessions *SessionAnalyzer
+	// Distinct names the event data property whose unique values are counted
+	// among the matches of each pattern
+	Distinct string
+	// DistinctTop is the number of most frequent distinct values to report
+	DistinctTop int
+	// Labels names the patterns of the same index in the results instead of
+	// the pattern itself, unless empty
+	Labels []string
+	// Aggregate names the numeric event data property whose sum, average,
+	// minimum and maximum are computed over the matches of each pattern, broken
+	// down by GroupBy when set
+	Aggregate string
 }
 
 func NewCountAnalyzer(eventPatterns []string) (*CountAnalyzer, error) {
-	logrus.WithField("pattern_count", len(eventPatterns)).Debug("Creating new count analyzer")
+	return NewCountAnalyzerWithOptions(eventPatterns, CountOptions{})
+}
+
+// NewCountAnalyzerWithOptions creates a count analyzer for eventPatterns with
+// the given matching options
+func NewCountAnalyzerWithOptions(eventPatterns []string, options CountOptions) (*CountAnalyzer, error) {
+	logrus.WithFields(logrus.Fields{
+		"pattern_count": len(eventPatterns),
+		"ignore_case":   options.IgnoreCase,
+		"group_by":      options.GroupBy,
+		"per_session":   options.Sessions != nil,
+		"distinct":      options.Distinct,
+		"aggregate":     options.Aggregate,
+	}).Debug("Creating new count analyzer")
 
 	patterns := make([]EventPattern, len(eventPatterns))
 	for i, patternStr := range eventPatterns {
-		regex, err := regexp.Compile(patternStr)
+		regex, err := compilePattern(patternStr, options.IgnoreCase)
 		if err != nil {
 			logrus.WithError(err).WithField("pattern", patternStr).Error("Failed to compile event pattern regex")
 			return nil, err
 		}
 
+		name := patternStr
+		if i < len(options.Labels) && options.Labels[i] != "" {
+			name = options.Labels[i]
+		}
 		patterns[i] = EventPattern{
-			Name:    patternStr,
+			Name:    name,
 			Pattern: patternStr,
 			Regex:   regex,
 		}
@@ -51,11 +132,22 @@ func NewCountAnalyzer(eventPatterns []string) (*CountAnalyzer, error) {
 	}
 
 	return &CountAnalyzer{
-		patterns: patterns,
+		patterns:    patterns,
+		groupBy:     options.GroupBy,
+		sessions:    options.Sessions,
+		distinct:    options.Distinct,
+		distinctTop: options.DistinctTop,
+		aggregate:   options.Aggregate,
 	}, nil
 }
 
 func (ca *CountAnalyzer) AnalyzeCount(entries []*parser.LogEntry) *CountResult {
+	return ca.AnalyzeCountContext(context.Background(), entries)
+}
+
+// AnalyzeCountContext is like AnalyzeCount but stops when ctx is cancelled,
+// returning the counts for the entries analyzed so far marked as partial
+func (ca *CountAnalyzer) AnalyzeCountContext(ctx context.Context, entries []*parser.LogEntry) *CountResult {
 	logrus.WithFields(logrus.Fields{
 		"entry_count":   len(entries),
 		"pattern_count": len(ca.patterns),
@@ -71,6 +163,18 @@ func (ca *CountAnalyzer) AnalyzeCount(entries []*parser.LogEntry) *CountResult {
 
 	patternCounts := make([]PatternCount, len(ca.patterns))
 	counts := make([]int, len(ca.patterns))
+	values := make([][]float64, len(ca.patterns))
+	spans := make([]timeSpan, len(ca.patterns))
+	pairCounts := make(map[[2]int]int)
+	groups := newGroupCounter(ca.groupBy, len(ca.patterns))
+	sessions := newSessionCounter(ca.sessions, len(ca.patterns))
+	distinct := newDistinctCounter(ca.distinct, ca.distinctTop, len(ca.patterns))
+	aggregate := newAggregateCounter(ca.aggregate, ca.groupBy, len(ca.patterns))
+	overlappingEntries := 0
+	analyzedEntries := len(entries)
+	partial := false
+	var first, last time.Time
+	timedEntries := 0
 
 	// Initialize pattern counts
 	for i, pattern := range ca.patterns {
@@ -86,9 +190,32 @@ func (ca *CountAnalyzer) AnalyzeCount(entries []*parser.LogEntry) *CountResult {
 
 	// Count matches for each entry
 	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Count analysis cancelled, returning partial result")
+			analyzedEntries = entryIndex
+			partial = true
+			break
+		}
+
+		if !entry.Timestamp.IsZero() {
+			if timedEntries == 0 || entry.Timestamp.Before(first) {
+				first = entry.Timestamp
+			}
+			if timedEntries == 0 || entry.Timestamp.After(last) {
+				last = entry.Timestamp
+			}
+			timedEntries++
+		}
+
+		var matchedPatterns []int
 		for patternIndex, pattern := range ca.patterns {
-			if ca.eventMatchesPattern(entry, pattern) {
+			if target, matched := ca.matchPattern(entry, pattern); matched {
+				matchedPatterns = append(matchedPatterns, patternIndex)
 				counts[patternIndex]++
+				spans[patternIndex].add(entry.Timestamp)
+				if value, ok := captureNumericValue(target, pattern); ok {
+					values[patternIndex] = append(values[patternIndex], value)
+				}
 				logrus.WithFields(logrus.Fields{
 					"entry_index":   entryIndex + 1,
 					"pattern_index": patternIndex + 1,
@@ -98,31 +225,92 @@ func (ca *CountAnalyzer) AnalyzeCount(entries []*parser.LogEntry) *CountResult {
 				}).Debug("Event matched pattern")
 			}
 		}
+
+		groups.add(entry, matchedPatterns)
+		sessions.add(entryIndex, entry, matchedPatterns)
+		distinct.add(entry, matchedPatterns)
+		aggregate.add(entry, matchedPatterns)
+
+		if len(matchedPatterns) > 1 {
+			overlappingEntries++
+			for i := 0; i < len(matchedPatterns); i++ {
+				for j := i + 1; j < len(matchedPatterns); j++ {
+					pairCounts[[2]int{matchedPatterns[i], matchedPatterns[j]}]++
+				}
+			}
+		}
+	}
+
+	overlaps := []PatternOverlap{}
+	for i := range ca.patterns {
+		for j := i + 1; j < len(ca.patterns); j++ {
+			if count := pairCounts[[2]int{i, j}]; count > 0 {
+				overlaps = append(overlaps, PatternOverlap{
+					Patterns: []string{ca.patterns[i].Name, ca.patterns[j].Name},
+					Count:    count,
+				})
+			}
+		}
+	}
+
+	var durationSeconds, eventsPerMinute float64
+	if timedEntries > 0 {
+		durationSeconds = last.Sub(first).Seconds()
+		if durationSeconds > 0 {
+			eventsPerMinute = float64(timedEntries) / (durationSeconds / 60)
+		}
 	}
 
 	// Update pattern counts with final results
 	for i, count := range counts {
 		patternCounts[i].Count = count
+		patternCounts[i].Values = computeValueStats(values[i])
+		patternCounts[i].FirstTimestamp, patternCounts[i].LastTimestamp = spans[i].bounds()
+		if durationSeconds > 0 {
+			patternCounts[i].PerMinute = float64(count) / (durationSeconds / 60)
+		}
 		logrus.WithFields(logrus.Fields{
-			"pattern_name": patternCounts[i].Pattern,
-			"count":        count,
+			"pattern_name":    patternCounts[i].Pattern,
+			"count":           count,
+			"captured_values": len(values[i]),
 		}).Debug("Pattern count finalized")
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"total_entries":    len(entries),
-		"patterns_checked": len(ca.patterns),
+		"total_entries":       len(entries),
+		"patterns_checked":    len(ca.patterns),
+		"overlapping_entries": overlappingEntries,
 	}).Info("Count analysis completed")
 
 	result := &CountResult{
-		TotalEventsAnalyzed: len(entries),
+		TotalEventsAnalyzed: analyzedEntries,
+		DurationSeconds:     durationSeconds,
+		EventsPerMinute:     eventsPerMinute,
 		PatternCounts:       patternCounts,
+		OverlappingEntries:  overlappingEntries,
+		Overlaps:            overlaps,
+		Groups:              groups.result(),
+		Sessions:            sessions.result(ca.patterns),
+		Distinct:            distinct.result(ca.patterns),
+		Aggregate:           aggregate.result(ca.patterns),
+		Partial:             partial,
+	}
+	if timedEntries > 0 {
+		result.FirstTimestamp = &first
+		result.LastTimestamp = &last
 	}
 
 	return result
 }
 
 func (ca *CountAnalyzer) eventMatchesPattern(entry *parser.LogEntry, pattern EventPattern) bool {
+	_, matched := ca.matchPattern(entry, pattern)
+	return matched
+}
+
+// matchPattern reports whether the entry matches the pattern and returns the
+// text the pattern was matched against
+func (ca *CountAnalyzer) matchPattern(entry *parser.LogEntry, pattern EventPattern) (string, bool) {
 	logrus.WithFields(logrus.Fields{
 		"pattern_name":   pattern.Name,
 		"entry_message":  entry.Message,
@@ -140,23 +328,44 @@ func (ca *CountAnalyzer) eventMatchesPattern(entry *parser.LogEntry, pattern Eve
 
 				matched := pattern.Regex.MatchString(eventStr)
 				logrus.WithField("matched", matched).Debug("Structured event match result")
-				return matched
+				return eventStr, matched
 			} else {
 				logrus.Debug("Event field is not a string, failing match")
-				return false
+				return "", false
 			}
 		} else {
 			// Fall back to matching the raw message if no "event" field
 			logrus.Debug("No 'event' field found, falling back to raw message matching")
 			matched := pattern.Regex.MatchString(entry.Message)
 			logrus.WithField("matched", matched).Debug("Raw message match result")
-			return matched
+			return entry.Message, matched
 		}
 	} else {
 		// No structured data, match against raw message
 		logrus.Debug("No structured data, matching against raw message")
 		matched := pattern.Regex.MatchString(entry.Message)
 		logrus.WithField("matched", matched).Debug("Raw message match result")
-		return matched
+		return entry.Message, matched
+	}
+}
+
+// captureNumericValue extracts the first numeric capture group of the pattern
+// from the matched text, e.g. 123 from "load_time_ms=123" with `load_time_ms=(\d+)`
+func captureNumericValue(target string, pattern EventPattern) (float64, bool) {
+	if pattern.Regex.NumSubexp() == 0 {
+		return 0, false
 	}
+
+	matches := pattern.Regex.FindStringSubmatch(target)
+	for _, group := range matches[1:] {
+		if value, err := strconv.ParseFloat(group, 64); err == nil {
+			return value, true
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"pattern": pattern.Pattern,
+		"target":  target,
+	}).Debug("Pattern has capture groups but none is numeric")
+	return 0, false
 }
@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// CountAggregate sums up a numeric event data property over the entries
+// matching each pattern, such as the revenue of purchases
+type CountAggregate struct {
+	Property string              `json:"property"`
+	Patterns []PropertyAggregate `json:"patterns"`
+	// GroupBy and Groups break the aggregates down by the value of the
+	// group-by property when grouping is enabled
+	GroupBy string           `json:"group_by,omitempty"`
+	Groups  []AggregateGroup `json:"groups,omitempty"`
+}
+
+// PropertyAggregate is the aggregate of the property over the matches of one
+// pattern
+type PropertyAggregate struct {
+	Pattern string `json:"pattern"`
+	// Values counts the matching entries with a numeric property value
+	Values int     `json:"values"`
+	Sum    float64 `json:"sum"`
+	Avg    float64 `json:"avg"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	// MissingEntries counts matching entries without a numeric value
+	MissingEntries int `json:"missing_entries,omitempty"`
+}
+
+// AggregateGroup holds the aggregates of one group-by property value.
+// Patterns are in the order of the result's pattern counts.
+type AggregateGroup struct {
+	Value    string              `json:"value"`
+	Patterns []PropertyAggregate `json:"patterns"`
+	// Sum is the sum over all patterns
+	Sum float64 `json:"sum"`
+}
+
+// aggregateCounter accumulates the numeric property values of pattern
+// matches during count analysis
+type aggregateCounter struct {
+	property string
+	groupBy  string
+	patterns []PropertyAggregate
+	groups   map[string][]PropertyAggregate
+}
+
+func newAggregateCounter(property, groupBy string, patterns int) *aggregateCounter {
+	if property == "" {
+		return nil
+	}
+	return &aggregateCounter{
+		property: property,
+		groupBy:  groupBy,
+		patterns: make([]PropertyAggregate, patterns),
+		groups:   make(map[string][]PropertyAggregate),
+	}
+}
+
+// add records the property value of entry for the patterns it matched
+func (a *aggregateCounter) add(entry *parser.LogEntry, matchedPatterns []int) {
+	if a == nil || len(matchedPatterns) == 0 {
+		return
+	}
+
+	value, ok := numericProperty(entry, a.property)
+	var group []PropertyAggregate
+	if a.groupBy != "" {
+		if groupValue, grouped := propertyValue(entry, a.groupBy); grouped {
+			group = a.groups[groupValue]
+			if group == nil {
+				group = make([]PropertyAggregate, len(a.patterns))
+				a.groups[groupValue] = group
+			}
+		}
+	}
+
+	for _, patternIndex := range matchedPatterns {
+		a.patterns[patternIndex].add(value, ok)
+		if group != nil {
+			group[patternIndex].add(value, ok)
+		}
+	}
+}
+
+func (p *PropertyAggregate) add(value float64, ok bool) {
+	if !ok {
+		p.MissingEntries++
+		return
+	}
+	if p.Values == 0 || value < p.Min {
+		p.Min = value
+	}
+	if p.Values == 0 || value > p.Max {
+		p.Max = value
+	}
+	p.Values++
+	p.Sum += value
+}
+
+// result returns the aggregates of each pattern, with the groups ordered by
+// sum, highest first
+func (a *aggregateCounter) result(patterns []EventPattern) *CountAggregate {
+	if a == nil {
+		return nil
+	}
+
+	finish := func(aggregates []PropertyAggregate) float64 {
+		sum := 0.0
+		for i := range aggregates {
+			aggregates[i].Pattern = patterns[i].Name
+			if aggregates[i].Values > 0 {
+				aggregates[i].Avg = aggregates[i].Sum / float64(aggregates[i].Values)
+			}
+			sum += aggregates[i].Sum
+		}
+		return sum
+	}
+
+	finish(a.patterns)
+	result := &CountAggregate{
+		Property: a.property,
+		Patterns: a.patterns,
+		GroupBy:  a.groupBy,
+	}
+	for value, aggregates := range a.groups {
+		result.Groups = append(result.Groups, AggregateGroup{Value: value, Patterns: aggregates, Sum: finish(aggregates)})
+	}
+	sort.Slice(result.Groups, func(i, j int) bool {
+		if result.Groups[i].Sum != result.Groups[j].Sum {
+			return result.Groups[i].Sum > result.Groups[j].Sum
+		}
+		return result.Groups[i].Value < result.Groups[j].Value
+	})
+	return result
+}
+
+// numericProperty returns the event data property key of entry as a number,
+// or false if the entry does not have it or it is not numeric
+func numericProperty(entry *parser.LogEntry, key string) (float64, bool) {
+	var number float64
+	switch v := entry.EventData[key].(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	case int64:
+		number = float64(v)
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false
+		}
+		number = parsed
+	default:
+		return 0, false
+	}
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, false
+	}
+	return number, true
+}
@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestCountAnalyzer_Aggregate(t *testing.T) {
+	analyzer, err := NewCountAnalyzerWithOptions([]string{"purchase", "refund"}, CountOptions{Aggregate: "amount"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzerWithOptions() unexpected error: %v", err)
+	}
+
+	entries := []*parser.LogEntry{
+		{EventData: map[string]interface{}{"event": "purchase", "amount": 20.0}},
+		{EventData: map[string]interface{}{"event": "purchase", "amount": " 5.5 "}},
+		{EventData: map[string]interface{}{"event": "purchase", "amount": 10}},
+		{EventData: map[string]interface{}{"event": "purchase", "amount": "free"}},
+		{EventData: map[string]interface{}{"event": "purchase"}},
+		{EventData: map[string]interface{}{"event": "view", "amount": 99.0}},
+	}
+
+	result := analyzer.AnalyzeCount(entries)
+	if result.Aggregate == nil || result.Aggregate.Property != "amount" || len(result.Aggregate.Patterns) != 2 {
+		t.Fatalf("Expected the amount aggregate of 2 patterns, got %+v", result.Aggregate)
+	}
+
+	want := PropertyAggregate{Pattern: "purchase", Values: 3, Sum: 35.5, Avg: 35.5 / 3, Min: 5.5, Max: 20, MissingEntries: 2}
+	if result.Aggregate.Patterns[0] != want {
+		t.Errorf("Patterns[0] = %+v, want %+v", result.Aggregate.Patterns[0], want)
+	}
+	if refund := result.Aggregate.Patterns[1]; refund != (PropertyAggregate{Pattern: "refund"}) {
+		t.Errorf("Expected an empty refund aggregate, got %+v", refund)
+	}
+	if result.Aggregate.GroupBy != "" || result.Aggregate.Groups != nil {
+		t.Errorf("Expected no groups without group by, got %+v", result.Aggregate.Groups)
+	}
+}
+
+func TestCountAnalyzer_AggregateByGroup(t *testing.T) {
+	analyzer, err := NewCountAnalyzerWithOptions([]string{"purchase", "refund"}, CountOptions{Aggregate: "amount", GroupBy: "user_id"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzerWithOptions() unexpected error: %v", err)
+	}
+
+	entries := []*parser.LogEntry{
+		{EventData: map[string]interface{}{"event": "purchase", "user_id": "bob", "amount": 10.0}},
+		{EventData: map[string]interface{}{"event": "purchase", "user_id": "alice", "amount": 30.0}},
+		{EventData: map[string]interface{}{"event": "refund", "user_id": "alice", "amount": 5.0}},
+		{EventData: map[string]interface{}{"event": "purchase", "amount": 100.0}},
+	}
+
+	result := analyzer.AnalyzeCount(entries)
+	groups := result.Aggregate.Groups
+	if result.Aggregate.GroupBy != "user_id" || len(groups) != 2 {
+		t.Fatalf("Expected 2 groups by user_id, got %+v", result.Aggregate)
+	}
+	if groups[0].Value != "alice" || groups[0].Sum != 35 || groups[0].Patterns[0].Sum != 30 || groups[0].Patterns[1].Sum != 5 {
+		t.Errorf("Expected alice first with a sum of 35, got %+v", groups[0])
+	}
+	if groups[1].Value != "bob" || groups[1].Sum != 10 || groups[1].Patterns[1].Pattern != "refund" {
+		t.Errorf("Expected bob with a sum of 10, got %+v", groups[1])
+	}
+	if result.Aggregate.Patterns[0].Sum != 140 {
+		t.Errorf("Expected the ungrouped purchase in the pattern sum, got %+v", result.Aggregate.Patterns[0])
+	}
+}
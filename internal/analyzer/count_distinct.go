@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// CountDistinct reports how many unique values of an event data property the
+// entries matching each pattern have, such as the users that purchased
+type CountDistinct struct {
+	Property string                 `json:"property"`
+	Patterns []DistinctPatternCount `json:"patterns"`
+}
+
+// DistinctPatternCount is the distinct values of one pattern
+type DistinctPatternCount struct {
+	Pattern string `json:"pattern"`
+	Values  int    `json:"values"`
+	// MissingEntries counts matching entries without the property
+	MissingEntries int `json:"missing_entries,omitempty"`
+	// Top lists the most frequent values, most first
+	Top []ValueCount `json:"top,omitempty"`
+}
+
+// ValueCount is the number of matching entries with a property value
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// distinctCounter accumulates the property values of pattern matches during
+// count analysis
+type distinctCounter struct {
+	property string
+	top      int
+	values   []map[string]int
+	missing  []int
+}
+
+func newDistinctCounter(property string, top, patterns int) *distinctCounter {
+	if property == "" {
+		return nil
+	}
+	values := make([]map[string]int, patterns)
+	for i := range values {
+		values[i] = make(map[string]int)
+	}
+	return &distinctCounter{
+		property: property,
+		top:      top,
+		values:   values,
+		missing:  make([]int, patterns),
+	}
+}
+
+// add records the property value of entry for the patterns it matched
+func (d *distinctCounter) add(entry *parser.LogEntry, matchedPatterns []int) {
+	if d == nil || len(matchedPatterns) == 0 {
+		return
+	}
+
+	value, ok := propertyValue(entry, d.property)
+	for _, patternIndex := range matchedPatterns {
+		if ok {
+			d.values[patternIndex][value]++
+		} else {
+			d.missing[patternIndex]++
+		}
+	}
+}
+
+// result returns the distinct values of each pattern with the top values
+// ordered by matches, most first
+func (d *distinctCounter) result(patterns []EventPattern) *CountDistinct {
+	if d == nil {
+		return nil
+	}
+
+	counts := make([]DistinctPatternCount, len(patterns))
+	for i, pattern := range patterns {
+		counts[i] = DistinctPatternCount{
+			Pattern:        pattern.Name,
+			Values:         len(d.values[i]),
+			MissingEntries: d.missing[i],
+		}
+		if d.top <= 0 {
+			continue
+		}
+
+		top := make([]ValueCount, 0, len(d.values[i]))
+		for value, count := range d.values[i] {
+			top = append(top, ValueCount{Value: value, Count: count})
+		}
+		sort.Slice(top, func(a, b int) bool {
+			if top[a].Count != top[b].Count {
+				return top[a].Count > top[b].Count
+			}
+			return top[a].Value < top[b].Value
+		})
+		if len(top) > d.top {
+			top = top[:d.top]
+		}
+		counts[i].Top = top
+	}
+
+	return &CountDistinct{
+		Property: d.property,
+		Patterns: counts,
+	}
+}
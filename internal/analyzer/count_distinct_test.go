@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestCountAnalyzer_Distinct(t *testing.T) {
+	analyzer, err := NewCountAnalyzerWithOptions([]string{"view", "purchase"}, CountOptions{Distinct: "user_id", DistinctTop: 2})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzerWithOptions() unexpected error: %v", err)
+	}
+
+	entries := []*parser.LogEntry{
+		{EventData: map[string]interface{}{"event": "view", "user_id": "bob"}},
+		{EventData: map[string]interface{}{"event": "view", "user_id": "alice"}},
+		{EventData: map[string]interface{}{"event": "view", "user_id": "alice"}},
+		{EventData: map[string]interface{}{"event": "view", "user_id": 42.0}},
+		{EventData: map[string]interface{}{"event": "purchase"}},
+		{EventData: map[string]interface{}{"event": "logout", "user_id": "carol"}},
+	}
+
+	result := analyzer.AnalyzeCount(entries)
+	if result.Distinct == nil || result.Distinct.Property != "user_id" || len(result.Distinct.Patterns) != 2 {
+		t.Fatalf("Expected distinct user_id of 2 patterns, got %+v", result.Distinct)
+	}
+
+	view := result.Distinct.Patterns[0]
+	if view.Pattern != "view" || view.Values != 3 || view.MissingEntries != 0 {
+		t.Errorf("Expected 3 distinct values of view, got %+v", view)
+	}
+	if len(view.Top) != 2 || view.Top[0] != (ValueCount{Value: "alice", Count: 2}) || view.Top[1] != (ValueCount{Value: "42", Count: 1}) {
+		t.Errorf("Expected the top 2 values ordered by count and value, got %+v", view.Top)
+	}
+
+	purchase := result.Distinct.Patterns[1]
+	if purchase.Values != 0 || purchase.MissingEntries != 1 || len(purchase.Top) != 0 {
+		t.Errorf("Expected a purchase without user_id, got %+v", purchase)
+	}
+}
+
+func TestCountAnalyzer_DistinctWithoutTop(t *testing.T) {
+	analyzer, err := NewCountAnalyzerWithOptions([]string{"view"}, CountOptions{Distinct: "user_id"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzerWithOptions() unexpected error: %v", err)
+	}
+
+	result := analyzer.AnalyzeCount([]*parser.LogEntry{{EventData: map[string]interface{}{"event": "view", "user_id": "bob"}}})
+	if result.Distinct.Patterns[0].Values != 1 || result.Distinct.Patterns[0].Top != nil {
+		t.Errorf("Expected one value without top values, got %+v", result.Distinct.Patterns[0])
+	}
+}
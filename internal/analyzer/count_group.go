@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// CountGroups breaks the pattern counts down by the value of an event data
+// property, such as user_id or screen
+type CountGroups struct {
+	GroupBy string       `json:"group_by"`
+	Groups  []GroupCount `json:"groups"`
+	// UngroupedMatches counts matching entries without the property
+	UngroupedMatches int `json:"ungrouped_matches,omitempty"`
+}
+
+// GroupCount holds the matches of one property value. Counts are in the order
+// of the result's pattern counts.
+type GroupCount struct {
+	Value  string `json:"value"`
+	Counts []int  `json:"counts"`
+	Total  int    `json:"total"`
+}
+
+// groupCounter accumulates per-group pattern matches during count analysis
+type groupCounter struct {
+	groupBy   string
+	patterns  int
+	counts    map[string][]int
+	ungrouped int
+}
+
+func newGroupCounter(groupBy string, patterns int) *groupCounter {
+	if groupBy == "" {
+		return nil
+	}
+	return &groupCounter{
+		groupBy:  groupBy,
+		patterns: patterns,
+		counts:   make(map[string][]int),
+	}
+}
+
+// add records the patterns matched by entry under its group
+func (g *groupCounter) add(entry *parser.LogEntry, matchedPatterns []int) {
+	if g == nil || len(matchedPatterns) == 0 {
+		return
+	}
+
+	value, ok := propertyValue(entry, g.groupBy)
+	if !ok {
+		g.ungrouped++
+		return
+	}
+
+	counts, exists := g.counts[value]
+	if !exists {
+		counts = make([]int, g.patterns)
+		g.counts[value] = counts
+	}
+	for _, patternIndex := range matchedPatterns {
+		counts[patternIndex]++
+	}
+}
+
+// result returns the groups ordered by total matches, most first
+func (g *groupCounter) result() *CountGroups {
+	if g == nil {
+		return nil
+	}
+
+	groups := make([]GroupCount, 0, len(g.counts))
+	for value, counts := range g.counts {
+		total := 0
+		for _, count := range counts {
+			total += count
+		}
+		groups = append(groups, GroupCount{Value: value, Counts: counts, Total: total})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Total != groups[j].Total {
+			return groups[i].Total > groups[j].Total
+		}
+		return groups[i].Value < groups[j].Value
+	})
+
+	return &CountGroups{
+		GroupBy:          g.groupBy,
+		Groups:           groups,
+		UngroupedMatches: g.ungrouped,
+	}
+}
+
+// propertyValue returns the event data property key of entry as a string,
+// or false if the entry does not have it
+func propertyValue(entry *parser.LogEntry, key string) (string, bool) {
+	value, ok := entry.EventData[key]
+	if !ok || value == nil {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
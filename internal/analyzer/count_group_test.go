@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestCountAnalyzer_GroupBy(t *testing.T) {
+	analyzer, err := NewCountAnalyzerWithOptions([]string{"view", "purchase"}, CountOptions{GroupBy: "user_id"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzerWithOptions() unexpected error: %v", err)
+	}
+
+	entries := []*parser.LogEntry{
+		{EventData: map[string]interface{}{"event": "view", "user_id": "bob"}},
+		{EventData: map[string]interface{}{"event": "view", "user_id": "alice"}},
+		{EventData: map[string]interface{}{"event": "purchase", "user_id": "alice"}},
+		{EventData: map[string]interface{}{"event": "view", "user_id": 42.0}},
+		{EventData: map[string]interface{}{"event": "view"}},
+		{EventData: map[string]interface{}{"event": "logout", "user_id": "carol"}},
+	}
+
+	result := analyzer.AnalyzeCount(entries)
+	if result.Groups == nil {
+		t.Fatal("Expected groups in result")
+	}
+
+	groups := result.Groups
+	if groups.GroupBy != "user_id" || groups.UngroupedMatches != 1 {
+		t.Errorf("Unexpected groups summary %+v", groups)
+	}
+
+	expected := []GroupCount{
+		{Value: "alice", Counts: []int{1, 1}, Total: 2},
+		{Value: "42", Counts: []int{1, 0}, Total: 1},
+		{Value: "bob", Counts: []int{1, 0}, Total: 1},
+	}
+	if len(groups.Groups) != len(expected) {
+		t.Fatalf("Expected %d groups, got %+v", len(expected), groups.Groups)
+	}
+	for i, want := range expected {
+		got := groups.Groups[i]
+		if got.Value != want.Value || got.Total != want.Total || got.Counts[0] != want.Counts[0] || got.Counts[1] != want.Counts[1] {
+			t.Errorf("Group %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestCountAnalyzer_NoGroupBy(t *testing.T) {
+	analyzer, err := NewCountAnalyzer([]string{"view"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzer() unexpected error: %v", err)
+	}
+
+	result := analyzer.AnalyzeCount([]*parser.LogEntry{{Message: "view"}})
+	if result.Groups != nil {
+		t.Errorf("Expected no groups without group_by, got %+v", result.Groups)
+	}
+}
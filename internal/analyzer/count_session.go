@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// CountSessions reports for each pattern how many sessions contained a match,
+// which answers adoption questions raw match counts cannot
+type CountSessions struct {
+	SessionKey string `json:"session_key"`
+	// IdleTimeoutSeconds is the gap between events that starts a new session, 0 if disabled
+	IdleTimeoutSeconds float64 `json:"idle_timeout_seconds"`
+	SessionCount       int     `json:"session_count"`
+	// UnkeyedEntries counts entries without the session key property
+	UnkeyedEntries int                   `json:"unkeyed_entries,omitempty"`
+	Patterns       []SessionPatternCount `json:"patterns"`
+}
+
+// SessionPatternCount is the session breakdown of one pattern
+type SessionPatternCount struct {
+	Pattern string `json:"pattern"`
+	// Sessions counts sessions with at least one match
+	Sessions   int     `json:"sessions"`
+	Percentage float64 `json:"percentage"`
+	// AvgPerSession averages the matches over all sessions
+	AvgPerSession float64 `json:"avg_per_session"`
+	// AvgPerMatchingSession averages the matches over sessions with a match
+	AvgPerMatchingSession float64 `json:"avg_per_matching_session"`
+}
+
+// sessionCounter accumulates per-session pattern matches during count analysis
+type sessionCounter struct {
+	analyzer *SessionAnalyzer
+	tracker  *sessionTracker
+	matches  []map[*session]int
+}
+
+func newSessionCounter(sessions *SessionAnalyzer, patterns int) *sessionCounter {
+	if sessions == nil {
+		return nil
+	}
+
+	matches := make([]map[*session]int, patterns)
+	for i := range matches {
+		matches[i] = make(map[*session]int)
+	}
+	return &sessionCounter{
+		analyzer: sessions,
+		tracker:  sessions.newTracker(),
+		matches:  matches,
+	}
+}
+
+// add assigns entry to its session and records the patterns it matched
+func (c *sessionCounter) add(entryIndex int, entry *parser.LogEntry, matchedPatterns []int) {
+	if c == nil {
+		return
+	}
+
+	s := c.tracker.add(entryIndex, entry)
+	if s == nil {
+		return
+	}
+	for _, patternIndex := range matchedPatterns {
+		c.matches[patternIndex][s]++
+	}
+}
+
+func (c *sessionCounter) result(patterns []EventPattern) *CountSessions {
+	if c == nil {
+		return nil
+	}
+
+	sessionCount := len(c.tracker.sessions())
+	result := &CountSessions{
+		SessionKey:         c.analyzer.sessionKey,
+		IdleTimeoutSeconds: c.analyzer.idleTimeout.Seconds(),
+		SessionCount:       sessionCount,
+		UnkeyedEntries:     c.tracker.unkeyed,
+		Patterns:           make([]SessionPatternCount, len(patterns)),
+	}
+
+	for i, pattern := range patterns {
+		total := 0
+		for _, count := range c.matches[i] {
+			total += count
+		}
+
+		patternCount := SessionPatternCount{
+			Pattern:  pattern.Name,
+			Sessions: len(c.matches[i]),
+		}
+		if sessionCount > 0 {
+			patternCount.Percentage = float64(patternCount.Sessions) / float64(sessionCount) * 100.0
+			patternCount.AvgPerSession = float64(total) / float64(sessionCount)
+		}
+		if patternCount.Sessions > 0 {
+			patternCount.AvgPerMatchingSession = float64(total) / float64(patternCount.Sessions)
+		}
+		result.Patterns[i] = patternCount
+	}
+	return result
+}
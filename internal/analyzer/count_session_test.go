@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestCountAnalyzer_PerSession(t *testing.T) {
+	sessions, err := NewSessionAnalyzer("session_id", time.Minute)
+	if err != nil {
+		t.Fatalf("NewSessionAnalyzer() unexpected error: %v", err)
+	}
+	analyzer, err := NewCountAnalyzerWithOptions([]string{"share", "open"}, CountOptions{Sessions: sessions})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzerWithOptions() unexpected error: %v", err)
+	}
+
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	event := func(offset time.Duration, name, sessionID string) *parser.LogEntry {
+		data := map[string]interface{}{"event": name}
+		if sessionID != "" {
+			data["session_id"] = sessionID
+		}
+		return &parser.LogEntry{Timestamp: base.Add(offset), EventData: data}
+	}
+
+	entries := []*parser.LogEntry{
+		event(0, "open", "a"),
+		event(10*time.Second, "share", "a"),
+		event(20*time.Second, "share", "a"),
+		event(30*time.Second, "open", "b"),
+		event(5*time.Minute, "open", "a"), // idle timeout, new session of a
+		event(5*time.Minute, "share", ""),
+	}
+
+	result := analyzer.AnalyzeCount(entries)
+	if result.Sessions == nil {
+		t.Fatal("Expected sessions in result")
+	}
+
+	summary := result.Sessions
+	if summary.SessionKey != "session_id" || summary.SessionCount != 3 || summary.UnkeyedEntries != 1 || summary.IdleTimeoutSeconds != 60 {
+		t.Errorf("Unexpected sessions summary %+v", summary)
+	}
+
+	share := summary.Patterns[0]
+	if share.Pattern != "share" || share.Sessions != 1 || share.AvgPerMatchingSession != 2 {
+		t.Errorf("Unexpected share breakdown %+v", share)
+	}
+	if math.Abs(share.Percentage-100.0/3) > 0.01 || math.Abs(share.AvgPerSession-2.0/3) > 0.01 {
+		t.Errorf("Unexpected share averages %+v", share)
+	}
+
+	open := summary.Patterns[1]
+	if open.Sessions != 3 || open.Percentage != 100 || open.AvgPerSession != 1 {
+		t.Errorf("Unexpected open breakdown %+v", open)
+	}
+
+	// The raw counts still include entries without the session key
+	if result.PatternCounts[0].Count != 3 {
+		t.Errorf("Expected 3 share matches, got %d", result.PatternCounts[0].Count)
+	}
+}
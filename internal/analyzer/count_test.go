@@ -1,9 +1,11 @@
 package analyzer
 
 import (
-	"github.com/parfenovvs/loglion/internal/parser"
+	"context"
 	"testing"
 	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
 )
 
 func TestNewCountAnalyzer(t *testing.T) {
@@ -556,3 +558,182 @@ func TestCountAnalyzer_ComplexRegexPatterns(t *testing.T) {
 		})
 	}
 }
+
+func TestCountAnalyzer_NumericCaptureStats(t *testing.T) {
+	analyzer, err := NewCountAnalyzer([]string{`load_time_ms=(\d+)`, `screen=(\w+)`, "login"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzer() unexpected error: %v", err)
+	}
+
+	entries := []*parser.LogEntry{
+		{Message: "app_start load_time_ms=100"},
+		{Message: "app_start load_time_ms=300"},
+		{Message: "screen=home"},
+		{Message: "login"},
+		{EventData: map[string]interface{}{"event": "resume load_time_ms=200"}},
+	}
+
+	result := analyzer.AnalyzeCount(entries)
+
+	loadTime := result.PatternCounts[0]
+	if loadTime.Count != 3 {
+		t.Errorf("load_time count = %d, want 3", loadTime.Count)
+	}
+	if loadTime.Values == nil {
+		t.Fatal("Expected captured value stats for numeric pattern")
+	}
+	if loadTime.Values.Samples != 3 || loadTime.Values.Min != 100 || loadTime.Values.Max != 300 || loadTime.Values.Avg != 200 {
+		t.Errorf("Unexpected value stats: %+v", loadTime.Values)
+	}
+
+	if result.PatternCounts[1].Values != nil {
+		t.Errorf("Non-numeric capture should not produce value stats, got %+v", result.PatternCounts[1].Values)
+	}
+	if result.PatternCounts[2].Values != nil {
+		t.Errorf("Pattern without capture groups should not produce value stats")
+	}
+}
+
+func TestCountAnalyzer_Overlaps(t *testing.T) {
+	analyzer, err := NewCountAnalyzer([]string{"login", "login_failed", "logout"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzer() unexpected error: %v", err)
+	}
+
+	entries := []*parser.LogEntry{
+		{Message: "login"},
+		{Message: "login_failed"},
+		{Message: "login_failed"},
+		{Message: "logout"},
+	}
+
+	result := analyzer.AnalyzeCount(entries)
+
+	if result.OverlappingEntries != 2 {
+		t.Errorf("OverlappingEntries = %d, want 2", result.OverlappingEntries)
+	}
+	if len(result.Overlaps) != 1 {
+		t.Fatalf("Expected 1 overlapping pair, got %d", len(result.Overlaps))
+	}
+
+	overlap := result.Overlaps[0]
+	if overlap.Patterns[0] != "login" || overlap.Patterns[1] != "login_failed" || overlap.Count != 2 {
+		t.Errorf("Unexpected overlap: %+v", overlap)
+	}
+}
+
+func TestCountAnalyzer_NoOverlaps(t *testing.T) {
+	analyzer, err := NewCountAnalyzer([]string{"^login$", "^logout$"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzer() unexpected error: %v", err)
+	}
+
+	result := analyzer.AnalyzeCount([]*parser.LogEntry{{Message: "login"}, {Message: "logout"}})
+
+	if result.OverlappingEntries != 0 || len(result.Overlaps) != 0 {
+		t.Errorf("Expected no overlaps, got %d entries and %+v", result.OverlappingEntries, result.Overlaps)
+	}
+}
+
+func TestCountAnalyzer_IgnoreCase(t *testing.T) {
+	entries := []*parser.LogEntry{
+		{Message: "Login"},
+		{Message: "LOGIN"},
+		{Message: "login"},
+	}
+
+	analyzer, err := NewCountAnalyzerWithOptions([]string{"^login$"}, CountOptions{IgnoreCase: true})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzerWithOptions() unexpected error: %v", err)
+	}
+	result := analyzer.AnalyzeCount(entries)
+	if result.PatternCounts[0].Count != 3 {
+		t.Errorf("Expected 3 matches ignoring case, got %d", result.PatternCounts[0].Count)
+	}
+	if result.PatternCounts[0].Pattern != "^login$" {
+		t.Errorf("Expected pattern to be reported as given, got %q", result.PatternCounts[0].Pattern)
+	}
+
+	analyzer, err = NewCountAnalyzer([]string{"^login$"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzer() unexpected error: %v", err)
+	}
+	if count := analyzer.AnalyzeCount(entries).PatternCounts[0].Count; count != 1 {
+		t.Errorf("Expected 1 case-sensitive match, got %d", count)
+	}
+}
+
+func TestCountAnalyzer_Labels(t *testing.T) {
+	analyzer, err := NewCountAnalyzerWithOptions([]string{"^login$", "^logout$"}, CountOptions{Labels: []string{"Sign in", ""}})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzerWithOptions() unexpected error: %v", err)
+	}
+	result := analyzer.AnalyzeCount([]*parser.LogEntry{{Message: "login"}, {Message: "logout"}})
+	if result.PatternCounts[0].Pattern != "Sign in" || result.PatternCounts[0].Count != 1 {
+		t.Errorf("Expected the labeled pattern to be reported as 'Sign in', got %+v", result.PatternCounts[0])
+	}
+	if result.PatternCounts[1].Pattern != "^logout$" {
+		t.Errorf("Expected a pattern without label to be reported as given, got %q", result.PatternCounts[1].Pattern)
+	}
+}
+
+func TestCountAnalyzer_Rates(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	entries := []*parser.LogEntry{
+		{Timestamp: start.Add(30 * time.Second), Message: "login"},
+		{Timestamp: start, Message: "request"},
+		{Message: "login"},
+		{Timestamp: start.Add(2 * time.Minute), Message: "request"},
+	}
+
+	analyzer, err := NewCountAnalyzer([]string{"login", "request"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzer() unexpected error: %v", err)
+	}
+	result := analyzer.AnalyzeCount(entries)
+
+	if result.FirstTimestamp == nil || !result.FirstTimestamp.Equal(start) || !result.LastTimestamp.Equal(start.Add(2*time.Minute)) {
+		t.Fatalf("Expected the earliest and latest timestamps, got %v and %v", result.FirstTimestamp, result.LastTimestamp)
+	}
+	if result.DurationSeconds != 120 || result.EventsPerMinute != 1.5 {
+		t.Errorf("Expected 120s and 1.5 timed events per minute, got %v and %v", result.DurationSeconds, result.EventsPerMinute)
+	}
+	if result.PatternCounts[0].PerMinute != 1 || result.PatternCounts[1].PerMinute != 1 {
+		t.Errorf("Expected 1 match per minute of each pattern, got %+v", result.PatternCounts)
+	}
+	if request := result.PatternCounts[1]; request.FirstTimestamp == nil || !request.FirstTimestamp.Equal(start) || !request.LastTimestamp.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("Expected request to be matched from the start to 2m, got %v and %v", request.FirstTimestamp, request.LastTimestamp)
+	}
+
+	untimed := analyzer.AnalyzeCount([]*parser.LogEntry{{Message: "login"}})
+	if untimed.FirstTimestamp != nil || untimed.DurationSeconds != 0 || untimed.PatternCounts[0].PerMinute != 0 || untimed.PatternCounts[0].FirstTimestamp != nil {
+		t.Errorf("Expected no rates without timestamps, got %+v", untimed)
+	}
+}
+
+func TestAnalyzeCountContext_Cancelled(t *testing.T) {
+	analyzer, err := NewCountAnalyzer([]string{"login"})
+	if err != nil {
+		t.Fatalf("NewCountAnalyzer() unexpected error: %v", err)
+	}
+
+	entries := []*parser.LogEntry{{Message: "login"}, {Message: "login"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := analyzer.AnalyzeCountContext(ctx, entries)
+	if !result.Partial {
+		t.Error("Expected partial result after cancellation")
+	}
+	if result.TotalEventsAnalyzed != 0 || result.PatternCounts[0].Count != 0 {
+		t.Errorf("Expected nothing analyzed, got %d events and %d matches",
+			result.TotalEventsAnalyzed, result.PatternCounts[0].Count)
+	}
+
+	result = analyzer.AnalyzeCountContext(context.Background(), entries)
+	if result.Partial || result.PatternCounts[0].Count != 2 {
+		t.Errorf("Expected complete result with 2 matches, got partial=%v count=%d",
+			result.Partial, result.PatternCounts[0].Count)
+	}
+}
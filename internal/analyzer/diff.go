@@ -0,0 +1,114 @@
+package analyzer
+
+// DiffReport compares the funnels analyzed in a baseline and a candidate log,
+// e.g. before and after a release. Deltas are candidate minus baseline.
+type DiffReport struct {
+	Baseline  string       `json:"baseline"`
+	Candidate string       `json:"candidate"`
+	Funnels   []FunnelDiff `json:"funnels"`
+}
+
+// FunnelDiff is the change of one funnel between the baseline and the candidate
+type FunnelDiff struct {
+	FunnelName         string  `json:"funnel_name"`
+	BaselineCompleted  bool    `json:"baseline_completed"`
+	CandidateCompleted bool    `json:"candidate_completed"`
+	BaselineRate       float64 `json:"baseline_conversion_rate"`
+	CandidateRate      float64 `json:"candidate_conversion_rate"`
+	// RateDelta is the change of the conversion rate in percentage points
+	RateDelta float64       `json:"conversion_rate_delta"`
+	Steps     []StepDiff    `json:"steps"`
+	DropOffs  []DropOffDiff `json:"drop_offs"`
+}
+
+// StepDiff is the change of the event count and conversion of one step.
+// Conversion is the percentage of the first step, as in StepResult.
+type StepDiff struct {
+	Name                string  `json:"name"`
+	BaselineCount       int     `json:"baseline_count"`
+	CandidateCount      int     `json:"candidate_count"`
+	CountDelta          int     `json:"count_delta"`
+	BaselinePercentage  float64 `json:"baseline_percentage"`
+	CandidatePercentage float64 `json:"candidate_percentage"`
+	PercentageDelta     float64 `json:"percentage_delta"`
+}
+
+// DropOffDiff is the change of the drop-off rate between two consecutive steps
+type DropOffDiff struct {
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	BaselineRate  float64 `json:"baseline_rate"`
+	CandidateRate float64 `json:"candidate_rate"`
+	RateDelta     float64 `json:"rate_delta"`
+}
+
+// NewDiffReport compares the funnel results of the baseline and the candidate
+// log. Both must have been analyzed with the same funnels in the same order.
+func NewDiffReport(baseline, candidate string, baselineResults, candidateResults []*FunnelResult) *DiffReport {
+	report := &DiffReport{Baseline: baseline, Candidate: candidate, Funnels: []FunnelDiff{}}
+
+	for i, before := range baselineResults {
+		after := candidateResults[i]
+		funnel := FunnelDiff{
+			FunnelName:         before.FunnelName,
+			BaselineCompleted:  before.FunnelCompleted,
+			CandidateCompleted: after.FunnelCompleted,
+			BaselineRate:       before.ConversionRate(),
+			CandidateRate:      after.ConversionRate(),
+			Steps:              []StepDiff{},
+			DropOffs:           []DropOffDiff{},
+		}
+		funnel.RateDelta = funnel.CandidateRate - funnel.BaselineRate
+
+		// Results without events have no steps
+		for j := range max(len(before.Steps), len(after.Steps)) {
+			beforeStep, afterStep := stepAt(before.Steps, j), stepAt(after.Steps, j)
+			step := StepDiff{
+				Name:                beforeStep.Name,
+				BaselineCount:       beforeStep.EventCount,
+				CandidateCount:      afterStep.EventCount,
+				CountDelta:          afterStep.EventCount - beforeStep.EventCount,
+				BaselinePercentage:  beforeStep.Percentage,
+				CandidatePercentage: afterStep.Percentage,
+				PercentageDelta:     afterStep.Percentage - beforeStep.Percentage,
+			}
+			if step.Name == "" {
+				step.Name = afterStep.Name
+			}
+			funnel.Steps = append(funnel.Steps, step)
+		}
+
+		for j := 0; j+1 < len(funnel.Steps); j++ {
+			from, to := funnel.Steps[j], funnel.Steps[j+1]
+			dropOff := DropOffDiff{
+				From:          from.Name,
+				To:            to.Name,
+				BaselineRate:  dropOffRate(from.BaselineCount, to.BaselineCount),
+				CandidateRate: dropOffRate(from.CandidateCount, to.CandidateCount),
+			}
+			dropOff.RateDelta = dropOff.CandidateRate - dropOff.BaselineRate
+			funnel.DropOffs = append(funnel.DropOffs, dropOff)
+		}
+
+		report.Funnels = append(report.Funnels, funnel)
+	}
+
+	return report
+}
+
+// stepAt returns the step result at index, or an empty one past the end
+func stepAt(steps []StepResult, index int) StepResult {
+	if index < len(steps) {
+		return steps[index]
+	}
+	return StepResult{}
+}
+
+// dropOffRate is the percentage of from that did not reach to, 0 when no
+// event reached from
+func dropOffRate(from, to int) float64 {
+	if from == 0 {
+		return 0
+	}
+	return float64(from-to) / float64(from) * 100.0
+}
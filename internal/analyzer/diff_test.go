@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewDiffReport(t *testing.T) {
+	funnel := func(completed bool, counts ...int) *FunnelResult {
+		result := &FunnelResult{FunnelName: "Checkout", FunnelCompleted: completed}
+		for i, count := range counts {
+			result.Steps = append(result.Steps, StepResult{
+				Name:       []string{"View", "Cart", "Buy"}[i],
+				EventCount: count,
+				Percentage: float64(count) / float64(counts[0]) * 100,
+			})
+		}
+		return result
+	}
+
+	report := NewDiffReport("before.txt", "after.txt",
+		[]*FunnelResult{funnel(true, 10, 5, 2)},
+		[]*FunnelResult{funnel(false, 20, 5, 0)})
+
+	if report.Baseline != "before.txt" || report.Candidate != "after.txt" {
+		t.Errorf("Unexpected sources %q and %q", report.Baseline, report.Candidate)
+	}
+	if len(report.Funnels) != 1 {
+		t.Fatalf("Expected 1 funnel, got %d", len(report.Funnels))
+	}
+
+	diff := report.Funnels[0]
+	if diff.FunnelName != "Checkout" || !diff.BaselineCompleted || diff.CandidateCompleted {
+		t.Errorf("Unexpected funnel diff %+v", diff)
+	}
+	if diff.BaselineRate != 20 || diff.CandidateRate != 0 || diff.RateDelta != -20 {
+		t.Errorf("Expected conversion rate 20%% -> 0%% (-20pp), got %v -> %v (%v)", diff.BaselineRate, diff.CandidateRate, diff.RateDelta)
+	}
+
+	cart := StepDiff{
+		Name:                "Cart",
+		BaselineCount:       5,
+		CandidateCount:      5,
+		CountDelta:          0,
+		BaselinePercentage:  50,
+		CandidatePercentage: 25,
+		PercentageDelta:     -25,
+	}
+	if !reflect.DeepEqual(diff.Steps[1], cart) {
+		t.Errorf("Expected step diff %+v, got %+v", cart, diff.Steps[1])
+	}
+	if diff.Steps[0].CountDelta != 10 {
+		t.Errorf("Expected first step delta +10, got %d", diff.Steps[0].CountDelta)
+	}
+
+	expectedDropOffs := []DropOffDiff{
+		{From: "View", To: "Cart", BaselineRate: 50, CandidateRate: 75, RateDelta: 25},
+		{From: "Cart", To: "Buy", BaselineRate: 60, CandidateRate: 100, RateDelta: 40},
+	}
+	if !reflect.DeepEqual(diff.DropOffs, expectedDropOffs) {
+		t.Errorf("Expected drop-offs %+v, got %+v", expectedDropOffs, diff.DropOffs)
+	}
+}
+
+func TestNewDiffReportWithoutEvents(t *testing.T) {
+	// A log without events yields a result without steps
+	baseline := &FunnelResult{FunnelName: "Checkout", Steps: []StepResult{
+		{Name: "View", EventCount: 4, Percentage: 100},
+		{Name: "Buy", EventCount: 1, Percentage: 25},
+	}}
+	candidate := &FunnelResult{FunnelName: "Checkout", Steps: []StepResult{}}
+
+	diff := NewDiffReport("a", "b", []*FunnelResult{baseline}, []*FunnelResult{candidate}).Funnels[0]
+
+	if len(diff.Steps) != 2 || diff.Steps[1].Name != "Buy" || diff.Steps[1].CandidateCount != 0 || diff.Steps[1].CountDelta != -1 {
+		t.Errorf("Expected steps of the baseline with zero candidate counts, got %+v", diff.Steps)
+	}
+	if diff.RateDelta != -25 {
+		t.Errorf("Expected conversion rate delta -25, got %v", diff.RateDelta)
+	}
+}
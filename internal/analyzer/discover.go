@@ -0,0 +1,255 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// DiscoverAnalyzer mines the event sequences that occur, in order though not
+// necessarily one after another, in many sessions of a log, as candidate
+// funnels
+type DiscoverAnalyzer struct {
+	sessions *SessionAnalyzer
+	options  DiscoverOptions
+}
+
+// DiscoverOptions limits the sequences the discover analyzer reports
+type DiscoverOptions struct {
+	// MinSupport is the percentage of sessions a sequence must occur in
+	MinSupport float64
+	// MinLength and MaxLength bound the number of events of a sequence
+	MinLength int
+	MaxLength int
+	// Limit is the number of sequences to report, 0 for all of them
+	Limit int
+}
+
+// DiscoverResult lists frequent event sequences, most frequent first
+type DiscoverResult struct {
+	TotalEventsAnalyzed int    `json:"total_events_analyzed"`
+	SessionKey          string `json:"session_key,omitempty"`
+	// IdleTimeoutSeconds is the gap between events that starts a new session
+	IdleTimeoutSeconds float64 `json:"idle_timeout_seconds,omitempty"`
+	SessionCount       int     `json:"session_count"`
+	// UnkeyedEntries counts entries without the session key property
+	UnkeyedEntries int `json:"unkeyed_entries,omitempty"`
+	// MinSupport is the number of sessions a sequence had to occur in
+	MinSupport int             `json:"min_support"`
+	Sequences  []EventSequence `json:"sequences"`
+	// TotalSequences counts the frequent sequences before the limit
+	TotalSequences int `json:"total_sequences"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+// EventSequence is a sequence of events and the sessions it occurs in
+type EventSequence struct {
+	Events     []string `json:"events"`
+	Support    int      `json:"support"`
+	Percentage float64  `json:"percentage"`
+}
+
+// projection is the position in a session after which a prefix's sequence
+// continues
+type projection struct {
+	session  int
+	position int
+}
+
+// NewDiscoverAnalyzer creates an analyzer mining the event sequences of the
+// sessions split by sessions
+func NewDiscoverAnalyzer(sessions *SessionAnalyzer, options DiscoverOptions) (*DiscoverAnalyzer, error) {
+	logrus.WithFields(logrus.Fields{
+		"min_support": options.MinSupport,
+		"min_length":  options.MinLength,
+		"max_length":  options.MaxLength,
+		"limit":       options.Limit,
+	}).Debug("Creating new discover analyzer")
+
+	if options.MinSupport <= 0 || options.MinSupport > 100 {
+		return nil, fmt.Errorf("minimum support must be a percentage above 0 and up to 100, got %g", options.MinSupport)
+	}
+	if options.MinLength < 1 {
+		return nil, fmt.Errorf("minimum length must be at least 1, got %d", options.MinLength)
+	}
+	if options.MaxLength < options.MinLength {
+		return nil, fmt.Errorf("maximum length %d is less than the minimum length %d", options.MaxLength, options.MinLength)
+	}
+	if options.Limit < 0 {
+		return nil, fmt.Errorf("limit cannot be negative")
+	}
+	return &DiscoverAnalyzer{sessions: sessions, options: options}, nil
+}
+
+func (da *DiscoverAnalyzer) AnalyzeDiscover(entries []*parser.LogEntry) *DiscoverResult {
+	return da.AnalyzeDiscoverContext(context.Background(), entries)
+}
+
+// AnalyzeDiscoverContext is like AnalyzeDiscover but stops when ctx is
+// cancelled, returning the sequences of the entries analyzed so far marked
+// as partial
+func (da *DiscoverAnalyzer) AnalyzeDiscoverContext(ctx context.Context, entries []*parser.LogEntry) *DiscoverResult {
+	logrus.WithFields(logrus.Fields{
+		"entry_count": len(entries),
+		"session_key": da.sessions.sessionKey,
+	}).Info("Starting sequence discovery")
+
+	result := &DiscoverResult{
+		TotalEventsAnalyzed: len(entries),
+		SessionKey:          da.sessions.sessionKey,
+		IdleTimeoutSeconds:  da.sessions.idleTimeout.Seconds(),
+		Sequences:           []EventSequence{},
+	}
+
+	// Sessionize the events, keeping sessions in order of their start
+	tracker := da.sessions.newTracker()
+	sessionIndex := make(map[*session]int)
+	var sequences [][]string
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Sequence discovery cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+
+		s := tracker.add(entryIndex, entry)
+		if s == nil {
+			continue
+		}
+		index, exists := sessionIndex[s]
+		if !exists {
+			index = len(sequences)
+			sessionIndex[s] = index
+			sequences = append(sequences, nil)
+		}
+		sequences[index] = append(sequences[index], EventName(entry))
+	}
+	result.SessionCount = len(sequences)
+	result.UnkeyedEntries = tracker.unkeyed
+	if result.SessionCount == 0 {
+		return result
+	}
+
+	result.MinSupport = max(1, int(math.Ceil(da.options.MinSupport/100*float64(result.SessionCount))))
+
+	root := make([]projection, len(sequences))
+	for i := range sequences {
+		root[i] = projection{session: i, position: -1}
+	}
+	var found []EventSequence
+	da.prefixSpan(sequences, nil, root, result.MinSupport, &found)
+
+	found = closedSequences(found)
+	for i := range found {
+		found[i].Percentage = float64(found[i].Support) / float64(result.SessionCount) * 100.0
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Support != found[j].Support {
+			return found[i].Support > found[j].Support
+		}
+		if len(found[i].Events) != len(found[j].Events) {
+			return len(found[i].Events) > len(found[j].Events)
+		}
+		return strings.Join(found[i].Events, "\x00") < strings.Join(found[j].Events, "\x00")
+	})
+
+	result.TotalSequences = len(found)
+	if da.options.Limit > 0 && len(found) > da.options.Limit {
+		found = found[:da.options.Limit]
+	}
+	result.Sequences = found
+
+	logrus.WithFields(logrus.Fields{
+		"session_count":   result.SessionCount,
+		"min_support":     result.MinSupport,
+		"total_sequences": result.TotalSequences,
+		"partial":         result.Partial,
+	}).Info("Sequence discovery completed")
+
+	return result
+}
+
+// prefixSpan extends prefix, occurring in the sessions of projections, by
+// every event that follows it in at least minSupport sessions, recording the
+// extended sequences of at least the minimum length
+func (da *DiscoverAnalyzer) prefixSpan(sequences [][]string, prefix []string, projections []projection, minSupport int, found *[]EventSequence) {
+	if len(prefix) >= da.options.MaxLength {
+		return
+	}
+
+	// Count the sessions each event follows the prefix in
+	support := make(map[string]int)
+	for _, p := range projections {
+		seen := make(map[string]bool)
+		for _, event := range sequences[p.session][p.position+1:] {
+			if !seen[event] {
+				seen[event] = true
+				support[event]++
+			}
+		}
+	}
+
+	events := make([]string, 0, len(support))
+	for event, count := range support {
+		if count >= minSupport {
+			events = append(events, event)
+		}
+	}
+	sort.Strings(events)
+
+	for _, event := range events {
+		extended := append(append([]string(nil), prefix...), event)
+		if len(extended) >= da.options.MinLength {
+			*found = append(*found, EventSequence{Events: extended, Support: support[event]})
+		}
+
+		// Project each session onto the first occurrence of the event
+		var next []projection
+		for _, p := range projections {
+			for position := p.position + 1; position < len(sequences[p.session]); position++ {
+				if sequences[p.session][position] == event {
+					next = append(next, projection{session: p.session, position: position})
+					break
+				}
+			}
+		}
+		da.prefixSpan(sequences, extended, next, minSupport, found)
+	}
+}
+
+// closedSequences drops the sequences that a longer sequence with the same
+// support contains, which add nothing as a candidate funnel
+func closedSequences(sequences []EventSequence) []EventSequence {
+	var closed []EventSequence
+	for i, sequence := range sequences {
+		contained := false
+		for j, other := range sequences {
+			if i != j && other.Support == sequence.Support && len(other.Events) > len(sequence.Events) && isSubsequence(sequence.Events, other.Events) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			closed = append(closed, sequence)
+		}
+	}
+	return closed
+}
+
+// isSubsequence reports whether the events of sub occur in sequence in order
+func isSubsequence(sub, sequence []string) bool {
+	i := 0
+	for _, event := range sequence {
+		if i < len(sub) && sub[i] == event {
+			i++
+		}
+	}
+	return i == len(sub)
+}
@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestDiscoverAnalyzer_Sequences(t *testing.T) {
+	sessions, err := NewSessionAnalyzer("user_id", 0)
+	if err != nil {
+		t.Fatalf("NewSessionAnalyzer() unexpected error: %v", err)
+	}
+	analyzer, err := NewDiscoverAnalyzer(sessions, DiscoverOptions{MinSupport: 50, MinLength: 2, MaxLength: 5})
+	if err != nil {
+		t.Fatalf("NewDiscoverAnalyzer() unexpected error: %v", err)
+	}
+
+	var entries []*parser.LogEntry
+	for _, e := range [][2]string{
+		{"alice", "open"}, {"alice", "view"}, {"alice", "cart"}, {"alice", "purchase"},
+		{"bob", "open"}, {"bob", "view"}, {"bob", "search"}, {"bob", "cart"},
+		{"carol", "view"}, {"carol", "purchase"},
+		{"dave", "open"}, {"dave", "search"},
+	} {
+		entries = append(entries, &parser.LogEntry{EventData: map[string]interface{}{"user_id": e[0], "event": e[1]}})
+	}
+	entries = append(entries, &parser.LogEntry{EventData: map[string]interface{}{"event": "open"}})
+
+	result := analyzer.AnalyzeDiscover(entries)
+	if result.SessionCount != 4 || result.UnkeyedEntries != 1 || result.MinSupport != 2 {
+		t.Fatalf("Expected 4 sessions, 1 unkeyed entry and a minimum support of 2, got %+v", result)
+	}
+
+	// open → view → cart contains open → view and open → cart with the same
+	// support, which are left out
+	want := []EventSequence{
+		{Events: []string{"open", "view", "cart"}, Support: 2, Percentage: 50},
+		{Events: []string{"open", "search"}, Support: 2, Percentage: 50},
+		{Events: []string{"view", "purchase"}, Support: 2, Percentage: 50},
+	}
+	if !reflect.DeepEqual(result.Sequences, want) {
+		t.Errorf("Sequences = %+v, want %+v", result.Sequences, want)
+	}
+	if result.TotalSequences != 3 {
+		t.Errorf("Expected 3 sequences in total, got %d", result.TotalSequences)
+	}
+}
+
+func TestDiscoverAnalyzer_Limit(t *testing.T) {
+	sessions, _ := NewSessionAnalyzer("", 0)
+	analyzer, err := NewDiscoverAnalyzer(sessions, DiscoverOptions{MinSupport: 100, MinLength: 1, MaxLength: 2, Limit: 1})
+	if err != nil {
+		t.Fatalf("NewDiscoverAnalyzer() unexpected error: %v", err)
+	}
+
+	entries := []*parser.LogEntry{
+		{EventData: map[string]interface{}{"event": "a"}},
+		{EventData: map[string]interface{}{"event": "b"}},
+		{EventData: map[string]interface{}{"event": "a"}},
+	}
+	result := analyzer.AnalyzeDiscover(entries)
+	// a → a, a → b and b → a, limited to the first in lexical order
+	if result.SessionCount != 1 || result.TotalSequences != 3 || len(result.Sequences) != 1 {
+		t.Fatalf("Expected 3 sequences limited to 1 in a single session, got %+v", result)
+	}
+	if got := result.Sequences[0].Events; !reflect.DeepEqual(got, []string{"a", "a"}) {
+		t.Errorf("Expected a → a first, got %v", got)
+	}
+}
+
+func TestNewDiscoverAnalyzer_InvalidOptions(t *testing.T) {
+	sessions, _ := NewSessionAnalyzer("", 0)
+	tests := []DiscoverOptions{
+		{MinSupport: 0, MinLength: 2, MaxLength: 5},
+		{MinSupport: 150, MinLength: 2, MaxLength: 5},
+		{MinSupport: 10, MinLength: 0, MaxLength: 5},
+		{MinSupport: 10, MinLength: 3, MaxLength: 2},
+		{MinSupport: 10, MinLength: 2, MaxLength: 5, Limit: -1},
+	}
+	for _, options := range tests {
+		if _, err := NewDiscoverAnalyzer(sessions, options); err == nil {
+			t.Errorf("NewDiscoverAnalyzer(%+v) expected an error", options)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// Extractor selects the entries matching event patterns or a funnel step,
+// together with the entries around them, e.g. to attach the log lines behind
+// a funnel failure to a bug report
+type Extractor struct {
+	match   func(entry *parser.LogEntry) bool
+	context int
+}
+
+// ExtractResult holds the matching entries and their context in log order
+type ExtractResult struct {
+	TotalEventsAnalyzed int              `json:"total_events_analyzed"`
+	Matches             int              `json:"matches"`
+	Context             int              `json:"context"`
+	Entries             []ExtractedEntry `json:"entries"`
+	// Partial is set when the extraction was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+// ExtractedEntry is a matching entry or an entry of its context, normalized
+// for JSON output. Raw holds the original log lines when the parser kept them.
+type ExtractedEntry struct {
+	// Index is the position of the entry among the analyzed entries
+	Index     int                    `json:"index"`
+	Match     bool                   `json:"match"`
+	Timestamp *time.Time             `json:"timestamp,omitempty"`
+	Level     string                 `json:"level,omitempty"`
+	Tag       string                 `json:"tag,omitempty"`
+	PID       int                    `json:"pid,omitempty"`
+	TID       int                    `json:"tid,omitempty"`
+	Message   string                 `json:"message"`
+	EventData map[string]interface{} `json:"event_data,omitempty"`
+	Raw       string                 `json:"raw,omitempty"`
+}
+
+// NewPatternExtractor creates an extractor for entries matching any of
+// eventPatterns, matched like the patterns of the count analyzer, with
+// context entries before and after each match
+func NewPatternExtractor(eventPatterns []string, ignoreCase bool, context int) (*Extractor, error) {
+	logrus.WithFields(logrus.Fields{
+		"pattern_count": len(eventPatterns),
+		"ignore_case":   ignoreCase,
+		"context":       context,
+	}).Debug("Creating new pattern extractor")
+
+	if context < 0 {
+		return nil, fmt.Errorf("context cannot be negative")
+	}
+	counter, err := NewCountAnalyzerWithOptions(eventPatterns, CountOptions{IgnoreCase: ignoreCase})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Extractor{
+		match: func(entry *parser.LogEntry) bool {
+			for _, pattern := range counter.patterns {
+				if counter.eventMatchesPattern(entry, pattern) {
+					return true
+				}
+			}
+			return false
+		},
+		context: context,
+	}, nil
+}
+
+// NewStepExtractor creates an extractor for entries matching the funnel step
+// named stepName, including its required properties, with context entries
+// before and after each match
+func NewStepExtractor(cfg *config.FunnelConfig, stepName string, context int) (*Extractor, error) {
+	logrus.WithFields(logrus.Fields{
+		"funnel_name": cfg.Name,
+		"step_name":   stepName,
+		"context":     context,
+	}).Debug("Creating new step extractor")
+
+	if context < 0 {
+		return nil, fmt.Errorf("context cannot be negative")
+	}
+	for _, step := range cfg.Steps {
+		if step.Name != stepName {
+			continue
+		}
+		funnel := NewFunnelAnalyzer(cfg)
+		return &Extractor{
+			match: func(entry *parser.LogEntry) bool {
+				return funnel.eventMatchesStep(entry, step)
+			},
+			context: context,
+		}, nil
+	}
+	return nil, fmt.Errorf("funnel '%s' has no step named '%s'", cfg.Name, stepName)
+}
+
+func (e *Extractor) Extract(entries []*parser.LogEntry) *ExtractResult {
+	return e.ExtractContext(context.Background(), entries)
+}
+
+// ExtractContext is like Extract but stops when ctx is cancelled, returning
+// the entries extracted so far marked as partial
+func (e *Extractor) ExtractContext(ctx context.Context, entries []*parser.LogEntry) *ExtractResult {
+	logrus.WithFields(logrus.Fields{
+		"entry_count": len(entries),
+		"context":     e.context,
+	}).Info("Starting extraction")
+
+	result := &ExtractResult{
+		TotalEventsAnalyzed: len(entries),
+		Context:             e.context,
+		Entries:             []ExtractedEntry{},
+	}
+
+	// next is the index of the first entry not extracted yet, so that
+	// overlapping contexts include each entry once
+	next := 0
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Extraction cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+		if !e.match(entry) {
+			continue
+		}
+
+		result.Matches++
+		for i := max(next, entryIndex-e.context); i < entryIndex; i++ {
+			result.Entries = append(result.Entries, newExtractedEntry(i, entries[i], false))
+		}
+		// A match within the context of the previous match is already
+		// extracted as context
+		if entryIndex < next {
+			result.Entries[len(result.Entries)-(next-entryIndex)].Match = true
+		} else {
+			result.Entries = append(result.Entries, newExtractedEntry(entryIndex, entry, true))
+			next = entryIndex + 1
+		}
+		for i := next; i < len(entries) && i <= entryIndex+e.context; i++ {
+			result.Entries = append(result.Entries, newExtractedEntry(i, entries[i], false))
+			next = i + 1
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"matches":   result.Matches,
+		"extracted": len(result.Entries),
+		"partial":   result.Partial,
+	}).Info("Extraction completed")
+
+	return result
+}
+
+func newExtractedEntry(index int, entry *parser.LogEntry, match bool) ExtractedEntry {
+	extracted := ExtractedEntry{
+		Index:     index,
+		Match:     match,
+		Level:     entry.Level,
+		Tag:       entry.Tag,
+		PID:       entry.PID,
+		TID:       entry.TID,
+		Message:   entry.Message,
+		EventData: entry.EventData,
+		Raw:       entry.Raw,
+	}
+	if !entry.Timestamp.IsZero() {
+		timestamp := entry.Timestamp
+		extracted.Timestamp = &timestamp
+	}
+	return extracted
+}
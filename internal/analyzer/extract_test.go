@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func extractedIndexes(result *ExtractResult) ([]int, []int) {
+	var indexes, matches []int
+	for _, entry := range result.Entries {
+		indexes = append(indexes, entry.Index)
+		if entry.Match {
+			matches = append(matches, entry.Index)
+		}
+	}
+	return indexes, matches
+}
+
+func TestExtractor_Extract(t *testing.T) {
+	entries := []*parser.LogEntry{
+		{Message: "start"},
+		{Message: "login"},
+		{Message: "noise"},
+		{Message: "noise"},
+		{Message: "LOGIN again"},
+		{Message: "noise"},
+		{Message: "noise"},
+		{Message: "noise"},
+		{EventData: map[string]interface{}{"event": "logout"}, Raw: `Analytics: {"event":"logout"}`},
+	}
+
+	tests := []struct {
+		name        string
+		patterns    []string
+		ignoreCase  bool
+		context     int
+		wantIndexes []int
+		wantMatches []int
+	}{
+		{name: "matches only", patterns: []string{"login", "logout"}, wantIndexes: []int{1, 8}, wantMatches: []int{1, 8}},
+		{name: "ignoring case", patterns: []string{"login"}, ignoreCase: true, wantIndexes: []int{1, 4}, wantMatches: []int{1, 4}},
+		{name: "context", patterns: []string{"logout"}, context: 2, wantIndexes: []int{6, 7, 8}, wantMatches: []int{8}},
+		{
+			name:        "overlapping contexts include entries once",
+			patterns:    []string{"(?i)login"},
+			context:     2,
+			wantIndexes: []int{0, 1, 2, 3, 4, 5, 6},
+			wantMatches: []int{1, 4},
+		},
+		{
+			name:        "match within the context of another",
+			patterns:    []string{"login", "start"},
+			context:     1,
+			wantIndexes: []int{0, 1, 2},
+			wantMatches: []int{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor, err := NewPatternExtractor(tt.patterns, tt.ignoreCase, tt.context)
+			if err != nil {
+				t.Fatalf("NewPatternExtractor() unexpected error: %v", err)
+			}
+			result := extractor.Extract(entries)
+
+			indexes, matches := extractedIndexes(result)
+			if !slices.Equal(indexes, tt.wantIndexes) || !slices.Equal(matches, tt.wantMatches) {
+				t.Errorf("Extracted %v with matches %v, want %v with matches %v", indexes, matches, tt.wantIndexes, tt.wantMatches)
+			}
+			if result.Matches != len(tt.wantMatches) || result.TotalEventsAnalyzed != len(entries) {
+				t.Errorf("Unexpected totals %+v", result)
+			}
+		})
+	}
+}
+
+func TestExtractor_KeepsEntryFields(t *testing.T) {
+	extractor, err := NewPatternExtractor([]string{"logout"}, false, 0)
+	if err != nil {
+		t.Fatalf("NewPatternExtractor() unexpected error: %v", err)
+	}
+
+	entry := &parser.LogEntry{Tag: "Analytics", EventData: map[string]interface{}{"event": "logout"}, Raw: `Analytics: {"event":"logout"}`}
+	result := extractor.Extract([]*parser.LogEntry{entry})
+	if len(result.Entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %+v", result.Entries)
+	}
+	extracted := result.Entries[0]
+	if extracted.Raw != entry.Raw || extracted.Tag != "Analytics" || extracted.EventData["event"] != "logout" || extracted.Timestamp != nil {
+		t.Errorf("Unexpected extracted entry %+v", extracted)
+	}
+}
+
+func TestNewStepExtractor(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "Checkout",
+		Steps: []config.Step{
+			{Name: "View", EventPattern: "view"},
+			{Name: "Pay", EventPattern: "purchase", RequiredProperties: map[string]string{"amount": "> 0"}},
+		},
+	}
+	entries := []*parser.LogEntry{
+		{EventData: map[string]interface{}{"event": "purchase", "amount": 0.0}},
+		{EventData: map[string]interface{}{"event": "view"}},
+		{EventData: map[string]interface{}{"event": "purchase", "amount": 9.99}},
+	}
+
+	extractor, err := NewStepExtractor(cfg, "Pay", 0)
+	if err != nil {
+		t.Fatalf("NewStepExtractor() unexpected error: %v", err)
+	}
+	indexes, _ := extractedIndexes(extractor.Extract(entries))
+	if !slices.Equal(indexes, []int{2}) {
+		t.Errorf("Extracted %v, want [2]", indexes)
+	}
+
+	if _, err := NewStepExtractor(cfg, "Ship", 0); err == nil {
+		t.Error("Expected error for unknown step")
+	}
+	if _, err := NewStepExtractor(cfg, "Pay", -1); err == nil {
+		t.Error("Expected error for negative context")
+	}
+}
+
+func TestExtractor_Cancelled(t *testing.T) {
+	extractor, err := NewPatternExtractor([]string{"login"}, false, 0)
+	if err != nil {
+		t.Fatalf("NewPatternExtractor() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := extractor.ExtractContext(ctx, []*parser.LogEntry{{Message: "login"}})
+	if !result.Partial || result.TotalEventsAnalyzed != 0 || len(result.Entries) != 0 {
+		t.Errorf("Expected empty partial result, got %+v", result)
+	}
+}
@@ -1,29 +1,110 @@
 package analyzer
 
 import (
-	"github.com/parfenovvs/loglion/internal/config"
-	"github.com/parfenovvs/loglion/internal/parser"
+	"context"
+	"maps"
 	"regexp"
+	"time"
 
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/expr"
+	"github.com/parfenovvs/loglion/internal/parser"
 	"github.com/sirupsen/logrus"
 )
 
 type FunnelAnalyzer struct {
 	config *config.FunnelConfig
+	hooks  Hooks
+	// limit and mode are the defaults of Analyze and the mode of attempts
+	limit int
+	mode  string
+	// samples is the number of first and last matches kept per step
+	samples int
+	// conversionSteps keeps the entry of every step of each conversion
+	conversionSteps bool
+	// exprs caches the compiled step expressions by source
+	exprs map[string]*expr.Program
+	// regexps and conditions cache the compiled event and exclude patterns
+	// and required property conditions of the steps, nil when invalid
+	regexps    map[patternKey]*regexp.Regexp
+	conditions map[patternKey]compiledCondition
+	// branchProperties caches the required properties of any_of branches
+	// merged with those of their step
+	branchProperties map[*config.StepBranch]map[string]string
+}
+
+// patternKey identifies a compiled pattern or condition by its source and
+// case sensitivity
+type patternKey struct {
+	pattern    string
+	ignoreCase bool
+}
+
+// compiledCondition is a parsed required property pattern, or the error
+// parsing it
+type compiledCondition struct {
+	condition *expr.Condition
+	err       error
 }
 
 type FunnelResult struct {
-	FunnelName          string       `json:"funnel_name"`
-	TotalEventsAnalyzed int          `json:"total_events_analyzed"`
-	FunnelCompleted     bool         `json:"funnel_completed"`
-	Steps               []StepResult `json:"steps"`
-	DropOffs            []DropOff    `json:"drop_offs"`
+	FunnelName          string `json:"funnel_name"`
+	TotalEventsAnalyzed int    `json:"total_events_analyzed"`
+	FunnelCompleted     bool   `json:"funnel_completed"`
+	// EnteredFunnel counts the attempts that reached any step, or the groups
+	// that did for grouped funnels. Step percentages are relative to it.
+	EnteredFunnel int `json:"entered_funnel"`
+	// ConversionsFound counts the attempts that completed the funnel, or the
+	// groups that did for grouped funnels
+	ConversionsFound int          `json:"conversions_found"`
+	Steps            []StepResult `json:"steps"`
+	DropOffs         []DropOff    `json:"drop_offs"`
+	Anomalies        []Anomaly    `json:"anomalies,omitempty"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+	// Groups is set when the funnel was analyzed per group with group_by
+	Groups *GroupSummary `json:"groups,omitempty"`
+	// Exclusions lists the steps whose exclude_pattern aborted funnel attempts
+	Exclusions []Exclusion `json:"exclusions,omitempty"`
+	// Timings holds time-to-convert statistics from the first step to each
+	// later step; the last one is the total conversion time
+	Timings []StepTiming `json:"timings,omitempty"`
+	// Assertions are the outcomes of the min_count and min_conversion_pct
+	// thresholds of the steps
+	Assertions []Assertion `json:"assertions,omitempty"`
+	// Conversions lists the attempts that completed the funnel in the order
+	// they completed
+	Conversions []FunnelConversion `json:"conversions,omitempty"`
 }
 
 type StepResult struct {
 	Name       string  `json:"name"`
 	EventCount int     `json:"event_count"`
 	Percentage float64 `json:"percentage"`
+	// Branches shows which any_of branch the step was reached through
+	Branches []BranchResult `json:"branches,omitempty"`
+	// Samples are the first and last entries that matched the step, in log
+	// order, when samples were requested
+	Samples []StepSample `json:"samples,omitempty"`
+	// NearMisses are the first entries that almost matched a step no entry
+	// matched, out of NearMissCount
+	NearMisses    []NearMiss `json:"near_misses,omitempty"`
+	NearMissCount int        `json:"near_miss_count,omitempty"`
+	// AssertionFailed is set when the step misses its min_count or
+	// min_conversion_pct threshold
+	AssertionFailed bool `json:"assertion_failed,omitempty"`
+	// FirstTimestamp and LastTimestamp are the times of the first and last
+	// entries that matched the step, if they have timestamps
+	FirstTimestamp *time.Time `json:"first_timestamp,omitempty"`
+	LastTimestamp  *time.Time `json:"last_timestamp,omitempty"`
+}
+
+// BranchResult counts how often a step was reached through one of its any_of
+// branches. Percentage is relative to the step's event count.
+type BranchResult struct {
+	Name       string  `json:"name"`
+	EventCount int     `json:"event_count"`
+	Percentage float64 `json:"percentage"`
 }
 
 type DropOff struct {
@@ -33,18 +114,202 @@ type DropOff struct {
 	DropOffRate float64 `json:"drop_off_rate"`
 }
 
+// ConversionRate returns the percentage of funnel entries that reached the last step
+func (r *FunnelResult) ConversionRate() float64 {
+	if len(r.Steps) == 0 {
+		return 0
+	}
+	return r.Steps[len(r.Steps)-1].Percentage
+}
+
+// Anomaly describes a funnel attempt that was abandoned because a step's event
+// fired more often than its fail_if_more_than limit allows.
+type Anomaly struct {
+	Step        string `json:"step"`
+	Occurrences int    `json:"occurrences"`
+	Limit       int    `json:"limit"`
+	EntryIndex  int    `json:"entry_index"`
+}
+
+// Exclusion counts funnel attempts aborted by a forbidden event between the
+// previous step and Step
+type Exclusion struct {
+	Step            string `json:"step"`
+	Pattern         string `json:"pattern"`
+	AbortedAttempts int    `json:"aborted_attempts"`
+}
+
+// AbortedAttempts returns the number of funnel attempts aborted by exclusion events
+func (r *FunnelResult) AbortedAttempts() int {
+	aborted := 0
+	for _, exclusion := range r.Exclusions {
+		aborted += exclusion.AbortedAttempts
+	}
+	return aborted
+}
+
 func NewFunnelAnalyzer(cfg *config.FunnelConfig) *FunnelAnalyzer {
 	logrus.WithFields(logrus.Fields{
 		"funnel_name": cfg.Name,
 		"step_count":  len(cfg.Steps),
 	}).Debug("Creating new funnel analyzer")
 
-	return &FunnelAnalyzer{
+	fa := &FunnelAnalyzer{
 		config: cfg,
+		mode:   cfg.Mode,
+	}
+	fa.compileSteps()
+	return fa
+}
+
+// compileSteps fills the caches with the expressions, patterns and required
+// property conditions of every step, so they are not compiled per entry.
+// Steps outside the config are compiled on first use.
+func (fa *FunnelAnalyzer) compileSteps() {
+	for i := range fa.config.Steps {
+		step := &fa.config.Steps[i]
+		ignoreCase := step.IgnoresCase(fa.config.CaseInsensitive)
+		if step.Expr != "" {
+			fa.compiledExpr(step.Expr)
+		} else if len(step.AnyOf) == 0 {
+			fa.compiledPattern(step.EventPattern, ignoreCase)
+		}
+		if step.ExcludePattern != "" {
+			fa.compiledPattern(step.ExcludePattern, ignoreCase)
+		}
+		for _, pattern := range step.RequiredProperties {
+			fa.compiledCondition(pattern, ignoreCase)
+		}
+
+		for j := range step.AnyOf {
+			branch := &step.AnyOf[j]
+			branchIgnoreCase := branch.IgnoresCase(ignoreCase)
+			fa.compiledPattern(branch.EventPattern, branchIgnoreCase)
+			for _, pattern := range fa.mergedProperties(step, branch) {
+				fa.compiledCondition(pattern, branchIgnoreCase)
+			}
+		}
+	}
+}
+
+// compiledExpr returns the compiled expression of source, nil if invalid
+func (fa *FunnelAnalyzer) compiledExpr(source string) *expr.Program {
+	program, exists := fa.exprs[source]
+	if !exists {
+		var err error
+		program, err = expr.Compile(source)
+		if err != nil {
+			logrus.WithError(err).WithField("expr", source).Error("Failed to compile step expression")
+		}
+		if fa.exprs == nil {
+			fa.exprs = make(map[string]*expr.Program)
+		}
+		fa.exprs[source] = program
+	}
+	return program
+}
+
+// compiledPattern returns the compiled regex of pattern, nil if invalid
+func (fa *FunnelAnalyzer) compiledPattern(pattern string, ignoreCase bool) *regexp.Regexp {
+	key := patternKey{pattern: pattern, ignoreCase: ignoreCase}
+	regex, exists := fa.regexps[key]
+	if !exists {
+		var err error
+		regex, err = compilePattern(pattern, ignoreCase)
+		if err != nil {
+			logrus.WithError(err).WithField("step_pattern", pattern).Error("Failed to compile step regex pattern")
+		}
+		if fa.regexps == nil {
+			fa.regexps = make(map[patternKey]*regexp.Regexp)
+		}
+		fa.regexps[key] = regex
+	}
+	return regex
+}
+
+// compiledCondition returns the condition of a required property pattern
+func (fa *FunnelAnalyzer) compiledCondition(pattern string, ignoreCase bool) (*expr.Condition, error) {
+	key := patternKey{pattern: pattern, ignoreCase: ignoreCase}
+	compiled, exists := fa.conditions[key]
+	if !exists {
+		compiled.condition, compiled.err = expr.ParseCondition(pattern, ignoreCase)
+		if compiled.err != nil {
+			logrus.WithError(compiled.err).WithField("pattern", pattern).Error("Failed to parse property pattern")
+		}
+		if fa.conditions == nil {
+			fa.conditions = make(map[patternKey]compiledCondition)
+		}
+		fa.conditions[key] = compiled
+	}
+	return compiled.condition, compiled.err
+}
+
+// mergedProperties returns the required properties of step overridden by
+// those of its any_of branch
+func (fa *FunnelAnalyzer) mergedProperties(step *config.Step, branch *config.StepBranch) map[string]string {
+	if len(step.RequiredProperties) == 0 {
+		return branch.RequiredProperties
+	}
+	merged, exists := fa.branchProperties[branch]
+	if !exists {
+		merged = make(map[string]string, len(step.RequiredProperties)+len(branch.RequiredProperties))
+		maps.Copy(merged, step.RequiredProperties)
+		maps.Copy(merged, branch.RequiredProperties)
+		if fa.branchProperties == nil {
+			fa.branchProperties = make(map[*config.StepBranch]map[string]string)
+		}
+		fa.branchProperties[branch] = merged
+	}
+	return merged
+}
+
+// NewFunnelAnalyzerWithHooks creates a funnel analyzer that calls hooks during analysis
+func NewFunnelAnalyzerWithHooks(cfg *config.FunnelConfig, hooks Hooks) *FunnelAnalyzer {
+	return NewFunnelAnalyzerWithOptions(cfg, FunnelOptions{Hooks: hooks})
+}
+
+// FunnelOptions changes what the funnel analyzer reports
+type FunnelOptions struct {
+	// Hooks are called during analysis
+	Hooks Hooks
+	// Samples keeps the first and the last Samples entries that matched each
+	// step in the step results, none when 0
+	Samples int
+	// ConversionSteps lists the entry that matched each step in every
+	// conversion of the result
+	ConversionSteps bool
+	// Limit stops Analyze after Limit conversions, 0 analyzes all entries
+	Limit int
+	// Mode overrides the mode of the funnel config when set
+	Mode string
+}
+
+// NewFunnelAnalyzerWithOptions creates a funnel analyzer with the given options
+func NewFunnelAnalyzerWithOptions(cfg *config.FunnelConfig, options FunnelOptions) *FunnelAnalyzer {
+	fa := NewFunnelAnalyzer(cfg)
+	fa.hooks = options.Hooks
+	fa.samples = options.Samples
+	fa.conversionSteps = options.ConversionSteps
+	fa.limit = options.Limit
+	if options.Mode != "" {
+		fa.mode = options.Mode
 	}
+	return fa
+}
+
+// Analyze runs the funnel over entries with the limit of the analyzer
+// options, stopping when ctx is cancelled like AnalyzeFunnelContext
+func (fa *FunnelAnalyzer) Analyze(ctx context.Context, entries []*parser.LogEntry) *FunnelResult {
+	return fa.AnalyzeFunnelContext(ctx, entries, fa.limit)
 }
 
 func (fa *FunnelAnalyzer) AnalyzeFunnel(entries []*parser.LogEntry, limit int) *FunnelResult {
+	return fa.AnalyzeFunnelContext(context.Background(), entries, limit)
+}
+
+// AnalyzeFunnelContext is like AnalyzeFunnel but stops when ctx is cancelled,
+// returning the result for the entries analyzed so far marked as partial
+func (fa *FunnelAnalyzer) AnalyzeFunnelContext(ctx context.Context, entries []*parser.LogEntry, limit int) *FunnelResult {
 	logrus.WithFields(logrus.Fields{
 		"funnel_name": fa.config.Name,
 		"entry_count": len(entries),
@@ -59,126 +324,232 @@ func (fa *FunnelAnalyzer) AnalyzeFunnel(entries []*parser.LogEntry, limit int) *
 			FunnelCompleted:     false,
 			Steps:               []StepResult{},
 			DropOffs:            []DropOff{},
+			Assertions:          fa.checkAssertions(nil),
 		}
 	}
 
-	stepResults := make([]StepResult, len(fa.config.Steps))
-	stepCounts := make([]int, len(fa.config.Steps))
-
-	// Initialize step results
-	for i, step := range fa.config.Steps {
-		stepResults[i] = StepResult{
-			Name:       step.Name,
-			EventCount: 0,
-			Percentage: 0.0,
-		}
-		logrus.WithFields(logrus.Fields{
-			"step_index": i + 1,
-			"step_name":  step.Name,
-			"pattern":    step.EventPattern,
-		}).Debug("Initialized funnel step")
+	if fa.config.GroupBy != "" {
+		return fa.analyzeGroups(ctx, entries, limit)
 	}
 
+	stepCounts := make([]int, len(fa.config.Steps))
+
 	var matchedEvents int
-	var currentStep int
 	var conversionsFound int
-
+	var conversions []FunnelConversion
+	details := fa.newStepDetails()
+	attempt := fa.newAttempt(details)
+	analyzedEntries := len(entries)
+	partial := false
+
+	// Without a limit, track funnel progression through the entire log,
+	// otherwise stop after 'limit' conversions
 	if limit == 0 {
-		// Mode 1: Track sequential funnel progression through the entire log
 		logrus.Debug("Mode 1: Tracking sequential funnel progression")
-		currentStep = 0
-
-		for entryIndex, entry := range entries {
-			// Check if current entry matches the expected next step
-			if currentStep < len(fa.config.Steps) {
-				step := fa.config.Steps[currentStep]
-				if fa.eventMatchesStep(entry, step) {
-					stepCounts[currentStep]++
-					matchedEvents++
-					currentStep++
-
-					logrus.WithFields(logrus.Fields{
-						"entry_index": entryIndex + 1,
-						"step_index":  currentStep,
-						"step_name":   step.Name,
-						"timestamp":   entry.Timestamp,
-						"message":     entry.Message,
-					}).Debug("Event matched funnel step")
-
-					// Check if funnel was completed
-					if currentStep >= len(fa.config.Steps) {
-						conversionsFound++
-						logrus.WithField("conversions_total", conversionsFound).Debug("Funnel completed")
-						// Reset to look for additional complete funnels
-						currentStep = 0
-					}
-				}
-			}
-		}
 	} else {
-		// Mode 2: Track complete funnel conversions, stop after 'limit' conversions
 		logrus.WithField("target_conversions", limit).Debug("Mode 2: Tracking complete funnel conversions")
-		conversionsFound = 0
-		currentStep = 0
-
-		for entryIndex, entry := range entries {
-			if conversionsFound >= limit {
-				logrus.WithField("conversions_found", conversionsFound).Debug("Target conversions reached, stopping analysis")
-				break
-			}
+	}
 
-			if currentStep >= len(fa.config.Steps) {
-				logrus.Debug("Funnel completed, resetting for next conversion")
-				conversionsFound++
-				currentStep = 0 // Reset for next conversion
-				if conversionsFound >= limit {
-					break
-				}
-			}
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Funnel analysis cancelled, returning partial result")
+			analyzedEntries = entryIndex
+			partial = true
+			break
+		}
+		fa.hooks.entryParsed(entryIndex, entry)
 
-			step := fa.config.Steps[currentStep]
-			if fa.eventMatchesStep(entry, step) {
-				stepCounts[currentStep]++
-				matchedEvents++
-				logrus.WithFields(logrus.Fields{
-					"entry_index":        entryIndex + 1,
-					"step_index":         currentStep + 1,
-					"step_name":          step.Name,
-					"timestamp":          entry.Timestamp,
-					"message":            entry.Message,
-					"conversions_so_far": conversionsFound,
-				}).Debug("Event matched funnel step")
-				currentStep++
-			}
+		match, ok := attempt.add(entryIndex, entry)
+		if !ok {
+			continue
 		}
+		stepCounts[match.step]++
+		details.matched(match.step, match.branch, entryIndex, entry, attempt.start)
+		fa.notifyStepMatched(match.step, match.branch, "", entryIndex, entry)
+		matchedEvents++
 
-		// Check if funnel was completed at the end
-		if currentStep >= len(fa.config.Steps) {
-			logrus.Debug("Funnel completed at end of log")
+		logrus.WithFields(logrus.Fields{
+			"entry_index":        entryIndex + 1,
+			"step_index":         match.step + 1,
+			"step_name":          fa.config.Steps[match.step].Name,
+			"timestamp":          entry.Timestamp,
+			"message":            entry.Message,
+			"conversions_so_far": conversionsFound,
+		}).Debug("Event matched funnel step")
+
+		if attempt.converted() {
 			conversionsFound++
+			logrus.WithField("conversions_total", conversionsFound).Debug("Funnel completed")
+			conversions = append(conversions, fa.newConversion(conversionsFound, "", attempt.entries()))
+			fa.hooks.conversion(Conversion{Number: conversionsFound, EntryIndex: entryIndex + 1, Entry: entry})
+			// Reset to look for additional complete funnels
+			attempt.reset()
+			if limit > 0 && conversionsFound >= limit {
+				logrus.WithField("conversions_found", conversionsFound).Debug("Target conversions reached, stopping analysis")
+				break
+			}
 		}
 	}
 
 	logrus.WithFields(logrus.Fields{
 		"total_entries":   len(entries),
 		"matched_events":  matchedEvents,
-		"completed_steps": currentStep,
+		"completed_steps": attempt.reached,
 		"total_steps":     len(fa.config.Steps),
 		"mode":            map[bool]string{true: "count_all", false: "track_conversions"}[limit == 0],
 	}).Info("Funnel analysis completed")
 
-	// Calculate percentages based on first step
-	logrus.Debug("Calculating conversion percentages")
-	var baseCount int
-	if len(stepCounts) > 0 && stepCounts[0] > 0 {
-		baseCount = stepCounts[0]
+	stepResults, dropOffs := fa.buildStepResults(stepCounts, attempt.entered, details)
+	if !partial {
+		fa.addNearMisses(stepResults, entries)
+	}
+	assertions := fa.checkAssertions(stepResults)
+	// The funnel was completed if any attempt converted, in both modes
+	funnelCompleted := conversionsFound > 0
+	logrus.WithField("funnel_completed", funnelCompleted).Debug("Funnel completion status determined")
+
+	result := &FunnelResult{
+		FunnelName:          fa.config.Name,
+		TotalEventsAnalyzed: analyzedEntries,
+		FunnelCompleted:     funnelCompleted,
+		EnteredFunnel:       attempt.entered,
+		ConversionsFound:    conversionsFound,
+		Steps:               stepResults,
+		DropOffs:            dropOffs,
+		Anomalies:           append([]Anomaly{}, attempt.anomalies...),
+		Partial:             partial,
+		Exclusions:          fa.buildExclusions(details.exclusions),
+		Timings:             fa.buildTimings(details.timer),
+		Assertions:          assertions,
+		Conversions:         conversions,
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"funnel_name":      result.FunnelName,
+		"total_events":     result.TotalEventsAnalyzed,
+		"funnel_completed": result.FunnelCompleted,
+		"conversions":      result.ConversionsFound,
+		"steps_analyzed":   len(result.Steps),
+		"drop_offs_found":  len(result.DropOffs),
+		"anomalies_found":  len(result.Anomalies),
+		"aborted_attempts": result.AbortedAttempts(),
+		"partial":          result.Partial,
+	}).Info("Funnel analysis completed")
+
+	return result
+}
+
+// notifyStepMatched calls the OnStepMatched hook for an entry that matched a
+// step, through branch for steps with any_of
+func (fa *FunnelAnalyzer) notifyStepMatched(stepIndex, branch int, group string, entryIndex int, entry *parser.LogEntry) {
+	if fa.hooks.OnStepMatched == nil {
+		return
+	}
+
+	step := fa.config.Steps[stepIndex]
+	match := StepMatch{
+		StepIndex:  stepIndex,
+		Step:       step.Name,
+		Group:      group,
+		EntryIndex: entryIndex + 1,
+		Entry:      entry,
+	}
+	if branch >= 0 {
+		match.Branch = step.AnyOf[branch].Label()
+	}
+	fa.hooks.stepMatched(match)
+}
+
+// stepDetails collects per-step details of funnel attempts besides step counts
+type stepDetails struct {
+	// exclusions counts attempts aborted while waiting for each step
+	exclusions []int
+	// branches counts how often each any_of branch of a step was taken
+	branches [][]int
+	timer    *stepTimer
+	// spans tracks when each step was matched
+	spans []timeSpan
+	// samples collects the matches of each step, nil unless samples were requested
+	samples []*sampleCollector
+}
+
+func (fa *FunnelAnalyzer) newStepDetails() *stepDetails {
+	details := &stepDetails{
+		exclusions: make([]int, len(fa.config.Steps)),
+		branches:   make([][]int, len(fa.config.Steps)),
+		timer:      newStepTimer(len(fa.config.Steps)),
+		spans:      make([]timeSpan, len(fa.config.Steps)),
+	}
+	for i, step := range fa.config.Steps {
+		details.branches[i] = make([]int, len(step.AnyOf))
+	}
+	if fa.samples > 0 {
+		details.samples = make([]*sampleCollector, len(fa.config.Steps))
+		for i := range details.samples {
+			details.samples[i] = &sampleCollector{limit: fa.samples}
+		}
+	}
+	return details
+}
+
+// matched records that an attempt started at start reached step through
+// branch (-1 for steps without any_of) with the entry at entryIndex
+func (d *stepDetails) matched(step, branch, entryIndex int, entry *parser.LogEntry, start time.Time) {
+	if branch >= 0 {
+		d.branches[step][branch]++
+	}
+	d.timer.record(step, start, entry.Timestamp)
+	d.spans[step].add(entry.Timestamp)
+	if d.samples != nil {
+		d.samples[step].add(entryIndex, entry)
+	}
+}
+
+// buildStepResults converts per-step counts into step percentages relative
+// to the entered attempts or groups and drop-offs between consecutive steps,
+// adding the branch counts and samples of details
+func (fa *FunnelAnalyzer) buildStepResults(stepCounts []int, entered int, details *stepDetails) ([]StepResult, []DropOff) {
+	stepResults := make([]StepResult, len(fa.config.Steps))
+
+	// Initialize step results
+	for i, step := range fa.config.Steps {
+		stepResults[i] = StepResult{
+			Name:       step.Name,
+			EventCount: 0,
+			Percentage: 0.0,
+		}
+		logrus.WithFields(logrus.Fields{
+			"step_index": i + 1,
+			"step_name":  step.Name,
+			"pattern":    step.EventPattern,
+			"branches":   len(step.AnyOf),
+		}).Debug("Initialized funnel step")
 	}
 
+	// Calculate percentages based on the attempts that entered the funnel,
+	// which in ordered modes are the ones that reached the first step
+	logrus.Debug("Calculating conversion percentages")
+	baseCount := entered
+
 	for i, count := range stepCounts {
 		stepResults[i].EventCount = count
 		if baseCount > 0 {
 			stepResults[i].Percentage = float64(count) / float64(baseCount) * 100.0
 		}
+		stepResults[i].FirstTimestamp, stepResults[i].LastTimestamp = details.spans[i].bounds()
+		if details.samples != nil {
+			stepResults[i].Samples = details.samples[i].samples()
+		}
+		for branch, branchCount := range details.branches[i] {
+			branchResult := BranchResult{
+				Name:       fa.config.Steps[i].AnyOf[branch].Label(),
+				EventCount: branchCount,
+			}
+			if count > 0 {
+				branchResult.Percentage = float64(branchCount) / float64(count) * 100.0
+			}
+			stepResults[i].Branches = append(stepResults[i].Branches, branchResult)
+		}
 		logrus.WithFields(logrus.Fields{
 			"step_name":   stepResults[i].Name,
 			"event_count": count,
@@ -212,48 +583,99 @@ func (fa *FunnelAnalyzer) AnalyzeFunnel(entries []*parser.LogEntry, limit int) *
 		}
 	}
 
-	// Determine if funnel was completed
-	var funnelCompleted bool
-	if limit == 0 {
-		// In Mode 1, check if we found any complete conversions
-		funnelCompleted = conversionsFound > 0
-	} else {
-		// In Mode 2, check if we found any complete conversions
-		funnelCompleted = conversionsFound > 0
-	}
-	logrus.WithField("funnel_completed", funnelCompleted).Debug("Funnel completion status determined")
+	return stepResults, dropOffs
+}
 
-	result := &FunnelResult{
-		FunnelName:          fa.config.Name,
-		TotalEventsAnalyzed: len(entries),
-		FunnelCompleted:     funnelCompleted,
-		Steps:               stepResults,
-		DropOffs:            dropOffs,
+// buildExclusions lists the steps whose exclude_pattern aborted at least one attempt
+func (fa *FunnelAnalyzer) buildExclusions(exclusionCounts []int) []Exclusion {
+	var exclusions []Exclusion
+	for i, count := range exclusionCounts {
+		if count == 0 {
+			continue
+		}
+		exclusions = append(exclusions, Exclusion{
+			Step:            fa.config.Steps[i].Name,
+			Pattern:         fa.config.Steps[i].ExcludePattern,
+			AbortedAttempts: count,
+		})
 	}
+	return exclusions
+}
 
-	logrus.WithFields(logrus.Fields{
-		"funnel_name":      result.FunnelName,
-		"total_events":     result.TotalEventsAnalyzed,
-		"funnel_completed": result.FunnelCompleted,
-		"steps_analyzed":   len(result.Steps),
-		"drop_offs_found":  len(result.DropOffs),
-	}).Info("Funnel analysis completed")
+// trackOccurrences counts entries matching steps that declare fail_if_more_than
+// within the current funnel attempt and reports the first step whose limit is exceeded.
+func (fa *FunnelAnalyzer) trackOccurrences(entry *parser.LogEntry, entryIndex int, occurrences []int) *Anomaly {
+	for i, step := range fa.config.Steps {
+		if step.FailIfMoreThan == nil || !fa.eventMatchesStep(entry, step) {
+			continue
+		}
 
-	return result
+		limit := *step.FailIfMoreThan
+		occurrences[i]++
+		if occurrences[i] > limit {
+			logrus.WithFields(logrus.Fields{
+				"entry_index": entryIndex + 1,
+				"step_name":   step.Name,
+				"occurrences": occurrences[i],
+				"limit":       limit,
+			}).Debug("Step exceeded maximum occurrences, marking attempt as anomalous")
+
+			return &Anomaly{
+				Step:        step.Name,
+				Occurrences: occurrences[i],
+				Limit:       limit,
+				EntryIndex:  entryIndex + 1,
+			}
+		}
+	}
+	return nil
 }
 
 func (fa *FunnelAnalyzer) eventMatchesStep(entry *parser.LogEntry, step config.Step) bool {
+	matched, _ := fa.matchStep(entry, step)
+	return matched
+}
+
+// matchStep reports whether entry satisfies step and, for steps with any_of,
+// the index of the first matching branch (-1 otherwise)
+func (fa *FunnelAnalyzer) matchStep(entry *parser.LogEntry, step config.Step) (bool, int) {
+	if step.Expr != "" {
+		return fa.eventMatchesExpr(entry, step), -1
+	}
+
+	ignoreCase := step.IgnoresCase(fa.config.CaseInsensitive)
+	if len(step.AnyOf) == 0 {
+		return fa.eventMatchesPattern(entry, step.Name, step.EventPattern, step.RequiredProperties, ignoreCase), -1
+	}
+
+	for i := range step.AnyOf {
+		// The branches share their array with the config, so the merged
+		// properties are cached by branch
+		branch := &step.AnyOf[i]
+		requiredProps := fa.mergedProperties(&step, branch)
+		if fa.eventMatchesPattern(entry, step.Name, branch.EventPattern, requiredProps, branch.IgnoresCase(ignoreCase)) {
+			logrus.WithFields(logrus.Fields{
+				"step_name": step.Name,
+				"branch":    branch.Label(),
+			}).Debug("Event matched step branch")
+			return true, i
+		}
+	}
+	return false, -1
+}
+
+// eventMatchesPattern matches the "event" field of structured entries, or the
+// raw message otherwise, against pattern and checks the required properties
+func (fa *FunnelAnalyzer) eventMatchesPattern(entry *parser.LogEntry, stepName, pattern string, requiredProperties map[string]string, ignoreCase bool) bool {
 	logrus.WithFields(logrus.Fields{
-		"step_name":      step.Name,
-		"step_pattern":   step.EventPattern,
+		"step_name":      stepName,
+		"step_pattern":   pattern,
 		"entry_message":  entry.Message,
 		"has_event_data": entry.EventData != nil,
 	}).Debug("Checking if event matches step")
 
-	// Compile regex pattern
-	eventRegex, err := regexp.Compile(step.EventPattern)
-	if err != nil {
-		logrus.WithError(err).WithField("step_pattern", step.EventPattern).Error("Failed to compile step regex pattern")
+	eventRegex := fa.compiledPattern(pattern, ignoreCase)
+	if eventRegex == nil {
 		return false
 	}
 
@@ -263,7 +685,7 @@ func (fa *FunnelAnalyzer) eventMatchesStep(entry *parser.LogEntry, step config.S
 			if eventStr, ok := eventValue.(string); ok {
 				logrus.WithFields(logrus.Fields{
 					"event_str": eventStr,
-					"pattern":   step.EventPattern,
+					"pattern":   pattern,
 				}).Debug("Matching against structured event field")
 
 				if !eventRegex.MatchString(eventStr) {
@@ -289,17 +711,67 @@ func (fa *FunnelAnalyzer) eventMatchesStep(entry *parser.LogEntry, step config.S
 			logrus.Debug("Raw message does not match pattern")
 			return false
 		}
-		hasRequiredProps := len(step.RequiredProperties) == 0
+		hasRequiredProps := len(requiredProperties) == 0
 		logrus.WithField("has_required_props", hasRequiredProps).Debug("No structured data available for property checking")
 		return hasRequiredProps
 	}
 
 	// Check required properties
-	logrus.WithField("required_props_count", len(step.RequiredProperties)).Debug("Checking required properties")
-	return fa.checkRequiredProperties(entry.EventData, step.RequiredProperties)
+	logrus.WithField("required_props_count", len(requiredProperties)).Debug("Checking required properties")
+	return fa.checkRequiredProperties(entry.EventData, requiredProperties, ignoreCase)
+}
+
+// eventMatchesExpr evaluates the expression of step for entry and checks the
+// required properties
+func (fa *FunnelAnalyzer) eventMatchesExpr(entry *parser.LogEntry, step config.Step) bool {
+	program := fa.compiledExpr(step.Expr)
+	if program == nil || !program.Match(entry) {
+		return false
+	}
+
+	logrus.WithField("step_name", step.Name).Debug("Event matched step expression")
+	if len(step.RequiredProperties) > 0 && entry.EventData == nil {
+		return false
+	}
+	return fa.checkRequiredProperties(entry.EventData, step.RequiredProperties, step.IgnoresCase(fa.config.CaseInsensitive))
 }
 
-func (fa *FunnelAnalyzer) checkRequiredProperties(eventData map[string]interface{}, requiredProps map[string]string) bool {
+// eventMatchesExclusion reports whether entry is a forbidden event for the
+// step the current attempt is waiting for. Like step patterns, the exclude
+// pattern matches the "event" field of structured entries or the raw message.
+func (fa *FunnelAnalyzer) eventMatchesExclusion(entry *parser.LogEntry, entryIndex int, step config.Step) bool {
+	if step.ExcludePattern == "" {
+		return false
+	}
+
+	excludeRegex := fa.compiledPattern(step.ExcludePattern, step.IgnoresCase(fa.config.CaseInsensitive))
+	if excludeRegex == nil {
+		return false
+	}
+
+	eventStr := entry.Message
+	if eventValue, exists := entry.EventData["event"]; exists {
+		str, ok := eventValue.(string)
+		if !ok {
+			return false
+		}
+		eventStr = str
+	}
+
+	if !excludeRegex.MatchString(eventStr) {
+		return false
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"entry_index":     entryIndex + 1,
+		"step_name":       step.Name,
+		"exclude_pattern": step.ExcludePattern,
+		"event":           eventStr,
+	}).Debug("Exclusion event aborted funnel attempt")
+	return true
+}
+
+func (fa *FunnelAnalyzer) checkRequiredProperties(eventData map[string]interface{}, requiredProps map[string]string, ignoreCase bool) bool {
 	logrus.WithField("properties_to_check", len(requiredProps)).Debug("Starting required properties validation")
 
 	for key, pattern := range requiredProps {
@@ -308,42 +780,30 @@ func (fa *FunnelAnalyzer) checkRequiredProperties(eventData map[string]interface
 			"pattern":      pattern,
 		}).Debug("Checking required property")
 
-		value, exists := eventData[key]
+		value, exists := parser.LookupProperty(eventData, key)
 		if !exists {
 			logrus.WithField("property_key", key).Debug("Required property not found in event data")
 			return false
 		}
 
-		valueStr, ok := value.(string)
-		if !ok {
-			logrus.WithFields(logrus.Fields{
-				"property_key": key,
-				"value_type":   typeof(value),
-			}).Debug("Property value is not a string")
-			return false
-		}
-
-		matched, err := regexp.MatchString(pattern, valueStr)
+		condition, err := fa.compiledCondition(pattern, ignoreCase)
 		if err != nil {
-			logrus.WithError(err).WithFields(logrus.Fields{
-				"property_key": key,
-				"pattern":      pattern,
-			}).Error("Failed to compile property pattern regex")
 			return false
 		}
 
-		if !matched {
+		if !condition.Match(value) {
 			logrus.WithFields(logrus.Fields{
 				"property_key":   key,
-				"property_value": valueStr,
-				"pattern":        pattern,
-			}).Debug("Property value does not match required pattern")
+				"property_value": value,
+				"value_type":     typeof(value),
+				"condition":      condition.String(),
+			}).Debug("Property value does not satisfy required pattern")
 			return false
 		}
 
 		logrus.WithFields(logrus.Fields{
 			"property_key":   key,
-			"property_value": valueStr,
+			"property_value": value,
 		}).Debug("Property validation passed")
 	}
 
@@ -351,6 +811,15 @@ func (fa *FunnelAnalyzer) checkRequiredProperties(eventData map[string]interface
 	return true
 }
 
+// compilePattern compiles a regex pattern, matching regardless of case when
+// ignoreCase is set
+func compilePattern(pattern string, ignoreCase bool) (*regexp.Regexp, error) {
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
 // Helper function to get type name for logging
 func typeof(v interface{}) string {
 	switch v.(type) {
@@ -1,10 +1,13 @@
 package analyzer
 
 import (
-	"github.com/parfenovvs/loglion/internal/config"
-	"github.com/parfenovvs/loglion/internal/parser"
+	"context"
 	"testing"
 	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/expr"
+	"github.com/parfenovvs/loglion/internal/parser"
 )
 
 func TestNewFunnelAnalyzer(t *testing.T) {
@@ -467,6 +470,57 @@ func TestCheckRequiredProperties(t *testing.T) {
 			},
 			wantMatch: false,
 		},
+		{
+			name: "typed_comparisons_match",
+			eventData: map[string]interface{}{
+				"amount":      12.5,
+				"retry_count": 2.0,
+				"premium":     true,
+			},
+			requiredProps: map[string]string{
+				"amount":      ">= 10",
+				"retry_count": "< 3",
+				"premium":     "== true",
+			},
+			wantMatch: true,
+		},
+		{
+			name: "typed_comparison_no_match",
+			eventData: map[string]interface{}{
+				"amount":      12.5,
+				"retry_count": 3.0,
+			},
+			requiredProps: map[string]string{
+				"amount":      ">= 10",
+				"retry_count": "< 3",
+			},
+			wantMatch: false,
+		},
+		{
+			name: "nested_property_paths",
+			eventData: map[string]interface{}{
+				"event": "checkout",
+				"properties": map[string]interface{}{
+					"screen": "cart",
+					"cart":   map[string]interface{}{"total": 25.0},
+				},
+			},
+			requiredProps: map[string]string{
+				"properties.screen":     "^cart$",
+				"properties.cart.total": "> 20",
+			},
+			wantMatch: true,
+		},
+		{
+			name: "nested_property_missing",
+			eventData: map[string]interface{}{
+				"properties": map[string]interface{}{"screen": "cart"},
+			},
+			requiredProps: map[string]string{
+				"properties.cart.total": "> 20",
+			},
+			wantMatch: false,
+		},
 		{
 			name: "invalid_regex_pattern",
 			eventData: map[string]interface{}{
@@ -491,7 +545,7 @@ func TestCheckRequiredProperties(t *testing.T) {
 				config: &config.FunnelConfig{},
 			}
 
-			result := analyzer.checkRequiredProperties(tt.eventData, tt.requiredProps)
+			result := analyzer.checkRequiredProperties(tt.eventData, tt.requiredProps, false)
 			if result != tt.wantMatch {
 				t.Errorf("checkRequiredProperties() = %v, want %v", result, tt.wantMatch)
 			}
@@ -566,3 +620,337 @@ func TestPercentageCalculation(t *testing.T) {
 		t.Errorf("Expected step2 percentage to be less than step1, got step1=%f step2=%f", result.Steps[0].Percentage, result.Steps[1].Percentage)
 	}
 }
+
+func TestFailIfMoreThan(t *testing.T) {
+	maxScreens := 2
+	cfg := &config.FunnelConfig{
+		Name: "test",
+		Steps: []config.Step{
+			{Name: "step1", EventPattern: "event1"},
+			{Name: "screen", EventPattern: "screen_view", FailIfMoreThan: &maxScreens},
+			{Name: "step3", EventPattern: "event3"},
+		},
+	}
+
+	entries := []*parser.LogEntry{
+		{Message: "event1"},
+		{Message: "screen_view"},
+		{Message: "screen_view"},
+		{Message: "screen_view"}, // exceeds the limit, attempt is abandoned
+		{Message: "event3"},
+		{Message: "event1"},
+		{Message: "screen_view"},
+		{Message: "event3"},
+	}
+
+	for _, limit := range []int{0, 5} {
+		analyzer := NewFunnelAnalyzer(cfg)
+		result := analyzer.AnalyzeFunnel(entries, limit)
+
+		if len(result.Anomalies) != 1 {
+			t.Fatalf("limit=%d: expected 1 anomaly, got %d", limit, len(result.Anomalies))
+		}
+
+		anomaly := result.Anomalies[0]
+		if anomaly.Step != "screen" || anomaly.Occurrences != 3 || anomaly.Limit != 2 || anomaly.EntryIndex != 4 {
+			t.Errorf("limit=%d: unexpected anomaly %+v", limit, anomaly)
+		}
+
+		if !result.FunnelCompleted {
+			t.Errorf("limit=%d: expected second attempt to complete the funnel", limit)
+		}
+
+		if result.Steps[2].EventCount != 1 {
+			t.Errorf("limit=%d: expected step3 count 1, got %d", limit, result.Steps[2].EventCount)
+		}
+	}
+}
+
+func TestFailIfMoreThanZero(t *testing.T) {
+	noErrors := 0
+	cfg := &config.FunnelConfig{
+		Name: "test",
+		Steps: []config.Step{
+			{Name: "start", EventPattern: "event1"},
+			{Name: "error", EventPattern: "payment_error", FailIfMoreThan: &noErrors},
+		},
+	}
+
+	entries := []*parser.LogEntry{
+		{Message: "event1"},
+		{Message: "payment_error"},
+	}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+	if len(result.Anomalies) != 1 {
+		t.Fatalf("expected 1 anomaly, got %d", len(result.Anomalies))
+	}
+	if anomaly := result.Anomalies[0]; anomaly.Step != "error" || anomaly.Occurrences != 1 || anomaly.Limit != 0 {
+		t.Errorf("unexpected anomaly %+v", anomaly)
+	}
+}
+
+func TestExcludePattern(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "test",
+		Steps: []config.Step{
+			{Name: "cart", EventPattern: "add_to_cart"},
+			{Name: "checkout", EventPattern: "checkout"},
+			{Name: "purchase", EventPattern: "^purchase$", ExcludePattern: "purchase_error"},
+		},
+	}
+
+	entries := []*parser.LogEntry{
+		{Message: "add_to_cart"},
+		{Message: "checkout"},
+		{Message: "purchase_error"}, // aborts the attempt
+		{Message: "purchase"},
+		{Message: "purchase_error"}, // no attempt waiting for purchase, ignored
+		{Message: "add_to_cart"},
+		{Message: "checkout"},
+		{Message: "purchase"},
+	}
+
+	for _, limit := range []int{0, 5} {
+		result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, limit)
+
+		if len(result.Exclusions) != 1 {
+			t.Fatalf("limit=%d: expected 1 exclusion, got %+v", limit, result.Exclusions)
+		}
+		exclusion := result.Exclusions[0]
+		if exclusion.Step != "purchase" || exclusion.Pattern != "purchase_error" || exclusion.AbortedAttempts != 1 {
+			t.Errorf("limit=%d: unexpected exclusion %+v", limit, exclusion)
+		}
+		if result.AbortedAttempts() != 1 {
+			t.Errorf("limit=%d: expected 1 aborted attempt, got %d", limit, result.AbortedAttempts())
+		}
+
+		if result.Steps[0].EventCount != 2 || result.Steps[2].EventCount != 1 {
+			t.Errorf("limit=%d: unexpected step counts %+v", limit, result.Steps)
+		}
+		if !result.FunnelCompleted {
+			t.Errorf("limit=%d: expected second attempt to complete the funnel", limit)
+		}
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	caseSensitive := false
+	cfg := &config.FunnelConfig{
+		Name:            "test",
+		CaseInsensitive: true,
+		Steps: []config.Step{
+			{Name: "login", EventPattern: "^login$", RequiredProperties: map[string]string{"method": "^email$"}},
+			{Name: "purchase", EventPattern: "^purchase$", ExcludePattern: "^logout$"},
+			{Name: "receipt", EventPattern: "^receipt$", CaseInsensitive: &caseSensitive},
+		},
+	}
+	analyzer := NewFunnelAnalyzer(cfg)
+
+	tests := []struct {
+		name  string
+		entry *parser.LogEntry
+		step  int
+		want  bool
+	}{
+		{"funnel setting applies to event pattern", &parser.LogEntry{Message: "PURCHASE"}, 1, true},
+		{"funnel setting applies to properties", &parser.LogEntry{EventData: map[string]interface{}{"event": "Login", "method": "EMAIL"}}, 0, true},
+		{"property value still has to match", &parser.LogEntry{EventData: map[string]interface{}{"event": "login", "method": "phone"}}, 0, false},
+		{"step override keeps case", &parser.LogEntry{Message: "RECEIPT"}, 2, false},
+		{"step override exact case", &parser.LogEntry{Message: "receipt"}, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := analyzer.eventMatchesStep(tt.entry, cfg.Steps[tt.step]); got != tt.want {
+				t.Errorf("eventMatchesStep() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !analyzer.eventMatchesExclusion(&parser.LogEntry{Message: "LogOut"}, 0, cfg.Steps[1]) {
+		t.Error("Expected exclude pattern to match regardless of case")
+	}
+}
+
+func TestAnyOfBranches(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "signup",
+		Steps: []config.Step{
+			{Name: "open", EventPattern: "app_open"},
+			{
+				Name:               "sign_in",
+				RequiredProperties: map[string]string{"result": "^ok$"},
+				AnyOf: []config.StepBranch{
+					{Name: "google", EventPattern: "google_signin"},
+					{EventPattern: "apple_signin"},
+				},
+			},
+			{Name: "home", EventPattern: "home_view"},
+		},
+	}
+
+	event := func(name string, props map[string]interface{}) *parser.LogEntry {
+		eventData := map[string]interface{}{"event": name}
+		for key, value := range props {
+			eventData[key] = value
+		}
+		return &parser.LogEntry{Message: name, EventData: eventData}
+	}
+
+	entries := []*parser.LogEntry{
+		event("app_open", nil),
+		event("google_signin", map[string]interface{}{"result": "error"}), // step properties apply to branches
+		event("google_signin", map[string]interface{}{"result": "ok"}),
+		event("home_view", nil),
+		event("app_open", nil),
+		event("apple_signin", map[string]interface{}{"result": "ok"}),
+		event("home_view", nil),
+		event("app_open", nil),
+		event("google_signin", map[string]interface{}{"result": "ok"}),
+		event("home_view", nil),
+	}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+
+	signIn := result.Steps[1]
+	if signIn.EventCount != 3 {
+		t.Fatalf("Expected sign_in to be reached 3 times, got %d", signIn.EventCount)
+	}
+	if len(signIn.Branches) != 2 {
+		t.Fatalf("Expected 2 branches, got %+v", signIn.Branches)
+	}
+	if signIn.Branches[0].Name != "google" || signIn.Branches[0].EventCount != 2 {
+		t.Errorf("Unexpected google branch %+v", signIn.Branches[0])
+	}
+	if signIn.Branches[1].Name != "apple_signin" || signIn.Branches[1].EventCount != 1 {
+		t.Errorf("Unexpected apple branch %+v", signIn.Branches[1])
+	}
+	if signIn.Branches[0].Percentage < 66.6 || signIn.Branches[0].Percentage > 66.7 {
+		t.Errorf("Expected google branch percentage of 66.7, got %f", signIn.Branches[0].Percentage)
+	}
+
+	if result.Steps[0].Branches != nil || result.Steps[2].Branches != nil {
+		t.Error("Steps without any_of should not report branches")
+	}
+	if result.Steps[2].EventCount != 3 {
+		t.Errorf("Expected home to be reached 3 times, got %d", result.Steps[2].EventCount)
+	}
+}
+
+func TestCompileSteps(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "checkout",
+		Steps: []config.Step{
+			{Name: "cart", EventPattern: "add_cart", ExcludePattern: "logout", RequiredProperties: map[string]string{"items": "> 0"}},
+			{
+				Name:               "pay",
+				RequiredProperties: map[string]string{"result": "^ok$"},
+				AnyOf: []config.StepBranch{
+					{EventPattern: "card_paid", RequiredProperties: map[string]string{"amount": ">= 10"}},
+					{EventPattern: "wallet_paid"},
+				},
+			},
+			{Name: "done", Expr: `event == "purchase"`},
+		},
+	}
+
+	analyzer := NewFunnelAnalyzer(cfg)
+	if len(analyzer.regexps) != 4 || len(analyzer.conditions) != 3 || len(analyzer.exprs) != 1 || len(analyzer.branchProperties) != 2 {
+		t.Fatalf("Expected the steps to be compiled when the analyzer is built, got %d regexps, %d conditions, %d expressions and %d merged branch properties",
+			len(analyzer.regexps), len(analyzer.conditions), len(analyzer.exprs), len(analyzer.branchProperties))
+	}
+
+	entries := []*parser.LogEntry{
+		{Message: "add_cart", EventData: map[string]interface{}{"event": "add_cart", "items": 2.0}},
+		{Message: "card_paid", EventData: map[string]interface{}{"event": "card_paid", "amount": 20.0, "result": "ok"}},
+		{Message: "purchase", EventData: map[string]interface{}{"event": "purchase"}},
+	}
+	result := analyzer.AnalyzeFunnel(entries, 0)
+	if result.ConversionsFound != 1 {
+		t.Errorf("Expected 1 conversion, got %d", result.ConversionsFound)
+	}
+	if len(analyzer.regexps) != 4 || len(analyzer.conditions) != 3 || len(analyzer.branchProperties) != 2 {
+		t.Error("Expected analysis to reuse the compiled steps")
+	}
+}
+
+func TestExprStep(t *testing.T) {
+	if !expr.Supported {
+		t.Skip("CEL expressions are not compiled in (-tags noexpr)")
+	}
+	cfg := &config.FunnelConfig{
+		Name: "checkout",
+		Steps: []config.Step{
+			{Name: "view", EventPattern: "product_view"},
+			{
+				Name:               "purchase",
+				Expr:               `event == "purchase" && double(props.amount) > 9.99`,
+				RequiredProperties: map[string]string{"currency": "^EUR$"},
+			},
+		},
+	}
+
+	event := func(name string, props map[string]interface{}) *parser.LogEntry {
+		eventData := map[string]interface{}{"event": name}
+		for key, value := range props {
+			eventData[key] = value
+		}
+		return &parser.LogEntry{Message: name, EventData: eventData}
+	}
+
+	entries := []*parser.LogEntry{
+		event("product_view", nil),
+		event("purchase", map[string]interface{}{"amount": 5.0, "currency": "EUR"}),
+		event("purchase", map[string]interface{}{"amount": 20.0, "currency": "USD"}), // required properties still apply
+		event("purchase", map[string]interface{}{"currency": "EUR"}),                 // missing property does not match
+		event("purchase", map[string]interface{}{"amount": "12.50", "currency": "EUR"}),
+		event("product_view", nil),
+		event("purchase", map[string]interface{}{"amount": "lots", "currency": "EUR"}),
+	}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+
+	if result.Steps[0].EventCount != 2 {
+		t.Errorf("Expected view to be reached 2 times, got %d", result.Steps[0].EventCount)
+	}
+	if result.Steps[1].EventCount != 1 {
+		t.Errorf("Expected purchase to be reached 1 time, got %d", result.Steps[1].EventCount)
+	}
+}
+
+func TestAnalyzeFunnelContext_Cancelled(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "test_funnel",
+		Steps: []config.Step{
+			{Name: "step1", EventPattern: "event1"},
+			{Name: "step2", EventPattern: "event2"},
+		},
+	}
+
+	entries := make([]*parser.LogEntry, 0, cancelCheckInterval*2)
+	for i := 0; i < cancelCheckInterval; i++ {
+		entries = append(entries, &parser.LogEntry{Message: "event1"}, &parser.LogEntry{Message: "event2"})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, limit := range []int{0, 5} {
+		result := NewFunnelAnalyzer(cfg).AnalyzeFunnelContext(ctx, entries, limit)
+		if !result.Partial {
+			t.Errorf("limit %d: expected partial result after cancellation", limit)
+		}
+		if result.TotalEventsAnalyzed != 0 {
+			t.Errorf("limit %d: expected no events analyzed, got %d", limit, result.TotalEventsAnalyzed)
+		}
+	}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnelContext(context.Background(), entries, 0)
+	if result.Partial {
+		t.Error("Expected complete result without cancellation")
+	}
+	if result.TotalEventsAnalyzed != len(entries) {
+		t.Errorf("Expected %d events analyzed, got %d", len(entries), result.TotalEventsAnalyzed)
+	}
+}
@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds of gaps between periodic events
+const (
+	// GapBetween is a gap between two matching events
+	GapBetween = "between"
+	// GapLogStart is a gap from the start of the log to the first matching event
+	GapLogStart = "log_start"
+	// GapLogEnd is a gap from the last matching event to the end of the log,
+	// when the events stopped for good
+	GapLogEnd = "log_end"
+)
+
+// GapAnalyzer finds gaps longer than a threshold between events of a
+// periodic, heartbeat-style pattern
+type GapAnalyzer struct {
+	matcher   *CountAnalyzer
+	threshold time.Duration
+}
+
+// GapResult lists the gaps between periodic events in the order they occurred
+type GapResult struct {
+	Pattern             string  `json:"pattern"`
+	ThresholdSeconds    float64 `json:"threshold_seconds"`
+	TotalEventsAnalyzed int     `json:"total_events_analyzed"`
+	MatchingEvents      int     `json:"matching_events"`
+	// UntimedEvents counts matching entries without a timestamp, which cannot
+	// be placed between others
+	UntimedEvents int `json:"untimed_events,omitempty"`
+	// LogStart and LogEnd are the earliest and latest timestamps of all
+	// entries, which bound the leading and trailing gaps
+	LogStart *time.Time `json:"log_start,omitempty"`
+	LogEnd   *time.Time `json:"log_end,omitempty"`
+	// MedianIntervalSeconds is the median time between matching events, the
+	// period the events are expected at
+	MedianIntervalSeconds float64 `json:"median_interval_seconds,omitempty"`
+	Gaps                  []Gap   `json:"gaps"`
+	// LongestGapSeconds is the longest time without a matching event, even if
+	// below the threshold
+	LongestGapSeconds float64 `json:"longest_gap_seconds"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+// Gap is a period without matching events longer than the threshold
+type Gap struct {
+	Kind            string    `json:"kind"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"duration_seconds"`
+}
+
+// NewGapAnalyzer creates an analyzer reporting gaps longer than threshold
+// between events matching eventPattern
+func NewGapAnalyzer(eventPattern string, threshold time.Duration, options CountOptions) (*GapAnalyzer, error) {
+	logrus.WithFields(logrus.Fields{
+		"pattern":   eventPattern,
+		"threshold": threshold,
+	}).Debug("Creating new gap analyzer")
+
+	if threshold <= 0 {
+		return nil, fmt.Errorf("gap threshold must be positive, got %s", threshold)
+	}
+
+	matcher, err := NewCountAnalyzerWithOptions([]string{eventPattern}, options)
+	if err != nil {
+		return nil, err
+	}
+	return &GapAnalyzer{matcher: matcher, threshold: threshold}, nil
+}
+
+func (ga *GapAnalyzer) AnalyzeGaps(entries []*parser.LogEntry) *GapResult {
+	return ga.AnalyzeGapsContext(context.Background(), entries)
+}
+
+// AnalyzeGapsContext is like AnalyzeGaps but stops when ctx is cancelled,
+// returning the gaps of the entries analyzed so far marked as partial
+func (ga *GapAnalyzer) AnalyzeGapsContext(ctx context.Context, entries []*parser.LogEntry) *GapResult {
+	pattern := ga.matcher.patterns[0]
+	logrus.WithFields(logrus.Fields{
+		"entry_count": len(entries),
+		"pattern":     pattern.Name,
+		"threshold":   ga.threshold,
+	}).Info("Starting gap analysis")
+
+	result := &GapResult{
+		Pattern:             pattern.Name,
+		ThresholdSeconds:    ga.threshold.Seconds(),
+		TotalEventsAnalyzed: len(entries),
+		Gaps:                []Gap{},
+	}
+
+	var logStart, logEnd time.Time
+	var timestamps []time.Time
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Gap analysis cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+
+		if !entry.Timestamp.IsZero() {
+			if logStart.IsZero() || entry.Timestamp.Before(logStart) {
+				logStart = entry.Timestamp
+			}
+			if logEnd.IsZero() || entry.Timestamp.After(logEnd) {
+				logEnd = entry.Timestamp
+			}
+		}
+
+		if !ga.matcher.eventMatchesPattern(entry, pattern) {
+			continue
+		}
+		result.MatchingEvents++
+		if entry.Timestamp.IsZero() {
+			result.UntimedEvents++
+			continue
+		}
+		timestamps = append(timestamps, entry.Timestamp)
+	}
+
+	if !logStart.IsZero() {
+		result.LogStart, result.LogEnd = &logStart, &logEnd
+	}
+	if len(timestamps) == 0 {
+		return result
+	}
+
+	// Entries of several log files are not necessarily in order
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i].Before(timestamps[j]) })
+
+	var longest time.Duration
+	addGap := func(kind string, start, end time.Time) {
+		duration := end.Sub(start)
+		longest = max(longest, duration)
+		if duration > ga.threshold {
+			result.Gaps = append(result.Gaps, Gap{Kind: kind, Start: start, End: end, DurationSeconds: duration.Seconds()})
+		}
+	}
+
+	addGap(GapLogStart, logStart, timestamps[0])
+	intervals := make([]float64, 0, len(timestamps)-1)
+	for i := 1; i < len(timestamps); i++ {
+		addGap(GapBetween, timestamps[i-1], timestamps[i])
+		intervals = append(intervals, timestamps[i].Sub(timestamps[i-1]).Seconds())
+	}
+	addGap(GapLogEnd, timestamps[len(timestamps)-1], logEnd)
+
+	result.LongestGapSeconds = longest.Seconds()
+	if len(intervals) > 0 {
+		sort.Float64s(intervals)
+		result.MedianIntervalSeconds = intervals[len(intervals)/2]
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"matching_events": result.MatchingEvents,
+		"gaps":            len(result.Gaps),
+		"partial":         result.Partial,
+	}).Info("Gap analysis completed")
+
+	return result
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestGapAnalyzer_Gaps(t *testing.T) {
+	analyzer, err := NewGapAnalyzer("ping", time.Minute, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewGapAnalyzer() unexpected error: %v", err)
+	}
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(minutes float64, message string) *parser.LogEntry {
+		return &parser.LogEntry{Timestamp: start.Add(time.Duration(minutes * float64(time.Minute))), Message: message}
+	}
+	entries := []*parser.LogEntry{
+		at(0, "app_start"),
+		at(2, "ping"),
+		at(2.5, "ping"),
+		at(3, "ping"),
+		at(8, "ping"),
+		at(7, "purchase"),
+		at(8.5, "ping"),
+		{Message: "ping"},
+		at(12, "app_stop"),
+	}
+
+	result := analyzer.AnalyzeGaps(entries)
+	if result.MatchingEvents != 6 || result.UntimedEvents != 1 {
+		t.Fatalf("Expected 6 matching events and 1 untimed, got %+v", result)
+	}
+
+	want := []Gap{
+		{Kind: GapLogStart, Start: start, End: start.Add(2 * time.Minute), DurationSeconds: 120},
+		{Kind: GapBetween, Start: start.Add(3 * time.Minute), End: start.Add(8 * time.Minute), DurationSeconds: 300},
+		{Kind: GapLogEnd, Start: start.Add(8*time.Minute + 30*time.Second), End: start.Add(12 * time.Minute), DurationSeconds: 210},
+	}
+	if len(result.Gaps) != len(want) {
+		t.Fatalf("Expected %d gaps, got %+v", len(want), result.Gaps)
+	}
+	for i := range want {
+		if result.Gaps[i] != want[i] {
+			t.Errorf("Gaps[%d] = %+v, want %+v", i, result.Gaps[i], want[i])
+		}
+	}
+	if result.LongestGapSeconds != 300 || result.MedianIntervalSeconds != 30 {
+		t.Errorf("Expected a longest gap of 300s and a median interval of 30s, got %v and %v", result.LongestGapSeconds, result.MedianIntervalSeconds)
+	}
+}
+
+func TestGapAnalyzer_NoMatches(t *testing.T) {
+	analyzer, err := NewGapAnalyzer("ping", time.Minute, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewGapAnalyzer() unexpected error: %v", err)
+	}
+
+	result := analyzer.AnalyzeGaps([]*parser.LogEntry{{Timestamp: time.Now(), Message: "app_start"}})
+	if result.MatchingEvents != 0 || len(result.Gaps) != 0 || result.LogStart == nil {
+		t.Errorf("Expected no gaps without matching events, got %+v", result)
+	}
+}
+
+func TestNewGapAnalyzer_InvalidThreshold(t *testing.T) {
+	if _, err := NewGapAnalyzer("ping", 0, CountOptions{}); err == nil {
+		t.Error("Expected an error for a zero threshold")
+	}
+}
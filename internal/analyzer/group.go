@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+	"context"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// GroupSummary describes a funnel analyzed per group (e.g. per user) with
+// group_by. Step counts of such a result are numbers of groups, not events.
+type GroupSummary struct {
+	GroupBy          string `json:"group_by"`
+	TotalGroups      int    `json:"total_groups"`
+	CompletedGroups  int    `json:"completed_groups"`
+	UngroupedEntries int    `json:"ungrouped_entries"`
+}
+
+// groupedEntry is a log entry together with its index in the analyzed log
+type groupedEntry struct {
+	index int
+	entry *parser.LogEntry
+}
+
+// groupState is the funnel state machine of one group
+type groupState struct {
+	attempt *funnelAttempt
+	// reached marks the steps the group reached in any attempt
+	reached []bool
+	// converted is set once an attempt of the group completed the funnel,
+	// later attempts cannot reach further
+	converted bool
+}
+
+// analyzeGroups tracks the funnel independently per value of the group_by
+// property, with one state machine per group advanced as the group's entries
+// occur in the log, so interleaved groups do not disturb each other. Each step
+// counts the groups that reached it in any attempt. With a limit, analysis
+// stops once that many groups completed the funnel.
+func (fa *FunnelAnalyzer) analyzeGroups(ctx context.Context, entries []*parser.LogEntry, limit int) *FunnelResult {
+	groupBy := fa.config.GroupBy
+	logrus.WithFields(logrus.Fields{
+		"funnel_name": fa.config.Name,
+		"group_by":    groupBy,
+		"entry_count": len(entries),
+	}).Debug("Tracking funnel per group")
+
+	groups := make(map[string]*groupState)
+	stepCounts := make([]int, len(fa.config.Steps))
+	details := fa.newStepDetails()
+	anomalies := []Anomaly{}
+	completedGroups := 0
+	ungrouped := 0
+	analyzedEntries := len(entries)
+	partial := false
+	var conversions []FunnelConversion
+
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Funnel analysis cancelled, returning partial result")
+			analyzedEntries = entryIndex
+			partial = true
+			break
+		}
+		fa.hooks.entryParsed(entryIndex, entry)
+
+		key, ok := propertyValue(entry, groupBy)
+		if !ok {
+			ungrouped++
+			continue
+		}
+
+		group, exists := groups[key]
+		if !exists {
+			group = &groupState{attempt: fa.newAttempt(details), reached: make([]bool, len(fa.config.Steps))}
+			groups[key] = group
+		}
+		if group.converted {
+			continue
+		}
+
+		anomalyCount := len(group.attempt.anomalies)
+		match, matched := group.attempt.add(entryIndex, entry)
+		anomalies = append(anomalies, group.attempt.anomalies[anomalyCount:]...)
+		if !matched {
+			continue
+		}
+		if !group.reached[match.step] {
+			group.reached[match.step] = true
+			stepCounts[match.step]++
+			details.matched(match.step, match.branch, entryIndex, entry, group.attempt.start)
+		}
+		fa.notifyStepMatched(match.step, match.branch, key, entryIndex, entry)
+		if !group.attempt.converted() {
+			continue
+		}
+
+		group.converted = true
+		completedGroups++
+		conversions = append(conversions, fa.newConversion(completedGroups, key, group.attempt.entries()))
+		fa.hooks.conversion(Conversion{
+			Number:     completedGroups,
+			Group:      key,
+			EntryIndex: entryIndex + 1,
+			Entry:      entry,
+		})
+		logrus.WithFields(logrus.Fields{
+			"group":       key,
+			"entry_index": entryIndex + 1,
+		}).Debug("Group converted")
+
+		if limit > 0 && completedGroups >= limit {
+			logrus.WithField("completed_groups", completedGroups).Debug("Target conversions reached, stopping group analysis")
+			break
+		}
+	}
+	analyzedGroups := len(groups)
+	enteredGroups := 0
+	for _, group := range groups {
+		if group.attempt.entered > 0 {
+			enteredGroups++
+		}
+	}
+
+	stepResults, dropOffs := fa.buildStepResults(stepCounts, enteredGroups, details)
+	if !partial {
+		fa.addNearMisses(stepResults, entries)
+	}
+	assertions := fa.checkAssertions(stepResults)
+
+	result := &FunnelResult{
+		FunnelName:          fa.config.Name,
+		TotalEventsAnalyzed: analyzedEntries,
+		FunnelCompleted:     completedGroups > 0,
+		EnteredFunnel:       enteredGroups,
+		ConversionsFound:    completedGroups,
+		Steps:               stepResults,
+		DropOffs:            dropOffs,
+		Anomalies:           anomalies,
+		Partial:             partial,
+		Exclusions:          fa.buildExclusions(details.exclusions),
+		Timings:             fa.buildTimings(details.timer),
+		Assertions:          assertions,
+		Conversions:         conversions,
+		Groups: &GroupSummary{
+			GroupBy:          groupBy,
+			TotalGroups:      analyzedGroups,
+			CompletedGroups:  completedGroups,
+			UngroupedEntries: ungrouped,
+		},
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"funnel_name":       result.FunnelName,
+		"group_by":          groupBy,
+		"total_groups":      analyzedGroups,
+		"completed_groups":  completedGroups,
+		"ungrouped_entries": ungrouped,
+		"partial":           partial,
+	}).Info("Grouped funnel analysis completed")
+
+	return result
+}
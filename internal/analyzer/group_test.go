@@ -0,0 +1,205 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func userEvent(event string, user interface{}) *parser.LogEntry {
+	eventData := map[string]interface{}{"event": event}
+	if user != nil {
+		eventData["user_id"] = user
+	}
+	return &parser.LogEntry{Message: event, EventData: eventData}
+}
+
+func groupedFunnelConfig() *config.FunnelConfig {
+	return &config.FunnelConfig{
+		Name:    "Checkout",
+		GroupBy: "user_id",
+		Steps: []config.Step{
+			{Name: "View", EventPattern: "view"},
+			{Name: "Cart", EventPattern: "cart"},
+			{Name: "Buy", EventPattern: "buy"},
+		},
+	}
+}
+
+func TestAnalyzeFunnel_GroupBy(t *testing.T) {
+	// Interleaved users: alice converts, bob stops at cart, carol only views.
+	// Without grouping, bob's cart would complete alice's funnel attempt.
+	entries := []*parser.LogEntry{
+		userEvent("view", "alice"),
+		userEvent("view", "bob"),
+		userEvent("cart", "bob"),
+		userEvent("view", "carol"),
+		userEvent("cart", "alice"),
+		userEvent("buy", "alice"),
+		userEvent("buy", nil),
+		userEvent("view", 42.0),
+	}
+
+	result := NewFunnelAnalyzer(groupedFunnelConfig()).AnalyzeFunnel(entries, 0)
+
+	if result.Groups == nil {
+		t.Fatal("Expected group summary for grouped funnel")
+	}
+	if result.Groups.GroupBy != "user_id" || result.Groups.TotalGroups != 4 ||
+		result.Groups.CompletedGroups != 1 || result.Groups.UngroupedEntries != 1 {
+		t.Errorf("Unexpected group summary: %+v", result.Groups)
+	}
+	if !result.FunnelCompleted {
+		t.Error("Expected funnel to be completed by one group")
+	}
+
+	expectedCounts := []int{4, 2, 1}
+	for i, expected := range expectedCounts {
+		if result.Steps[i].EventCount != expected {
+			t.Errorf("Step %s: expected %d groups, got %d", result.Steps[i].Name, expected, result.Steps[i].EventCount)
+		}
+	}
+	if result.Steps[2].Percentage != 25.0 {
+		t.Errorf("Expected 25%% of groups to convert, got %.1f%%", result.Steps[2].Percentage)
+	}
+	if len(result.DropOffs) != 2 || result.DropOffs[0].EventsLost != 2 {
+		t.Errorf("Unexpected drop-offs: %+v", result.DropOffs)
+	}
+}
+
+func TestAnalyzeFunnel_GroupByLimit(t *testing.T) {
+	entries := []*parser.LogEntry{
+		userEvent("view", "alice"),
+		userEvent("cart", "alice"),
+		userEvent("buy", "alice"),
+		userEvent("view", "bob"),
+		userEvent("cart", "bob"),
+		userEvent("buy", "bob"),
+	}
+
+	result := NewFunnelAnalyzer(groupedFunnelConfig()).AnalyzeFunnel(entries, 1)
+
+	if result.Groups.TotalGroups != 1 || result.Groups.CompletedGroups != 1 {
+		t.Errorf("Expected analysis to stop after one converted group, got %+v", result.Groups)
+	}
+}
+
+func TestAnalyzeFunnel_GroupByInterleavedLimit(t *testing.T) {
+	// alice appears first, but bob's interleaved attempt converts first
+	entries := []*parser.LogEntry{
+		userEvent("view", "alice"),
+		userEvent("view", "bob"),
+		userEvent("cart", "bob"),
+		userEvent("cart", "alice"),
+		userEvent("buy", "bob"),
+		userEvent("view", "carol"),
+		userEvent("buy", "alice"),
+	}
+
+	result := NewFunnelAnalyzer(groupedFunnelConfig()).AnalyzeFunnel(entries, 1)
+
+	if result.Groups.CompletedGroups != 1 || result.Groups.TotalGroups != 2 {
+		t.Errorf("Expected analysis to stop when bob converted with 2 groups seen, got %+v", result.Groups)
+	}
+	if len(result.Conversions) != 1 || result.Conversions[0].Group != "bob" || result.Conversions[0].EndEntry != 5 {
+		t.Errorf("Expected bob's conversion at entry 5, got %+v", result.Conversions)
+	}
+	expectedCounts := []int{2, 2, 1}
+	for i, expected := range expectedCounts {
+		if result.Steps[i].EventCount != expected {
+			t.Errorf("Step %s: expected %d groups, got %d", result.Steps[i].Name, expected, result.Steps[i].EventCount)
+		}
+	}
+}
+
+func TestAnalyzeFunnel_GroupByAnomalies(t *testing.T) {
+	cfg := groupedFunnelConfig()
+	maxViews := 1
+	cfg.Steps[0].FailIfMoreThan = &maxViews
+
+	entries := []*parser.LogEntry{
+		userEvent("view", "alice"),
+		userEvent("view", "bob"),
+		userEvent("view", "alice"),
+		userEvent("cart", "bob"),
+	}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+
+	if len(result.Anomalies) != 1 || result.Anomalies[0].EntryIndex != 3 {
+		t.Errorf("Expected one anomaly for alice at entry 3, got %+v", result.Anomalies)
+	}
+	if result.Steps[1].EventCount != 1 {
+		t.Errorf("Expected only bob to reach cart, got %d", result.Steps[1].EventCount)
+	}
+}
+
+func TestAnalyzeFunnel_GroupByExclusions(t *testing.T) {
+	cfg := groupedFunnelConfig()
+	cfg.Steps[2].ExcludePattern = "payment_error"
+
+	// bob's payment error must not abort alice's attempt
+	entries := []*parser.LogEntry{
+		userEvent("view", "alice"),
+		userEvent("view", "bob"),
+		userEvent("cart", "alice"),
+		userEvent("cart", "bob"),
+		userEvent("payment_error", "bob"),
+		userEvent("buy", "alice"),
+		userEvent("buy", "bob"),
+	}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+
+	if result.Groups.CompletedGroups != 1 {
+		t.Errorf("Expected only alice to convert, got %+v", result.Groups)
+	}
+	if result.AbortedAttempts() != 1 || result.Exclusions[0].Step != "Buy" {
+		t.Errorf("Expected one attempt aborted before Buy, got %+v", result.Exclusions)
+	}
+}
+
+func TestAnalyzeFunnel_GroupByTimings(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(entry *parser.LogEntry, seconds int) *parser.LogEntry {
+		entry.Timestamp = start.Add(time.Duration(seconds) * time.Second)
+		return entry
+	}
+
+	entries := []*parser.LogEntry{
+		at(userEvent("view", "alice"), 0),
+		at(userEvent("view", "bob"), 1),
+		at(userEvent("cart", "alice"), 5),
+		at(userEvent("cart", "bob"), 21),
+		at(userEvent("cart", "bob"), 40), // bob already reached cart, not timed again
+		at(userEvent("buy", "alice"), 60),
+	}
+
+	result := NewFunnelAnalyzer(groupedFunnelConfig()).AnalyzeFunnel(entries, 0)
+
+	if len(result.Timings) != 2 {
+		t.Fatalf("Expected timings for Cart and Buy, got %+v", result.Timings)
+	}
+	cart := result.Timings[0]
+	if cart.Samples != 2 || cart.MinSeconds != 5 || cart.MaxSeconds != 20 {
+		t.Errorf("Unexpected Cart timing %+v", cart)
+	}
+	buy := result.Timings[1]
+	if buy.Samples != 1 || buy.MedianSeconds != 60 {
+		t.Errorf("Unexpected Buy timing %+v", buy)
+	}
+}
+
+func TestAnalyzeFunnelContext_GroupByCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := NewFunnelAnalyzer(groupedFunnelConfig()).AnalyzeFunnelContext(ctx, []*parser.LogEntry{userEvent("view", "alice")}, 0)
+
+	if !result.Partial || result.Groups.TotalGroups != 0 {
+		t.Errorf("Expected empty partial result, got partial=%v groups=%+v", result.Partial, result.Groups)
+	}
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// StepHits counts the entries of a log sample matching a step on their own,
+// regardless of the order of the funnel, to find steps that never match
+type StepHits struct {
+	Step string `json:"step"`
+	// PatternHits is the number of entries matching the event pattern, any
+	// branch pattern or the expression, ignoring required properties
+	PatternHits int `json:"pattern_hits"`
+	// Hits is the number of entries matching the whole step
+	Hits       int            `json:"hits"`
+	Properties []PropertyHits `json:"properties,omitempty"`
+}
+
+// PropertyHits counts the entries matching the pattern of a step that also
+// satisfy one of its required properties
+type PropertyHits struct {
+	Property string `json:"property"`
+	Hits     int    `json:"hits"`
+}
+
+// AnalyzeStepHits matches every step and every required property of the
+// funnel against each entry
+func (fa *FunnelAnalyzer) AnalyzeStepHits(entries []*parser.LogEntry) []StepHits {
+	logrus.WithFields(logrus.Fields{
+		"funnel_name": fa.config.Name,
+		"entry_count": len(entries),
+	}).Info("Counting step hits")
+
+	hits := make([]StepHits, len(fa.config.Steps))
+	for i, step := range fa.config.Steps {
+		hits[i].Step = step.Name
+		properties := slices.Sorted(maps.Keys(step.RequiredProperties))
+		for _, property := range properties {
+			hits[i].Properties = append(hits[i].Properties, PropertyHits{Property: property})
+		}
+
+		ignoreCase := step.IgnoresCase(fa.config.CaseInsensitive)
+		for _, entry := range entries {
+			if !fa.matchesStepPattern(entry, step, ignoreCase) {
+				continue
+			}
+			hits[i].PatternHits++
+			for j, property := range properties {
+				required := map[string]string{property: step.RequiredProperties[property]}
+				if entry.EventData != nil && fa.checkRequiredProperties(entry.EventData, required, ignoreCase) {
+					hits[i].Properties[j].Hits++
+				}
+			}
+			if fa.eventMatchesStep(entry, step) {
+				hits[i].Hits++
+			}
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"step_name":    step.Name,
+			"pattern_hits": hits[i].PatternHits,
+			"hits":         hits[i].Hits,
+		}).Debug("Step hits counted")
+	}
+	return hits
+}
+
+// matchesStepPattern reports whether entry matches the event pattern, a
+// branch pattern or the expression of step, ignoring required properties
+func (fa *FunnelAnalyzer) matchesStepPattern(entry *parser.LogEntry, step config.Step, ignoreCase bool) bool {
+	if step.Expr != "" {
+		step.RequiredProperties = nil
+		return fa.eventMatchesExpr(entry, step)
+	}
+	if len(step.AnyOf) == 0 {
+		return fa.eventMatchesPattern(entry, step.Name, step.EventPattern, nil, ignoreCase)
+	}
+	for _, branch := range step.AnyOf {
+		if fa.eventMatchesPattern(entry, step.Name, branch.EventPattern, nil, branch.IgnoresCase(ignoreCase)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestFunnelStepHits(t *testing.T) {
+	funnelCfg := &config.FunnelConfig{
+		Name: "Checkout",
+		Steps: []config.Step{
+			{Name: "view", EventPattern: "^view$"},
+			{Name: "buy", EventPattern: "^purchase$", RequiredProperties: map[string]string{"amount": "> 0", "currency": "^EUR$"}},
+			{Name: "pay", AnyOf: []config.StepBranch{
+				{EventPattern: "^card_payment$"},
+				{EventPattern: "^wallet_payment$"},
+			}},
+			{Name: "refund", EventPattern: "^refund$"},
+		},
+	}
+	entries := []*parser.LogEntry{
+		{Message: "purchase", EventData: map[string]interface{}{"event": "purchase", "amount": 10.0, "currency": "EUR"}},
+		{Message: "view"},
+		{Message: "purchase", EventData: map[string]interface{}{"event": "purchase", "amount": 5.0, "currency": "USD"}},
+		{Message: "wallet", EventData: map[string]interface{}{"event": "wallet_payment"}},
+		{Message: "view"},
+		{Message: "card_payment"},
+	}
+
+	hits := NewFunnelAnalyzer(funnelCfg).AnalyzeStepHits(entries)
+	if len(hits) != 4 {
+		t.Fatalf("Expected hits for 4 steps, got %d", len(hits))
+	}
+
+	want := []struct {
+		pattern, hits int
+	}{{2, 2}, {2, 1}, {2, 2}, {0, 0}}
+	for i, w := range want {
+		if hits[i].PatternHits != w.pattern || hits[i].Hits != w.hits {
+			t.Errorf("Step %s: expected %d pattern hits and %d hits, got %d and %d", hits[i].Step, w.pattern, w.hits, hits[i].PatternHits, hits[i].Hits)
+		}
+	}
+
+	buy := hits[1].Properties
+	if len(buy) != 2 || buy[0].Property != "amount" || buy[0].Hits != 2 || buy[1].Property != "currency" || buy[1].Hits != 1 {
+		t.Errorf("Expected amount to hold for 2 entries and currency for 1, got %+v", buy)
+	}
+	if hits[0].Properties != nil {
+		t.Errorf("Expected no property hits without required properties, got %+v", hits[0].Properties)
+	}
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// Hooks are optional callbacks invoked while a funnel is analyzed, letting
+// embedders add side effects (markers, screenshots, live dashboards) without
+// changing the analyzer. Hooks run synchronously on the analysis goroutine
+// and must not modify the entries they receive.
+type Hooks struct {
+	// OnEntryParsed is called for every parsed entry handed to the analyzer
+	// with its 1-based index in the analyzed log
+	OnEntryParsed func(entryIndex int, entry *parser.LogEntry)
+	// OnStepMatched is called whenever an entry advances a funnel attempt
+	OnStepMatched func(match StepMatch)
+	// OnConversion is called whenever an attempt completes the funnel
+	OnConversion func(conversion Conversion)
+}
+
+// StepMatch describes an entry that advanced a funnel attempt
+type StepMatch struct {
+	// StepIndex is the 0-based index of the matched step
+	StepIndex int
+	Step      string
+	// Branch is the any_of branch that matched, empty for plain steps
+	Branch string
+	// Group is the group_by value of the attempt, empty for ungrouped funnels
+	Group string
+	// EntryIndex is the 1-based index of the entry in the analyzed log
+	EntryIndex int
+	Entry      *parser.LogEntry
+}
+
+// Conversion describes an attempt that completed the funnel
+type Conversion struct {
+	// Number counts conversions from 1 in the order they completed
+	Number int
+	// Group is the group_by value of the attempt, empty for ungrouped funnels
+	Group string
+	// EntryIndex is the 1-based index of the entry that matched the last step
+	EntryIndex int
+	Entry      *parser.LogEntry
+}
+
+func (h Hooks) entryParsed(entryIndex int, entry *parser.LogEntry) {
+	if h.OnEntryParsed != nil {
+		h.OnEntryParsed(entryIndex+1, entry)
+	}
+}
+
+func (h Hooks) stepMatched(match StepMatch) {
+	if h.OnStepMatched != nil {
+		h.OnStepMatched(match)
+	}
+}
+
+func (h Hooks) conversion(conversion Conversion) {
+	if h.OnConversion != nil {
+		h.OnConversion(conversion)
+	}
+}
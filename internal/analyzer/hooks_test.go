@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// recordingHooks returns hooks that record every call
+func recordingHooks(parsed *[]int, matches *[]StepMatch, conversions *[]Conversion) Hooks {
+	return Hooks{
+		OnEntryParsed: func(entryIndex int, entry *parser.LogEntry) {
+			*parsed = append(*parsed, entryIndex)
+		},
+		OnStepMatched: func(match StepMatch) {
+			*matches = append(*matches, match)
+		},
+		OnConversion: func(conversion Conversion) {
+			*conversions = append(*conversions, conversion)
+		},
+	}
+}
+
+func TestFunnelAnalyzerHooks(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "signup",
+		Steps: []config.Step{
+			{Name: "open", EventPattern: "app_open"},
+			{Name: "sign_in", AnyOf: []config.StepBranch{
+				{Name: "google", EventPattern: "google_signin"},
+				{Name: "apple", EventPattern: "apple_signin"},
+			}},
+		},
+	}
+
+	entries := []*parser.LogEntry{
+		{Message: "app_open"},
+		{Message: "apple_signin"},
+		{Message: "noise"},
+		{Message: "app_open"},
+		{Message: "google_signin"},
+	}
+
+	for _, limit := range []int{0, 5} {
+		var parsed []int
+		var matches []StepMatch
+		var conversions []Conversion
+
+		fa := NewFunnelAnalyzerWithHooks(cfg, recordingHooks(&parsed, &matches, &conversions))
+		fa.AnalyzeFunnel(entries, limit)
+
+		if len(parsed) != len(entries) || parsed[0] != 1 || parsed[4] != 5 {
+			t.Errorf("limit=%d: expected OnEntryParsed for entries 1-5, got %v", limit, parsed)
+		}
+
+		if len(matches) != 4 {
+			t.Fatalf("limit=%d: expected 4 step matches, got %+v", limit, matches)
+		}
+		if matches[1].Step != "sign_in" || matches[1].StepIndex != 1 || matches[1].Branch != "apple" || matches[1].EntryIndex != 2 {
+			t.Errorf("limit=%d: unexpected step match %+v", limit, matches[1])
+		}
+		if matches[0].Branch != "" {
+			t.Errorf("limit=%d: expected no branch for plain step, got %q", limit, matches[0].Branch)
+		}
+
+		if len(conversions) != 2 {
+			t.Fatalf("limit=%d: expected 2 conversions, got %+v", limit, conversions)
+		}
+		if conversions[1].Number != 2 || conversions[1].EntryIndex != 5 || conversions[1].Entry != entries[4] {
+			t.Errorf("limit=%d: unexpected conversion %+v", limit, conversions[1])
+		}
+	}
+}
+
+func TestFunnelAnalyzerHooks_GroupBy(t *testing.T) {
+	var parsed []int
+	var matches []StepMatch
+	var conversions []Conversion
+
+	entries := []*parser.LogEntry{
+		userEvent("view", "alice"),
+		userEvent("view", "bob"),
+		userEvent("cart", "alice"),
+		userEvent("buy", "alice"),
+	}
+
+	fa := NewFunnelAnalyzerWithHooks(groupedFunnelConfig(), recordingHooks(&parsed, &matches, &conversions))
+	fa.AnalyzeFunnel(entries, 0)
+
+	if len(parsed) != 4 {
+		t.Errorf("Expected OnEntryParsed for 4 entries, got %v", parsed)
+	}
+	// Step matches of interleaved groups are reported in log order
+	for i, match := range matches {
+		if match.EntryIndex != i+1 {
+			t.Errorf("Expected step matches in log order, got %+v", matches)
+			break
+		}
+	}
+	if len(matches) != 4 || matches[1].Group != "bob" {
+		t.Errorf("Expected bob's view as second step match, got %+v", matches)
+	}
+	if len(conversions) != 1 || conversions[0].Group != "alice" || conversions[0].EntryIndex != 4 {
+		t.Errorf("Expected alice to convert at entry 4, got %+v", conversions)
+	}
+}
+
+func TestFunnelAnalyzerWithoutHooks(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name:  "test",
+		Steps: []config.Step{{Name: "open", EventPattern: "app_open"}},
+	}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel([]*parser.LogEntry{{Message: "app_open"}}, 0)
+	if !result.FunnelCompleted {
+		t.Error("Expected funnel to be completed without hooks")
+	}
+}
@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// maxNearMisses is the number of near misses kept per step
+const maxNearMisses = 5
+
+// matchedStepReason is the reason of a near miss that matched the whole step
+const matchedStepReason = "matches the step, but not after the previous step"
+
+// NearMiss is an entry that almost matched a step no entry matched, with the
+// reason it did not, to tell a typo in a funnel config from a real drop-off
+type NearMiss struct {
+	StepSample
+	// Property is the required property the entry failed, if any
+	Property string `json:"property,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// addNearMisses looks for near misses of the steps of stepResults that no
+// entry matched
+func (fa *FunnelAnalyzer) addNearMisses(stepResults []StepResult, entries []*parser.LogEntry) {
+	for i, step := range fa.config.Steps {
+		if stepResults[i].EventCount > 0 {
+			continue
+		}
+
+		for entryIndex, entry := range entries {
+			nearMiss, found := fa.diagnoseStep(entry, step)
+			if !found {
+				continue
+			}
+			if nearMiss.Reason == matchedStepReason && step.MinOccurrences > 1 {
+				nearMiss.Reason = fmt.Sprintf("matches the step, but not %d times in an attempt after the previous step", step.MinOccurrences)
+			}
+			stepResults[i].NearMissCount++
+			if len(stepResults[i].NearMisses) < maxNearMisses {
+				nearMiss.StepSample = newStepSample(entryIndex, entry)
+				stepResults[i].NearMisses = append(stepResults[i].NearMisses, nearMiss)
+			}
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"step_name":   step.Name,
+			"near_misses": stepResults[i].NearMissCount,
+		}).Debug("Near misses of unmatched step collected")
+	}
+}
+
+// diagnoseStep reports whether entry is a near miss of step: it matches the
+// event pattern or expression but fails a required property, matches the
+// event pattern only regardless of case, or matches the whole step but never
+// after the previous step was reached
+func (fa *FunnelAnalyzer) diagnoseStep(entry *parser.LogEntry, step config.Step) (NearMiss, bool) {
+	ignoreCase := step.IgnoresCase(fa.config.CaseInsensitive)
+	if step.Expr != "" {
+		program := fa.compiledExpr(step.Expr)
+		if program == nil || !program.Match(entry) {
+			return NearMiss{}, false
+		}
+		return fa.diagnoseProperties(entry, step.RequiredProperties, ignoreCase), true
+	}
+
+	if len(step.AnyOf) == 0 {
+		return fa.diagnosePattern(entry, step.EventPattern, step.RequiredProperties, ignoreCase)
+	}
+	for i := range step.AnyOf {
+		branch := &step.AnyOf[i]
+		requiredProps := fa.mergedProperties(&step, branch)
+		if nearMiss, found := fa.diagnosePattern(entry, branch.EventPattern, requiredProps, branch.IgnoresCase(ignoreCase)); found {
+			return nearMiss, true
+		}
+	}
+	return NearMiss{}, false
+}
+
+// diagnosePattern is diagnoseStep for one event pattern and its required
+// properties
+func (fa *FunnelAnalyzer) diagnosePattern(entry *parser.LogEntry, pattern string, requiredProps map[string]string, ignoreCase bool) (NearMiss, bool) {
+	eventStr := entry.Message
+	if eventValue, exists := entry.EventData["event"]; exists {
+		str, ok := eventValue.(string)
+		if !ok {
+			return NearMiss{}, false
+		}
+		eventStr = str
+	}
+
+	eventRegex := fa.compiledPattern(pattern, ignoreCase)
+	if eventRegex == nil {
+		return NearMiss{}, false
+	}
+	if eventRegex.MatchString(eventStr) {
+		return fa.diagnoseProperties(entry, requiredProps, ignoreCase), true
+	}
+
+	if !ignoreCase {
+		if caseless := fa.compiledPattern(pattern, true); caseless != nil && caseless.MatchString(eventStr) {
+			return NearMiss{Reason: fmt.Sprintf("event '%s' matches '%s' only regardless of case", eventStr, pattern)}, true
+		}
+	}
+	return NearMiss{}, false
+}
+
+// diagnoseProperties names the first required property, in key order, that
+// the event data of entry fails. An entry satisfying all of them matched the
+// step, but never while an attempt was waiting for it.
+func (fa *FunnelAnalyzer) diagnoseProperties(entry *parser.LogEntry, requiredProps map[string]string, ignoreCase bool) NearMiss {
+	for _, key := range slices.Sorted(maps.Keys(requiredProps)) {
+		pattern := requiredProps[key]
+		value, exists := parser.LookupProperty(entry.EventData, key)
+		if !exists {
+			return NearMiss{Property: key, Reason: fmt.Sprintf("required property '%s' is missing", key)}
+		}
+
+		condition, err := fa.compiledCondition(pattern, ignoreCase)
+		if err != nil {
+			return NearMiss{Property: key, Reason: fmt.Sprintf("required property '%s' has an invalid pattern: %v", key, err)}
+		}
+		if !condition.Match(value) {
+			return NearMiss{Property: key, Reason: fmt.Sprintf("property '%s' is %v, expected %s", key, formatPropertyValue(value), condition)}
+		}
+	}
+	return NearMiss{Reason: matchedStepReason}
+}
+
+// formatPropertyValue quotes string values to tell "10" from 10
+func formatPropertyValue(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return fmt.Sprintf("%q", str)
+	}
+	return fmt.Sprintf("%v", value)
+}
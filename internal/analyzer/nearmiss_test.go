@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/expr"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestFunnelNearMisses(t *testing.T) {
+	tests := []struct {
+		name         string
+		step         config.Step
+		entries      []*parser.LogEntry
+		wantIndex    int
+		wantProperty string
+		wantReason   string
+	}{
+		{
+			name: "missing required property",
+			step: config.Step{Name: "buy", EventPattern: "^purchase$", RequiredProperties: map[string]string{"amount": "> 0"}},
+			entries: []*parser.LogEntry{
+				{Message: "view"},
+				{Message: "purchase", EventData: map[string]interface{}{"event": "purchase"}},
+			},
+			wantIndex:    2,
+			wantProperty: "amount",
+			wantReason:   "required property 'amount' is missing",
+		},
+		{
+			name: "failing required property",
+			step: config.Step{Name: "buy", EventPattern: "^purchase$", RequiredProperties: map[string]string{"amount": "> 0", "currency": "^EUR$"}},
+			entries: []*parser.LogEntry{
+				{Message: "view"},
+				{Message: "purchase", EventData: map[string]interface{}{"event": "purchase", "amount": 10.0, "currency": "usd"}},
+			},
+			wantIndex:    2,
+			wantProperty: "currency",
+			wantReason:   `property 'currency' is "usd", expected ^EUR$`,
+		},
+		{
+			name: "required property of a branch",
+			step: config.Step{Name: "buy", AnyOf: []config.StepBranch{
+				{EventPattern: "^card_payment$"},
+				{EventPattern: "^wallet_payment$", RequiredProperties: map[string]string{"verified": "== true"}},
+			}},
+			entries: []*parser.LogEntry{
+				{Message: "view"},
+				{Message: "wallet", EventData: map[string]interface{}{"event": "wallet_payment", "verified": false}},
+			},
+			wantIndex:    2,
+			wantProperty: "verified",
+			wantReason:   "property 'verified' is false, expected == true",
+		},
+		{
+			name: "matches only regardless of case",
+			step: config.Step{Name: "buy", EventPattern: "^purchase$"},
+			entries: []*parser.LogEntry{
+				{Message: "view"},
+				{Message: "Purchase"},
+			},
+			wantIndex:  2,
+			wantReason: "event 'Purchase' matches '^purchase$' only regardless of case",
+		},
+		{
+			name: "expression with failing required property",
+			step: config.Step{Name: "buy", Expr: `event == "purchase"`, RequiredProperties: map[string]string{"amount": "> 0"}},
+			entries: []*parser.LogEntry{
+				{Message: "view"},
+				{Message: "purchase", EventData: map[string]interface{}{"event": "purchase", "amount": 0.0}},
+			},
+			wantIndex:    2,
+			wantProperty: "amount",
+			wantReason:   "property 'amount' is 0, expected > 0",
+		},
+		{
+			name: "matches before the previous step",
+			step: config.Step{Name: "buy", EventPattern: "^purchase$"},
+			entries: []*parser.LogEntry{
+				{Message: "purchase"},
+				{Message: "view"},
+			},
+			wantIndex:  1,
+			wantReason: "matches the step, but not after the previous step",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.step.Expr != "" && !expr.Supported {
+				t.Skip("CEL expressions are not compiled in (-tags noexpr)")
+			}
+			cfg := &config.FunnelConfig{
+				Name:  "checkout",
+				Steps: []config.Step{{Name: "view", EventPattern: "view"}, tt.step},
+			}
+			result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(tt.entries, 0)
+
+			if result.Steps[0].NearMisses != nil {
+				t.Errorf("Expected no near misses of a matched step, got %+v", result.Steps[0].NearMisses)
+			}
+			step := result.Steps[1]
+			if step.EventCount != 0 || step.NearMissCount != 1 || len(step.NearMisses) != 1 {
+				t.Fatalf("Expected one near miss of an unmatched step, got %+v", step)
+			}
+			nearMiss := step.NearMisses[0]
+			if nearMiss.EntryIndex != tt.wantIndex {
+				t.Errorf("Expected the near miss at entry %d, got %d", tt.wantIndex, nearMiss.EntryIndex)
+			}
+			if nearMiss.Property != tt.wantProperty {
+				t.Errorf("Expected property %q, got %q", tt.wantProperty, nearMiss.Property)
+			}
+			if nearMiss.Reason != tt.wantReason {
+				t.Errorf("Expected reason %q, got %q", tt.wantReason, nearMiss.Reason)
+			}
+		})
+	}
+}
+
+func TestFunnelNearMissesLimit(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "checkout",
+		Steps: []config.Step{
+			{Name: "buy", EventPattern: "^purchase$", RequiredProperties: map[string]string{"amount": "> 0"}},
+		},
+	}
+
+	var entries []*parser.LogEntry
+	for i := 0; i < maxNearMisses+3; i++ {
+		entries = append(entries, &parser.LogEntry{
+			Message:   fmt.Sprintf("purchase %d", i),
+			EventData: map[string]interface{}{"event": "purchase", "amount": 0.0},
+		})
+	}
+	entries = append(entries, &parser.LogEntry{Message: "unrelated"})
+
+	step := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0).Steps[0]
+
+	if step.NearMissCount != maxNearMisses+3 {
+		t.Errorf("Expected %d near misses, got %d", maxNearMisses+3, step.NearMissCount)
+	}
+	if len(step.NearMisses) != maxNearMisses || step.NearMisses[0].Message != "purchase 0" {
+		t.Errorf("Expected the first %d near misses, got %+v", maxNearMisses, step.NearMisses)
+	}
+}
+
+func TestFunnelNearMissesMinOccurrences(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "checkout",
+		Steps: []config.Step{
+			{Name: "open", EventPattern: "^open$"},
+			{Name: "views", EventPattern: "^view_product$", MinOccurrences: 3},
+		},
+	}
+	entries := []*parser.LogEntry{{Message: "open"}, {Message: "view_product"}, {Message: "view_product"}}
+
+	step := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0).Steps[1]
+
+	if step.NearMissCount != 2 || step.NearMisses[0].Reason != "matches the step, but not 3 times in an attempt after the previous step" {
+		t.Errorf("Expected 2 near misses short of min_occurrences, got %+v", step.NearMisses)
+	}
+}
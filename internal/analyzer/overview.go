@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// OverviewAnalyzer summarizes a log before analysis: how much of it was
+// parsed, the time it covers and which levels, tags and events it holds
+type OverviewAnalyzer struct {
+	limit int
+}
+
+// OverviewResult is a quick health view of parsed logs
+type OverviewResult struct {
+	TotalLines    int `json:"total_lines"`
+	ParsedEntries int `json:"parsed_entries"`
+	// SkippedLines could not be parsed, not counting empty lines
+	SkippedLines int `json:"skipped_lines"`
+	EmptyLines   int `json:"empty_lines"`
+	// TotalEventsAnalyzed are the entries left after the entry filters
+	TotalEventsAnalyzed int        `json:"total_events_analyzed"`
+	FirstTimestamp      *time.Time `json:"first_timestamp,omitempty"`
+	LastTimestamp       *time.Time `json:"last_timestamp,omitempty"`
+	SpanSeconds         float64    `json:"span_seconds"`
+	// EntriesPerMinute is the rate of entries with a timestamp over the span
+	EntriesPerMinute float64 `json:"entries_per_minute"`
+	UntimedEntries   int     `json:"untimed_entries,omitempty"`
+	DistinctEvents   int     `json:"distinct_events"`
+	// Levels and Tags are ordered by count, most frequent first
+	Levels       []ValueFrequency `json:"levels"`
+	Tags         []ValueFrequency `json:"tags"`
+	DistinctTags int              `json:"distinct_tags"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+// ValueFrequency is the number of entries with one value of a field
+type ValueFrequency struct {
+	Value      string  `json:"value"`
+	Count      int     `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// NewOverviewAnalyzer creates an analyzer reporting the limit most frequent
+// tags, or all of them with a limit of 0
+func NewOverviewAnalyzer(limit int) (*OverviewAnalyzer, error) {
+	logrus.WithField("limit", limit).Debug("Creating new overview analyzer")
+
+	if limit < 0 {
+		return nil, fmt.Errorf("limit cannot be negative")
+	}
+	return &OverviewAnalyzer{limit: limit}, nil
+}
+
+func (oa *OverviewAnalyzer) AnalyzeOverview(entries []*parser.LogEntry, stats parser.ParseStats) *OverviewResult {
+	return oa.AnalyzeOverviewContext(context.Background(), entries, stats)
+}
+
+// AnalyzeOverviewContext is like AnalyzeOverview but stops when ctx is
+// cancelled, returning the result for the entries analyzed so far marked as
+// partial. stats are the statistics of the parser that produced entries.
+func (oa *OverviewAnalyzer) AnalyzeOverviewContext(ctx context.Context, entries []*parser.LogEntry, stats parser.ParseStats) *OverviewResult {
+	logrus.WithFields(logrus.Fields{
+		"entry_count": len(entries),
+		"limit":       oa.limit,
+	}).Info("Starting log overview analysis")
+
+	result := &OverviewResult{
+		TotalLines:          stats.TotalLines,
+		ParsedEntries:       stats.ParsedEntries,
+		SkippedLines:        stats.Skipped(),
+		EmptyLines:          stats.EmptyLines,
+		TotalEventsAnalyzed: len(entries),
+	}
+
+	var first, last time.Time
+	timedEntries := 0
+	levels := make(map[string]int)
+	tags := make(map[string]int)
+	events := make(map[string]bool)
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Log overview analysis cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+
+		if entry.Timestamp.IsZero() {
+			result.UntimedEntries++
+		} else {
+			if timedEntries == 0 || entry.Timestamp.Before(first) {
+				first = entry.Timestamp
+			}
+			if timedEntries == 0 || entry.Timestamp.After(last) {
+				last = entry.Timestamp
+			}
+			timedEntries++
+		}
+
+		if entry.Level != "" {
+			levels[entry.Level]++
+		}
+		if entry.Tag != "" {
+			tags[entry.Tag]++
+		}
+		events[EventName(entry)] = true
+	}
+
+	if timedEntries > 0 {
+		result.FirstTimestamp = &first
+		result.LastTimestamp = &last
+		result.SpanSeconds = last.Sub(first).Seconds()
+		if result.SpanSeconds > 0 {
+			result.EntriesPerMinute = float64(timedEntries) / (result.SpanSeconds / 60)
+		}
+	}
+
+	result.DistinctEvents = len(events)
+	result.Levels = frequencies(levels, result.TotalEventsAnalyzed)
+	result.Tags = frequencies(tags, result.TotalEventsAnalyzed)
+	result.DistinctTags = len(result.Tags)
+	if oa.limit > 0 && len(result.Tags) > oa.limit {
+		result.Tags = result.Tags[:oa.limit]
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"span_seconds":    result.SpanSeconds,
+		"distinct_events": result.DistinctEvents,
+		"distinct_tags":   result.DistinctTags,
+		"partial":         result.Partial,
+	}).Info("Log overview analysis completed")
+
+	return result
+}
+
+// frequencies orders counts by count, most frequent first, with percentages
+// of total
+func frequencies(counts map[string]int, total int) []ValueFrequency {
+	result := make([]ValueFrequency, 0, len(counts))
+	for value, count := range counts {
+		result = append(result, ValueFrequency{
+			Value:      value,
+			Count:      count,
+			Percentage: float64(count) / float64(total) * 100.0,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestNewOverviewAnalyzer(t *testing.T) {
+	if _, err := NewOverviewAnalyzer(-1); err == nil {
+		t.Error("Expected error for negative limit")
+	}
+	if _, err := NewOverviewAnalyzer(0); err != nil {
+		t.Errorf("NewOverviewAnalyzer(0) unexpected error: %v", err)
+	}
+}
+
+func TestOverviewAnalyzer_AnalyzeOverview(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	entries := []*parser.LogEntry{
+		{Timestamp: base.Add(time.Minute), Level: "I", Tag: "Analytics", EventData: map[string]interface{}{"event": "login"}},
+		{Timestamp: base, Level: "I", Tag: "Analytics", EventData: map[string]interface{}{"event": "view"}},
+		{Timestamp: base.Add(2 * time.Minute), Level: "E", Tag: "Network", Message: "timeout"},
+		{Level: "I", Tag: "chatty", Message: "view"},
+	}
+	stats := parser.ParseStats{TotalLines: 7, ParsedEntries: 5, EmptyLines: 1, UnmatchedLines: 1}
+
+	analyzer, err := NewOverviewAnalyzer(2)
+	if err != nil {
+		t.Fatalf("NewOverviewAnalyzer() unexpected error: %v", err)
+	}
+	result := analyzer.AnalyzeOverview(entries, stats)
+
+	if result.TotalLines != 7 || result.ParsedEntries != 5 || result.SkippedLines != 1 || result.EmptyLines != 1 {
+		t.Errorf("Unexpected line totals %+v", result)
+	}
+	if result.TotalEventsAnalyzed != 4 || result.DistinctEvents != 3 || result.UntimedEntries != 1 {
+		t.Errorf("Unexpected entry totals %+v", result)
+	}
+	// Timestamps need not be ordered
+	if !result.FirstTimestamp.Equal(base) || !result.LastTimestamp.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("Unexpected time range %v - %v", result.FirstTimestamp, result.LastTimestamp)
+	}
+	if result.SpanSeconds != 120 || result.EntriesPerMinute != 1.5 {
+		t.Errorf("Unexpected span %v and rate %v", result.SpanSeconds, result.EntriesPerMinute)
+	}
+
+	if len(result.Levels) != 2 || result.Levels[0] != (ValueFrequency{Value: "I", Count: 3, Percentage: 75}) {
+		t.Errorf("Unexpected levels %+v", result.Levels)
+	}
+	// Tags are limited and ties are ordered by name
+	if result.DistinctTags != 3 || len(result.Tags) != 2 || result.Tags[0].Value != "Analytics" || result.Tags[1].Value != "Network" {
+		t.Errorf("Unexpected tags %+v (%d distinct)", result.Tags, result.DistinctTags)
+	}
+}
+
+func TestOverviewAnalyzer_WithoutTimestamps(t *testing.T) {
+	analyzer, err := NewOverviewAnalyzer(0)
+	if err != nil {
+		t.Fatalf("NewOverviewAnalyzer() unexpected error: %v", err)
+	}
+
+	result := analyzer.AnalyzeOverview([]*parser.LogEntry{{Message: "login"}}, parser.ParseStats{TotalLines: 1, ParsedEntries: 1})
+	if result.FirstTimestamp != nil || result.SpanSeconds != 0 || result.EntriesPerMinute != 0 || result.UntimedEntries != 1 {
+		t.Errorf("Expected no time range, got %+v", result)
+	}
+	if len(result.Levels) != 0 || len(result.Tags) != 0 {
+		t.Errorf("Expected no levels or tags, got %+v", result)
+	}
+}
+
+func TestOverviewAnalyzer_Cancelled(t *testing.T) {
+	analyzer, err := NewOverviewAnalyzer(0)
+	if err != nil {
+		t.Fatalf("NewOverviewAnalyzer() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := analyzer.AnalyzeOverviewContext(ctx, []*parser.LogEntry{{Message: "login"}}, parser.ParseStats{TotalLines: 1, ParsedEntries: 1})
+	if !result.Partial || result.TotalEventsAnalyzed != 0 || result.TotalLines != 1 {
+		t.Errorf("Expected partial result, got %+v", result)
+	}
+}
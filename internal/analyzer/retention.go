@@ -0,0 +1,263 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultRetentionUserKey is the event data property that identifies users
+// when no other is configured
+const DefaultRetentionUserKey = "user_id"
+
+// RetentionAnalyzer computes how many users return in the periods after a
+// cohort-defining event, such as the days after signing up
+type RetentionAnalyzer struct {
+	matcher *CountAnalyzer
+	options RetentionOptions
+}
+
+// RetentionOptions configures how users are identified and the periods
+// retention is computed for
+type RetentionOptions struct {
+	// UserKey is the event data property that identifies a user
+	UserKey string
+	// Period is the length of the periods, such as a day or an hour
+	Period time.Duration
+	// Periods is the number of periods after period 0 retention is computed
+	// for
+	Periods int
+}
+
+// RetentionResult holds the retention curve of all users and of each cohort
+type RetentionResult struct {
+	CohortPattern       string  `json:"cohort_pattern"`
+	ReturnPattern       string  `json:"return_pattern"`
+	UserKey             string  `json:"user_key"`
+	PeriodSeconds       float64 `json:"period_seconds"`
+	TotalEventsAnalyzed int     `json:"total_events_analyzed"`
+	// Users counts the users with a cohort event
+	Users int `json:"users"`
+	// UnkeyedEvents counts matching entries without the user key, which
+	// cannot be attributed to a user
+	UnkeyedEvents int `json:"unkeyed_events,omitempty"`
+	// UntimedEvents counts matching entries without a timestamp, which cannot
+	// be placed in a period
+	UntimedEvents int               `json:"untimed_events,omitempty"`
+	Periods       []RetentionPeriod `json:"periods"`
+	// Cohorts break the retention down by the period users had their cohort
+	// event in
+	Cohorts []RetentionCohort `json:"cohorts"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+// RetentionPeriod is the share of users who returned in a period after their
+// cohort event. Period N spans from N to N+1 period lengths after it.
+type RetentionPeriod struct {
+	Period int `json:"period"`
+	// EligibleUsers counts the users for whom the log reaches the period, as
+	// users cannot be seen returning in periods after the end of the log
+	EligibleUsers int     `json:"eligible_users"`
+	RetainedUsers int     `json:"retained_users"`
+	Percentage    float64 `json:"percentage"`
+}
+
+// RetentionCohort holds the retention of the users whose cohort event fell in
+// the period starting at Start
+type RetentionCohort struct {
+	Start   time.Time         `json:"start"`
+	Users   int               `json:"users"`
+	Periods []RetentionPeriod `json:"periods"`
+}
+
+// retentionUser is the cohort event and the return events of one user
+type retentionUser struct {
+	joined time.Time
+	// joinedIndex is the index of the cohort entry, which does not count as a
+	// return when the patterns match the same event
+	joinedIndex int
+	returns     []retentionEvent
+}
+
+// retentionEvent is a timed return event and the index of its entry
+type retentionEvent struct {
+	index     int
+	timestamp time.Time
+}
+
+// NewRetentionAnalyzer creates an analyzer computing the retention of users
+// returning with an event matching returnPattern after an event matching
+// cohortPattern
+func NewRetentionAnalyzer(cohortPattern, returnPattern string, options RetentionOptions, countOptions CountOptions) (*RetentionAnalyzer, error) {
+	logrus.WithFields(logrus.Fields{
+		"cohort_pattern": cohortPattern,
+		"return_pattern": returnPattern,
+		"user_key":       options.UserKey,
+		"period":         options.Period,
+		"periods":        options.Periods,
+	}).Debug("Creating new retention analyzer")
+
+	if options.UserKey == "" {
+		options.UserKey = DefaultRetentionUserKey
+	}
+	if options.Period <= 0 {
+		return nil, fmt.Errorf("retention period must be positive, got %s", options.Period)
+	}
+	if options.Periods < 1 {
+		return nil, fmt.Errorf("number of periods must be at least 1, got %d", options.Periods)
+	}
+
+	matcher, err := NewCountAnalyzerWithOptions([]string{cohortPattern, returnPattern}, countOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &RetentionAnalyzer{matcher: matcher, options: options}, nil
+}
+
+func (ra *RetentionAnalyzer) AnalyzeRetention(entries []*parser.LogEntry) *RetentionResult {
+	return ra.AnalyzeRetentionContext(context.Background(), entries)
+}
+
+// AnalyzeRetentionContext is like AnalyzeRetention but stops when ctx is
+// cancelled, returning the retention of the entries analyzed so far marked as
+// partial
+func (ra *RetentionAnalyzer) AnalyzeRetentionContext(ctx context.Context, entries []*parser.LogEntry) *RetentionResult {
+	cohortPattern, returnPattern := ra.matcher.patterns[0], ra.matcher.patterns[1]
+	logrus.WithFields(logrus.Fields{
+		"entry_count":    len(entries),
+		"cohort_pattern": cohortPattern.Name,
+		"return_pattern": returnPattern.Name,
+		"period":         ra.options.Period,
+	}).Info("Starting retention analysis")
+
+	result := &RetentionResult{
+		CohortPattern:       cohortPattern.Name,
+		ReturnPattern:       returnPattern.Name,
+		UserKey:             ra.options.UserKey,
+		PeriodSeconds:       ra.options.Period.Seconds(),
+		TotalEventsAnalyzed: len(entries),
+		Periods:             []RetentionPeriod{},
+		Cohorts:             []RetentionCohort{},
+	}
+
+	// Entries may come from several log files out of order, so the earliest
+	// cohort event of each user is kept and returns are placed afterwards
+	var logEnd time.Time
+	users := make(map[string]*retentionUser)
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Retention analysis cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+
+		if entry.Timestamp.After(logEnd) {
+			logEnd = entry.Timestamp
+		}
+
+		joins := ra.matcher.eventMatchesPattern(entry, cohortPattern)
+		returns := ra.matcher.eventMatchesPattern(entry, returnPattern)
+		if !joins && !returns {
+			continue
+		}
+		userID, ok := propertyValue(entry, ra.options.UserKey)
+		if !ok {
+			result.UnkeyedEvents++
+			continue
+		}
+		if entry.Timestamp.IsZero() {
+			result.UntimedEvents++
+			continue
+		}
+
+		user := users[userID]
+		if user == nil {
+			user = &retentionUser{joinedIndex: -1}
+			users[userID] = user
+		}
+		if joins && (user.joined.IsZero() || entry.Timestamp.Before(user.joined)) {
+			user.joined = entry.Timestamp
+			user.joinedIndex = entryIndex
+		}
+		if returns {
+			user.returns = append(user.returns, retentionEvent{index: entryIndex, timestamp: entry.Timestamp})
+		}
+	}
+
+	period := ra.options.Period
+	overall := make([]RetentionPeriod, ra.options.Periods+1)
+	cohorts := make(map[time.Time][]RetentionPeriod)
+	cohortUsers := make(map[time.Time]int)
+	for _, user := range users {
+		if user.joined.IsZero() {
+			continue
+		}
+		result.Users++
+
+		cohortStart := user.joined.Truncate(period)
+		cohort := cohorts[cohortStart]
+		if cohort == nil {
+			cohort = make([]RetentionPeriod, ra.options.Periods+1)
+			cohorts[cohortStart] = cohort
+		}
+		cohortUsers[cohortStart]++
+
+		returned := make([]bool, len(overall))
+		for _, ret := range user.returns {
+			if ret.index == user.joinedIndex || ret.timestamp.Before(user.joined) {
+				continue
+			}
+			if p := int(ret.timestamp.Sub(user.joined) / period); p < len(returned) {
+				returned[p] = true
+			}
+		}
+		for p := range overall {
+			if user.joined.Add(time.Duration(p) * period).After(logEnd) {
+				break
+			}
+			overall[p].EligibleUsers++
+			cohort[p].EligibleUsers++
+			if returned[p] {
+				overall[p].RetainedUsers++
+				cohort[p].RetainedUsers++
+			}
+		}
+	}
+
+	result.Periods = finishRetentionPeriods(overall)
+	for start, periods := range cohorts {
+		result.Cohorts = append(result.Cohorts, RetentionCohort{
+			Start:   start,
+			Users:   cohortUsers[start],
+			Periods: finishRetentionPeriods(periods),
+		})
+	}
+	sort.Slice(result.Cohorts, func(i, j int) bool {
+		return result.Cohorts[i].Start.Before(result.Cohorts[j].Start)
+	})
+
+	logrus.WithFields(logrus.Fields{
+		"users":   result.Users,
+		"cohorts": len(result.Cohorts),
+		"partial": result.Partial,
+	}).Info("Retention analysis completed")
+
+	return result
+}
+
+// finishRetentionPeriods numbers the periods and computes their percentages
+func finishRetentionPeriods(periods []RetentionPeriod) []RetentionPeriod {
+	for i := range periods {
+		periods[i].Period = i
+		if periods[i].EligibleUsers > 0 {
+			periods[i].Percentage = float64(periods[i].RetainedUsers) / float64(periods[i].EligibleUsers) * 100
+		}
+	}
+	return periods
+}
@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestRetentionAnalyzer_Retention(t *testing.T) {
+	analyzer, err := NewRetentionAnalyzer("signup", "app_open", RetentionOptions{Period: 24 * time.Hour, Periods: 2}, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewRetentionAnalyzer() unexpected error: %v", err)
+	}
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(hours float64, message, user string) *parser.LogEntry {
+		entry := &parser.LogEntry{Timestamp: start.Add(time.Duration(hours * float64(time.Hour))), Message: message}
+		if user != "" {
+			entry.EventData = map[string]interface{}{"user_id": user}
+		}
+		return entry
+	}
+	entries := []*parser.LogEntry{
+		at(0, "signup", "alice"),
+		at(2, "app_open", "alice"),
+		at(1, "signup", "bob"),
+		at(20, "signup", "carol"),
+		at(26, "app_open", "alice"),
+		at(30, "app_open", "bob"),
+		at(40, "app_open", "dave"),
+		at(50, "app_open", ""),
+		{Message: "app_open", EventData: map[string]interface{}{"user_id": "bob"}},
+		at(52, "app_open", "alice"),
+		at(55, "heartbeat", ""),
+	}
+
+	result := analyzer.AnalyzeRetention(entries)
+	if result.Users != 3 || result.UnkeyedEvents != 1 || result.UntimedEvents != 1 {
+		t.Fatalf("Expected 3 users, 1 unkeyed and 1 untimed event, got %+v", result)
+	}
+
+	// The log ends 55h in, so carol, who signed up 20h in, is not eligible for
+	// day 2
+	percentage := func(retained, eligible int) float64 {
+		return float64(retained) / float64(eligible) * 100
+	}
+	want := []RetentionPeriod{
+		{Period: 0, EligibleUsers: 3, RetainedUsers: 1, Percentage: percentage(1, 3)},
+		{Period: 1, EligibleUsers: 3, RetainedUsers: 2, Percentage: percentage(2, 3)},
+		{Period: 2, EligibleUsers: 2, RetainedUsers: 1, Percentage: 50},
+	}
+	if len(result.Periods) != len(want) {
+		t.Fatalf("Expected %d periods, got %+v", len(want), result.Periods)
+	}
+	for i := range want {
+		if result.Periods[i] != want[i] {
+			t.Errorf("Periods[%d] = %+v, want %+v", i, result.Periods[i], want[i])
+		}
+	}
+
+	// carol signed up on the next day
+	day := start.Truncate(24 * time.Hour)
+	if len(result.Cohorts) != 2 || !result.Cohorts[0].Start.Equal(day) || result.Cohorts[0].Users != 2 ||
+		!result.Cohorts[1].Start.Equal(day.Add(24*time.Hour)) || result.Cohorts[1].Users != 1 {
+		t.Fatalf("Expected daily cohorts of 2 and 1 users, got %+v", result.Cohorts)
+	}
+	if result.Cohorts[0].Periods[1].Percentage != 100 {
+		t.Errorf("Expected the first cohort to fully return on day 1, got %+v", result.Cohorts[0].Periods[1])
+	}
+}
+
+func TestRetentionAnalyzer_Cohorts(t *testing.T) {
+	analyzer, err := NewRetentionAnalyzer("open", "open", RetentionOptions{UserKey: "device", Period: time.Hour, Periods: 1}, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewRetentionAnalyzer() unexpected error: %v", err)
+	}
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int, device string) *parser.LogEntry {
+		return &parser.LogEntry{
+			Timestamp: start.Add(time.Duration(minutes) * time.Minute),
+			Message:   "open",
+			EventData: map[string]interface{}{"device": device},
+		}
+	}
+	entries := []*parser.LogEntry{
+		at(10, "a"),
+		at(80, "a"),
+		at(70, "b"),
+		at(150, "c"),
+	}
+
+	result := analyzer.AnalyzeRetention(entries)
+	if result.UserKey != "device" || result.Users != 3 {
+		t.Fatalf("Expected 3 devices, got %+v", result)
+	}
+	// The cohort event itself is not a return, so only a returned in hour 1
+	if len(result.Cohorts) != 3 {
+		t.Fatalf("Expected 3 hourly cohorts, got %+v", result.Cohorts)
+	}
+	first := result.Cohorts[0]
+	if !first.Start.Equal(start) || first.Periods[0].RetainedUsers != 0 || first.Periods[1].RetainedUsers != 1 {
+		t.Errorf("Expected the first cohort to start at %v and return in hour 1, got %+v", start, first)
+	}
+	if last := result.Cohorts[2]; last.Periods[1].EligibleUsers != 0 {
+		t.Errorf("Expected the last cohort not to reach hour 1, got %+v", last)
+	}
+}
+
+func TestRetentionAnalyzer_Cancelled(t *testing.T) {
+	analyzer, err := NewRetentionAnalyzer("signup", "app_open", RetentionOptions{Period: time.Hour, Periods: 1}, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewRetentionAnalyzer() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := analyzer.AnalyzeRetentionContext(ctx, []*parser.LogEntry{{Message: "signup"}})
+	if !result.Partial || result.TotalEventsAnalyzed != 0 {
+		t.Errorf("Expected a partial result without analyzed events, got %+v", result)
+	}
+}
+
+func TestNewRetentionAnalyzer_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options RetentionOptions
+	}{
+		{"zero period", RetentionOptions{Periods: 1}},
+		{"no periods", RetentionOptions{Period: time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRetentionAnalyzer("signup", "app_open", tt.options, CountOptions{}); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// StepSample is an entry that matched a funnel step, kept to show which log
+// lines a step count is made of
+type StepSample struct {
+	// EntryIndex is the 1-based position of the entry among the analyzed entries
+	EntryIndex int        `json:"entry_index"`
+	Timestamp  *time.Time `json:"timestamp,omitempty"`
+	Message    string     `json:"message"`
+}
+
+// sampleCollector keeps the first and the last limit samples added to it
+type sampleCollector struct {
+	limit int
+	first []StepSample
+	// last is a ring buffer of the latest samples after first is full,
+	// starting at next once it is full too
+	last []StepSample
+	next int
+}
+
+// newStepSample creates the sample of the entry at entryIndex
+func newStepSample(entryIndex int, entry *parser.LogEntry) StepSample {
+	sample := StepSample{EntryIndex: entryIndex + 1, Message: entry.Message}
+	if !entry.Timestamp.IsZero() {
+		timestamp := entry.Timestamp
+		sample.Timestamp = &timestamp
+	}
+	return sample
+}
+
+func (c *sampleCollector) add(entryIndex int, entry *parser.LogEntry) {
+	sample := newStepSample(entryIndex, entry)
+	switch {
+	case len(c.first) < c.limit:
+		c.first = append(c.first, sample)
+	case len(c.last) < c.limit:
+		c.last = append(c.last, sample)
+	default:
+		c.last[c.next] = sample
+		c.next = (c.next + 1) % c.limit
+	}
+}
+
+// samples returns the kept samples in log order
+func (c *sampleCollector) samples() []StepSample {
+	samples := make([]StepSample, 0, len(c.first)+len(c.last))
+	samples = append(samples, c.first...)
+	samples = append(samples, c.last[c.next:]...)
+	return append(samples, c.last[:c.next]...)
+}
@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestSampleCollector(t *testing.T) {
+	tests := []struct {
+		name  string
+		added int
+		want  []int
+	}{
+		{name: "fewer than the limit", added: 3, want: []int{1, 2, 3}},
+		{name: "first and last overlap", added: 5, want: []int{1, 2, 3, 4, 5}},
+		{name: "first and last", added: 6, want: []int{1, 2, 3, 4, 5, 6}},
+		{name: "matches in between are dropped", added: 10, want: []int{1, 2, 3, 8, 9, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &sampleCollector{limit: 3}
+			for i := 0; i < tt.added; i++ {
+				collector.add(i, &parser.LogEntry{Message: fmt.Sprintf("entry %d", i+1)})
+			}
+
+			var got []int
+			for _, sample := range collector.samples() {
+				got = append(got, sample.EntryIndex)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("samples() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFunnelSamples(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	cfg := &config.FunnelConfig{
+		Name: "checkout",
+		Steps: []config.Step{
+			{Name: "view", EventPattern: "view"},
+			{Name: "buy", EventPattern: "buy"},
+		},
+	}
+
+	var entries []*parser.LogEntry
+	for i := 0; i < 4; i++ {
+		entries = append(entries,
+			&parser.LogEntry{Timestamp: base.Add(time.Duration(i) * time.Minute), Message: fmt.Sprintf("view %d", i)},
+			&parser.LogEntry{Message: fmt.Sprintf("buy %d", i)},
+		)
+	}
+
+	result := NewFunnelAnalyzerWithOptions(cfg, FunnelOptions{Samples: 1}).AnalyzeFunnel(entries, 0)
+
+	view := result.Steps[0].Samples
+	if len(view) != 2 || view[0].EntryIndex != 1 || view[0].Message != "view 0" || view[1].EntryIndex != 7 || view[1].Message != "view 3" {
+		t.Errorf("Unexpected view samples %+v", view)
+	}
+	if view[0].Timestamp == nil || !view[0].Timestamp.Equal(base) {
+		t.Errorf("Expected the timestamp of the first sample, got %v", view[0].Timestamp)
+	}
+	if buy := result.Steps[1].Samples; len(buy) != 2 || buy[1].Timestamp != nil {
+		t.Errorf("Unexpected buy samples %+v", buy)
+	}
+
+	// No samples unless requested
+	if result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0); result.Steps[0].Samples != nil {
+		t.Errorf("Expected no samples by default, got %+v", result.Steps[0].Samples)
+	}
+}
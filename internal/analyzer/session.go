@@ -0,0 +1,195 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// SessionAnalyzer splits log entries into sessions by a session key property
+// and an idle timeout
+type SessionAnalyzer struct {
+	sessionKey  string
+	idleTimeout time.Duration
+}
+
+// SessionResult summarizes the sessions found in a log
+type SessionResult struct {
+	TotalEventsAnalyzed int    `json:"total_events_analyzed"`
+	SessionKey          string `json:"session_key,omitempty"`
+	// IdleTimeoutSeconds is the gap between events that starts a new session, 0 if disabled
+	IdleTimeoutSeconds float64 `json:"idle_timeout_seconds"`
+	SessionCount       int     `json:"session_count"`
+	// IdleSplits counts sessions started because the previous one timed out
+	IdleSplits int `json:"idle_splits"`
+	// UnkeyedEntries counts entries without the session key property
+	UnkeyedEntries int `json:"unkeyed_entries,omitempty"`
+	// Durations are session lengths in seconds, for sessions with timestamps
+	Durations *ValueStats `json:"duration_seconds,omitempty"`
+	// EventsPerSession is the distribution of session sizes
+	EventsPerSession *ValueStats `json:"events_per_session,omitempty"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+type session struct {
+	start  time.Time
+	last   time.Time
+	events int
+}
+
+// sessionTracker assigns entries, in log order, to sessions
+type sessionTracker struct {
+	analyzer   *SessionAnalyzer
+	keyOrder   []string
+	open       map[string]*session
+	closed     []*session
+	idleSplits int
+	unkeyed    int
+}
+
+// NewSessionAnalyzer creates a session analyzer. With an empty sessionKey all
+// entries belong to one stream; a zero idleTimeout never splits sessions.
+func NewSessionAnalyzer(sessionKey string, idleTimeout time.Duration) (*SessionAnalyzer, error) {
+	logrus.WithFields(logrus.Fields{
+		"session_key":  sessionKey,
+		"idle_timeout": idleTimeout,
+	}).Debug("Creating new session analyzer")
+
+	if idleTimeout < 0 {
+		return nil, fmt.Errorf("idle timeout cannot be negative")
+	}
+
+	return &SessionAnalyzer{
+		sessionKey:  sessionKey,
+		idleTimeout: idleTimeout,
+	}, nil
+}
+
+func (sa *SessionAnalyzer) AnalyzeSessions(entries []*parser.LogEntry) *SessionResult {
+	return sa.AnalyzeSessionsContext(context.Background(), entries)
+}
+
+// AnalyzeSessionsContext is like AnalyzeSessions but stops when ctx is
+// cancelled, returning the result for the entries analyzed so far marked as partial
+func (sa *SessionAnalyzer) AnalyzeSessionsContext(ctx context.Context, entries []*parser.LogEntry) *SessionResult {
+	logrus.WithFields(logrus.Fields{
+		"entry_count":  len(entries),
+		"session_key":  sa.sessionKey,
+		"idle_timeout": sa.idleTimeout,
+	}).Info("Starting session analysis")
+
+	result := &SessionResult{
+		TotalEventsAnalyzed: len(entries),
+		SessionKey:          sa.sessionKey,
+		IdleTimeoutSeconds:  sa.idleTimeout.Seconds(),
+	}
+
+	tracker := sa.newTracker()
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Session analysis cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+		tracker.add(entryIndex, entry)
+	}
+
+	sessions := tracker.sessions()
+	result.IdleSplits = tracker.idleSplits
+	result.UnkeyedEntries = tracker.unkeyed
+
+	var durations, sizes []float64
+	for _, s := range sessions {
+		sizes = append(sizes, float64(s.events))
+		if !s.start.IsZero() && !s.last.IsZero() {
+			durations = append(durations, s.last.Sub(s.start).Seconds())
+		}
+	}
+
+	result.SessionCount = len(sessions)
+	result.Durations = computeValueStats(durations)
+	result.EventsPerSession = computeValueStats(sizes)
+
+	logrus.WithFields(logrus.Fields{
+		"session_count":   result.SessionCount,
+		"idle_splits":     result.IdleSplits,
+		"unkeyed_entries": result.UnkeyedEntries,
+		"partial":         result.Partial,
+	}).Info("Session analysis completed")
+
+	return result
+}
+
+func (sa *SessionAnalyzer) newTracker() *sessionTracker {
+	return &sessionTracker{
+		analyzer: sa,
+		open:     make(map[string]*session),
+	}
+}
+
+// add assigns entry to the session of its key, starting a new session when
+// the key is seen for the first time or its session timed out. It returns
+// nil for entries without the session key.
+func (t *sessionTracker) add(entryIndex int, entry *parser.LogEntry) *session {
+	key := ""
+	if t.analyzer.sessionKey != "" {
+		value, ok := propertyValue(entry, t.analyzer.sessionKey)
+		if !ok {
+			t.unkeyed++
+			return nil
+		}
+		key = value
+	}
+
+	current, exists := t.open[key]
+	if !exists {
+		t.keyOrder = append(t.keyOrder, key)
+	} else if t.analyzer.timedOut(current, entry.Timestamp) {
+		logrus.WithFields(logrus.Fields{
+			"entry_index": entryIndex + 1,
+			"session_key": key,
+			"idle":        entry.Timestamp.Sub(current.last),
+		}).Debug("Session idle timeout exceeded, starting new session")
+		t.closed = append(t.closed, current)
+		t.idleSplits++
+		current = nil
+	}
+
+	if current == nil {
+		current = &session{start: entry.Timestamp}
+		t.open[key] = current
+	}
+	current.events++
+	if !entry.Timestamp.IsZero() {
+		if current.start.IsZero() {
+			current.start = entry.Timestamp
+		}
+		current.last = entry.Timestamp
+	}
+	return current
+}
+
+// sessions returns the sessions that timed out followed by the ones still
+// open, in order of the first appearance of their key
+func (t *sessionTracker) sessions() []*session {
+	sessions := append([]*session{}, t.closed...)
+	for _, key := range t.keyOrder {
+		sessions = append(sessions, t.open[key])
+	}
+	return sessions
+}
+
+// timedOut reports whether an event at timestamp comes after the session was
+// idle for longer than the idle timeout. Entries without timestamps never
+// split sessions.
+func (sa *SessionAnalyzer) timedOut(s *session, timestamp time.Time) bool {
+	if sa.idleTimeout == 0 || timestamp.IsZero() || s.last.IsZero() {
+		return false
+	}
+	return timestamp.Sub(s.last) > sa.idleTimeout
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestNewSessionAnalyzer(t *testing.T) {
+	if _, err := NewSessionAnalyzer("session_id", -time.Second); err == nil {
+		t.Error("Expected error for negative idle timeout")
+	}
+
+	sa, err := NewSessionAnalyzer("session_id", time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sa.sessionKey != "session_id" || sa.idleTimeout != time.Minute {
+		t.Errorf("Unexpected analyzer %+v", sa)
+	}
+}
+
+func TestAnalyzeSessions(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	event := func(user interface{}, minutes int) *parser.LogEntry {
+		entry := userEvent("view", user)
+		entry.Timestamp = start.Add(time.Duration(minutes) * time.Minute)
+		return entry
+	}
+
+	entries := []*parser.LogEntry{
+		event("alice", 0),
+		event("bob", 1),
+		event("alice", 5),
+		event("alice", 10),
+		event("bob", 2),
+		event(nil, 3),
+		event("alice", 60), // idle for 50 minutes, new session
+		event("alice", 61),
+	}
+
+	sa, _ := NewSessionAnalyzer("user_id", 30*time.Minute)
+	result := sa.AnalyzeSessions(entries)
+
+	if result.SessionCount != 3 || result.IdleSplits != 1 || result.UnkeyedEntries != 1 {
+		t.Errorf("Unexpected session summary %+v", result)
+	}
+	if result.IdleTimeoutSeconds != 1800 {
+		t.Errorf("Expected idle timeout of 1800 seconds, got %v", result.IdleTimeoutSeconds)
+	}
+
+	// Sessions: alice 0-10 (3 events), bob 1-2 (2 events), alice 60-61 (2 events)
+	if result.Durations == nil || result.Durations.Min != 60 || result.Durations.Max != 600 {
+		t.Errorf("Unexpected duration stats %+v", result.Durations)
+	}
+	if result.EventsPerSession == nil || result.EventsPerSession.Samples != 3 ||
+		result.EventsPerSession.Min != 2 || result.EventsPerSession.Max != 3 {
+		t.Errorf("Unexpected events per session %+v", result.EventsPerSession)
+	}
+}
+
+func TestAnalyzeSessions_WholeLog(t *testing.T) {
+	entries := []*parser.LogEntry{
+		{Message: "open"},
+		{Message: "view"},
+		{Message: "close"},
+	}
+
+	sa, _ := NewSessionAnalyzer("", time.Minute)
+	result := sa.AnalyzeSessions(entries)
+
+	if result.SessionCount != 1 || result.IdleSplits != 0 {
+		t.Errorf("Expected one session without timestamps, got %+v", result)
+	}
+	if result.Durations != nil {
+		t.Errorf("Expected no durations without timestamps, got %+v", result.Durations)
+	}
+	if result.EventsPerSession.Max != 3 {
+		t.Errorf("Expected 3 events in the session, got %+v", result.EventsPerSession)
+	}
+}
+
+func TestAnalyzeSessionsContext_Cancelled(t *testing.T) {
+	entries := []*parser.LogEntry{{Message: "open"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sa, _ := NewSessionAnalyzer("", 0)
+	result := sa.AnalyzeSessionsContext(ctx, entries)
+
+	if !result.Partial || result.TotalEventsAnalyzed != 0 || result.SessionCount != 0 {
+		t.Errorf("Expected empty partial result, got %+v", result)
+	}
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+)
+
+// histogramBuckets is the number of equal-width buckets used for value histograms
+const histogramBuckets = 10
+
+// ValueStats summarizes numeric values captured from matching events
+type ValueStats struct {
+	Samples   int               `json:"samples"`
+	Min       float64           `json:"min"`
+	Max       float64           `json:"max"`
+	Avg       float64           `json:"avg"`
+	P95       float64           `json:"p95"`
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+// HistogramBucket counts values in the range [From, To)
+type HistogramBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// computeValueStats returns summary statistics for values, or nil if there are none
+func computeValueStats(values []float64) *ValueStats {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return &ValueStats{
+		Samples:   len(sorted),
+		Min:       sorted[0],
+		Max:       sorted[len(sorted)-1],
+		Avg:       sum / float64(len(sorted)),
+		P95:       percentile(sorted, 95),
+		Histogram: buildHistogram(sorted, histogramBuckets),
+	}
+}
+
+// percentile returns the p-th percentile of sorted values using the nearest-rank method
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100.0 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// buildHistogram splits the range of sorted values into equal-width buckets.
+// The last bucket includes the maximum value.
+func buildHistogram(sorted []float64, buckets int) []HistogramBucket {
+	min, max := sorted[0], sorted[len(sorted)-1]
+	if min == max {
+		return []HistogramBucket{{From: min, To: max, Count: len(sorted)}}
+	}
+
+	width := (max - min) / float64(buckets)
+	histogram := make([]HistogramBucket, buckets)
+	for i := range histogram {
+		histogram[i].From = min + float64(i)*width
+		histogram[i].To = min + float64(i+1)*width
+	}
+	histogram[buckets-1].To = max
+
+	for _, v := range sorted {
+		index := int((v - min) / width)
+		if index >= buckets {
+			index = buckets - 1
+		}
+		histogram[index].Count++
+	}
+
+	return histogram
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+func TestComputeValueStats(t *testing.T) {
+	if stats := computeValueStats(nil); stats != nil {
+		t.Errorf("computeValueStats(nil) = %+v, want nil", stats)
+	}
+
+	values := []float64{}
+	for i := 1; i <= 100; i++ {
+		values = append(values, float64(i))
+	}
+
+	stats := computeValueStats(values)
+	if stats.Samples != 100 || stats.Min != 1 || stats.Max != 100 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.Avg != 50.5 {
+		t.Errorf("Avg = %v, want 50.5", stats.Avg)
+	}
+	if stats.P95 != 95 {
+		t.Errorf("P95 = %v, want 95", stats.P95)
+	}
+
+	if len(stats.Histogram) != histogramBuckets {
+		t.Fatalf("Histogram has %d buckets, want %d", len(stats.Histogram), histogramBuckets)
+	}
+	total := 0
+	for _, bucket := range stats.Histogram {
+		total += bucket.Count
+	}
+	if total != 100 {
+		t.Errorf("Histogram counts sum to %d, want 100", total)
+	}
+	if stats.Histogram[histogramBuckets-1].To != 100 {
+		t.Errorf("Last bucket should end at max value, got %v", stats.Histogram[histogramBuckets-1].To)
+	}
+}
+
+func TestComputeValueStats_SingleValue(t *testing.T) {
+	stats := computeValueStats([]float64{42, 42, 42})
+
+	if stats.Min != 42 || stats.Max != 42 || stats.Avg != 42 || stats.P95 != 42 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if len(stats.Histogram) != 1 || stats.Histogram[0].Count != 3 {
+		t.Errorf("Expected a single bucket with 3 values, got %+v", stats.Histogram)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+
+	tests := map[float64]float64{
+		0:   10,
+		50:  20,
+		75:  30,
+		95:  40,
+		100: 40,
+	}
+	for p, want := range tests {
+		if got := percentile(sorted, p); got != want {
+			t.Errorf("percentile(%v) = %v, want %v", p, got, want)
+		}
+	}
+}
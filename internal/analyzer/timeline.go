@@ -0,0 +1,187 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// maxTimelineBuckets is the most buckets a timeline has; longer logs are
+// bucketed into a multiple of the requested interval
+const maxTimelineBuckets = 1000
+
+// TimelineAnalyzer buckets matching events into fixed time intervals
+type TimelineAnalyzer struct {
+	// matcher matches entries against the patterns, nil to include every entry
+	matcher  *CountAnalyzer
+	interval time.Duration
+}
+
+// TimelineResult is the number of matching events per interval, from the
+// interval of the earliest to the one of the latest matching event. Intervals
+// without events are included so gaps stand out.
+type TimelineResult struct {
+	Patterns            []string `json:"patterns,omitempty"`
+	IntervalSeconds     float64  `json:"interval_seconds"`
+	TotalEventsAnalyzed int      `json:"total_events_analyzed"`
+	MatchingEvents      int      `json:"matching_events"`
+	// UntimedEvents counts matching entries without a timestamp, which are
+	// not in any bucket
+	UntimedEvents int              `json:"untimed_events"`
+	Buckets       []TimelineBucket `json:"buckets"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+// TimelineBucket is the number of matching events in the interval starting at
+// Start. Counts breaks it down by pattern when there are several.
+type TimelineBucket struct {
+	Start  time.Time `json:"start"`
+	Count  int       `json:"count"`
+	Counts []int     `json:"counts,omitempty"`
+}
+
+// NewTimelineAnalyzer creates an analyzer bucketing entries matching any of
+// eventPatterns, or all entries without patterns, into intervals
+func NewTimelineAnalyzer(eventPatterns []string, interval time.Duration, options CountOptions) (*TimelineAnalyzer, error) {
+	logrus.WithFields(logrus.Fields{
+		"pattern_count": len(eventPatterns),
+		"interval":      interval,
+	}).Debug("Creating new timeline analyzer")
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive, got %s", interval)
+	}
+
+	var matcher *CountAnalyzer
+	if len(eventPatterns) > 0 {
+		var err error
+		if matcher, err = NewCountAnalyzerWithOptions(eventPatterns, options); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TimelineAnalyzer{matcher: matcher, interval: interval}, nil
+}
+
+func (ta *TimelineAnalyzer) AnalyzeTimeline(entries []*parser.LogEntry) *TimelineResult {
+	return ta.AnalyzeTimelineContext(context.Background(), entries)
+}
+
+// AnalyzeTimelineContext is like AnalyzeTimeline but stops when ctx is
+// cancelled, returning the timeline of the entries analyzed so far marked as
+// partial
+func (ta *TimelineAnalyzer) AnalyzeTimelineContext(ctx context.Context, entries []*parser.LogEntry) *TimelineResult {
+	logrus.WithFields(logrus.Fields{
+		"entry_count": len(entries),
+		"interval":    ta.interval,
+	}).Info("Starting timeline analysis")
+
+	result := &TimelineResult{
+		TotalEventsAnalyzed: len(entries),
+		Buckets:             []TimelineBucket{},
+	}
+
+	// matchedEvent is a timestamped matching entry with the patterns it matched
+	type matchedEvent struct {
+		timestamp time.Time
+		patterns  []int
+	}
+	var events []matchedEvent
+	var first, last time.Time
+
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Timeline analysis cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+
+		patterns, matched := ta.match(entry)
+		if !matched {
+			continue
+		}
+		result.MatchingEvents++
+
+		if entry.Timestamp.IsZero() {
+			result.UntimedEvents++
+			continue
+		}
+		if len(events) == 0 || entry.Timestamp.Before(first) {
+			first = entry.Timestamp
+		}
+		if len(events) == 0 || entry.Timestamp.After(last) {
+			last = entry.Timestamp
+		}
+		events = append(events, matchedEvent{timestamp: entry.Timestamp, patterns: patterns})
+	}
+
+	interval := ta.interval
+	if len(events) > 0 {
+		start := first.Truncate(interval)
+		if span := last.Sub(start); span/interval >= maxTimelineBuckets {
+			// Widen the interval to the smallest multiple that fits
+			interval *= span/(interval*maxTimelineBuckets) + 1
+			start = first.Truncate(interval)
+			logrus.WithFields(logrus.Fields{
+				"requested_interval": ta.interval,
+				"interval":           interval,
+			}).Info("Widened timeline interval to limit the number of buckets")
+		}
+
+		bucketCount := int(last.Sub(start)/interval) + 1
+		result.Buckets = make([]TimelineBucket, bucketCount)
+		for i := range result.Buckets {
+			result.Buckets[i].Start = start.Add(time.Duration(i) * interval)
+			if ta.matcher != nil && len(ta.matcher.patterns) > 1 {
+				result.Buckets[i].Counts = make([]int, len(ta.matcher.patterns))
+			}
+		}
+
+		for _, event := range events {
+			bucket := &result.Buckets[int(event.timestamp.Sub(start)/interval)]
+			bucket.Count++
+			if bucket.Counts != nil {
+				for _, pattern := range event.patterns {
+					bucket.Counts[pattern]++
+				}
+			}
+		}
+	}
+
+	result.IntervalSeconds = interval.Seconds()
+	if ta.matcher != nil {
+		for _, pattern := range ta.matcher.patterns {
+			result.Patterns = append(result.Patterns, pattern.Name)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"matching_events": result.MatchingEvents,
+		"untimed_events":  result.UntimedEvents,
+		"buckets":         len(result.Buckets),
+		"partial":         result.Partial,
+	}).Info("Timeline analysis completed")
+
+	return result
+}
+
+// match returns the indexes of the patterns the entry matches and whether it
+// is included in the timeline
+func (ta *TimelineAnalyzer) match(entry *parser.LogEntry) ([]int, bool) {
+	if ta.matcher == nil {
+		return nil, true
+	}
+
+	var matched []int
+	for i, pattern := range ta.matcher.patterns {
+		if ta.matcher.eventMatchesPattern(entry, pattern) {
+			matched = append(matched, i)
+		}
+	}
+	return matched, len(matched) > 0
+}
@@ -0,0 +1,123 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestNewTimelineAnalyzer(t *testing.T) {
+	if _, err := NewTimelineAnalyzer(nil, 0, CountOptions{}); err == nil {
+		t.Error("Expected error for zero interval")
+	}
+	if _, err := NewTimelineAnalyzer([]string{"[invalid"}, time.Minute, CountOptions{}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+	if _, err := NewTimelineAnalyzer(nil, time.Minute, CountOptions{}); err != nil {
+		t.Errorf("NewTimelineAnalyzer() unexpected error: %v", err)
+	}
+}
+
+func TestTimelineAnalyzer_AnalyzeTimeline(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	entries := []*parser.LogEntry{
+		{Timestamp: base.Add(10 * time.Second), Message: "login"},
+		{Timestamp: base.Add(50 * time.Second), Message: "purchase"},
+		{Timestamp: base.Add(3*time.Minute + 5*time.Second), Message: "login"},
+		{Timestamp: base.Add(20 * time.Second), Message: "other"},
+		{Message: "login"},
+	}
+
+	analyzer, err := NewTimelineAnalyzer([]string{"login", "purchase"}, time.Minute, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewTimelineAnalyzer() unexpected error: %v", err)
+	}
+	result := analyzer.AnalyzeTimeline(entries)
+
+	if result.TotalEventsAnalyzed != 5 || result.MatchingEvents != 4 || result.UntimedEvents != 1 {
+		t.Errorf("Unexpected totals: %+v", result)
+	}
+	if result.IntervalSeconds != 60 {
+		t.Errorf("Expected interval of 60 seconds, got %v", result.IntervalSeconds)
+	}
+
+	// Empty intervals are part of the timeline
+	expected := []int{2, 0, 0, 1}
+	if len(result.Buckets) != len(expected) {
+		t.Fatalf("Expected %d buckets, got %+v", len(expected), result.Buckets)
+	}
+	for i, count := range expected {
+		bucket := result.Buckets[i]
+		if bucket.Count != count {
+			t.Errorf("Bucket %d: expected count %d, got %d", i, count, bucket.Count)
+		}
+		if !bucket.Start.Equal(base.Add(time.Duration(i) * time.Minute)) {
+			t.Errorf("Bucket %d: unexpected start %v", i, bucket.Start)
+		}
+	}
+	if counts := result.Buckets[0].Counts; len(counts) != 2 || counts[0] != 1 || counts[1] != 1 {
+		t.Errorf("Expected per-pattern counts [1 1], got %v", counts)
+	}
+}
+
+func TestTimelineAnalyzer_AllEvents(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	entries := []*parser.LogEntry{
+		{Timestamp: base, Message: "a"},
+		{Timestamp: base.Add(time.Minute), Message: "b"},
+	}
+
+	analyzer, err := NewTimelineAnalyzer(nil, time.Hour, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewTimelineAnalyzer() unexpected error: %v", err)
+	}
+	result := analyzer.AnalyzeTimeline(entries)
+
+	if len(result.Buckets) != 1 || result.Buckets[0].Count != 2 || result.Buckets[0].Counts != nil {
+		t.Errorf("Expected a single bucket with both entries, got %+v", result.Buckets)
+	}
+	if result.Patterns != nil {
+		t.Errorf("Expected no patterns, got %v", result.Patterns)
+	}
+}
+
+func TestTimelineAnalyzer_WidensInterval(t *testing.T) {
+	base := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	entries := []*parser.LogEntry{
+		{Timestamp: base, Message: "a"},
+		{Timestamp: base.Add(48 * time.Hour), Message: "b"},
+	}
+
+	analyzer, err := NewTimelineAnalyzer(nil, time.Second, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewTimelineAnalyzer() unexpected error: %v", err)
+	}
+	result := analyzer.AnalyzeTimeline(entries)
+
+	if len(result.Buckets) > maxTimelineBuckets {
+		t.Errorf("Expected at most %d buckets, got %d", maxTimelineBuckets, len(result.Buckets))
+	}
+	if result.IntervalSeconds <= 1 {
+		t.Errorf("Expected a widened interval, got %v seconds", result.IntervalSeconds)
+	}
+	if last := result.Buckets[len(result.Buckets)-1]; last.Count != 1 {
+		t.Errorf("Expected the last event in the last bucket, got %+v", last)
+	}
+}
+
+func TestTimelineAnalyzer_Cancelled(t *testing.T) {
+	analyzer, err := NewTimelineAnalyzer(nil, time.Minute, CountOptions{})
+	if err != nil {
+		t.Fatalf("NewTimelineAnalyzer() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := analyzer.AnalyzeTimelineContext(ctx, []*parser.LogEntry{{Timestamp: time.Now(), Message: "login"}})
+	if !result.Partial || result.TotalEventsAnalyzed != 0 || len(result.Buckets) != 0 {
+		t.Errorf("Expected empty partial result, got %+v", result)
+	}
+}
@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+)
+
+// StepTiming summarizes the time funnel attempts took from the first step to Step
+type StepTiming struct {
+	Step          string  `json:"step"`
+	Samples       int     `json:"samples"`
+	MinSeconds    float64 `json:"min_seconds"`
+	MedianSeconds float64 `json:"median_seconds"`
+	P95Seconds    float64 `json:"p95_seconds"`
+	MaxSeconds    float64 `json:"max_seconds"`
+}
+
+// stepTimer records the time from the start of an attempt to each later step
+// it reaches. Entries without a timestamp are not timed.
+type stepTimer struct {
+	durations [][]float64
+}
+
+func newStepTimer(stepCount int) *stepTimer {
+	return &stepTimer{durations: make([][]float64, stepCount)}
+}
+
+// record notes that an attempt started at start reached step at timestamp.
+// The first step is not timed.
+func (t *stepTimer) record(step int, start, timestamp time.Time) {
+	if step == 0 || start.IsZero() || timestamp.IsZero() {
+		return
+	}
+	t.durations[step] = append(t.durations[step], timestamp.Sub(start).Seconds())
+}
+
+// timeSpan tracks the first and last timestamp of a set of entries, such as
+// the matches of a step. Entries without a timestamp are ignored.
+type timeSpan struct {
+	first, last time.Time
+}
+
+func (s *timeSpan) add(timestamp time.Time) {
+	if timestamp.IsZero() {
+		return
+	}
+	if s.first.IsZero() || timestamp.Before(s.first) {
+		s.first = timestamp
+	}
+	if s.last.IsZero() || timestamp.After(s.last) {
+		s.last = timestamp
+	}
+}
+
+// bounds returns the first and last timestamp, or nil if none was added
+func (s timeSpan) bounds() (*time.Time, *time.Time) {
+	if s.first.IsZero() {
+		return nil, nil
+	}
+	first, last := s.first, s.last
+	return &first, &last
+}
+
+// buildTimings summarizes recorded durations for every step after the first
+// one that was reached at least once with timestamps
+func (fa *FunnelAnalyzer) buildTimings(timer *stepTimer) []StepTiming {
+	var timings []StepTiming
+	for i, durations := range timer.durations {
+		if i == 0 || len(durations) == 0 {
+			continue
+		}
+
+		sorted := make([]float64, len(durations))
+		copy(sorted, durations)
+		sort.Float64s(sorted)
+
+		timings = append(timings, StepTiming{
+			Step:          fa.config.Steps[i].Name,
+			Samples:       len(sorted),
+			MinSeconds:    sorted[0],
+			MedianSeconds: percentile(sorted, 50),
+			P95Seconds:    percentile(sorted, 95),
+			MaxSeconds:    sorted[len(sorted)-1],
+		})
+	}
+	return timings
+}
@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestAnalyzeFunnel_Timings(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "checkout",
+		Steps: []config.Step{
+			{Name: "view", EventPattern: "view"},
+			{Name: "cart", EventPattern: "cart"},
+			{Name: "buy", EventPattern: "buy"},
+		},
+	}
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(message string, seconds int) *parser.LogEntry {
+		return &parser.LogEntry{Message: message, Timestamp: start.Add(time.Duration(seconds) * time.Second)}
+	}
+
+	entries := []*parser.LogEntry{
+		at("view", 0),
+		at("cart", 2),
+		at("buy", 10),
+		at("view", 100),
+		at("cart", 104),
+		at("buy", 130),
+		at("view", 200),
+		at("cart", 201),
+	}
+
+	for _, limit := range []int{0, 5} {
+		result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, limit)
+
+		if len(result.Timings) != 2 {
+			t.Fatalf("limit=%d: expected timings for cart and buy, got %+v", limit, result.Timings)
+		}
+
+		cart := result.Timings[0]
+		if cart.Step != "cart" || cart.Samples != 3 || cart.MinSeconds != 1 || cart.MedianSeconds != 2 || cart.MaxSeconds != 4 {
+			t.Errorf("limit=%d: unexpected cart timing %+v", limit, cart)
+		}
+
+		buy := result.Timings[1]
+		if buy.Step != "buy" || buy.Samples != 2 || buy.MinSeconds != 10 || buy.P95Seconds != 30 || buy.MaxSeconds != 30 {
+			t.Errorf("limit=%d: unexpected buy timing %+v", limit, buy)
+		}
+
+		cartStep := result.Steps[1]
+		if cartStep.FirstTimestamp == nil || !cartStep.FirstTimestamp.Equal(start.Add(2*time.Second)) || !cartStep.LastTimestamp.Equal(start.Add(201*time.Second)) {
+			t.Errorf("limit=%d: expected cart to be matched from 2s to 201s, got %v and %v", limit, cartStep.FirstTimestamp, cartStep.LastTimestamp)
+		}
+	}
+}
+
+func TestAnalyzeFunnel_TimingsWithoutTimestamps(t *testing.T) {
+	cfg := &config.FunnelConfig{
+		Name: "checkout",
+		Steps: []config.Step{
+			{Name: "view", EventPattern: "view"},
+			{Name: "buy", EventPattern: "buy"},
+		},
+	}
+
+	entries := []*parser.LogEntry{
+		{Message: "view"},
+		{Message: "buy"},
+	}
+
+	result := NewFunnelAnalyzer(cfg).AnalyzeFunnel(entries, 0)
+	if !result.FunnelCompleted {
+		t.Fatal("Expected funnel to be completed")
+	}
+	if len(result.Timings) != 0 {
+		t.Errorf("Expected no timings without timestamps, got %+v", result.Timings)
+	}
+	if result.Steps[0].FirstTimestamp != nil || result.Steps[0].LastTimestamp != nil {
+		t.Errorf("Expected no step match times without timestamps, got %+v", result.Steps[0])
+	}
+}
+
+func TestStepTimer_Record(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	timer := newStepTimer(3)
+
+	timer.record(1, time.Time{}, start) // attempt without a start time
+	timer.record(0, start, start)
+	timer.record(1, start, start.Add(1500*time.Millisecond))
+	timer.record(2, start, time.Time{}) // entry without timestamp
+
+	if len(timer.durations[1]) != 1 || timer.durations[1][0] != 1.5 {
+		t.Errorf("Expected one 1.5s sample for step 2, got %v", timer.durations[1])
+	}
+	if len(timer.durations[2]) != 0 {
+		t.Errorf("Expected no samples for step 3, got %v", timer.durations[2])
+	}
+}
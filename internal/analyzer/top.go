@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// TopAnalyzer finds the most frequent events of a log without predefined
+// patterns
+type TopAnalyzer struct {
+	limit int
+}
+
+// TopResult lists the most frequent events, most frequent first
+type TopResult struct {
+	TotalEventsAnalyzed int              `json:"total_events_analyzed"`
+	DistinctEvents      int              `json:"distinct_events"`
+	Events              []EventFrequency `json:"events"`
+	// Partial is set when the analysis was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+}
+
+// EventFrequency is the number of entries with one event
+type EventFrequency struct {
+	Event      string  `json:"event"`
+	Count      int     `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// NewTopAnalyzer creates an analyzer reporting the limit most frequent events,
+// or all of them with a limit of 0
+func NewTopAnalyzer(limit int) (*TopAnalyzer, error) {
+	logrus.WithField("limit", limit).Debug("Creating new top analyzer")
+
+	if limit < 0 {
+		return nil, fmt.Errorf("limit cannot be negative")
+	}
+	return &TopAnalyzer{limit: limit}, nil
+}
+
+func (ta *TopAnalyzer) AnalyzeTop(entries []*parser.LogEntry) *TopResult {
+	return ta.AnalyzeTopContext(context.Background(), entries)
+}
+
+// AnalyzeTopContext is like AnalyzeTop but stops when ctx is cancelled,
+// returning the result for the entries analyzed so far marked as partial
+func (ta *TopAnalyzer) AnalyzeTopContext(ctx context.Context, entries []*parser.LogEntry) *TopResult {
+	logrus.WithFields(logrus.Fields{
+		"entry_count": len(entries),
+		"limit":       ta.limit,
+	}).Info("Starting top events analysis")
+
+	result := &TopResult{
+		TotalEventsAnalyzed: len(entries),
+		Events:              []EventFrequency{},
+	}
+
+	counts := make(map[string]int)
+	for entryIndex, entry := range entries {
+		if cancelled(ctx, entryIndex) {
+			logrus.WithField("entry_index", entryIndex).Info("Top events analysis cancelled, returning partial result")
+			result.TotalEventsAnalyzed = entryIndex
+			result.Partial = true
+			break
+		}
+		counts[EventName(entry)]++
+	}
+
+	for event, count := range counts {
+		result.Events = append(result.Events, EventFrequency{
+			Event:      event,
+			Count:      count,
+			Percentage: float64(count) / float64(result.TotalEventsAnalyzed) * 100.0,
+		})
+	}
+	sort.Slice(result.Events, func(i, j int) bool {
+		if result.Events[i].Count != result.Events[j].Count {
+			return result.Events[i].Count > result.Events[j].Count
+		}
+		return result.Events[i].Event < result.Events[j].Event
+	})
+
+	result.DistinctEvents = len(result.Events)
+	if ta.limit > 0 && len(result.Events) > ta.limit {
+		result.Events = result.Events[:ta.limit]
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"distinct_events": result.DistinctEvents,
+		"reported_events": len(result.Events),
+		"partial":         result.Partial,
+	}).Info("Top events analysis completed")
+
+	return result
+}
+
+// EventName returns the "event" field of structured entries, or the trimmed
+// raw message otherwise
+func EventName(entry *parser.LogEntry) string {
+	if eventValue, exists := entry.EventData["event"]; exists {
+		if eventStr, ok := eventValue.(string); ok {
+			return eventStr
+		}
+	}
+	return strings.TrimSpace(entry.Message)
+}
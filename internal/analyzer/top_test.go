@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestNewTopAnalyzer(t *testing.T) {
+	if _, err := NewTopAnalyzer(-1); err == nil {
+		t.Error("Expected error for negative limit")
+	}
+	if _, err := NewTopAnalyzer(0); err != nil {
+		t.Errorf("NewTopAnalyzer(0) unexpected error: %v", err)
+	}
+}
+
+func TestTopAnalyzer_AnalyzeTop(t *testing.T) {
+	entries := []*parser.LogEntry{
+		{EventData: map[string]interface{}{"event": "login"}},
+		{EventData: map[string]interface{}{"event": "view"}},
+		{EventData: map[string]interface{}{"event": "view"}},
+		{Message: "  view  "},
+		{EventData: map[string]interface{}{"event": 42.0}, Message: "numeric"},
+		{Message: "logout"},
+	}
+
+	analyzer, err := NewTopAnalyzer(2)
+	if err != nil {
+		t.Fatalf("NewTopAnalyzer() unexpected error: %v", err)
+	}
+	result := analyzer.AnalyzeTop(entries)
+
+	if result.TotalEventsAnalyzed != 6 || result.DistinctEvents != 4 {
+		t.Errorf("Unexpected totals: %d events, %d distinct", result.TotalEventsAnalyzed, result.DistinctEvents)
+	}
+	if len(result.Events) != 2 {
+		t.Fatalf("Expected 2 events, got %+v", result.Events)
+	}
+	if result.Events[0].Event != "view" || result.Events[0].Count != 3 || result.Events[0].Percentage != 50 {
+		t.Errorf("Unexpected top event %+v", result.Events[0])
+	}
+	// Ties are ordered by name
+	if result.Events[1].Event != "login" || result.Events[1].Count != 1 {
+		t.Errorf("Unexpected second event %+v", result.Events[1])
+	}
+}
+
+func TestTopAnalyzer_Cancelled(t *testing.T) {
+	analyzer, err := NewTopAnalyzer(0)
+	if err != nil {
+		t.Fatalf("NewTopAnalyzer() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := analyzer.AnalyzeTopContext(ctx, []*parser.LogEntry{{Message: "login"}})
+	if !result.Partial || result.TotalEventsAnalyzed != 0 || len(result.Events) != 0 {
+		t.Errorf("Expected empty partial result, got %+v", result)
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Types a property of an audit schema can require
+const (
+	PropertyString  = "string"
+	PropertyNumber  = "number"
+	PropertyInteger = "integer"
+	PropertyBoolean = "boolean"
+	PropertyObject  = "object"
+	PropertyArray   = "array"
+)
+
+var validPropertyTypes = []string{PropertyString, PropertyNumber, PropertyInteger, PropertyBoolean, PropertyObject, PropertyArray}
+
+// AuditConfig declares the properties analytics events are expected to carry,
+// for example:
+//
+//	events:
+//	  - event_pattern: "^purchase$"
+//	    properties:
+//	      order_id: {type: string, required: true, pattern: "^ord_[0-9]+$"}
+//	      amount: {type: number, required: true}
+//	      currency: {type: string, pattern: "^[A-Z]{3}$"}
+type AuditConfig struct {
+	Events []EventSchema `yaml:"events"`
+}
+
+// EventSchema is the expected properties of the events matching EventPattern
+type EventSchema struct {
+	// EventPattern selects the events, matched like the patterns of count
+	EventPattern string `yaml:"event_pattern"`
+	// Properties maps property paths, with dots reaching into nested
+	// objects, to what their values must look like
+	Properties map[string]PropertySchema `yaml:"properties"`
+}
+
+// PropertySchema is what the value of a property must look like. Properties
+// that are not required are only checked when present.
+type PropertySchema struct {
+	Type     string `yaml:"type,omitempty"`
+	Required bool   `yaml:"required,omitempty"`
+	// Pattern is a regex the value must match, as text for values other than
+	// strings
+	Pattern string `yaml:"pattern,omitempty"`
+}
+
+// LoadAuditConfig loads the event property schema of an audit config file
+func LoadAuditConfig(filepath string) (*AuditConfig, error) {
+	logrus.WithField("filepath", filepath).Debug("Starting audit config load")
+
+	if filepath == "" {
+		return nil, fmt.Errorf("audit config file path is required")
+	}
+
+	data, err := readConfigFile(filepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("audit config file not found: %s", filepath)
+		}
+		return nil, fmt.Errorf("failed to read audit config file '%s': %w", filepath, err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("audit config file is empty: %s", filepath)
+	}
+	data, err = interpolateVars(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to interpolate variables of audit config file '%s': %w", filepath, err)
+	}
+
+	var config AuditConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML audit config file '%s': %w", filepath, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		logrus.WithError(err).WithField("filepath", filepath).Error("Audit config validation failed")
+		return nil, fmt.Errorf("audit config validation failed for '%s': %w", filepath, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"filepath":    filepath,
+		"event_count": len(config.Events),
+	}).Info("Audit config loaded and validated successfully")
+	return &config, nil
+}
+
+func (c *AuditConfig) Validate() error {
+	if len(c.Events) == 0 {
+		return fmt.Errorf("at least one event is required")
+	}
+
+	for i, event := range c.Events {
+		prefix := fmt.Sprintf("event %d", i+1)
+		if event.EventPattern == "" {
+			return fmt.Errorf("%s: event_pattern is required", prefix)
+		}
+		prefix = fmt.Sprintf("event %d (%s)", i+1, event.EventPattern)
+		if len(event.Properties) == 0 {
+			return fmt.Errorf("%s: at least one property is required", prefix)
+		}
+		for name, property := range event.Properties {
+			if name == "" {
+				return fmt.Errorf("%s: property name cannot be empty", prefix)
+			}
+			if property.Type != "" && !slices.Contains(validPropertyTypes, property.Type) {
+				return fmt.Errorf("%s: property '%s': invalid type '%s' (valid: string, number, integer, boolean, object, array)", prefix, name, property.Type)
+			}
+			if property.Pattern != "" {
+				if _, err := regexp.Compile(property.Pattern); err != nil {
+					return fmt.Errorf("%s: property '%s': invalid pattern '%s': %w", prefix, name, property.Pattern, err)
+				}
+			}
+		}
+	}
+	return nil
+}
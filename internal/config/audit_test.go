@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAuditConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.yaml")
+	content := `events:
+  - event_pattern: "^purchase$"
+    properties:
+      order_id: {type: string, required: true, pattern: "^ord_[0-9]+$"}
+      cart.total: {type: number}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write audit config: %v", err)
+	}
+
+	config, err := LoadAuditConfig(path)
+	if err != nil {
+		t.Fatalf("LoadAuditConfig() unexpected error: %v", err)
+	}
+	if len(config.Events) != 1 || config.Events[0].EventPattern != "^purchase$" {
+		t.Fatalf("Expected the purchase event, got %+v", config.Events)
+	}
+	orderID := config.Events[0].Properties["order_id"]
+	if orderID.Type != PropertyString || !orderID.Required || orderID.Pattern != "^ord_[0-9]+$" {
+		t.Errorf("Unexpected order_id schema: %+v", orderID)
+	}
+	if total := config.Events[0].Properties["cart.total"]; total.Type != PropertyNumber || total.Required {
+		t.Errorf("Unexpected cart.total schema: %+v", total)
+	}
+}
+
+func TestLoadAuditConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no events", "events: []\n", "at least one event is required"},
+		{"no event pattern", "events:\n  - properties: {id: {type: string}}\n", "event 1: event_pattern is required"},
+		{"no properties", "events:\n  - event_pattern: login\n", "event 1 (login): at least one property is required"},
+		{"invalid type", "events:\n  - event_pattern: login\n    properties: {id: {type: uuid}}\n", "property 'id': invalid type 'uuid'"},
+		{"invalid pattern", "events:\n  - event_pattern: login\n    properties: {id: {pattern: \"[\"}}\n", "property 'id': invalid pattern '['"},
+		{"unknown field", "events:\n  - event_pattern: login\n    properties: {id: {requried: true}}\n", "field requried not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write audit config: %v", err)
+			}
+			_, err := LoadAuditConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadAuditConfig() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Sections of a combined config file, which holds the parser config and the
+// funnel config in one file, for example:
+//
+//	parser:
+//	  format: android-logcat
+//	  event_regex: ".*Analytics: (.*)"
+//	funnel:
+//	  name: "Onboarding"
+//	  steps:
+//	    - name: "App Open"
+//	      event_pattern: "^app_open$"
+const (
+	ParserSection = "parser"
+	FunnelSection = "funnel"
+)
+
+// CombinedSections reports which sections the combined config file at
+// filepath defines. It fails if the file is not a combined config file.
+func CombinedSections(filepath string) (hasParser, hasFunnel bool, err error) {
+	data, err := readConfigFile(filepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, false, fmt.Errorf("config file not found: %s", filepath)
+		}
+		return false, false, fmt.Errorf("failed to read config file '%s': %w", filepath, err)
+	}
+
+	sections, combined, err := combinedSections(data)
+	if err != nil {
+		return false, false, fmt.Errorf("config file '%s': %w", filepath, err)
+	}
+	if !combined {
+		return false, false, fmt.Errorf("config file '%s' has no %s or %s section", filepath, ParserSection, FunnelSection)
+	}
+	_, hasParser = sections[ParserSection]
+	_, hasFunnel = sections[FunnelSection]
+	return hasParser, hasFunnel, nil
+}
+
+// configSection returns the YAML of a section when data is a combined config
+// file, or data itself when it is a config file of its own
+func configSection(data []byte, section string) ([]byte, error) {
+	sections, combined, err := combinedSections(data)
+	if err != nil || !combined {
+		return data, err
+	}
+
+	node, ok := sections[section]
+	if !ok {
+		return nil, fmt.Errorf("combined config file has no %s section", section)
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s section must be a mapping", section)
+	}
+
+	logrus.WithField("section", section).Debug("Reading section of combined config file")
+	return yaml.Marshal(&node)
+}
+
+// combinedSections splits a combined config file into its sections. Files
+// without a parser or funnel key at the top level are not combined; data that
+// is not a YAML mapping is left for the config loaders to report.
+func combinedSections(data []byte) (map[string]yaml.Node, bool, error) {
+	var sections map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return nil, false, nil
+	}
+
+	_, hasParser := sections[ParserSection]
+	_, hasFunnel := sections[FunnelSection]
+	if !hasParser && !hasFunnel {
+		return nil, false, nil
+	}
+
+	var unknown []string
+	for key := range sections {
+		if key != ParserSection && key != FunnelSection {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, true, fmt.Errorf("unknown section '%s' in combined config file (valid: %s, %s)", unknown[0], ParserSection, FunnelSection)
+	}
+	return sections, true, nil
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const combinedConfig = `parser:
+  format: android-logcat
+  event_regex: ".*Analytics: (.*)"
+  json_extraction: true
+funnel:
+  name: "Onboarding"
+  steps:
+    - name: "App Open"
+      event_pattern: "^app_open$"
+    - name: "Sign Up"
+      event_pattern: "^sign_up$"
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "loglion.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadCombinedConfig(t *testing.T) {
+	path := writeConfig(t, combinedConfig)
+
+	parserCfg, err := LoadParserConfig(path)
+	if err != nil {
+		t.Fatalf("LoadParserConfig() unexpected error: %v", err)
+	}
+	if parserCfg.Format != "android-logcat" || parserCfg.EventRegex != ".*Analytics: (.*)" || !parserCfg.JSONExtraction {
+		t.Errorf("Unexpected parser config %+v", parserCfg)
+	}
+
+	funnelCfg, err := LoadFunnelConfig(path)
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error: %v", err)
+	}
+	if funnelCfg.Name != "Onboarding" || len(funnelCfg.Steps) != 2 {
+		t.Errorf("Unexpected funnel config %+v", funnelCfg)
+	}
+
+	hasParser, hasFunnel, err := CombinedSections(path)
+	if err != nil || !hasParser || !hasFunnel {
+		t.Errorf("CombinedSections() = %v, %v, %v, want both sections", hasParser, hasFunnel, err)
+	}
+}
+
+func TestLoadCombinedConfigMultipleFunnels(t *testing.T) {
+	path := writeConfig(t, `parser:
+  event_regex: "^(.*)$"
+funnel:
+  funnels:
+    - name: "Signup"
+      steps:
+        - name: "Open"
+          event_pattern: "open"
+    - name: "Purchase"
+      steps:
+        - name: "Buy"
+          event_pattern: "buy"
+`)
+
+	funnelCfgs, err := LoadFunnelConfigs(path)
+	if err != nil {
+		t.Fatalf("LoadFunnelConfigs() unexpected error: %v", err)
+	}
+	if len(funnelCfgs) != 2 || funnelCfgs[1].Name != "Purchase" {
+		t.Errorf("Expected both funnels of the funnel section, got %d", len(funnelCfgs))
+	}
+}
+
+func TestLoadCombinedConfigErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		load        func(string) error
+		errContains string
+	}{
+		{
+			name:        "missing funnel section",
+			content:     "parser:\n  event_regex: \"^(.*)$\"\n",
+			load:        func(path string) error { _, err := LoadFunnelConfigs(path); return err },
+			errContains: "no funnel section",
+		},
+		{
+			name:        "unknown section",
+			content:     combinedConfig + "count:\n  patterns: [\"a\"]\n",
+			load:        func(path string) error { _, err := LoadParserConfig(path); return err },
+			errContains: "unknown section 'count'",
+		},
+		{
+			name:        "section is not a mapping",
+			content:     "parser: plain\nfunnel:\n  name: x\n",
+			load:        func(path string) error { _, err := LoadParserConfig(path); return err },
+			errContains: "parser section must be a mapping",
+		},
+		{
+			name:        "invalid parser section",
+			content:     "parser:\n  event_regex: \"[\"\n",
+			load:        func(path string) error { _, err := LoadParserConfig(path); return err },
+			errContains: "invalid event_regex",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestCombinedSectionsRejectsSeparateConfig(t *testing.T) {
+	path := writeConfig(t, "event_regex: \"^(.*)$\"\n")
+	if _, _, err := CombinedSections(path); err == nil || !strings.Contains(err.Error(), "no parser or funnel section") {
+		t.Errorf("Expected an error for a parser config file, got %v", err)
+	}
+
+	// Separate config files keep loading as before
+	if _, err := LoadParserConfig(path); err != nil {
+		t.Errorf("LoadParserConfig() unexpected error: %v", err)
+	}
+}
@@ -1,33 +1,171 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/parfenovvs/loglion/internal/expr"
+	"github.com/parfenovvs/loglion/internal/parser"
 	"github.com/sirupsen/logrus"
 	"github.com/xeipuuv/gojsonschema"
 	"gopkg.in/yaml.v3"
 )
 
 type ParserConfig struct {
+	// Format is the registered log format, plain by default
+	Format          string `yaml:"format,omitempty"`
 	TimestampFormat string `yaml:"timestamp_format"`
-	EventRegex      string `yaml:"event_regex"`
-	JSONExtraction  bool   `yaml:"json_extraction"`
-	LogLineRegex    string `yaml:"log_line_regex"`
+	// TimestampTimezone is the IANA zone of timestamps without one, UTC if empty
+	TimestampTimezone string `yaml:"timestamp_timezone,omitempty"`
+	// AssumeYear completes timestamps without a year: a year such as 2025,
+	// or "mtime" to infer it from the modification time of the log file
+	AssumeYear     string       `yaml:"assume_year,omitempty"`
+	EventRegex     string       `yaml:"event_regex"`
+	JSONExtraction bool         `yaml:"json_extraction"`
+	LogLineRegex   string       `yaml:"log_line_regex"`
+	Fields         FieldsConfig `yaml:"fields,omitempty"`
+	// MultilineStartRegex matches the first line of a log entry; lines that
+	// do not match are appended to the message of the previous entry
+	MultilineStartRegex string `yaml:"multiline_start_regex,omitempty"`
+	// PropertyRegexes extract event data properties from log messages, keyed
+	// by property name, e.g. user_id: "uid=(\\d+)"
+	PropertyRegexes map[string]string `yaml:"property_regexes,omitempty"`
+	Filter          FilterConfig      `yaml:"filter,omitempty"`
+	// Redact scrubs sensitive text, such as emails or tokens, from the
+	// messages and event data of entries before analysis and output
+	Redact []RedactionRule `yaml:"redact,omitempty"`
+}
+
+// RedactionRule replaces the matches of Pattern with Replacement, which may
+// refer to capture groups as $1 and is [REDACTED] if empty
+type RedactionRule struct {
+	Pattern     string `yaml:"pattern"`
+	Replacement string `yaml:"replacement,omitempty"`
+}
+
+// FieldsConfig maps the properties of jsonl records to log entry fields
+type FieldsConfig struct {
+	Timestamp string `yaml:"timestamp,omitempty"`
+	Level     string `yaml:"level,omitempty"`
+	Message   string `yaml:"message,omitempty"`
+	// Event is the property holding the event payload, the whole record if empty
+	Event string `yaml:"event,omitempty"`
+}
+
+// FilterConfig selects which parsed entries reach the analyzers
+type FilterConfig struct {
+	Level       string   `yaml:"level,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	ExcludeTags []string `yaml:"exclude_tags,omitempty"`
+	PIDs        []int    `yaml:"pids,omitempty"`
 }
 
 type FunnelConfig struct {
 	Name  string `yaml:"name"`
 	Steps []Step `yaml:"steps"`
+	// GroupBy names the event data property that partitions entries into
+	// independently analyzed groups, such as users or sessions
+	GroupBy string `yaml:"group_by,omitempty"`
+	// CaseInsensitive matches all patterns of the funnel regardless of case,
+	// unless a step or branch overrides it
+	CaseInsensitive bool `yaml:"case_insensitive,omitempty"`
+	// Mode sets how the steps of an attempt must follow each other, loose
+	// when empty
+	Mode string `yaml:"mode,omitempty"`
+	// AnchorFirstStep starts attempts of unordered funnels only at the first
+	// step, so events of other steps before it are ignored. Attempts of
+	// ordered funnels always start at the first step.
+	AnchorFirstStep bool `yaml:"anchor_first_step,omitempty"`
+}
+
+// Funnel modes
+const (
+	// ModeStrict requires the steps of an attempt to follow each other
+	// without other funnel events in between
+	ModeStrict = "strict"
+	// ModeLoose requires the steps of an attempt in order, with any events
+	// in between
+	ModeLoose = "loose"
+	// ModeUnordered only requires all steps in an attempt, in any order
+	ModeUnordered = "unordered"
+)
+
+// funnelConfigFile is the on-disk layout of a funnel config, which holds
+// either a single funnel at the top level or a list under "funnels"
+type funnelConfigFile struct {
+	FunnelConfig `yaml:",inline"`
+	Funnels      []FunnelConfig `yaml:"funnels,omitempty"`
 }
 
 type Step struct {
 	Name               string            `yaml:"name"`
+	EventPattern       string            `yaml:"event_pattern,omitempty"`
+	RequiredProperties map[string]string `yaml:"required_properties,omitempty"`
+	// FailIfMoreThan abandons the attempt once the step's event fired more
+	// than this many times; 0 fails it on any occurrence, nil disables it
+	FailIfMoreThan *int `yaml:"fail_if_more_than,omitempty"`
+	// MinOccurrences is the number of times the step's event must fire in an
+	// attempt before the step is reached
+	MinOccurrences int `yaml:"min_occurrences,omitempty"`
+	// MinCount and MinConversionPct assert that the step is reached at least
+	// this often, or by at least this percentage of the first step
+	MinCount         int     `yaml:"min_count,omitempty"`
+	MinConversionPct float64 `yaml:"min_conversion_pct,omitempty"`
+	// Expr is a CEL expression over the entry used instead of EventPattern,
+	// for numeric or cross-field conditions
+	Expr string `yaml:"expr,omitempty"`
+	// AnyOf lists alternative branches used instead of EventPattern; an event
+	// matching any of them satisfies the step
+	AnyOf []StepBranch `yaml:"any_of,omitempty"`
+	// ExcludePattern aborts the funnel attempt when a matching event occurs
+	// after the previous step and before this one
+	ExcludePattern string `yaml:"exclude_pattern,omitempty"`
+	// CaseInsensitive overrides the funnel setting for the patterns of this step
+	CaseInsensitive *bool `yaml:"case_insensitive,omitempty"`
+}
+
+// IgnoresCase reports whether the patterns of the step match regardless of
+// case, falling back to the funnel setting when the step does not set it
+func (s Step) IgnoresCase(funnelDefault bool) bool {
+	if s.CaseInsensitive != nil {
+		return *s.CaseInsensitive
+	}
+	return funnelDefault
+}
+
+// StepBranch is one alternative of a step with any_of. Required properties
+// of the step apply to every branch in addition to the branch's own.
+type StepBranch struct {
+	Name               string            `yaml:"name,omitempty"`
 	EventPattern       string            `yaml:"event_pattern"`
 	RequiredProperties map[string]string `yaml:"required_properties,omitempty"`
+	// CaseInsensitive overrides the step setting for the patterns of this branch
+	CaseInsensitive *bool `yaml:"case_insensitive,omitempty"`
+}
+
+// IgnoresCase reports whether the patterns of the branch match regardless of
+// case, falling back to the step setting when the branch does not set it
+func (b StepBranch) IgnoresCase(stepDefault bool) bool {
+	if b.CaseInsensitive != nil {
+		return *b.CaseInsensitive
+	}
+	return stepDefault
+}
+
+// Label returns the branch name, or its event pattern if it has no name
+func (b StepBranch) Label() string {
+	if b.Name != "" {
+		return b.Name
+	}
+	return b.EventPattern
 }
 
 func LoadParserConfig(filepath string) (*ParserConfig, error) {
@@ -39,7 +177,7 @@ func LoadParserConfig(filepath string) (*ParserConfig, error) {
 	}
 
 	logrus.WithField("filepath", filepath).Debug("Reading parser config file")
-	data, err := os.ReadFile(filepath)
+	data, err := readConfigFile(filepath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			logrus.WithField("filepath", filepath).Error("Parser config file not found")
@@ -54,6 +192,18 @@ func LoadParserConfig(filepath string) (*ParserConfig, error) {
 		return nil, fmt.Errorf("parser config file is empty: %s", filepath)
 	}
 
+	source := newSchemaSource(data, ParserSection)
+	data, err = configSection(data, ParserSection)
+	if err != nil {
+		logrus.WithError(err).WithField("filepath", filepath).Error("Failed to read parser section")
+		return nil, fmt.Errorf("failed to read parser config file '%s': %w", filepath, err)
+	}
+	data, err = applyOverrides(data, ParserSection)
+	if err != nil {
+		logrus.WithError(err).WithField("filepath", filepath).Error("Failed to apply parser config overrides")
+		return nil, fmt.Errorf("failed to apply overrides to parser config file '%s': %w", filepath, err)
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"filepath": filepath,
 		"size":     len(data),
@@ -67,7 +217,7 @@ func LoadParserConfig(filepath string) (*ParserConfig, error) {
 
 	logrus.Debug("Parser config parsed successfully, starting schema validation")
 
-	if err := validateParserSchema(data); err != nil {
+	if err := validateParserSchema(data, source); err != nil {
 		logrus.WithError(err).WithField("filepath", filepath).Error("Parser schema validation failed")
 		return nil, fmt.Errorf("parser schema validation failed for '%s': %w", filepath, err)
 	}
@@ -82,7 +232,27 @@ func LoadParserConfig(filepath string) (*ParserConfig, error) {
 	return &config, nil
 }
 
+// LoadFunnelConfig loads a funnel config file that defines exactly one funnel
 func LoadFunnelConfig(filepath string) (*FunnelConfig, error) {
+	configs, err := LoadFunnelConfigs(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(configs) != 1 {
+		logrus.WithFields(logrus.Fields{
+			"filepath":     filepath,
+			"funnel_count": len(configs),
+		}).Error("Funnel config file defines more than one funnel")
+		return nil, fmt.Errorf("funnel config file '%s' defines %d funnels, expected exactly one", filepath, len(configs))
+	}
+
+	return configs[0], nil
+}
+
+// LoadFunnelConfigs loads all funnels defined in a funnel config file, either
+// a single top-level funnel or every entry of the "funnels" list
+func LoadFunnelConfigs(filepath string) ([]*FunnelConfig, error) {
 	logrus.WithField("filepath", filepath).Debug("Starting funnel config load")
 
 	if filepath == "" {
@@ -91,7 +261,7 @@ func LoadFunnelConfig(filepath string) (*FunnelConfig, error) {
 	}
 
 	logrus.WithField("filepath", filepath).Debug("Reading funnel config file")
-	data, err := os.ReadFile(filepath)
+	data, err := readConfigFile(filepath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			logrus.WithField("filepath", filepath).Error("Funnel config file not found")
@@ -106,35 +276,90 @@ func LoadFunnelConfig(filepath string) (*FunnelConfig, error) {
 		return nil, fmt.Errorf("funnel config file is empty: %s", filepath)
 	}
 
+	source := newSchemaSource(data, FunnelSection)
+	data, err = configSection(data, FunnelSection)
+	if err != nil {
+		logrus.WithError(err).WithField("filepath", filepath).Error("Failed to read funnel section")
+		return nil, fmt.Errorf("failed to read funnel config file '%s': %w", filepath, err)
+	}
+	resolved, err := resolveExtends(data, filepath)
+	if err != nil {
+		logrus.WithError(err).WithField("filepath", filepath).Error("Failed to resolve funnel config extends")
+		return nil, fmt.Errorf("failed to resolve extends of funnel config file '%s': %w", filepath, err)
+	}
+	if !bytes.Equal(resolved, data) {
+		// Steps merged from a base funnel have no place in the file
+		source.data = nil
+	}
+	data = resolved
+	data, err = applyOverrides(data, FunnelSection)
+	if err != nil {
+		logrus.WithError(err).WithField("filepath", filepath).Error("Failed to apply funnel config overrides")
+		return nil, fmt.Errorf("failed to apply overrides to funnel config file '%s': %w", filepath, err)
+	}
+	data, err = interpolateVars(data)
+	if err != nil {
+		logrus.WithError(err).WithField("filepath", filepath).Error("Failed to interpolate funnel config variables")
+		return nil, fmt.Errorf("failed to interpolate variables of funnel config file '%s': %w", filepath, err)
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"filepath": filepath,
 		"size":     len(data),
 	}).Debug("Funnel config file read successfully, parsing YAML")
 
-	var config FunnelConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	var file funnelConfigFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
 		logrus.WithError(err).WithField("filepath", filepath).Error("Failed to parse YAML funnel config")
 		return nil, fmt.Errorf("failed to parse YAML funnel config file '%s': %w", filepath, err)
 	}
 
-	logrus.WithField("funnel", config.Name).Debug("Funnel config parsed successfully, starting schema validation")
+	logrus.WithFields(logrus.Fields{
+		"funnel":       file.Name,
+		"funnel_count": len(file.Funnels),
+	}).Debug("Funnel config parsed successfully, starting schema validation")
 
-	if err := validateFunnelSchema(data); err != nil {
+	if err := validateFunnelSchema(data, source); err != nil {
 		logrus.WithError(err).WithField("filepath", filepath).Error("Funnel schema validation failed")
 		return nil, fmt.Errorf("funnel schema validation failed for '%s': %w", filepath, err)
 	}
 
+	configs := []*FunnelConfig{&file.FunnelConfig}
+	if len(file.Funnels) > 0 {
+		configs = make([]*FunnelConfig, len(file.Funnels))
+		for i := range file.Funnels {
+			configs[i] = &file.Funnels[i]
+		}
+	}
+
 	logrus.Debug("Funnel schema validation passed, starting struct validation")
-	if err := config.Validate(); err != nil {
-		logrus.WithError(err).WithField("filepath", filepath).Error("Funnel config validation failed")
-		return nil, fmt.Errorf("funnel config validation failed for '%s': %w", filepath, err)
+	funnelNames := make(map[string]bool)
+	for i, config := range configs {
+		if err := config.Validate(); err != nil {
+			logrus.WithError(err).WithField("filepath", filepath).Error("Funnel config validation failed")
+			if len(file.Funnels) > 0 {
+				return nil, fmt.Errorf("funnel config validation failed for '%s': funnel %d: %w", filepath, i+1, err)
+			}
+			return nil, fmt.Errorf("funnel config validation failed for '%s': %w", filepath, err)
+		}
+
+		if funnelNames[config.Name] {
+			logrus.WithField("funnel_name", config.Name).Error("Duplicate funnel name")
+			return nil, fmt.Errorf("funnel config validation failed for '%s': funnel %d: duplicate funnel name '%s'", filepath, i+1, config.Name)
+		}
+		funnelNames[config.Name] = true
 	}
 
-	logrus.WithField("filepath", filepath).Info("Funnel config loaded and validated successfully")
-	return &config, nil
+	logrus.WithFields(logrus.Fields{
+		"filepath":     filepath,
+		"funnel_count": len(configs),
+	}).Info("Funnel config loaded and validated successfully")
+	return configs, nil
 }
 
-func validateParserSchema(yamlData []byte) error {
+// validateParserSchema validates a parser config against the JSON schema,
+// locating errors in source
+func validateParserSchema(yamlData []byte, source schemaSource) error {
 	// Get the schema file path relative to the project root
 	schemaPath := "schema/parser-config.schema.json"
 
@@ -174,18 +399,16 @@ func validateParserSchema(yamlData []byte) error {
 	}
 
 	if !result.Valid() {
-		var errors []string
-		for _, desc := range result.Errors() {
-			errors = append(errors, fmt.Sprintf("- %s", desc))
-		}
-		return fmt.Errorf("parser schema validation failed:\n%s", fmt.Sprintf("%v", errors))
+		return fmt.Errorf("parser schema validation failed:\n%s", formatSchemaErrors(result.Errors(), source))
 	}
 
 	logrus.Debug("Parser schema validation completed successfully")
 	return nil
 }
 
-func validateFunnelSchema(yamlData []byte) error {
+// validateFunnelSchema validates a funnel config against the JSON schema,
+// locating errors in source
+func validateFunnelSchema(yamlData []byte, source schemaSource) error {
 	// Get the schema file path relative to the project root
 	schemaPath := "schema/funnel-config.schema.json"
 
@@ -225,11 +448,7 @@ func validateFunnelSchema(yamlData []byte) error {
 	}
 
 	if !result.Valid() {
-		var errors []string
-		for _, desc := range result.Errors() {
-			errors = append(errors, fmt.Sprintf("- %s", desc))
-		}
-		return fmt.Errorf("funnel schema validation failed:\n%s", fmt.Sprintf("%v", errors))
+		return fmt.Errorf("funnel schema validation failed:\n%s", formatSchemaErrors(result.Errors(), source))
 	}
 
 	logrus.Debug("Funnel schema validation completed successfully")
@@ -239,7 +458,17 @@ func validateFunnelSchema(yamlData []byte) error {
 func (c *ParserConfig) Validate() error {
 	logrus.Debug("Starting parser config validation")
 
-	// Set defaults for plain format (the only supported format)
+	if c.Format == "" {
+		c.Format = parser.PlainFormat
+	}
+	if !slices.Contains(parser.Formats(), c.Format) {
+		return fmt.Errorf("invalid format '%s' (valid: %s)", c.Format, strings.Join(parser.Formats(), ", "))
+	}
+	if c.Format != parser.JSONLFormat && c.Fields != (FieldsConfig{}) {
+		return fmt.Errorf("fields are only supported with format: %s", parser.JSONLFormat)
+	}
+
+	// Set defaults for plain format
 	if c.TimestampFormat == "" {
 		c.TimestampFormat = "" // No default timestamp for plain format
 		logrus.Debug("Timestamp format not specified for plain format, leaving empty")
@@ -250,7 +479,8 @@ func (c *ParserConfig) Validate() error {
 		logrus.Debug("Event regex not specified, using default for plain format")
 	}
 
-	if c.LogLineRegex == "" {
+	// Other formats have their own line layout
+	if c.LogLineRegex == "" && c.Format == parser.PlainFormat {
 		c.LogLineRegex = "^(.*)$" // Default: entire line
 		logrus.Debug("Log line regex not specified, using default for plain format")
 	}
@@ -271,16 +501,140 @@ func (c *ParserConfig) Validate() error {
 		}
 	}
 
+	if c.TimestampTimezone != "" {
+		if _, err := time.LoadLocation(c.TimestampTimezone); err != nil {
+			return fmt.Errorf("invalid timestamp_timezone: %w", err)
+		}
+	}
+
+	if _, _, err := c.assumedYear(); err != nil {
+		return err
+	}
+
+	if c.MultilineStartRegex != "" {
+		if _, err := regexp.Compile(c.MultilineStartRegex); err != nil {
+			logrus.WithError(err).WithField("multiline_start_regex", c.MultilineStartRegex).Error("Invalid multiline start regex pattern")
+			return fmt.Errorf("invalid multiline_start_regex: %w", err)
+		}
+	}
+
+	for name, pattern := range c.PropertyRegexes {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid property_regexes.%s: %w", name, err)
+		}
+	}
+
+	for i, rule := range c.Redact {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid redact[%d].pattern: %w", i, err)
+		}
+	}
+
+	if c.Filter.Level != "" {
+		level, err := parser.ParseLevel(c.Filter.Level)
+		if err != nil {
+			return fmt.Errorf("invalid filter.level: %w", err)
+		}
+		c.Filter.Level = level
+	}
+
+	for _, pid := range c.Filter.PIDs {
+		if pid <= 0 {
+			return fmt.Errorf("invalid filter.pids: pid must be positive, got %d", pid)
+		}
+	}
+
 	logrus.WithFields(logrus.Fields{
+		"format":           c.Format,
 		"timestamp_format": c.TimestampFormat,
 		"event_regex":      c.EventRegex,
 		"log_line_regex":   c.LogLineRegex,
 		"json_extraction":  c.JSONExtraction,
+		"filter":           c.Filter,
 	}).Debug("Parser config validation completed successfully")
 
 	return nil
 }
 
+// NewParser creates a parser for the log format of the config
+func (c *ParserConfig) NewParser() (parser.Parser, error) {
+	format := c.Format
+	if format == "" {
+		format = parser.PlainFormat
+	}
+
+	var location *time.Location
+	if c.TimestampTimezone != "" {
+		var err error
+		if location, err = time.LoadLocation(c.TimestampTimezone); err != nil {
+			return nil, fmt.Errorf("invalid timestamp_timezone: %w", err)
+		}
+	}
+	year, yearFromModTime, err := c.assumedYear()
+	if err != nil {
+		return nil, err
+	}
+
+	logParser, err := parser.NewParserForFormat(format, parser.FormatOptions{
+		TimestampFormat: c.TimestampFormat,
+		EventRegex:      c.EventRegex,
+		JSONExtraction:  c.JSONExtraction,
+		LogLineRegex:    c.LogLineRegex,
+		Fields: parser.JSONLFields{
+			Timestamp: c.Fields.Timestamp,
+			Level:     c.Fields.Level,
+			Message:   c.Fields.Message,
+			Event:     c.Fields.Event,
+		},
+		MultilineStartRegex: c.MultilineStartRegex,
+		Location:            location,
+		AssumeYear:          year,
+		YearFromModTime:     yearFromModTime,
+	})
+	if err == nil && len(c.PropertyRegexes) > 0 {
+		logParser, err = parser.NewPropertyParser(logParser, c.PropertyRegexes)
+	}
+	// Redaction comes last so extracted properties are scrubbed too
+	if err != nil || len(c.Redact) == 0 {
+		return logParser, err
+	}
+	rules := make([]parser.RedactionRule, len(c.Redact))
+	for i, rule := range c.Redact {
+		rules[i] = parser.RedactionRule{Pattern: rule.Pattern, Replacement: rule.Replacement}
+	}
+	return parser.NewRedactingParser(logParser, rules)
+}
+
+// assumedYearFromModTime is the assume_year value inferring the year from the
+// modification time of the log file
+const assumedYearFromModTime = "mtime"
+
+// assumedYear parses assume_year into a year, or reports that the year is
+// inferred from the log file modification time
+func (c *ParserConfig) assumedYear() (int, bool, error) {
+	switch c.AssumeYear {
+	case "":
+		return 0, false, nil
+	case assumedYearFromModTime:
+		return 0, true, nil
+	}
+	year, err := strconv.Atoi(c.AssumeYear)
+	if err != nil || year < 1 || year > 9999 {
+		return 0, false, fmt.Errorf("invalid assume_year '%s' (use a year such as 2025, or %s)", c.AssumeYear, assumedYearFromModTime)
+	}
+	return year, false, nil
+}
+
+// EntryFilter converts the filter section into a parser entry filter
+func (c *ParserConfig) EntryFilter() parser.EntryFilter {
+	return parser.EntryFilter{
+		MinLevel:    c.Filter.Level,
+		Tags:        c.Filter.Tags,
+		ExcludeTags: c.Filter.ExcludeTags,
+		PIDs:        c.Filter.PIDs,
+	}
+}
+
 func (c *FunnelConfig) Validate() error {
 	logrus.Debug("Starting funnel config validation")
 
@@ -302,6 +656,12 @@ func (c *FunnelConfig) Validate() error {
 
 	logrus.WithField("step_count", len(c.Steps)).Debug("Funnel step count validation passed")
 
+	switch c.Mode {
+	case "", ModeStrict, ModeLoose, ModeUnordered:
+	default:
+		return fmt.Errorf("invalid mode '%s' (valid: %s, %s, %s)", c.Mode, ModeStrict, ModeLoose, ModeUnordered)
+	}
+
 	stepNames := make(map[string]bool)
 	for i, step := range c.Steps {
 		logrus.WithFields(logrus.Fields{
@@ -314,6 +674,10 @@ func (c *FunnelConfig) Validate() error {
 		}
 	}
 
+	if err := BuildStepGraph(c).Validate(); err != nil {
+		return err
+	}
+
 	logrus.WithField("funnel_name", c.Name).Debug("Funnel config validation completed successfully")
 	return nil
 }
@@ -328,25 +692,99 @@ func (c *FunnelConfig) validateStep(index int, step Step, stepNames map[string]b
 	}
 	stepNames[step.Name] = true
 
-	if step.EventPattern == "" {
-		return fmt.Errorf("step %d (%s): event_pattern is required", index+1, step.Name)
+	if step.Expr != "" && (step.EventPattern != "" || len(step.AnyOf) > 0) {
+		return fmt.Errorf("step %d (%s): expr cannot be combined with event_pattern or any_of", index+1, step.Name)
 	}
 
-	if _, err := regexp.Compile(step.EventPattern); err != nil {
-		return fmt.Errorf("step %d (%s): invalid event_pattern regex: %w", index+1, step.Name, err)
+	if len(step.AnyOf) > 0 {
+		if step.EventPattern != "" {
+			return fmt.Errorf("step %d (%s): event_pattern and any_of cannot be combined", index+1, step.Name)
+		}
+		if err := validateBranches(index, step); err != nil {
+			return err
+		}
+	} else if step.Expr != "" {
+		if _, err := expr.Compile(step.Expr); err != nil {
+			return fmt.Errorf("step %d (%s): invalid expr: %w", index+1, step.Name, err)
+		}
+	} else {
+		if step.EventPattern == "" {
+			return fmt.Errorf("step %d (%s): event_pattern, any_of or expr is required", index+1, step.Name)
+		}
+
+		if _, err := regexp.Compile(step.EventPattern); err != nil {
+			return fmt.Errorf("step %d (%s): invalid event_pattern regex: %w", index+1, step.Name, err)
+		}
+	}
+
+	if step.ExcludePattern != "" {
+		if index == 0 {
+			return fmt.Errorf("step %d (%s): exclude_pattern cannot be used on the first step", index+1, step.Name)
+		}
+		if _, err := regexp.Compile(step.ExcludePattern); err != nil {
+			return fmt.Errorf("step %d (%s): invalid exclude_pattern regex: %w", index+1, step.Name, err)
+		}
+	}
+
+	if step.FailIfMoreThan != nil && *step.FailIfMoreThan < 0 {
+		return fmt.Errorf("step %d (%s): fail_if_more_than cannot be negative", index+1, step.Name)
+	}
+
+	if step.MinOccurrences < 0 {
+		return fmt.Errorf("step %d (%s): min_occurrences cannot be negative", index+1, step.Name)
+	}
+	if step.FailIfMoreThan != nil && step.MinOccurrences > *step.FailIfMoreThan {
+		return fmt.Errorf("step %d (%s): min_occurrences cannot exceed fail_if_more_than", index+1, step.Name)
+	}
+
+	if step.MinCount < 0 {
+		return fmt.Errorf("step %d (%s): min_count cannot be negative", index+1, step.Name)
+	}
+	if step.MinConversionPct < 0 || step.MinConversionPct > 100 {
+		return fmt.Errorf("step %d (%s): min_conversion_pct must be between 0 and 100", index+1, step.Name)
 	}
 
-	for propName, propPattern := range step.RequiredProperties {
+	return validateRequiredProperties(fmt.Sprintf("step %d (%s)", index+1, step.Name), step.RequiredProperties)
+}
+
+func validateBranches(index int, step Step) error {
+	labels := make(map[string]bool)
+	for i, branch := range step.AnyOf {
+		prefix := fmt.Sprintf("step %d (%s): branch %d", index+1, step.Name, i+1)
+
+		if branch.EventPattern == "" {
+			return fmt.Errorf("%s: event_pattern is required", prefix)
+		}
+		if _, err := regexp.Compile(branch.EventPattern); err != nil {
+			return fmt.Errorf("%s: invalid event_pattern regex: %w", prefix, err)
+		}
+
+		if labels[branch.Label()] {
+			return fmt.Errorf("%s: duplicate branch '%s'", prefix, branch.Label())
+		}
+		labels[branch.Label()] = true
+
+		if err := validateRequiredProperties(prefix, branch.RequiredProperties); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRequiredProperties(prefix string, requiredProperties map[string]string) error {
+	for propName, propPattern := range requiredProperties {
 		if propName == "" {
-			return fmt.Errorf("step %d (%s): property name cannot be empty", index+1, step.Name)
+			return fmt.Errorf("%s: property name cannot be empty", prefix)
 		}
 		if propPattern == "" {
-			return fmt.Errorf("step %d (%s): property pattern for '%s' cannot be empty", index+1, step.Name, propName)
+			return fmt.Errorf("%s: property pattern for '%s' cannot be empty", prefix, propName)
 		}
-		if _, err := regexp.Compile(propPattern); err != nil {
-			return fmt.Errorf("step %d (%s): invalid regex pattern for property '%s': %w", index+1, step.Name, propName, err)
+		if _, err := expr.ParseCondition(propPattern, false); err != nil {
+			if expr.IsComparison(propPattern) {
+				return fmt.Errorf("%s: invalid comparison for property '%s': %w", prefix, propName, err)
+			}
+			return fmt.Errorf("%s: invalid regex pattern for property '%s': %w", prefix, propName, err)
 		}
 	}
-
 	return nil
 }
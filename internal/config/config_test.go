@@ -1,9 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/expr"
+	"github.com/parfenovvs/loglion/internal/parser"
 )
 
 func TestLoadParserConfig(t *testing.T) {
@@ -41,6 +46,115 @@ log_line_regex: "[invalid"`,
 			expectError: true,
 			errorMsg:    "invalid log_line_regex",
 		},
+		{
+			name: "parser_config_with_filter",
+			content: `event_regex: "valid"
+filter:
+  level: warn
+  tags: [Analytics]
+  exclude_tags: [chatty]
+  pids: [1234]`,
+			expectError: false,
+		},
+		{
+			name: "jsonl_parser_config",
+			content: `format: jsonl
+timestamp_format: "2006-01-02T15:04:05Z07:00"
+fields:
+  timestamp: ts
+  level: severity
+  message: msg
+  event: payload`,
+			expectError: false,
+		},
+		{
+			name: "property_regexes",
+			content: `event_regex: "valid"
+property_regexes:
+  user_id: "uid=(\\d+)"`,
+			expectError: false,
+		},
+		{
+			name: "timestamp_timezone_and_year",
+			content: `format: android-logcat
+timestamp_timezone: "Europe/Berlin"
+assume_year: 2025`,
+			expectError: false,
+		},
+		{
+			name: "year_from_mtime",
+			content: `format: android-logcat
+assume_year: mtime`,
+			expectError: false,
+		},
+		{
+			name: "invalid_timestamp_timezone",
+			content: `format: android-logcat
+timestamp_timezone: "Mars/Olympus"`,
+			expectError: true,
+			errorMsg:    "invalid timestamp_timezone",
+		},
+		{
+			name: "invalid_assume_year",
+			content: `format: android-logcat
+assume_year: last`,
+			expectError: true,
+			errorMsg:    "invalid assume_year 'last'",
+		},
+		{
+			name: "invalid_multiline_start_regex",
+			content: `event_regex: "valid"
+multiline_start_regex: "[invalid"`,
+			expectError: true,
+			errorMsg:    "invalid multiline_start_regex",
+		},
+		{
+			name: "redact",
+			content: `event_regex: "valid"
+redact:
+  - pattern: "[\\w.+-]+@[\\w-]+\\.[\\w.]+"
+    replacement: "<email>"
+  - pattern: "token=\\w+"`,
+			expectError: false,
+		},
+		{
+			name: "invalid_redact_pattern",
+			content: `event_regex: "valid"
+redact:
+  - pattern: "[invalid"`,
+			expectError: true,
+			errorMsg:    "invalid redact[0].pattern",
+		},
+		{
+			name: "invalid_property_regex",
+			content: `event_regex: "valid"
+property_regexes:
+  user_id: "[invalid"`,
+			expectError: true,
+			errorMsg:    "invalid property_regexes.user_id",
+		},
+		{
+			name:        "unknown_format",
+			content:     `format: xml`,
+			expectError: true,
+			errorMsg:    "invalid format 'xml'",
+		},
+		{
+			name: "fields_without_jsonl",
+			content: `event_regex: "valid"
+fields:
+  timestamp: ts`,
+			expectError: true,
+			errorMsg:    "fields are only supported with format: jsonl",
+		},
+		{
+			name: "invalid_filter_level",
+			content: `event_regex: "valid"
+filter:
+  level: loud`,
+			expectError: true,
+			errorMsg:    "invalid filter.level",
+		},
 	}
 
 	for _, tt := range tests {
@@ -146,6 +260,69 @@ steps:
 			expectError: true,
 			errorMsg:    "invalid regex pattern for property",
 		},
+		{
+			name: "typed_property_comparisons",
+			content: `name: "Test"
+steps:
+  - name: "Step1"
+    event_pattern: "test"
+    required_properties:
+      amount: ">= 10"
+      retry_count: "< 3"
+      premium: "== true"`,
+			expectError: false,
+		},
+		{
+			name: "invalid_property_comparison",
+			content: `name: "Test"
+steps:
+  - name: "Step1"
+    event_pattern: "test"
+    required_properties:
+      amount: ">= ten"`,
+			expectError: true,
+			errorMsg:    "invalid comparison for property 'amount': '>=' needs a number",
+		},
+		{
+			name: "any_of_branches",
+			content: `name: "Test"
+steps:
+  - name: "Sign In"
+    any_of:
+      - name: "Google"
+        event_pattern: "google_signin"
+      - event_pattern: "apple_signin"
+        required_properties:
+          region: "^eu$"`,
+			expectError: false,
+		},
+		{
+			name: "step_without_pattern",
+			content: `name: "Test"
+steps:
+  - name: "Step1"`,
+			expectError: true,
+		},
+		{
+			name: "any_of_combined_with_event_pattern",
+			content: `name: "Test"
+steps:
+  - name: "Step1"
+    event_pattern: "test"
+    any_of:
+      - event_pattern: "other"`,
+			expectError: true,
+		},
+		{
+			name: "any_of_invalid_regex",
+			content: `name: "Test"
+steps:
+  - name: "Step1"
+    any_of:
+      - event_pattern: "[invalid"`,
+			expectError: true,
+			errorMsg:    "branch 1: invalid event_pattern regex",
+		},
 	}
 
 	for _, tt := range tests {
@@ -180,6 +357,132 @@ steps:
 	}
 }
 
+func TestLoadFunnelConfigs(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantNames   []string
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name: "single_funnel",
+			content: `name: "Login"
+steps:
+  - name: "Step1"
+    event_pattern: "login"`,
+			wantNames: []string{"Login"},
+		},
+		{
+			name: "multiple_funnels",
+			content: `funnels:
+  - name: "Login"
+    steps:
+      - name: "Step1"
+        event_pattern: "login"
+  - name: "Purchase"
+    steps:
+      - name: "Cart"
+        event_pattern: "add_cart"
+      - name: "Pay"
+        event_pattern: "purchase"`,
+			wantNames: []string{"Login", "Purchase"},
+		},
+		{
+			name: "duplicate_funnel_names",
+			content: `funnels:
+  - name: "Login"
+    steps:
+      - name: "Step1"
+        event_pattern: "login"
+  - name: "Login"
+    steps:
+      - name: "Step1"
+        event_pattern: "logout"`,
+			expectError: true,
+			errorMsg:    "duplicate funnel name 'Login'",
+		},
+		{
+			name: "invalid_step_in_second_funnel",
+			content: `funnels:
+  - name: "Login"
+    steps:
+      - name: "Step1"
+        event_pattern: "login"
+  - name: "Broken"
+    steps:
+      - name: "Step1"
+        event_pattern: "[invalid"`,
+			expectError: true,
+			errorMsg:    "funnel 2: step 1 (Step1): invalid event_pattern regex",
+		},
+		{
+			name: "funnels_mixed_with_top_level_steps",
+			content: `name: "Login"
+funnels:
+  - name: "Purchase"
+    steps:
+      - name: "Pay"
+        event_pattern: "purchase"`,
+			expectError: true,
+			errorMsg:    "schema validation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), "funnel.yaml")
+			if err := os.WriteFile(tmpFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			configs, err := LoadFunnelConfigs(tmpFile)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', but got none", tt.errorMsg)
+				} else if !containsString(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error containing '%s', got: %v", tt.errorMsg, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if len(configs) != len(tt.wantNames) {
+				t.Fatalf("Expected %d funnels, got %d", len(tt.wantNames), len(configs))
+			}
+			for i, name := range tt.wantNames {
+				if configs[i].Name != name {
+					t.Errorf("Funnel %d name = %s, want %s", i, configs[i].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFunnelConfigRejectsMultipleFunnels(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "funnel.yaml")
+	content := `funnels:
+  - name: "Login"
+    steps:
+      - name: "Step1"
+        event_pattern: "login"
+  - name: "Logout"
+    steps:
+      - name: "Step1"
+        event_pattern: "logout"`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	_, err := LoadFunnelConfig(tmpFile)
+	if err == nil || !containsString(err.Error(), "defines 2 funnels") {
+		t.Errorf("Expected error about multiple funnels, got: %v", err)
+	}
+}
+
 func TestParserConfigFileErrors(t *testing.T) {
 	t.Run("empty_filepath", func(t *testing.T) {
 		_, err := LoadParserConfig("")
@@ -316,6 +619,130 @@ func TestParserConfigValidateDefaults(t *testing.T) {
 	}
 }
 
+func TestParserConfigEntryFilter(t *testing.T) {
+	config := &ParserConfig{
+		Filter: FilterConfig{
+			Level:       "error",
+			Tags:        []string{"Analytics"},
+			ExcludeTags: []string{"chatty"},
+			PIDs:        []int{1234},
+		},
+	}
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected no error with valid filter, got: %v", err)
+	}
+
+	filter := config.EntryFilter()
+	if filter.MinLevel != "E" {
+		t.Errorf("Expected level to be normalized to E, got: %s", filter.MinLevel)
+	}
+	if len(filter.Tags) != 1 || len(filter.ExcludeTags) != 1 || len(filter.PIDs) != 1 {
+		t.Errorf("Expected filter lists to be copied, got: %+v", filter)
+	}
+
+	config.Filter = FilterConfig{PIDs: []int{0}}
+	err := config.Validate()
+	if err == nil || !containsString(err.Error(), "invalid filter.pids") {
+		t.Errorf("Expected error about invalid pid, got: %v", err)
+	}
+}
+
+func TestParserConfigNewParser(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     ParserConfig
+		parserType string
+	}{
+		{name: "default", config: ParserConfig{}, parserType: "*parser.PlainParser"},
+		{name: "android logcat", config: ParserConfig{Format: parser.AndroidLogcatFormat}, parserType: "*parser.PlainParser"},
+		{name: "jsonl", config: ParserConfig{Format: parser.JSONLFormat, Fields: FieldsConfig{Event: "payload"}}, parserType: "*parser.JSONLParser"},
+		{name: "syslog", config: ParserConfig{Format: parser.SyslogFormat}, parserType: "*parser.SyslogParser"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); err != nil {
+				t.Fatalf("Expected no error with valid config, got: %v", err)
+			}
+			logParser, err := tt.config.NewParser()
+			if err != nil {
+				t.Fatalf("NewParser() unexpected error: %v", err)
+			}
+			if got := fmt.Sprintf("%T", logParser); got != tt.parserType {
+				t.Errorf("NewParser() = %s, want %s", got, tt.parserType)
+			}
+		})
+	}
+
+	jsonl := &ParserConfig{Format: parser.JSONLFormat, Fields: FieldsConfig{Event: "payload"}}
+	logParser, _ := jsonl.NewParser()
+	entry, err := logParser.Parse(`{"timestamp":"2025-01-15T10:30:00Z","payload":{"event":"login"}}`)
+	if err != nil {
+		t.Fatalf("Expected jsonl parser to parse record, got: %v", err)
+	}
+	if entry.EventData["event"] != "login" || entry.Timestamp.IsZero() {
+		t.Errorf("Expected jsonl field mapping to apply, got: %+v", entry)
+	}
+
+	logcat := &ParserConfig{Format: parser.AndroidLogcatFormat, JSONExtraction: true}
+	if err := logcat.Validate(); err != nil {
+		t.Fatalf("Expected no error with android-logcat config, got: %v", err)
+	}
+	logParser, _ = logcat.NewParser()
+	entry, err = logParser.Parse(`01-15 10:30:45.123  1234  5678 I Analytics: {"event":"login"}`)
+	if err != nil {
+		t.Fatalf("Expected android-logcat parser to parse line, got: %v", err)
+	}
+	if entry.Tag != "Analytics" || entry.PID != 1234 || entry.EventData["event"] != "login" {
+		t.Errorf("Expected threadtime layout to apply, got: %+v", entry)
+	}
+
+	withProperties := &ParserConfig{PropertyRegexes: map[string]string{"user_id": `uid=(\d+)`}}
+	if err := withProperties.Validate(); err != nil {
+		t.Fatalf("Expected no error with property regexes, got: %v", err)
+	}
+	logParser, _ = withProperties.NewParser()
+	entry, err = logParser.Parse("login uid=42")
+	if err != nil || entry.EventData["user_id"] != "42" {
+		t.Errorf("Expected property regexes to apply, got: %+v, %v", entry, err)
+	}
+
+	withRedaction := &ParserConfig{
+		PropertyRegexes: map[string]string{"email": `email=(\S+)`},
+		Redact:          []RedactionRule{{Pattern: `\S+@\S+`}},
+	}
+	if err := withRedaction.Validate(); err != nil {
+		t.Fatalf("Expected no error with redaction rules, got: %v", err)
+	}
+	logParser, _ = withRedaction.NewParser()
+	entry, err = logParser.Parse("login email=alice@example.com")
+	if err != nil || entry.Message != "login [REDACTED]" || entry.EventData["email"] != "[REDACTED]" {
+		t.Errorf("Expected redaction to apply to the message and extracted properties, got: %+v, %v", entry, err)
+	}
+
+	withYear := &ParserConfig{Format: parser.AndroidLogcatFormat, TimestampTimezone: "America/New_York", AssumeYear: "2025"}
+	if err := withYear.Validate(); err != nil {
+		t.Fatalf("Expected no error with timezone and year, got: %v", err)
+	}
+	logParser, _ = withYear.NewParser()
+	entry, err = logParser.Parse(`01-15 10:30:45.000  1234  5678 I Analytics: login`)
+	if err != nil {
+		t.Fatalf("Expected android-logcat parser to parse line, got: %v", err)
+	}
+	if want := time.Date(2025, 1, 15, 15, 30, 45, 0, time.UTC); !entry.Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %v, got: %v", want, entry.Timestamp)
+	}
+
+	invalid := &ParserConfig{Format: "xml"}
+	if err := invalid.Validate(); err == nil || !containsString(err.Error(), "invalid format 'xml' (valid: android-logcat, jsonl, logcat-json, plain, syslog)") {
+		t.Errorf("Expected error about invalid format, got: %v", err)
+	}
+	if _, err := invalid.NewParser(); err == nil {
+		t.Error("Expected NewParser() error for unknown format")
+	}
+}
+
 func TestFunnelConfigValidateStepLimits(t *testing.T) {
 	config := &FunnelConfig{
 		Name:  "Test",
@@ -339,6 +766,157 @@ func TestFunnelConfigValidateStepLimits(t *testing.T) {
 	}
 }
 
+func TestFunnelConfigValidateFailIfMoreThan(t *testing.T) {
+	limit := -1
+	config := &FunnelConfig{
+		Name: "Test",
+		Steps: []Step{
+			{Name: "Screen", EventPattern: "screen_view", FailIfMoreThan: &limit},
+		},
+	}
+
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("Expected error for negative fail_if_more_than")
+	}
+	if !containsString(err.Error(), "fail_if_more_than cannot be negative") {
+		t.Errorf("Expected fail_if_more_than error, got: %v", err)
+	}
+
+	for _, limit = range []int{0, 500} {
+		if err := config.Validate(); err != nil {
+			t.Errorf("fail_if_more_than %d: expected no error, got: %v", limit, err)
+		}
+	}
+}
+
+func TestLoadFunnelConfigFailIfMoreThanZero(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"funnel.yaml": `name: "Checkout"
+steps:
+  - name: "View"
+    event_pattern: "view"
+  - name: "Error"
+    event_pattern: "payment_error"
+    fail_if_more_than: 0
+`,
+	})
+
+	config, err := LoadFunnelConfig(filepath.Join(dir, "funnel.yaml"))
+	if err != nil {
+		t.Fatalf("Expected fail_if_more_than: 0 to load, got: %v", err)
+	}
+	if config.Steps[0].FailIfMoreThan != nil {
+		t.Errorf("Expected no limit on step without fail_if_more_than, got %d", *config.Steps[0].FailIfMoreThan)
+	}
+	if limit := config.Steps[1].FailIfMoreThan; limit == nil || *limit != 0 {
+		t.Errorf("Expected fail_if_more_than 0, got %v", limit)
+	}
+}
+
+func TestFunnelConfigValidateMinOccurrences(t *testing.T) {
+	config := &FunnelConfig{
+		Name: "Test",
+		Steps: []Step{
+			{Name: "Product View", EventPattern: "view_product", MinOccurrences: -1},
+		},
+	}
+
+	err := config.Validate()
+	if err == nil || !containsString(err.Error(), "min_occurrences cannot be negative") {
+		t.Errorf("Expected min_occurrences error, got: %v", err)
+	}
+
+	limit := 2
+	config.Steps[0].MinOccurrences = 3
+	config.Steps[0].FailIfMoreThan = &limit
+	err = config.Validate()
+	if err == nil || !containsString(err.Error(), "min_occurrences cannot exceed fail_if_more_than") {
+		t.Errorf("Expected min_occurrences above fail_if_more_than error, got: %v", err)
+	}
+
+	config.Steps[0].FailIfMoreThan = nil
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+func TestFunnelConfigValidateAssertions(t *testing.T) {
+	tests := []struct {
+		name        string
+		step        Step
+		expectError string
+	}{
+		{name: "valid thresholds", step: Step{Name: "Buy", EventPattern: "buy", MinCount: 10, MinConversionPct: 25}},
+		{name: "negative min_count", step: Step{Name: "Buy", EventPattern: "buy", MinCount: -1}, expectError: "min_count cannot be negative"},
+		{name: "negative min_conversion_pct", step: Step{Name: "Buy", EventPattern: "buy", MinConversionPct: -5}, expectError: "min_conversion_pct must be between 0 and 100"},
+		{name: "min_conversion_pct above 100", step: Step{Name: "Buy", EventPattern: "buy", MinConversionPct: 120}, expectError: "min_conversion_pct must be between 0 and 100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &FunnelConfig{Name: "Test", Steps: []Step{tt.step}}
+			err := config.Validate()
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !containsString(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestFunnelConfigValidateExcludePattern(t *testing.T) {
+	tests := []struct {
+		name        string
+		steps       []Step
+		expectError string
+	}{
+		{
+			name: "valid exclude pattern",
+			steps: []Step{
+				{Name: "Cart", EventPattern: "add_to_cart"},
+				{Name: "Purchase", EventPattern: "purchase", ExcludePattern: "purchase_error"},
+			},
+		},
+		{
+			name: "exclude pattern on first step",
+			steps: []Step{
+				{Name: "Cart", EventPattern: "add_to_cart", ExcludePattern: "cart_error"},
+			},
+			expectError: "exclude_pattern cannot be used on the first step",
+		},
+		{
+			name: "invalid exclude regex",
+			steps: []Step{
+				{Name: "Cart", EventPattern: "add_to_cart"},
+				{Name: "Purchase", EventPattern: "purchase", ExcludePattern: "[invalid"},
+			},
+			expectError: "invalid exclude_pattern regex",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &FunnelConfig{Name: "Test", Steps: tt.steps}
+			err := config.Validate()
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !containsString(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
 // Helper function to check if a string contains a substring
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr ||
@@ -355,3 +933,252 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestFunnelConfigValidateAnyOf(t *testing.T) {
+	tests := []struct {
+		name        string
+		step        Step
+		expectError string
+	}{
+		{
+			name: "valid branches",
+			step: Step{Name: "Sign In", AnyOf: []StepBranch{
+				{Name: "Google", EventPattern: "google_signin"},
+				{EventPattern: "apple_signin"},
+			}},
+		},
+		{
+			name:        "missing pattern",
+			step:        Step{Name: "Sign In"},
+			expectError: "event_pattern, any_of or expr is required",
+		},
+		{
+			name: "combined with event_pattern",
+			step: Step{Name: "Sign In", EventPattern: "signin", AnyOf: []StepBranch{
+				{EventPattern: "google_signin"},
+			}},
+			expectError: "event_pattern and any_of cannot be combined",
+		},
+		{
+			name: "branch without pattern",
+			step: Step{Name: "Sign In", AnyOf: []StepBranch{
+				{Name: "Google"},
+			}},
+			expectError: "branch 1: event_pattern is required",
+		},
+		{
+			name: "duplicate branch",
+			step: Step{Name: "Sign In", AnyOf: []StepBranch{
+				{EventPattern: "google_signin"},
+				{Name: "google_signin", EventPattern: "gsi"},
+			}},
+			expectError: "branch 2: duplicate branch 'google_signin'",
+		},
+		{
+			name: "invalid branch property",
+			step: Step{Name: "Sign In", AnyOf: []StepBranch{
+				{EventPattern: "google_signin", RequiredProperties: map[string]string{"region": "[invalid"}},
+			}},
+			expectError: "branch 1: invalid regex pattern for property 'region'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &FunnelConfig{Name: "Test", Steps: []Step{tt.step}}
+			err := config.Validate()
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !containsString(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestFunnelConfigValidateExpr(t *testing.T) {
+	if !expr.Supported {
+		t.Skip("CEL expressions are not compiled in (-tags noexpr)")
+	}
+	tests := []struct {
+		name        string
+		step        Step
+		expectError string
+	}{
+		{
+			name: "valid expr",
+			step: Step{Name: "Purchase", Expr: `event == "purchase" && double(props.amount) > 9.99`},
+		},
+		{
+			name:        "combined with event_pattern",
+			step:        Step{Name: "Purchase", EventPattern: "purchase", Expr: `event == "purchase"`},
+			expectError: "expr cannot be combined with event_pattern or any_of",
+		},
+		{
+			name:        "syntax error",
+			step:        Step{Name: "Purchase", Expr: `event ==`},
+			expectError: "step 1 (Purchase): invalid expr",
+		},
+		{
+			name:        "unknown variable",
+			step:        Step{Name: "Purchase", Expr: `amount > 10`},
+			expectError: "undeclared reference to 'amount'",
+		},
+		{
+			name:        "not a condition",
+			step:        Step{Name: "Purchase", Expr: `props.amount`},
+			expectError: "expression must evaluate to a bool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &FunnelConfig{Name: "Test", Steps: []Step{tt.step}}
+			err := config.Validate()
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !containsString(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestLoadFunnelConfigGroupBy(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	singleFile := filepath.Join(tmpDir, "single.yaml")
+	content := `name: "Checkout"
+group_by: user_id
+steps:
+  - name: "View"
+    event_pattern: "view"`
+	if err := os.WriteFile(singleFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config, err := LoadFunnelConfig(singleFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if config.GroupBy != "user_id" {
+		t.Errorf("Expected group_by to be user_id, got: %q", config.GroupBy)
+	}
+
+	multiFile := filepath.Join(tmpDir, "multi.yaml")
+	content = `funnels:
+  - name: "Checkout"
+    group_by: session_id
+    steps:
+      - name: "View"
+        event_pattern: "view"`
+	if err := os.WriteFile(multiFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	configs, err := LoadFunnelConfigs(multiFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if configs[0].GroupBy != "session_id" {
+		t.Errorf("Expected group_by to be session_id, got: %q", configs[0].GroupBy)
+	}
+}
+
+func TestLoadFunnelConfigCaseInsensitive(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	configFile := filepath.Join(tmpDir, "funnel.yaml")
+	content := `name: "Checkout"
+case_insensitive: true
+steps:
+  - name: "View"
+    event_pattern: "view"
+  - name: "Purchase"
+    event_pattern: "Purchase"
+    case_insensitive: false
+  - name: "Receipt"
+    any_of:
+      - event_pattern: "receipt"
+        case_insensitive: false`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config, err := LoadFunnelConfig(configFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !config.CaseInsensitive {
+		t.Error("Expected case_insensitive to be set on the funnel")
+	}
+	if !config.Steps[0].IgnoresCase(config.CaseInsensitive) {
+		t.Error("Expected step without case_insensitive to inherit the funnel setting")
+	}
+	if config.Steps[1].IgnoresCase(config.CaseInsensitive) {
+		t.Error("Expected step case_insensitive to override the funnel setting")
+	}
+	receipt := config.Steps[2]
+	if receipt.AnyOf[0].IgnoresCase(receipt.IgnoresCase(config.CaseInsensitive)) {
+		t.Error("Expected branch case_insensitive to override the step setting")
+	}
+}
+
+func TestLoadFunnelConfigMode(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	configFile := filepath.Join(tmpDir, "funnel.yaml")
+	content := `funnels:
+  - name: "Checkout"
+    mode: strict
+    steps:
+      - name: "View"
+        event_pattern: "view"
+  - name: "Onboarding"
+    mode: unordered
+    anchor_first_step: true
+    steps:
+      - name: "Profile"
+        event_pattern: "profile"`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	configs, err := LoadFunnelConfigs(configFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if configs[0].Mode != ModeStrict || configs[1].Mode != ModeUnordered {
+		t.Errorf("Expected strict and unordered modes, got %q and %q", configs[0].Mode, configs[1].Mode)
+	}
+	if configs[0].AnchorFirstStep || !configs[1].AnchorFirstStep {
+		t.Error("Expected only the unordered funnel to anchor the first step")
+	}
+
+	invalid := &FunnelConfig{Name: "Test", Mode: "random", Steps: []Step{{Name: "View", EventPattern: "view"}}}
+	err = invalid.Validate()
+	if err == nil || !containsString(err.Error(), "invalid mode 'random'") {
+		t.Errorf("Expected invalid mode error, got: %v", err)
+	}
+
+	invalidFile := filepath.Join(tmpDir, "invalid.yaml")
+	content = `name: "Checkout"
+mode: sequential
+steps:
+  - name: "View"
+    event_pattern: "view"`
+	if err := os.WriteFile(invalidFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := LoadFunnelConfig(invalidFile); err == nil {
+		t.Error("Expected error for unknown mode in config file")
+	}
+}
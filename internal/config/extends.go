@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// extendsKey names the base funnel config file a funnel inherits from, a path
+// relative to the extending file or a URL
+const extendsKey = "extends"
+
+// stepMatchers are the step keys of which a step has exactly one
+var stepMatchers = []string{"event_pattern", "any_of", "expr"}
+
+// resolveExtends replaces every funnel in the YAML of the funnel config file
+// at path that extends a base funnel with the base funnel merged with its own
+// fields. Fields of the extending funnel replace those of the base funnel,
+// except steps: a step with the name of a base step overrides fields of that
+// step, other steps are appended. Data without extends is returned unchanged.
+// A remote funnel config can only extend other remote configs, and one pinned
+// with a #sha256= checksum only other pinned configs.
+func resolveExtends(data []byte, path string) ([]byte, error) {
+	trusted := ""
+	if IsURL(path) {
+		trusted = path
+	}
+	return resolveExtendsFrom(data, path, trusted, map[string]bool{})
+}
+
+// resolveExtendsFrom resolves the extends of the funnel config at path.
+// trusted is the first URL of the chain of extends, which the user gave on the
+// command line or in a local config, and the only origin remote headers are
+// sent to.
+func resolveExtendsFrom(data []byte, path, trusted string, visited map[string]bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		// Left for the loader to report
+		return data, nil
+	}
+	root := doc.Content[0]
+
+	funnels := []*yaml.Node{root}
+	if list := mappingValue(root, "funnels"); list != nil && list.Kind == yaml.SequenceNode {
+		funnels = list.Content
+	}
+
+	extended := false
+	for _, funnel := range funnels {
+		if funnel.Kind != yaml.MappingNode {
+			continue
+		}
+		base := mappingValue(funnel, extendsKey)
+		if base == nil {
+			continue
+		}
+		if base.Kind != yaml.ScalarNode || base.Value == "" {
+			return nil, fmt.Errorf("%s must be the path of a funnel config file", extendsKey)
+		}
+
+		basePath := resolveConfigPath(path, base.Value)
+		if IsURL(path) && !IsURL(basePath) {
+			return nil, fmt.Errorf("remote funnel config '%s' cannot extend local file '%s'", path, basePath)
+		}
+		// The checksum of a pinned config would not cover an unpinned base
+		if isPinned(path) && !isPinned(basePath) {
+			return nil, fmt.Errorf("checksum-pinned funnel config '%s' cannot extend '%s' without a checksum; pin it with #sha256=<hex digest>", path, basePath)
+		}
+		baseTrusted := trusted
+		if baseTrusted == "" && IsURL(basePath) {
+			baseTrusted = basePath
+		}
+
+		baseNode, err := loadBaseFunnel(basePath, baseTrusted, visited)
+		if err != nil {
+			return nil, err
+		}
+		merged := mergeFunnel(baseNode, funnel)
+		*funnel = *merged
+		extended = true
+	}
+
+	if !extended {
+		return data, nil
+	}
+	return yaml.Marshal(&doc)
+}
+
+// loadBaseFunnel reads the single funnel of a base funnel config file,
+// resolving its own extends
+func loadBaseFunnel(path, trusted string, visited map[string]bool) (*yaml.Node, error) {
+	if visited[path] {
+		return nil, fmt.Errorf("funnel config '%s' extends itself", path)
+	}
+	visited[path] = true
+	defer delete(visited, path)
+
+	logrus.WithField("base", path).Debug("Loading base funnel config")
+	data, err := readConfigFileTrusting(path, trusted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base funnel config '%s': %w", path, err)
+	}
+	data, err = configSection(data, FunnelSection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base funnel config '%s': %w", path, err)
+	}
+	data, err = resolveExtendsFrom(data, path, trusted, visited)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse base funnel config '%s': %w", path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("base funnel config '%s' must be a mapping", path)
+	}
+	if mappingValue(doc.Content[0], "funnels") != nil {
+		return nil, fmt.Errorf("base funnel config '%s' must define a single funnel, not a funnels list", path)
+	}
+	return doc.Content[0], nil
+}
+
+// resolveConfigPath resolves a path given in the config file at from
+func resolveConfigPath(from, path string) string {
+	if IsURL(path) || filepath.IsAbs(path) {
+		return path
+	}
+	if IsURL(from) {
+		if base, err := url.Parse(from); err == nil {
+			if ref, err := url.Parse(path); err == nil {
+				return base.ResolveReference(ref).String()
+			}
+		}
+	}
+	return filepath.Join(filepath.Dir(from), path)
+}
+
+// mergeFunnel returns base with the fields of funnel, except extends
+func mergeFunnel(base, funnel *yaml.Node) *yaml.Node {
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: base.Tag, Content: append([]*yaml.Node(nil), base.Content...)}
+	for i := 0; i+1 < len(funnel.Content); i += 2 {
+		key, value := funnel.Content[i].Value, funnel.Content[i+1]
+		switch {
+		case key == extendsKey:
+			continue
+		case key == "steps":
+			if baseSteps := mappingValue(merged, "steps"); baseSteps != nil && baseSteps.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode {
+				setMappingValue(merged, key, mergeSteps(baseSteps, value))
+				continue
+			}
+		}
+		setMappingValue(merged, key, value)
+	}
+	return merged
+}
+
+// mergeSteps overrides the base steps with the steps of the same name and
+// appends the others. A step that sets how it matches events replaces the
+// matcher of the base step, e.g. an event_pattern replaces any_of.
+func mergeSteps(base, steps *yaml.Node) *yaml.Node {
+	merged := &yaml.Node{Kind: yaml.SequenceNode, Tag: base.Tag, Content: append([]*yaml.Node(nil), base.Content...)}
+
+	for _, step := range steps.Content {
+		name := mappingValue(step, "name")
+		index := -1
+		for i, baseStep := range merged.Content {
+			if baseName := mappingValue(baseStep, "name"); name != nil && baseName != nil && baseName.Value == name.Value {
+				index = i
+				break
+			}
+		}
+		if index < 0 || step.Kind != yaml.MappingNode || merged.Content[index].Kind != yaml.MappingNode {
+			merged.Content = append(merged.Content, step)
+			continue
+		}
+
+		overridden := &yaml.Node{Kind: yaml.MappingNode, Content: append([]*yaml.Node(nil), merged.Content[index].Content...)}
+		for _, matcher := range stepMatchers {
+			if mappingValue(step, matcher) != nil {
+				for _, other := range stepMatchers {
+					deleteMappingValue(overridden, other)
+				}
+				break
+			}
+		}
+		for i := 0; i+1 < len(step.Content); i += 2 {
+			setMappingValue(overridden, step.Content[i].Value, step.Content[i+1])
+		}
+		merged.Content[index] = overridden
+	}
+	return merged
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces the value of key in a mapping node, or adds it
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+// deleteMappingValue removes key from a mapping node
+func deleteMappingValue(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const baseFunnel = `name: "Checkout"
+group_by: user_id
+steps:
+  - name: "View"
+    event_pattern: "^product_view$"
+  - name: "Cart"
+    event_pattern: "^add_to_cart$"
+    required_properties:
+      currency: "^USD$"
+  - name: "Purchase"
+    any_of:
+      - event_pattern: "^purchase$"
+      - event_pattern: "^order_placed$"
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadFunnelConfigExtends(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base/checkout.yaml": baseFunnel,
+		"brands/acme.yaml": `extends: ../base/checkout.yaml
+name: "Acme Checkout"
+steps:
+  - name: "Cart"
+    event_pattern: "^acme_cart$"
+  - name: "Purchase"
+    event_pattern: "^acme_purchase$"
+  - name: "Review"
+    event_pattern: "^review$"
+`,
+	})
+
+	funnelCfg, err := LoadFunnelConfig(filepath.Join(dir, "brands", "acme.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error: %v", err)
+	}
+
+	if funnelCfg.Name != "Acme Checkout" || funnelCfg.GroupBy != "user_id" {
+		t.Errorf("Expected the own name and the base group_by, got %q and %q", funnelCfg.Name, funnelCfg.GroupBy)
+	}
+	var names []string
+	for _, step := range funnelCfg.Steps {
+		names = append(names, step.Name)
+	}
+	if strings.Join(names, ",") != "View,Cart,Purchase,Review" {
+		t.Fatalf("Expected the base steps followed by the new step, got %v", names)
+	}
+	if funnelCfg.Steps[0].EventPattern != "^product_view$" {
+		t.Errorf("Expected the base View step, got %+v", funnelCfg.Steps[0])
+	}
+	cart := funnelCfg.Steps[1]
+	if cart.EventPattern != "^acme_cart$" || cart.RequiredProperties["currency"] != "^USD$" {
+		t.Errorf("Expected the overridden pattern with the base properties, got %+v", cart)
+	}
+	purchase := funnelCfg.Steps[2]
+	if purchase.EventPattern != "^acme_purchase$" || len(purchase.AnyOf) != 0 {
+		t.Errorf("Expected the event_pattern to replace any_of, got %+v", purchase)
+	}
+}
+
+func TestLoadFunnelConfigsExtendsInList(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"checkout.yaml": baseFunnel,
+		"brands.yaml": `funnels:
+  - extends: checkout.yaml
+    name: "Acme"
+  - extends: checkout.yaml
+    name: "Globex"
+    steps:
+      - name: "View"
+        event_pattern: "^globex_view$"
+`,
+	})
+
+	funnelCfgs, err := LoadFunnelConfigs(filepath.Join(dir, "brands.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFunnelConfigs() unexpected error: %v", err)
+	}
+	if len(funnelCfgs) != 2 || len(funnelCfgs[0].Steps) != 3 || len(funnelCfgs[1].Steps) != 3 {
+		t.Fatalf("Expected two funnels with the base steps, got %d", len(funnelCfgs))
+	}
+	if funnelCfgs[0].Steps[0].EventPattern != "^product_view$" || funnelCfgs[1].Steps[0].EventPattern != "^globex_view$" {
+		t.Errorf("Expected only Globex to override View, got %q and %q", funnelCfgs[0].Steps[0].EventPattern, funnelCfgs[1].Steps[0].EventPattern)
+	}
+}
+
+func TestLoadFunnelConfigExtendsChainAndURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/funnels/base.yaml":
+			w.Write([]byte(baseFunnel))
+		case "/funnels/regional.yaml":
+			w.Write([]byte("extends: base.yaml\nname: Regional\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := writeFiles(t, map[string]string{
+		"funnel.yaml": "extends: " + server.URL + "/funnels/regional.yaml\nname: Local\n",
+	})
+	funnelCfg, err := LoadFunnelConfig(filepath.Join(dir, "funnel.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error: %v", err)
+	}
+	if funnelCfg.Name != "Local" || len(funnelCfg.Steps) != 3 {
+		t.Errorf("Expected the base steps through the chain, got %+v", funnelCfg)
+	}
+}
+
+func TestLoadFunnelConfigExtendsRemoteHeaders(t *testing.T) {
+	t.Cleanup(func() { SetRemoteOptions(RemoteOptions{}) })
+
+	var otherAuthorization string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherAuthorization = r.Header.Get("Authorization")
+		w.Write([]byte(baseFunnel))
+	}))
+	defer other.Close()
+
+	authorizations := map[string]string{}
+	trusted := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations[r.URL.Path] = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/funnel.yaml":
+			w.Write([]byte("extends: regional.yaml\nname: Remote\n"))
+		case "/regional.yaml":
+			w.Write([]byte("extends: " + other.URL + "/base.yaml\nname: Regional\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer trusted.Close()
+
+	SetRemoteOptions(RemoteOptions{Headers: map[string]string{"Authorization": "Bearer token"}})
+	funnelCfg, err := LoadFunnelConfig(trusted.URL + "/funnel.yaml")
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error: %v", err)
+	}
+	if funnelCfg.Name != "Remote" || len(funnelCfg.Steps) != 3 {
+		t.Errorf("Expected the base steps through the chain, got %+v", funnelCfg)
+	}
+
+	for _, path := range []string{"/funnel.yaml", "/regional.yaml"} {
+		if authorizations[path] != "Bearer token" {
+			t.Errorf("Expected the Authorization header with %s on the given host, got %q", path, authorizations[path])
+		}
+	}
+	if otherAuthorization != "" {
+		t.Errorf("Expected no Authorization header for a base on another host, got %q", otherAuthorization)
+	}
+}
+
+func TestLoadFunnelConfigExtendsLocalFromRemote(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"base.yaml": baseFunnel})
+	basePath := filepath.Join(dir, "base.yaml")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("extends: " + basePath + "\nname: Remote\n"))
+	}))
+	defer server.Close()
+
+	_, err := LoadFunnelConfig(server.URL + "/funnel.yaml")
+	if err == nil || !strings.Contains(err.Error(), "cannot extend local file '"+basePath+"'") {
+		t.Errorf("Expected error for a remote config extending a local file, got %v", err)
+	}
+}
+
+func TestLoadFunnelConfigExtendsFromPinned(t *testing.T) {
+	pinned := func(content string) string {
+		return fmt.Sprintf("#sha256=%x", sha256.Sum256([]byte(content)))
+	}
+	baseSum := pinned(baseFunnel)
+	unpinned := "extends: base.yaml\nname: Pinned\n"
+	pinnedBase := "extends: base.yaml" + baseSum + "\nname: Pinned\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/base.yaml":
+			w.Write([]byte(baseFunnel))
+		case "/unpinned.yaml":
+			w.Write([]byte(unpinned))
+		case "/pinned.yaml":
+			w.Write([]byte(pinnedBase))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	_, err := LoadFunnelConfig(server.URL + "/unpinned.yaml" + pinned(unpinned))
+	if err == nil || !strings.Contains(err.Error(), "cannot extend '"+server.URL+"/base.yaml' without a checksum") {
+		t.Errorf("Expected error for a pinned config extending an unpinned URL, got %v", err)
+	}
+
+	funnelCfg, err := LoadFunnelConfig(server.URL + "/pinned.yaml" + pinned(pinnedBase))
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error for a pinned chain: %v", err)
+	}
+	if funnelCfg.Name != "Pinned" || len(funnelCfg.Steps) != 3 {
+		t.Errorf("Expected the pinned base steps, got %+v", funnelCfg)
+	}
+}
+
+func TestLoadFunnelConfigExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		errContains string
+	}{
+		{
+			name:        "missing base",
+			files:       map[string]string{"funnel.yaml": "extends: missing.yaml\nname: A\n"},
+			errContains: "failed to read base funnel config",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"funnel.yaml": "extends: other.yaml\nname: A\n",
+				"other.yaml":  "extends: funnel.yaml\nname: B\n",
+			},
+			errContains: "extends itself",
+		},
+		{
+			name: "base with funnels list",
+			files: map[string]string{
+				"funnel.yaml": "extends: list.yaml\nname: A\n",
+				"list.yaml":   "funnels:\n  - name: B\n    steps:\n      - name: S\n        event_pattern: s\n",
+			},
+			errContains: "must define a single funnel",
+		},
+		{
+			name: "invalid merged funnel",
+			files: map[string]string{
+				"funnel.yaml": "extends: base.yaml\nsteps:\n  - name: Extra\n",
+				"base.yaml":   "name: Base\nsteps:\n  - name: S\n    event_pattern: s\n",
+			},
+			errContains: "steps.1: event_pattern is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			_, err := LoadFunnelConfigs(filepath.Join(dir, "funnel.yaml"))
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StepGraph is the graph of funnel steps an attempt can move through. Steps
+// with any_of have one node per branch; every node of a step leads to every
+// node of the next step.
+type StepGraph struct {
+	Funnel string
+	Nodes  []StepNode
+	// Edges lists the indexes of the nodes each node leads to
+	Edges [][]int
+
+	config *FunnelConfig
+}
+
+// StepNode is a step, or one branch of a step with any_of
+type StepNode struct {
+	Step int
+	// Branch is the index of the any_of branch, -1 for steps without branches
+	Branch int
+	Label  string
+}
+
+// BuildStepGraph builds the step graph of a funnel
+func BuildStepGraph(c *FunnelConfig) *StepGraph {
+	graph := &StepGraph{Funnel: c.Name, config: c}
+
+	var previous []int
+	for stepIndex, step := range c.Steps {
+		var current []int
+		if len(step.AnyOf) == 0 {
+			current = append(current, graph.addNode(StepNode{Step: stepIndex, Branch: -1, Label: step.Name}))
+		} else {
+			for branchIndex, branch := range step.AnyOf {
+				current = append(current, graph.addNode(StepNode{Step: stepIndex, Branch: branchIndex, Label: branch.Label()}))
+			}
+		}
+
+		for _, from := range previous {
+			graph.Edges[from] = append(graph.Edges[from], current...)
+		}
+		previous = current
+	}
+
+	return graph
+}
+
+func (g *StepGraph) addNode(node StepNode) int {
+	g.Nodes = append(g.Nodes, node)
+	g.Edges = append(g.Edges, nil)
+	return len(g.Nodes) - 1
+}
+
+// entryNodes returns the nodes an attempt can start at
+func (g *StepGraph) entryNodes() []int {
+	var entries []int
+	for i, node := range g.Nodes {
+		if node.Step == 0 {
+			entries = append(entries, i)
+		}
+	}
+	return entries
+}
+
+// Validate checks that the graph is acyclic and that every node can be
+// reached from the first step
+func (g *StepGraph) Validate() error {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(g.Nodes))
+
+	var visit func(node int) error
+	visit = func(node int) error {
+		state[node] = visiting
+		for _, next := range g.Edges[node] {
+			switch state[next] {
+			case visiting:
+				return fmt.Errorf("step graph has a cycle through '%s'", g.Nodes[next].Label)
+			case unvisited:
+				if err := visit(next); err != nil {
+					return err
+				}
+			}
+		}
+		state[node] = done
+		return nil
+	}
+
+	for _, entry := range g.entryNodes() {
+		if state[entry] == unvisited {
+			if err := visit(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, node := range g.Nodes {
+		if state[i] == unvisited {
+			return fmt.Errorf("step %d (%s) is unreachable from the first step", node.Step+1, node.Label)
+		}
+	}
+	return nil
+}
+
+// DOT renders the graph in the Graphviz DOT language. Branches of a step are
+// grouped in a cluster labeled with the step name.
+func (g *StepGraph) DOT() string {
+	var out strings.Builder
+
+	out.WriteString(fmt.Sprintf("digraph %s {\n", dotQuote(g.Funnel)))
+	out.WriteString("  rankdir=LR;\n")
+	out.WriteString("  node [shape=box];\n")
+
+	for stepIndex, step := range g.config.Steps {
+		label := fmt.Sprintf("%d. %s", stepIndex+1, step.Name)
+		if step.ExcludePattern != "" {
+			label += fmt.Sprintf("\nexcludes /%s/", step.ExcludePattern)
+		}
+
+		if len(step.AnyOf) == 0 {
+			out.WriteString(fmt.Sprintf("  %s [label=%s];\n", g.nodeID(stepIndex, -1), dotQuote(label)))
+			continue
+		}
+
+		out.WriteString(fmt.Sprintf("  subgraph cluster_s%d {\n", stepIndex))
+		out.WriteString(fmt.Sprintf("    label=%s;\n", dotQuote(label)))
+		for branchIndex, branch := range step.AnyOf {
+			out.WriteString(fmt.Sprintf("    %s [label=%s];\n", g.nodeID(stepIndex, branchIndex), dotQuote(branch.Label())))
+		}
+		out.WriteString("  }\n")
+	}
+
+	for from, targets := range g.Edges {
+		for _, to := range targets {
+			out.WriteString(fmt.Sprintf("  %s -> %s;\n",
+				g.nodeID(g.Nodes[from].Step, g.Nodes[from].Branch),
+				g.nodeID(g.Nodes[to].Step, g.Nodes[to].Branch)))
+		}
+	}
+
+	out.WriteString("}\n")
+	return out.String()
+}
+
+func (g *StepGraph) nodeID(step, branch int) string {
+	if branch < 0 {
+		return fmt.Sprintf("s%d", step)
+	}
+	return fmt.Sprintf("s%d_%d", step, branch)
+}
+
+// dotQuote quotes s as a DOT string, escaping quotes, backslashes and newlines
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildStepGraph(t *testing.T) {
+	cfg := &FunnelConfig{
+		Name: "Checkout",
+		Steps: []Step{
+			{Name: "View", EventPattern: "view"},
+			{Name: "Sign In", AnyOf: []StepBranch{
+				{Name: "Google", EventPattern: "google"},
+				{EventPattern: "apple"},
+			}},
+			{Name: "Purchase", EventPattern: "purchase"},
+		},
+	}
+
+	graph := BuildStepGraph(cfg)
+	if len(graph.Nodes) != 4 {
+		t.Fatalf("Expected 4 nodes, got %+v", graph.Nodes)
+	}
+	if graph.Nodes[2].Label != "apple" || graph.Nodes[2].Step != 1 || graph.Nodes[2].Branch != 1 {
+		t.Errorf("Unexpected branch node %+v", graph.Nodes[2])
+	}
+
+	expectedEdges := [][]int{{1, 2}, {3}, {3}, nil}
+	for i, edges := range expectedEdges {
+		if len(graph.Edges[i]) != len(edges) {
+			t.Errorf("Node %d: expected edges %v, got %v", i, edges, graph.Edges[i])
+			continue
+		}
+		for j := range edges {
+			if graph.Edges[i][j] != edges[j] {
+				t.Errorf("Node %d: expected edges %v, got %v", i, edges, graph.Edges[i])
+			}
+		}
+	}
+
+	if err := graph.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestStepGraphValidate(t *testing.T) {
+	nodes := []StepNode{
+		{Step: 0, Branch: -1, Label: "View"},
+		{Step: 1, Branch: -1, Label: "Cart"},
+		{Step: 2, Branch: -1, Label: "Purchase"},
+	}
+
+	tests := []struct {
+		name    string
+		edges   [][]int
+		wantErr string
+	}{
+		{
+			name:  "linear",
+			edges: [][]int{{1}, {2}, nil},
+		},
+		{
+			name:    "cycle",
+			edges:   [][]int{{1}, {2}, {1}},
+			wantErr: "step graph has a cycle through 'Cart'",
+		},
+		{
+			name:    "unreachable step",
+			edges:   [][]int{{2}, {2}, nil},
+			wantErr: "step 2 (Cart) is unreachable from the first step",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&StepGraph{Nodes: nodes, Edges: tt.edges}).Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStepGraphDOT(t *testing.T) {
+	cfg := &FunnelConfig{
+		Name: `Sign "Up"`,
+		Steps: []Step{
+			{Name: "View", EventPattern: "view"},
+			{Name: "Sign In", AnyOf: []StepBranch{
+				{Name: "Google", EventPattern: "google"},
+				{EventPattern: "apple"},
+			}},
+			{Name: "Purchase", EventPattern: "purchase", ExcludePattern: `error\d`},
+		},
+	}
+
+	dot := BuildStepGraph(cfg).DOT()
+
+	expected := []string{
+		`digraph "Sign \"Up\"" {`,
+		`  s0 [label="1. View"];`,
+		`  subgraph cluster_s1 {`,
+		`    label="2. Sign In";`,
+		`    s1_0 [label="Google"];`,
+		`    s1_1 [label="apple"];`,
+		`  s2 [label="3. Purchase\nexcludes /error\\d/"];`,
+		`  s0 -> s1_0;`,
+		`  s0 -> s1_1;`,
+		`  s1_1 -> s2;`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(dot, line+"\n") {
+			t.Errorf("DOT() should contain %q, got:\n%s", line, dot)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// OverrideEnvPrefix starts the names of environment variables overriding
+// config fields, e.g. LOGLION_PARSER_EVENT_REGEX for parser.event_regex.
+// Nested fields are separated by a double underscore, e.g.
+// LOGLION_FUNNEL_STEPS__0__EVENT_PATTERN for funnel.steps.0.event_pattern.
+const OverrideEnvPrefix = "LOGLION_"
+
+// Override replaces the value of a parser or funnel config field, given as
+// the path of mapping keys and sequence indexes below the section
+type Override struct {
+	Section string
+	Path    []string
+	Value   string
+}
+
+func (o Override) String() string {
+	return o.Section + "." + strings.Join(o.Path, ".") + "=" + o.Value
+}
+
+var (
+	overridesMu sync.RWMutex
+	overrides   []Override
+)
+
+// SetOverrides sets the overrides applied to every parser and funnel config
+// loaded afterwards. Later overrides of the same field win.
+func SetOverrides(o []Override) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	overrides = o
+}
+
+// ParseOverride parses an override in the form section.path=value, e.g.
+// parser.json_extraction=true or funnel.steps.0.name=Open
+func ParseOverride(s string) (Override, error) {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return Override{}, fmt.Errorf("invalid override '%s': expected section.field=value", s)
+	}
+	return newOverride(strings.Split(key, "."), value, s)
+}
+
+// OverridesFromEnv returns the overrides of the LOGLION_PARSER_* and
+// LOGLION_FUNNEL_* variables of environ, given as key=value pairs like
+// os.Environ, sorted by variable name
+func OverridesFromEnv(environ []string) ([]Override, error) {
+	sort.Strings(environ)
+
+	var result []Override
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		rest, ok := strings.CutPrefix(name, OverrideEnvPrefix)
+		if !ok {
+			continue
+		}
+		section, field, ok := strings.Cut(strings.ToLower(rest), "_")
+		if !ok || (section != ParserSection && section != FunnelSection) {
+			continue
+		}
+		override, err := newOverride(append([]string{section}, strings.Split(field, "__")...), value, name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, override)
+	}
+	return result, nil
+}
+
+func newOverride(keys []string, value, source string) (Override, error) {
+	if len(keys) < 2 || (keys[0] != ParserSection && keys[0] != FunnelSection) {
+		return Override{}, fmt.Errorf("invalid override '%s': field must start with %s. or %s.", source, ParserSection, FunnelSection)
+	}
+	for _, key := range keys[1:] {
+		if key == "" {
+			return Override{}, fmt.Errorf("invalid override '%s': empty field name", source)
+		}
+	}
+	return Override{Section: keys[0], Path: keys[1:], Value: value}, nil
+}
+
+// applyOverrides applies the overrides of section to the YAML of a config,
+// returning data unchanged when there are none
+func applyOverrides(data []byte, section string) ([]byte, error) {
+	overridesMu.RLock()
+	var sectionOverrides []Override
+	for _, override := range overrides {
+		if override.Section == section {
+			sectionOverrides = append(sectionOverrides, override)
+		}
+	}
+	overridesMu.RUnlock()
+	if len(sectionOverrides) == 0 {
+		return data, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s config must be a mapping to apply overrides", section)
+	}
+
+	for _, override := range sectionOverrides {
+		value, err := overrideValue(override.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid override '%s': %w", override, err)
+		}
+		if err := setNode(doc.Content[0], override.Path, value); err != nil {
+			return nil, fmt.Errorf("invalid override '%s': %w", override, err)
+		}
+		logrus.WithField("override", override.String()).Debug("Applied config override")
+	}
+	return yaml.Marshal(&doc)
+}
+
+// overrideValue converts an override value to a YAML node. Flow sequences
+// and mappings such as [a, b] are parsed; anything else is a scalar whose
+// type is resolved like an unquoted YAML value, so true is a boolean.
+func overrideValue(value string) (*yaml.Node, error) {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(trimmed), &doc); err != nil {
+			return nil, err
+		}
+		return doc.Content[0], nil
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}, nil
+}
+
+// setNode replaces the node at path below node with value, creating missing
+// mapping keys
+func setNode(node *yaml.Node, path []string, value *yaml.Node) error {
+	key := path[0]
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			if node.Content[i].Value != key {
+				continue
+			}
+			if len(path) == 1 {
+				node.Content[i+1] = value
+				return nil
+			}
+			return setNode(node.Content[i+1], path[1:], value)
+		}
+		child := value
+		if len(path) > 1 {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			if err := setNode(child, path[1:], value); err != nil {
+				return err
+			}
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+		return nil
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(node.Content) {
+			return fmt.Errorf("no item %s in a list of %d", key, len(node.Content))
+		}
+		if len(path) == 1 {
+			node.Content[index] = value
+			return nil
+		}
+		return setNode(node.Content[index], path[1:], value)
+	default:
+		return fmt.Errorf("cannot set '%s' of a scalar value", key)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOverride(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Override
+		errContains string
+	}{
+		{input: "parser.json_extraction=true", expected: Override{Section: "parser", Path: []string{"json_extraction"}, Value: "true"}},
+		{input: "funnel.steps.0.event_pattern=a=b", expected: Override{Section: "funnel", Path: []string{"steps", "0", "event_pattern"}, Value: "a=b"}},
+		{input: "parser.event_regex=", expected: Override{Section: "parser", Path: []string{"event_regex"}, Value: ""}},
+		{input: "parser.json_extraction", errContains: "expected section.field=value"},
+		{input: "count.patterns=a", errContains: "must start with parser. or funnel."},
+		{input: "parser=x", errContains: "must start with parser. or funnel."},
+		{input: "parser..fields=x", errContains: "empty field name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			override, err := ParseOverride(tt.input)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOverride() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(override, tt.expected) {
+				t.Errorf("ParseOverride() = %+v, want %+v", override, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOverridesFromEnv(t *testing.T) {
+	overrides, err := OverridesFromEnv([]string{
+		"PATH=/usr/bin",
+		"LOGLION_PARSER_EVENT_REGEX=.*Analytics: (.*)",
+		"LOGLION_FUNNEL_STEPS__1__NAME=Buy",
+		"LOGLION_OTHER_SETTING=1",
+		"LOGLION_PARSER_FIELDS__TIMESTAMP=ts",
+	})
+	if err != nil {
+		t.Fatalf("OverridesFromEnv() unexpected error: %v", err)
+	}
+
+	expected := []Override{
+		{Section: "funnel", Path: []string{"steps", "1", "name"}, Value: "Buy"},
+		{Section: "parser", Path: []string{"event_regex"}, Value: ".*Analytics: (.*)"},
+		{Section: "parser", Path: []string{"fields", "timestamp"}, Value: "ts"},
+	}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("OverridesFromEnv() = %+v, want %+v", overrides, expected)
+	}
+}
+
+func TestLoadConfigsWithOverrides(t *testing.T) {
+	t.Cleanup(func() { SetOverrides(nil) })
+
+	parserPath := writeConfig(t, "event_regex: \"^(.*)$\"\njson_extraction: false\n")
+	funnelPath := writeConfig(t, "name: Flow\nsteps:\n  - name: A\n    event_pattern: a\n  - name: B\n    event_pattern: b\n")
+
+	SetOverrides([]Override{
+		{Section: "parser", Path: []string{"json_extraction"}, Value: "true"},
+		{Section: "parser", Path: []string{"event_regex"}, Value: ".*Analytics: (.*)"},
+		{Section: "parser", Path: []string{"filter", "tags"}, Value: "[Analytics, Checkout]"},
+		{Section: "funnel", Path: []string{"steps", "1", "event_pattern"}, Value: "^buy$"},
+		{Section: "funnel", Path: []string{"name"}, Value: "Checkout"},
+	})
+
+	parserCfg, err := LoadParserConfig(parserPath)
+	if err != nil {
+		t.Fatalf("LoadParserConfig() unexpected error: %v", err)
+	}
+	if !parserCfg.JSONExtraction || parserCfg.EventRegex != ".*Analytics: (.*)" {
+		t.Errorf("Expected overridden parser fields, got %+v", parserCfg)
+	}
+	if !reflect.DeepEqual(parserCfg.Filter.Tags, []string{"Analytics", "Checkout"}) {
+		t.Errorf("Expected the filter tags to be added, got %v", parserCfg.Filter.Tags)
+	}
+
+	funnelCfg, err := LoadFunnelConfig(funnelPath)
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error: %v", err)
+	}
+	if funnelCfg.Name != "Checkout" || funnelCfg.Steps[1].EventPattern != "^buy$" || funnelCfg.Steps[0].EventPattern != "a" {
+		t.Errorf("Expected overridden funnel fields, got %+v", funnelCfg)
+	}
+}
+
+func TestLoadConfigsWithInvalidOverrides(t *testing.T) {
+	t.Cleanup(func() { SetOverrides(nil) })
+	funnelPath := writeConfig(t, "name: Flow\nsteps:\n  - name: A\n    event_pattern: a\n")
+
+	tests := []struct {
+		name        string
+		override    Override
+		errContains string
+	}{
+		{"index out of range", Override{Section: "funnel", Path: []string{"steps", "3", "name"}, Value: "x"}, "no item 3 in a list of 1"},
+		{"field of a scalar", Override{Section: "funnel", Path: []string{"name", "first"}, Value: "x"}, "cannot set 'first' of a scalar value"},
+		{"invalid value", Override{Section: "funnel", Path: []string{"steps", "0", "event_pattern"}, Value: "["}, "failed to apply overrides"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetOverrides([]Override{tt.override})
+			_, err := LoadFunnelConfigs(funnelPath)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
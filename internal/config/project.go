@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFile is the name of the project-level file with default flags
+const ProjectConfigFile = ".loglion.yaml"
+
+// ProjectConfig holds default flag values declared per command in a
+// project file, for example:
+//
+//	funnel:
+//	  parser-config: configs/parser.yaml
+//	  min-conversion-rate: 80
+//	count:
+//	  output: json
+type ProjectConfig struct {
+	// Path is the location of the loaded project file
+	Path string
+	// Commands maps a command name to its flag defaults
+	Commands map[string]map[string]string
+}
+
+// FindProjectConfig looks for a project file in dir and its parents, stopping
+// at the repository root (a directory containing .git). It returns an empty
+// path if no project file is found.
+func FindProjectConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory '%s': %w", dir, err)
+	}
+
+	for {
+		candidate := filepath.Join(dir, ProjectConfigFile)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			logrus.WithField("filepath", candidate).Debug("Found project config")
+			return candidate, nil
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	logrus.Debug("No project config found")
+	return "", nil
+}
+
+// LoadProjectConfig reads per-command flag defaults from a project file
+func LoadProjectConfig(filepath string) (*ProjectConfig, error) {
+	logrus.WithField("filepath", filepath).Debug("Loading project config")
+
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config file '%s': %w", filepath, err)
+	}
+
+	var raw map[string]map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse project config file '%s': %w", filepath, err)
+	}
+
+	projectCfg := &ProjectConfig{
+		Path:     filepath,
+		Commands: make(map[string]map[string]string, len(raw)),
+	}
+	for command, flags := range raw {
+		defaults := make(map[string]string, len(flags))
+		for flag, value := range flags {
+			flagValue, err := projectFlagValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("project config '%s': %s.%s: %w", filepath, command, flag, err)
+			}
+			defaults[flag] = flagValue
+		}
+		projectCfg.Commands[command] = defaults
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"filepath":       filepath,
+		"commands_count": len(projectCfg.Commands),
+	}).Debug("Project config loaded successfully")
+
+	return projectCfg, nil
+}
+
+// Defaults returns the flag defaults declared for a command
+func (p *ProjectConfig) Defaults(command string) map[string]string {
+	if p == nil {
+		return nil
+	}
+	return p.Commands[command]
+}
+
+// projectFlagValue converts a YAML value to its command-line form. Lists
+// become comma-separated values, as accepted by slice flags.
+func projectFlagValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", fmt.Errorf("value cannot be empty")
+	case map[string]interface{}:
+		return "", fmt.Errorf("value must be a scalar or a list")
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			itemValue, err := projectFlagValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, itemValue)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
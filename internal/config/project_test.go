@@ -0,0 +1,129 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+	nested := filepath.Join(root, "app", "src")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create nested dir: %v", err)
+	}
+
+	// No project file up to the repository root
+	path, err := FindProjectConfig(nested)
+	if err != nil {
+		t.Fatalf("FindProjectConfig() unexpected error: %v", err)
+	}
+	if path != "" {
+		t.Errorf("FindProjectConfig() = %q, want empty path", path)
+	}
+
+	projectFile := filepath.Join(root, ProjectConfigFile)
+	if err := os.WriteFile(projectFile, []byte("funnel:\n  output: json\n"), 0644); err != nil {
+		t.Fatalf("Failed to write project file: %v", err)
+	}
+
+	path, err = FindProjectConfig(nested)
+	if err != nil {
+		t.Fatalf("FindProjectConfig() unexpected error: %v", err)
+	}
+	if path != projectFile {
+		t.Errorf("FindProjectConfig() = %q, want %q", path, projectFile)
+	}
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	projectFile := filepath.Join(t.TempDir(), ProjectConfigFile)
+	content := `funnel:
+  parser-config: configs/parser.yaml
+  min-conversion-rate: 80
+  fail-on-incomplete: true
+count:
+  hide: [zero-count, summary]
+`
+	if err := os.WriteFile(projectFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write project file: %v", err)
+	}
+
+	projectCfg, err := LoadProjectConfig(projectFile)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() unexpected error: %v", err)
+	}
+
+	expected := map[string]map[string]string{
+		"funnel": {
+			"parser-config":       "configs/parser.yaml",
+			"min-conversion-rate": "80",
+			"fail-on-incomplete":  "true",
+		},
+		"count": {
+			"hide": "zero-count,summary",
+		},
+	}
+	for command, flags := range expected {
+		defaults := projectCfg.Defaults(command)
+		for flag, value := range flags {
+			if defaults[flag] != value {
+				t.Errorf("Defaults(%q)[%q] = %q, want %q", command, flag, defaults[flag], value)
+			}
+		}
+	}
+
+	if defaults := projectCfg.Defaults("validate"); len(defaults) != 0 {
+		t.Errorf("Defaults(\"validate\") = %v, want none", defaults)
+	}
+}
+
+func TestLoadProjectConfigErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:          "invalid yaml",
+			content:       "funnel: [",
+			expectedError: "failed to parse project config file",
+		},
+		{
+			name:          "command is not a map",
+			content:       "funnel: json",
+			expectedError: "failed to parse project config file",
+		},
+		{
+			name:          "empty value",
+			content:       "funnel:\n  output:\n",
+			expectedError: "funnel.output: value cannot be empty",
+		},
+		{
+			name:          "nested map value",
+			content:       "funnel:\n  output:\n    format: json\n",
+			expectedError: "funnel.output: value must be a scalar or a list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectFile := filepath.Join(t.TempDir(), ProjectConfigFile)
+			if err := os.WriteFile(projectFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write project file: %v", err)
+			}
+
+			_, err := LoadProjectConfig(projectFile)
+			if err == nil {
+				t.Fatal("LoadProjectConfig() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("LoadProjectConfig() error = %q, should contain %q", err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
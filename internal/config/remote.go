@@ -0,0 +1,192 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultRemoteTimeout is how long fetching a config from a URL may take
+const DefaultRemoteTimeout = 10 * time.Second
+
+// maxRemoteConfigBytes limits the size of a config fetched from a URL
+const maxRemoteConfigBytes = 10 << 20
+
+// RemoteOptions configure how configs given as http or https URLs are fetched
+type RemoteOptions struct {
+	// Timeout of each request, DefaultRemoteTimeout when zero
+	Timeout time.Duration
+	// Headers, e.g. Authorization, are sent with configs the user gave as URLs
+	// and with configs they extend on the same scheme and host
+	Headers map[string]string
+}
+
+var (
+	remoteMu      sync.Mutex
+	remoteOptions RemoteOptions
+	// remoteConfigs caches fetched configs by URL, so a config read by several
+	// loaders, such as a combined config file, is fetched once
+	remoteConfigs = make(map[string][]byte)
+)
+
+// SetRemoteOptions sets the options of configs fetched afterwards
+func SetRemoteOptions(options RemoteOptions) {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	remoteOptions = options
+}
+
+// IsURL reports whether a config path is an http or https URL
+func IsURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// readConfigFile reads a config from a local file or, for an http or https
+// URL, fetches it. A URL fragment of the form sha256=<hex digest> is checked
+// against the fetched content. The path is one the user gave, so the
+// configured headers are sent with it.
+func readConfigFile(path string) ([]byte, error) {
+	return readConfigFileTrusting(path, path)
+}
+
+// readConfigFileTrusting reads a config like readConfigFile, but only sends
+// the configured headers if path has the scheme and host of trusted, the URL
+// the user gave. Configs referenced by fetched configs cannot obtain the
+// credentials of other hosts that way.
+func readConfigFileTrusting(path, trusted string) ([]byte, error) {
+	if !IsURL(path) {
+		return os.ReadFile(path)
+	}
+
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	if data, ok := remoteConfigs[path]; ok {
+		logrus.WithField("url", path).Debug("Using fetched config")
+		return data, nil
+	}
+
+	options := remoteOptions
+	if !sameOrigin(path, trusted) {
+		if len(options.Headers) > 0 {
+			logrus.WithFields(logrus.Fields{
+				"url":     path,
+				"trusted": trusted,
+			}).Debug("Not sending config headers to another origin")
+		}
+		options.Headers = nil
+	}
+	data, err := fetchConfig(path, options)
+	if err != nil {
+		return nil, err
+	}
+	remoteConfigs[path] = data
+	return data, nil
+}
+
+// sameOrigin reports whether two URLs have the same scheme and host
+func sameOrigin(a, b string) bool {
+	urlA, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	urlB, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return urlA.Host != "" && strings.EqualFold(urlA.Scheme, urlB.Scheme) && strings.EqualFold(urlA.Host, urlB.Host)
+}
+
+// isPinned reports whether path is a URL whose content is pinned with a
+// sha256=<hex digest> fragment
+func isPinned(path string) bool {
+	if !IsURL(path) {
+		return false
+	}
+	parsed, err := url.Parse(path)
+	return err == nil && strings.HasPrefix(parsed.Fragment, "sha256=")
+}
+
+// fetchConfig downloads the config at rawURL and verifies its checksum
+func fetchConfig(rawURL string, options RemoteOptions) ([]byte, error) {
+	configURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config URL: %w", err)
+	}
+	checksum, err := configChecksum(configURL.Fragment)
+	if err != nil {
+		return nil, err
+	}
+	configURL.Fragment = ""
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRemoteTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, configURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config URL: %w", err)
+	}
+	for name, value := range options.Headers {
+		request.Header.Set(name, value)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"url":     configURL.String(),
+		"timeout": timeout,
+	}).Info("Fetching config")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch config: server returned %s", response.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxRemoteConfigBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config: %w", err)
+	}
+	if len(data) > maxRemoteConfigBytes {
+		return nil, fmt.Errorf("config is larger than %d bytes", maxRemoteConfigBytes)
+	}
+
+	if checksum != "" {
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); actual != checksum {
+			return nil, fmt.Errorf("config checksum mismatch: expected sha256 %s, got %s", checksum, actual)
+		}
+		logrus.WithField("sha256", checksum).Debug("Config checksum verified")
+	}
+	return data, nil
+}
+
+// configChecksum returns the expected SHA-256 digest of a URL fragment of
+// the form sha256=<hex digest>, or an empty string without a fragment
+func configChecksum(fragment string) (string, error) {
+	if fragment == "" {
+		return "", nil
+	}
+	digest, ok := strings.CutPrefix(fragment, "sha256=")
+	if !ok {
+		return "", fmt.Errorf("invalid config URL fragment '%s': expected sha256=<hex digest>", fragment)
+	}
+	digest = strings.ToLower(digest)
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("invalid config checksum '%s': expected %d hex digits", digest, 2*sha256.Size)
+	}
+	return digest, nil
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const remoteFunnel = "name: Remote Flow\nsteps:\n  - name: A\n    event_pattern: a\n"
+
+func TestLoadRemoteConfig(t *testing.T) {
+	t.Cleanup(func() { SetRemoteOptions(RemoteOptions{}) })
+
+	requests := 0
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/funnel.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(remoteFunnel))
+	}))
+	defer server.Close()
+
+	SetRemoteOptions(RemoteOptions{Headers: map[string]string{"Authorization": "Bearer token"}})
+	sum := sha256.Sum256([]byte(remoteFunnel))
+	url := server.URL + "/funnel.yaml#sha256=" + hex.EncodeToString(sum[:])
+
+	funnelCfg, err := LoadFunnelConfig(url)
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error: %v", err)
+	}
+	if funnelCfg.Name != "Remote Flow" {
+		t.Errorf("Expected the remote funnel, got %q", funnelCfg.Name)
+	}
+	if authorization != "Bearer token" {
+		t.Errorf("Expected the Authorization header to be sent, got %q", authorization)
+	}
+
+	// The config is fetched once
+	if _, err := LoadFunnelConfigs(url); err != nil {
+		t.Fatalf("LoadFunnelConfigs() unexpected error: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+
+	_, err = LoadFunnelConfig(server.URL + "/missing.yaml")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected an error with the response status, got %v", err)
+	}
+}
+
+func TestLoadRemoteConfigErrors(t *testing.T) {
+	t.Cleanup(func() { SetRemoteOptions(RemoteOptions{}) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow.yaml" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(remoteFunnel))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		url         string
+		errContains string
+	}{
+		{"checksum mismatch", server.URL + "/mismatch.yaml#sha256=" + strings.Repeat("0", 64), "checksum mismatch"},
+		{"invalid checksum", server.URL + "/invalid.yaml#sha256=abc", "expected 64 hex digits"},
+		{"unknown fragment", server.URL + "/fragment.yaml#md5=abc", "expected sha256=<hex digest>"},
+		{"timeout", server.URL + "/slow.yaml", "failed to fetch config"},
+	}
+
+	SetRemoteOptions(RemoteOptions{Timeout: 50 * time.Millisecond})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFunnelConfig(tt.url)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://configs.example.com/base.yaml", "https://configs.example.com/funnels/checkout.yaml", true},
+		{"https://CONFIGS.example.com/base.yaml", "https://configs.example.com/checkout.yaml", true},
+		{"http://configs.example.com/base.yaml", "https://configs.example.com/checkout.yaml", false},
+		{"https://configs.example.com:8443/base.yaml", "https://configs.example.com/checkout.yaml", false},
+		{"https://other.example.com/base.yaml", "https://configs.example.com/checkout.yaml", false},
+		{"https://configs.example.com/base.yaml", "", false},
+	}
+	for _, tt := range tests {
+		if got := sameOrigin(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameOrigin(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIsURL(t *testing.T) {
+	for path, expected := range map[string]bool{
+		"https://example.com/funnel.yaml": true,
+		"http://localhost:8080/p.yaml":    true,
+		"configs/funnel.yaml":             false,
+		"/abs/https.yaml":                 false,
+		"ftp://example.com/funnel.yaml":   false,
+	} {
+		if IsURL(path) != expected {
+			t.Errorf("IsURL(%q) = %v, want %v", path, !expected, expected)
+		}
+	}
+}
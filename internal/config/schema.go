@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaSource is the YAML a config was written in, for locating schema
+// validation errors by line and column
+type schemaSource struct {
+	// data is the content of the config file, nil when the validated config
+	// differs from it in structure, e.g. after merging a base funnel
+	data []byte
+	// path holds the keys of the config within data, such as the section
+	// of a combined config file
+	path []string
+}
+
+// newSchemaSource returns the source of the section of a config file,
+// which is the whole file unless it is a combined config file
+func newSchemaSource(data []byte, section string) schemaSource {
+	source := schemaSource{data: data}
+	if _, combined, _ := combinedSections(data); combined {
+		source.path = []string{section}
+	}
+	return source
+}
+
+// formatSchemaErrors lists schema validation errors, one per line. Errors
+// found in the source are followed by the line they point at, with a caret
+// under the offending key or value.
+func formatSchemaErrors(errs []gojsonschema.ResultError, source schemaSource) string {
+	var root *yaml.Node
+	var lines []string
+	if source.data != nil {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(source.data, &doc); err == nil && len(doc.Content) > 0 {
+			root = doc.Content[0]
+			lines = strings.Split(string(source.data), "\n")
+		}
+	}
+
+	var b strings.Builder
+	for i, desc := range errs {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "- %s", desc)
+
+		node := locateSchemaError(root, source.path, desc)
+		if node == nil || node.Line > len(lines) {
+			continue
+		}
+		fmt.Fprintf(&b, " (line %d, column %d)\n%s", node.Line, node.Column, snippet(lines, node.Line, node.Column))
+	}
+	return b.String()
+}
+
+// locateSchemaError returns the node of the YAML document root an error
+// points at: the key of a property that is not allowed, or else the value
+// that failed validation. It returns nil when the node is not found.
+func locateSchemaError(root *yaml.Node, path []string, desc gojsonschema.ResultError) *yaml.Node {
+	if root == nil {
+		return nil
+	}
+
+	// The context is "(root)" followed by the keys and indexes of the value;
+	// splitting on NUL keeps keys that contain dots intact
+	keys := strings.Split(desc.Context().String("\x00"), "\x00")[1:]
+	node := locateYAML(root, append(append([]string{}, path...), keys...))
+	if node == nil {
+		return nil
+	}
+
+	if property, ok := desc.Details()["property"].(string); ok && desc.Type() == "additional_property_not_allowed" && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == property {
+				return node.Content[i]
+			}
+		}
+	}
+	return node
+}
+
+// locateYAML returns the node at path, a list of mapping keys and sequence
+// indexes, or nil if there is none
+func locateYAML(node *yaml.Node, path []string) *yaml.Node {
+	for _, key := range path {
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			if node = mappingValue(node, key); node == nil {
+				return nil
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[index]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// snippet returns a line of the config, numbered, with a caret under column
+func snippet(lines []string, line, column int) string {
+	number := strconv.Itoa(line)
+	gutter := strings.Repeat(" ", len(number))
+	text := strings.TrimRight(lines[line-1], "\r")
+	// Tabs keep their width so the caret lines up
+	indent := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, string([]rune(text)[:min(column-1, len([]rune(text)))]))
+	return fmt.Sprintf("    %s | %s\n    %s | %s^", number, text, gutter, indent)
+}
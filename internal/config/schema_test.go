@@ -0,0 +1,101 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaErrorsAreLocated(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"funnel.yaml": `name: "Checkout"
+steps:
+  - name: "View"
+    event_pattern: "view"
+  - name: "Buy"
+    event_pattern: 42
+    colour: red
+`,
+		"combined.yaml": `parser:
+  event_regex: "(.*)"
+  json_extraction: "yes"
+funnel:
+  name: "Checkout"
+  steps:
+    - name: "View"
+      event_pattern: "view"
+`,
+		"base.yaml": baseFunnel,
+		"extended.yaml": `extends: base.yaml
+name: "Extended"
+steps:
+  - name: "Review"
+    event_pattern: 42
+`,
+	})
+
+	tests := []struct {
+		name string
+		load func(path string) error
+		file string
+		want []string
+		// notWant must not be in the error
+		notWant string
+	}{
+		{
+			name: "funnel config",
+			load: func(path string) error { _, err := LoadFunnelConfigs(path); return err },
+			file: "funnel.yaml",
+			want: []string{
+				"- steps.1.event_pattern: Invalid type. Expected: string, given: integer (line 6, column 20)\n" +
+					"    6 |     event_pattern: 42\n" +
+					"      |                    ^",
+				"- steps.1: Additional property colour is not allowed (line 7, column 5)\n" +
+					"    7 |     colour: red\n" +
+					"      |     ^",
+			},
+		},
+		{
+			name: "parser section of a combined config",
+			load: func(path string) error { _, err := LoadParserConfig(path); return err },
+			file: "combined.yaml",
+			want: []string{
+				"- json_extraction: Invalid type. Expected: boolean, given: string (line 3, column 20)\n" +
+					"    3 |   json_extraction: \"yes\"\n" +
+					"      |                    ^",
+			},
+		},
+		{
+			name:    "funnel merged with a base funnel",
+			load:    func(path string) error { _, err := LoadFunnelConfigs(path); return err },
+			file:    "extended.yaml",
+			want:    []string{"- steps.3.event_pattern: Invalid type. Expected: string, given: integer"},
+			notWant: "(line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.load(filepath.Join(dir, tt.file))
+			if err == nil {
+				t.Fatal("Expected a schema validation error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to contain:\n%s\ngot:\n%s", want, err)
+				}
+			}
+			if tt.notWant != "" && strings.Contains(err.Error(), tt.notWant) {
+				t.Errorf("Expected error without %q, got:\n%s", tt.notWant, err)
+			}
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	lines := []string{"steps:", "\t- name: x\r"}
+	want := "    2 | \t- name: x\n      | \t  ^"
+	if got := snippet(lines, 2, 4); got != want {
+		t.Errorf("snippet() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// varsKey holds the variables of a funnel config file or of one funnel
+const varsKey = "vars"
+
+// varPattern matches ${NAME} references, and $${NAME} for a literal ${NAME}
+var varPattern = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var (
+	varsMu  sync.RWMutex
+	cliVars map[string]string
+)
+
+// SetVars sets the variables of funnel configs loaded afterwards, which take
+// precedence over environment variables and the vars of the configs
+func SetVars(vars map[string]string) {
+	varsMu.Lock()
+	defer varsMu.Unlock()
+	cliVars = vars
+}
+
+// ParseVar parses a variable in the form NAME=value
+func ParseVar(s string) (string, string, error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || !varPattern.MatchString("${"+name+"}") {
+		return "", "", fmt.Errorf("invalid variable '%s': expected NAME=value", s)
+	}
+	return name, value, nil
+}
+
+// interpolateVars replaces the ${NAME} references in the funnels of the YAML
+// of a funnel config with the value of the variable, looked up in SetVars,
+// the environment, the vars of the funnel and the vars of the file, in that
+// order. Data without references is returned unchanged.
+func interpolateVars(data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte("${")) {
+		return data, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		// Left for the loader to report
+		return data, nil
+	}
+	root := doc.Content[0]
+
+	fileVars, err := configVars(root)
+	if err != nil {
+		return nil, err
+	}
+
+	list := mappingValue(root, "funnels")
+	if list == nil || list.Kind != yaml.SequenceNode {
+		if err := interpolateNode(root, fileVars); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(&doc)
+	}
+
+	for i, funnel := range list.Content {
+		funnelVars, err := configVars(funnel)
+		if err != nil {
+			return nil, fmt.Errorf("funnel %d: %w", i+1, err)
+		}
+		vars := make(map[string]string, len(fileVars)+len(funnelVars))
+		for name, value := range fileVars {
+			vars[name] = value
+		}
+		for name, value := range funnelVars {
+			vars[name] = value
+		}
+		if err := interpolateNode(funnel, vars); err != nil {
+			return nil, fmt.Errorf("funnel %d: %w", i+1, err)
+		}
+	}
+	return yaml.Marshal(&doc)
+}
+
+// configVars returns the vars declared in a mapping node
+func configVars(node *yaml.Node) (map[string]string, error) {
+	varsNode := mappingValue(node, varsKey)
+	if varsNode == nil {
+		return nil, nil
+	}
+	if varsNode.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s must be a mapping of names to values", varsKey)
+	}
+
+	vars := make(map[string]string, len(varsNode.Content)/2)
+	for i := 0; i+1 < len(varsNode.Content); i += 2 {
+		name, value := varsNode.Content[i].Value, varsNode.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("variable '%s' must be a scalar value", name)
+		}
+		vars[name] = value.Value
+	}
+	return vars, nil
+}
+
+// interpolateNode replaces the references in the keys and values below node,
+// except in its vars
+func interpolateNode(node *yaml.Node, vars map[string]string) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		value, err := interpolate(node.Value, vars)
+		if err != nil {
+			return err
+		}
+		node.Value = value
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == varsKey {
+				continue
+			}
+			if err := interpolateNode(node.Content[i], vars); err != nil {
+				return err
+			}
+			if err := interpolateNode(node.Content[i+1], vars); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := interpolateNode(item, vars); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// interpolate replaces the references in s
+func interpolate(s string, vars map[string]string) (string, error) {
+	var undefined string
+	result := varPattern.ReplaceAllStringFunc(s, func(reference string) string {
+		match := varPattern.FindStringSubmatch(reference)
+		escaped, name := match[1] != "", match[2]
+		if escaped {
+			return reference[1:]
+		}
+		if value, ok := lookupVar(name, vars); ok {
+			logrus.WithFields(logrus.Fields{
+				"name":  name,
+				"value": value,
+			}).Debug("Interpolated config variable")
+			return value
+		}
+		if undefined == "" {
+			undefined = name
+		}
+		return reference
+	})
+	if undefined != "" {
+		return "", fmt.Errorf("undefined variable '%s' in '%s' (set it in %s, with --var or in the environment)", undefined, s, varsKey)
+	}
+	return result, nil
+}
+
+// lookupVar returns the value of a variable by precedence
+func lookupVar(name string, vars map[string]string) (string, bool) {
+	varsMu.RLock()
+	value, ok := cliVars[name]
+	varsMu.RUnlock()
+	if ok {
+		return value, true
+	}
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	value, ok = vars[name]
+	return value, ok
+}
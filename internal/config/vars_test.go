@@ -0,0 +1,135 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFunnelConfigVars(t *testing.T) {
+	t.Cleanup(func() { SetVars(nil) })
+	t.Setenv("LOGLION_TEST_ENDPOINT", "/api/v2/checkout")
+
+	path := writeConfig(t, `vars:
+  PACKAGE: com.example.app
+  ENDPOINT: /api/v1/checkout
+  FLAVOR: release
+name: "Checkout (${FLAVOR})"
+steps:
+  - name: "Open"
+    event_pattern: "^${PACKAGE}: app_open$"
+  - name: "Pay"
+    event_pattern: "request ${LOGLION_TEST_ENDPOINT}"
+    required_properties:
+      package: "^${PACKAGE}$"
+  - name: "Literal"
+    event_pattern: "price $${AMOUNT}"
+`)
+
+	SetVars(map[string]string{"PACKAGE": "com.example.debug"})
+	funnelCfg, err := LoadFunnelConfig(path)
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error: %v", err)
+	}
+
+	if funnelCfg.Name != "Checkout (release)" {
+		t.Errorf("Expected the vars block value, got %q", funnelCfg.Name)
+	}
+	if funnelCfg.Steps[0].EventPattern != "^com.example.debug: app_open$" {
+		t.Errorf("Expected --var to take precedence, got %q", funnelCfg.Steps[0].EventPattern)
+	}
+	if funnelCfg.Steps[1].EventPattern != "request /api/v2/checkout" {
+		t.Errorf("Expected the environment value, got %q", funnelCfg.Steps[1].EventPattern)
+	}
+	if funnelCfg.Steps[1].RequiredProperties["package"] != "^com.example.debug$" {
+		t.Errorf("Expected required properties to be interpolated, got %v", funnelCfg.Steps[1].RequiredProperties)
+	}
+	if funnelCfg.Steps[2].EventPattern != "price ${AMOUNT}" {
+		t.Errorf("Expected an escaped reference to be kept, got %q", funnelCfg.Steps[2].EventPattern)
+	}
+}
+
+func TestLoadFunnelConfigsVarsPerFunnel(t *testing.T) {
+	path := writeConfig(t, `vars:
+  EVENT: purchase
+funnels:
+  - name: "Default"
+    steps:
+      - name: "Buy"
+        event_pattern: "^${EVENT}$"
+  - name: "Legacy"
+    vars:
+      EVENT: order_placed
+    steps:
+      - name: "Buy"
+        event_pattern: "^${EVENT}$"
+`)
+
+	funnelCfgs, err := LoadFunnelConfigs(path)
+	if err != nil {
+		t.Fatalf("LoadFunnelConfigs() unexpected error: %v", err)
+	}
+	if funnelCfgs[0].Steps[0].EventPattern != "^purchase$" || funnelCfgs[1].Steps[0].EventPattern != "^order_placed$" {
+		t.Errorf("Expected funnel vars to override file vars, got %q and %q", funnelCfgs[0].Steps[0].EventPattern, funnelCfgs[1].Steps[0].EventPattern)
+	}
+}
+
+func TestLoadFunnelConfigVarsWithExtends(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base.yaml":  "vars:\n  PACKAGE: com.example.app\nname: Base\nsteps:\n  - name: Open\n    event_pattern: \"^${PACKAGE} open$\"\n",
+		"debug.yaml": "extends: base.yaml\nname: Debug\nvars:\n  PACKAGE: com.example.debug\n",
+	})
+
+	funnelCfg, err := LoadFunnelConfig(filepath.Join(dir, "debug.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFunnelConfig() unexpected error: %v", err)
+	}
+	if funnelCfg.Steps[0].EventPattern != "^com.example.debug open$" {
+		t.Errorf("Expected the extending funnel's vars in inherited steps, got %q", funnelCfg.Steps[0].EventPattern)
+	}
+}
+
+func TestLoadFunnelConfigVarsErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		errContains string
+	}{
+		{
+			name:        "undefined variable",
+			content:     "name: Flow\nsteps:\n  - name: A\n    event_pattern: \"${LOGLION_TEST_UNDEFINED}\"\n",
+			errContains: "undefined variable 'LOGLION_TEST_UNDEFINED'",
+		},
+		{
+			name:        "vars not a mapping",
+			content:     "vars: [a]\nname: Flow\nsteps:\n  - name: A\n    event_pattern: \"${A}\"\n",
+			errContains: "vars must be a mapping",
+		},
+		{
+			name:        "variable not a scalar",
+			content:     "vars:\n  A: [x]\nname: Flow\nsteps:\n  - name: A\n    event_pattern: \"${A}\"\n",
+			errContains: "variable 'A' must be a scalar value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFunnelConfigs(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestParseVar(t *testing.T) {
+	name, value, err := ParseVar("PACKAGE=com.example=debug")
+	if err != nil || name != "PACKAGE" || value != "com.example=debug" {
+		t.Errorf("ParseVar() = %q, %q, %v", name, value, err)
+	}
+	for _, invalid := range []string{"PACKAGE", "=value", "1ST=x", "A-B=x"} {
+		if _, _, err := ParseVar(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
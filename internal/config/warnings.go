@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// Warning is a setting that is valid but likely a mistake, such as a step
+// pattern that matches every event
+type Warning struct {
+	// Funnel names the funnel of the setting, empty for count patterns
+	Funnel  string
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Funnel == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("funnel '%s': %s", w.Funnel, w.Message)
+}
+
+// matchProbe is an event no meaningful pattern should match
+const matchProbe = "~loglion probe 0~"
+
+// matchesEverything reports whether pattern matches any event, such as .*
+func matchesEverything(pattern string) bool {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString("") && re.MatchString(matchProbe)
+}
+
+// ExtractsEventData reports whether the entries of the parser may carry event
+// data, which required properties and group_by are matched against. Formats
+// other than the regex based ones are assumed to extract it.
+func (c *ParserConfig) ExtractsEventData() bool {
+	switch c.Format {
+	case "", parser.PlainFormat, parser.AndroidLogcatFormat, parser.LogcatJSONFormat:
+		return c.JSONExtraction || len(c.PropertyRegexes) > 0
+	}
+	return true
+}
+
+// noEventData explains why the settings reading event data find none
+const noEventData = "the parser config has neither json_extraction nor property_regexes, so no entry has event data"
+
+// Warnings returns the suspicious settings of a valid funnel. The settings
+// that read event data are checked against parserCfg unless it is nil.
+func (c *FunnelConfig) Warnings(parserCfg *ParserConfig) []Warning {
+	var warnings []Warning
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, Warning{Funnel: c.Name, Message: fmt.Sprintf(format, args...)})
+	}
+	withoutEventData := parserCfg != nil && !parserCfg.ExtractsEventData()
+
+	if c.GroupBy != "" && withoutEventData {
+		warn("group_by '%s' never matches: %s", c.GroupBy, noEventData)
+	}
+
+	// Steps matching the same events with the same properties, by pattern
+	seen := map[string]string{}
+	checkPattern := func(prefix, pattern string, ignoreCase bool, requiredProperties map[string]string) {
+		if matchesEverything(pattern) {
+			warn("%s: event_pattern '%s' matches every event", prefix, pattern)
+		}
+		// fmt prints maps sorted by key, so equal properties give equal keys
+		key := fmt.Sprintf("%t %q %v", ignoreCase, pattern, requiredProperties)
+		if previous, exists := seen[key]; exists {
+			warn("%s: event_pattern '%s' matches the same events as %s", prefix, pattern, previous)
+		} else {
+			seen[key] = prefix
+		}
+	}
+
+	for i, step := range c.Steps {
+		prefix := fmt.Sprintf("step %d (%s)", i+1, step.Name)
+		ignoreCase := step.IgnoresCase(c.CaseInsensitive)
+
+		usesProperties := len(step.RequiredProperties) > 0
+		if step.EventPattern != "" {
+			checkPattern(prefix, step.EventPattern, ignoreCase, step.RequiredProperties)
+		}
+		for j, branch := range step.AnyOf {
+			checkPattern(fmt.Sprintf("%s: branch %d", prefix, j+1), branch.EventPattern, branch.IgnoresCase(ignoreCase), branch.RequiredProperties)
+			usesProperties = usesProperties || len(branch.RequiredProperties) > 0
+		}
+
+		if step.ExcludePattern != "" && matchesEverything(step.ExcludePattern) {
+			warn("%s: exclude_pattern '%s' matches every event, so no attempt reaches the step", prefix, step.ExcludePattern)
+		}
+		if usesProperties && withoutEventData {
+			warn("%s: required_properties never match: %s", prefix, noEventData)
+		}
+	}
+	return warnings
+}
+
+// CountWarnings returns the suspicious settings of a count: patterns that
+// match every event or repeat another pattern, and event data properties,
+// such as the group-by property, that the parser cannot extract
+func CountWarnings(patterns []string, properties []string, parserCfg *ParserConfig) []Warning {
+	var warnings []Warning
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, Warning{Message: fmt.Sprintf(format, args...)})
+	}
+
+	seen := map[string]int{}
+	for i, pattern := range patterns {
+		if matchesEverything(pattern) {
+			warn("pattern %d '%s' matches every event", i+1, pattern)
+		}
+		if previous, exists := seen[pattern]; exists {
+			warn("pattern %d '%s' repeats pattern %d", i+1, pattern, previous)
+		} else {
+			seen[pattern] = i + 1
+		}
+	}
+
+	if parserCfg != nil && !parserCfg.ExtractsEventData() {
+		for _, property := range properties {
+			if property != "" {
+				warn("property '%s' is never found: %s", property, noEventData)
+			}
+		}
+	}
+	return warnings
+}
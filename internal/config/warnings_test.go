@@ -0,0 +1,111 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFunnelConfigWarnings(t *testing.T) {
+	plain := &ParserConfig{Format: "plain"}
+	extracting := &ParserConfig{Format: "plain", JSONExtraction: true}
+
+	tests := []struct {
+		name      string
+		cfg       *FunnelConfig
+		parserCfg *ParserConfig
+		want      []string
+	}{
+		{
+			name: "no warnings",
+			cfg: &FunnelConfig{Name: "Checkout", GroupBy: "user_id", Steps: []Step{
+				{Name: "View", EventPattern: "^view$"},
+				{Name: "Buy", EventPattern: "^buy$", RequiredProperties: map[string]string{"amount": "> 0"}},
+			}},
+			parserCfg: extracting,
+		},
+		{
+			name: "pattern matching everything",
+			cfg: &FunnelConfig{Name: "Checkout", Steps: []Step{
+				{Name: "View", EventPattern: "view"},
+				{Name: "Any", EventPattern: ".*"},
+				{Name: "Buy", EventPattern: "buy", ExcludePattern: "^"},
+			}},
+			want: []string{
+				"funnel 'Checkout': step 2 (Any): event_pattern '.*' matches every event",
+				"funnel 'Checkout': step 3 (Buy): exclude_pattern '^' matches every event, so no attempt reaches the step",
+			},
+		},
+		{
+			name: "duplicate patterns",
+			cfg: &FunnelConfig{Name: "Checkout", Steps: []Step{
+				{Name: "View", EventPattern: "view"},
+				{Name: "View Again", EventPattern: "view"},
+				{Name: "Paid View", EventPattern: "view", RequiredProperties: map[string]string{"paid": "true"}},
+				{Name: "Buy", AnyOf: []StepBranch{{EventPattern: "buy"}, {EventPattern: "view", RequiredProperties: map[string]string{"paid": "true"}}}},
+			}},
+			want: []string{
+				"funnel 'Checkout': step 2 (View Again): event_pattern 'view' matches the same events as step 1 (View)",
+				"funnel 'Checkout': step 4 (Buy): branch 2: event_pattern 'view' matches the same events as step 3 (Paid View)",
+			},
+		},
+		{
+			name: "event data without extraction",
+			cfg: &FunnelConfig{Name: "Checkout", GroupBy: "user_id", Steps: []Step{
+				{Name: "View", EventPattern: "view"},
+				{Name: "Buy", AnyOf: []StepBranch{{EventPattern: "buy", RequiredProperties: map[string]string{"amount": "> 0"}}}},
+			}},
+			parserCfg: plain,
+			want: []string{
+				"funnel 'Checkout': group_by 'user_id' never matches: " + noEventData,
+				"funnel 'Checkout': step 2 (Buy): required_properties never match: " + noEventData,
+			},
+		},
+		{
+			name: "event data with property regexes",
+			cfg: &FunnelConfig{Name: "Checkout", GroupBy: "user_id", Steps: []Step{
+				{Name: "View", EventPattern: "view"},
+			}},
+			parserCfg: &ParserConfig{Format: "android-logcat", PropertyRegexes: map[string]string{"user_id": `uid=(\d+)`}},
+		},
+		{
+			name: "event data from jsonl",
+			cfg: &FunnelConfig{Name: "Checkout", GroupBy: "user_id", Steps: []Step{
+				{Name: "View", EventPattern: "view"},
+			}},
+			parserCfg: &ParserConfig{Format: "jsonl"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, warning := range tt.cfg.Warnings(tt.parserCfg) {
+				got = append(got, warning.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Warnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountWarnings(t *testing.T) {
+	var got []string
+	for _, warning := range CountWarnings([]string{"login", "", "login", "a*"}, []string{"user_id", ""}, &ParserConfig{}) {
+		got = append(got, warning.String())
+	}
+
+	want := []string{
+		"pattern 2 '' matches every event",
+		"pattern 3 'login' repeats pattern 1",
+		"pattern 4 'a*' matches every event",
+		"property 'user_id' is never found: " + noEventData,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CountWarnings() = %q, want %q", got, want)
+	}
+
+	if warnings := CountWarnings([]string{"login", "^$"}, []string{"user_id"}, &ParserConfig{JSONExtraction: true}); len(warnings) != 0 {
+		t.Errorf("CountWarnings() = %v, want none", warnings)
+	}
+}
@@ -0,0 +1,214 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// Files of a corpus case directory
+const (
+	ParserConfigFile = "parser.yaml"
+	InputFile        = "input.log"
+	GoldenFile       = "golden.ndjson"
+)
+
+// Case is one sample log of the corpus together with the parser config it is
+// parsed with and the golden entries it is expected to parse into
+type Case struct {
+	Name string
+	Dir  string
+}
+
+// Record is the golden representation of a parsed log entry
+type Record struct {
+	Timestamp string                 `json:"timestamp,omitempty"`
+	Level     string                 `json:"level,omitempty"`
+	Tag       string                 `json:"tag,omitempty"`
+	PID       int                    `json:"pid,omitempty"`
+	TID       int                    `json:"tid,omitempty"`
+	Message   string                 `json:"message"`
+	EventData map[string]interface{} `json:"event_data,omitempty"`
+}
+
+// NewRecord converts a parsed entry into its golden representation
+func NewRecord(entry *parser.LogEntry) Record {
+	record := Record{
+		Level:     entry.Level,
+		Tag:       entry.Tag,
+		PID:       entry.PID,
+		TID:       entry.TID,
+		Message:   entry.Message,
+		EventData: entry.EventData,
+	}
+	if !entry.Timestamp.IsZero() {
+		record.Timestamp = entry.Timestamp.Format(time.RFC3339Nano)
+	}
+	return record
+}
+
+// Mismatch is a golden line that differs from the parsed output. Expected or
+// Actual is empty when one side has fewer entries than the other.
+type Mismatch struct {
+	Line     int
+	Expected string
+	Actual   string
+}
+
+// Result is the outcome of verifying one case
+type Result struct {
+	Case       string
+	Entries    int
+	Mismatches []Mismatch
+}
+
+// Passed reports whether the parsed output matched the golden file
+func (r *Result) Passed() bool {
+	return len(r.Mismatches) == 0
+}
+
+// FindCases returns the cases of the corpus, one per subdirectory containing
+// a parser config, sorted by name
+func FindCases(corpusDir string) ([]Case, error) {
+	dirs, err := os.ReadDir(corpusDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corpus directory: %w", err)
+	}
+
+	var cases []Case
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		caseDir := filepath.Join(corpusDir, dir.Name())
+		if _, err := os.Stat(filepath.Join(caseDir, ParserConfigFile)); err != nil {
+			continue
+		}
+		cases = append(cases, Case{Name: dir.Name(), Dir: caseDir})
+	}
+
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no conformance cases found in '%s'", corpusDir)
+	}
+
+	sort.Slice(cases, func(i, j int) bool {
+		return cases[i].Name < cases[j].Name
+	})
+
+	logrus.WithFields(logrus.Fields{
+		"corpus_dir": corpusDir,
+		"case_count": len(cases),
+	}).Debug("Found conformance cases")
+
+	return cases, nil
+}
+
+// Render parses the input of the case and returns it as NDJSON records
+func Render(c Case) ([]string, error) {
+	parserCfg, err := config.LoadParserConfig(filepath.Join(c.Dir, ParserConfigFile))
+	if err != nil {
+		return nil, err
+	}
+
+	logParser, err := parserCfg.NewParser()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := logParser.ParseFile(filepath.Join(c.Dir, InputFile))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(NewRecord(entry)); err != nil {
+			return nil, fmt.Errorf("failed to encode entry %d: %w", i+1, err)
+		}
+		lines[i] = strings.TrimSuffix(buf.String(), "\n")
+	}
+	return lines, nil
+}
+
+// Verify parses the input of the case and compares it with the golden file
+func Verify(c Case) (*Result, error) {
+	actual, err := Render(c)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.Name, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.Dir, GoldenFile))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read golden file: %w", c.Name, err)
+	}
+	expected := splitLines(data)
+
+	result := &Result{Case: c.Name, Entries: len(actual)}
+	for i := 0; i < max(len(expected), len(actual)); i++ {
+		var want, got string
+		if i < len(expected) {
+			want = expected[i]
+		}
+		if i < len(actual) {
+			got = actual[i]
+		}
+		if want != got {
+			result.Mismatches = append(result.Mismatches, Mismatch{Line: i + 1, Expected: want, Actual: got})
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"case":       c.Name,
+		"entries":    result.Entries,
+		"mismatches": len(result.Mismatches),
+	}).Debug("Verified conformance case")
+
+	return result, nil
+}
+
+// Update rewrites the golden file of the case from the current parser output
+func Update(c Case) error {
+	lines, err := Render(c)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.Name, err)
+	}
+
+	var out strings.Builder
+	for _, line := range lines {
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+
+	if err := os.WriteFile(filepath.Join(c.Dir, GoldenFile), []byte(out.String()), 0644); err != nil {
+		return fmt.Errorf("%s: failed to write golden file: %w", c.Name, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"case":    c.Name,
+		"entries": len(lines),
+	}).Info("Updated conformance golden file")
+	return nil
+}
+
+func splitLines(data []byte) []string {
+	var lines []string
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if len(line) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,105 @@
+package conformance
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestCorpus(t *testing.T) {
+	cases, err := FindCases(filepath.Join("..", "..", "conformance"))
+	if err != nil {
+		t.Fatalf("FindCases() unexpected error: %v", err)
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			result, err := Verify(c)
+			if err != nil {
+				t.Fatalf("Verify() unexpected error: %v", err)
+			}
+			for _, mismatch := range result.Mismatches {
+				t.Errorf("entry %d:\nexpected: %s\nactual:   %s", mismatch.Line, mismatch.Expected, mismatch.Actual)
+			}
+		})
+	}
+}
+
+func TestVerifyMismatches(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ParserConfigFile, "event_regex: \"^(.*)$\"\njson_extraction: false\n")
+	writeFile(t, dir, InputFile, "login\nlogout\npurchase\n")
+	writeFile(t, dir, GoldenFile, "{\"message\":\"login\"}\r\n{\"message\":\"log_out\"}\n")
+
+	result, err := Verify(Case{Name: "simple", Dir: dir})
+	if err != nil {
+		t.Fatalf("Verify() unexpected error: %v", err)
+	}
+
+	if result.Passed() || result.Entries != 3 {
+		t.Fatalf("Expected failed result with 3 entries, got %+v", result)
+	}
+	if len(result.Mismatches) != 2 {
+		t.Fatalf("Expected 2 mismatches, got %+v", result.Mismatches)
+	}
+	if m := result.Mismatches[0]; m.Line != 2 || m.Expected != `{"message":"log_out"}` || m.Actual != `{"message":"logout"}` {
+		t.Errorf("Unexpected first mismatch %+v", m)
+	}
+	if m := result.Mismatches[1]; m.Line != 3 || m.Expected != "" || m.Actual != `{"message":"purchase"}` {
+		t.Errorf("Unexpected second mismatch %+v", m)
+	}
+
+	if err := Update(Case{Name: "simple", Dir: dir}); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	result, err = Verify(Case{Name: "simple", Dir: dir})
+	if err != nil {
+		t.Fatalf("Verify() unexpected error: %v", err)
+	}
+	if !result.Passed() {
+		t.Errorf("Expected updated golden file to pass, got %+v", result.Mismatches)
+	}
+}
+
+func TestFindCases(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"syslog", "json"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatalf("Failed to create case directory: %v", err)
+		}
+		writeFile(t, filepath.Join(dir, name), ParserConfigFile, "event_regex: \"^(.*)$\"\n")
+	}
+	if err := os.Mkdir(filepath.Join(dir, "notes"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	cases, err := FindCases(dir)
+	if err != nil {
+		t.Fatalf("FindCases() unexpected error: %v", err)
+	}
+	if len(cases) != 2 || cases[0].Name != "json" || cases[1].Name != "syslog" {
+		t.Errorf("Expected json and syslog cases, got %+v", cases)
+	}
+
+	_, err = FindCases(filepath.Join(dir, "notes"))
+	if err == nil || !strings.Contains(err.Error(), "no conformance cases") {
+		t.Errorf("Expected no cases error, got %v", err)
+	}
+}
+
+func TestNewRecord(t *testing.T) {
+	record := NewRecord(&parser.LogEntry{Message: "login"})
+	if record.Timestamp != "" || record.Message != "login" {
+		t.Errorf("Unexpected record %+v", record)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
@@ -0,0 +1,136 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+const (
+	CSVFormat     = "csv"
+	ParquetFormat = "parquet"
+)
+
+// baseColumns are the columns of every exported entry, followed by one column
+// per selected event data property
+var baseColumns = []string{"timestamp", "level", "tag", "pid", "tid", "event", "message"}
+
+// Row is a log entry flattened into columns. Fields are empty, or zero for
+// pid and tid, when the entry has no value for them.
+type Row struct {
+	Timestamp time.Time
+	Level     string
+	Tag       string
+	PID       int
+	TID       int
+	Event     string
+	Message   string
+	// Properties holds the values of the selected properties in order, and
+	// Present whether the entry has each of them
+	Properties []string
+	Present    []bool
+}
+
+// Columns returns the column names of exported entries with the given
+// properties
+func Columns(properties []string) []string {
+	columns := make([]string, 0, len(baseColumns)+len(properties))
+	columns = append(columns, baseColumns...)
+	return append(columns, properties...)
+}
+
+// Flatten returns the row of an entry. Properties are event data paths as
+// accepted by parser.LookupProperty; string values are kept as they are and
+// other values are encoded as JSON.
+func Flatten(entry *parser.LogEntry, properties []string) Row {
+	row := Row{
+		Timestamp:  entry.Timestamp,
+		Level:      entry.Level,
+		Tag:        entry.Tag,
+		PID:        entry.PID,
+		TID:        entry.TID,
+		Event:      analyzer.EventName(entry),
+		Message:    entry.Message,
+		Properties: make([]string, len(properties)),
+		Present:    make([]bool, len(properties)),
+	}
+	for i, property := range properties {
+		value, exists := parser.LookupProperty(entry.EventData, property)
+		if !exists || value == nil {
+			continue
+		}
+		row.Properties[i] = propertyString(value)
+		row.Present[i] = true
+	}
+	return row
+}
+
+func propertyString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// Write writes entries to w in the format, csv or parquet
+func Write(w io.Writer, format string, entries []*parser.LogEntry, properties []string) error {
+	switch format {
+	case CSVFormat:
+		return WriteCSV(w, entries, properties)
+	case ParquetFormat:
+		return WriteParquet(w, entries, properties)
+	default:
+		return fmt.Errorf("unsupported export format: %s (supported: csv, parquet)", format)
+	}
+}
+
+// WriteCSV writes entries as CSV with a header row. Timestamps are RFC 3339
+// with fractional seconds, and missing values are empty.
+func WriteCSV(w io.Writer, entries []*parser.LogEntry, properties []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(Columns(properties)); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	record := make([]string, len(baseColumns)+len(properties))
+	for _, entry := range entries {
+		row := Flatten(entry, properties)
+		record[0] = ""
+		if !row.Timestamp.IsZero() {
+			record[0] = row.Timestamp.Format(time.RFC3339Nano)
+		}
+		record[1] = row.Level
+		record[2] = row.Tag
+		record[3] = optionalInt(row.PID)
+		record[4] = optionalInt(row.TID)
+		record[5] = row.Event
+		record[6] = row.Message
+		copy(record[len(baseColumns):], row.Properties)
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+func optionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func testEntries() []*parser.LogEntry {
+	return []*parser.LogEntry{
+		{
+			Timestamp: time.Date(2025, 1, 15, 10, 30, 15, 100000000, time.UTC),
+			Level:     "I",
+			Tag:       "Analytics",
+			PID:       1234,
+			TID:       1250,
+			Message:   `purchase {"event":"purchase"}`,
+			EventData: map[string]interface{}{
+				"event":      "purchase",
+				"screen":     "checkout",
+				"properties": map[string]interface{}{"total": 42.5, "items": []interface{}{"a", "b"}},
+			},
+		},
+		{Level: "W", Message: "  request slow, retrying  "},
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	entries := testEntries()
+	properties := []string{"screen", "properties.total", "properties.items", "missing"}
+
+	row := Flatten(entries[0], properties)
+	if row.Event != "purchase" || row.PID != 1234 || row.Tag != "Analytics" {
+		t.Errorf("Unexpected row fields: %+v", row)
+	}
+	expected := []string{"checkout", "42.5", `["a","b"]`, ""}
+	for i, value := range expected {
+		if row.Properties[i] != value || row.Present[i] != (value != "") {
+			t.Errorf("Property %s = %q (present %v), want %q", properties[i], row.Properties[i], row.Present[i], value)
+		}
+	}
+
+	if row := Flatten(entries[1], properties); row.Event != "request slow, retrying" || row.Present[0] {
+		t.Errorf("Expected the trimmed message as event and no properties, got %+v", row)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testEntries(), []string{"screen", "properties.total"}); err != nil {
+		t.Fatalf("WriteCSV() unexpected error: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	expected := [][]string{
+		{"timestamp", "level", "tag", "pid", "tid", "event", "message", "screen", "properties.total"},
+		{"2025-01-15T10:30:15.1Z", "I", "Analytics", "1234", "1250", "purchase", `purchase {"event":"purchase"}`, "checkout", "42.5"},
+		{"", "W", "", "", "", "request slow, retrying", "  request slow, retrying  ", "", ""},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d records, got %v", len(expected), records)
+	}
+	for i := range expected {
+		if strings.Join(records[i], "|") != strings.Join(expected[i], "|") {
+			t.Errorf("Record %d = %q, want %q", i, records[i], expected[i])
+		}
+	}
+}
+
+func TestWrite_UnsupportedFormat(t *testing.T) {
+	err := Write(&bytes.Buffer{}, "xlsx", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported export format: xlsx") {
+		t.Errorf("Write() error = %v, want unsupported format error", err)
+	}
+}
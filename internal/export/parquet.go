@@ -0,0 +1,344 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// parquetRowGroupRows is the maximum number of rows of a Parquet row group
+const parquetRowGroupRows = 100000
+
+// Parquet physical and converted types, encodings and thrift compact
+// protocol types used by the writer, as defined by parquet.thrift
+const (
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetOptional = 1
+
+	parquetUTF8            = 0
+	parquetTimestampMicros = 10
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// parquetColumn is an optional column of the file. Its value appends the
+// plain encoding of the row's value to buf and reports whether the row has
+// a value at all.
+type parquetColumn struct {
+	name          string
+	physicalType  int32
+	convertedType int32
+	value         func(buf []byte, row *Row) ([]byte, bool)
+}
+
+func parquetColumns(properties []string) []parquetColumn {
+	columns := []parquetColumn{
+		{"timestamp", parquetInt64, parquetTimestampMicros, func(buf []byte, row *Row) ([]byte, bool) {
+			if row.Timestamp.IsZero() {
+				return buf, false
+			}
+			return binary.LittleEndian.AppendUint64(buf, uint64(row.Timestamp.UnixMicro())), true
+		}},
+		stringColumn("level", func(row *Row) string { return row.Level }),
+		stringColumn("tag", func(row *Row) string { return row.Tag }),
+		intColumn("pid", func(row *Row) int { return row.PID }),
+		intColumn("tid", func(row *Row) int { return row.TID }),
+		stringColumn("event", func(row *Row) string { return row.Event }),
+		stringColumn("message", func(row *Row) string { return row.Message }),
+	}
+	for i, property := range properties {
+		columns = append(columns, parquetColumn{property, parquetByteArray, parquetUTF8, func(buf []byte, row *Row) ([]byte, bool) {
+			if !row.Present[i] {
+				return buf, false
+			}
+			return appendByteArray(buf, row.Properties[i]), true
+		}})
+	}
+	return columns
+}
+
+func stringColumn(name string, field func(row *Row) string) parquetColumn {
+	return parquetColumn{name, parquetByteArray, parquetUTF8, func(buf []byte, row *Row) ([]byte, bool) {
+		value := field(row)
+		if value == "" {
+			return buf, false
+		}
+		return appendByteArray(buf, value), true
+	}}
+}
+
+func intColumn(name string, field func(row *Row) int) parquetColumn {
+	return parquetColumn{name, parquetInt32, -1, func(buf []byte, row *Row) ([]byte, bool) {
+		value := field(row)
+		if value == 0 {
+			return buf, false
+		}
+		return binary.LittleEndian.AppendUint32(buf, uint32(int32(value))), true
+	}}
+}
+
+func appendByteArray(buf []byte, s string) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(s)))
+	return append(buf, s...)
+}
+
+// columnChunk locates the data page of a column in a row group
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+type rowGroup struct {
+	rows   int
+	chunks []columnChunk
+}
+
+// WriteParquet writes entries as an uncompressed Parquet file. Every column
+// is optional and stored as a single plain-encoded data page per row group of
+// up to 100000 rows. Timestamps are microseconds since the epoch, pid and tid
+// 32-bit integers, and the other columns UTF-8 strings.
+func WriteParquet(w io.Writer, entries []*parser.LogEntry, properties []string) error {
+	out := &countingWriter{w: bufio.NewWriter(w)}
+	columns := parquetColumns(properties)
+
+	out.write([]byte("PAR1"))
+	var rowGroups []rowGroup
+	rows := make([]Row, 0, min(len(entries), parquetRowGroupRows))
+	for start := 0; start < len(entries); start += parquetRowGroupRows {
+		end := min(start+parquetRowGroupRows, len(entries))
+		rows = rows[:0]
+		for _, entry := range entries[start:end] {
+			rows = append(rows, Flatten(entry, properties))
+		}
+
+		group := rowGroup{rows: len(rows)}
+		for _, column := range columns {
+			page := encodeDataPage(column, rows)
+			header := encodePageHeader(len(page), len(rows))
+			group.chunks = append(group.chunks, columnChunk{offset: out.n, size: int64(len(header) + len(page))})
+			out.write(header)
+			out.write(page)
+		}
+		rowGroups = append(rowGroups, group)
+	}
+
+	footer := encodeFileMetaData(columns, len(entries), rowGroups)
+	out.write(footer)
+	out.write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	out.write([]byte("PAR1"))
+
+	if out.err == nil {
+		out.err = out.w.Flush()
+	}
+	if out.err != nil {
+		return fmt.Errorf("failed to write Parquet: %w", out.err)
+	}
+	return nil
+}
+
+// encodeDataPage returns the definition levels and values of a column
+func encodeDataPage(column parquetColumn, rows []Row) []byte {
+	present := make([]bool, len(rows))
+	var values []byte
+	for i := range rows {
+		values, present[i] = column.value(values, &rows[i])
+	}
+
+	levels := encodeLevels(present)
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(levels)))
+	page = append(page, levels...)
+	return append(page, values...)
+}
+
+// encodeLevels encodes definition levels with the RLE/bit-packing hybrid
+// encoding, as runs of equal levels of bit width 1
+func encodeLevels(present []bool) []byte {
+	var buf []byte
+	for i := 0; i < len(present); {
+		run := 1
+		for i+run < len(present) && present[i+run] == present[i] {
+			run++
+		}
+		buf = binary.AppendUvarint(buf, uint64(run)<<1)
+		if present[i] {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		i += run
+	}
+	return buf
+}
+
+func encodePageHeader(pageSize, numValues int) []byte {
+	t := &thriftWriter{}
+	t.i32(1, 0) // DATA_PAGE
+	t.i32(2, int32(pageSize))
+	t.i32(3, int32(pageSize))
+	t.beginField(5, thriftStruct)
+	t.beginStruct()
+	t.i32(1, int32(numValues))
+	t.i32(2, parquetPlain)
+	t.i32(3, parquetRLE)
+	t.i32(4, parquetRLE)
+	t.endStruct()
+	t.endStruct()
+	return t.buf
+}
+
+func encodeFileMetaData(columns []parquetColumn, numRows int, rowGroups []rowGroup) []byte {
+	t := &thriftWriter{}
+	t.i32(1, 1)
+
+	t.listField(2, thriftStruct, len(columns)+1)
+	t.beginStruct()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(columns)))
+	t.endStruct()
+	for _, column := range columns {
+		t.beginStruct()
+		t.i32(1, column.physicalType)
+		t.i32(3, parquetOptional)
+		t.binary(4, column.name)
+		if column.convertedType >= 0 {
+			t.i32(6, column.convertedType)
+		}
+		t.endStruct()
+	}
+
+	t.i64(3, int64(numRows))
+
+	t.listField(4, thriftStruct, len(rowGroups))
+	for _, group := range rowGroups {
+		t.beginStruct()
+		t.listField(1, thriftStruct, len(columns))
+		var totalSize int64
+		for i, column := range columns {
+			chunk := group.chunks[i]
+			totalSize += chunk.size
+			t.beginStruct()
+			t.i64(2, chunk.offset)
+			t.beginField(3, thriftStruct)
+			t.beginStruct()
+			t.i32(1, column.physicalType)
+			t.listField(2, thriftI32, 2)
+			t.varint(parquetPlain)
+			t.varint(parquetRLE)
+			t.listField(3, thriftBinary, 1)
+			t.bytes(column.name)
+			t.i32(4, 0) // UNCOMPRESSED
+			t.i64(5, int64(group.rows))
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64(2, totalSize)
+		t.i64(3, int64(group.rows))
+		t.endStruct()
+	}
+
+	t.binary(6, "loglion")
+	t.endStruct()
+	return t.buf
+}
+
+// thriftWriter encodes structs with the thrift compact protocol. The field
+// IDs of enclosing structs are kept on a stack, since field headers hold the
+// delta to the previous field of the same struct.
+type thriftWriter struct {
+	buf     []byte
+	lastID  int16
+	enclose []int16
+}
+
+func (t *thriftWriter) beginField(id int16, fieldType byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|fieldType)
+	} else {
+		t.buf = append(t.buf, fieldType)
+		t.varint(int64(id))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) beginStruct() {
+	t.enclose = append(t.enclose, t.lastID)
+	t.lastID = 0
+}
+
+// endStruct writes the stop field of the current struct, or of the
+// top-level struct when none was begun
+func (t *thriftWriter) endStruct() {
+	t.buf = append(t.buf, 0)
+	if n := len(t.enclose); n > 0 {
+		t.lastID = t.enclose[n-1]
+		t.enclose = t.enclose[:n-1]
+	}
+}
+
+func (t *thriftWriter) listField(id int16, elemType byte, size int) {
+	t.beginField(id, thriftList)
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elemType)
+	} else {
+		t.buf = append(t.buf, 0xF0|elemType)
+		t.buf = binary.AppendUvarint(t.buf, uint64(size))
+	}
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.beginField(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.beginField(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.beginField(id, thriftBinary)
+	t.bytes(s)
+}
+
+// varint writes a zigzag-encoded integer
+func (t *thriftWriter) varint(v int64) {
+	t.buf = binary.AppendVarint(t.buf, v)
+}
+
+func (t *thriftWriter) bytes(s string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+// countingWriter tracks the file offset of written data and keeps the first
+// write error
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) write(p []byte) {
+	if c.err != nil {
+		return
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+}
@@ -0,0 +1,179 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// thriftReader decodes thrift compact structs into maps of field IDs to
+// values, enough to check the files written by WriteParquet
+type thriftReader struct {
+	t   *testing.T
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var lastID int16
+	for {
+		header := r.buf[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		id := lastID + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.varint())
+		}
+		fields[id] = r.readValue(header & 0x0F)
+		lastID = id
+	}
+}
+
+func (r *thriftReader) readValue(valueType byte) interface{} {
+	switch valueType {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n, size := binary.Uvarint(r.buf[r.pos:])
+		r.pos += size + int(n)
+		return string(r.buf[r.pos-int(n) : r.pos])
+	case thriftStruct:
+		return r.readStruct()
+	case thriftList:
+		header := r.buf[r.pos]
+		r.pos++
+		n := int(header >> 4)
+		if n == 15 {
+			size, read := binary.Uvarint(r.buf[r.pos:])
+			n, r.pos = int(size), r.pos+read
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = r.readValue(header & 0x0F)
+		}
+		return list
+	default:
+		r.t.Fatalf("Unexpected thrift type %d at %d", valueType, r.pos)
+		return nil
+	}
+}
+
+func (r *thriftReader) varint() int64 {
+	v, size := binary.Varint(r.buf[r.pos:])
+	r.pos += size
+	return v
+}
+
+func TestWriteParquet(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, testEntries(), []string{"screen"}); err != nil {
+		t.Fatalf("WriteParquet() unexpected error: %v", err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatal("Expected the file to start and end with the PAR1 magic")
+	}
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &thriftReader{t: t, buf: data[len(data)-8-footerSize : len(data)-8]}
+	metadata := footer.readStruct()
+
+	if metadata[3] != int64(2) || metadata[6] != "loglion" {
+		t.Errorf("Unexpected file metadata: %v", metadata)
+	}
+	schema := metadata[2].([]interface{})
+	var names []string
+	for _, element := range schema[1:] {
+		names = append(names, element.(map[int16]interface{})[4].(string))
+	}
+	if got := names; len(got) != 8 || got[0] != "timestamp" || got[5] != "event" || got[7] != "screen" {
+		t.Errorf("Unexpected schema columns: %v", got)
+	}
+
+	rowGroups := metadata[4].([]interface{})
+	if len(rowGroups) != 1 {
+		t.Fatalf("Expected 1 row group, got %d", len(rowGroups))
+	}
+	chunks := rowGroups[0].(map[int16]interface{})[1].([]interface{})
+
+	// The timestamp column holds one value and a null
+	timestamps := readPage(t, data, chunks[0])
+	if len(timestamps.levels) != 2 || !timestamps.levels[0] || timestamps.levels[1] {
+		t.Errorf("Unexpected timestamp definition levels: %v", timestamps.levels)
+	}
+	micros := int64(binary.LittleEndian.Uint64(timestamps.values))
+	if want := time.Date(2025, 1, 15, 10, 30, 15, 100000000, time.UTC).UnixMicro(); micros != want {
+		t.Errorf("Timestamp = %d, want %d", micros, want)
+	}
+
+	// The event column holds two strings
+	events := readPage(t, data, chunks[5])
+	if got := byteArrays(events.values); len(got) != 2 || got[0] != "purchase" || got[1] != "request slow, retrying" {
+		t.Errorf("Unexpected event values: %q", got)
+	}
+
+	screens := readPage(t, data, chunks[7])
+	if got := byteArrays(screens.values); len(got) != 1 || got[0] != "checkout" || screens.levels[1] {
+		t.Errorf("Unexpected screen values: %q, levels %v", got, screens.levels)
+	}
+}
+
+func TestWriteParquet_NoEntries(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, nil, nil); err != nil {
+		t.Fatalf("WriteParquet() unexpected error: %v", err)
+	}
+	data := buf.Bytes()
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	metadata := (&thriftReader{t: t, buf: data[len(data)-8-footerSize : len(data)-8]}).readStruct()
+	if metadata[3] != int64(0) || len(metadata[4].([]interface{})) != 0 {
+		t.Errorf("Expected no rows and row groups, got %v", metadata)
+	}
+}
+
+type decodedPage struct {
+	levels []bool
+	values []byte
+}
+
+// readPage decodes the data page of a column chunk
+func readPage(t *testing.T, data []byte, chunk interface{}) decodedPage {
+	t.Helper()
+	meta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+	offset := int(meta[9].(int64))
+
+	reader := &thriftReader{t: t, buf: data, pos: offset}
+	header := reader.readStruct()
+	numValues := int(header[5].(map[int16]interface{})[1].(int64))
+	page := data[reader.pos : reader.pos+int(header[2].(int64))]
+
+	levelsSize := int(binary.LittleEndian.Uint32(page))
+	levels := page[4 : 4+levelsSize]
+	var decoded decodedPage
+	for len(levels) > 0 {
+		run, size := binary.Uvarint(levels)
+		for i := 0; i < int(run>>1); i++ {
+			decoded.levels = append(decoded.levels, levels[size] == 1)
+		}
+		levels = levels[size+1:]
+	}
+	if len(decoded.levels) != numValues {
+		t.Fatalf("Expected %d definition levels, got %d", numValues, len(decoded.levels))
+	}
+	decoded.values = page[4+levelsSize:]
+	return decoded
+}
+
+func byteArrays(values []byte) []string {
+	var strs []string
+	for len(values) > 0 {
+		n := binary.LittleEndian.Uint32(values)
+		strs = append(strs, string(values[4:4+n]))
+		values = values[4+n:]
+	}
+	return strs
+}
@@ -0,0 +1,183 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Comparison operators of property conditions, longest first so ">=" is not
+// read as ">"
+var comparisonOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// Condition checks one event data property. It is either a comparison such
+// as ">= 10", "< 3", "== true" or "!= guest", or a regular expression that
+// string values must match.
+type Condition struct {
+	regex *regexp.Regexp
+
+	operator string
+	number   *float64
+	boolean  *bool
+	text     string
+	// ignoreCase compares string operands regardless of case
+	ignoreCase bool
+}
+
+// ParseCondition parses a required property pattern. Patterns starting with
+// a comparison operator compare the value with the operand: numerically for
+// numbers, as a boolean for true and false, and as a string otherwise.
+// Ordering operators need a number. Other patterns are regular expressions.
+func ParseCondition(pattern string, ignoreCase bool) (*Condition, error) {
+	if operator, operand, found := cutOperator(pattern); found {
+		return parseComparison(operator, operand, ignoreCase)
+	}
+
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &Condition{regex: regex}, nil
+}
+
+// IsComparison reports whether a required property pattern is a comparison
+// rather than a regular expression
+func IsComparison(pattern string) bool {
+	_, _, found := cutOperator(pattern)
+	return found
+}
+
+// cutOperator splits a comparison pattern into its operator and operand
+func cutOperator(pattern string) (string, string, bool) {
+	trimmed := strings.TrimSpace(pattern)
+	for _, operator := range comparisonOperators {
+		if operand, found := strings.CutPrefix(trimmed, operator); found {
+			return operator, strings.TrimSpace(operand), true
+		}
+	}
+	return "", "", false
+}
+
+func parseComparison(operator, operand string, ignoreCase bool) (*Condition, error) {
+	if operand == "" {
+		return nil, fmt.Errorf("missing value after '%s'", operator)
+	}
+
+	condition := &Condition{operator: operator, ignoreCase: ignoreCase}
+	if number, err := strconv.ParseFloat(operand, 64); err == nil {
+		condition.number = &number
+		return condition, nil
+	}
+
+	if operator != "==" && operator != "!=" {
+		return nil, fmt.Errorf("'%s' needs a number, got '%s'", operator, operand)
+	}
+	if operand == "true" || operand == "false" {
+		boolean := operand == "true"
+		condition.boolean = &boolean
+		return condition, nil
+	}
+	if unquoted, err := strconv.Unquote(operand); err == nil {
+		operand = unquoted
+	}
+	condition.text = operand
+	return condition, nil
+}
+
+// Match reports whether value satisfies the condition. Regular expressions
+// only match strings; comparisons convert numeric strings and JSON numbers.
+func (c *Condition) Match(value interface{}) bool {
+	if c.regex != nil {
+		str, ok := value.(string)
+		return ok && c.regex.MatchString(str)
+	}
+
+	switch {
+	case c.number != nil:
+		number, ok := toNumber(value)
+		return ok && compareNumbers(c.operator, number, *c.number)
+	case c.boolean != nil:
+		boolean, ok := toBool(value)
+		return ok && (boolean == *c.boolean) == (c.operator == "==")
+	default:
+		str, ok := value.(string)
+		if !ok {
+			return false
+		}
+		equal := str == c.text || (c.ignoreCase && strings.EqualFold(str, c.text))
+		return equal == (c.operator == "==")
+	}
+}
+
+// String describes the condition for logging
+func (c *Condition) String() string {
+	if c.regex != nil {
+		return c.regex.String()
+	}
+	switch {
+	case c.number != nil:
+		return fmt.Sprintf("%s %g", c.operator, *c.number)
+	case c.boolean != nil:
+		return fmt.Sprintf("%s %t", c.operator, *c.boolean)
+	default:
+		return fmt.Sprintf("%s %q", c.operator, c.text)
+	}
+}
+
+func compareNumbers(operator string, value, operand float64) bool {
+	switch operator {
+	case ">=":
+		return value >= operand
+	case "<=":
+		return value <= operand
+	case ">":
+		return value > operand
+	case "<":
+		return value < operand
+	case "==":
+		return value == operand
+	default:
+		return value != operand
+	}
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func toBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,73 @@
+package expr
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCondition_Match(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		ignoreCase bool
+		value      interface{}
+		want       bool
+	}{
+		{name: "greater or equal", pattern: ">= 10", value: 10.0, want: true},
+		{name: "greater or equal below", pattern: ">= 10", value: 9.99},
+		{name: "less than int", pattern: "< 3", value: 2, want: true},
+		{name: "less than without space", pattern: "<3", value: 3},
+		{name: "greater than numeric string", pattern: "> 9.99", value: "12.50", want: true},
+		{name: "json number", pattern: "== 42", value: json.Number("42"), want: true},
+		{name: "not equal number", pattern: "!= 0", value: 1.0, want: true},
+		{name: "number against text", pattern: ">= 10", value: "many"},
+		{name: "number against bool", pattern: ">= 1", value: true},
+		{name: "bool equality", pattern: "== true", value: true, want: true},
+		{name: "bool inequality", pattern: "!= true", value: false, want: true},
+		{name: "bool string", pattern: "== false", value: "false", want: true},
+		{name: "bool against number", pattern: "== true", value: 1.0},
+		{name: "string equality", pattern: "== gold", value: "gold", want: true},
+		{name: "quoted string", pattern: `== "gold tier"`, value: "gold tier", want: true},
+		{name: "string inequality", pattern: "!= guest", value: "member", want: true},
+		{name: "string ignoring case", pattern: "== GOLD", ignoreCase: true, value: "gold", want: true},
+		{name: "string respecting case", pattern: "== GOLD", value: "gold"},
+		{name: "regex", pattern: `^\d+$`, value: "123", want: true},
+		{name: "regex ignoring case", pattern: "^mobile$", ignoreCase: true, value: "MOBILE", want: true},
+		{name: "regex against number", pattern: `^\d+$`, value: 123.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := ParseCondition(tt.pattern, tt.ignoreCase)
+			if err != nil {
+				t.Fatalf("ParseCondition() unexpected error: %v", err)
+			}
+			if got := condition.Match(tt.value); got != tt.want {
+				t.Errorf("Match(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCondition_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		errContains string
+	}{
+		{name: "missing operand", pattern: ">=", errContains: "missing value after '>='"},
+		{name: "ordering a string", pattern: "< abc", errContains: "'<' needs a number, got 'abc'"},
+		{name: "ordering a bool", pattern: "> true", errContains: "'>' needs a number"},
+		{name: "invalid regex", pattern: "[invalid", errContains: "error parsing regexp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCondition(tt.pattern, false)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("ParseCondition() error = %v, want it to contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
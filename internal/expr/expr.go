@@ -0,0 +1,48 @@
+// Package expr evaluates CEL expressions over log entries and typed
+// conditions over their properties, for funnel steps whose conditions cannot
+// be written as regular expressions. The CEL engine can be left out with
+// -tags noexpr, which makes Compile fail.
+package expr
+
+import (
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// Variables available to expressions
+const (
+	// EventVariable is the "event" property of structured entries, or the
+	// raw message of other entries, like event patterns match
+	EventVariable     = "event"
+	MessageVariable   = "message"
+	LevelVariable     = "level"
+	TagVariable       = "tag"
+	PIDVariable       = "pid"
+	TimestampVariable = "timestamp"
+	// PropsVariable holds the event data of the entry, empty if it has none
+	PropsVariable = "props"
+)
+
+// activation maps the fields of entry to the expression variables
+func activation(entry *parser.LogEntry) map[string]interface{} {
+	event := entry.Message
+	if value, exists := entry.EventData["event"]; exists {
+		if str, ok := value.(string); ok {
+			event = str
+		}
+	}
+
+	props := entry.EventData
+	if props == nil {
+		props = map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		EventVariable:     event,
+		MessageVariable:   entry.Message,
+		LevelVariable:     entry.Level,
+		TagVariable:       entry.Tag,
+		PIDVariable:       entry.PID,
+		TimestampVariable: entry.Timestamp,
+		PropsVariable:     props,
+	}
+}
@@ -0,0 +1,78 @@
+//go:build !noexpr
+
+package expr
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// Supported reports whether CEL expressions are compiled in
+const Supported = true
+
+// celEnv creates the CEL environment on first use, so commands without
+// expressions do not pay for it
+var celEnv = sync.OnceValues(func() (*cel.Env, error) {
+	env, err := cel.NewEnv(
+		cel.Variable(EventVariable, cel.StringType),
+		cel.Variable(MessageVariable, cel.StringType),
+		cel.Variable(LevelVariable, cel.StringType),
+		cel.Variable(TagVariable, cel.StringType),
+		cel.Variable(PIDVariable, cel.IntType),
+		cel.Variable(TimestampVariable, cel.TimestampType),
+		cel.Variable(PropsVariable, cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	return env, nil
+})
+
+// Program is a compiled boolean expression
+type Program struct {
+	source  string
+	program cel.Program
+}
+
+// Compile parses and type-checks a CEL expression, which must evaluate to a bool
+func Compile(source string) (*Program, error) {
+	env, err := celEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %s", ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{source: source, program: program}, nil
+}
+
+// Match reports whether the expression holds for entry. Evaluation errors,
+// such as accessing a property the entry does not have, do not match.
+func (p *Program) Match(entry *parser.LogEntry) bool {
+	result, _, err := p.program.Eval(activation(entry))
+	if err != nil {
+		logrus.WithError(err).WithField("expr", p.source).Debug("Expression evaluation failed, not matching")
+		return false
+	}
+
+	matched, ok := result.Value().(bool)
+	return ok && matched
+}
+
+// String returns the source of the expression
+func (p *Program) String() string {
+	return p.source
+}
@@ -0,0 +1,33 @@
+//go:build noexpr
+
+package expr
+
+import (
+	"fmt"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// Supported reports whether CEL expressions are compiled in
+const Supported = false
+
+// Program is a compiled boolean expression. Without the CEL engine no
+// expression compiles.
+type Program struct {
+	source string
+}
+
+// Compile fails, as the CEL engine was left out of this build
+func Compile(source string) (*Program, error) {
+	return nil, fmt.Errorf("CEL expressions are not supported in this build (built with -tags noexpr)")
+}
+
+// Match never matches
+func (p *Program) Match(entry *parser.LogEntry) bool {
+	return false
+}
+
+// String returns the source of the expression
+func (p *Program) String() string {
+	return p.source
+}
@@ -0,0 +1,15 @@
+//go:build noexpr
+
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompile_NotCompiledIn(t *testing.T) {
+	_, err := Compile(`event == "purchase"`)
+	if err == nil || !strings.Contains(err.Error(), "not supported in this build") {
+		t.Errorf("Compile() error = %v, want not supported error", err)
+	}
+}
@@ -0,0 +1,81 @@
+//go:build !noexpr
+
+package expr
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestProgram_Match(t *testing.T) {
+	structured := &parser.LogEntry{
+		Timestamp: time.Date(2025, 1, 15, 10, 30, 45, 0, time.UTC),
+		Level:     "I",
+		Tag:       "Analytics",
+		PID:       1234,
+		Message:   `{"event":"purchase","amount":12.5,"items":["a","b"],"user":{"tier":"gold"}}`,
+		EventData: map[string]interface{}{
+			"event":  "purchase",
+			"amount": 12.5,
+			"count":  "3",
+			"items":  []interface{}{"a", "b"},
+			"user":   map[string]interface{}{"tier": "gold"},
+		},
+	}
+	plain := &parser.LogEntry{Message: "user login succeeded", Level: "W"}
+
+	tests := []struct {
+		name   string
+		source string
+		entry  *parser.LogEntry
+		want   bool
+	}{
+		{name: "event and numeric property", source: `event == "purchase" && double(props.amount) > 9.99`, entry: structured, want: true},
+		{name: "numeric property below threshold", source: `double(props.amount) > 20.0`, entry: structured},
+		{name: "string property converted", source: `int(props.count) >= 3`, entry: structured, want: true},
+		{name: "nested property", source: `props.user.tier == "gold"`, entry: structured, want: true},
+		{name: "list property", source: `size(props.items) == 2`, entry: structured, want: true},
+		{name: "entry fields", source: `level == "I" && tag == "Analytics" && pid == 1234`, entry: structured, want: true},
+		{name: "timestamp", source: `timestamp > timestamp("2025-01-01T00:00:00Z")`, entry: structured, want: true},
+		{name: "has", source: `has(props.discount)`, entry: structured},
+		{name: "missing property does not match", source: `props.discount > 0.0`, entry: structured},
+		{name: "raw message as event", source: `event.contains("login") && level == "W"`, entry: plain, want: true},
+		{name: "entry without event data", source: `size(props) == 0`, entry: plain, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			if got := program.Match(tt.entry); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		errContains string
+	}{
+		{name: "syntax error", source: `event ==`, errContains: "Syntax error"},
+		{name: "undeclared variable", source: `amount > 1`, errContains: "undeclared reference to 'amount'"},
+		{name: "not a bool", source: `event + "x"`, errContains: "must evaluate to a bool, not string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Compile() error = %v, want it to contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/sirupsen/logrus"
+)
+
+// Snapshot is the analysis state published to the metrics endpoint.
+// A snapshot is never modified after it has been published.
+type Snapshot struct {
+	EntriesParsed int
+	Funnels       []*analyzer.FunnelResult
+	Count         *analyzer.CountResult
+	UpdatedAt     time.Time
+}
+
+// Exporter serves the latest snapshot in Prometheus text format. Updates
+// swap an atomic pointer, so scrapes never block the analysis loop.
+type Exporter struct {
+	snapshot atomic.Pointer[Snapshot]
+}
+
+func NewExporter() *Exporter {
+	logrus.Debug("Creating new metrics exporter")
+	return &Exporter{}
+}
+
+// Update publishes a new snapshot
+func (e *Exporter) Update(snapshot *Snapshot) {
+	e.snapshot.Store(snapshot)
+}
+
+// Snapshot returns the latest published snapshot, or nil if none was published yet
+func (e *Exporter) Snapshot() *Snapshot {
+	return e.snapshot.Load()
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot := e.snapshot.Load()
+	logrus.WithField("has_snapshot", snapshot != nil).Debug("Serving metrics")
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := WritePrometheus(w, snapshot); err != nil {
+		logrus.WithError(err).Warn("Failed to write metrics response")
+	}
+}
+
+// WritePrometheus writes snapshot in the Prometheus text exposition format
+func WritePrometheus(w io.Writer, snapshot *Snapshot) error {
+	var out strings.Builder
+
+	if snapshot == nil {
+		snapshot = &Snapshot{}
+	}
+
+	writeHeader(&out, "loglion_entries_parsed", "Number of log entries parsed so far")
+	out.WriteString(fmt.Sprintf("loglion_entries_parsed %d\n", snapshot.EntriesParsed))
+
+	if !snapshot.UpdatedAt.IsZero() {
+		writeHeader(&out, "loglion_last_update_timestamp_seconds", "Unix time of the last analysis update")
+		out.WriteString(fmt.Sprintf("loglion_last_update_timestamp_seconds %d\n", snapshot.UpdatedAt.Unix()))
+	}
+
+	if len(snapshot.Funnels) > 0 {
+		writeHeader(&out, "loglion_funnel_completed", "Whether the funnel has been completed at least once (1 or 0)")
+		for _, funnel := range snapshot.Funnels {
+			completed := 0
+			if funnel.FunnelCompleted {
+				completed = 1
+			}
+			out.WriteString(fmt.Sprintf("loglion_funnel_completed{funnel=\"%s\"} %d\n", escapeLabel(funnel.FunnelName), completed))
+		}
+
+		writeHeader(&out, "loglion_funnel_conversion_ratio", "Ratio of funnel entries that reached the last step")
+		for _, funnel := range snapshot.Funnels {
+			out.WriteString(fmt.Sprintf("loglion_funnel_conversion_ratio{funnel=\"%s\"} %g\n",
+				escapeLabel(funnel.FunnelName), funnel.ConversionRate()/100))
+		}
+
+		writeHeader(&out, "loglion_funnel_step_events", "Number of funnel entries that reached the step")
+		for _, funnel := range snapshot.Funnels {
+			for _, step := range funnel.Steps {
+				out.WriteString(fmt.Sprintf("loglion_funnel_step_events{funnel=\"%s\",step=\"%s\"} %d\n",
+					escapeLabel(funnel.FunnelName), escapeLabel(step.Name), step.EventCount))
+			}
+		}
+
+		writeHeader(&out, "loglion_funnel_anomalies", "Number of step occurrence anomalies")
+		for _, funnel := range snapshot.Funnels {
+			out.WriteString(fmt.Sprintf("loglion_funnel_anomalies{funnel=\"%s\"} %d\n",
+				escapeLabel(funnel.FunnelName), len(funnel.Anomalies)))
+		}
+	}
+
+	if snapshot.Count != nil {
+		writeHeader(&out, "loglion_pattern_matches", "Number of log entries matching the count pattern")
+		for _, patternCount := range snapshot.Count.PatternCounts {
+			out.WriteString(fmt.Sprintf("loglion_pattern_matches{pattern=\"%s\"} %d\n",
+				escapeLabel(patternCount.Pattern), patternCount.Count))
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func writeHeader(out *strings.Builder, name, help string) {
+	out.WriteString(fmt.Sprintf("# HELP %s %s\n", name, help))
+	out.WriteString(fmt.Sprintf("# TYPE %s gauge\n", name))
+}
+
+// escapeLabel escapes a label value as required by the exposition format
+func escapeLabel(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+)
+
+func TestWritePrometheus_Funnel(t *testing.T) {
+	snapshot := &Snapshot{
+		EntriesParsed: 42,
+		UpdatedAt:     time.Unix(1700000000, 0),
+		Funnels: []*analyzer.FunnelResult{
+			{
+				FunnelName:      "Checkout",
+				FunnelCompleted: true,
+				Steps: []analyzer.StepResult{
+					{Name: "View", EventCount: 10, Percentage: 100},
+					{Name: "Buy", EventCount: 4, Percentage: 40},
+				},
+				Anomalies: []analyzer.Anomaly{{Step: "View", Occurrences: 3, Limit: 2}},
+			},
+		},
+	}
+
+	var out strings.Builder
+	if err := WritePrometheus(&out, snapshot); err != nil {
+		t.Fatalf("WritePrometheus() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"# TYPE loglion_entries_parsed gauge",
+		"loglion_entries_parsed 42",
+		"loglion_last_update_timestamp_seconds 1700000000",
+		`loglion_funnel_completed{funnel="Checkout"} 1`,
+		`loglion_funnel_conversion_ratio{funnel="Checkout"} 0.4`,
+		`loglion_funnel_step_events{funnel="Checkout",step="View"} 10`,
+		`loglion_funnel_step_events{funnel="Checkout",step="Buy"} 4`,
+		`loglion_funnel_anomalies{funnel="Checkout"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("WritePrometheus() should contain %q, got:\n%s", line, out.String())
+		}
+	}
+
+	if strings.Contains(out.String(), "loglion_pattern_matches") {
+		t.Errorf("WritePrometheus() should not contain pattern metrics for funnel snapshot")
+	}
+}
+
+func TestWritePrometheus_Count(t *testing.T) {
+	snapshot := &Snapshot{
+		EntriesParsed: 5,
+		Count: &analyzer.CountResult{
+			TotalEventsAnalyzed: 5,
+			PatternCounts: []analyzer.PatternCount{
+				{Pattern: `say "hi"\d`, Count: 2},
+			},
+		},
+	}
+
+	var out strings.Builder
+	if err := WritePrometheus(&out, snapshot); err != nil {
+		t.Fatalf("WritePrometheus() unexpected error: %v", err)
+	}
+
+	expected := `loglion_pattern_matches{pattern="say \"hi\"\\d"} 2`
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("WritePrometheus() should contain %q, got:\n%s", expected, out.String())
+	}
+}
+
+func TestWritePrometheus_NilSnapshot(t *testing.T) {
+	var out strings.Builder
+	if err := WritePrometheus(&out, nil); err != nil {
+		t.Fatalf("WritePrometheus() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "loglion_entries_parsed 0") {
+		t.Errorf("WritePrometheus() should report zero entries, got:\n%s", out.String())
+	}
+}
+
+func TestExporter_ServeHTTP(t *testing.T) {
+	exporter := NewExporter()
+	if exporter.Snapshot() != nil {
+		t.Fatal("Expected no snapshot before first update")
+	}
+
+	exporter.Update(&Snapshot{EntriesParsed: 7})
+
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := io.ReadAll(recorder.Body)
+	if !strings.Contains(string(body), "loglion_entries_parsed 7") {
+		t.Errorf("Expected metrics body to contain entries count, got:\n%s", body)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("Expected text/plain content type, got %q", contentType)
+	}
+}
+
+func TestExporter_ConcurrentUpdates(t *testing.T) {
+	exporter := NewExporter()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			exporter.Update(&Snapshot{EntriesParsed: i})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			exporter.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics", nil))
+		}
+	}()
+	wg.Wait()
+
+	if snapshot := exporter.Snapshot(); snapshot == nil || snapshot.EntriesParsed != 99 {
+		t.Errorf("Expected last snapshot to be published, got %+v", snapshot)
+	}
+}
@@ -0,0 +1,330 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultServiceName is the service.name resource attribute of exported traces
+const DefaultServiceName = "loglion"
+
+// tracesPerRequest is the number of conversions sent in one export request
+const tracesPerRequest = 100
+
+// Trace is a funnel conversion: the step matches of one attempt that
+// completed the funnel, in step order
+type Trace struct {
+	Funnel string
+	// Group is the group_by value of the attempt, empty for ungrouped funnels
+	Group string
+	// Conversion counts the conversions of the funnel from 1
+	Conversion int
+	Steps      []analyzer.StepMatch
+}
+
+// Recorder collects the step matches of funnel attempts through analyzer
+// hooks and keeps every attempt that completes the funnel as a trace
+type Recorder struct {
+	// attempts holds the matches of the current attempt per funnel and group
+	attempts map[string][]analyzer.StepMatch
+	traces   []Trace
+}
+
+func NewRecorder() *Recorder {
+	logrus.Debug("Creating new trace recorder")
+	return &Recorder{attempts: make(map[string][]analyzer.StepMatch)}
+}
+
+// Hooks returns the analyzer hooks recording the conversions of funnelName
+func (r *Recorder) Hooks(funnelName string) analyzer.Hooks {
+	return analyzer.Hooks{
+		OnStepMatched: func(match analyzer.StepMatch) {
+			key := funnelName + "\x00" + match.Group
+			// Matching the first step always starts a new attempt
+			if match.StepIndex == 0 {
+				r.attempts[key] = nil
+			}
+			r.attempts[key] = append(r.attempts[key], match)
+		},
+		OnConversion: func(conversion analyzer.Conversion) {
+			key := funnelName + "\x00" + conversion.Group
+			r.traces = append(r.traces, Trace{
+				Funnel:     funnelName,
+				Group:      conversion.Group,
+				Conversion: conversion.Number,
+				Steps:      r.attempts[key],
+			})
+			delete(r.attempts, key)
+		},
+	}
+}
+
+// Traces returns the recorded conversions in the order they completed
+func (r *Recorder) Traces() []Trace {
+	return r.traces
+}
+
+// Exporter sends traces to an OTLP/HTTP endpoint in the JSON encoding
+type Exporter struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	client      *http.Client
+}
+
+// NewExporter creates an exporter for endpoint, the URL of an OTLP/HTTP
+// receiver. An endpoint without a path gets the standard /v1/traces path.
+// headers are added to every request, e.g. for authentication.
+func NewExporter(endpoint string, headers map[string]string, serviceName string) (*Exporter, error) {
+	logrus.WithFields(logrus.Fields{
+		"endpoint":     endpoint,
+		"service_name": serviceName,
+	}).Debug("Creating new OTLP exporter")
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") || endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint '%s' (expected an http or https URL)", endpoint)
+	}
+	if endpointURL.Path == "" || endpointURL.Path == "/" {
+		endpointURL.Path = "/v1/traces"
+	}
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+
+	return &Exporter{
+		endpoint:    endpointURL.String(),
+		headers:     headers,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Export sends traces to the endpoint and returns the number of traces sent.
+// Conversions whose entries have no timestamp cannot be placed on a timeline
+// and are skipped.
+func (e *Exporter) Export(ctx context.Context, traces []Trace) (int, error) {
+	var spans []span
+	sent := 0
+	for _, trace := range traces {
+		traceSpans, ok := traceToSpans(trace)
+		if !ok {
+			logrus.WithFields(logrus.Fields{
+				"funnel":     trace.Funnel,
+				"conversion": trace.Conversion,
+			}).Debug("Skipping conversion without timestamps")
+			continue
+		}
+		spans = append(spans, traceSpans...)
+		sent++
+
+		if sent%tracesPerRequest == 0 {
+			if err := e.send(ctx, spans); err != nil {
+				return sent - tracesPerRequest, err
+			}
+			spans = nil
+		}
+	}
+
+	if len(spans) > 0 {
+		if err := e.send(ctx, spans); err != nil {
+			return sent - sent%tracesPerRequest, err
+		}
+	}
+	logrus.WithFields(logrus.Fields{
+		"endpoint": e.endpoint,
+		"traces":   sent,
+		"skipped":  len(traces) - sent,
+	}).Info("Exported funnel conversions as traces")
+	return sent, nil
+}
+
+func (e *Exporter) send(ctx context.Context, spans []span) error {
+	body, err := json.Marshal(exportRequest{ResourceSpans: []resourceSpans{{
+		Resource: resource{Attributes: []attribute{stringAttribute("service.name", e.serviceName)}},
+		ScopeSpans: []scopeSpans{{
+			Scope: scope{Name: "loglion"},
+			Spans: spans,
+		}},
+	}}})
+	if err != nil {
+		return fmt.Errorf("failed to encode traces: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		request.Header.Set(key, value)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"endpoint": e.endpoint,
+		"spans":    len(spans),
+	}).Debug("Sending spans to OTLP endpoint")
+	response, err := e.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send traces to %s: %w", e.endpoint, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("OTLP endpoint %s returned %s: %s", e.endpoint, response.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// traceToSpans converts a conversion into a root span covering the whole
+// conversion and one child span per step, from the previous step's entry to
+// the entry that matched the step. The first step is an instant.
+func traceToSpans(trace Trace) ([]span, bool) {
+	if len(trace.Steps) == 0 {
+		return nil, false
+	}
+	for _, step := range trace.Steps {
+		if step.Entry.Timestamp.IsZero() {
+			return nil, false
+		}
+	}
+
+	traceID := newID(16)
+	rootID := newID(8)
+	start := spanTime(trace.Steps[0].Entry.Timestamp)
+	end := spanTime(trace.Steps[len(trace.Steps)-1].Entry.Timestamp)
+
+	rootAttributes := []attribute{
+		stringAttribute("loglion.funnel", trace.Funnel),
+		intAttribute("loglion.conversion", trace.Conversion),
+	}
+	if trace.Group != "" {
+		rootAttributes = append(rootAttributes, stringAttribute("loglion.group", trace.Group))
+	}
+	spans := []span{{
+		TraceID:           traceID,
+		SpanID:            rootID,
+		Name:              trace.Funnel,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: unixNano(start),
+		EndTimeUnixNano:   unixNano(end),
+		Attributes:        rootAttributes,
+	}}
+
+	previous := start
+	for _, step := range trace.Steps {
+		timestamp := spanTime(step.Entry.Timestamp)
+		attributes := []attribute{
+			stringAttribute("loglion.step", step.Step),
+			intAttribute("loglion.step_index", step.StepIndex+1),
+			intAttribute("loglion.entry_index", step.EntryIndex),
+			stringAttribute("log.message", step.Entry.Message),
+		}
+		if step.Branch != "" {
+			attributes = append(attributes, stringAttribute("loglion.branch", step.Branch))
+		}
+		spans = append(spans, span{
+			TraceID:           traceID,
+			SpanID:            newID(8),
+			ParentSpanID:      rootID,
+			Name:              step.Step,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: unixNano(previous),
+			EndTimeUnixNano:   unixNano(timestamp),
+			Attributes:        attributes,
+		})
+		previous = timestamp
+	}
+	return spans, true
+}
+
+// spanTime places timestamps without a year, such as those of logcat, in the
+// current year
+func spanTime(t time.Time) time.Time {
+	if t.Year() == 0 {
+		return t.AddDate(time.Now().Year(), 0, 0)
+	}
+	return t
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// newID returns a random trace or span ID of size bytes, hex encoded as the
+// OTLP JSON encoding requires
+func newID(size int) string {
+	id := make([]byte, size)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// OTLP JSON encoding of ExportTraceServiceRequest
+
+const spanKindInternal = 1
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []attribute `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type span struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId,omitempty"`
+	Name              string      `json:"name"`
+	Kind              int         `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []attribute `json:"attributes"`
+}
+
+type attribute struct {
+	Key   string         `json:"key"`
+	Value attributeValue `json:"value"`
+}
+
+type attributeValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	// IntValue is a decimal string, as int64 values are in OTLP JSON
+	IntValue *string `json:"intValue,omitempty"`
+}
+
+func stringAttribute(key, value string) attribute {
+	return attribute{Key: key, Value: attributeValue{StringValue: &value}}
+}
+
+func intAttribute(key string, value int) attribute {
+	str := strconv.Itoa(value)
+	return attribute{Key: key, Value: attributeValue{IntValue: &str}}
+}
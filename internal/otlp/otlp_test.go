@@ -0,0 +1,190 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func checkoutEntries() []*parser.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return []*parser.LogEntry{
+		{Timestamp: base, Message: "view"},
+		{Timestamp: base.Add(2 * time.Second), Message: "view"},
+		{Timestamp: base.Add(5 * time.Second), Message: "buy"},
+		{Timestamp: base.Add(9 * time.Second), Message: "view"},
+	}
+}
+
+func checkoutConfig() *config.FunnelConfig {
+	return &config.FunnelConfig{
+		Name: "checkout",
+		Steps: []config.Step{
+			{Name: "view", EventPattern: "^view$"},
+			{Name: "buy", EventPattern: "^buy$"},
+		},
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder()
+	analyzer.NewFunnelAnalyzerWithHooks(checkoutConfig(), recorder.Hooks("checkout")).AnalyzeFunnel(checkoutEntries(), 0)
+
+	traces := recorder.Traces()
+	if len(traces) != 1 {
+		t.Fatalf("Expected one trace, got %d", len(traces))
+	}
+	trace := traces[0]
+	if trace.Funnel != "checkout" || trace.Conversion != 1 || len(trace.Steps) != 2 {
+		t.Fatalf("Unexpected trace %+v", trace)
+	}
+	if trace.Steps[0].EntryIndex != 1 || trace.Steps[1].EntryIndex != 3 {
+		t.Errorf("Expected the conversion of entries 1 and 3, got %d and %d", trace.Steps[0].EntryIndex, trace.Steps[1].EntryIndex)
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"http://localhost:4318", "http://localhost:4318/v1/traces"},
+		{"http://localhost:4318/", "http://localhost:4318/v1/traces"},
+		{"https://collector.example.com/otlp/v1/traces", "https://collector.example.com/otlp/v1/traces"},
+	}
+	for _, tt := range tests {
+		exporter, err := NewExporter(tt.endpoint, nil, "")
+		if err != nil {
+			t.Fatalf("NewExporter(%q) unexpected error: %v", tt.endpoint, err)
+		}
+		if exporter.endpoint != tt.want {
+			t.Errorf("NewExporter(%q) endpoint = %q, want %q", tt.endpoint, exporter.endpoint, tt.want)
+		}
+		if exporter.serviceName != DefaultServiceName {
+			t.Errorf("Expected default service name, got %q", exporter.serviceName)
+		}
+	}
+
+	for _, invalid := range []string{"", "localhost:4318", "ftp://localhost", "http://"} {
+		if _, err := NewExporter(invalid, nil, ""); err == nil {
+			t.Errorf("Expected error for endpoint %q", invalid)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	var request exportRequest
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("Expected a request to /v1/traces, got %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder()
+	analyzer.NewFunnelAnalyzerWithHooks(checkoutConfig(), recorder.Hooks("checkout")).AnalyzeFunnel(checkoutEntries(), 0)
+	traces := append(recorder.Traces(), Trace{
+		Funnel:     "checkout",
+		Conversion: 2,
+		Steps:      []analyzer.StepMatch{{Step: "view", Entry: &parser.LogEntry{Message: "view"}}},
+	})
+
+	exporter, err := NewExporter(server.URL, map[string]string{"Authorization": "Bearer token"}, "shop")
+	if err != nil {
+		t.Fatalf("NewExporter() unexpected error: %v", err)
+	}
+	exported, err := exporter.Export(context.Background(), traces)
+	if err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	// The conversion without timestamps is skipped
+	if exported != 1 {
+		t.Errorf("Expected 1 exported trace, got %d", exported)
+	}
+
+	if header.Get("Content-Type") != "application/json" || header.Get("Authorization") != "Bearer token" {
+		t.Errorf("Unexpected request headers %v", header)
+	}
+	if len(request.ResourceSpans) != 1 || len(request.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("Unexpected request %+v", request)
+	}
+	if service := request.ResourceSpans[0].Resource.Attributes[0]; service.Key != "service.name" || *service.Value.StringValue != "shop" {
+		t.Errorf("Expected service.name shop, got %+v", service)
+	}
+
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 3 {
+		t.Fatalf("Expected a root span and two step spans, got %d", len(spans))
+	}
+	root, view, buy := spans[0], spans[1], spans[2]
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	if root.Name != "checkout" || root.ParentSpanID != "" || len(root.TraceID) != 32 || len(root.SpanID) != 16 {
+		t.Errorf("Unexpected root span %+v", root)
+	}
+	if root.StartTimeUnixNano != unixNano(start) || root.EndTimeUnixNano != unixNano(start.Add(5*time.Second)) {
+		t.Errorf("Expected the root span to cover the conversion, got %s to %s", root.StartTimeUnixNano, root.EndTimeUnixNano)
+	}
+	for _, step := range []span{view, buy} {
+		if step.TraceID != root.TraceID || step.ParentSpanID != root.SpanID {
+			t.Errorf("Expected step span %s to be a child of the root span", step.Name)
+		}
+	}
+	if view.Name != "view" || view.StartTimeUnixNano != view.EndTimeUnixNano {
+		t.Errorf("Expected an instant first step span, got %+v", view)
+	}
+	if buy.Name != "buy" || buy.StartTimeUnixNano != unixNano(start) || buy.EndTimeUnixNano != unixNano(start.Add(5*time.Second)) {
+		t.Errorf("Expected the buy span to cover the time from view, got %+v", buy)
+	}
+	if index := buy.Attributes[1]; index.Key != "loglion.step_index" || *index.Value.IntValue != "2" {
+		t.Errorf("Expected step index 2, got %+v", index)
+	}
+}
+
+func TestExportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder()
+	analyzer.NewFunnelAnalyzerWithHooks(checkoutConfig(), recorder.Hooks("checkout")).AnalyzeFunnel(checkoutEntries(), 0)
+
+	exporter, err := NewExporter(server.URL, nil, "")
+	if err != nil {
+		t.Fatalf("NewExporter() unexpected error: %v", err)
+	}
+	exported, err := exporter.Export(context.Background(), recorder.Traces())
+	if err == nil || !strings.Contains(err.Error(), "429") || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("Expected an error with the response status and body, got %v", err)
+	}
+	if exported != 0 {
+		t.Errorf("Expected no exported traces, got %d", exported)
+	}
+}
+
+func TestSpanTime(t *testing.T) {
+	logcat := time.Date(0, 5, 1, 10, 0, 0, 0, time.UTC)
+	if got := spanTime(logcat); got.Year() != time.Now().Year() || got.Month() != time.May || got.Hour() != 10 {
+		t.Errorf("Expected a logcat timestamp in the current year, got %v", got)
+	}
+
+	dated := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	if got := spanTime(dated); !got.Equal(dated) {
+		t.Errorf("Expected a timestamp with a year to be kept, got %v", got)
+	}
+}
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+)
+
+// asciiReplacer spells out the status icons of text output as PASS/FAIL
+// wording and replaces the symbols of charts and tables
+var asciiReplacer = strings.NewReplacer(
+	"✅", "PASS",
+	"❌", "FAIL",
+	"⚠️", "WARN",
+	"⛔", "STOP",
+	"→", "->",
+	"↳", "->",
+	"│", "|",
+	"─", "-",
+	"┌", "+", "┬", "+", "┐", "+",
+	"├", "+", "┼", "+", "┤", "+",
+	"└", "+", "┴", "+", "┘", "+",
+	"█", "#",
+	"Δ", "Delta",
+	"…", "...",
+	"—", "-",
+)
+
+// ASCII returns text output for terminals that mangle emoji and symbols:
+// status icons become PASS, FAIL and WARN, chart and table symbols become
+// ASCII, and decorative emoji are dropped. Other text, such as event names,
+// is kept as it is.
+func ASCII(s string) string {
+	s = asciiReplacer.Replace(s)
+
+	var out strings.Builder
+	out.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if !isEmoji(r) {
+			out.WriteRune(r)
+			continue
+		}
+		// Drop the variation selector and the space after the emoji too
+		if strings.HasPrefix(s[i:], "️") {
+			i += len("️")
+		}
+		if strings.HasPrefix(s[i:], " ") {
+			i++
+		}
+	}
+	return out.String()
+}
+
+// isEmoji reports whether r is a pictograph of the emoji blocks
+func isEmoji(r rune) bool {
+	return r >= 0x1F000 && r <= 0x1FAFF || r >= 0x2600 && r <= 0x27BF
+}
+
+// asciiFormatter is a text formatter whose output is converted with ASCII
+type asciiFormatter struct {
+	Formatter
+}
+
+func toASCII(s string, err error) (string, error) {
+	return ASCII(s), err
+}
+
+func (f asciiFormatter) FormatFunnel(result *analyzer.FunnelResult) (string, error) {
+	return toASCII(f.Formatter.FormatFunnel(result))
+}
+
+func (f asciiFormatter) FormatFunnels(results []*analyzer.FunnelResult) (string, error) {
+	return toASCII(f.Formatter.FormatFunnels(results))
+}
+
+func (f asciiFormatter) FormatCount(result *analyzer.CountResult) (string, error) {
+	return toASCII(f.Formatter.FormatCount(result))
+}
+
+func (f asciiFormatter) FormatSessions(result *analyzer.SessionResult) (string, error) {
+	return toASCII(f.Formatter.FormatSessions(result))
+}
+
+func (f asciiFormatter) FormatTop(result *analyzer.TopResult) (string, error) {
+	return toASCII(f.Formatter.FormatTop(result))
+}
+
+func (f asciiFormatter) FormatTimeline(result *analyzer.TimelineResult) (string, error) {
+	return toASCII(f.Formatter.FormatTimeline(result))
+}
+
+func (f asciiFormatter) FormatOverview(result *analyzer.OverviewResult) (string, error) {
+	return toASCII(f.Formatter.FormatOverview(result))
+}
+
+func (f asciiFormatter) FormatExtract(result *analyzer.ExtractResult) (string, error) {
+	return toASCII(f.Formatter.FormatExtract(result))
+}
+
+func (f asciiFormatter) FormatDiff(report *analyzer.DiffReport) (string, error) {
+	return toASCII(f.Formatter.FormatDiff(report))
+}
+
+func (f asciiFormatter) FormatLabeled(report *analyzer.LabeledReport) (string, error) {
+	return toASCII(f.Formatter.FormatLabeled(report))
+}
+
+func (f asciiFormatter) FormatCooccurrence(result *analyzer.CooccurrenceResult) (string, error) {
+	return toASCII(f.Formatter.FormatCooccurrence(result))
+}
+
+func (f asciiFormatter) FormatDiscover(result *analyzer.DiscoverResult) (string, error) {
+	return toASCII(f.Formatter.FormatDiscover(result))
+}
+
+func (f asciiFormatter) FormatGaps(result *analyzer.GapResult) (string, error) {
+	return toASCII(f.Formatter.FormatGaps(result))
+}
+
+func (f asciiFormatter) FormatAnomalies(result *analyzer.AnomalyResult) (string, error) {
+	return toASCII(f.Formatter.FormatAnomalies(result))
+}
+
+func (f asciiFormatter) FormatRetention(result *analyzer.RetentionResult) (string, error) {
+	return toASCII(f.Formatter.FormatRetention(result))
+}
+
+func (f asciiFormatter) FormatAudit(result *analyzer.AuditResult) (string, error) {
+	return toASCII(f.Formatter.FormatAudit(result))
+}
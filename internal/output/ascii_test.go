@@ -0,0 +1,51 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestASCII(t *testing.T) {
+	tests := map[string]string{
+		"✅ Funnel Analysis Complete":        "PASS Funnel Analysis Complete",
+		"❌ Funnel not completed":            "FAIL Funnel not completed",
+		"⚠️  2 step(s) never match":         "WARN  2 step(s) never match",
+		"📊 Event Count Analysis Complete":   "Event Count Analysis Complete",
+		"🏷️ Labeled Report":                 "Labeled Report",
+		"View → Cart: 50.0%":                "View -> Cart: 50.0%",
+		"│ ████ 40%":                        "| #### 40%",
+		"checkout_started_with_a_long_nam…": "checkout_started_with_a_long_nam...",
+		"café: 3 matches":                   "café: 3 matches",
+	}
+
+	for input, want := range tests {
+		if got := ASCII(input); got != want {
+			t.Errorf("ASCII(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestASCIIFormatter(t *testing.T) {
+	formatter := NewFormatterWithOptions(TextFormat, Options{ASCII: true})
+	if _, ok := formatter.(asciiFormatter); !ok {
+		t.Fatalf("Expected an ASCII text formatter, got %T", formatter)
+	}
+
+	output, err := formatter.FormatFunnel(testFunnelResult())
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	for _, r := range output {
+		if r >= utf8.RuneSelf {
+			t.Fatalf("Expected ASCII output, found %q in:\n%s", r, output)
+		}
+	}
+	if !strings.Contains(output, "FAIL Funnel Analysis Complete") {
+		t.Errorf("Expected FAIL wording, got:\n%s", output)
+	}
+
+	if _, ok := NewFormatterWithOptions(JSONFormat, Options{ASCII: true}).(*JSONFormatter); !ok {
+		t.Error("Expected JSON output to ignore the ASCII option")
+	}
+}
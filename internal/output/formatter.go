@@ -3,9 +3,12 @@ package output
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/parfenovvs/loglion/internal/analyzer"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/parfenovvs/loglion/internal/analyzer"
 	"github.com/sirupsen/logrus"
 )
 
@@ -14,27 +17,70 @@ type OutputFormat string
 const (
 	TextFormat OutputFormat = "text"
 	JSONFormat OutputFormat = "json"
+	HTMLFormat OutputFormat = "html"
+	// TemplateFormat renders results through the template file of the options
+	TemplateFormat OutputFormat = "template"
+	// SankeyFormat renders funnels as nodes and links for Sankey diagrams
+	SankeyFormat OutputFormat = "sankey"
 )
 
 type Formatter interface {
 	FormatFunnel(result *analyzer.FunnelResult) (string, error)
+	FormatFunnels(results []*analyzer.FunnelResult) (string, error)
 	FormatCount(result *analyzer.CountResult) (string, error)
+	FormatSessions(result *analyzer.SessionResult) (string, error)
+	FormatTop(result *analyzer.TopResult) (string, error)
+	FormatTimeline(result *analyzer.TimelineResult) (string, error)
+	FormatOverview(result *analyzer.OverviewResult) (string, error)
+	FormatExtract(result *analyzer.ExtractResult) (string, error)
+	FormatDiff(report *analyzer.DiffReport) (string, error)
+	FormatLabeled(report *analyzer.LabeledReport) (string, error)
+	FormatCooccurrence(result *analyzer.CooccurrenceResult) (string, error)
+	FormatDiscover(result *analyzer.DiscoverResult) (string, error)
+	FormatGaps(result *analyzer.GapResult) (string, error)
+	FormatAnomalies(result *analyzer.AnomalyResult) (string, error)
+	FormatRetention(result *analyzer.RetentionResult) (string, error)
+	FormatAudit(result *analyzer.AuditResult) (string, error)
 }
 
 func NewFormatter(format OutputFormat) Formatter {
-	logrus.WithField("format", format).Debug("Creating new output formatter")
+	return NewFormatterWithOptions(format, Options{})
+}
+
+func NewFormatterWithOptions(format OutputFormat, options Options) Formatter {
+	logrus.WithFields(logrus.Fields{
+		"format":         format,
+		"only":           options.Only,
+		"hide":           options.Hide,
+		"max_name_width": options.MaxNameWidth,
+		"ascii":          options.ASCII,
+	}).Debug("Creating new output formatter")
 
 	switch format {
 	case JSONFormat:
 		logrus.Debug("Using JSON formatter")
-		return &JSONFormatter{}
+		return &JSONFormatter{options: options}
+	case HTMLFormat:
+		logrus.Debug("Using HTML formatter")
+		return &HTMLFormatter{options: options}
+	case TemplateFormat:
+		logrus.Debug("Using template formatter")
+		return &TemplateFormatter{options: options}
+	case SankeyFormat:
+		logrus.Debug("Using Sankey formatter")
+		return &SankeyFormatter{options: options}
 	default:
 		logrus.Debug("Using text formatter (default)")
-		return &TextFormatter{}
+		if options.ASCII {
+			return asciiFormatter{&TextFormatter{options: options}}
+		}
+		return &TextFormatter{options: options}
 	}
 }
 
-type TextFormatter struct{}
+type TextFormatter struct {
+	options Options
+}
 
 func (f *TextFormatter) FormatFunnel(result *analyzer.FunnelResult) (string, error) {
 	logrus.WithFields(logrus.Fields{
@@ -53,38 +99,90 @@ func (f *TextFormatter) FormatFunnel(result *analyzer.FunnelResult) (string, err
 		return output.String(), nil
 	}
 
-	// Choose status icon
-	statusIcon := "✅"
-	if !result.FunnelCompleted {
-		statusIcon = "❌"
+	// The time to the last step is the total conversion time, even if
+	// zero-count steps are hidden
+	lastStep := ""
+	if len(result.Steps) > 0 {
+		lastStep = result.Steps[len(result.Steps)-1].Name
 	}
-	logrus.WithField("status_icon", statusIcon).Debug("Selected status icon")
+	result = f.options.filterFunnel(result)
 
-	output.WriteString(fmt.Sprintf("%s Funnel Analysis Complete\n\n", statusIcon))
-	output.WriteString(fmt.Sprintf("Funnel: %s\n", result.FunnelName))
-	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if f.options.showSection(SectionSummary) {
+		// Choose status icon
+		statusIcon := "✅"
+		if !result.FunnelCompleted {
+			statusIcon = "❌"
+		}
+		logrus.WithField("status_icon", statusIcon).Debug("Selected status icon")
 
-	if result.FunnelCompleted {
-		output.WriteString("Funnel Completed: Yes\n\n")
-	} else {
-		output.WriteString("Funnel Completed: No\n\n")
+		output.WriteString(fmt.Sprintf("%s Funnel Analysis Complete\n\n", statusIcon))
+		output.WriteString(fmt.Sprintf("Funnel: %s\n", result.FunnelName))
+		output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+		if result.Partial {
+			output.WriteString(partialResultNote)
+		}
+
+		if result.FunnelCompleted {
+			output.WriteString("Funnel Completed: Yes\n")
+		} else {
+			output.WriteString("Funnel Completed: No\n")
+		}
+		output.WriteString(fmt.Sprintf("Entered Funnel: %d\n", result.EnteredFunnel))
+		output.WriteString(fmt.Sprintf("Conversions Found: %d\n", result.ConversionsFound))
+
+		if result.Groups != nil {
+			output.WriteString(fmt.Sprintf("Grouped By: %s (%d groups, %d completed, %d dropped)\n",
+				result.Groups.GroupBy, result.Groups.TotalGroups, result.Groups.CompletedGroups,
+				result.Groups.TotalGroups-result.Groups.CompletedGroups))
+			if result.Groups.UngroupedEntries > 0 {
+				output.WriteString(fmt.Sprintf("Entries Without %s: %d\n", result.Groups.GroupBy, result.Groups.UngroupedEntries))
+			}
+		}
 	}
 
-	logrus.Debug("Formatting step breakdown section")
-	output.WriteString("Step Breakdown:\n")
-	for i, step := range result.Steps {
-		logrus.WithFields(logrus.Fields{
-			"step_index":  i + 1,
-			"step_name":   step.Name,
-			"event_count": step.EventCount,
-			"percentage":  step.Percentage,
-		}).Debug("Formatting step result")
+	// Step counts of grouped funnels are numbers of groups
+	unit := "events"
+	if result.Groups != nil {
+		unit = "groups"
+	}
+
+	if f.options.showSection(SectionSteps) {
+		logrus.Debug("Formatting step breakdown section")
+		output.WriteString("\nStep Breakdown:\n")
+		if f.options.tables() {
+			output.WriteString(f.renderStepTable(result, unit))
+		} else {
+			for i, step := range result.Steps {
+				logrus.WithFields(logrus.Fields{
+					"step_index":  i + 1,
+					"step_name":   step.Name,
+					"event_count": step.EventCount,
+					"percentage":  step.Percentage,
+				}).Debug("Formatting step result")
+
+				assertionFailed := ""
+				if step.AssertionFailed {
+					assertionFailed = " ❌ assertion failed"
+				}
+				output.WriteString(fmt.Sprintf("%d. %s: %d %s (%.1f%%)%s\n",
+					i+1, f.options.truncateName(step.Name), step.EventCount, unit, step.Percentage, assertionFailed))
+				for _, branch := range step.Branches {
+					output.WriteString(fmt.Sprintf("   ↳ %s: %d %s (%.1f%%)\n",
+						f.options.truncateName(branch.Name), branch.EventCount, unit, branch.Percentage))
+				}
+				output.WriteString(renderStepSamples(step))
+				output.WriteString(renderNearMisses(step))
+			}
+		}
+	}
 
-		output.WriteString(fmt.Sprintf("%d. %s: %d events (%.1f%%)\n",
-			i+1, step.Name, step.EventCount, step.Percentage))
+	if len(result.Steps) > 0 && f.options.showSection(SectionChart) {
+		logrus.Debug("Formatting funnel chart section")
+		output.WriteString("\nFunnel Chart:\n")
+		output.WriteString(renderFunnelChart(result.Steps, f.options))
 	}
 
-	if len(result.DropOffs) > 0 {
+	if len(result.DropOffs) > 0 && f.options.showSection(SectionDropOffs) {
 		logrus.Debug("Formatting drop-off analysis section")
 		output.WriteString("\nDrop-off Analysis:\n")
 		for _, dropOff := range result.DropOffs {
@@ -95,16 +193,167 @@ func (f *TextFormatter) FormatFunnel(result *analyzer.FunnelResult) (string, err
 				"drop_off_rate": dropOff.DropOffRate,
 			}).Debug("Formatting drop-off result")
 
-			output.WriteString(fmt.Sprintf("- %s → %s: %d events lost (%.1f%% drop-off)\n",
-				dropOff.From, dropOff.To, dropOff.EventsLost, dropOff.DropOffRate))
+			output.WriteString(fmt.Sprintf("- %s → %s: %d %s lost (%.1f%% drop-off)\n",
+				f.options.truncateName(dropOff.From), f.options.truncateName(dropOff.To), dropOff.EventsLost, unit, dropOff.DropOffRate))
 		}
 	}
 
-	resultStr := output.String()
+	if len(result.Anomalies) > 0 && f.options.showSection(SectionAnomalies) {
+		logrus.Debug("Formatting anomalies section")
+		output.WriteString("\nAnomalies:\n")
+		for _, anomaly := range result.Anomalies {
+			output.WriteString(fmt.Sprintf("- ⚠️ %s: fired %d times in one attempt (limit %d, entry %d)\n",
+				f.options.truncateName(anomaly.Step), anomaly.Occurrences, anomaly.Limit, anomaly.EntryIndex))
+		}
+	}
+
+	if len(result.Exclusions) > 0 && f.options.showSection(SectionExclusions) {
+		logrus.Debug("Formatting exclusions section")
+		output.WriteString(fmt.Sprintf("\nExclusions: %d attempts aborted\n", result.AbortedAttempts()))
+		for _, exclusion := range result.Exclusions {
+			output.WriteString(fmt.Sprintf("- ⛔ %s: %d attempts aborted by /%s/\n",
+				f.options.truncateName(exclusion.Step), exclusion.AbortedAttempts, f.options.truncateName(exclusion.Pattern)))
+		}
+	}
+
+	if len(result.Timings) > 0 && f.options.showSection(SectionTimings) {
+		logrus.Debug("Formatting time-to-convert section")
+		output.WriteString("\nTime to Convert (from first step):\n")
+		for _, timing := range result.Timings {
+			total := ""
+			if timing.Step == lastStep {
+				total = " (total)"
+			}
+			output.WriteString(fmt.Sprintf("- %s%s: n=%d min=%s median=%s p95=%s max=%s\n",
+				f.options.truncateName(timing.Step), total, timing.Samples,
+				formatSeconds(timing.MinSeconds), formatSeconds(timing.MedianSeconds),
+				formatSeconds(timing.P95Seconds), formatSeconds(timing.MaxSeconds)))
+		}
+	}
+
+	if len(result.Conversions) > 0 && f.options.showSection(SectionConversions) {
+		logrus.Debug("Formatting conversions section")
+		output.WriteString("\nConversions:\n")
+		output.WriteString(renderConversions(result.Conversions, f.options))
+	}
+
+	if len(result.Assertions) > 0 && f.options.showSection(SectionAssertions) {
+		logrus.Debug("Formatting assertions section")
+		failed := len(result.FailedAssertions())
+		output.WriteString(fmt.Sprintf("\nAssertions: %d passed, %d failed\n", len(result.Assertions)-failed, failed))
+		for _, assertion := range result.Assertions {
+			icon := "✅"
+			if !assertion.Passed {
+				icon = "❌"
+			}
+			output.WriteString(fmt.Sprintf("- %s %s\n", icon, assertion))
+		}
+	}
+
+	resultStr := strings.TrimLeft(output.String(), "\n")
 	logrus.WithField("output_length", len(resultStr)).Debug("Text formatting completed")
 	return resultStr, nil
 }
 
+func (f *TextFormatter) FormatFunnels(results []*analyzer.FunnelResult) (string, error) {
+	logrus.WithField("funnel_count", len(results)).Debug("Formatting multi-funnel report as text")
+
+	var output strings.Builder
+
+	if f.options.showSection(SectionSummary) {
+		completed := 0
+		for _, result := range results {
+			if result.FunnelCompleted {
+				completed++
+			}
+		}
+
+		output.WriteString("📋 Multi-Funnel Report\n\n")
+		output.WriteString(fmt.Sprintf("Funnels Analyzed: %d\n", len(results)))
+		output.WriteString(fmt.Sprintf("Funnels Completed: %d\n", completed))
+	}
+
+	for _, result := range results {
+		funnelOutput, err := f.FormatFunnel(result)
+		if err != nil {
+			return "", err
+		}
+		if output.Len() > 0 {
+			output.WriteString("\n" + strings.Repeat("─", 40) + "\n\n")
+		}
+		output.WriteString(funnelOutput)
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text multi-funnel formatting completed")
+	return resultStr, nil
+}
+
+func (f *TextFormatter) FormatLabeled(report *analyzer.LabeledReport) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"label_count":  len(report.Labels),
+		"funnel_count": len(report.Comparisons),
+	}).Debug("Formatting labeled report as text")
+
+	var output strings.Builder
+
+	if f.options.showSection(SectionSummary) {
+		output.WriteString("🏷️  Comparison by Label\n")
+		for _, comparison := range report.Comparisons {
+			output.WriteString(fmt.Sprintf("\n%s:\n", comparison.FunnelName))
+			output.WriteString(f.renderComparison(comparison))
+		}
+	}
+
+	for _, labeled := range report.Labels {
+		var labelOutput string
+		var err error
+		if len(labeled.Funnels) == 1 {
+			labelOutput, err = f.FormatFunnel(labeled.Funnels[0])
+		} else {
+			labelOutput, err = f.FormatFunnels(labeled.Funnels)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if output.Len() > 0 {
+			output.WriteString("\n" + strings.Repeat("─", 40) + "\n\n")
+		}
+		output.WriteString(fmt.Sprintf("Label: %s\n\n", labeled.Label))
+		output.WriteString(labelOutput)
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text labeled report formatting completed")
+	return resultStr, nil
+}
+
+// renderComparison renders the steps of a funnel as a table with one column
+// per label
+func (f *TextFormatter) renderComparison(comparison analyzer.FunnelComparison) string {
+	var output strings.Builder
+	table := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, "Step\t"+strings.Join(comparison.Labels, "\t"))
+	for i, step := range comparison.Steps {
+		row := []string{fmt.Sprintf("%d. %s", i+1, f.options.truncateName(step.Name))}
+		for j, count := range step.EventCounts {
+			row = append(row, fmt.Sprintf("%d (%.1f%%)", count, step.Percentages[j]))
+		}
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+
+	row := []string{"Completed"}
+	for _, completed := range comparison.Completed {
+		row = append(row, yesNo(completed))
+	}
+	fmt.Fprintln(table, strings.Join(row, "\t"))
+
+	table.Flush()
+	return output.String()
+}
+
 func (f *TextFormatter) FormatCount(result *analyzer.CountResult) (string, error) {
 	logrus.WithFields(logrus.Fields{
 		"total_events":   result.TotalEventsAnalyzed,
@@ -119,71 +368,1528 @@ func (f *TextFormatter) FormatCount(result *analyzer.CountResult) (string, error
 		return output.String(), nil
 	}
 
-	output.WriteString("📊 Event Count Analysis Complete\n\n")
-	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n\n", result.TotalEventsAnalyzed))
+	totalEvents := result.TotalEventsAnalyzed
+	result = f.options.filterCount(result)
+
+	if f.options.showSection(SectionSummary) {
+		output.WriteString("📊 Event Count Analysis Complete\n\n")
+		output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", totalEvents))
+		if result.Partial {
+			output.WriteString(partialResultNote)
+		}
+		if result.FirstTimestamp != nil {
+			output.WriteString(fmt.Sprintf("First Timestamp: %s\n", formatTimestamp(*result.FirstTimestamp)))
+			output.WriteString(fmt.Sprintf("Last Timestamp: %s\n", formatTimestamp(*result.LastTimestamp)))
+			output.WriteString(fmt.Sprintf("Duration: %s\n", formatSeconds(result.DurationSeconds)))
+			if result.DurationSeconds > 0 {
+				output.WriteString(fmt.Sprintf("Events per Minute: %.2f\n", result.EventsPerMinute))
+			}
+		}
+	}
 
-	if len(result.PatternCounts) > 0 {
+	if len(result.PatternCounts) > 0 && f.options.showSection(SectionCounts) {
 		logrus.Debug("Formatting pattern counts section")
-		output.WriteString("Pattern Counts:\n")
+		output.WriteString("\nPattern Counts:\n")
 		totalMatches := 0
-		for i, patternCount := range result.PatternCounts {
-			logrus.WithFields(logrus.Fields{
-				"pattern_index": i + 1,
-				"pattern_name":  patternCount.Pattern,
-				"count":         patternCount.Count,
-			}).Debug("Formatting pattern count result")
-
-			percentage := 0.0
-			if result.TotalEventsAnalyzed > 0 {
-				percentage = float64(patternCount.Count) / float64(result.TotalEventsAnalyzed) * 100.0
+		if f.options.tables() {
+			output.WriteString(f.renderPatternTable(result, totalEvents))
+			for _, patternCount := range result.PatternCounts {
+				totalMatches += patternCount.Count
 			}
+		} else {
+			for i, patternCount := range result.PatternCounts {
+				logrus.WithFields(logrus.Fields{
+					"pattern_index": i + 1,
+					"pattern_name":  patternCount.Pattern,
+					"count":         patternCount.Count,
+				}).Debug("Formatting pattern count result")
 
-			output.WriteString(fmt.Sprintf("%d. %s: %d matches (%.1f%%)\n",
-				i+1, patternCount.Pattern, patternCount.Count, percentage))
-			totalMatches += patternCount.Count
+				percentage := 0.0
+				if totalEvents > 0 {
+					percentage = float64(patternCount.Count) / float64(totalEvents) * 100.0
+				}
+
+				output.WriteString(fmt.Sprintf("%d. %s: %d matches (%.1f%%)",
+					i+1, f.options.truncateName(patternCount.Pattern), patternCount.Count, percentage))
+				if result.DurationSeconds > 0 {
+					output.WriteString(fmt.Sprintf(", %.2f per minute", patternCount.PerMinute))
+				}
+				output.WriteString("\n")
+				if patternCount.Values != nil {
+					output.WriteString(renderValueStats("Values", patternCount.Values))
+				}
+				totalMatches += patternCount.Count
+			}
 		}
 
 		output.WriteString(fmt.Sprintf("\nTotal Matches: %d\n", totalMatches))
 	}
 
-	resultStr := output.String()
+	if result.OverlappingEntries > 0 && f.options.showSection(SectionOverlaps) {
+		logrus.WithField("overlapping_entries", result.OverlappingEntries).Debug("Formatting overlaps section")
+		output.WriteString(fmt.Sprintf("\n⚠️  Overlapping Patterns: %d entries matched more than one pattern, percentages overlap\n",
+			result.OverlappingEntries))
+		for _, overlap := range result.Overlaps {
+			patterns := make([]string, len(overlap.Patterns))
+			for i, pattern := range overlap.Patterns {
+				patterns[i] = f.options.truncateName(pattern)
+			}
+			output.WriteString(fmt.Sprintf("- %s: %d shared matches\n", strings.Join(patterns, " & "), overlap.Count))
+		}
+	}
+
+	if result.Groups != nil && len(result.PatternCounts) > 0 && f.options.showSection(SectionGroups) {
+		logrus.WithField("group_count", len(result.Groups.Groups)).Debug("Formatting groups section")
+		output.WriteString(f.renderCountGroups(result))
+	}
+
+	if result.Sessions != nil && f.options.showSection(SectionSessions) {
+		logrus.WithField("session_count", result.Sessions.SessionCount).Debug("Formatting sessions section")
+		output.WriteString(f.renderCountSessions(result.Sessions))
+	}
+
+	if result.Distinct != nil && f.options.showSection(SectionDistinct) {
+		logrus.WithField("property", result.Distinct.Property).Debug("Formatting distinct section")
+		output.WriteString(f.renderCountDistinct(result.Distinct))
+	}
+
+	if result.Aggregate != nil && f.options.showSection(SectionAggregate) {
+		logrus.WithField("property", result.Aggregate.Property).Debug("Formatting aggregate section")
+		output.WriteString(f.renderCountAggregate(result.Aggregate))
+	}
+
+	resultStr := strings.TrimLeft(output.String(), "\n")
 	logrus.WithField("output_length", len(resultStr)).Debug("Text count formatting completed")
 	return resultStr, nil
 }
 
-type JSONFormatter struct{}
+func (f *TextFormatter) FormatSessions(result *analyzer.SessionResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"total_events":  result.TotalEventsAnalyzed,
+		"session_count": result.SessionCount,
+	}).Debug("Formatting session result as text")
+
+	var output strings.Builder
 
-func (f *JSONFormatter) FormatFunnel(result *analyzer.FunnelResult) (string, error) {
+	if result.TotalEventsAnalyzed == 0 {
+		logrus.Debug("No events found, generating empty result message")
+		output.WriteString("❌ No events found\n")
+		return output.String(), nil
+	}
+
+	output.WriteString("🕒 Session Analysis Complete\n\n")
+	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if result.Partial {
+		output.WriteString(partialResultNote)
+	}
+
+	if result.SessionKey != "" {
+		output.WriteString(fmt.Sprintf("Session Key: %s\n", result.SessionKey))
+	} else {
+		output.WriteString("Session Key: none (whole log)\n")
+	}
+	if result.IdleTimeoutSeconds > 0 {
+		output.WriteString(fmt.Sprintf("Idle Timeout: %s\n", formatSeconds(result.IdleTimeoutSeconds)))
+	} else {
+		output.WriteString("Idle Timeout: disabled\n")
+	}
+
+	output.WriteString(fmt.Sprintf("Sessions: %d\n", result.SessionCount))
+	output.WriteString(fmt.Sprintf("Idle Timeout Splits: %d\n", result.IdleSplits))
+	if result.UnkeyedEntries > 0 {
+		output.WriteString(fmt.Sprintf("Entries Without %s: %d\n", result.SessionKey, result.UnkeyedEntries))
+	}
+
+	if result.Durations != nil {
+		output.WriteString("\nSession Duration (seconds):\n")
+		output.WriteString(renderValueStats("Duration", result.Durations))
+	}
+
+	if result.EventsPerSession != nil {
+		output.WriteString("\nEvents per Session:\n")
+		output.WriteString(renderValueStats("Events", result.EventsPerSession))
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text session formatting completed")
+	return resultStr, nil
+}
+
+// partialResultNote marks results of an analysis that was cancelled early
+const partialResultNote = "⚠️  Partial result: analysis was cancelled before all events were analyzed\n"
+
+// funnelChartWidth is the width of the widest (100%) bar in the funnel chart
+const funnelChartWidth = 40
+
+// renderFunnelChart draws one centered bar per step whose width is
+// proportional to the step's percentage, so the output narrows like a funnel
+func (f *TextFormatter) FormatTop(result *analyzer.TopResult) (string, error) {
 	logrus.WithFields(logrus.Fields{
-		"funnel_name":      result.FunnelName,
-		"total_events":     result.TotalEventsAnalyzed,
-		"funnel_completed": result.FunnelCompleted,
-		"steps_count":      len(result.Steps),
-		"dropoffs_count":   len(result.DropOffs),
-	}).Debug("Formatting funnel result as JSON")
+		"total_events":    result.TotalEventsAnalyzed,
+		"distinct_events": result.DistinctEvents,
+	}).Debug("Formatting top events result as text")
 
-	jsonData, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		logrus.WithError(err).Error("Failed to marshal funnel result to JSON")
-		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	var output strings.Builder
+
+	if result.TotalEventsAnalyzed == 0 {
+		logrus.Debug("No events found, generating empty result message")
+		output.WriteString("❌ No events found\n")
+		return output.String(), nil
 	}
 
-	logrus.WithField("json_length", len(jsonData)).Debug("JSON formatting completed")
-	return string(jsonData), nil
+	output.WriteString("🔝 Top Events\n\n")
+	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if result.Partial {
+		output.WriteString(partialResultNote)
+	}
+	output.WriteString(fmt.Sprintf("Distinct Events: %d\n\n", result.DistinctEvents))
+
+	for i, event := range result.Events {
+		output.WriteString(fmt.Sprintf("%d. %s: %d (%.1f%%)\n",
+			i+1, f.options.truncateName(event.Event), event.Count, event.Percentage))
+	}
+	if hidden := result.DistinctEvents - len(result.Events); hidden > 0 {
+		output.WriteString(fmt.Sprintf("... and %d more\n", hidden))
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text top events formatting completed")
+	return resultStr, nil
 }
 
-func (f *JSONFormatter) FormatCount(result *analyzer.CountResult) (string, error) {
+func (f *TextFormatter) FormatCooccurrence(result *analyzer.CooccurrenceResult) (string, error) {
 	logrus.WithFields(logrus.Fields{
-		"total_events":   result.TotalEventsAnalyzed,
-		"patterns_count": len(result.PatternCounts),
-	}).Debug("Formatting count result as JSON")
+		"total_events": result.TotalEventsAnalyzed,
+		"units":        result.Units,
+	}).Debug("Formatting co-occurrence result as text")
 
-	jsonData, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		logrus.WithError(err).Error("Failed to marshal count result to JSON")
-		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	var output strings.Builder
+
+	if result.TotalEventsAnalyzed == 0 {
+		logrus.Debug("No events found, generating empty result message")
+		output.WriteString("❌ No events found\n")
+		return output.String(), nil
 	}
 
-	logrus.WithField("json_length", len(jsonData)).Debug("JSON count formatting completed")
+	output.WriteString("🔗 Co-occurrence Analysis Complete\n\n")
+	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if result.Partial {
+		output.WriteString(partialResultNote)
+	}
+
+	units := "sessions"
+	if result.Unit == analyzer.CooccurrenceWindow {
+		units = "windows"
+		output.WriteString(fmt.Sprintf("Windows of %s: %d\n", formatSeconds(result.WindowSeconds), result.Units))
+		if result.SkippedEntries > 0 {
+			output.WriteString(fmt.Sprintf("Entries Without Timestamp: %d\n", result.SkippedEntries))
+		}
+	} else {
+		if result.SessionKey != "" {
+			output.WriteString(fmt.Sprintf("Sessions by %s: %d", result.SessionKey, result.Units))
+		} else {
+			output.WriteString(fmt.Sprintf("Sessions: %d", result.Units))
+		}
+		if result.IdleTimeoutSeconds > 0 {
+			output.WriteString(fmt.Sprintf(" (idle timeout %s)", formatSeconds(result.IdleTimeoutSeconds)))
+		}
+		output.WriteString("\n")
+		if result.SkippedEntries > 0 {
+			output.WriteString(fmt.Sprintf("Entries Without %s: %d\n", result.SessionKey, result.SkippedEntries))
+		}
+	}
+
+	output.WriteString("\nPatterns:\n")
+	names := make([]string, len(result.Patterns))
+	for i, pattern := range result.Patterns {
+		names[i] = f.options.truncateName(pattern.Pattern)
+		output.WriteString(fmt.Sprintf("%d. %s: %d %s (%.1f%%)\n", i+1, names[i], pattern.Units, units, pattern.Percentage))
+	}
+
+	output.WriteString(fmt.Sprintf("\nCo-occurrence (%s with both):\n", units))
+	output.WriteString(renderMatrix(names, func(i, j int) string { return strconv.Itoa(result.Matrix[i][j]) }))
+
+	output.WriteString("\nConditional Probability P(column | row):\n")
+	output.WriteString(renderMatrix(names, func(i, j int) string { return fmt.Sprintf("%.1f%%", result.Conditional[i][j]) }))
+
+	output.WriteString(fmt.Sprintf("\nRow Before Column (%s):\n", units))
+	output.WriteString(renderMatrix(names, func(i, j int) string {
+		if i == j {
+			return "-"
+		}
+		return strconv.Itoa(result.Before[i][j])
+	}))
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text co-occurrence formatting completed")
+	return resultStr, nil
+}
+
+// renderMatrix renders a square table with a row and a column per name
+func renderMatrix(names []string, cell func(i, j int) string) string {
+	var output strings.Builder
+	table := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "\t"+strings.Join(names, "\t"))
+	for i, name := range names {
+		row := []string{name}
+		for j := range names {
+			row = append(row, cell(i, j))
+		}
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+	table.Flush()
+	return output.String()
+}
+
+func (f *TextFormatter) FormatDiscover(result *analyzer.DiscoverResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"total_events":    result.TotalEventsAnalyzed,
+		"total_sequences": result.TotalSequences,
+	}).Debug("Formatting discover result as text")
+
+	var output strings.Builder
+
+	if result.TotalEventsAnalyzed == 0 {
+		logrus.Debug("No events found, generating empty result message")
+		output.WriteString("❌ No events found\n")
+		return output.String(), nil
+	}
+
+	output.WriteString("🧭 Sequence Discovery Complete\n\n")
+	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if result.Partial {
+		output.WriteString(partialResultNote)
+	}
+	if result.SessionKey != "" {
+		output.WriteString(fmt.Sprintf("Sessions by %s: %d", result.SessionKey, result.SessionCount))
+	} else {
+		output.WriteString(fmt.Sprintf("Sessions: %d", result.SessionCount))
+	}
+	if result.IdleTimeoutSeconds > 0 {
+		output.WriteString(fmt.Sprintf(" (idle timeout %s)", formatSeconds(result.IdleTimeoutSeconds)))
+	}
+	output.WriteString("\n")
+	if result.UnkeyedEntries > 0 {
+		output.WriteString(fmt.Sprintf("Entries Without %s: %d\n", result.SessionKey, result.UnkeyedEntries))
+	}
+	output.WriteString(fmt.Sprintf("Minimum Support: %d sessions\n", result.MinSupport))
+
+	if len(result.Sequences) == 0 {
+		output.WriteString("\nNo frequent event sequences found\n")
+	} else {
+		output.WriteString("\nCandidate Funnels:\n")
+	}
+	for i, sequence := range result.Sequences {
+		events := make([]string, len(sequence.Events))
+		for j, event := range sequence.Events {
+			events[j] = f.options.truncateName(event)
+		}
+		output.WriteString(fmt.Sprintf("%d. %s: %d sessions (%.1f%%)\n",
+			i+1, strings.Join(events, " → "), sequence.Support, sequence.Percentage))
+	}
+	if hidden := result.TotalSequences - len(result.Sequences); hidden > 0 {
+		output.WriteString(fmt.Sprintf("... and %d more\n", hidden))
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text discover formatting completed")
+	return resultStr, nil
+}
+
+func (f *TextFormatter) FormatGaps(result *analyzer.GapResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"matching_events": result.MatchingEvents,
+		"gaps":            len(result.Gaps),
+	}).Debug("Formatting gap result as text")
+
+	var output strings.Builder
+
+	if result.MatchingEvents == 0 {
+		logrus.Debug("No matching events found, generating empty result message")
+		output.WriteString("❌ No matching events found\n")
+		return output.String(), nil
+	}
+
+	output.WriteString("💓 Gap Analysis Complete\n\n")
+	output.WriteString(fmt.Sprintf("Pattern: %s\n", f.options.truncateName(result.Pattern)))
+	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if result.Partial {
+		output.WriteString(partialResultNote)
+	}
+	output.WriteString(fmt.Sprintf("Matching Events: %d\n", result.MatchingEvents))
+	if result.UntimedEvents > 0 {
+		output.WriteString(fmt.Sprintf("Without Timestamp: %d\n", result.UntimedEvents))
+	}
+	if result.MedianIntervalSeconds > 0 {
+		output.WriteString(fmt.Sprintf("Median Interval: %s\n", formatSeconds(result.MedianIntervalSeconds)))
+	}
+	output.WriteString(fmt.Sprintf("Longest Gap: %s\n", formatSeconds(result.LongestGapSeconds)))
+
+	if len(result.Gaps) == 0 {
+		output.WriteString(fmt.Sprintf("\n✅ No gaps longer than %s\n", formatSeconds(result.ThresholdSeconds)))
+	} else {
+		output.WriteString(fmt.Sprintf("\nGaps Longer Than %s:\n", formatSeconds(result.ThresholdSeconds)))
+	}
+	for i, gap := range result.Gaps {
+		output.WriteString(fmt.Sprintf("%d. %s → %s: %s", i+1, formatTimestamp(gap.Start), formatTimestamp(gap.End), formatSeconds(gap.DurationSeconds)))
+		switch gap.Kind {
+		case analyzer.GapLogStart:
+			output.WriteString(" (before the first event)")
+		case analyzer.GapLogEnd:
+			output.WriteString(" (until the end of the log)")
+		}
+		output.WriteString("\n")
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text gap formatting completed")
+	return resultStr, nil
+}
+
+func (f *TextFormatter) FormatAnomalies(result *analyzer.AnomalyResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"windows":   result.Windows,
+		"anomalies": len(result.Anomalies),
+	}).Debug("Formatting anomaly result as text")
+
+	var output strings.Builder
+
+	if result.Windows == 0 {
+		logrus.Debug("No timed events found, generating empty result message")
+		output.WriteString("❌ No events with a timestamp found\n")
+		return output.String(), nil
+	}
+
+	output.WriteString("🚨 Anomaly Detection Complete\n\n")
+	output.WriteString(fmt.Sprintf("Patterns: %s\n", strings.Join(result.Patterns, ", ")))
+	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if result.Partial {
+		output.WriteString(partialResultNote)
+	}
+	output.WriteString(fmt.Sprintf("Windows of %s: %d\n", formatSeconds(result.WindowSeconds), result.Windows))
+	output.WriteString(fmt.Sprintf("Baseline: average of the previous %d windows\n", result.BaselineWindows))
+	output.WriteString(fmt.Sprintf("Factor: %gx\n", result.Factor))
+	if result.UntimedEvents > 0 {
+		output.WriteString(fmt.Sprintf("Without Timestamp: %d\n", result.UntimedEvents))
+	}
+
+	if len(result.Anomalies) == 0 {
+		output.WriteString("\n✅ No anomalies found\n")
+	} else {
+		output.WriteString("\nAnomalies:\n")
+	}
+	for i, anomaly := range result.Anomalies {
+		output.WriteString(fmt.Sprintf("%d. %s → %s %s: %d events", i+1, formatTimestamp(anomaly.Start), formatTimestamp(anomaly.End),
+			f.options.truncateName(anomaly.Pattern), anomaly.Count))
+		if anomaly.Baseline > 0 {
+			output.WriteString(fmt.Sprintf(", %.1fx the baseline of %.2f", anomaly.Magnitude, anomaly.Baseline))
+		} else {
+			output.WriteString(", none before")
+		}
+		output.WriteString(fmt.Sprintf(" (%s)\n", anomaly.Kind))
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text anomaly formatting completed")
+	return resultStr, nil
+}
+
+func (f *TextFormatter) FormatRetention(result *analyzer.RetentionResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"users":   result.Users,
+		"cohorts": len(result.Cohorts),
+	}).Debug("Formatting retention result as text")
+
+	var output strings.Builder
+
+	if result.Users == 0 {
+		logrus.Debug("No users with a cohort event found, generating empty result message")
+		output.WriteString("❌ No users with a cohort event found\n")
+		return output.String(), nil
+	}
+
+	output.WriteString("🔁 Retention Analysis Complete\n\n")
+	output.WriteString(fmt.Sprintf("Cohort Event: %s\n", f.options.truncateName(result.CohortPattern)))
+	output.WriteString(fmt.Sprintf("Return Event: %s\n", f.options.truncateName(result.ReturnPattern)))
+	output.WriteString(fmt.Sprintf("User Key: %s\n", result.UserKey))
+	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if result.Partial {
+		output.WriteString(partialResultNote)
+	}
+	output.WriteString(fmt.Sprintf("Users: %d\n", result.Users))
+	if result.UnkeyedEvents > 0 {
+		output.WriteString(fmt.Sprintf("Without %s: %d\n", result.UserKey, result.UnkeyedEvents))
+	}
+	if result.UntimedEvents > 0 {
+		output.WriteString(fmt.Sprintf("Without Timestamp: %d\n", result.UntimedEvents))
+	}
+
+	label := retentionPeriodLabel(result.PeriodSeconds)
+	if label == "Period" {
+		output.WriteString(fmt.Sprintf("Period: %s\n", formatSeconds(result.PeriodSeconds)))
+	}
+
+	output.WriteString("\nRetention:\n")
+	for _, period := range result.Periods {
+		if period.EligibleUsers == 0 {
+			output.WriteString(fmt.Sprintf("%s %d: not reached by the log\n", label, period.Period))
+			continue
+		}
+		output.WriteString(fmt.Sprintf("%s %d: %d/%d users (%.1f%%)\n", label, period.Period,
+			period.RetainedUsers, period.EligibleUsers, period.Percentage))
+	}
+
+	output.WriteString("\nCohorts:\n")
+	table := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprint(table, "Cohort\tUsers")
+	for _, period := range result.Periods {
+		fmt.Fprintf(table, "\t%s %d", label, period.Period)
+	}
+	fmt.Fprintln(table)
+	for _, cohort := range result.Cohorts {
+		fmt.Fprintf(table, "%s\t%d", formatTimestamp(cohort.Start), cohort.Users)
+		for _, period := range cohort.Periods {
+			if period.EligibleUsers == 0 {
+				fmt.Fprint(table, "\t-")
+				continue
+			}
+			fmt.Fprintf(table, "\t%.1f%%", period.Percentage)
+		}
+		fmt.Fprintln(table)
+	}
+	table.Flush()
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text retention formatting completed")
+	return resultStr, nil
+}
+
+// retentionPeriodLabel names the retention periods of the given length, such
+// as Day for day-N retention
+func retentionPeriodLabel(periodSeconds float64) string {
+	switch time.Duration(periodSeconds * float64(time.Second)) {
+	case 24 * time.Hour:
+		return "Day"
+	case time.Hour:
+		return "Hour"
+	default:
+		return "Period"
+	}
+}
+
+// maxListedViolations caps the schema violations listed in text output
+const maxListedViolations = 20
+
+func (f *TextFormatter) FormatAudit(result *analyzer.AuditResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"audited_events": result.AuditedEvents,
+		"violations":     len(result.Violations),
+	}).Debug("Formatting audit result as text")
+
+	var output strings.Builder
+
+	if result.AuditedEvents == 0 {
+		logrus.Debug("No events of the schema found, generating empty result message")
+		output.WriteString("❌ No events of the schema found\n")
+		return output.String(), nil
+	}
+
+	output.WriteString("🧾 Event Audit Complete\n\n")
+	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if result.Partial {
+		output.WriteString(partialResultNote)
+	}
+	output.WriteString(fmt.Sprintf("Audited Events: %d\n", result.AuditedEvents))
+	output.WriteString(fmt.Sprintf("Invalid Events: %d\n", result.InvalidEvents))
+
+	output.WriteString("\nEvents:\n")
+	for _, event := range result.Events {
+		output.WriteString(fmt.Sprintf("%s: %d occurrences, %d invalid\n",
+			f.options.truncateName(event.Pattern), event.Occurrences, event.InvalidOccurrences))
+	}
+
+	if len(result.Violations) == 0 {
+		output.WriteString("\n✅ All audited events match the schema\n")
+	} else {
+		output.WriteString("\nViolations:\n")
+	}
+	for i, violation := range result.Violations[:min(len(result.Violations), maxListedViolations)] {
+		output.WriteString(fmt.Sprintf("%d. %s %s: %s: %s", i+1, entryLocation(violation.Source, violation.Line, violation.EntryIndex),
+			f.options.truncateName(violation.Event), violation.Property, violation.Message))
+		if violation.Value != nil {
+			value, _ := json.Marshal(violation.Value)
+			output.WriteString(fmt.Sprintf(" (%s)", value))
+		}
+		output.WriteString("\n")
+	}
+	if len(result.Violations) > maxListedViolations {
+		output.WriteString(fmt.Sprintf("... and %d more\n", len(result.Violations)-maxListedViolations))
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text audit formatting completed")
+	return resultStr, nil
+}
+
+// entryLocation refers to an entry by its file and line, or by its position
+// among the analyzed entries when the line is not known
+func entryLocation(source string, line, entryIndex int) string {
+	switch {
+	case line > 0 && source != "":
+		return fmt.Sprintf("%s:%d", source, line)
+	case line > 0:
+		return fmt.Sprintf("line %d", line)
+	default:
+		return fmt.Sprintf("#%d", entryIndex)
+	}
+}
+
+func (f *TextFormatter) FormatTimeline(result *analyzer.TimelineResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"matching_events": result.MatchingEvents,
+		"buckets":         len(result.Buckets),
+	}).Debug("Formatting timeline result as text")
+
+	var output strings.Builder
+
+	if result.MatchingEvents == 0 {
+		logrus.Debug("No matching events found, generating empty result message")
+		output.WriteString("❌ No matching events found\n")
+		return output.String(), nil
+	}
+
+	output.WriteString("📈 Event Timeline\n\n")
+	if len(result.Patterns) > 0 {
+		output.WriteString(fmt.Sprintf("Patterns: %s\n", strings.Join(result.Patterns, ", ")))
+	}
+	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if result.Partial {
+		output.WriteString(partialResultNote)
+	}
+	output.WriteString(fmt.Sprintf("Matching Events: %d\n", result.MatchingEvents))
+	output.WriteString(fmt.Sprintf("Interval: %s\n", formatSeconds(result.IntervalSeconds)))
+	if result.UntimedEvents > 0 {
+		output.WriteString(fmt.Sprintf("Without Timestamp: %d\n", result.UntimedEvents))
+	}
+
+	if len(result.Buckets) > 0 {
+		output.WriteString("\n")
+		output.WriteString(renderTimeline(result.Buckets))
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text timeline formatting completed")
+	return resultStr, nil
+}
+
+func (f *TextFormatter) FormatOverview(result *analyzer.OverviewResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"total_lines":  result.TotalLines,
+		"total_events": result.TotalEventsAnalyzed,
+	}).Debug("Formatting log overview result as text")
+
+	var output strings.Builder
+
+	output.WriteString("📋 Log Overview\n\n")
+	output.WriteString(fmt.Sprintf("Total Lines: %d\n", result.TotalLines))
+	output.WriteString(fmt.Sprintf("Parsed Entries: %d\n", result.ParsedEntries))
+	output.WriteString(fmt.Sprintf("Skipped Lines: %d\n", result.SkippedLines))
+	if result.EmptyLines > 0 {
+		output.WriteString(fmt.Sprintf("Empty Lines: %d\n", result.EmptyLines))
+	}
+	output.WriteString(fmt.Sprintf("Total Events Analyzed: %d\n", result.TotalEventsAnalyzed))
+	if result.Partial {
+		output.WriteString(partialResultNote)
+	}
+	output.WriteString(fmt.Sprintf("Distinct Events: %d\n", result.DistinctEvents))
+
+	if result.FirstTimestamp != nil {
+		output.WriteString(fmt.Sprintf("\nFirst Timestamp: %s\n", formatTimestamp(*result.FirstTimestamp)))
+		output.WriteString(fmt.Sprintf("Last Timestamp: %s\n", formatTimestamp(*result.LastTimestamp)))
+		output.WriteString(fmt.Sprintf("Time Span: %s\n", formatSeconds(result.SpanSeconds)))
+		output.WriteString(fmt.Sprintf("Entries per Minute: %.2f\n", result.EntriesPerMinute))
+	}
+	if result.UntimedEntries > 0 {
+		output.WriteString(fmt.Sprintf("Without Timestamp: %d\n", result.UntimedEntries))
+	}
+
+	if len(result.Levels) > 0 {
+		output.WriteString("\nLevels:\n")
+		output.WriteString(f.renderFrequencies(result.Levels))
+	}
+	if len(result.Tags) > 0 {
+		output.WriteString(fmt.Sprintf("\nTags (%d distinct):\n", result.DistinctTags))
+		output.WriteString(f.renderFrequencies(result.Tags))
+		if hidden := result.DistinctTags - len(result.Tags); hidden > 0 {
+			output.WriteString(fmt.Sprintf("  ... and %d more\n", hidden))
+		}
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text log overview formatting completed")
+	return resultStr, nil
+}
+
+// renderFrequencies renders value counts as an indented table
+func (f *TextFormatter) renderFrequencies(values []analyzer.ValueFrequency) string {
+	var output strings.Builder
+	table := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	for _, value := range values {
+		fmt.Fprintf(table, "  %s\t%d\t(%.1f%%)\n", f.options.truncateName(value.Value), value.Count, value.Percentage)
+	}
+	table.Flush()
+	return output.String()
+}
+
+// FormatExtract prints the original log lines of the extracted entries, or
+// their message when the parser did not keep them, without any decoration so
+// the output can be attached to bug reports as is. Like grep, "--" separates
+// groups of entries that are not adjacent in the log when context is shown.
+func (f *TextFormatter) FormatExtract(result *analyzer.ExtractResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"matches":   result.Matches,
+		"extracted": len(result.Entries),
+	}).Debug("Formatting extract result as text")
+
+	var output strings.Builder
+	for i, entry := range result.Entries {
+		if i > 0 && result.Context > 0 && entry.Index != result.Entries[i-1].Index+1 {
+			output.WriteString("--\n")
+		}
+		line := entry.Raw
+		if line == "" {
+			line = entry.Message
+		}
+		output.WriteString(line)
+		output.WriteString("\n")
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text extract formatting completed")
+	return resultStr, nil
+}
+
+func (f *TextFormatter) FormatDiff(report *analyzer.DiffReport) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"baseline":     report.Baseline,
+		"candidate":    report.Candidate,
+		"funnel_count": len(report.Funnels),
+	}).Debug("Formatting diff report as text")
+
+	var output strings.Builder
+
+	output.WriteString("🔀 Funnel Diff\n")
+	output.WriteString(fmt.Sprintf("Baseline: %s\n", report.Baseline))
+	output.WriteString(fmt.Sprintf("Candidate: %s\n", report.Candidate))
+
+	for _, funnel := range report.Funnels {
+		output.WriteString(fmt.Sprintf("\n%s:\n", funnel.FunnelName))
+		output.WriteString(f.renderStepDiffs(funnel))
+		if len(funnel.DropOffs) > 0 {
+			output.WriteString("\nDrop-offs:\n")
+			output.WriteString(f.renderDropOffDiffs(funnel.DropOffs))
+		}
+		output.WriteString(fmt.Sprintf("\nConversion Rate: %.1f%% → %.1f%% (%s)\n",
+			funnel.BaselineRate, funnel.CandidateRate, formatPointsDelta(funnel.RateDelta)))
+	}
+
+	resultStr := output.String()
+	logrus.WithField("output_length", len(resultStr)).Debug("Text diff formatting completed")
+	return resultStr, nil
+}
+
+// renderStepDiffs renders the steps of a funnel diff as a table of baseline
+// and candidate counts with their deltas
+func (f *TextFormatter) renderStepDiffs(funnel analyzer.FunnelDiff) string {
+	var output strings.Builder
+	table := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, "Step\tBaseline\tCandidate\tΔ Count\tΔ Conversion")
+	for i, step := range funnel.Steps {
+		fmt.Fprintf(table, "%d. %s\t%d (%.1f%%)\t%d (%.1f%%)\t%+d\t%s\n",
+			i+1, f.options.truncateName(step.Name),
+			step.BaselineCount, step.BaselinePercentage,
+			step.CandidateCount, step.CandidatePercentage,
+			step.CountDelta, formatPointsDelta(step.PercentageDelta))
+	}
+	fmt.Fprintf(table, "Completed\t%s\t%s\n", yesNo(funnel.BaselineCompleted), yesNo(funnel.CandidateCompleted))
+
+	table.Flush()
+	return output.String()
+}
+
+// renderDropOffDiffs renders the drop-off rates of a funnel diff as a table
+func (f *TextFormatter) renderDropOffDiffs(dropOffs []analyzer.DropOffDiff) string {
+	var output strings.Builder
+	table := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, "From → To\tBaseline\tCandidate\tΔ")
+	for _, dropOff := range dropOffs {
+		fmt.Fprintf(table, "%s → %s\t%.1f%%\t%.1f%%\t%s\n",
+			f.options.truncateName(dropOff.From), f.options.truncateName(dropOff.To),
+			dropOff.BaselineRate, dropOff.CandidateRate, formatPointsDelta(dropOff.RateDelta))
+	}
+
+	table.Flush()
+	return output.String()
+}
+
+// formatPointsDelta formats a change of a percentage in percentage points
+func formatPointsDelta(delta float64) string {
+	return fmt.Sprintf("%+.1fpp", delta)
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "Yes"
+	}
+	return "No"
+}
+
+// renderStepTable renders the step breakdown as a table with the conversion
+// and drop-off of each step, and with --wide the times the step was first and
+// last matched and the median time to reach it. Samples and near misses are
+// listed below the table.
+func (f *TextFormatter) renderStepTable(result *analyzer.FunnelResult, unit string) string {
+	dropOffs := make(map[string]analyzer.DropOff, len(result.DropOffs))
+	for _, dropOff := range result.DropOffs {
+		dropOffs[dropOff.To] = dropOff
+	}
+	medians := make(map[string]float64, len(result.Timings))
+	for _, timing := range result.Timings {
+		medians[timing.Step] = timing.MedianSeconds
+	}
+
+	header := []string{">#", "Step", ">" + strings.ToUpper(unit[:1]) + unit[1:], ">Conversion", ">Drop-off"}
+	if f.options.Wide {
+		header = append(header, "First", "Last", ">Median Time")
+	}
+	steps := newTable(header...)
+	for i, step := range result.Steps {
+		name := f.options.truncateName(step.Name)
+		if step.AssertionFailed {
+			name += " (assertion failed)"
+		}
+		dropOff := "-"
+		if d, ok := dropOffs[step.Name]; ok && i > 0 {
+			dropOff = fmt.Sprintf("%.1f%%", d.DropOffRate)
+		}
+		row := []string{strconv.Itoa(i + 1), name, strconv.Itoa(step.EventCount), fmt.Sprintf("%.1f%%", step.Percentage), dropOff}
+		if f.options.Wide {
+			median := "-"
+			if seconds, ok := medians[step.Name]; ok {
+				median = formatSeconds(seconds)
+			}
+			row = append(row, formatOptionalTimestamp(step.FirstTimestamp), formatOptionalTimestamp(step.LastTimestamp), median)
+		}
+		steps.add(row...)
+
+		for _, branch := range step.Branches {
+			steps.add("", "↳ "+f.options.truncateName(branch.Name), strconv.Itoa(branch.EventCount), fmt.Sprintf("%.1f%%", branch.Percentage))
+		}
+	}
+
+	var output strings.Builder
+	output.WriteString(steps.render(f.options))
+	for i, step := range result.Steps {
+		if len(step.Samples) == 0 && step.NearMissCount == 0 {
+			continue
+		}
+		output.WriteString(fmt.Sprintf("\n%d. %s:\n", i+1, f.options.truncateName(step.Name)))
+		output.WriteString(renderStepSamples(step))
+		output.WriteString(renderNearMisses(step))
+	}
+	return output.String()
+}
+
+// renderPatternTable renders the pattern counts as a table with the share of
+// analyzed events and rate of each pattern, and with --wide the times of the
+// first and last match. Value statistics are listed below the table.
+func (f *TextFormatter) renderPatternTable(result *analyzer.CountResult, totalEvents int) string {
+	header := []string{">#", "Pattern", ">Matches", ">Share"}
+	if result.DurationSeconds > 0 {
+		header = append(header, ">Per Minute")
+	}
+	if f.options.Wide {
+		header = append(header, "First", "Last")
+	}
+	patterns := newTable(header...)
+	for i, patternCount := range result.PatternCounts {
+		percentage := 0.0
+		if totalEvents > 0 {
+			percentage = float64(patternCount.Count) / float64(totalEvents) * 100.0
+		}
+		row := []string{strconv.Itoa(i + 1), f.options.truncateName(patternCount.Pattern), strconv.Itoa(patternCount.Count), fmt.Sprintf("%.1f%%", percentage)}
+		if result.DurationSeconds > 0 {
+			row = append(row, fmt.Sprintf("%.2f", patternCount.PerMinute))
+		}
+		if f.options.Wide {
+			row = append(row, formatOptionalTimestamp(patternCount.FirstTimestamp), formatOptionalTimestamp(patternCount.LastTimestamp))
+		}
+		patterns.add(row...)
+	}
+
+	var output strings.Builder
+	output.WriteString(patterns.render(f.options))
+	for i, patternCount := range result.PatternCounts {
+		if patternCount.Values != nil {
+			output.WriteString(fmt.Sprintf("\n%d. %s:\n", i+1, f.options.truncateName(patternCount.Pattern)))
+			output.WriteString(renderValueStats("Values", patternCount.Values))
+		}
+	}
+	return output.String()
+}
+
+// renderStepSamples lists the sample entries of a step with their entry
+// number. Samples are the first and last matches, so the matches between
+// them are summarized when the step has more.
+func renderStepSamples(step analyzer.StepResult) string {
+	var output strings.Builder
+	omitted := step.EventCount - len(step.Samples)
+	for i, sample := range step.Samples {
+		if omitted > 0 && i == len(step.Samples)/2 {
+			output.WriteString(fmt.Sprintf("     ... %d more\n", omitted))
+		}
+		timestamp := ""
+		if sample.Timestamp != nil {
+			timestamp = formatTimestamp(*sample.Timestamp) + " "
+		}
+		output.WriteString(fmt.Sprintf("     #%d %s%s\n", sample.EntryIndex, timestamp, sample.Message))
+	}
+	return output.String()
+}
+
+// renderNearMisses lists the entries that almost matched a step no entry
+// matched, each followed by the reason it did not match
+func renderNearMisses(step analyzer.StepResult) string {
+	if step.NearMissCount == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("   ⚠️  Near misses: %d\n", step.NearMissCount))
+	for _, nearMiss := range step.NearMisses {
+		timestamp := ""
+		if nearMiss.Timestamp != nil {
+			timestamp = formatTimestamp(*nearMiss.Timestamp) + " "
+		}
+		output.WriteString(fmt.Sprintf("     #%d %s%s\n", nearMiss.EntryIndex, timestamp, nearMiss.Message))
+		output.WriteString(fmt.Sprintf("        %s\n", nearMiss.Reason))
+	}
+	if omitted := step.NearMissCount - len(step.NearMisses); omitted > 0 {
+		output.WriteString(fmt.Sprintf("     ... %d more\n", omitted))
+	}
+	return output.String()
+}
+
+// renderCountGroups renders the per-group breakdown of a count result as a
+// table with one column per pattern
+func (f *TextFormatter) renderCountGroups(result *analyzer.CountResult) string {
+	var output strings.Builder
+	groups := result.Groups
+
+	output.WriteString(fmt.Sprintf("\nMatches by %s:\n", groups.GroupBy))
+	if len(groups.Groups) == 0 {
+		output.WriteString(fmt.Sprintf("No matches with %s\n", groups.GroupBy))
+	} else {
+		table := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+		header := []string{f.options.truncateName(groups.GroupBy)}
+		for _, patternCount := range result.PatternCounts {
+			header = append(header, f.options.truncateName(patternCount.Pattern))
+		}
+		header = append(header, "Total")
+		fmt.Fprintln(table, strings.Join(header, "\t"))
+
+		for _, group := range groups.Groups {
+			row := []string{f.options.truncateName(group.Value)}
+			for _, count := range group.Counts {
+				row = append(row, strconv.Itoa(count))
+			}
+			row = append(row, strconv.Itoa(group.Total))
+			fmt.Fprintln(table, strings.Join(row, "\t"))
+		}
+		table.Flush()
+	}
+
+	if groups.UngroupedMatches > 0 {
+		output.WriteString(fmt.Sprintf("Without %s: %d matching entries\n", groups.GroupBy, groups.UngroupedMatches))
+	}
+	return output.String()
+}
+
+// renderCountSessions renders how many sessions matched each pattern
+func (f *TextFormatter) renderCountSessions(sessions *analyzer.CountSessions) string {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("\nSessions by %s: %d", sessions.SessionKey, sessions.SessionCount))
+	if sessions.IdleTimeoutSeconds > 0 {
+		output.WriteString(fmt.Sprintf(" (idle timeout %s)", formatSeconds(sessions.IdleTimeoutSeconds)))
+	}
+	output.WriteString("\n")
+
+	for i, patternCount := range sessions.Patterns {
+		output.WriteString(fmt.Sprintf("%d. %s: %d sessions (%.1f%%), %s per session, %s per matching session\n",
+			i+1, f.options.truncateName(patternCount.Pattern), patternCount.Sessions, patternCount.Percentage,
+			formatNumber(patternCount.AvgPerSession), formatNumber(patternCount.AvgPerMatchingSession)))
+	}
+
+	if sessions.UnkeyedEntries > 0 {
+		output.WriteString(fmt.Sprintf("Entries Without %s: %d\n", sessions.SessionKey, sessions.UnkeyedEntries))
+	}
+	return output.String()
+}
+
+// renderCountDistinct renders the distinct property values of each pattern
+func (f *TextFormatter) renderCountDistinct(distinct *analyzer.CountDistinct) string {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("\nDistinct %s:\n", distinct.Property))
+	for i, patternCount := range distinct.Patterns {
+		output.WriteString(fmt.Sprintf("%d. %s: %d values", i+1, f.options.truncateName(patternCount.Pattern), patternCount.Values))
+		if patternCount.MissingEntries > 0 {
+			output.WriteString(fmt.Sprintf(" (%d matches without %s)", patternCount.MissingEntries, distinct.Property))
+		}
+		output.WriteString("\n")
+		for _, value := range patternCount.Top {
+			output.WriteString(fmt.Sprintf("   %s: %d\n", f.options.truncateName(value.Value), value.Count))
+		}
+	}
+	return output.String()
+}
+
+// renderCountAggregate renders the sum, average, minimum and maximum of the
+// property over the matches of each pattern, and the sums of each group
+func (f *TextFormatter) renderCountAggregate(aggregate *analyzer.CountAggregate) string {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("\nAggregate %s:\n", aggregate.Property))
+	for i, patternAggregate := range aggregate.Patterns {
+		output.WriteString(fmt.Sprintf("%d. %s: ", i+1, f.options.truncateName(patternAggregate.Pattern)))
+		if patternAggregate.Values == 0 && patternAggregate.MissingEntries == 0 {
+			output.WriteString("no matches\n")
+			continue
+		}
+		if patternAggregate.Values == 0 {
+			output.WriteString(fmt.Sprintf("no values (%d matches without a numeric %s)\n", patternAggregate.MissingEntries, aggregate.Property))
+			continue
+		}
+		output.WriteString(fmt.Sprintf("sum=%s avg=%s min=%s max=%s (%d values",
+			formatNumber(patternAggregate.Sum), formatNumber(patternAggregate.Avg),
+			formatNumber(patternAggregate.Min), formatNumber(patternAggregate.Max), patternAggregate.Values))
+		if patternAggregate.MissingEntries > 0 {
+			output.WriteString(fmt.Sprintf(", %d matches without a numeric %s", patternAggregate.MissingEntries, aggregate.Property))
+		}
+		output.WriteString(")\n")
+	}
+
+	if aggregate.GroupBy == "" || len(aggregate.Patterns) == 0 {
+		return output.String()
+	}
+	output.WriteString(fmt.Sprintf("\nSum of %s by %s:\n", aggregate.Property, aggregate.GroupBy))
+	if len(aggregate.Groups) == 0 {
+		output.WriteString(fmt.Sprintf("No matches with %s\n", aggregate.GroupBy))
+		return output.String()
+	}
+	table := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	header := []string{f.options.truncateName(aggregate.GroupBy)}
+	for _, patternAggregate := range aggregate.Patterns {
+		header = append(header, f.options.truncateName(patternAggregate.Pattern))
+	}
+	header = append(header, "Total")
+	fmt.Fprintln(table, strings.Join(header, "\t"))
+	for _, group := range aggregate.Groups {
+		row := []string{f.options.truncateName(group.Value)}
+		for _, patternAggregate := range group.Patterns {
+			row = append(row, formatNumber(patternAggregate.Sum))
+		}
+		row = append(row, formatNumber(group.Sum))
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+	table.Flush()
+	return output.String()
+}
+
+func renderFunnelChart(steps []analyzer.StepResult, options Options) string {
+	names := make([]string, len(steps))
+	nameWidth := 0
+	for i, step := range steps {
+		names[i] = options.truncateName(step.Name)
+		if len([]rune(names[i])) > nameWidth {
+			nameWidth = len([]rune(names[i]))
+		}
+	}
+
+	var chart strings.Builder
+	for i, step := range steps {
+		percentage := step.Percentage
+		if percentage > 100.0 {
+			percentage = 100.0
+		}
+
+		barWidth := int(percentage/100.0*funnelChartWidth + 0.5)
+		if barWidth == 0 && step.EventCount > 0 {
+			barWidth = 1
+		}
+		padding := (funnelChartWidth - barWidth) / 2
+
+		chart.WriteString(fmt.Sprintf("  %s%s │%s%s%s│ %5.1f%%\n",
+			names[i],
+			strings.Repeat(" ", nameWidth-len([]rune(names[i]))),
+			strings.Repeat(" ", padding),
+			strings.Repeat("█", barWidth),
+			strings.Repeat(" ", funnelChartWidth-barWidth-padding),
+			step.Percentage))
+	}
+	return chart.String()
+}
+
+// valueHistogramWidth is the width of the bar for the fullest histogram bucket
+const valueHistogramWidth = 20
+
+// renderValueStats renders numeric values as an indented summary line
+// followed by a histogram
+func renderValueStats(label string, stats *analyzer.ValueStats) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("   %s: n=%d min=%s avg=%s p95=%s max=%s\n",
+		label, stats.Samples, formatNumber(stats.Min), formatNumber(stats.Avg), formatNumber(stats.P95), formatNumber(stats.Max)))
+
+	maxCount := 0
+	labels := make([]string, len(stats.Histogram))
+	labelWidth := 0
+	for i, bucket := range stats.Histogram {
+		if bucket.Count > maxCount {
+			maxCount = bucket.Count
+		}
+		labels[i] = fmt.Sprintf("%s - %s", formatNumber(bucket.From), formatNumber(bucket.To))
+		if len(labels[i]) > labelWidth {
+			labelWidth = len(labels[i])
+		}
+	}
+
+	for i, bucket := range stats.Histogram {
+		barWidth := 0
+		if maxCount > 0 {
+			barWidth = bucket.Count * valueHistogramWidth / maxCount
+		}
+		if barWidth == 0 && bucket.Count > 0 {
+			barWidth = 1
+		}
+		out.WriteString(fmt.Sprintf("   %-*s │%s %d\n", labelWidth, labels[i], strings.Repeat("█", barWidth), bucket.Count))
+	}
+
+	return out.String()
+}
+
+// timelineWidth is the width of the bar for the fullest timeline interval
+const timelineWidth = 40
+
+// renderTimeline draws one bar per interval labeled with its start time,
+// including the date only when the timeline spans several days
+func renderTimeline(buckets []analyzer.TimelineBucket) string {
+	layout := "15:04:05"
+	first, last := buckets[0].Start, buckets[len(buckets)-1].Start
+	if first.YearDay() != last.YearDay() || first.Year() != last.Year() {
+		layout = "01-02 15:04:05"
+	}
+
+	maxCount := 0
+	for _, bucket := range buckets {
+		maxCount = max(maxCount, bucket.Count)
+	}
+
+	var out strings.Builder
+	for _, bucket := range buckets {
+		barWidth := bucket.Count * timelineWidth / maxCount
+		if barWidth == 0 && bucket.Count > 0 {
+			barWidth = 1
+		}
+		out.WriteString(fmt.Sprintf("  %s │%s %d\n", bucket.Start.Format(layout), strings.Repeat("█", barWidth), bucket.Count))
+	}
+	return out.String()
+}
+
+// formatSeconds prints a duration in seconds rounded to milliseconds, e.g. 1m2.5s
+// maxListedConversions caps the conversions listed in text output, unless
+// their steps were requested. The JSON output has all of them.
+const maxListedConversions = 10
+
+// renderConversions lists the entries and timestamps that started and ended
+// each conversion, with the time it took and the entry of each step when
+// conversion steps were requested
+func renderConversions(conversions []analyzer.FunnelConversion, options Options) string {
+	var output strings.Builder
+	listed := conversions
+	if len(conversions[0].Steps) == 0 {
+		listed = conversions[:min(len(conversions), maxListedConversions)]
+	}
+	for _, conversion := range listed {
+		output.WriteString(fmt.Sprintf("%d. ", conversion.Number))
+		if conversion.Group != "" {
+			output.WriteString(fmt.Sprintf("%s: ", options.truncateName(conversion.Group)))
+		}
+		output.WriteString(fmt.Sprintf("entries %d → %d", conversion.StartEntry, conversion.EndEntry))
+		if conversion.StartTime != nil && conversion.EndTime != nil {
+			output.WriteString(fmt.Sprintf(", %s → %s (%s)", formatTimestamp(*conversion.StartTime),
+				formatTimestamp(*conversion.EndTime), formatSeconds(conversion.DurationSeconds)))
+		}
+		output.WriteString("\n")
+		for _, step := range conversion.Steps {
+			timestamp := ""
+			if step.Timestamp != nil {
+				timestamp = formatTimestamp(*step.Timestamp) + " "
+			}
+			output.WriteString(fmt.Sprintf("   %s: #%d %s%s\n", options.truncateName(step.Step), step.EntryIndex, timestamp, step.Message))
+		}
+	}
+	if hidden := len(conversions) - len(listed); hidden > 0 {
+		output.WriteString(fmt.Sprintf("... and %d more\n", hidden))
+	}
+	return output.String()
+}
+
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
+
+// formatTimestamp prints a log timestamp with milliseconds, without the year
+// for formats such as logcat that carry none
+// formatOptionalTimestamp formats t, or returns "-" for a missing timestamp
+func formatOptionalTimestamp(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return formatTimestamp(*t)
+}
+
+func formatTimestamp(t time.Time) string {
+	if t.Year() == 0 {
+		return t.Format("01-02 15:04:05.000")
+	}
+	return t.Format("2006-01-02 15:04:05.000")
+}
+
+// formatNumber prints whole numbers without decimals and others with two
+func formatNumber(v float64) string {
+	if v == float64(int64(v)) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+type JSONFormatter struct {
+	options Options
+}
+
+var funnelJSONSections = map[string]string{
+	"steps":       SectionSteps,
+	"drop_offs":   SectionDropOffs,
+	"anomalies":   SectionAnomalies,
+	"exclusions":  SectionExclusions,
+	"timings":     SectionTimings,
+	"assertions":  SectionAssertions,
+	"conversions": SectionConversions,
+}
+
+var countJSONSections = map[string]string{
+	"pattern_counts":      SectionCounts,
+	"overlapping_entries": SectionOverlaps,
+	"overlaps":            SectionOverlaps,
+	"groups":              SectionGroups,
+	"sessions":            SectionSessions,
+	"distinct":            SectionDistinct,
+	"aggregate":           SectionAggregate,
+}
+
+func (f *JSONFormatter) FormatFunnel(result *analyzer.FunnelResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"funnel_name":      result.FunnelName,
+		"total_events":     result.TotalEventsAnalyzed,
+		"funnel_completed": result.FunnelCompleted,
+		"steps_count":      len(result.Steps),
+		"dropoffs_count":   len(result.DropOffs),
+	}).Debug("Formatting funnel result as JSON")
+
+	jsonData, err := json.MarshalIndent(f.options.filterFunnel(result), "", "  ")
+	if err == nil {
+		jsonData, err = f.options.selectJSONSections(jsonData, funnelJSONSections)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal funnel result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatFunnels(results []*analyzer.FunnelResult) (string, error) {
+	logrus.WithField("funnel_count", len(results)).Debug("Formatting multi-funnel report as JSON")
+
+	report := struct {
+		Funnels []json.RawMessage `json:"funnels"`
+	}{
+		Funnels: make([]json.RawMessage, 0, len(results)),
+	}
+
+	for _, result := range results {
+		funnelOutput, err := f.FormatFunnel(result)
+		if err != nil {
+			return "", err
+		}
+		report.Funnels = append(report.Funnels, json.RawMessage(funnelOutput))
+	}
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal multi-funnel report to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON multi-funnel formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatCount(result *analyzer.CountResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"total_events":   result.TotalEventsAnalyzed,
+		"patterns_count": len(result.PatternCounts),
+	}).Debug("Formatting count result as JSON")
+
+	jsonData, err := json.MarshalIndent(f.options.filterCount(result), "", "  ")
+	if err == nil {
+		jsonData, err = f.options.selectJSONSections(jsonData, countJSONSections)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal count result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON count formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatSessions(result *analyzer.SessionResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"total_events":  result.TotalEventsAnalyzed,
+		"session_count": result.SessionCount,
+	}).Debug("Formatting session result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal session result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON session formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatTop(result *analyzer.TopResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"total_events":    result.TotalEventsAnalyzed,
+		"distinct_events": result.DistinctEvents,
+	}).Debug("Formatting top events result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal top events result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON top events formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatCooccurrence(result *analyzer.CooccurrenceResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"total_events": result.TotalEventsAnalyzed,
+		"units":        result.Units,
+	}).Debug("Formatting co-occurrence result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal co-occurrence result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON co-occurrence formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatDiscover(result *analyzer.DiscoverResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"total_events":    result.TotalEventsAnalyzed,
+		"total_sequences": result.TotalSequences,
+	}).Debug("Formatting discover result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal discover result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON discover formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatGaps(result *analyzer.GapResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"matching_events": result.MatchingEvents,
+		"gaps":            len(result.Gaps),
+	}).Debug("Formatting gap result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal gap result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON gap formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatAnomalies(result *analyzer.AnomalyResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"windows":   result.Windows,
+		"anomalies": len(result.Anomalies),
+	}).Debug("Formatting anomaly result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal anomaly result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON anomaly formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatRetention(result *analyzer.RetentionResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"users":   result.Users,
+		"cohorts": len(result.Cohorts),
+	}).Debug("Formatting retention result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal retention result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON retention formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatAudit(result *analyzer.AuditResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"audited_events": result.AuditedEvents,
+		"violations":     len(result.Violations),
+	}).Debug("Formatting audit result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal audit result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON audit formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatTimeline(result *analyzer.TimelineResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"matching_events": result.MatchingEvents,
+		"buckets":         len(result.Buckets),
+	}).Debug("Formatting timeline result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal timeline result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON timeline formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatOverview(result *analyzer.OverviewResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"total_lines":  result.TotalLines,
+		"total_events": result.TotalEventsAnalyzed,
+	}).Debug("Formatting log overview result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal log overview result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON log overview formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatExtract(result *analyzer.ExtractResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"matches":   result.Matches,
+		"extracted": len(result.Entries),
+	}).Debug("Formatting extract result as JSON")
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal extract result to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON extract formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatDiff(report *analyzer.DiffReport) (string, error) {
+	logrus.WithField("funnel_count", len(report.Funnels)).Debug("Formatting diff report as JSON")
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal diff report to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON diff formatting completed")
+	return string(jsonData), nil
+}
+
+func (f *JSONFormatter) FormatLabeled(report *analyzer.LabeledReport) (string, error) {
+	logrus.WithField("label_count", len(report.Labels)).Debug("Formatting labeled report as JSON")
+
+	labels := make([]json.RawMessage, len(report.Labels))
+	for i, labeled := range report.Labels {
+		funnels := make([]json.RawMessage, len(labeled.Funnels))
+		for j, result := range labeled.Funnels {
+			funnelJSON, err := f.FormatFunnel(result)
+			if err != nil {
+				return "", err
+			}
+			funnels[j] = json.RawMessage(funnelJSON)
+		}
+
+		labelJSON, err := json.Marshal(struct {
+			Label   string            `json:"label"`
+			Funnels []json.RawMessage `json:"funnels"`
+		}{labeled.Label, funnels})
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		labels[i] = labelJSON
+	}
+
+	jsonData, err := json.MarshalIndent(struct {
+		Labels      []json.RawMessage           `json:"labels"`
+		Comparisons []analyzer.FunnelComparison `json:"comparisons"`
+	}{labels, report.Comparisons}, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal labeled report to JSON")
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	logrus.WithField("json_length", len(jsonData)).Debug("JSON labeled report formatting completed")
 	return string(jsonData), nil
 }
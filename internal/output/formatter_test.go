@@ -2,10 +2,12 @@ package output
 
 import (
 	"encoding/json"
-	"github.com/parfenovvs/loglion/internal/analyzer"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
 )
 
 func TestOutputFormat_Constants(t *testing.T) {
@@ -182,6 +184,344 @@ func TestTextFormatter_FormatFunnel_NoDropOffs(t *testing.T) {
 	}
 }
 
+func TestFormatters_PartialResult(t *testing.T) {
+	funnelResult := &analyzer.FunnelResult{
+		FunnelName:          "Test",
+		TotalEventsAnalyzed: 10,
+		Steps:               []analyzer.StepResult{{Name: "Login", EventCount: 1, Percentage: 100}},
+		Partial:             true,
+	}
+	countResult := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		PatternCounts:       []analyzer.PatternCount{{Pattern: "login", Count: 1}},
+		Partial:             true,
+	}
+
+	textFunnel, _ := (&TextFormatter{}).FormatFunnel(funnelResult)
+	textCount, _ := (&TextFormatter{}).FormatCount(countResult)
+	for _, output := range []string{textFunnel, textCount} {
+		if !strings.Contains(output, "Partial result") {
+			t.Errorf("Text output should mark partial result, got:\n%s", output)
+		}
+	}
+
+	jsonFunnel, _ := (&JSONFormatter{}).FormatFunnel(funnelResult)
+	jsonCount, _ := (&JSONFormatter{}).FormatCount(countResult)
+	for _, output := range []string{jsonFunnel, jsonCount} {
+		if !strings.Contains(output, `"partial": true`) {
+			t.Errorf("JSON output should contain partial marker, got:\n%s", output)
+		}
+	}
+
+	funnelResult.Partial = false
+	jsonFunnel, _ = (&JSONFormatter{}).FormatFunnel(funnelResult)
+	if strings.Contains(jsonFunnel, "partial") {
+		t.Errorf("JSON output should omit partial marker for complete results, got:\n%s", jsonFunnel)
+	}
+}
+
+func TestTextFormatter_FormatFunnel_Groups(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Checkout",
+		TotalEventsAnalyzed: 20,
+		FunnelCompleted:     true,
+		Steps: []analyzer.StepResult{
+			{Name: "View", EventCount: 4, Percentage: 100},
+			{Name: "Buy", EventCount: 1, Percentage: 25},
+		},
+		DropOffs: []analyzer.DropOff{
+			{From: "View", To: "Buy", EventsLost: 3, DropOffRate: 75},
+		},
+		Groups: &analyzer.GroupSummary{
+			GroupBy:          "user_id",
+			TotalGroups:      4,
+			CompletedGroups:  1,
+			UngroupedEntries: 2,
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Grouped By: user_id (4 groups, 1 completed, 3 dropped)",
+		"Entries Without user_id: 2",
+		"1. View: 4 groups (100.0%)",
+		"- View → Buy: 3 groups lost (75.0% drop-off)",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatFunnel() should contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func TestTextFormatter_FormatFunnel_Anomalies(t *testing.T) {
+	formatter := &TextFormatter{}
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Screen Loop",
+		TotalEventsAnalyzed: 600,
+		FunnelCompleted:     false,
+		Steps: []analyzer.StepResult{
+			{Name: "Screen View", EventCount: 1, Percentage: 100.0},
+		},
+		DropOffs: []analyzer.DropOff{},
+		Anomalies: []analyzer.Anomaly{
+			{Step: "Screen View", Occurrences: 501, Limit: 500, EntryIndex: 42},
+		},
+	}
+
+	output, err := formatter.FormatFunnel(result)
+	if err != nil {
+		t.Errorf("FormatFunnel() unexpected error: %v", err)
+		return
+	}
+
+	if !strings.Contains(output, "Anomalies:") {
+		t.Errorf("FormatFunnel() should contain anomalies section")
+	}
+	if !strings.Contains(output, "Screen View: fired 501 times in one attempt (limit 500, entry 42)") {
+		t.Errorf("FormatFunnel() should contain anomaly details, got:\n%s", output)
+	}
+}
+
+func TestTextFormatter_FormatFunnel_Exclusions(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Purchase",
+		TotalEventsAnalyzed: 20,
+		FunnelCompleted:     true,
+		Steps: []analyzer.StepResult{
+			{Name: "Cart", EventCount: 4, Percentage: 100.0},
+			{Name: "Purchase", EventCount: 1, Percentage: 25.0},
+		},
+		DropOffs: []analyzer.DropOff{},
+		Exclusions: []analyzer.Exclusion{
+			{Step: "Purchase", Pattern: "purchase_error", AbortedAttempts: 3},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "Exclusions: 3 attempts aborted") {
+		t.Errorf("FormatFunnel() should contain exclusions section, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Purchase: 3 attempts aborted by /purchase_error/") {
+		t.Errorf("FormatFunnel() should contain exclusion details, got:\n%s", output)
+	}
+
+	hidden := &TextFormatter{options: Options{Hide: []string{SectionExclusions}}}
+	output, err = hidden.FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	if strings.Contains(output, "Exclusions:") {
+		t.Errorf("FormatFunnel() should hide exclusions section, got:\n%s", output)
+	}
+}
+
+func TestTextFormatter_FormatFunnel_Timings(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Checkout",
+		TotalEventsAnalyzed: 20,
+		FunnelCompleted:     true,
+		Steps: []analyzer.StepResult{
+			{Name: "View", EventCount: 4, Percentage: 100.0},
+			{Name: "Cart", EventCount: 3, Percentage: 75.0},
+			{Name: "Pay", EventCount: 2, Percentage: 50.0},
+		},
+		DropOffs: []analyzer.DropOff{},
+		Timings: []analyzer.StepTiming{
+			{Step: "Cart", Samples: 3, MinSeconds: 1, MedianSeconds: 2.5, P95Seconds: 4, MaxSeconds: 4},
+			{Step: "Pay", Samples: 2, MinSeconds: 10, MedianSeconds: 10, P95Seconds: 90.25, MaxSeconds: 90.25},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Time to Convert (from first step):",
+		"- Cart: n=3 min=1s median=2.5s p95=4s max=4s",
+		"- Pay (total): n=2 min=10s median=10s p95=1m30.25s max=1m30.25s",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatFunnel() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	jsonOutput, err := (&JSONFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	if !strings.Contains(jsonOutput, `"median_seconds": 2.5`) {
+		t.Errorf("JSON output should contain timings, got:\n%s", jsonOutput)
+	}
+}
+
+func TestTextFormatter_FormatFunnel_Branches(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Signup",
+		TotalEventsAnalyzed: 20,
+		FunnelCompleted:     true,
+		Steps: []analyzer.StepResult{
+			{Name: "Open", EventCount: 4, Percentage: 100.0},
+			{Name: "Sign In", EventCount: 3, Percentage: 75.0, Branches: []analyzer.BranchResult{
+				{Name: "google", EventCount: 2, Percentage: 66.7},
+				{Name: "apple", EventCount: 1, Percentage: 33.3},
+			}},
+		},
+		DropOffs: []analyzer.DropOff{},
+	}
+
+	output, err := (&TextFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	expected := "2. Sign In: 3 events (75.0%)\n   ↳ google: 2 events (66.7%)\n   ↳ apple: 1 events (33.3%)\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("FormatFunnel() should list branches under the step, got:\n%s", output)
+	}
+}
+
+func TestTextFormatter_FormatFunnel_Samples(t *testing.T) {
+	timestamp := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Signup",
+		TotalEventsAnalyzed: 20,
+		FunnelCompleted:     true,
+		Steps: []analyzer.StepResult{
+			{Name: "Open", EventCount: 5, Percentage: 100.0, Samples: []analyzer.StepSample{
+				{EntryIndex: 1, Timestamp: &timestamp, Message: "open"},
+				{EntryIndex: 19, Message: "open again"},
+			}},
+			{Name: "Sign In", EventCount: 1, Percentage: 20.0, Samples: []analyzer.StepSample{
+				{EntryIndex: 4, Message: "sign_in"},
+			}},
+		},
+		DropOffs: []analyzer.DropOff{},
+	}
+
+	output, err := (&TextFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"1. Open: 5 events (100.0%)\n     #1 2025-01-15 10:30:00.000 open\n     ... 3 more\n     #19 open again\n",
+		"2. Sign In: 1 events (20.0%)\n     #4 sign_in\n",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(output, fragment) {
+			t.Errorf("FormatFunnel() should contain %q, got:\n%s", fragment, output)
+		}
+	}
+}
+
+func TestTextFormatter_FormatFunnel_NearMisses(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Checkout",
+		TotalEventsAnalyzed: 20,
+		Steps: []analyzer.StepResult{
+			{Name: "View", EventCount: 5, Percentage: 100.0},
+			{Name: "Purchase", EventCount: 0, Percentage: 0.0, NearMissCount: 3, NearMisses: []analyzer.NearMiss{
+				{StepSample: analyzer.StepSample{EntryIndex: 7, Message: "purchase"}, Property: "amount", Reason: "property 'amount' is 0, expected > 0"},
+			}},
+		},
+		DropOffs: []analyzer.DropOff{},
+	}
+
+	output, err := (&TextFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	expected := "2. Purchase: 0 events (0.0%)\n   ⚠️  Near misses: 3\n     #7 purchase\n        property 'amount' is 0, expected > 0\n     ... 2 more\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("FormatFunnel() should contain %q, got:\n%s", expected, output)
+	}
+	if strings.Count(output, "Near misses") != 1 {
+		t.Errorf("FormatFunnel() should only list near misses of unmatched steps, got:\n%s", output)
+	}
+}
+
+func TestTextFormatter_FormatFunnel_Assertions(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Checkout",
+		TotalEventsAnalyzed: 20,
+		Steps: []analyzer.StepResult{
+			{Name: "View", EventCount: 10, Percentage: 100.0},
+			{Name: "Purchase", EventCount: 1, Percentage: 10.0, AssertionFailed: true},
+		},
+		DropOffs: []analyzer.DropOff{},
+		Assertions: []analyzer.Assertion{
+			{Step: "View", Threshold: analyzer.ThresholdMinCount, Expected: 5, Actual: 10, Passed: true},
+			{Step: "Purchase", Threshold: analyzer.ThresholdMinConversionPct, Expected: 25, Actual: 10},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"1. View: 10 events (100.0%)\n",
+		"2. Purchase: 1 events (10.0%) ❌ assertion failed\n",
+		"Assertions: 1 passed, 1 failed\n- ✅ View: min_count 5, got 10\n- ❌ Purchase: min_conversion_pct 25.0%, got 10.0%\n",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(output, fragment) {
+			t.Errorf("FormatFunnel() should contain %q, got:\n%s", fragment, output)
+		}
+	}
+
+	hidden, _ := (&TextFormatter{options: Options{Hide: []string{SectionAssertions}}}).FormatFunnel(result)
+	if strings.Contains(hidden, "Assertions:") {
+		t.Errorf("Expected assertions to be hidden, got:\n%s", hidden)
+	}
+}
+
+func TestTextFormatter_FormatFunnel_Chart(t *testing.T) {
+	formatter := &TextFormatter{}
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Checkout",
+		TotalEventsAnalyzed: 20,
+		FunnelCompleted:     true,
+		Steps: []analyzer.StepResult{
+			{Name: "View", EventCount: 10, Percentage: 100.0},
+			{Name: "Cart", EventCount: 5, Percentage: 50.0},
+			{Name: "Pay", EventCount: 0, Percentage: 0.0},
+		},
+		DropOffs: []analyzer.DropOff{},
+	}
+
+	output, err := formatter.FormatFunnel(result)
+	if err != nil {
+		t.Errorf("FormatFunnel() unexpected error: %v", err)
+		return
+	}
+
+	expectedLines := []string{
+		"Funnel Chart:",
+		"  View │" + strings.Repeat("█", 40) + "│ 100.0%",
+		"  Cart │" + strings.Repeat(" ", 10) + strings.Repeat("█", 20) + strings.Repeat(" ", 10) + "│  50.0%",
+		"  Pay  │" + strings.Repeat(" ", 40) + "│   0.0%",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatFunnel() should contain chart line %q, got:\n%s", line, output)
+		}
+	}
+}
+
 func TestJSONFormatter_FormatFunnel_ValidResult(t *testing.T) {
 	formatter := &JSONFormatter{}
 	result := &analyzer.FunnelResult{
@@ -663,3 +1003,1136 @@ func TestFormatter_Interface_FormatCount(t *testing.T) {
 		})
 	}
 }
+
+func TestTextFormatter_FormatCount_ValueStats(t *testing.T) {
+	formatter := &TextFormatter{}
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		PatternCounts: []analyzer.PatternCount{
+			{
+				Pattern: `load_time_ms=(\d+)`,
+				Count:   3,
+				Values: &analyzer.ValueStats{
+					Samples: 3,
+					Min:     100,
+					Max:     300,
+					Avg:     200,
+					P95:     300,
+					Histogram: []analyzer.HistogramBucket{
+						{From: 100, To: 200, Count: 1},
+						{From: 200, To: 300, Count: 2},
+					},
+				},
+			},
+		},
+	}
+
+	output, err := formatter.FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Values: n=3 min=100 avg=200 p95=300 max=300",
+		"100 - 200 │" + strings.Repeat("█", 10) + " 1",
+		"200 - 300 │" + strings.Repeat("█", 20) + " 2",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatCount() should contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func TestTextFormatter_FormatCount_Overlaps(t *testing.T) {
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: "login", Count: 4},
+			{Pattern: "login_failed", Count: 2},
+		},
+		OverlappingEntries: 2,
+		Overlaps: []analyzer.PatternOverlap{
+			{Patterns: []string{"login", "login_failed"}, Count: 2},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Overlapping Patterns: 2 entries matched more than one pattern",
+		"- login & login_failed: 2 shared matches",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatCount() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	hidden, err := (&TextFormatter{options: Options{Hide: []string{SectionOverlaps}}}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if strings.Contains(hidden, "Overlapping Patterns") {
+		t.Errorf("FormatCount() should hide overlaps section, got:\n%s", hidden)
+	}
+}
+
+func TestTextFormatter_FormatCount_Groups(t *testing.T) {
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: "login", Count: 3},
+			{Pattern: "logout", Count: 0},
+			{Pattern: "purchase", Count: 2},
+		},
+		Groups: &analyzer.CountGroups{
+			GroupBy: "user_id",
+			Groups: []analyzer.GroupCount{
+				{Value: "alice", Counts: []int{2, 0, 2}, Total: 4},
+				{Value: "bob", Counts: []int{1, 0, 0}, Total: 1},
+			},
+			UngroupedMatches: 1,
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Matches by user_id:\n",
+		"user_id  login  logout  purchase  Total\n",
+		"alice    2      0       2         4\n",
+		"bob      1      0       0         1\n",
+		"Without user_id: 1 matching entries",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatCount() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	filtered, err := (&TextFormatter{options: Options{Hide: []string{HideZeroCount}}}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if !strings.Contains(filtered, "alice    2      2         4\n") {
+		t.Errorf("FormatCount() should drop zero-count pattern columns, got:\n%s", filtered)
+	}
+	if result.Groups.Groups[0].Counts[1] != 0 || len(result.Groups.Groups[0].Counts) != 3 {
+		t.Errorf("Filtering should not modify the result, got %+v", result.Groups.Groups[0])
+	}
+
+	hidden, err := (&TextFormatter{options: Options{Hide: []string{SectionGroups}}}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if strings.Contains(hidden, "Matches by") {
+		t.Errorf("FormatCount() should hide groups section, got:\n%s", hidden)
+	}
+}
+
+func TestTextFormatter_FormatCount_Sessions(t *testing.T) {
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: "share", Count: 3},
+			{Pattern: "export", Count: 0},
+		},
+		Sessions: &analyzer.CountSessions{
+			SessionKey:         "session_id",
+			IdleTimeoutSeconds: 1800,
+			SessionCount:       4,
+			UnkeyedEntries:     2,
+			Patterns: []analyzer.SessionPatternCount{
+				{Pattern: "share", Sessions: 2, Percentage: 50, AvgPerSession: 0.75, AvgPerMatchingSession: 1.5},
+				{Pattern: "export", Sessions: 0},
+			},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Sessions by session_id: 4 (idle timeout 30m0s)\n",
+		"1. share: 2 sessions (50.0%), 0.75 per session, 1.50 per matching session\n",
+		"2. export: 0 sessions (0.0%), 0 per session, 0 per matching session\n",
+		"Entries Without session_id: 2\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatCount() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	filtered, err := (&TextFormatter{options: Options{Hide: []string{HideZeroCount}}}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if strings.Contains(filtered, "export: 0 sessions") {
+		t.Errorf("FormatCount() should hide patterns without sessions, got:\n%s", filtered)
+	}
+
+	hidden, err := (&TextFormatter{options: Options{Only: []string{SectionCounts}}}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if strings.Contains(hidden, "Sessions by") {
+		t.Errorf("FormatCount() should hide sessions section, got:\n%s", hidden)
+	}
+}
+
+func TestTextFormatter_FormatFunnels(t *testing.T) {
+	formatter := &TextFormatter{}
+	results := []*analyzer.FunnelResult{
+		{
+			FunnelName:          "Login",
+			TotalEventsAnalyzed: 10,
+			FunnelCompleted:     true,
+			Steps:               []analyzer.StepResult{{Name: "Login", EventCount: 2, Percentage: 100.0}},
+			DropOffs:            []analyzer.DropOff{},
+		},
+		{
+			FunnelName:          "Purchase",
+			TotalEventsAnalyzed: 10,
+			FunnelCompleted:     false,
+			Steps:               []analyzer.StepResult{{Name: "Pay", EventCount: 0, Percentage: 0.0}},
+			DropOffs:            []analyzer.DropOff{},
+		},
+	}
+
+	output, err := formatter.FormatFunnels(results)
+	if err != nil {
+		t.Fatalf("FormatFunnels() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"📋 Multi-Funnel Report",
+		"Funnels Analyzed: 2",
+		"Funnels Completed: 1",
+		"✅ Funnel Analysis Complete\n\nFunnel: Login",
+		"❌ Funnel Analysis Complete\n\nFunnel: Purchase",
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("FormatFunnels() should contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestJSONFormatter_FormatFunnels(t *testing.T) {
+	formatter := &JSONFormatter{}
+	results := []*analyzer.FunnelResult{
+		{FunnelName: "Login", TotalEventsAnalyzed: 10, FunnelCompleted: true, Steps: []analyzer.StepResult{}, DropOffs: []analyzer.DropOff{}},
+		{FunnelName: "Purchase", TotalEventsAnalyzed: 10, Steps: []analyzer.StepResult{}, DropOffs: []analyzer.DropOff{}},
+	}
+
+	output, err := formatter.FormatFunnels(results)
+	if err != nil {
+		t.Fatalf("FormatFunnels() unexpected error: %v", err)
+	}
+
+	var parsed struct {
+		Funnels []analyzer.FunnelResult `json:"funnels"`
+	}
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("FormatFunnels() output is not valid JSON: %v", err)
+	}
+
+	if len(parsed.Funnels) != 2 || parsed.Funnels[0].FunnelName != "Login" || parsed.Funnels[1].FunnelName != "Purchase" {
+		t.Errorf("Unexpected funnels in JSON output: %+v", parsed.Funnels)
+	}
+}
+
+func TestTextFormatter_FormatCount_Rates(t *testing.T) {
+	first := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	last := first.Add(2 * time.Minute)
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		FirstTimestamp:      &first,
+		LastTimestamp:       &last,
+		DurationSeconds:     120,
+		EventsPerMinute:     5,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: "login", Count: 3, PerMinute: 1.5},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	expected := []string{
+		"First Timestamp: 2024-01-15 10:00:00.000\n",
+		"Duration: 2m0s\n",
+		"Events per Minute: 5.00\n",
+		"1. login: 3 matches (30.0%), 1.50 per minute\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatCount() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	html, err := (&HTMLFormatter{}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if !strings.Contains(html, `<th class="num">Per minute</th>`) || !strings.Contains(html, `<td class="num">1.50</td>`) {
+		t.Errorf("HTML FormatCount() should contain the rate column, got:\n%s", html)
+	}
+}
+
+func TestTextFormatter_FormatCount_Distinct(t *testing.T) {
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: "purchase", Count: 4},
+			{Pattern: "refund", Count: 0},
+		},
+		Distinct: &analyzer.CountDistinct{
+			Property: "user_id",
+			Patterns: []analyzer.DistinctPatternCount{
+				{Pattern: "purchase", Values: 2, MissingEntries: 1, Top: []analyzer.ValueCount{{Value: "alice", Count: 2}, {Value: "bob", Count: 1}}},
+				{Pattern: "refund", Values: 0},
+			},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Distinct user_id:\n",
+		"1. purchase: 2 values (1 matches without user_id)\n",
+		"   alice: 2\n",
+		"   bob: 1\n",
+		"2. refund: 0 values\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatCount() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	filtered, err := (&TextFormatter{options: Options{Hide: []string{HideZeroCount}}}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if strings.Contains(filtered, "refund: 0 values") {
+		t.Errorf("FormatCount() should hide patterns without matches, got:\n%s", filtered)
+	}
+
+	hidden, err := (&TextFormatter{options: Options{Hide: []string{SectionDistinct}}}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if strings.Contains(hidden, "Distinct user_id") {
+		t.Errorf("FormatCount() should hide distinct section, got:\n%s", hidden)
+	}
+}
+
+func TestTextFormatter_FormatCount_Aggregate(t *testing.T) {
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: "purchase", Count: 4},
+			{Pattern: "refund", Count: 0},
+		},
+		Aggregate: &analyzer.CountAggregate{
+			Property: "amount",
+			Patterns: []analyzer.PropertyAggregate{
+				{Pattern: "purchase", Values: 3, Sum: 65.5, Avg: 21.83, Min: 15.5, Max: 30, MissingEntries: 1},
+				{Pattern: "refund"},
+			},
+			GroupBy: "user_id",
+			Groups: []analyzer.AggregateGroup{
+				{Value: "alice", Sum: 50, Patterns: []analyzer.PropertyAggregate{{Pattern: "purchase", Sum: 50}, {Pattern: "refund"}}},
+				{Value: "bob", Sum: 15.5, Patterns: []analyzer.PropertyAggregate{{Pattern: "purchase", Sum: 15.5}, {Pattern: "refund"}}},
+			},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Aggregate amount:\n",
+		"1. purchase: sum=65.50 avg=21.83 min=15.50 max=30 (3 values, 1 matches without a numeric amount)\n",
+		"2. refund: no matches\n",
+		"Sum of amount by user_id:\n",
+		"user_id  purchase  refund  Total\n",
+		"bob      15.50     0       15.50\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatCount() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	filtered, err := (&TextFormatter{options: Options{Hide: []string{HideZeroCount}}}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if strings.Contains(filtered, "refund") {
+		t.Errorf("FormatCount() should hide patterns without matches, got:\n%s", filtered)
+	}
+
+	hidden, err := (&TextFormatter{options: Options{Hide: []string{SectionAggregate}}}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if strings.Contains(hidden, "Aggregate amount") {
+		t.Errorf("FormatCount() should hide aggregate section, got:\n%s", hidden)
+	}
+}
+
+func TestTextFormatter_FormatCooccurrence(t *testing.T) {
+	result := &analyzer.CooccurrenceResult{
+		TotalEventsAnalyzed: 20,
+		Unit:                analyzer.CooccurrenceWindow,
+		WindowSeconds:       300,
+		Units:               4,
+		SkippedEntries:      2,
+		Patterns: []analyzer.CooccurrencePattern{
+			{Pattern: "crash", Units: 2, Percentage: 50},
+			{Pattern: "low_memory", Units: 1, Percentage: 25},
+		},
+		Matrix:      [][]int{{2, 1}, {1, 1}},
+		Conditional: [][]float64{{100, 50}, {100, 100}},
+		Before:      [][]int{{0, 0}, {1, 0}},
+	}
+
+	output, err := (&TextFormatter{}).FormatCooccurrence(result)
+	if err != nil {
+		t.Fatalf("FormatCooccurrence() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Windows of 5m0s: 4\n",
+		"Entries Without Timestamp: 2\n",
+		"1. crash: 2 windows (50.0%)\n",
+		"Co-occurrence (windows with both):\n",
+		"crash       2      1\n",
+		"crash       100.0%  50.0%\n",
+		"low_memory  1      -\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatCooccurrence() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	if _, err := (&HTMLFormatter{}).FormatCooccurrence(result); err == nil {
+		t.Error("Expected HTML co-occurrence output to be unsupported")
+	}
+}
+
+func TestTextFormatter_FormatDiscover(t *testing.T) {
+	result := &analyzer.DiscoverResult{
+		TotalEventsAnalyzed: 30,
+		SessionKey:          "user_id",
+		IdleTimeoutSeconds:  1800,
+		SessionCount:        4,
+		UnkeyedEntries:      1,
+		MinSupport:          2,
+		Sequences: []analyzer.EventSequence{
+			{Events: []string{"open", "view", "cart"}, Support: 3, Percentage: 75},
+			{Events: []string{"view", "purchase"}, Support: 2, Percentage: 50},
+		},
+		TotalSequences: 5,
+	}
+
+	output, err := (&TextFormatter{}).FormatDiscover(result)
+	if err != nil {
+		t.Fatalf("FormatDiscover() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Sessions by user_id: 4 (idle timeout 30m0s)\n",
+		"Entries Without user_id: 1\n",
+		"Minimum Support: 2 sessions\n",
+		"1. open → view → cart: 3 sessions (75.0%)\n",
+		"2. view → purchase: 2 sessions (50.0%)\n",
+		"... and 3 more\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatDiscover() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	if _, err := (&HTMLFormatter{}).FormatDiscover(result); err == nil {
+		t.Error("Expected HTML discover output to be unsupported")
+	}
+}
+
+func TestTextFormatter_FormatGaps(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	result := &analyzer.GapResult{
+		Pattern:               "session_ping",
+		ThresholdSeconds:      60,
+		TotalEventsAnalyzed:   50,
+		MatchingEvents:        12,
+		MedianIntervalSeconds: 30,
+		LongestGapSeconds:     300,
+		Gaps: []analyzer.Gap{
+			{Kind: analyzer.GapBetween, Start: start, End: start.Add(5 * time.Minute), DurationSeconds: 300},
+			{Kind: analyzer.GapLogEnd, Start: start.Add(10 * time.Minute), End: start.Add(12 * time.Minute), DurationSeconds: 120},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatGaps(result)
+	if err != nil {
+		t.Fatalf("FormatGaps() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Pattern: session_ping\n",
+		"Median Interval: 30s\n",
+		"Longest Gap: 5m0s\n",
+		"Gaps Longer Than 1m0s:\n",
+		"1. 2024-01-15 10:00:00.000 → 2024-01-15 10:05:00.000: 5m0s\n",
+		"2. 2024-01-15 10:10:00.000 → 2024-01-15 10:12:00.000: 2m0s (until the end of the log)\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatGaps() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	result.Gaps = nil
+	if output, _ := (&TextFormatter{}).FormatGaps(result); !strings.Contains(output, "✅ No gaps longer than 1m0s") {
+		t.Errorf("Expected no gaps to be reported, got:\n%s", output)
+	}
+
+	if _, err := (&HTMLFormatter{}).FormatGaps(result); err == nil {
+		t.Error("Expected HTML gap output to be unsupported")
+	}
+}
+
+func TestTextFormatter_FormatAnomalies(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	result := &analyzer.AnomalyResult{
+		Patterns:            []string{"error", "ping"},
+		WindowSeconds:       60,
+		BaselineWindows:     10,
+		Factor:              3,
+		MinCount:            5,
+		TotalEventsAnalyzed: 500,
+		Windows:             30,
+		Anomalies: []analyzer.RateAnomaly{
+			{Pattern: "error", Kind: analyzer.AnomalySpike, Start: start, End: start.Add(time.Minute), Count: 42, Baseline: 5, Magnitude: 8.4},
+			{Pattern: "ping", Kind: analyzer.AnomalyDrop, Start: start.Add(time.Minute), End: start.Add(2 * time.Minute), Count: 0, Baseline: 6},
+			{Pattern: "error", Kind: analyzer.AnomalySpike, Start: start.Add(5 * time.Minute), End: start.Add(6 * time.Minute), Count: 7},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatAnomalies(result)
+	if err != nil {
+		t.Fatalf("FormatAnomalies() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Patterns: error, ping\n",
+		"Windows of 1m0s: 30\n",
+		"Baseline: average of the previous 10 windows\n",
+		"Factor: 3x\n",
+		"1. 2024-01-15 10:00:00.000 → 2024-01-15 10:01:00.000 error: 42 events, 8.4x the baseline of 5.00 (spike)\n",
+		"2. 2024-01-15 10:01:00.000 → 2024-01-15 10:02:00.000 ping: 0 events, 0.0x the baseline of 6.00 (drop)\n",
+		"3. 2024-01-15 10:05:00.000 → 2024-01-15 10:06:00.000 error: 7 events, none before (spike)\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatAnomalies() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	if _, err := (&HTMLFormatter{}).FormatAnomalies(result); err == nil {
+		t.Error("Expected HTML anomaly output to be unsupported")
+	}
+}
+
+func TestTextFormatter_FormatRetention(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	result := &analyzer.RetentionResult{
+		CohortPattern:       "signup",
+		ReturnPattern:       "app_open",
+		UserKey:             "user_id",
+		PeriodSeconds:       86400,
+		TotalEventsAnalyzed: 200,
+		Users:               3,
+		UnkeyedEvents:       2,
+		Periods: []analyzer.RetentionPeriod{
+			{Period: 0, EligibleUsers: 3, RetainedUsers: 1, Percentage: 100.0 / 3},
+			{Period: 1, EligibleUsers: 2, RetainedUsers: 2, Percentage: 100},
+			{Period: 2},
+		},
+		Cohorts: []analyzer.RetentionCohort{
+			{Start: day, Users: 2, Periods: []analyzer.RetentionPeriod{
+				{Period: 0, EligibleUsers: 2, RetainedUsers: 1, Percentage: 50},
+				{Period: 1, EligibleUsers: 2, RetainedUsers: 2, Percentage: 100},
+				{Period: 2},
+			}},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatRetention(result)
+	if err != nil {
+		t.Fatalf("FormatRetention() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Cohort Event: signup\n",
+		"Return Event: app_open\n",
+		"Users: 3\n",
+		"Without user_id: 2\n",
+		"Day 0: 1/3 users (33.3%)\n",
+		"Day 1: 2/2 users (100.0%)\n",
+		"Day 2: not reached by the log\n",
+		"Cohort                   Users  Day 0  Day 1   Day 2\n",
+		"2024-01-15 00:00:00.000  2      50.0%  100.0%  -\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatRetention() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	result.PeriodSeconds = 1800
+	if output, _ := (&TextFormatter{}).FormatRetention(result); !strings.Contains(output, "Period: 30m0s\n") || !strings.Contains(output, "Period 1: 2/2 users") {
+		t.Errorf("Expected periods other than days and hours to be numbered, got:\n%s", output)
+	}
+
+	if output, _ := (&TextFormatter{}).FormatRetention(&analyzer.RetentionResult{}); !strings.Contains(output, "No users with a cohort event found") {
+		t.Errorf("Expected an empty result message, got:\n%s", output)
+	}
+
+	if _, err := (&HTMLFormatter{}).FormatRetention(result); err == nil {
+		t.Error("Expected HTML retention output to be unsupported")
+	}
+}
+
+func TestTextFormatter_FormatAudit(t *testing.T) {
+	result := &analyzer.AuditResult{
+		TotalEventsAnalyzed: 40,
+		AuditedEvents:       5,
+		InvalidEvents:       2,
+		Events: []analyzer.EventAudit{
+			{Pattern: "purchase", Occurrences: 3, InvalidOccurrences: 1},
+			{Pattern: "login", Occurrences: 2, InvalidOccurrences: 1},
+		},
+		Violations: []analyzer.PropertyViolation{
+			{Event: "purchase", Property: "amount", Kind: analyzer.ViolationType, Message: "expected number, got string", Value: "12,50", EntryIndex: 3, Source: "app.log", Line: 7},
+			{Event: "login", Property: "user_id", Kind: analyzer.ViolationMissing, Message: "missing required property", EntryIndex: 5},
+		},
+	}
+
+	output, err := (&TextFormatter{}).FormatAudit(result)
+	if err != nil {
+		t.Fatalf("FormatAudit() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Audited Events: 5\n",
+		"Invalid Events: 2\n",
+		"purchase: 3 occurrences, 1 invalid\n",
+		"1. app.log:7 purchase: amount: expected number, got string (\"12,50\")\n",
+		"2. #5 login: user_id: missing required property\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("FormatAudit() should contain %q, got:\n%s", line, output)
+		}
+	}
+
+	result.Violations = nil
+	if output, _ := (&TextFormatter{}).FormatAudit(result); !strings.Contains(output, "✅ All audited events match the schema") {
+		t.Errorf("Expected a success message without violations, got:\n%s", output)
+	}
+
+	if _, err := (&HTMLFormatter{}).FormatAudit(result); err == nil {
+		t.Error("Expected HTML audit output to be unsupported")
+	}
+}
+
+func TestFormatSessions(t *testing.T) {
+	result := &analyzer.SessionResult{
+		TotalEventsAnalyzed: 40,
+		SessionKey:          "user_id",
+		IdleTimeoutSeconds:  1800,
+		SessionCount:        3,
+		IdleSplits:          1,
+		UnkeyedEntries:      2,
+		Durations:           &analyzer.ValueStats{Samples: 3, Min: 60, Max: 600, Avg: 260, P95: 600, Histogram: []analyzer.HistogramBucket{{From: 60, To: 600, Count: 3}}},
+		EventsPerSession:    &analyzer.ValueStats{Samples: 3, Min: 2, Max: 30, Avg: 12.67, P95: 30, Histogram: []analyzer.HistogramBucket{{From: 2, To: 30, Count: 3}}},
+	}
+
+	text, err := (&TextFormatter{}).FormatSessions(result)
+	if err != nil {
+		t.Fatalf("FormatSessions() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"🕒 Session Analysis Complete",
+		"Session Key: user_id",
+		"Idle Timeout: 30m0s",
+		"Sessions: 3",
+		"Idle Timeout Splits: 1",
+		"Entries Without user_id: 2",
+		"Duration: n=3 min=60 avg=260 p95=600 max=600",
+		"Events: n=3 min=2 avg=12.67 p95=30 max=30",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			t.Errorf("FormatSessions() should contain %q, got:\n%s", line, text)
+		}
+	}
+
+	jsonOutput, err := (&JSONFormatter{}).FormatSessions(result)
+	if err != nil {
+		t.Fatalf("FormatSessions() unexpected error: %v", err)
+	}
+	for _, key := range []string{`"session_count": 3`, `"idle_splits": 1`, `"duration_seconds"`, `"events_per_session"`} {
+		if !strings.Contains(jsonOutput, key) {
+			t.Errorf("JSON output should contain %s, got:\n%s", key, jsonOutput)
+		}
+	}
+
+	empty, _ := (&TextFormatter{}).FormatSessions(&analyzer.SessionResult{})
+	if empty != "❌ No events found\n" {
+		t.Errorf("Expected no events message, got %q", empty)
+	}
+}
+
+func TestFormatTop(t *testing.T) {
+	result := &analyzer.TopResult{
+		TotalEventsAnalyzed: 10,
+		DistinctEvents:      4,
+		Events: []analyzer.EventFrequency{
+			{Event: "view_product", Count: 5, Percentage: 50},
+			{Event: "login", Count: 3, Percentage: 30},
+		},
+	}
+
+	text, err := (&TextFormatter{options: Options{MaxNameWidth: 8}}).FormatTop(result)
+	if err != nil {
+		t.Fatalf("FormatTop() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"🔝 Top Events",
+		"Total Events Analyzed: 10",
+		"Distinct Events: 4",
+		"1. view_pr…: 5 (50.0%)",
+		"2. login: 3 (30.0%)",
+		"... and 2 more",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			t.Errorf("FormatTop() should contain %q, got:\n%s", line, text)
+		}
+	}
+
+	jsonOutput, err := (&JSONFormatter{}).FormatTop(result)
+	if err != nil {
+		t.Fatalf("FormatTop() unexpected error: %v", err)
+	}
+	for _, key := range []string{`"distinct_events": 4`, `"event": "view_product"`, `"percentage": 50`} {
+		if !strings.Contains(jsonOutput, key) {
+			t.Errorf("JSON output should contain %s, got:\n%s", key, jsonOutput)
+		}
+	}
+
+	empty, _ := (&TextFormatter{}).FormatTop(&analyzer.TopResult{})
+	if empty != "❌ No events found\n" {
+		t.Errorf("Expected no events message, got %q", empty)
+	}
+}
+
+func TestFormatTimeline(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	result := &analyzer.TimelineResult{
+		Patterns:            []string{"login"},
+		IntervalSeconds:     60,
+		TotalEventsAnalyzed: 10,
+		MatchingEvents:      5,
+		UntimedEvents:       1,
+		Buckets: []analyzer.TimelineBucket{
+			{Start: base, Count: 4},
+			{Start: base.Add(time.Minute), Count: 0},
+			{Start: base.Add(2 * time.Minute), Count: 1},
+		},
+	}
+
+	text, err := (&TextFormatter{}).FormatTimeline(result)
+	if err != nil {
+		t.Fatalf("FormatTimeline() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"📈 Event Timeline",
+		"Patterns: login",
+		"Matching Events: 5",
+		"Interval: 1m0s",
+		"Without Timestamp: 1",
+		"  10:30:00 │" + strings.Repeat("█", 40) + " 4\n",
+		"  10:31:00 │ 0\n",
+		"  10:32:00 │" + strings.Repeat("█", 10) + " 1\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			t.Errorf("FormatTimeline() should contain %q, got:\n%s", line, text)
+		}
+	}
+
+	// Timelines spanning several days include the date
+	result.Buckets[2].Start = base.Add(24 * time.Hour)
+	text, _ = (&TextFormatter{}).FormatTimeline(result)
+	if !strings.Contains(text, "01-16 10:30:00 │") {
+		t.Errorf("Expected dated labels, got:\n%s", text)
+	}
+
+	jsonOutput, err := (&JSONFormatter{}).FormatTimeline(result)
+	if err != nil {
+		t.Fatalf("FormatTimeline() unexpected error: %v", err)
+	}
+	for _, key := range []string{`"interval_seconds": 60`, `"start": "2025-01-15T10:30:00Z"`, `"untimed_events": 1`} {
+		if !strings.Contains(jsonOutput, key) {
+			t.Errorf("JSON output should contain %s, got:\n%s", key, jsonOutput)
+		}
+	}
+
+	empty, _ := (&TextFormatter{}).FormatTimeline(&analyzer.TimelineResult{})
+	if empty != "❌ No matching events found\n" {
+		t.Errorf("Expected no events message, got %q", empty)
+	}
+}
+
+func TestFormatOverview(t *testing.T) {
+	first := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	last := first.Add(90 * time.Second)
+	result := &analyzer.OverviewResult{
+		TotalLines:          12,
+		ParsedEntries:       10,
+		SkippedLines:        1,
+		EmptyLines:          1,
+		TotalEventsAnalyzed: 10,
+		FirstTimestamp:      &first,
+		LastTimestamp:       &last,
+		SpanSeconds:         90,
+		EntriesPerMinute:    6.67,
+		DistinctEvents:      4,
+		Levels:              []analyzer.ValueFrequency{{Value: "I", Count: 8, Percentage: 80}, {Value: "E", Count: 2, Percentage: 20}},
+		Tags:                []analyzer.ValueFrequency{{Value: "Analytics", Count: 7, Percentage: 70}},
+		DistinctTags:        2,
+	}
+
+	text, err := (&TextFormatter{}).FormatOverview(result)
+	if err != nil {
+		t.Fatalf("FormatOverview() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"📋 Log Overview",
+		"Total Lines: 12",
+		"Skipped Lines: 1",
+		"Empty Lines: 1",
+		"Distinct Events: 4",
+		"First Timestamp: 2025-01-15 10:30:00.000",
+		"Last Timestamp: 2025-01-15 10:31:30.000",
+		"Time Span: 1m30s",
+		"Entries per Minute: 6.67",
+		"  I  8  (80.0%)\n",
+		"Tags (2 distinct):",
+		"  Analytics  7  (70.0%)\n",
+		"  ... and 1 more",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			t.Errorf("FormatOverview() should contain %q, got:\n%s", line, text)
+		}
+	}
+
+	// Timestamps without a year omit it
+	first, last = first.AddDate(-2025, 0, 0), last.AddDate(-2025, 0, 0)
+	text, _ = (&TextFormatter{}).FormatOverview(result)
+	if !strings.Contains(text, "First Timestamp: 01-15 10:30:00.000") {
+		t.Errorf("Expected timestamps without year, got:\n%s", text)
+	}
+
+	jsonOutput, err := (&JSONFormatter{}).FormatOverview(result)
+	if err != nil {
+		t.Fatalf("FormatOverview() unexpected error: %v", err)
+	}
+	for _, key := range []string{`"total_lines": 12`, `"span_seconds": 90`, `"value": "Analytics"`, `"distinct_tags": 2`} {
+		if !strings.Contains(jsonOutput, key) {
+			t.Errorf("JSON output should contain %s, got:\n%s", key, jsonOutput)
+		}
+	}
+
+	empty, _ := (&TextFormatter{}).FormatOverview(&analyzer.OverviewResult{})
+	if strings.Contains(empty, "First Timestamp") || strings.Contains(empty, "Levels:") {
+		t.Errorf("Expected no time range or levels, got:\n%s", empty)
+	}
+}
+
+func TestFormatExtract(t *testing.T) {
+	result := &analyzer.ExtractResult{
+		TotalEventsAnalyzed: 10,
+		Matches:             2,
+		Context:             1,
+		Entries: []analyzer.ExtractedEntry{
+			{Index: 1, Message: "start", Raw: "10:00:01 I App: start"},
+			{Index: 2, Match: true, Message: "crash", Raw: "10:00:02 E App: crash\n  at Main.run"},
+			{Index: 7, Match: true, Message: "retry"},
+		},
+	}
+
+	text, err := (&TextFormatter{}).FormatExtract(result)
+	if err != nil {
+		t.Fatalf("FormatExtract() unexpected error: %v", err)
+	}
+	expected := "10:00:01 I App: start\n10:00:02 E App: crash\n  at Main.run\n--\nretry\n"
+	if text != expected {
+		t.Errorf("FormatExtract() = %q, want %q", text, expected)
+	}
+
+	// Without context there are no groups to separate
+	result.Context = 0
+	text, _ = (&TextFormatter{}).FormatExtract(result)
+	if strings.Contains(text, "--") {
+		t.Errorf("Expected no separator without context, got:\n%s", text)
+	}
+
+	jsonOutput, err := (&JSONFormatter{}).FormatExtract(result)
+	if err != nil {
+		t.Fatalf("FormatExtract() unexpected error: %v", err)
+	}
+	for _, key := range []string{`"matches": 2`, `"index": 2`, `"match": true`, `"raw": "10:00:01 I App: start"`} {
+		if !strings.Contains(jsonOutput, key) {
+			t.Errorf("JSON output should contain %s, got:\n%s", key, jsonOutput)
+		}
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	report := &analyzer.DiffReport{
+		Baseline:  "before.txt",
+		Candidate: "after.txt",
+		Funnels: []analyzer.FunnelDiff{{
+			FunnelName:        "Checkout",
+			BaselineCompleted: true,
+			BaselineRate:      40,
+			CandidateRate:     25,
+			RateDelta:         -15,
+			Steps: []analyzer.StepDiff{
+				{Name: "View", BaselineCount: 10, CandidateCount: 12, CountDelta: 2, BaselinePercentage: 100, CandidatePercentage: 100},
+				{Name: "Buy", BaselineCount: 4, CandidateCount: 3, CountDelta: -1, BaselinePercentage: 40, CandidatePercentage: 25, PercentageDelta: -15},
+			},
+			DropOffs: []analyzer.DropOffDiff{
+				{From: "View", To: "Buy", BaselineRate: 60, CandidateRate: 75, RateDelta: 15},
+			},
+		}},
+	}
+
+	text, err := (&TextFormatter{}).FormatDiff(report)
+	if err != nil {
+		t.Fatalf("FormatDiff() unexpected error: %v", err)
+	}
+	expected := []string{
+		"🔀 Funnel Diff\nBaseline: before.txt\nCandidate: after.txt\n",
+		"Step       Baseline     Candidate    Δ Count  Δ Conversion\n",
+		"1. View    10 (100.0%)  12 (100.0%)  +2       +0.0pp\n",
+		"2. Buy     4 (40.0%)    3 (25.0%)    -1       -15.0pp\n",
+		"Completed  Yes          No\n",
+		"View → Buy  60.0%     75.0%      +15.0pp\n",
+		"Conversion Rate: 40.0% → 25.0% (-15.0pp)\n",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(text, fragment) {
+			t.Errorf("FormatDiff() should contain %q, got:\n%s", fragment, text)
+		}
+	}
+
+	jsonOutput, err := (&JSONFormatter{}).FormatDiff(report)
+	if err != nil {
+		t.Fatalf("FormatDiff() unexpected error: %v", err)
+	}
+	for _, key := range []string{`"baseline": "before.txt"`, `"conversion_rate_delta": -15`, `"count_delta": -1`, `"rate_delta": 15`} {
+		if !strings.Contains(jsonOutput, key) {
+			t.Errorf("JSON output should contain %s, got:\n%s", key, jsonOutput)
+		}
+	}
+}
+
+func TestFormatLabeled(t *testing.T) {
+	funnel := func(completed bool, buy int) *analyzer.FunnelResult {
+		return &analyzer.FunnelResult{
+			FunnelName:          "Checkout",
+			TotalEventsAnalyzed: 20,
+			FunnelCompleted:     completed,
+			Steps: []analyzer.StepResult{
+				{Name: "View", EventCount: 10, Percentage: 100},
+				{Name: "Buy", EventCount: buy, Percentage: float64(buy) * 10},
+			},
+		}
+	}
+	report := analyzer.NewLabeledReport([]analyzer.LabeledResult{
+		{Label: "device=pixel7", Funnels: []*analyzer.FunnelResult{funnel(true, 4)}},
+		{Label: "device=s23", Funnels: []*analyzer.FunnelResult{funnel(false, 0)}},
+	})
+
+	text, err := (&TextFormatter{}).FormatLabeled(report)
+	if err != nil {
+		t.Fatalf("FormatLabeled() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"🏷️  Comparison by Label\n\nCheckout:\n",
+		"Step       device=pixel7  device=s23\n",
+		"2. Buy     4 (40.0%)      0 (0.0%)\n",
+		"Completed  Yes            No\n",
+		"Label: device=pixel7\n\n✅ Funnel Analysis Complete",
+		"Label: device=s23\n\n❌ Funnel Analysis Complete",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(text, fragment) {
+			t.Errorf("FormatLabeled() should contain %q, got:\n%s", fragment, text)
+		}
+	}
+
+	jsonOutput, err := (&JSONFormatter{}).FormatLabeled(report)
+	if err != nil {
+		t.Fatalf("FormatLabeled() unexpected error: %v", err)
+	}
+	var parsed struct {
+		Labels []struct {
+			Label   string                  `json:"label"`
+			Funnels []analyzer.FunnelResult `json:"funnels"`
+		} `json:"labels"`
+		Comparisons []analyzer.FunnelComparison `json:"comparisons"`
+	}
+	if err := json.Unmarshal([]byte(jsonOutput), &parsed); err != nil {
+		t.Fatalf("FormatLabeled() produced invalid JSON: %v", err)
+	}
+	if len(parsed.Labels) != 2 || parsed.Labels[1].Label != "device=s23" || len(parsed.Labels[1].Funnels) != 1 {
+		t.Errorf("Unexpected labels in JSON output: %+v", parsed.Labels)
+	}
+	if len(parsed.Comparisons) != 1 || parsed.Comparisons[0].Steps[1].EventCounts[0] != 4 {
+		t.Errorf("Unexpected comparisons in JSON output: %+v", parsed.Comparisons)
+	}
+}
+
+func TestFormatFunnel_Conversions(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	end := start.Add(2*time.Minute + 30*time.Second)
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Login",
+		TotalEventsAnalyzed: 40,
+		FunnelCompleted:     true,
+		Steps:               []analyzer.StepResult{{Name: "Start", EventCount: 12}, {Name: "Done", EventCount: 12}},
+		EnteredFunnel:       15,
+		ConversionsFound:    12,
+		Conversions: []analyzer.FunnelConversion{
+			{Number: 1, StartEntry: 4, EndEntry: 9, StartTime: &start, EndTime: &end, DurationSeconds: 150},
+			{Number: 2, Group: "alice", StartEntry: 11, EndEntry: 12},
+		},
+	}
+	for i := 3; i <= 12; i++ {
+		result.Conversions = append(result.Conversions, analyzer.FunnelConversion{Number: i, StartEntry: 2 * i, EndEntry: 2*i + 1})
+	}
+
+	text, err := (&TextFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Entered Funnel: 15\nConversions Found: 12",
+		"Conversions:\n1. entries 4 → 9, 2025-01-15 10:00:00.000 → 2025-01-15 10:02:30.000 (2m30s)\n",
+		"2. alice: entries 11 → 12\n",
+		"10. entries 20 → 21\n... and 2 more\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected text output to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	hidden, err := (&TextFormatter{options: Options{Hide: []string{SectionConversions}}}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	if strings.Contains(hidden, "Conversions:") || !strings.Contains(hidden, "Conversions Found: 12") {
+		t.Errorf("Expected only the conversions list to be hidden, got:\n%s", hidden)
+	}
+
+	jsonOutput, err := (&JSONFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	var decoded struct {
+		EnteredFunnel    int `json:"entered_funnel"`
+		ConversionsFound int `json:"conversions_found"`
+		Conversions      []struct {
+			Number          int     `json:"number"`
+			Group           string  `json:"group"`
+			StartEntry      int     `json:"start_entry"`
+			DurationSeconds float64 `json:"duration_seconds"`
+		} `json:"conversions"`
+	}
+	if err := json.Unmarshal([]byte(jsonOutput), &decoded); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if decoded.EnteredFunnel != 15 || decoded.ConversionsFound != 12 || len(decoded.Conversions) != 12 ||
+		decoded.Conversions[0].DurationSeconds != 150 || decoded.Conversions[1].Group != "alice" {
+		t.Errorf("Unexpected JSON conversions: %+v", decoded)
+	}
+}
+
+func TestFormatFunnel_ConversionSteps(t *testing.T) {
+	timestamp := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Login",
+		TotalEventsAnalyzed: 40,
+		FunnelCompleted:     true,
+		Steps:               []analyzer.StepResult{{Name: "Start", EventCount: 12}, {Name: "Done", EventCount: 12}},
+		ConversionsFound:    12,
+	}
+	for i := 1; i <= 12; i++ {
+		result.Conversions = append(result.Conversions, analyzer.FunnelConversion{
+			Number: i, StartEntry: 2 * i, EndEntry: 2*i + 1,
+			Steps: []analyzer.ConversionStep{
+				{Step: "Start", StepSample: analyzer.StepSample{EntryIndex: 2 * i, Timestamp: &timestamp, Message: "start"}},
+				{Step: "Done", StepSample: analyzer.StepSample{EntryIndex: 2*i + 1, Message: "done"}},
+			},
+		})
+	}
+
+	text, err := (&TextFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"1. entries 2 → 3\n   Start: #2 2025-01-15 10:00:00.000 start\n   Done: #3 done\n",
+		"12. entries 24 → 25\n   Start: #24",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected text output to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "more") {
+		t.Errorf("Expected all conversions to be listed with their steps, got:\n%s", text)
+	}
+}
@@ -0,0 +1,464 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/sirupsen/logrus"
+)
+
+// HTMLFormatter renders results as a self-contained HTML report with inline
+// styles and no external resources, so it can be shared as a single file
+type HTMLFormatter struct {
+	options Options
+}
+
+// htmlFunnel is the template data of one funnel section
+type htmlFunnel struct {
+	*analyzer.FunnelResult
+	LastStep string
+	// MaxSeconds is the longest time to convert, the full width of timing bars
+	MaxSeconds float64
+	options    Options
+}
+
+// htmlCount is the template data of a count report
+type htmlCount struct {
+	*analyzer.CountResult
+	TotalEvents  int
+	TotalMatches int
+	MaxCount     int
+	options      Options
+}
+
+// htmlReport is the template data of a whole report
+type htmlReport struct {
+	Title   string
+	Funnels []htmlFunnel
+	Count   *htmlCount
+	// Completed counts completed funnels of a multi-funnel report
+	Completed   int
+	Comparisons []analyzer.FunnelComparison
+	Labels      []htmlLabel
+	options     Options
+}
+
+// htmlLabel is the template data of the funnels of one label
+type htmlLabel struct {
+	Label   string
+	Funnels []htmlFunnel
+}
+
+// Show reports whether a section is selected for output
+func (r htmlReport) Show(section string) bool { return r.options.showSection(section) }
+
+// Show reports whether a section is selected for output
+func (f htmlFunnel) Show(section string) bool { return f.options.showSection(section) }
+
+// Show reports whether a section is selected for output
+func (c htmlCount) Show(section string) bool { return c.options.showSection(section) }
+
+func (f *HTMLFormatter) FormatFunnel(result *analyzer.FunnelResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"funnel_name":  result.FunnelName,
+		"total_events": result.TotalEventsAnalyzed,
+		"steps_count":  len(result.Steps),
+	}).Debug("Formatting funnel result as HTML")
+
+	return f.render(htmlReport{
+		Title:   "Funnel Report: " + result.FunnelName,
+		Funnels: []htmlFunnel{f.newHTMLFunnel(result)},
+	})
+}
+
+func (f *HTMLFormatter) FormatFunnels(results []*analyzer.FunnelResult) (string, error) {
+	logrus.WithField("funnel_count", len(results)).Debug("Formatting multi-funnel report as HTML")
+
+	report := htmlReport{Title: "Multi-Funnel Report"}
+	for _, result := range results {
+		if result.FunnelCompleted {
+			report.Completed++
+		}
+		report.Funnels = append(report.Funnels, f.newHTMLFunnel(result))
+	}
+	return f.render(report)
+}
+
+func (f *HTMLFormatter) FormatLabeled(report *analyzer.LabeledReport) (string, error) {
+	logrus.WithField("label_count", len(report.Labels)).Debug("Formatting labeled report as HTML")
+
+	htmlReport := htmlReport{Title: "Comparison by Label", Comparisons: report.Comparisons}
+	for _, labeled := range report.Labels {
+		label := htmlLabel{Label: labeled.Label}
+		for _, result := range labeled.Funnels {
+			label.Funnels = append(label.Funnels, f.newHTMLFunnel(result))
+		}
+		htmlReport.Labels = append(htmlReport.Labels, label)
+	}
+	return f.render(htmlReport)
+}
+
+func (f *HTMLFormatter) FormatCount(result *analyzer.CountResult) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"total_events":   result.TotalEventsAnalyzed,
+		"patterns_count": len(result.PatternCounts),
+	}).Debug("Formatting count result as HTML")
+
+	count := &htmlCount{TotalEvents: result.TotalEventsAnalyzed, options: f.options}
+	count.CountResult = f.options.filterCount(result)
+	for _, patternCount := range count.PatternCounts {
+		count.TotalMatches += patternCount.Count
+		count.MaxCount = max(count.MaxCount, patternCount.Count)
+	}
+
+	return f.render(htmlReport{Title: "Event Count Report", Count: count})
+}
+
+func (f *HTMLFormatter) FormatSessions(result *analyzer.SessionResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for session statistics")
+}
+
+func (f *HTMLFormatter) FormatTop(result *analyzer.TopResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for top events")
+}
+
+func (f *HTMLFormatter) FormatCooccurrence(result *analyzer.CooccurrenceResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for co-occurrence")
+}
+
+func (f *HTMLFormatter) FormatDiscover(result *analyzer.DiscoverResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for sequence discovery")
+}
+
+func (f *HTMLFormatter) FormatGaps(result *analyzer.GapResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for gaps")
+}
+
+func (f *HTMLFormatter) FormatAnomalies(result *analyzer.AnomalyResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for anomalies")
+}
+
+func (f *HTMLFormatter) FormatRetention(result *analyzer.RetentionResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for retention")
+}
+
+func (f *HTMLFormatter) FormatAudit(result *analyzer.AuditResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for event audits")
+}
+
+func (f *HTMLFormatter) FormatTimeline(result *analyzer.TimelineResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for timelines")
+}
+
+func (f *HTMLFormatter) FormatOverview(result *analyzer.OverviewResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for log overviews")
+}
+
+func (f *HTMLFormatter) FormatExtract(result *analyzer.ExtractResult) (string, error) {
+	return "", fmt.Errorf("html output is not supported for extracted entries")
+}
+
+func (f *HTMLFormatter) FormatDiff(report *analyzer.DiffReport) (string, error) {
+	return "", fmt.Errorf("html output is not supported for funnel diffs")
+}
+
+func (f *HTMLFormatter) newHTMLFunnel(result *analyzer.FunnelResult) htmlFunnel {
+	funnel := htmlFunnel{options: f.options}
+	// The time to the last step is the total conversion time, even if
+	// zero-count steps are hidden
+	if len(result.Steps) > 0 {
+		funnel.LastStep = result.Steps[len(result.Steps)-1].Name
+	}
+	funnel.FunnelResult = f.options.filterFunnel(result)
+	for _, timing := range result.Timings {
+		funnel.MaxSeconds = max(funnel.MaxSeconds, timing.MaxSeconds)
+	}
+	return funnel
+}
+
+func (f *HTMLFormatter) render(report htmlReport) (string, error) {
+	report.options = f.options
+
+	var out strings.Builder
+	if err := htmlTemplate.Execute(&out, report); err != nil {
+		logrus.WithError(err).Error("Failed to render HTML report")
+		return "", fmt.Errorf("failed to render HTML report: %w", err)
+	}
+
+	logrus.WithField("html_length", out.Len()).Debug("HTML formatting completed")
+	return out.String(), nil
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pct":       func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"seconds":   formatSeconds,
+	"timestamp": formatTimestamp,
+	"number":    formatNumber,
+	// bar returns a CSS width for value relative to total
+	"bar": func(value, total float64) template.CSS {
+		if total <= 0 {
+			return "0%"
+		}
+		return template.CSS(fmt.Sprintf("%.1f%%", value/total*100))
+	},
+	"float": func(v int) float64 { return float64(v) },
+	"inc":   func(i int) int { return i + 1 },
+	"share": func(count, total int) string {
+		if total == 0 {
+			return "0.0%"
+		}
+		return fmt.Sprintf("%.1f%%", float64(count)/float64(total)*100)
+	},
+}).Parse(htmlReportTemplate))
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 960px; color: #222; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.3em; border-bottom: 1px solid #ddd; padding-bottom: .3em; margin-top: 2em; }
+h3 { font-size: 1.1em; margin-top: 1.5em; }
+table { border-collapse: collapse; width: 100%; margin: .5em 0; }
+th, td { text-align: left; padding: .35em .6em; border-bottom: 1px solid #eee; }
+td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+td.bar { width: 40%; }
+.track { background: #f0f0f0; height: 1em; }
+.fill { background: #4a90d9; height: 1em; }
+.fill.drop { background: #d9534f; }
+.fill.p95 { background: #f0ad4e; }
+.branch td:first-child { padding-left: 2em; color: #555; }
+.status { font-weight: bold; }
+.completed { color: #2e7d32; }
+.incomplete { color: #c62828; }
+.note { background: #fff8e1; padding: .5em; }
+dl { display: grid; grid-template-columns: max-content auto; gap: .2em 1em; }
+dt { font-weight: bold; }
+dd { margin: 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if gt (len .Funnels) 1}}{{if .Show "summary"}}
+<dl>
+<dt>Funnels Analyzed</dt><dd>{{len .Funnels}}</dd>
+<dt>Funnels Completed</dt><dd>{{.Completed}}</dd>
+</dl>
+{{- end}}{{end}}
+{{- if .Show "summary"}}{{range .Comparisons}}
+<h2>{{.FunnelName}}</h2>
+<table>
+<tr><th>Step</th>{{range .Labels}}<th class="num">{{.}}</th>{{end}}</tr>
+{{- range $i, $step := .Steps}}
+<tr><td>{{inc $i}}. {{$step.Name}}</td>{{range $j, $count := $step.EventCounts}}<td class="num">{{$count}} ({{pct (index $step.Percentages $j)}})</td>{{end}}</tr>
+{{- end}}
+<tr><td>Completed</td>{{range .Completed}}<td class="num status {{if .}}completed{{else}}incomplete{{end}}">{{if .}}Yes{{else}}No{{end}}</td>{{end}}</tr>
+</table>
+{{- end}}{{end}}
+{{- range .Funnels}}{{template "funnel" .}}{{end}}
+{{- range .Labels}}
+<h1>Label: {{.Label}}</h1>
+{{- range .Funnels}}{{template "funnel" .}}{{end}}
+{{- end}}
+{{- with .Count}}{{template "count" .}}{{end}}
+</body>
+</html>
+{{define "funnel"}}
+<section>
+<h2>{{.FunnelName}}</h2>
+{{- if eq .TotalEventsAnalyzed 0}}
+<p>No events found</p>
+{{- else}}
+{{- if .Show "summary"}}
+<dl>
+<dt>Status</dt><dd class="status {{if .FunnelCompleted}}completed{{else}}incomplete{{end}}">{{if .FunnelCompleted}}Completed{{else}}Not completed{{end}}</dd>
+<dt>Total Events Analyzed</dt><dd>{{.TotalEventsAnalyzed}}</dd>
+<dt>Entered Funnel</dt><dd>{{.EnteredFunnel}}</dd>
+<dt>Conversions Found</dt><dd>{{.ConversionsFound}}</dd>
+{{- with .Groups}}
+<dt>Grouped By</dt><dd>{{.GroupBy}} ({{.TotalGroups}} groups, {{.CompletedGroups}} completed)</dd>
+{{- if .UngroupedEntries}}
+<dt>Entries Without {{.GroupBy}}</dt><dd>{{.UngroupedEntries}}</dd>
+{{- end}}{{end}}
+</dl>
+{{- if .Partial}}
+<p class="note">Partial result: analysis was cancelled before all events were analyzed</p>
+{{- end}}{{end}}
+{{- if and .Steps (or (.Show "steps") (.Show "chart"))}}
+<h3>Steps</h3>
+<table>
+<tr><th>Step</th><th class="num">{{if .Groups}}Groups{{else}}Events{{end}}</th><th class="num">Conversion</th>{{if .Show "chart"}}<th></th>{{end}}</tr>
+{{- $view := .}}
+{{- range $i, $step := .Steps}}
+<tr><td>{{inc $i}}. {{$step.Name}}</td><td class="num">{{$step.EventCount}}</td><td class="num">{{pct $step.Percentage}}</td>{{if $view.Show "chart"}}<td class="bar"><div class="track"><div class="fill" style="width: {{bar $step.Percentage 100}}"></div></div></td>{{end}}</tr>
+{{- range $step.Branches}}
+<tr class="branch"><td>↳ {{.Name}}</td><td class="num">{{.EventCount}}</td><td class="num">{{pct .Percentage}}</td>{{if $view.Show "chart"}}<td></td>{{end}}</tr>
+{{- end}}{{end}}
+</table>
+{{- end}}
+{{- if .Show "steps"}}{{range .Steps}}{{if .Samples}}
+<h3>Samples: {{.Name}}</h3>
+<ul>
+{{- range .Samples}}
+<li>#{{.EntryIndex}} {{with .Timestamp}}{{timestamp .}} {{end}}<code>{{.Message}}</code></li>
+{{- end}}
+</ul>
+{{- end}}{{if .NearMisses}}
+<h3>Near misses: {{.Name}} ({{.NearMissCount}})</h3>
+<ul>
+{{- range .NearMisses}}
+<li>#{{.EntryIndex}} {{with .Timestamp}}{{timestamp .}} {{end}}<code>{{.Message}}</code>: {{.Reason}}</li>
+{{- end}}
+</ul>
+{{- end}}{{end}}{{end}}
+{{- if and .DropOffs (.Show "drop_offs")}}
+<h3>Drop-offs</h3>
+<table>
+<tr><th>From</th><th>To</th><th class="num">{{if .Groups}}Groups{{else}}Events{{end}} lost</th><th class="num">Drop-off</th><th></th></tr>
+{{- range .DropOffs}}
+<tr><td>{{.From}}</td><td>{{.To}}</td><td class="num">{{.EventsLost}}</td><td class="num">{{pct .DropOffRate}}</td><td class="bar"><div class="track"><div class="fill drop" style="width: {{bar .DropOffRate 100}}"></div></div></td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if and .Anomalies (.Show "anomalies")}}
+<h3>Anomalies</h3>
+<ul>
+{{- range .Anomalies}}
+<li>{{.Step}}: fired {{.Occurrences}} times in one attempt (limit {{.Limit}}, entry {{.EntryIndex}})</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if and .Exclusions (.Show "exclusions")}}
+<h3>Exclusions: {{.AbortedAttempts}} attempts aborted</h3>
+<ul>
+{{- range .Exclusions}}
+<li>{{.Step}}: {{.AbortedAttempts}} attempts aborted by <code>/{{.Pattern}}/</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if and .Timings (.Show "timings")}}
+<h3>Time to Convert (from first step)</h3>
+<table>
+<tr><th>Step</th><th class="num">n</th><th class="num">Min</th><th class="num">Median</th><th class="num">p95</th><th class="num">Max</th><th>Median / p95</th></tr>
+{{- $funnel := .}}
+{{- range .Timings}}
+<tr><td>{{.Step}}{{if eq .Step $funnel.LastStep}} (total){{end}}</td><td class="num">{{.Samples}}</td><td class="num">{{seconds .MinSeconds}}</td><td class="num">{{seconds .MedianSeconds}}</td><td class="num">{{seconds .P95Seconds}}</td><td class="num">{{seconds .MaxSeconds}}</td><td class="bar"><div class="track"><div class="fill" style="width: {{bar .MedianSeconds $funnel.MaxSeconds}}"></div></div><div class="track"><div class="fill p95" style="width: {{bar .P95Seconds $funnel.MaxSeconds}}"></div></div></td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if and .Conversions (index .Conversions 0).Steps (.Show "conversions")}}
+<h3>Conversions</h3>
+<ol>
+{{- range .Conversions}}
+<li>{{with .Group}}{{.}}: {{end}}entries {{.StartEntry}} → {{.EndEntry}}{{if .DurationSeconds}} ({{seconds .DurationSeconds}}){{end}}
+<ul>
+{{- range .Steps}}
+<li>{{.Step}}: #{{.EntryIndex}} {{with .Timestamp}}{{timestamp .}} {{end}}<code>{{.Message}}</code></li>
+{{- end}}
+</ul></li>
+{{- end}}
+</ol>
+{{- end}}
+{{- if and .Assertions (.Show "assertions")}}
+<h3>Assertions</h3>
+<ul>
+{{- range .Assertions}}
+<li class="status {{if .Passed}}completed{{else}}incomplete{{end}}">{{if .Passed}}Passed{{else}}Failed{{end}}: {{.String}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+</section>
+{{- end}}
+{{define "count"}}
+<section>
+{{- if eq .TotalEvents 0}}
+<p>No events found</p>
+{{- else}}
+{{- if .Show "summary"}}
+<dl>
+<dt>Total Events Analyzed</dt><dd>{{.TotalEvents}}</dd>
+{{- with .FirstTimestamp}}
+<dt>First Timestamp</dt><dd>{{timestamp .}}</dd>
+<dt>Last Timestamp</dt><dd>{{timestamp $.LastTimestamp}}</dd>
+<dt>Duration</dt><dd>{{seconds $.DurationSeconds}}</dd>
+{{- end}}
+{{- if .DurationSeconds}}
+<dt>Events per Minute</dt><dd>{{printf "%.2f" .EventsPerMinute}}</dd>
+{{- end}}
+</dl>
+{{- if .Partial}}
+<p class="note">Partial result: analysis was cancelled before all events were analyzed</p>
+{{- end}}{{end}}
+{{- if and .PatternCounts (.Show "counts")}}
+<h2>Pattern Counts</h2>
+<table>
+<tr><th>Pattern</th><th class="num">Matches</th><th class="num">Share of events</th>{{if .DurationSeconds}}<th class="num">Per minute</th>{{end}}<th></th></tr>
+{{- $count := .}}
+{{- range $i, $pattern := .PatternCounts}}
+<tr><td>{{inc $i}}. <code>{{$pattern.Pattern}}</code></td><td class="num">{{$pattern.Count}}</td><td class="num">{{share $pattern.Count $count.TotalEvents}}</td>{{if $count.DurationSeconds}}<td class="num">{{printf "%.2f" $pattern.PerMinute}}</td>{{end}}<td class="bar"><div class="track"><div class="fill" style="width: {{bar (float $pattern.Count) (float $count.MaxCount)}}"></div></div></td></tr>
+{{- end}}
+<tr><th>Total Matches</th><th class="num">{{.TotalMatches}}</th><th></th>{{if .DurationSeconds}}<th></th>{{end}}<th></th></tr>
+</table>
+{{- end}}
+{{- if and .OverlappingEntries (.Show "overlaps")}}
+<p class="note">{{.OverlappingEntries}} entries matched more than one pattern, percentages overlap</p>
+<ul>
+{{- range .Overlaps}}
+<li>{{range $i, $p := .Patterns}}{{if $i}} &amp; {{end}}<code>{{$p}}</code>{{end}}: {{.Count}} shared matches</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if and .Groups .PatternCounts (.Show "groups")}}
+<h2>Matches by {{.Groups.GroupBy}}</h2>
+<table>
+<tr><th>{{.Groups.GroupBy}}</th>{{range .PatternCounts}}<th class="num"><code>{{.Pattern}}</code></th>{{end}}<th class="num">Total</th></tr>
+{{- range .Groups.Groups}}
+<tr><td>{{.Value}}</td>{{range .Counts}}<td class="num">{{.}}</td>{{end}}<td class="num">{{.Total}}</td></tr>
+{{- end}}
+</table>
+{{- if .Groups.UngroupedMatches}}
+<p>Without {{.Groups.GroupBy}}: {{.Groups.UngroupedMatches}} matching entries</p>
+{{- end}}{{end}}
+{{- if and .Sessions (.Show "sessions")}}
+<h2>Sessions by {{.Sessions.SessionKey}}: {{.Sessions.SessionCount}}</h2>
+<table>
+<tr><th>Pattern</th><th class="num">Sessions</th><th class="num">Share</th><th class="num">Per session</th><th class="num">Per matching session</th></tr>
+{{- range .Sessions.Patterns}}
+<tr><td><code>{{.Pattern}}</code></td><td class="num">{{.Sessions}}</td><td class="num">{{pct .Percentage}}</td><td class="num">{{printf "%.2f" .AvgPerSession}}</td><td class="num">{{printf "%.2f" .AvgPerMatchingSession}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if and .Distinct (.Show "distinct")}}
+<h2>Distinct {{.Distinct.Property}}</h2>
+<table>
+<tr><th>Pattern</th><th class="num">Values</th><th class="num">Matches without {{.Distinct.Property}}</th><th>Top values</th></tr>
+{{- range .Distinct.Patterns}}
+<tr><td><code>{{.Pattern}}</code></td><td class="num">{{.Values}}</td><td class="num">{{.MissingEntries}}</td><td>{{range $i, $v := .Top}}{{if $i}}, {{end}}{{$v.Value}} ({{$v.Count}}){{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if and .Aggregate (.Show "aggregate")}}
+<h2>Aggregate {{.Aggregate.Property}}</h2>
+<table>
+<tr><th>Pattern</th><th class="num">Values</th><th class="num">Sum</th><th class="num">Avg</th><th class="num">Min</th><th class="num">Max</th><th class="num">Matches without {{.Aggregate.Property}}</th></tr>
+{{- range .Aggregate.Patterns}}
+<tr><td><code>{{.Pattern}}</code></td><td class="num">{{.Values}}</td><td class="num">{{number .Sum}}</td><td class="num">{{number .Avg}}</td><td class="num">{{number .Min}}</td><td class="num">{{number .Max}}</td><td class="num">{{.MissingEntries}}</td></tr>
+{{- end}}
+</table>
+{{- if and .Aggregate.GroupBy .Aggregate.Patterns}}
+<h2>Sum of {{.Aggregate.Property}} by {{.Aggregate.GroupBy}}</h2>
+<table>
+<tr><th>{{.Aggregate.GroupBy}}</th>{{range .Aggregate.Patterns}}<th class="num"><code>{{.Pattern}}</code></th>{{end}}<th class="num">Total</th></tr>
+{{- range .Aggregate.Groups}}
+<tr><td>{{.Value}}</td>{{range .Patterns}}<td class="num">{{number .Sum}}</td>{{end}}<td class="num">{{number .Sum}}</td></tr>
+{{- end}}
+</table>
+{{- end}}{{end}}
+{{- end}}
+</section>
+{{- end}}`
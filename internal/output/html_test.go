@@ -0,0 +1,282 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+)
+
+func TestHTMLFormatter_FormatFunnel(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Checkout <v2>",
+		TotalEventsAnalyzed: 100,
+		FunnelCompleted:     true,
+		Steps: []analyzer.StepResult{
+			{Name: "View", EventCount: 10, Percentage: 100},
+			{Name: "Purchase", EventCount: 4, Percentage: 40, Branches: []analyzer.BranchResult{
+				{Name: "card", EventCount: 3, Percentage: 75},
+			}, Samples: []analyzer.StepSample{
+				{EntryIndex: 42, Message: "purchase <card>"},
+			}},
+		},
+		DropOffs: []analyzer.DropOff{
+			{From: "View", To: "Purchase", EventsLost: 6, DropOffRate: 60},
+		},
+		Timings: []analyzer.StepTiming{
+			{Step: "Purchase", Samples: 4, MinSeconds: 1, MedianSeconds: 2, P95Seconds: 3, MaxSeconds: 4},
+		},
+	}
+
+	html, err := (&HTMLFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"<!DOCTYPE html>",
+		"<title>Funnel Report: Checkout &lt;v2&gt;</title>",
+		`<dd class="status completed">Completed</dd>`,
+		`<tr><td>2. Purchase</td><td class="num">4</td><td class="num">40.0%</td>`,
+		`<div class="fill" style="width: 40.0%">`,
+		`<tr class="branch"><td>↳ card</td>`,
+		`<td class="num">6</td><td class="num">60.0%</td>`,
+		`<div class="fill drop" style="width: 60.0%">`,
+		"<h3>Samples: Purchase</h3>",
+		"<li>#42 <code>purchase &lt;card&gt;</code></li>",
+		"<td>Purchase (total)</td>",
+		`<div class="fill" style="width: 50.0%">`,
+		"</html>",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(html, fragment) {
+			t.Errorf("FormatFunnel() should contain %q, got:\n%s", fragment, html)
+		}
+	}
+
+	// The report is self-contained
+	for _, external := range []string{"<script", "<link", "src="} {
+		if strings.Contains(html, external) {
+			t.Errorf("FormatFunnel() should not reference external resources, found %q", external)
+		}
+	}
+}
+
+func TestHTMLFormatter_FormatFunnel_NearMisses(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Test",
+		TotalEventsAnalyzed: 10,
+		Steps: []analyzer.StepResult{
+			{Name: "A", EventCount: 1, Percentage: 100},
+			{Name: "B", NearMissCount: 1, NearMisses: []analyzer.NearMiss{
+				{StepSample: analyzer.StepSample{EntryIndex: 5, Message: "<b>"}, Reason: "required property 'id' is missing"},
+			}},
+		},
+	}
+
+	html, err := (&HTMLFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	for _, fragment := range []string{
+		"<h3>Near misses: B (1)</h3>",
+		"<li>#5 <code>&lt;b&gt;</code>: required property &#39;id&#39; is missing</li>",
+	} {
+		if !strings.Contains(html, fragment) {
+			t.Errorf("FormatFunnel() should contain %q, got:\n%s", fragment, html)
+		}
+	}
+}
+
+func TestHTMLFormatter_FormatFunnel_ConversionSteps(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Test",
+		TotalEventsAnalyzed: 10,
+		FunnelCompleted:     true,
+		ConversionsFound:    1,
+		Steps:               []analyzer.StepResult{{Name: "A", EventCount: 1}, {Name: "B", EventCount: 1}},
+		Conversions: []analyzer.FunnelConversion{{Number: 1, Group: "alice", StartEntry: 2, EndEntry: 7, Steps: []analyzer.ConversionStep{
+			{Step: "A", StepSample: analyzer.StepSample{EntryIndex: 2, Message: "a"}},
+			{Step: "B", StepSample: analyzer.StepSample{EntryIndex: 7, Message: "<b>"}},
+		}}},
+	}
+
+	html, err := (&HTMLFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	for _, fragment := range []string{
+		"<dt>Conversions Found</dt><dd>1</dd>",
+		"<h3>Conversions</h3>",
+		"<li>alice: entries 2 → 7\n<ul>",
+		"<li>B: #7 <code>&lt;b&gt;</code></li>",
+	} {
+		if !strings.Contains(html, fragment) {
+			t.Errorf("FormatFunnel() should contain %q, got:\n%s", fragment, html)
+		}
+	}
+
+	// Without steps only the count is reported
+	result.Conversions[0].Steps = nil
+	html, err = (&HTMLFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	if strings.Contains(html, "<h3>Conversions</h3>") {
+		t.Errorf("FormatFunnel() should not list conversions without steps, got:\n%s", html)
+	}
+}
+
+func TestHTMLFormatter_FormatFunnel_Assertions(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Test",
+		TotalEventsAnalyzed: 10,
+		Steps:               []analyzer.StepResult{{Name: "A", EventCount: 1, Percentage: 100}},
+		Assertions: []analyzer.Assertion{
+			{Step: "A", Threshold: analyzer.ThresholdMinCount, Expected: 3, Actual: 1},
+		},
+	}
+
+	html, err := (&HTMLFormatter{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	expected := `<li class="status incomplete">Failed: A: min_count 3, got 1</li>`
+	if !strings.Contains(html, "<h3>Assertions</h3>") || !strings.Contains(html, expected) {
+		t.Errorf("FormatFunnel() should contain %q, got:\n%s", expected, html)
+	}
+}
+
+func TestHTMLFormatter_Sections(t *testing.T) {
+	result := &analyzer.FunnelResult{
+		FunnelName:          "Test",
+		TotalEventsAnalyzed: 10,
+		Steps:               []analyzer.StepResult{{Name: "A", EventCount: 1, Percentage: 100}},
+		DropOffs:            []analyzer.DropOff{{From: "A", To: "B", EventsLost: 1, DropOffRate: 100}},
+		Timings:             []analyzer.StepTiming{{Step: "B", Samples: 1}},
+	}
+
+	html, err := (&HTMLFormatter{options: Options{Hide: []string{SectionDropOffs, SectionTimings}}}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	if !strings.Contains(html, "<h3>Steps</h3>") {
+		t.Errorf("Expected steps section, got:\n%s", html)
+	}
+	for _, hidden := range []string{"Drop-offs", "Time to Convert"} {
+		if strings.Contains(html, hidden) {
+			t.Errorf("Expected %q to be hidden, got:\n%s", hidden, html)
+		}
+	}
+}
+
+func TestHTMLFormatter_FormatFunnels(t *testing.T) {
+	results := []*analyzer.FunnelResult{
+		{FunnelName: "First", TotalEventsAnalyzed: 1, FunnelCompleted: true},
+		{FunnelName: "Second", TotalEventsAnalyzed: 0},
+	}
+
+	html, err := (&HTMLFormatter{}).FormatFunnels(results)
+	if err != nil {
+		t.Fatalf("FormatFunnels() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"<h1>Multi-Funnel Report</h1>",
+		"<dt>Funnels Analyzed</dt><dd>2</dd>",
+		"<dt>Funnels Completed</dt><dd>1</dd>",
+		"<h2>First</h2>",
+		"<h2>Second</h2>\n<p>No events found</p>",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(html, fragment) {
+			t.Errorf("FormatFunnels() should contain %q, got:\n%s", fragment, html)
+		}
+	}
+}
+
+func TestHTMLFormatter_FormatCount(t *testing.T) {
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 20,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: "login", Count: 10},
+			{Pattern: "a&b", Count: 5},
+		},
+		OverlappingEntries: 2,
+		Overlaps:           []analyzer.PatternOverlap{{Patterns: []string{"login", "a&b"}, Count: 2}},
+	}
+
+	html, err := (&HTMLFormatter{}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"<h1>Event Count Report</h1>",
+		"<dt>Total Events Analyzed</dt><dd>20</dd>",
+		`<td>1. <code>login</code></td><td class="num">10</td><td class="num">50.0%</td>`,
+		`<td>2. <code>a&amp;b</code></td><td class="num">5</td><td class="num">25.0%</td>`,
+		`<div class="fill" style="width: 50.0%">`,
+		`<tr><th>Total Matches</th><th class="num">15</th>`,
+		"2 entries matched more than one pattern",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(html, fragment) {
+			t.Errorf("FormatCount() should contain %q, got:\n%s", fragment, html)
+		}
+	}
+}
+
+func TestHTMLFormatter_FormatLabeled(t *testing.T) {
+	report := analyzer.NewLabeledReport([]analyzer.LabeledResult{
+		{Label: "device=pixel7", Funnels: []*analyzer.FunnelResult{{
+			FunnelName: "Checkout", TotalEventsAnalyzed: 5, FunnelCompleted: true,
+			Steps: []analyzer.StepResult{{Name: "View", EventCount: 5, Percentage: 100}},
+		}}},
+		{Label: "device=s23", Funnels: []*analyzer.FunnelResult{{
+			FunnelName: "Checkout", TotalEventsAnalyzed: 3,
+			Steps: []analyzer.StepResult{{Name: "View", EventCount: 0, Percentage: 0}},
+		}}},
+	})
+
+	html, err := (&HTMLFormatter{}).FormatLabeled(report)
+	if err != nil {
+		t.Fatalf("FormatLabeled() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"<h1>Comparison by Label</h1>",
+		`<tr><th>Step</th><th class="num">device=pixel7</th><th class="num">device=s23</th></tr>`,
+		`<tr><td>1. View</td><td class="num">5 (100.0%)</td><td class="num">0 (0.0%)</td></tr>`,
+		`<td class="num status completed">Yes</td><td class="num status incomplete">No</td>`,
+		"<h1>Label: device=pixel7</h1>",
+		"<h1>Label: device=s23</h1>",
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(html, fragment) {
+			t.Errorf("FormatLabeled() should contain %q, got:\n%s", fragment, html)
+		}
+	}
+}
+
+func TestHTMLFormatter_Unsupported(t *testing.T) {
+	formatter := &HTMLFormatter{}
+	if _, err := formatter.FormatSessions(&analyzer.SessionResult{}); err == nil {
+		t.Error("Expected error for session statistics")
+	}
+	if _, err := formatter.FormatTop(&analyzer.TopResult{}); err == nil {
+		t.Error("Expected error for top events")
+	}
+	if _, err := formatter.FormatTimeline(&analyzer.TimelineResult{}); err == nil {
+		t.Error("Expected error for timelines")
+	}
+	if _, err := formatter.FormatOverview(&analyzer.OverviewResult{}); err == nil {
+		t.Error("Expected error for log overviews")
+	}
+	if _, err := formatter.FormatExtract(&analyzer.ExtractResult{}); err == nil {
+		t.Error("Expected error for extracted entries")
+	}
+	if _, err := formatter.FormatDiff(&analyzer.DiffReport{}); err == nil {
+		t.Error("Expected error for funnel diffs")
+	}
+}
@@ -0,0 +1,248 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+)
+
+// Output sections that can be selected with --only or removed with --hide
+const (
+	SectionSummary     = "summary"
+	SectionSteps       = "steps"
+	SectionChart       = "chart"
+	SectionDropOffs    = "drop_offs"
+	SectionAnomalies   = "anomalies"
+	SectionExclusions  = "exclusions"
+	SectionTimings     = "timings"
+	SectionAssertions  = "assertions"
+	SectionCounts      = "counts"
+	SectionOverlaps    = "overlaps"
+	SectionGroups      = "groups"
+	SectionSessions    = "sessions"
+	SectionDistinct    = "distinct"
+	SectionAggregate   = "aggregate"
+	SectionConversions = "conversions"
+)
+
+// HideZeroCount removes steps and patterns without any matches from the output
+const HideZeroCount = "zero-count"
+
+var knownSections = []string{
+	SectionSummary,
+	SectionSteps,
+	SectionChart,
+	SectionDropOffs,
+	SectionAnomalies,
+	SectionExclusions,
+	SectionTimings,
+	SectionAssertions,
+	SectionCounts,
+	SectionOverlaps,
+	SectionGroups,
+	SectionSessions,
+	SectionDistinct,
+	SectionAggregate,
+	SectionConversions,
+}
+
+// Options controls which parts of a result are rendered by a formatter.
+// The zero value renders everything.
+type Options struct {
+	// Only restricts output to the listed sections
+	Only []string
+	// Hide removes the listed sections, or zero-count rows with HideZeroCount
+	Hide []string
+	// MaxNameWidth truncates longer step and pattern names in text output with
+	// an ellipsis. Zero keeps names untruncated.
+	MaxNameWidth int
+	// Table renders step and pattern breakdowns of text output as tables
+	// with aligned columns
+	Table bool
+	// Borders frames tables with unicode box drawing borders
+	Borders bool
+	// Wide adds timestamp and duration columns to tables
+	Wide bool
+	// TemplateFile is the text/template file rendering results in the
+	// template output format
+	TemplateFile string
+	// ASCII replaces emoji and symbols in text output with plain ASCII and
+	// PASS/FAIL wording, see ASCII
+	ASCII bool
+}
+
+// Validate checks that all section names are known
+func (o Options) Validate() error {
+	for _, section := range o.Only {
+		if !slices.Contains(knownSections, section) {
+			return fmt.Errorf("unknown output section '%s' (valid: %v)", section, knownSections)
+		}
+	}
+
+	for _, section := range o.Hide {
+		if section != HideZeroCount && !slices.Contains(knownSections, section) {
+			return fmt.Errorf("unknown output section '%s' (valid: %v, %s)", section, knownSections, HideZeroCount)
+		}
+	}
+
+	if o.MaxNameWidth < 0 {
+		return fmt.Errorf("max name width cannot be negative")
+	}
+
+	if o.TemplateFile != "" {
+		if _, err := parseTemplate(o.TemplateFile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// truncateName shortens name to MaxNameWidth characters, ending it with an
+// ellipsis when it was cut
+func (o Options) truncateName(name string) string {
+	runes := []rune(name)
+	if o.MaxNameWidth <= 0 || len(runes) <= o.MaxNameWidth {
+		return name
+	}
+	return string(runes[:o.MaxNameWidth-1]) + "…"
+}
+
+// tables reports whether breakdowns are rendered as tables, which borders
+// and wide columns imply
+func (o Options) tables() bool {
+	return o.Table || o.Borders || o.Wide
+}
+
+func (o Options) showSection(section string) bool {
+	if len(o.Only) > 0 && !slices.Contains(o.Only, section) {
+		return false
+	}
+	return !slices.Contains(o.Hide, section)
+}
+
+func (o Options) hideZeroCount() bool {
+	return slices.Contains(o.Hide, HideZeroCount)
+}
+
+func (o Options) filtersSections() bool {
+	for _, section := range o.Hide {
+		if section != HideZeroCount {
+			return true
+		}
+	}
+	return len(o.Only) > 0
+}
+
+// filterFunnel returns a copy of result with zero-count steps removed if requested
+func (o Options) filterFunnel(result *analyzer.FunnelResult) *analyzer.FunnelResult {
+	if result == nil || !o.hideZeroCount() {
+		return result
+	}
+
+	filtered := *result
+	filtered.Steps = []analyzer.StepResult{}
+	for _, step := range result.Steps {
+		if step.EventCount > 0 {
+			filtered.Steps = append(filtered.Steps, step)
+		}
+	}
+	return &filtered
+}
+
+// filterCount returns a copy of result with zero-count patterns removed if requested
+func (o Options) filterCount(result *analyzer.CountResult) *analyzer.CountResult {
+	if result == nil || !o.hideZeroCount() {
+		return result
+	}
+
+	filtered := *result
+	filtered.PatternCounts = []analyzer.PatternCount{}
+	var kept []int
+	for i, patternCount := range result.PatternCounts {
+		if patternCount.Count > 0 {
+			filtered.PatternCounts = append(filtered.PatternCounts, patternCount)
+			kept = append(kept, i)
+		}
+	}
+
+	if result.Groups != nil {
+		groups := *result.Groups
+		groups.Groups = make([]analyzer.GroupCount, len(result.Groups.Groups))
+		for i, group := range result.Groups.Groups {
+			counts := make([]int, len(kept))
+			for j, patternIndex := range kept {
+				counts[j] = group.Counts[patternIndex]
+			}
+			group.Counts = counts
+			groups.Groups[i] = group
+		}
+		filtered.Groups = &groups
+	}
+
+	if result.Sessions != nil {
+		sessions := *result.Sessions
+		sessions.Patterns = []analyzer.SessionPatternCount{}
+		for _, patternCount := range result.Sessions.Patterns {
+			if patternCount.Sessions > 0 {
+				sessions.Patterns = append(sessions.Patterns, patternCount)
+			}
+		}
+		filtered.Sessions = &sessions
+	}
+
+	if result.Distinct != nil {
+		distinct := *result.Distinct
+		distinct.Patterns = make([]analyzer.DistinctPatternCount, len(kept))
+		for j, patternIndex := range kept {
+			distinct.Patterns[j] = result.Distinct.Patterns[patternIndex]
+		}
+		filtered.Distinct = &distinct
+	}
+
+	if result.Aggregate != nil {
+		aggregate := *result.Aggregate
+		keep := func(aggregates []analyzer.PropertyAggregate) []analyzer.PropertyAggregate {
+			projected := make([]analyzer.PropertyAggregate, len(kept))
+			for j, patternIndex := range kept {
+				projected[j] = aggregates[patternIndex]
+			}
+			return projected
+		}
+		aggregate.Patterns = keep(result.Aggregate.Patterns)
+		aggregate.Groups = make([]analyzer.AggregateGroup, len(result.Aggregate.Groups))
+		for i, group := range result.Aggregate.Groups {
+			group.Patterns = keep(group.Patterns)
+			aggregate.Groups[i] = group
+		}
+		filtered.Aggregate = &aggregate
+	}
+	return &filtered
+}
+
+// selectJSONSections drops top-level JSON keys belonging to hidden sections.
+// Keys not listed in sectionKeys are treated as part of the summary.
+func (o Options) selectJSONSections(data []byte, sectionKeys map[string]string) ([]byte, error) {
+	if !o.filtersSections() || string(data) == "null" {
+		return data, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for key := range fields {
+		section, ok := sectionKeys[key]
+		if !ok {
+			section = SectionSummary
+		}
+		if !o.showSection(section) {
+			delete(fields, key)
+		}
+	}
+
+	return json.MarshalIndent(fields, "", "  ")
+}
@@ -0,0 +1,197 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+)
+
+func testFunnelResult() *analyzer.FunnelResult {
+	return &analyzer.FunnelResult{
+		FunnelName:          "Checkout",
+		TotalEventsAnalyzed: 20,
+		FunnelCompleted:     false,
+		Steps: []analyzer.StepResult{
+			{Name: "View", EventCount: 10, Percentage: 100.0},
+			{Name: "Cart", EventCount: 5, Percentage: 50.0},
+			{Name: "Pay", EventCount: 0, Percentage: 0.0},
+		},
+		DropOffs: []analyzer.DropOff{
+			{From: "View", To: "Cart", EventsLost: 5, DropOffRate: 50.0},
+			{From: "Cart", To: "Pay", EventsLost: 5, DropOffRate: 100.0},
+		},
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     Options
+		expectError bool
+	}{
+		{name: "empty", options: Options{}},
+		{name: "known_sections", options: Options{Only: []string{"steps", "drop_offs"}, Hide: []string{"chart"}}},
+		{name: "zero_count", options: Options{Hide: []string{"zero-count"}}},
+		{name: "zero_count_in_only", options: Options{Only: []string{"zero-count"}}, expectError: true},
+		{name: "unknown_section", options: Options{Hide: []string{"footer"}}, expectError: true},
+		{name: "max_name_width", options: Options{MaxNameWidth: 20}},
+		{name: "negative_max_name_width", options: Options{MaxNameWidth: -1}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if tt.expectError && err == nil {
+				t.Error("Validate() expected error")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTextFormatter_OnlySteps(t *testing.T) {
+	formatter := NewFormatterWithOptions(TextFormat, Options{Only: []string{SectionSteps}})
+
+	output, err := formatter.FormatFunnel(testFunnelResult())
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(output, "Step Breakdown:\n") {
+		t.Errorf("Output should start with step breakdown, got:\n%s", output)
+	}
+	for _, unexpected := range []string{"Funnel Analysis Complete", "Funnel Chart:", "Drop-off Analysis:"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Output should not contain %q, got:\n%s", unexpected, output)
+		}
+	}
+}
+
+func TestTextFormatter_OnlyDropOffsHideZeroCount(t *testing.T) {
+	formatter := NewFormatterWithOptions(TextFormat, Options{
+		Only: []string{SectionSteps, SectionDropOffs},
+		Hide: []string{HideZeroCount},
+	})
+
+	output, err := formatter.FormatFunnel(testFunnelResult())
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	if strings.Contains(output, "Pay: 0 events") {
+		t.Errorf("Zero-count step should be hidden, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Cart → Pay: 5 events lost") {
+		t.Errorf("Drop-offs should still be shown, got:\n%s", output)
+	}
+}
+
+func TestTextFormatter_CountHideZeroCount(t *testing.T) {
+	formatter := NewFormatterWithOptions(TextFormat, Options{Hide: []string{HideZeroCount, SectionSummary}})
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: "login", Count: 4},
+			{Pattern: "crash", Count: 0},
+		},
+	}
+
+	output, err := formatter.FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(output, "Pattern Counts:\n1. login: 4 matches (40.0%)") {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+	if strings.Contains(output, "crash") {
+		t.Errorf("Zero-count pattern should be hidden, got:\n%s", output)
+	}
+}
+
+func TestOptions_TruncateName(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		input string
+		want  string
+	}{
+		{name: "no_limit", width: 0, input: "checkout_started", want: "checkout_started"},
+		{name: "fits", width: 16, input: "checkout_started", want: "checkout_started"},
+		{name: "truncated", width: 10, input: "checkout_started", want: "checkout_…"},
+		{name: "multibyte", width: 4, input: "ввод данных", want: "вво…"},
+		{name: "single_char", width: 1, input: "checkout", want: "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Options{MaxNameWidth: tt.width}.truncateName(tt.input)
+			if got != tt.want {
+				t.Errorf("truncateName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatter_MaxNameWidth(t *testing.T) {
+	longPattern := "user_(signed_in|signed_up|logged_in_with_google|logged_in_with_apple)"
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: longPattern, Count: 4},
+		},
+	}
+
+	text, err := NewFormatterWithOptions(TextFormat, Options{MaxNameWidth: 12}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "1. user_(signe…: 4 matches") {
+		t.Errorf("Text output should truncate the pattern, got:\n%s", text)
+	}
+
+	jsonOutput, err := NewFormatterWithOptions(JSONFormat, Options{MaxNameWidth: 12}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+	var decoded analyzer.CountResult
+	if err := json.Unmarshal([]byte(jsonOutput), &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON output: %v", err)
+	}
+	if decoded.PatternCounts[0].Pattern != longPattern {
+		t.Errorf("JSON output should keep the full pattern, got %q", decoded.PatternCounts[0].Pattern)
+	}
+}
+
+func TestJSONFormatter_SectionFilters(t *testing.T) {
+	formatter := NewFormatterWithOptions(JSONFormat, Options{
+		Only: []string{SectionSteps},
+		Hide: []string{HideZeroCount},
+	})
+
+	output, err := formatter.FormatFunnel(testFunnelResult())
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	var parsed map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+
+	if len(parsed) != 1 {
+		t.Errorf("Expected only the steps key, got: %s", output)
+	}
+
+	var steps []analyzer.StepResult
+	if err := json.Unmarshal(parsed["steps"], &steps); err != nil {
+		t.Fatalf("Steps are not valid JSON: %v", err)
+	}
+	if len(steps) != 2 {
+		t.Errorf("Expected 2 non-zero steps, got %d", len(steps))
+	}
+}
@@ -0,0 +1,153 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/sirupsen/logrus"
+)
+
+// SankeyFormatter renders funnels as Sankey diagram data: a node per step
+// and per drop-off, with links from each step to the next step and to its
+// drop-off weighted by the attempts that took them. Links refer to nodes by
+// index, as expected by d3-sankey in Observable and by ECharts.
+type SankeyFormatter struct {
+	options Options
+}
+
+// sankeyNode is a step of a funnel or the attempts that dropped off after it
+type sankeyNode struct {
+	Name string `json:"name"`
+	// Kind is "step" or "drop_off"
+	Kind string `json:"kind"`
+}
+
+type sankeyLink struct {
+	Source int `json:"source"`
+	Target int `json:"target"`
+	Value  int `json:"value"`
+}
+
+type sankeyFunnel struct {
+	FunnelName string       `json:"funnel_name"`
+	Nodes      []sankeyNode `json:"nodes"`
+	Links      []sankeyLink `json:"links"`
+}
+
+type sankeyLabel struct {
+	Label   string         `json:"label"`
+	Funnels []sankeyFunnel `json:"funnels"`
+}
+
+// newSankeyFunnel returns the nodes and links of a funnel. Drop-off nodes
+// are added after all step nodes, so step i is node i.
+func newSankeyFunnel(result *analyzer.FunnelResult) sankeyFunnel {
+	funnel := sankeyFunnel{FunnelName: result.FunnelName, Nodes: []sankeyNode{}, Links: []sankeyLink{}}
+	steps := map[string]int{}
+	for i, step := range result.Steps {
+		funnel.Nodes = append(funnel.Nodes, sankeyNode{Name: step.Name, Kind: "step"})
+		steps[step.Name] = i
+		if i > 0 && step.EventCount > 0 {
+			funnel.Links = append(funnel.Links, sankeyLink{Source: i - 1, Target: i, Value: step.EventCount})
+		}
+	}
+
+	for _, dropOff := range result.DropOffs {
+		source, exists := steps[dropOff.From]
+		if !exists || dropOff.EventsLost <= 0 {
+			continue
+		}
+		funnel.Nodes = append(funnel.Nodes, sankeyNode{Name: fmt.Sprintf("Dropped after %s", dropOff.From), Kind: "drop_off"})
+		funnel.Links = append(funnel.Links, sankeyLink{Source: source, Target: len(funnel.Nodes) - 1, Value: dropOff.EventsLost})
+	}
+	return funnel
+}
+
+func marshalSankey(value interface{}) (string, error) {
+	jsonData, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return string(jsonData), nil
+}
+
+func (f *SankeyFormatter) FormatFunnel(result *analyzer.FunnelResult) (string, error) {
+	logrus.WithField("funnel_name", result.FunnelName).Debug("Formatting funnel result as Sankey data")
+	return marshalSankey(newSankeyFunnel(result))
+}
+
+func (f *SankeyFormatter) FormatFunnels(results []*analyzer.FunnelResult) (string, error) {
+	report := struct {
+		Funnels []sankeyFunnel `json:"funnels"`
+	}{Funnels: make([]sankeyFunnel, len(results))}
+	for i, result := range results {
+		report.Funnels[i] = newSankeyFunnel(result)
+	}
+	return marshalSankey(report)
+}
+
+func (f *SankeyFormatter) FormatLabeled(report *analyzer.LabeledReport) (string, error) {
+	labeled := struct {
+		Labels []sankeyLabel `json:"labels"`
+	}{Labels: make([]sankeyLabel, len(report.Labels))}
+	for i, label := range report.Labels {
+		labeled.Labels[i] = sankeyLabel{Label: label.Label, Funnels: make([]sankeyFunnel, len(label.Funnels))}
+		for j, result := range label.Funnels {
+			labeled.Labels[i].Funnels[j] = newSankeyFunnel(result)
+		}
+	}
+	return marshalSankey(labeled)
+}
+
+func (f *SankeyFormatter) FormatCount(result *analyzer.CountResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for event counts")
+}
+
+func (f *SankeyFormatter) FormatSessions(result *analyzer.SessionResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for session statistics")
+}
+
+func (f *SankeyFormatter) FormatTop(result *analyzer.TopResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for top events")
+}
+
+func (f *SankeyFormatter) FormatTimeline(result *analyzer.TimelineResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for timelines")
+}
+
+func (f *SankeyFormatter) FormatOverview(result *analyzer.OverviewResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for log overviews")
+}
+
+func (f *SankeyFormatter) FormatExtract(result *analyzer.ExtractResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for extracted entries")
+}
+
+func (f *SankeyFormatter) FormatDiff(report *analyzer.DiffReport) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for funnel diffs")
+}
+
+func (f *SankeyFormatter) FormatCooccurrence(result *analyzer.CooccurrenceResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for co-occurrence")
+}
+
+func (f *SankeyFormatter) FormatDiscover(result *analyzer.DiscoverResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for sequence discovery")
+}
+
+func (f *SankeyFormatter) FormatGaps(result *analyzer.GapResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for gaps")
+}
+
+func (f *SankeyFormatter) FormatAnomalies(result *analyzer.AnomalyResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for anomalies")
+}
+
+func (f *SankeyFormatter) FormatRetention(result *analyzer.RetentionResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for retention")
+}
+
+func (f *SankeyFormatter) FormatAudit(result *analyzer.AuditResult) (string, error) {
+	return "", fmt.Errorf("sankey output is not supported for event audits")
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+)
+
+func TestSankeyFormatter_FormatFunnel(t *testing.T) {
+	formatter := NewFormatter(SankeyFormat)
+	output, err := formatter.FormatFunnel(testFunnelResult())
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	var funnel sankeyFunnel
+	if err := json.Unmarshal([]byte(output), &funnel); err != nil {
+		t.Fatalf("Failed to unmarshal Sankey data: %v", err)
+	}
+
+	wantNodes := []sankeyNode{
+		{Name: "View", Kind: "step"},
+		{Name: "Cart", Kind: "step"},
+		{Name: "Pay", Kind: "step"},
+		{Name: "Dropped after View", Kind: "drop_off"},
+		{Name: "Dropped after Cart", Kind: "drop_off"},
+	}
+	wantLinks := []sankeyLink{
+		{Source: 0, Target: 1, Value: 5},
+		{Source: 0, Target: 3, Value: 5},
+		{Source: 1, Target: 4, Value: 5},
+	}
+	if funnel.FunnelName != "Checkout" || len(funnel.Nodes) != len(wantNodes) || len(funnel.Links) != len(wantLinks) {
+		t.Fatalf("Unexpected Sankey data: %s", output)
+	}
+	for i, node := range wantNodes {
+		if funnel.Nodes[i] != node {
+			t.Errorf("Node %d = %+v, want %+v", i, funnel.Nodes[i], node)
+		}
+	}
+	for i, link := range wantLinks {
+		if funnel.Links[i] != link {
+			t.Errorf("Link %d = %+v, want %+v", i, funnel.Links[i], link)
+		}
+	}
+}
+
+func TestSankeyFormatter_FormatFunnels(t *testing.T) {
+	formatter := NewFormatter(SankeyFormat)
+	output, err := formatter.FormatFunnels([]*analyzer.FunnelResult{testFunnelResult(), {FunnelName: "Empty"}})
+	if err != nil {
+		t.Fatalf("FormatFunnels() unexpected error: %v", err)
+	}
+	if !strings.Contains(output, `"funnel_name": "Checkout"`) || !strings.Contains(output, `"funnel_name": "Empty",
+      "nodes": [],
+      "links": []`) {
+		t.Errorf("Expected both funnels, got:\n%s", output)
+	}
+}
+
+func TestSankeyFormatter_Unsupported(t *testing.T) {
+	_, err := NewFormatter(SankeyFormat).FormatCount(&analyzer.CountResult{})
+	if err == nil || err.Error() != "sankey output is not supported for event counts" {
+		t.Errorf("FormatCount() error = %v, want unsupported error", err)
+	}
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// table is a text table whose columns are padded to their widest cell.
+// Numeric columns are aligned to the right.
+type table struct {
+	header []string
+	right  []bool
+	rows   [][]string
+}
+
+// newTable starts a table with the given column headers. Headers starting
+// with '>' name right-aligned columns.
+func newTable(header ...string) *table {
+	t := &table{header: make([]string, len(header)), right: make([]bool, len(header))}
+	for i, column := range header {
+		t.header[i] = strings.TrimPrefix(column, ">")
+		t.right[i] = column != t.header[i]
+	}
+	return t
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// render draws the table, framed with unicode borders if requested. Cells
+// are converted before measuring them when the output is ASCII, so that
+// columns stay aligned.
+func (t *table) render(options Options) string {
+	rows := append([][]string{t.header}, t.rows...)
+	widths := make([]int, len(t.header))
+	for _, row := range rows {
+		for i, cell := range row {
+			if options.ASCII {
+				row[i] = ASCII(cell)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(row[i]))
+		}
+	}
+
+	var output strings.Builder
+	line := func(left, fill, join, right string) {
+		if !options.Borders {
+			return
+		}
+		output.WriteString(left)
+		for i, width := range widths {
+			if i > 0 {
+				output.WriteString(join)
+			}
+			output.WriteString(strings.Repeat(fill, width+2))
+		}
+		output.WriteString(right + "\n")
+	}
+
+	line("┌", "─", "┬", "┐")
+	for r, row := range rows {
+		var cells []string
+		for i, width := range widths {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			padding := strings.Repeat(" ", width-utf8.RuneCountInString(cell))
+			if t.right[i] {
+				cells = append(cells, padding+cell)
+			} else {
+				cells = append(cells, cell+padding)
+			}
+		}
+		if options.Borders {
+			output.WriteString("│ " + strings.Join(cells, " │ ") + " │\n")
+		} else {
+			output.WriteString(strings.TrimRight(strings.Join(cells, "  "), " ") + "\n")
+		}
+
+		if r == 0 {
+			if options.Borders {
+				line("├", "─", "┼", "┤")
+			} else {
+				var rules []string
+				for _, width := range widths {
+					rules = append(rules, strings.Repeat("-", width))
+				}
+				output.WriteString(strings.Join(rules, "  ") + "\n")
+			}
+		}
+	}
+	line("└", "─", "┴", "┘")
+	return output.String()
+}
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+)
+
+func TestTable_Render(t *testing.T) {
+	table := newTable(">#", "Step", ">Events")
+	table.add("1", "View", "10")
+	table.add("2", "Checkout", "5")
+
+	want := strings.Join([]string{
+		"#  Step      Events",
+		"-  --------  ------",
+		"1  View          10",
+		"2  Checkout       5",
+		"",
+	}, "\n")
+	if got := table.render(Options{}); got != want {
+		t.Errorf("render() =\n%s\nwant\n%s", got, want)
+	}
+
+	want = strings.Join([]string{
+		"┌───┬──────────┬────────┐",
+		"│ # │ Step     │ Events │",
+		"├───┼──────────┼────────┤",
+		"│ 1 │ View     │     10 │",
+		"│ 2 │ Checkout │      5 │",
+		"└───┴──────────┴────────┘",
+		"",
+	}, "\n")
+	if got := table.render(Options{Borders: true}); got != want {
+		t.Errorf("render() with borders =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTable_RenderASCII(t *testing.T) {
+	table := newTable("Step", ">Events")
+	table.add("↳ View…", "10")
+	table.add("Pay", "5")
+
+	want := strings.Join([]string{
+		"Step        Events",
+		"----------  ------",
+		"-> View...      10",
+		"Pay              5",
+		"",
+	}, "\n")
+	if got := table.render(Options{ASCII: true}); got != want {
+		t.Errorf("render() with ASCII =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTextFormatter_FormatFunnel_Table(t *testing.T) {
+	first := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	last := first.Add(time.Minute)
+	result := testFunnelResult()
+	result.Steps[0].FirstTimestamp, result.Steps[0].LastTimestamp = &first, &last
+	result.Steps[1].AssertionFailed = true
+	result.Timings = []analyzer.StepTiming{{Step: "Cart", Samples: 5, MedianSeconds: 12}}
+
+	formatter := &TextFormatter{options: Options{Wide: true}}
+	output, err := formatter.FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"#  Step                     Events  Conversion  Drop-off  First                    Last                     Median Time\n",
+		"1  View                         10      100.0%         -  2025-01-15 10:30:00.000  2025-01-15 10:31:00.000            -\n",
+		"2  Cart (assertion failed)       5       50.0%     50.0%  -                        -                                12s\n",
+		"3  Pay                           0        0.0%    100.0%  -                        -                                  -\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "1. View: 10 events") {
+		t.Errorf("Expected the step list to be replaced by the table, got:\n%s", output)
+	}
+}
+
+func TestTextFormatter_FormatCount_Table(t *testing.T) {
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 100,
+		DurationSeconds:     60,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: "login", Count: 25, PerMinute: 25},
+			{Pattern: "error", Count: 5, PerMinute: 5, Values: &analyzer.ValueStats{Samples: 5, Min: 1, Avg: 2, P95: 3, Max: 3}},
+		},
+	}
+
+	formatter := &TextFormatter{options: Options{Table: true}}
+	output, err := formatter.FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"#  Pattern  Matches  Share  Per Minute\n",
+		"1  login         25  25.0%       25.00\n",
+		"2  error          5   5.0%        5.00\n",
+		"\n2. error:\n   Values: n=5",
+		"Total Matches: 30",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/sirupsen/logrus"
+)
+
+// TemplateFormatter renders results through a user-supplied text/template,
+// e.g. to build Slack messages or wiki snippets. The template is executed
+// with the result itself, such as *analyzer.FunnelResult, as its data.
+type TemplateFormatter struct {
+	options Options
+}
+
+// templateFuncs are the helper functions available to output templates
+var templateFuncs = template.FuncMap{
+	"percent": func(value float64) string {
+		return fmt.Sprintf("%.1f%%", value)
+	},
+	"seconds": formatSeconds,
+	"timestamp": func(value interface{}) string {
+		switch t := value.(type) {
+		case time.Time:
+			return formatTimestamp(t)
+		case *time.Time:
+			return formatOptionalTimestamp(t)
+		}
+		return ""
+	},
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// parseTemplate reads and parses an output template file
+func parseTemplate(path string) (*template.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template file: %w", err)
+	}
+	return tmpl, nil
+}
+
+func (f *TemplateFormatter) render(data interface{}) (string, error) {
+	if f.options.TemplateFile == "" {
+		return "", fmt.Errorf("template output requires a template file")
+	}
+	logrus.WithField("template_file", f.options.TemplateFile).Debug("Rendering output template")
+
+	tmpl, err := parseTemplate(f.options.TemplateFile)
+	if err != nil {
+		return "", err
+	}
+	var output strings.Builder
+	if err := tmpl.Execute(&output, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return output.String(), nil
+}
+
+func (f *TemplateFormatter) FormatFunnel(result *analyzer.FunnelResult) (string, error) {
+	return f.render(f.options.filterFunnel(result))
+}
+
+func (f *TemplateFormatter) FormatFunnels(results []*analyzer.FunnelResult) (string, error) {
+	filtered := make([]*analyzer.FunnelResult, len(results))
+	for i, result := range results {
+		filtered[i] = f.options.filterFunnel(result)
+	}
+	return f.render(filtered)
+}
+
+func (f *TemplateFormatter) FormatCount(result *analyzer.CountResult) (string, error) {
+	return f.render(f.options.filterCount(result))
+}
+
+func (f *TemplateFormatter) FormatSessions(result *analyzer.SessionResult) (string, error) {
+	return f.render(result)
+}
+
+func (f *TemplateFormatter) FormatTop(result *analyzer.TopResult) (string, error) {
+	return f.render(result)
+}
+
+func (f *TemplateFormatter) FormatTimeline(result *analyzer.TimelineResult) (string, error) {
+	return f.render(result)
+}
+
+func (f *TemplateFormatter) FormatOverview(result *analyzer.OverviewResult) (string, error) {
+	return f.render(result)
+}
+
+func (f *TemplateFormatter) FormatExtract(result *analyzer.ExtractResult) (string, error) {
+	return f.render(result)
+}
+
+func (f *TemplateFormatter) FormatDiff(report *analyzer.DiffReport) (string, error) {
+	return f.render(report)
+}
+
+func (f *TemplateFormatter) FormatLabeled(report *analyzer.LabeledReport) (string, error) {
+	return f.render(report)
+}
+
+func (f *TemplateFormatter) FormatCooccurrence(result *analyzer.CooccurrenceResult) (string, error) {
+	return f.render(result)
+}
+
+func (f *TemplateFormatter) FormatDiscover(result *analyzer.DiscoverResult) (string, error) {
+	return f.render(result)
+}
+
+func (f *TemplateFormatter) FormatGaps(result *analyzer.GapResult) (string, error) {
+	return f.render(result)
+}
+
+func (f *TemplateFormatter) FormatAnomalies(result *analyzer.AnomalyResult) (string, error) {
+	return f.render(result)
+}
+
+func (f *TemplateFormatter) FormatRetention(result *analyzer.RetentionResult) (string, error) {
+	return f.render(result)
+}
+
+func (f *TemplateFormatter) FormatAudit(result *analyzer.AuditResult) (string, error) {
+	return f.render(result)
+}
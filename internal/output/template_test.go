@@ -0,0 +1,81 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	return path
+}
+
+func TestTemplateFormatter_FormatFunnel(t *testing.T) {
+	path := writeTemplate(t, `{{.FunnelName}}: {{percent .ConversionRate}}{{range .Steps}}
+- {{upper .Name}} {{.EventCount}}{{end}}`)
+
+	formatter := NewFormatterWithOptions(TemplateFormat, Options{TemplateFile: path, Hide: []string{HideZeroCount}})
+	output, err := formatter.FormatFunnel(testFunnelResult())
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+
+	want := "Checkout: 50.0%\n- VIEW 10\n- CART 5"
+	if output != want {
+		t.Errorf("FormatFunnel() = %q, want %q", output, want)
+	}
+}
+
+func TestTemplateFormatter_FormatCount(t *testing.T) {
+	path := writeTemplate(t, `{{range .PatternCounts}}{{.Pattern}}={{.Count}} since {{timestamp .FirstTimestamp}}; {{end}}{{json .TotalEventsAnalyzed}}`)
+
+	first := time.Date(0, 1, 15, 10, 30, 0, 0, time.UTC)
+	result := &analyzer.CountResult{
+		TotalEventsAnalyzed: 10,
+		PatternCounts: []analyzer.PatternCount{
+			{Pattern: "login", Count: 3, FirstTimestamp: &first},
+			{Pattern: "logout", Count: 0},
+		},
+	}
+	output, err := NewFormatterWithOptions(TemplateFormat, Options{TemplateFile: path}).FormatCount(result)
+	if err != nil {
+		t.Fatalf("FormatCount() unexpected error: %v", err)
+	}
+
+	want := "login=3 since 01-15 10:30:00.000; logout=0 since -; 10"
+	if output != want {
+		t.Errorf("FormatCount() = %q, want %q", output, want)
+	}
+}
+
+func TestTemplateFormatter_Errors(t *testing.T) {
+	result := testFunnelResult()
+
+	if _, err := NewFormatterWithOptions(TemplateFormat, Options{}).FormatFunnel(result); err == nil || !strings.Contains(err.Error(), "requires a template file") {
+		t.Errorf("Expected an error without a template file, got %v", err)
+	}
+
+	path := writeTemplate(t, "{{.Missing}}")
+	if _, err := NewFormatterWithOptions(TemplateFormat, Options{TemplateFile: path}).FormatFunnel(result); err == nil || !strings.Contains(err.Error(), "failed to render template") {
+		t.Errorf("Expected an error for an unknown field, got %v", err)
+	}
+
+	invalid := Options{TemplateFile: writeTemplate(t, "{{range .Steps}")}
+	if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), "invalid template file") {
+		t.Errorf("Validate() error = %v, want invalid template error", err)
+	}
+
+	missing := Options{TemplateFile: filepath.Join(t.TempDir(), "missing.tmpl")}
+	if err := missing.Validate(); err == nil || !strings.Contains(err.Error(), "failed to read template file") {
+		t.Errorf("Validate() error = %v, want read error", err)
+	}
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Compression formats detected by OpenLogFile
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// decompressors holds the compression backends compiled into this build.
+// Optional backends register themselves from files guarded by build tags,
+// so a build can leave them out while staying pure Go (CGO_ENABLED=0).
+var decompressors = map[string]func(io.Reader) (io.ReadCloser, error){
+	CompressionGzip: func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+}
+
+// CompressionFormats returns the compression formats supported by this build
+func CompressionFormats() []string {
+	formats := make([]string, 0, len(decompressors))
+	for format := range decompressors {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// OpenLogFile opens a log file for reading. Gzip and zstd compressed files
+// are decompressed transparently, detected by magic bytes or, failing that,
+// by the .gz/.zst extension.
+func OpenLogFile(path string) (io.ReadCloser, error) {
+	return openLogFile(path, nil)
+}
+
+// openLogFile is OpenLogFile, reporting the bytes read from the file on disk
+// to report unless it is nil
+func openLogFile(path string, report ProgressFunc) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var source io.Reader = file
+	if report != nil {
+		if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+			source = &progressReader{reader: file, path: path, size: info.Size(), report: report}
+		}
+	}
+
+	reader := bufio.NewReader(source)
+	compression := detectCompression(reader, path)
+	logrus.WithFields(logrus.Fields{
+		"filepath":    path,
+		"compression": compression,
+	}).Debug("Detected log file compression")
+
+	if compression == CompressionNone {
+		return &decompressingReader{Reader: reader, close: file.Close}, nil
+	}
+
+	newDecompressor, ok := decompressors[compression]
+	if !ok {
+		file.Close()
+		return nil, fmt.Errorf("%s compressed logs are not supported in this build (built with -tags no%s)", compression, compression)
+	}
+
+	decompressor, err := newDecompressor(reader)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open %s stream: %w", compression, err)
+	}
+	return &decompressingReader{Reader: decompressor, close: func() error {
+		decompressor.Close()
+		return file.Close()
+	}}, nil
+}
+
+// detectCompression checks the leading magic bytes, falling back to the file extension
+func detectCompression(reader *bufio.Reader, path string) string {
+	header, _ := reader.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return CompressionGzip
+	case bytes.HasPrefix(header, zstdMagic):
+		return CompressionZstd
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".gzip":
+		return CompressionGzip
+	case ".zst", ".zstd":
+		return CompressionZstd
+	}
+	return CompressionNone
+}
+
+// decompressingReader closes both the decompressor and the underlying file
+type decompressingReader struct {
+	io.Reader
+	close func() error
+}
+
+func (r *decompressingReader) Close() error {
+	return r.close()
+}
@@ -0,0 +1,23 @@
+//go:build nozstd
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenLogFile_ZstdNotCompiledIn(t *testing.T) {
+	zstdPath := filepath.Join(t.TempDir(), "log.txt.zst")
+	header := []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}
+	if err := os.WriteFile(zstdPath, header, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	_, err := OpenLogFile(zstdPath)
+	if err == nil || !strings.Contains(err.Error(), "not supported in this build") {
+		t.Errorf("OpenLogFile() error = %v, want unsupported format error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const compressedLogContent = "login\naction\nlogout\n"
+
+func writeGzipFile(t *testing.T, path, content string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatalf("Failed to write gzip content: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+}
+
+func TestOpenLogFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	plainPath := filepath.Join(tmpDir, "plain.txt")
+	if err := os.WriteFile(plainPath, []byte(compressedLogContent), 0644); err != nil {
+		t.Fatalf("Failed to write plain file: %v", err)
+	}
+
+	gzipPath := filepath.Join(tmpDir, "log.txt.gz")
+	writeGzipFile(t, gzipPath, compressedLogContent)
+
+	// Compression is detected by magic bytes regardless of the extension
+	gzipNoExtPath := filepath.Join(tmpDir, "bugreport")
+	writeGzipFile(t, gzipNoExtPath, compressedLogContent)
+
+	for _, path := range []string{plainPath, gzipPath, gzipNoExtPath} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			reader, err := OpenLogFile(path)
+			if err != nil {
+				t.Fatalf("OpenLogFile() unexpected error: %v", err)
+			}
+			defer reader.Close()
+
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() unexpected error: %v", err)
+			}
+			if string(content) != compressedLogContent {
+				t.Errorf("OpenLogFile() content = %q, want %q", content, compressedLogContent)
+			}
+		})
+	}
+}
+
+func TestOpenLogFile_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if _, err := OpenLogFile(filepath.Join(tmpDir, "missing.gz")); err == nil {
+		t.Error("OpenLogFile() expected error for missing file")
+	}
+
+	// A .gz extension on content that is not gzip compressed
+	fakeGzipPath := filepath.Join(tmpDir, "fake.gz")
+	if err := os.WriteFile(fakeGzipPath, []byte(compressedLogContent), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := OpenLogFile(fakeGzipPath); err == nil {
+		t.Error("OpenLogFile() expected error for invalid gzip content")
+	}
+}
+
+func TestPlainParser_ParseFileCompressed(t *testing.T) {
+	tmpDir := t.TempDir()
+	gzipPath := filepath.Join(tmpDir, "log.txt.gz")
+	writeGzipFile(t, gzipPath, compressedLogContent)
+
+	assertParsesCompressedLog(t, gzipPath)
+}
+
+func assertParsesCompressedLog(t *testing.T, path string) {
+	t.Helper()
+	entries, err := NewPlainParser().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile(%s) unexpected error: %v", filepath.Base(path), err)
+	}
+	if len(entries) != 3 || entries[0].Message != "login" || entries[2].Message != "logout" {
+		t.Errorf("ParseFile(%s) returned unexpected entries: %v", filepath.Base(path), entries)
+	}
+}
+
+func TestCompressionFormats(t *testing.T) {
+	formats := CompressionFormats()
+	if len(formats) == 0 || formats[0] != CompressionGzip {
+		t.Errorf("CompressionFormats() = %v, should always include gzip", formats)
+	}
+}
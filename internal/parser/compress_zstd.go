@@ -0,0 +1,20 @@
+//go:build !nozstd
+
+package parser
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// zstd support uses a pure Go decoder and can be left out with -tags nozstd
+func init() {
+	decompressors[CompressionZstd] = func(r io.Reader) (io.ReadCloser, error) {
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+}
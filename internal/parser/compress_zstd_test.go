@@ -0,0 +1,68 @@
+//go:build !nozstd
+
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func writeZstdFile(t *testing.T, path, content string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	writer, err := zstd.NewWriter(file)
+	if err != nil {
+		t.Fatalf("Failed to create zstd writer: %v", err)
+	}
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatalf("Failed to write zstd content: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close zstd writer: %v", err)
+	}
+}
+
+func TestOpenLogFile_Zstd(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	zstdPath := filepath.Join(tmpDir, "log.txt.zst")
+	writeZstdFile(t, zstdPath, compressedLogContent)
+
+	// Compression is detected by magic bytes regardless of the extension
+	zstdNoExtPath := filepath.Join(tmpDir, "artifact.log")
+	writeZstdFile(t, zstdNoExtPath, compressedLogContent)
+
+	for _, path := range []string{zstdPath, zstdNoExtPath} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			reader, err := OpenLogFile(path)
+			if err != nil {
+				t.Fatalf("OpenLogFile() unexpected error: %v", err)
+			}
+			defer reader.Close()
+
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() unexpected error: %v", err)
+			}
+			if string(content) != compressedLogContent {
+				t.Errorf("OpenLogFile() content = %q, want %q", content, compressedLogContent)
+			}
+		})
+	}
+
+	assertParsesCompressedLog(t, zstdPath)
+
+	if !slices.Contains(CompressionFormats(), CompressionZstd) {
+		t.Errorf("CompressionFormats() = %v, should include zstd", CompressionFormats())
+	}
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DedupeOptions define when an entry is a duplicate of an earlier one
+type DedupeOptions struct {
+	// Window is the longest time after a kept entry in which an entry with
+	// the same key is a duplicate
+	Window time.Duration
+	// Keys are event data properties, or dot paths, that must also be equal
+	// besides the event
+	Keys []string
+}
+
+// DedupeEntries drops entries that repeat the event and key properties of an
+// earlier kept entry within the window, such as events sent twice by an
+// analytics SDK retrying a flush. Entries without a timestamp are only
+// duplicates of the entry right before them. It returns the kept entries in
+// order and the number of entries dropped.
+func DedupeEntries(entries []*LogEntry, options DedupeOptions) ([]*LogEntry, int) {
+	type keptEntry struct {
+		timestamp time.Time
+		index     int
+	}
+
+	kept := make([]*LogEntry, 0, len(entries))
+	lastByKey := make(map[string]keptEntry)
+	for i, entry := range entries {
+		key := dedupeKey(entry, options.Keys)
+		if last, exists := lastByKey[key]; exists && isDuplicate(entry.Timestamp, last.timestamp, i-last.index, options.Window) {
+			logrus.WithFields(logrus.Fields{
+				"entry_index": i + 1,
+				"key":         key,
+			}).Debug("Dropping duplicate entry")
+			continue
+		}
+		lastByKey[key] = keptEntry{timestamp: entry.Timestamp, index: i}
+		kept = append(kept, entry)
+	}
+
+	removed := len(entries) - len(kept)
+	if removed > 0 {
+		logrus.WithField("duplicates", removed).Debug("Removed duplicate entries")
+	}
+	return kept, removed
+}
+
+// isDuplicate reports whether an entry with the key of an entry kept distance
+// entries earlier is a duplicate of it
+func isDuplicate(timestamp, keptTimestamp time.Time, distance int, window time.Duration) bool {
+	if timestamp.IsZero() || keptTimestamp.IsZero() {
+		return distance == 1
+	}
+	elapsed := timestamp.Sub(keptTimestamp)
+	return elapsed >= 0 && elapsed <= window
+}
+
+// dedupeKey identifies the event of entry: its "event" field or trimmed
+// message, followed by the values of the key properties
+func dedupeKey(entry *LogEntry, keys []string) string {
+	event := strings.TrimSpace(entry.Message)
+	if value, ok := entry.EventData["event"].(string); ok {
+		event = value
+	}
+
+	var key strings.Builder
+	key.WriteString(event)
+	for _, name := range keys {
+		key.WriteByte(0)
+		if value, exists := LookupProperty(entry.EventData, name); exists {
+			fmt.Fprintf(&key, "%v", value)
+		} else {
+			// A missing property differs from any value
+			key.WriteByte(1)
+		}
+	}
+	return key.String()
+}
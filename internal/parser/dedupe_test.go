@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedupeEntries(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	event := func(offset time.Duration, name string, props map[string]interface{}) *LogEntry {
+		eventData := map[string]interface{}{"event": name}
+		for key, value := range props {
+			eventData[key] = value
+		}
+		return &LogEntry{Timestamp: base.Add(offset), Message: name, EventData: eventData}
+	}
+
+	tests := []struct {
+		name        string
+		entries     []*LogEntry
+		options     DedupeOptions
+		wantKept    []int
+		wantRemoved int
+	}{
+		{
+			name: "duplicate within window",
+			entries: []*LogEntry{
+				event(0, "purchase", nil),
+				event(200*time.Millisecond, "purchase", nil),
+				event(3*time.Second, "purchase", nil),
+			},
+			options:     DedupeOptions{Window: time.Second},
+			wantKept:    []int{0, 2},
+			wantRemoved: 1,
+		},
+		{
+			name: "window starts at the kept entry",
+			entries: []*LogEntry{
+				event(0, "tap", nil),
+				event(600*time.Millisecond, "tap", nil),
+				event(1200*time.Millisecond, "tap", nil),
+			},
+			options:     DedupeOptions{Window: time.Second},
+			wantKept:    []int{0, 2},
+			wantRemoved: 1,
+		},
+		{
+			name: "key properties must match",
+			entries: []*LogEntry{
+				event(0, "purchase", map[string]interface{}{"order_id": "A"}),
+				event(100*time.Millisecond, "purchase", map[string]interface{}{"order_id": "B"}),
+				event(200*time.Millisecond, "purchase", map[string]interface{}{"order_id": "A"}),
+				event(300*time.Millisecond, "purchase", nil),
+			},
+			options:     DedupeOptions{Window: time.Second, Keys: []string{"order_id"}},
+			wantKept:    []int{0, 1, 3},
+			wantRemoved: 1,
+		},
+		{
+			name: "different events are kept",
+			entries: []*LogEntry{
+				event(0, "login", nil),
+				event(0, "purchase", nil),
+			},
+			options:  DedupeOptions{Window: time.Second},
+			wantKept: []int{0, 1},
+		},
+		{
+			name: "entries without timestamp only collapse when adjacent",
+			entries: []*LogEntry{
+				{Message: "sync"},
+				{Message: "sync"},
+				{Message: "other"},
+				{Message: "sync"},
+			},
+			options:     DedupeOptions{Window: time.Second},
+			wantKept:    []int{0, 2, 3},
+			wantRemoved: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, removed := DedupeEntries(tt.entries, tt.options)
+			if removed != tt.wantRemoved {
+				t.Errorf("DedupeEntries() removed %d, want %d", removed, tt.wantRemoved)
+			}
+			if len(kept) != len(tt.wantKept) {
+				t.Fatalf("DedupeEntries() kept %d entries, want %d", len(kept), len(tt.wantKept))
+			}
+			for i, index := range tt.wantKept {
+				if kept[i] != tt.entries[index] {
+					t.Errorf("kept[%d] is not entry %d", i, index)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ResolveLogFiles expands glob patterns into log file paths. Files keep the
+// order in which they were given, with the matches of each pattern sorted by
+// name. Paths matched more than once are only returned once. With
+// sortByMTime all files are ordered by modification time, oldest first.
+func ResolveLogFiles(patterns []string, sortByMTime bool) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if hasGlobMeta(pattern) {
+			globMatches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid log file pattern '%s': %w", pattern, err)
+			}
+			if len(globMatches) == 0 {
+				return nil, fmt.Errorf("no log files match pattern '%s'", pattern)
+			}
+			matches = globMatches
+		}
+
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+
+	if sortByMTime {
+		modTimes := make(map[string]int64, len(files))
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to stat log file: %w", err)
+			}
+			modTimes[file] = info.ModTime().UnixNano()
+		}
+		sort.SliceStable(files, func(i, j int) bool {
+			return modTimes[files[i]] < modTimes[files[j]]
+		})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"patterns":      patterns,
+		"sort_by_mtime": sortByMTime,
+		"files":         files,
+	}).Debug("Resolved log files")
+
+	return files, nil
+}
+
+// DuplicateFile is a log file with the same content as an earlier file
+type DuplicateFile struct {
+	Path   string
+	SameAs string
+}
+
+// DedupeFiles drops files whose decompressed content is identical to an
+// earlier file, so the same log collected under several names is analyzed
+// once. It returns the remaining files in order and the dropped duplicates.
+func DedupeFiles(files []string) ([]string, []DuplicateFile, error) {
+	if len(files) < 2 {
+		return files, nil, nil
+	}
+
+	var unique []string
+	var duplicates []DuplicateFile
+	firstByHash := make(map[string]string)
+
+	for _, file := range files {
+		hash, err := hashLogFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		if first, exists := firstByHash[hash]; exists {
+			duplicates = append(duplicates, DuplicateFile{Path: file, SameAs: first})
+			logrus.WithFields(logrus.Fields{
+				"file":    file,
+				"same_as": first,
+				"sha256":  hash,
+			}).Debug("Skipping duplicate log file")
+			continue
+		}
+		firstByHash[hash] = file
+		unique = append(unique, file)
+	}
+
+	return unique, duplicates, nil
+}
+
+// hashLogFile returns the hex SHA-256 of the decompressed content of a log file
+func hashLogFile(file string) (string, error) {
+	reader, err := OpenLogFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", fmt.Errorf("error reading file '%s': %w", file, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// FileOptions adjusts how the entries of each parsed file are processed
+type FileOptions struct {
+	// Monotonicize clamps timestamps going backwards within a file, see Monotonicize
+	Monotonicize bool
+	// SortByTimestamp orders the merged entries of all files by timestamp,
+	// see SortByTimestamp
+	SortByTimestamp bool
+}
+
+// ParseFiles parses the files in order and merges their entries
+func ParseFiles(p Parser, files []string) ([]*LogEntry, error) {
+	return ParseFilesWithOptions(p, files, FileOptions{})
+}
+
+// ParseFilesWithOptions is like ParseFiles but processes the entries of each
+// file according to options before merging them
+func ParseFilesWithOptions(p Parser, files []string, options FileOptions) ([]*LogEntry, error) {
+	var entries []*LogEntry
+	for _, file := range files {
+		fileEntries, err := p.ParseFile(file)
+		if err != nil {
+			if len(files) > 1 {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			return nil, err
+		}
+		if options.Monotonicize {
+			if clamped := Monotonicize(fileEntries); clamped > 0 {
+				logrus.WithFields(logrus.Fields{
+					"file":               file,
+					"clamped_timestamps": clamped,
+				}).Info("Clamped timestamps that went backwards")
+			}
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	if options.SortByTimestamp {
+		if regressions := SortByTimestamp(entries); regressions > 0 {
+			logrus.WithField("timestamp_regressions", regressions).Info("Sorted entries by timestamp")
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"file_count":  len(files),
+		"entry_count": len(entries),
+	}).Debug("Parsed log files")
+
+	return entries, nil
+}
+
+// ParseReaderWithOptions parses the log read from r, such as a log piped to
+// stdin, and processes its entries according to options like a single file
+func ParseReaderWithOptions(p Parser, r io.Reader, options FileOptions) ([]*LogEntry, error) {
+	entries, err := p.ParseReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Monotonicize {
+		if clamped := Monotonicize(entries); clamped > 0 {
+			logrus.WithField("clamped_timestamps", clamped).Info("Clamped timestamps that went backwards")
+		}
+	}
+	if options.SortByTimestamp {
+		if regressions := SortByTimestamp(entries); regressions > 0 {
+			logrus.WithField("timestamp_regressions", regressions).Info("Sorted entries by timestamp")
+		}
+	}
+
+	logrus.WithField("entry_count", len(entries)).Debug("Parsed log reader")
+	return entries, nil
+}
+
+func hasGlobMeta(pattern string) bool {
+	for _, c := range pattern {
+		switch c {
+		case '*', '?', '[':
+			return true
+		}
+	}
+	return false
+}
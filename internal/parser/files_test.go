@@ -0,0 +1,249 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeLogFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestResolveLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFiles(t, dir, map[string]string{
+		"logcat.1.txt": "b\n",
+		"logcat.0.txt": "a\n",
+		"other.log":    "c\n",
+	})
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{
+			name:     "single file",
+			patterns: []string{path("other.log")},
+			want:     []string{path("other.log")},
+		},
+		{
+			name:     "glob sorted by name",
+			patterns: []string{path("logcat.*.txt")},
+			want:     []string{path("logcat.0.txt"), path("logcat.1.txt")},
+		},
+		{
+			name:     "given order kept across patterns",
+			patterns: []string{path("other.log"), path("logcat.*.txt")},
+			want:     []string{path("other.log"), path("logcat.0.txt"), path("logcat.1.txt")},
+		},
+		{
+			name:     "duplicates removed",
+			patterns: []string{path("logcat.0.txt"), path("*.txt")},
+			want:     []string{path("logcat.0.txt"), path("logcat.1.txt")},
+		},
+		{
+			name:     "missing plain path is kept for the parser to report",
+			patterns: []string{path("missing.txt")},
+			want:     []string{path("missing.txt")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveLogFiles(tt.patterns, false)
+			if err != nil {
+				t.Fatalf("ResolveLogFiles() unexpected error: %v", err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("ResolveLogFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveLogFiles_SortByMTime(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFiles(t, dir, map[string]string{"a.txt": "a\n", "b.txt": "b\n"})
+
+	now := time.Now()
+	if err := os.Chtimes(filepath.Join(dir, "a.txt"), now, now); err != nil {
+		t.Fatalf("Chtimes() failed: %v", err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "b.txt"), now.Add(-time.Hour), now.Add(-time.Hour)); err != nil {
+		t.Fatalf("Chtimes() failed: %v", err)
+	}
+
+	got, err := ResolveLogFiles([]string{filepath.Join(dir, "*.txt")}, true)
+	if err != nil {
+		t.Fatalf("ResolveLogFiles() unexpected error: %v", err)
+	}
+	if len(got) != 2 || filepath.Base(got[0]) != "b.txt" || filepath.Base(got[1]) != "a.txt" {
+		t.Errorf("ResolveLogFiles() = %v, want b.txt before a.txt", got)
+	}
+}
+
+func TestResolveLogFiles_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := ResolveLogFiles([]string{filepath.Join(dir, "*.txt")}, false); err == nil || !strings.Contains(err.Error(), "no log files match") {
+		t.Errorf("ResolveLogFiles() error = %v, want no match error", err)
+	}
+
+	if _, err := ResolveLogFiles([]string{filepath.Join(dir, "[")}, false); err == nil {
+		t.Error("ResolveLogFiles() expected error for malformed pattern")
+	}
+
+	if _, err := ResolveLogFiles([]string{filepath.Join(dir, "missing.txt")}, true); err == nil {
+		t.Error("ResolveLogFiles() expected error when sorting missing file by mtime")
+	}
+}
+
+func TestParseFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFiles(t, dir, map[string]string{
+		"part1.txt": "login\naction\n",
+		"part2.txt": "logout\n",
+	})
+
+	entries, err := ParseFiles(NewPlainParser(), []string{filepath.Join(dir, "part1.txt"), filepath.Join(dir, "part2.txt")})
+	if err != nil {
+		t.Fatalf("ParseFiles() unexpected error: %v", err)
+	}
+
+	var messages []string
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+	if strings.Join(messages, ",") != "login,action,logout" {
+		t.Errorf("ParseFiles() messages = %v, want merged in file order", messages)
+	}
+
+	_, err = ParseFiles(NewPlainParser(), []string{filepath.Join(dir, "part1.txt"), filepath.Join(dir, "missing.txt")})
+	if err == nil || !strings.Contains(err.Error(), "missing.txt") {
+		t.Errorf("ParseFiles() error = %v, want error naming the missing file", err)
+	}
+}
+
+func TestDedupeFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFiles(t, dir, map[string]string{
+		"device.txt":      "login\nlogout\n",
+		"device-copy.txt": "login\nlogout\n",
+		"other.txt":       "login\n",
+	})
+	writeGzipFile(t, filepath.Join(dir, "device.txt.gz"), "login\nlogout\n")
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+	files, duplicates, err := DedupeFiles([]string{path("device.txt"), path("other.txt"), path("device-copy.txt"), path("device.txt.gz")})
+	if err != nil {
+		t.Fatalf("DedupeFiles() unexpected error: %v", err)
+	}
+
+	if strings.Join(files, ",") != path("device.txt")+","+path("other.txt") {
+		t.Errorf("DedupeFiles() files = %v, want the first of each content", files)
+	}
+	expected := []DuplicateFile{
+		{Path: path("device-copy.txt"), SameAs: path("device.txt")},
+		{Path: path("device.txt.gz"), SameAs: path("device.txt")},
+	}
+	if len(duplicates) != len(expected) || duplicates[0] != expected[0] || duplicates[1] != expected[1] {
+		t.Errorf("DedupeFiles() duplicates = %+v, want %+v", duplicates, expected)
+	}
+
+	if _, _, err := DedupeFiles([]string{path("device.txt"), path("missing.txt")}); err == nil {
+		t.Error("DedupeFiles() expected error for a missing file")
+	}
+}
+
+func TestParseFilesWithOptions_Monotonicize(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFiles(t, dir, map[string]string{
+		"part1.txt": "10:00:05 login\n10:00:01 action\n",
+		"part2.txt": "09:00:00 logout\n",
+	})
+	files := []string{filepath.Join(dir, "part1.txt"), filepath.Join(dir, "part2.txt")}
+	logParser := NewPlainParserWithConfig("15:04:05", "", false, `^(\S+) (.*)$`)
+
+	entries, err := ParseFilesWithOptions(logParser, files, FileOptions{Monotonicize: true})
+	if err != nil {
+		t.Fatalf("ParseFilesWithOptions() unexpected error: %v", err)
+	}
+
+	var timestamps []string
+	for _, entry := range entries {
+		timestamps = append(timestamps, entry.Timestamp.Format("15:04:05"))
+	}
+	// Timestamps are clamped within each file only
+	if strings.Join(timestamps, ",") != "10:00:05,10:00:05,09:00:00" {
+		t.Errorf("ParseFilesWithOptions() timestamps = %v, want clamped per file", timestamps)
+	}
+}
+
+func TestParseReaderWithOptions(t *testing.T) {
+	logParser := NewPlainParserWithConfig("15:04:05", "", false, `^(\S+) (.*)$`)
+	input := "10:00:05 login\n10:00:01 action\n10:00:07 logout\n"
+
+	tests := []struct {
+		name    string
+		options FileOptions
+		want    string
+	}{
+		{name: "as read", want: "10:00:05 login,10:00:01 action,10:00:07 logout"},
+		{name: "monotonicize", options: FileOptions{Monotonicize: true}, want: "10:00:05 login,10:00:05 action,10:00:07 logout"},
+		{name: "sort by timestamp", options: FileOptions{SortByTimestamp: true}, want: "10:00:01 action,10:00:05 login,10:00:07 logout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ParseReaderWithOptions(logParser, strings.NewReader(input), tt.options)
+			if err != nil {
+				t.Fatalf("ParseReaderWithOptions() unexpected error: %v", err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Timestamp.Format("15:04:05")+" "+entry.Message)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("ParseReaderWithOptions() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFiles_Progress(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Repeat("I login\n", 1000)
+	writeLogFiles(t, dir, map[string]string{"app.log": content})
+	path := filepath.Join(dir, "app.log")
+
+	var last, size int64
+	SetProgress(func(file string, read, total int64) {
+		if file != path || read < last {
+			t.Errorf("Unexpected progress %s %d after %d", file, read, last)
+		}
+		last, size = read, total
+	})
+	defer SetProgress(nil)
+
+	entries, err := ParseFiles(NewPlainParserWithConfig("", "", false, `^I (.*)$`), []string{path})
+	if err != nil {
+		t.Fatalf("ParseFiles() unexpected error: %v", err)
+	}
+	if len(entries) != 1000 {
+		t.Fatalf("Expected 1000 entries, got %d", len(entries))
+	}
+	if last != int64(len(content)) || size != int64(len(content)) {
+		t.Errorf("Expected the whole file to be reported read, got %d of %d", last, size)
+	}
+}
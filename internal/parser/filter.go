@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logLevels lists logcat priorities from least to most severe
+var logLevels = []string{"V", "D", "I", "W", "E", "F", "A"}
+
+var logLevelNames = map[string]string{
+	"verbose": "V",
+	"debug":   "D",
+	"info":    "I",
+	"warn":    "W",
+	"warning": "W",
+	"error":   "E",
+	"fatal":   "F",
+	"assert":  "A",
+}
+
+// ParseLevel normalizes a logcat priority letter (V, D, I, W, E, F, A) or
+// level name (verbose, debug, info, warn, error, fatal, assert)
+func ParseLevel(level string) (string, error) {
+	normalized := strings.TrimSpace(level)
+	if name, ok := logLevelNames[strings.ToLower(normalized)]; ok {
+		return name, nil
+	}
+
+	normalized = strings.ToUpper(normalized)
+	if slices.Contains(logLevels, normalized) {
+		return normalized, nil
+	}
+	return "", fmt.Errorf("unknown log level '%s' (valid: %s)", level, strings.Join(logLevels, ", "))
+}
+
+// EntryFilter selects log entries by level, tag and process before analysis.
+// Empty fields do not filter.
+type EntryFilter struct {
+	// MinLevel keeps entries with this priority or a more severe one
+	MinLevel string
+	// Tags keeps only entries with one of these tags
+	Tags []string
+	// ExcludeTags drops entries with one of these tags
+	ExcludeTags []string
+	// PIDs keeps only entries logged by one of these processes
+	PIDs []int
+}
+
+// IsEmpty reports whether the filter keeps every entry
+func (f EntryFilter) IsEmpty() bool {
+	return f.MinLevel == "" && len(f.Tags) == 0 && len(f.ExcludeTags) == 0 && len(f.PIDs) == 0
+}
+
+// Matches reports whether entry passes the filter
+func (f EntryFilter) Matches(entry *LogEntry) bool {
+	if f.MinLevel != "" {
+		entryLevel := slices.Index(logLevels, strings.ToUpper(strings.TrimSpace(entry.Level)))
+		if entryLevel < slices.Index(logLevels, f.MinLevel) {
+			return false
+		}
+	}
+
+	tag := strings.TrimSpace(entry.Tag)
+	if len(f.Tags) > 0 && !slices.Contains(f.Tags, tag) {
+		return false
+	}
+	if slices.Contains(f.ExcludeTags, tag) {
+		return false
+	}
+
+	if len(f.PIDs) > 0 && !slices.Contains(f.PIDs, entry.PID) {
+		return false
+	}
+
+	return true
+}
+
+// FilterEntries returns the entries that pass the filter
+func FilterEntries(entries []*LogEntry, filter EntryFilter) []*LogEntry {
+	if filter.IsEmpty() {
+		return entries
+	}
+
+	filtered := make([]*LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if filter.Matches(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"min_level":      filter.MinLevel,
+		"tags":           filter.Tags,
+		"exclude_tags":   filter.ExcludeTags,
+		"pids":           filter.PIDs,
+		"total_entries":  len(entries),
+		"passed_entries": len(filtered),
+	}).Debug("Filtered log entries")
+
+	return filtered
+}
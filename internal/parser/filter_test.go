@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "W", want: "W"},
+		{input: "e", want: "E"},
+		{input: "warning", want: "W"},
+		{input: "Info", want: "I"},
+		{input: " debug ", want: "D"},
+		{input: "X", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLevel(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntryFilter_Matches(t *testing.T) {
+	entry := &LogEntry{Level: "W", Tag: "Network ", PID: 1234, Message: "request slow"}
+
+	tests := []struct {
+		name   string
+		filter EntryFilter
+		want   bool
+	}{
+		{name: "empty filter", filter: EntryFilter{}, want: true},
+		{name: "same level", filter: EntryFilter{MinLevel: "W"}, want: true},
+		{name: "lower min level", filter: EntryFilter{MinLevel: "D"}, want: true},
+		{name: "higher min level", filter: EntryFilter{MinLevel: "E"}, want: false},
+		{name: "matching tag", filter: EntryFilter{Tags: []string{"Analytics", "Network"}}, want: true},
+		{name: "other tag", filter: EntryFilter{Tags: []string{"Analytics"}}, want: false},
+		{name: "excluded tag", filter: EntryFilter{ExcludeTags: []string{"Network"}}, want: false},
+		{name: "other excluded tag", filter: EntryFilter{ExcludeTags: []string{"chatty"}}, want: true},
+		{name: "matching pid", filter: EntryFilter{PIDs: []int{1, 1234}}, want: true},
+		{name: "other pid", filter: EntryFilter{PIDs: []int{5678}}, want: false},
+		{name: "all filters match", filter: EntryFilter{MinLevel: "I", Tags: []string{"Network"}, PIDs: []int{1234}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(entry); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntryFilter_LevelWithoutEntryLevel(t *testing.T) {
+	filter := EntryFilter{MinLevel: "V"}
+	if filter.Matches(&LogEntry{Message: "no level"}) {
+		t.Error("Matches() should reject entries without a level when a level filter is set")
+	}
+}
+
+func TestFilterEntries(t *testing.T) {
+	entries := []*LogEntry{
+		{Level: "I", Tag: "Analytics", Message: "login"},
+		{Level: "D", Tag: "Network", Message: "request"},
+		{Level: "E", Tag: "Analytics", Message: "error"},
+	}
+
+	if got := FilterEntries(entries, EntryFilter{}); len(got) != len(entries) {
+		t.Errorf("FilterEntries() with empty filter returned %d entries, want %d", len(got), len(entries))
+	}
+
+	got := FilterEntries(entries, EntryFilter{MinLevel: "I", Tags: []string{"Analytics"}})
+	if len(got) != 2 || got[0].Message != "login" || got[1].Message != "error" {
+		t.Errorf("FilterEntries() = %v, want login and error entries", got)
+	}
+}
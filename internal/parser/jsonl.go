@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JSONLFields names the properties of JSONL records that hold the fields of a
+// log entry
+type JSONLFields struct {
+	Timestamp string
+	Level     string
+	Message   string
+	// Event is the property holding the event payload. An object payload is
+	// the event data; a string payload is the event name, with the rest of the
+	// record as its properties. When empty, the whole record is the event data.
+	Event string
+}
+
+// DefaultJSONLFields are the property names used for fields left empty
+var DefaultJSONLFields = JSONLFields{
+	Timestamp: "timestamp",
+	Level:     "level",
+	Message:   "message",
+}
+
+// JSONLParser parses logs where every line is a standalone JSON object, such
+// as analytics exports and structured loggers
+type JSONLParser struct {
+	lineOptions
+	skippedSamples
+	timestampFormat string
+	fields          JSONLFields
+	stats           ParseStats
+}
+
+// NewJSONLParser creates a JSONL parser. Timestamps are parsed with
+// timestampFormat, or as RFC 3339 when it is empty.
+func NewJSONLParser(timestampFormat string, fields JSONLFields) *JSONLParser {
+	if fields.Timestamp == "" {
+		fields.Timestamp = DefaultJSONLFields.Timestamp
+	}
+	if fields.Level == "" {
+		fields.Level = DefaultJSONLFields.Level
+	}
+	if fields.Message == "" {
+		fields.Message = DefaultJSONLFields.Message
+	}
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339Nano
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"timestamp_format": timestampFormat,
+		"fields":           fields,
+	}).Debug("Creating new JSONL parser")
+
+	return &JSONLParser{
+		timestampFormat: timestampFormat,
+		fields:          fields,
+		lineOptions:     lineOptions{maxLineBytes: DefaultMaxLineBytes},
+	}
+}
+
+func (p *JSONLParser) Parse(logLine string) (*LogEntry, error) {
+	return p.parseSingle(logLine, p.parseLine)
+}
+
+// parseLine parses one JSON record, counting repaired input in stats
+func (p *JSONLParser) parseLine(logLine string, stats *ParseStats) (*LogEntry, error) {
+	logrus.WithField("log_line", logLine).Debug("Parsing JSONL log line")
+
+	if !utf8.ValidString(logLine) {
+		stats.InvalidUTF8Lines++
+		logLine = strings.ToValidUTF8(logLine, "\uFFFD")
+		logrus.Debug("Replaced invalid UTF-8 in log line")
+	}
+
+	trimmedLine := strings.TrimSpace(logLine)
+	if trimmedLine == "" {
+		return nil, fmt.Errorf("empty log line")
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(trimmedLine), &record); err != nil {
+		if looksLikeJSON(trimmedLine) {
+			stats.InvalidJSON++
+		}
+		return nil, fmt.Errorf("invalid JSON record: %w", err)
+	}
+	if record == nil {
+		return nil, fmt.Errorf("JSON record is not an object")
+	}
+
+	entry := &LogEntry{EventData: record}
+
+	if value, ok := record[p.fields.Timestamp].(string); ok && value != "" {
+		if timestamp, err := time.ParseInLocation(p.timestampFormat, value, p.timezone()); err == nil {
+			entry.Timestamp = timestamp
+		} else {
+			logrus.WithError(err).WithField("timestamp_str", value).Debug("Failed to parse timestamp")
+			stats.InvalidTimestamps++
+		}
+	}
+
+	if value, ok := record[p.fields.Level].(string); ok {
+		// Normalize level names so entry filters work across formats
+		if level, err := ParseLevel(value); err == nil {
+			entry.Level = level
+		} else {
+			entry.Level = value
+		}
+	}
+
+	if value, ok := record[p.fields.Message].(string); ok {
+		entry.Message = value
+	}
+
+	if p.fields.Event != "" {
+		switch payload := record[p.fields.Event].(type) {
+		case map[string]interface{}:
+			entry.EventData = payload
+		case string:
+			record["event"] = payload
+		default:
+			entry.EventData = nil
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"timestamp": entry.Timestamp,
+		"level":     entry.Level,
+		"has_event": entry.EventData != nil,
+	}).Debug("JSONL record parsed successfully")
+
+	return entry, nil
+}
+
+func (p *JSONLParser) ParseFile(filepath string) ([]*LogEntry, error) {
+	return parseFile(filepath, p.lineOptions, p.parseReader)
+}
+
+// ParseReader parses JSON records from r, skipping lines that are not JSON
+// objects or exceed the line size limit
+func (p *JSONLParser) ParseReader(r io.Reader) ([]*LogEntry, error) {
+	return p.parseReader(r, p.lineOptions)
+}
+
+func (p *JSONLParser) parseReader(r io.Reader, options lineOptions) ([]*LogEntry, error) {
+	entries, stats, skipped, err := parseLines(r, options, p.parseLine)
+	if err != nil {
+		return nil, err
+	}
+	p.stats.Add(stats)
+	p.addSkipped(skipped)
+	return entries, nil
+}
+
+// Stats returns the statistics accumulated over all readers parsed so far
+func (p *JSONLParser) Stats() ParseStats {
+	return p.stats
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLParser_Parse(t *testing.T) {
+	tests := []struct {
+		name          string
+		fields        JSONLFields
+		line          string
+		wantTimestamp time.Time
+		wantLevel     string
+		wantMessage   string
+		wantEvent     interface{}
+		wantProperty  string
+		expectError   bool
+	}{
+		{
+			name:          "default fields",
+			line:          `{"timestamp":"2025-01-15T10:30:45.123Z","level":"info","message":"user logged in","event":"login","user_id":"alice"}`,
+			wantTimestamp: time.Date(2025, 1, 15, 10, 30, 45, 123000000, time.UTC),
+			wantLevel:     "I",
+			wantMessage:   "user logged in",
+			wantEvent:     "login",
+			wantProperty:  "alice",
+		},
+		{
+			name:          "mapped fields with object payload",
+			fields:        JSONLFields{Timestamp: "ts", Level: "severity", Message: "msg", Event: "payload"},
+			line:          `{"ts":"2025-01-15T10:30:45Z","severity":"W","msg":"retrying","payload":{"event":"purchase","user_id":"bob"}}`,
+			wantTimestamp: time.Date(2025, 1, 15, 10, 30, 45, 0, time.UTC),
+			wantLevel:     "W",
+			wantMessage:   "retrying",
+			wantEvent:     "purchase",
+			wantProperty:  "bob",
+		},
+		{
+			name:         "event name field keeps record properties",
+			fields:       JSONLFields{Event: "name"},
+			line:         `{"name":"signup","user_id":"carol"}`,
+			wantEvent:    "signup",
+			wantProperty: "carol",
+		},
+		{
+			name:      "unknown level is kept",
+			line:      `{"level":"notice","event":"login"}`,
+			wantLevel: "notice",
+			wantEvent: "login",
+		},
+		{
+			name:        "not JSON",
+			line:        "plain text line",
+			expectError: true,
+		},
+		{
+			name:        "not an object",
+			line:        "null",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := NewJSONLParser("", tt.fields).Parse(tt.line)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Parse() expected error, got entry %+v", entry)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if !entry.Timestamp.Equal(tt.wantTimestamp) {
+				t.Errorf("Timestamp = %v, want %v", entry.Timestamp, tt.wantTimestamp)
+			}
+			if entry.Level != tt.wantLevel {
+				t.Errorf("Level = %q, want %q", entry.Level, tt.wantLevel)
+			}
+			if entry.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", entry.Message, tt.wantMessage)
+			}
+			if entry.EventData["event"] != tt.wantEvent {
+				t.Errorf("EventData[event] = %v, want %v", entry.EventData["event"], tt.wantEvent)
+			}
+			if tt.wantProperty != "" && entry.EventData["user_id"] != tt.wantProperty {
+				t.Errorf("EventData[user_id] = %v, want %v", entry.EventData["user_id"], tt.wantProperty)
+			}
+		})
+	}
+}
+
+func TestJSONLParser_ParseReader_Stats(t *testing.T) {
+	parser := NewJSONLParser("2006-01-02 15:04:05", JSONLFields{})
+
+	input := strings.Join([]string{
+		`{"timestamp":"2025-01-15 10:30:45","event":"login"}`,
+		"",
+		"garbage",
+		`{"timestamp":"yesterday","event":"action"}`,
+		`{"event":"checkout",`,
+		`{"timestamp":"2025-01-15 10:30:40","event":"logout"}`,
+	}, "\n")
+
+	entries, err := parser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ParseReader() expected 3 entries, got %d", len(entries))
+	}
+
+	expected := ParseStats{
+		TotalLines:           6,
+		ParsedEntries:        3,
+		EmptyLines:           1,
+		UnmatchedLines:       2,
+		InvalidTimestamps:    1,
+		InvalidJSON:          1,
+		TimestampRegressions: 1,
+	}
+	if stats := parser.Stats(); stats != expected {
+		t.Errorf("Stats() = %+v, want %+v", stats, expected)
+	}
+}
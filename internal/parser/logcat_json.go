@@ -0,0 +1,222 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logcatMessage is a message of an Android Studio .logcat export
+type logcatMessage struct {
+	Header struct {
+		LogLevel      string `json:"logLevel"`
+		PID           int    `json:"pid"`
+		TID           int    `json:"tid"`
+		ApplicationID string `json:"applicationId"`
+		ProcessName   string `json:"processName"`
+		Tag           string `json:"tag"`
+		Timestamp     struct {
+			Seconds int64 `json:"seconds"`
+			Nanos   int64 `json:"nanos"`
+		} `json:"timestamp"`
+	} `json:"header"`
+	Message string `json:"message"`
+}
+
+// LogcatJSONParser parses the .logcat files Android Studio exports from its
+// Logcat window: a JSON document with the messages in a logcatMessages array.
+// Event extraction works as with the android-logcat format, the event regex
+// seeing each message as a threadtime line, so configs carry over.
+type LogcatJSONParser struct {
+	lineOptions
+	skippedSamples
+	// events extracts the event data of messages
+	events         *PlainParser
+	jsonExtraction bool
+	stats          ParseStats
+}
+
+// NewLogcatJSONParser creates a parser for Android Studio .logcat exports.
+// With jsonExtraction the event data is taken from the JSON matched by the
+// first capture group of eventRegex, or from a JSON message.
+func NewLogcatJSONParser(eventRegex string, jsonExtraction bool) *LogcatJSONParser {
+	logrus.WithFields(logrus.Fields{
+		"event_regex":     eventRegex,
+		"json_extraction": jsonExtraction,
+	}).Debug("Creating new logcat JSON parser")
+
+	return &LogcatJSONParser{
+		events:         NewPlainParserWithConfig("", eventRegex, jsonExtraction, ""),
+		jsonExtraction: jsonExtraction,
+		lineOptions:    lineOptions{maxLineBytes: DefaultMaxLineBytes},
+	}
+}
+
+// Parse parses a single message object of a .logcat file
+func (p *LogcatJSONParser) Parse(logLine string) (*LogEntry, error) {
+	if strings.TrimSpace(logLine) == "" {
+		return nil, fmt.Errorf("empty log line")
+	}
+
+	var message logcatMessage
+	if err := json.Unmarshal([]byte(logLine), &message); err != nil {
+		return nil, fmt.Errorf("invalid logcat message: %w", err)
+	}
+	return p.newEntry(&message, &ParseStats{}), nil
+}
+
+// newEntry converts a message to a log entry, counting invalid event JSON in
+// stats
+func (p *LogcatJSONParser) newEntry(message *logcatMessage, stats *ParseStats) *LogEntry {
+	header := message.Header
+	entry := &LogEntry{
+		PID:     header.PID,
+		TID:     header.TID,
+		Tag:     header.Tag,
+		Message: message.Message,
+	}
+	if header.Timestamp.Seconds != 0 || header.Timestamp.Nanos != 0 {
+		entry.Timestamp = time.Unix(header.Timestamp.Seconds, header.Timestamp.Nanos).In(p.timezone())
+	}
+
+	// Android Studio names the levels, e.g. INFO and ASSERT
+	if level, err := ParseLevel(header.LogLevel); err == nil {
+		entry.Level = level
+	} else {
+		entry.Level = header.LogLevel
+	}
+
+	if p.jsonExtraction {
+		p.events.extractEventData(entry, strings.ReplaceAll(threadtimeLine(entry), "\n", " "), stats)
+	}
+	return entry
+}
+
+// threadtimeLine formats an entry as a line of logcat -v threadtime
+func threadtimeLine(entry *LogEntry) string {
+	return fmt.Sprintf("%s %5d %5d %s %s: %s", entry.Timestamp.Format(logcatTimestampFormat), entry.PID, entry.TID, entry.Level, entry.Tag, entry.Message)
+}
+
+func (p *LogcatJSONParser) ParseFile(filepath string) ([]*LogEntry, error) {
+	return parseFile(filepath, p.lineOptions, p.parseReader)
+}
+
+// ParseReader parses the messages of a .logcat file read from r
+func (p *LogcatJSONParser) ParseReader(r io.Reader) ([]*LogEntry, error) {
+	return p.parseReader(r, p.lineOptions)
+}
+
+// parseReader decodes the messages one by one, so the whole document is
+// never held in memory. Every message counts as a line of the log; messages
+// that are not valid message objects are skipped and reported like unmatched
+// lines, while a document that is not valid JSON cannot be read past the
+// error and fails.
+func (p *LogcatJSONParser) parseReader(r io.Reader, options lineOptions) ([]*LogEntry, error) {
+	decoder := json.NewDecoder(r)
+	var entries []*LogEntry
+	var stats ParseStats
+	var skipped []SkippedLine
+	var previous time.Time
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid logcat file: %w", err)
+		}
+		if token != "logcatMessages" {
+			// Skip the metadata and other properties
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return nil, fmt.Errorf("invalid logcat file: %w", err)
+			}
+			continue
+		}
+
+		if err := expectDelim(decoder, '['); err != nil {
+			return nil, err
+		}
+		for decoder.More() {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return nil, fmt.Errorf("invalid logcat file: message %d: %w", stats.TotalLines+1, err)
+			}
+			stats.TotalLines++
+
+			var message logcatMessage
+			if err := json.Unmarshal(raw, &message); err != nil {
+				stats.UnmatchedLines++
+				if len(skipped) < MaxSkippedSamples {
+					skipped = append(skipped, newSkippedLine(options.source, stats.TotalLines, compactJSON(raw), SkipUnmatched))
+				}
+				logrus.WithError(err).WithField("message_number", stats.TotalLines).Debug("Invalid logcat message, skipping")
+				continue
+			}
+
+			entry := p.newEntry(&message, &stats)
+			if !entry.Timestamp.IsZero() {
+				if !previous.IsZero() && entry.Timestamp.Before(previous) {
+					stats.TimestampRegressions++
+				}
+				previous = entry.Timestamp
+			}
+			if options.keepRaw {
+				entry.Raw = threadtimeLine(entry)
+			}
+			entry.Source, entry.Line = options.source, stats.TotalLines
+			entries = append(entries, entry)
+			stats.ParsedEntries++
+			if options.maxEntries > 0 && len(entries) > options.maxEntries {
+				return nil, tooManyEntries(options.maxEntries)
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return nil, err
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"source":   options.source,
+		"messages": stats.TotalLines,
+	}).Info("Finished parsing logcat file")
+
+	p.stats.Add(stats)
+	p.addSkipped(skipped)
+	return entries, nil
+}
+
+// compactJSON returns raw on one line, for samples of skipped messages
+func compactJSON(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+// expectDelim reads the next token of decoder, which must be delim
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err == io.EOF && delim == '{' {
+		return fmt.Errorf("invalid logcat file: empty input")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid logcat file: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("invalid logcat file: expected '%s', got %v", delim, token)
+	}
+	return nil
+}
+
+// Stats returns the statistics accumulated over all readers parsed so far
+func (p *LogcatJSONParser) Stats() ParseStats {
+	return p.stats
+}
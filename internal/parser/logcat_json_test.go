@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLogcatFile = `{
+  "metadata": {
+    "device": {"serialNumber": "emulator-5554", "isEmulator": true},
+    "filter": "package:mine",
+    "projectApplicationIds": ["com.example.shop"]
+  },
+  "logcatMessages": [
+    {
+      "header": {"logLevel": "INFO", "pid": 1234, "tid": 1250, "applicationId": "com.example.shop", "processName": "com.example.shop", "tag": "Analytics", "timestamp": {"seconds": 1736937015, "nanos": 100000000}},
+      "message": "{\"event\":\"login\",\"user_id\":\"alice\"}"
+    },
+    {
+      "header": {"logLevel": "DEBUG", "pid": 1234, "tid": 1251, "applicationId": "com.example.shop", "processName": "com.example.shop", "tag": "Network", "timestamp": {"seconds": 1736937016, "nanos": 200000000}},
+      "message": "request started"
+    },
+    {
+      "header": {"logLevel": "ASSERT", "pid": 1234, "tid": 1250, "applicationId": "com.example.shop", "processName": "com.example.shop", "tag": "Analytics", "timestamp": {"seconds": 1736937017, "nanos": 0}},
+      "message": "event: {\"event\":\"crash\"}"
+    }
+  ]
+}`
+
+func TestLogcatJSONParser_ParseReader(t *testing.T) {
+	p := NewLogcatJSONParser(`Analytics: (?:event: )?(.*)`, true)
+	p.SetKeepRaw(true)
+
+	entries, err := p.ParseReader(strings.NewReader(testLogcatFile))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ParseReader() returned %d entries, want 3", len(entries))
+	}
+
+	first := entries[0]
+	if want := time.Date(2025, 1, 15, 10, 30, 15, 100000000, time.UTC); !first.Timestamp.Equal(want) || first.Timestamp.Location() != time.UTC {
+		t.Errorf("Timestamp = %v, want %v", first.Timestamp, want)
+	}
+	if first.Level != "I" || first.Tag != "Analytics" || first.PID != 1234 || first.TID != 1250 || first.Line != 1 {
+		t.Errorf("entry = %+v, want level I, tag Analytics, pid 1234, tid 1250, line 1", first)
+	}
+	if want := map[string]interface{}{"event": "login", "user_id": "alice"}; !reflect.DeepEqual(first.EventData, want) {
+		t.Errorf("EventData = %v, want %v", first.EventData, want)
+	}
+	if want := `01-15 10:30:15.100  1234  1250 I Analytics: {"event":"login","user_id":"alice"}`; first.Raw != want {
+		t.Errorf("Raw = %q, want %q", first.Raw, want)
+	}
+
+	if entries[1].Level != "D" || entries[1].Message != "request started" || entries[1].EventData != nil {
+		t.Errorf("entries[1] = %+v, want a debug entry without event data", entries[1])
+	}
+	if entries[2].Level != "A" || entries[2].EventData["event"] != "crash" {
+		t.Errorf("entries[2] = %+v, want an assert entry with event crash", entries[2])
+	}
+
+	stats := p.Stats()
+	if stats.TotalLines != 3 || stats.ParsedEntries != 3 {
+		t.Errorf("Stats() = %+v, want 3 lines and 3 parsed entries", stats)
+	}
+}
+
+func TestLogcatJSONParser_Timezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	p := NewLogcatJSONParser("", false)
+	p.SetTimezone(berlin)
+
+	entry, err := p.Parse(`{"header": {"logLevel": "WARN", "tag": "App", "timestamp": {"seconds": 1736937015}}, "message": "slow"}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if entry.Timestamp.Location() != berlin || entry.Timestamp.Hour() != 11 {
+		t.Errorf("Timestamp = %v, want 11:30:15 in Europe/Berlin", entry.Timestamp)
+	}
+	if entry.Level != "W" || entry.EventData != nil {
+		t.Errorf("entry = %+v, want level W and no event data without json extraction", entry)
+	}
+}
+
+func TestLogcatJSONParser_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "not JSON", input: "01-15 10:30:15.100  1234  1250 I Analytics: login"},
+		{name: "array document", input: `[{"message": "login"}]`},
+		{name: "messages not an array", input: `{"logcatMessages": {}}`},
+		{name: "truncated", input: `{"logcatMessages": [{"header": {"logLevel": "INFO"}, "message": "login"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLogcatJSONParser("", false).ParseReader(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), "invalid logcat file") {
+				t.Errorf("ParseReader() error = %v, want an invalid logcat file error", err)
+			}
+		})
+	}
+}
+
+func TestLogcatJSONParser_SkipsInvalidMessages(t *testing.T) {
+	input := `{"logcatMessages": [
+  {"header": {"logLevel": "INFO", "pid": 1234, "tag": "Analytics"}, "message": "login"},
+  {"header": {"logLevel": "INFO", "pid": "not a number"}, "message": "broken"},
+  42,
+  {"header": {"logLevel": "INFO", "pid": 1234, "tag": "Analytics"}, "message": "logout"}
+]}`
+	p := NewLogcatJSONParser("", false)
+
+	entries, err := p.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v, want invalid messages to be skipped", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "login" || entries[1].Message != "logout" || entries[1].Line != 4 {
+		t.Fatalf("ParseReader() = %+v, want the login and logout messages", entries)
+	}
+
+	stats := p.Stats()
+	if stats.TotalLines != 4 || stats.ParsedEntries != 2 || stats.UnmatchedLines != 2 {
+		t.Errorf("Stats() = %+v, want 4 messages with 2 unmatched", stats)
+	}
+	skipped := p.SkippedLines()
+	if len(skipped) != 2 || skipped[0].Line != 2 || skipped[1].Text != "42" || skipped[0].Reason != SkipUnmatched {
+		t.Errorf("SkippedLines() = %+v, want messages 2 and 3", skipped)
+	}
+	if want := `{"header":{"logLevel":"INFO","pid":"not a number"},"message":"broken"}`; len(skipped) > 0 && skipped[0].Text != want {
+		t.Errorf("SkippedLines()[0].Text = %q, want %q", skipped[0].Text, want)
+	}
+}
+
+func TestLogcatJSONParser_ParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.logcat")
+	if err := os.WriteFile(path, []byte(testLogcatFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewParserForFormat(LogcatJSONFormat, FormatOptions{EventRegex: "^(.*)$"})
+	if err != nil {
+		t.Fatalf("NewParserForFormat() error = %v", err)
+	}
+	entries, err := p.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if len(entries) != 3 || entries[2].Source != path || entries[2].Line != 3 {
+		t.Errorf("ParseFile() = %d entries, last %+v, want 3 entries located in %s", len(entries), entries[len(entries)-1], path)
+	}
+}
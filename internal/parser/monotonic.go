@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Monotonicize clamps timestamps that went backwards to the latest earlier
+// timestamp, so durations between entries are never negative. Entries
+// without a timestamp are left alone. It returns the number of timestamps
+// clamped.
+func Monotonicize(entries []*LogEntry) int {
+	var latest time.Time
+	clamped := 0
+
+	for _, entry := range entries {
+		if entry.Timestamp.IsZero() {
+			continue
+		}
+		if !latest.IsZero() && entry.Timestamp.Before(latest) {
+			entry.Timestamp = latest
+			clamped++
+			continue
+		}
+		latest = entry.Timestamp
+	}
+
+	if clamped > 0 {
+		logrus.WithField("clamped_timestamps", clamped).Debug("Clamped out-of-order timestamps")
+	}
+	return clamped
+}
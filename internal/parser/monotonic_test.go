@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonotonicize(t *testing.T) {
+	at := func(seconds int) time.Time {
+		return time.Date(2025, 1, 15, 10, 0, seconds, 0, time.UTC)
+	}
+	entries := []*LogEntry{
+		{Timestamp: at(5)},
+		{Timestamp: at(1)},
+		{},
+		{Timestamp: at(3)},
+		{Timestamp: at(7)},
+		{Timestamp: at(6)},
+	}
+
+	if clamped := Monotonicize(entries); clamped != 3 {
+		t.Errorf("Monotonicize() = %d, want 3", clamped)
+	}
+
+	expected := []time.Time{at(5), at(5), {}, at(5), at(7), at(7)}
+	for i, entry := range entries {
+		if !entry.Timestamp.Equal(expected[i]) {
+			t.Errorf("entry %d timestamp = %v, want %v", i, entry.Timestamp, expected[i])
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"io"
 	"time"
 )
 
@@ -12,11 +13,21 @@ type LogEntry struct {
 	TID       int
 	Message   string
 	EventData map[string]interface{}
+	// Raw is the log line, or the lines of a multiline entry, the entry was
+	// parsed from. It is only kept when enabled with SetKeepRaw.
+	Raw string
+	// Source and Line locate the entry in the log: the file it was parsed
+	// from, empty for other readers, and the number of its first line
+	Source string
+	Line   int
 }
 
 type Parser interface {
 	Parse(logLine string) (*LogEntry, error)
 	ParseFile(filepath string) ([]*LogEntry, error)
+	ParseReader(r io.Reader) ([]*LogEntry, error)
+	// Stats returns the statistics accumulated over all parsed files and readers
+	Stats() ParseStats
 }
 
 func NewParser() Parser {
@@ -26,3 +37,27 @@ func NewParser() Parser {
 func NewParserWithConfig(timestampFormat, eventRegex string, jsonExtraction bool, logLineRegex string) Parser {
 	return NewPlainParserWithConfig(timestampFormat, eventRegex, jsonExtraction, logLineRegex)
 }
+
+// LookupProperty returns the event data property at path. Dots in path reach
+// into nested objects, so "properties.cart.total" finds the total of
+// {"properties": {"cart": {"total": 42}}}. Keys that contain dots themselves
+// are matched as a whole first.
+func LookupProperty(eventData map[string]interface{}, path string) (interface{}, bool) {
+	if value, exists := eventData[path]; exists {
+		return value, true
+	}
+
+	for i := 0; i < len(path); i++ {
+		if path[i] != '.' {
+			continue
+		}
+		nested, ok := eventData[path[:i]].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, exists := LookupProperty(nested, path[i+1:]); exists {
+			return value, true
+		}
+	}
+	return nil, false
+}
@@ -213,3 +213,37 @@ func TestLogEntry_ZeroValues(t *testing.T) {
 		t.Errorf("LogEntry.EventData should be nil when not set")
 	}
 }
+
+func TestLookupProperty(t *testing.T) {
+	eventData := map[string]interface{}{
+		"event": "checkout",
+		"properties": map[string]interface{}{
+			"cart": map[string]interface{}{"total": 42.5},
+			"a.b":  "dotted key",
+		},
+		"app.version": "1.2.0",
+	}
+
+	tests := []struct {
+		path       string
+		wantValue  interface{}
+		wantExists bool
+	}{
+		{path: "event", wantValue: "checkout", wantExists: true},
+		{path: "properties.cart.total", wantValue: 42.5, wantExists: true},
+		{path: "app.version", wantValue: "1.2.0", wantExists: true},
+		{path: "properties.a.b", wantValue: "dotted key", wantExists: true},
+		{path: "properties.cart.items"},
+		{path: "event.name"},
+		{path: "missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, exists := LookupProperty(eventData, tt.path)
+			if exists != tt.wantExists || value != tt.wantValue {
+				t.Errorf("LookupProperty(%q) = %v, %v, want %v, %v", tt.path, value, exists, tt.wantValue, tt.wantExists)
+			}
+		})
+	}
+}
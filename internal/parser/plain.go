@@ -2,22 +2,133 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/sirupsen/logrus"
 )
 
+// DefaultMaxLineBytes is the longest log line parsed; longer lines are skipped
+const DefaultMaxLineBytes = 1 << 20
+
+// lineOptions control how the lines of a reader are split into log records
+// and how timestamps missing a zone or year are completed
+type lineOptions struct {
+	maxLineBytes int
+	// multilineStart matches the first line of a record; other lines are
+	// continuation lines appended to the previous record. Every line is a
+	// record of its own when nil.
+	multilineStart *regexp.Regexp
+	// location is the zone of timestamps without one, UTC when nil
+	location *time.Location
+	// year completes timestamps without a year; they keep year 0 when unset
+	year int
+	// yearFromModTime takes the year from modTime, the modification time of
+	// the parsed file, or the current time when reading other input
+	yearFromModTime bool
+	modTime         time.Time
+	// source names the parsed file in samples of skipped lines
+	source string
+	// workers is the number of goroutines parsing records, one if unset
+	workers int
+	// keepRaw stores the parsed record in the Raw field of each entry
+	keepRaw bool
+	// maxEntries stops parsing a reader with ErrTooManyEntries once it has
+	// more entries; unlimited if 0
+	maxEntries int
+}
+
+// SetTimezone parses timestamps without a zone in loc instead of UTC
+func (o *lineOptions) SetTimezone(loc *time.Location) {
+	o.location = loc
+}
+
+// SetAssumedYear completes timestamps without a year, such as logcat and BSD
+// syslog timestamps, with year. The year is incremented when timestamps wrap
+// around New Year. With fromModTime the year is instead inferred from the
+// modification time of the log file, which no entry may be newer than.
+func (o *lineOptions) SetAssumedYear(year int, fromModTime bool) {
+	o.year = year
+	o.yearFromModTime = fromModTime
+}
+
+// parseSingle parses a line outside of a reader with parseLine, completing a
+// missing year without wrap-around detection. The current time stands in for
+// the file modification time, as single lines usually come from live logs.
+func (o *lineOptions) parseSingle(logLine string, parseLine func(string, *ParseStats) (*LogEntry, error)) (*LogEntry, error) {
+	entry, err := parseLine(logLine, &ParseStats{})
+	if err != nil {
+		return nil, err
+	}
+
+	year := o.year
+	if o.yearFromModTime {
+		year = time.Now().Year()
+	}
+	if year != 0 && entry.Timestamp.Year() == 0 && !entry.Timestamp.IsZero() {
+		entry.Timestamp = withYear(entry.Timestamp, year)
+	}
+	return entry, nil
+}
+
+// SetMaxLineBytes skips lines longer than n bytes instead of
+// DefaultMaxLineBytes. Multiline entries are limited to n bytes as a whole.
+func (o *lineOptions) SetMaxLineBytes(n int) {
+	o.maxLineBytes = n
+}
+
+// SetMaxEntries stops parsing a reader with ErrTooManyEntries as soon as it
+// has more than n entries, so a huge log is rejected before it is held in
+// memory. 0 removes the limit.
+func (o *lineOptions) SetMaxEntries(n int) {
+	o.maxEntries = n
+}
+
+// SetWorkers parses records with n goroutines. Entries keep the order of the
+// log regardless of n.
+func (o *lineOptions) SetWorkers(n int) {
+	o.workers = n
+}
+
+// SetKeepRaw keeps the lines each entry was parsed from in its Raw field
+func (o *lineOptions) SetKeepRaw(keep bool) {
+	o.keepRaw = keep
+}
+
+// timezone returns the zone of timestamps without one
+func (o *lineOptions) timezone() *time.Location {
+	if o.location == nil {
+		return time.UTC
+	}
+	return o.location
+}
+
+// SetMultilineStart makes lines that do not match regex continuation lines of
+// the previous entry, e.g. stack traces or pretty-printed JSON. A nil regex
+// parses every line as an entry of its own.
+func (o *lineOptions) SetMultilineStart(regex *regexp.Regexp) {
+	o.multilineStart = regex
+}
+
 type PlainParser struct {
+	lineOptions
+	skippedSamples
 	timestampFormat string
 	eventRegex      *regexp.Regexp
 	jsonExtraction  bool
 	logLineRegex    *regexp.Regexp
+	stats           ParseStats
 }
 
 func NewPlainParser() *PlainParser {
@@ -56,6 +167,7 @@ func NewPlainParserWithConfig(timestampFormat, eventRegexPattern string, jsonExt
 		eventRegex:      eventRegex,
 		jsonExtraction:  jsonExtraction,
 		logLineRegex:    logLineRegex,
+		lineOptions:     lineOptions{maxLineBytes: DefaultMaxLineBytes},
 	}
 
 	logrus.Debug("Plain parser created successfully")
@@ -63,8 +175,19 @@ func NewPlainParserWithConfig(timestampFormat, eventRegexPattern string, jsonExt
 }
 
 func (p *PlainParser) Parse(logLine string) (*LogEntry, error) {
+	return p.parseSingle(logLine, p.parseLine)
+}
+
+// parseLine parses one log line, counting repaired input in stats
+func (p *PlainParser) parseLine(logLine string, stats *ParseStats) (*LogEntry, error) {
 	logrus.WithField("log_line", logLine).Debug("Parsing Plain log line")
 
+	if !utf8.ValidString(logLine) {
+		stats.InvalidUTF8Lines++
+		logLine = strings.ToValidUTF8(logLine, "\uFFFD")
+		logrus.Debug("Replaced invalid UTF-8 in log line")
+	}
+
 	// Check for empty lines
 	trimmedLine := strings.TrimSpace(logLine)
 	if trimmedLine == "" {
@@ -72,8 +195,11 @@ func (p *PlainParser) Parse(logLine string) (*LogEntry, error) {
 		return nil, fmt.Errorf("empty log line")
 	}
 
+	// Continuation lines of multiline entries belong to the message
+	header, continuation, _ := strings.Cut(trimmedLine, "\n")
+
 	// Use regex to parse the log line
-	matches := p.logLineRegex.FindStringSubmatch(trimmedLine)
+	matches := p.logLineRegex.FindStringSubmatch(header)
 	if len(matches) == 0 {
 		logrus.WithField("log_line", logLine).Debug("Log line does not match expected format")
 		return nil, fmt.Errorf("invalid log line format: %s", logLine)
@@ -93,11 +219,12 @@ func (p *PlainParser) Parse(logLine string) (*LogEntry, error) {
 	// Groups are in order: timestamp, pid, tid, level, tag, message
 	if len(matches) > 1 && matches[1] != "" && p.timestampFormat != "" {
 		// Try to parse timestamp if format is provided
-		if timestamp, err := time.Parse(p.timestampFormat, matches[1]); err == nil {
+		if timestamp, err := time.ParseInLocation(p.timestampFormat, matches[1], p.timezone()); err == nil {
 			entry.Timestamp = timestamp
 			logrus.WithField("timestamp", timestamp).Debug("Parsed timestamp")
 		} else {
 			logrus.WithError(err).WithField("timestamp_str", matches[1]).Debug("Failed to parse timestamp, using as message")
+			stats.InvalidTimestamps++
 			entry.Message = matches[1]
 		}
 	}
@@ -135,6 +262,10 @@ func (p *PlainParser) Parse(logLine string) (*LogEntry, error) {
 		entry.Message = matches[len(matches)-1]
 	}
 
+	if continuation != "" {
+		entry.Message += "\n" + continuation
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"timestamp": entry.Timestamp,
 		"pid":       entry.PID,
@@ -147,7 +278,9 @@ func (p *PlainParser) Parse(logLine string) (*LogEntry, error) {
 	// Try to extract JSON data if enabled
 	if p.jsonExtraction {
 		logrus.Debug("Attempting to extract event data from log entry")
-		p.extractEventData(entry, logLine)
+		// The event regex sees multiline entries as one line, which keeps
+		// pretty-printed JSON intact since newlines are JSON whitespace
+		p.extractEventData(entry, strings.ReplaceAll(logLine, "\n", " "), stats)
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -160,7 +293,7 @@ func (p *PlainParser) Parse(logLine string) (*LogEntry, error) {
 }
 
 // extractEventData attempts to extract JSON event data from the log entry
-func (p *PlainParser) extractEventData(entry *LogEntry, logLine string) {
+func (p *PlainParser) extractEventData(entry *LogEntry, logLine string, stats *ParseStats) {
 	// First try the event regex pattern
 	if p.eventRegex != nil {
 		logrus.Debug("Trying to extract event data using regex pattern")
@@ -172,6 +305,9 @@ func (p *PlainParser) extractEventData(entry *LogEntry, logLine string) {
 				logrus.Debug("Successfully extracted event data using regex pattern")
 				return
 			}
+			if looksLikeJSON(jsonStr) {
+				stats.InvalidJSON++
+			}
 		}
 	}
 
@@ -196,6 +332,12 @@ func (p *PlainParser) tryParseJSON(entry *LogEntry, jsonStr string) bool {
 	return false
 }
 
+// looksLikeJSON reports whether s is meant to be a JSON object, so failing to
+// parse it is a problem rather than a line without event data
+func looksLikeJSON(s string) bool {
+	return strings.HasPrefix(s, "{")
+}
+
 // Helper function to get map keys for logging
 func getMapKeysPlain(m map[string]interface{}) []string {
 	keys := make([]string, 0, len(m))
@@ -206,53 +348,351 @@ func getMapKeysPlain(m map[string]interface{}) []string {
 }
 
 func (p *PlainParser) ParseFile(filepath string) ([]*LogEntry, error) {
+	return parseFile(filepath, p.lineOptions, p.parseReader)
+}
+
+// ParseReader parses log lines from r, skipping lines that do not match the
+// log format or exceed the line size limit
+func (p *PlainParser) ParseReader(r io.Reader) ([]*LogEntry, error) {
+	return p.parseReader(r, p.lineOptions)
+}
+
+func (p *PlainParser) parseReader(r io.Reader, options lineOptions) ([]*LogEntry, error) {
+	entries, stats, skipped, err := parseLines(r, options, p.parseLine)
+	if err != nil {
+		return nil, err
+	}
+	p.stats.Add(stats)
+	p.addSkipped(skipped)
+	return entries, nil
+}
+
+// Stats returns the statistics accumulated over all readers parsed so far
+func (p *PlainParser) Stats() ParseStats {
+	return p.stats
+}
+
+// parseFile opens a log file and parses it with parseReader. The file name is
+// passed on for skipped line samples and its modification time for year
+// inference.
+func parseFile(filepath string, options lineOptions, parseReader func(io.Reader, lineOptions) ([]*LogEntry, error)) ([]*LogEntry, error) {
 	logrus.WithField("filepath", filepath).Info("Starting to parse log file")
 
-	file, err := os.Open(filepath)
+	file, err := openLogFile(filepath, progress)
 	if err != nil {
 		logrus.WithError(err).WithField("filepath", filepath).Error("Failed to open log file")
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
+	options.source = filepath
+	if options.yearFromModTime {
+		if info, err := os.Stat(filepath); err == nil {
+			options.modTime = info.ModTime()
+		}
+	}
+
+	entries, err := parseReader(file, options)
+	if err != nil {
+		logrus.WithError(err).WithField("filepath", filepath).Error("Error reading log file")
+		return nil, err
+	}
+	return entries, nil
+}
+
+// SetMaxLineBytes sets the line size limit of p if it reads lines, as the
+// built-in formats do
+func SetMaxLineBytes(p Parser, n int) {
+	if setter, ok := p.(interface{ SetMaxLineBytes(int) }); ok {
+		setter.SetMaxLineBytes(n)
+	}
+}
+
+// ErrTooManyEntries is returned by parsers whose entry limit set with
+// SetMaxEntries was exceeded
+var ErrTooManyEntries = errors.New("too many log entries")
+
+// SetMaxEntries limits the entries p parses from a reader to n if it reads
+// lines, as the built-in formats do
+func SetMaxEntries(p Parser, n int) {
+	if setter, ok := p.(interface{ SetMaxEntries(int) }); ok {
+		setter.SetMaxEntries(n)
+	}
+}
+
+// tooManyEntries returns the error of a reader with more than limit entries
+func tooManyEntries(limit int) error {
+	return fmt.Errorf("%w: more than %d", ErrTooManyEntries, limit)
+}
+
+// SetKeepRaw makes p keep the lines each entry was parsed from if it reads
+// lines, as the built-in formats do
+func SetKeepRaw(p Parser, keep bool) {
+	if setter, ok := p.(interface{ SetKeepRaw(bool) }); ok {
+		setter.SetKeepRaw(keep)
+	}
+}
+
+// LineReader reads lines up to a size limit. Unlike bufio.Scanner it does not
+// stop at a longer line, but skips it and reports it as oversized.
+type LineReader struct {
+	reader   *bufio.Reader
+	maxBytes int
+}
+
+// NewLineReader creates a line reader for lines of at most maxBytes bytes
+func NewLineReader(r io.Reader, maxBytes int) *LineReader {
+	return &LineReader{reader: bufio.NewReader(r), maxBytes: maxBytes}
+}
+
+// ReadLine returns the next line without its line ending, or oversized
+// without the line if it is longer than the limit. It returns io.EOF only
+// when no more lines are left.
+func (l *LineReader) ReadLine() (line string, oversized bool, err error) {
+	return readLine(l.reader, l.maxBytes)
+}
+
+// readLine reads the next line without its line ending. Lines longer than
+// maxBytes are consumed and reported as oversized without being buffered.
+// It returns io.EOF only when no more lines are left.
+func readLine(reader *bufio.Reader, maxBytes int) (string, bool, error) {
+	var line []byte
+	oversized := false
+	read := false
+
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(chunk) > 0 {
+			read = true
+			if !oversized && len(line)+len(chunk) > maxBytes+2 {
+				// The limit allows for the line ending, checked again below
+				oversized = true
+				line = nil
+			}
+			if !oversized {
+				line = append(line, chunk...)
+			}
+		}
+
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF {
+			if !read {
+				return "", false, io.EOF
+			}
+			break
+		}
+		if err != nil {
+			return "", false, err
+		}
+		break
+	}
+
+	if oversized {
+		return "", true, nil
+	}
+
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) > maxBytes {
+		return "", true, nil
+	}
+	return string(line), false, nil
+}
+
+// parseLines parses the records of r with parseLine, skipping empty lines,
+// records parseLine rejects and lines longer than the size limit. A record is
+// one line, or in multiline mode a start line joined with its continuation
+// lines by newlines. Records are parsed by the configured number of workers
+// and returned in input order. It returns the entries together with the
+// statistics and the first skipped lines of this reader.
+func parseLines(r io.Reader, options lineOptions, parseLine func(string, *ParseStats) (*LogEntry, error)) ([]*LogEntry, ParseStats, []SkippedLine, error) {
 	var entries []*LogEntry
-	scanner := bufio.NewScanner(file)
-	lineCount := 0
-	parsedCount := 0
-	skippedCount := 0
-
-	for scanner.Scan() {
-		lineCount++
-		line := scanner.Text()
+	// Counters of the reading loop and, as the consumer may run on another
+	// goroutine, of the parsed records
+	var stats, parsed ParseStats
+	var skipped, unmatched []SkippedLine
+	var previous time.Time
+	reader := NewLineReader(r, options.maxLineBytes)
+
+	// The pending multiline record and the number of its first line
+	var record []string
+	var recordBytes, recordLine int
+
+	year := options.year
+	if options.yearFromModTime {
+		if options.modTime.IsZero() {
+			options.modTime = time.Now()
+		}
+		year = options.modTime.Year()
+	}
+	// Entries whose timestamp got the assumed year
+	var completed []*LogEntry
+
+	skip := func(samples *[]SkippedLine, lineNumber int, text, reason string) {
+		if len(*samples) < MaxSkippedSamples {
+			*samples = append(*samples, newSkippedLine(options.source, lineNumber, text, reason))
+		}
+	}
+
+	// tooMany is set once the entry limit is exceeded, stopping the reading
+	// loop
+	var tooMany atomic.Bool
+
+	// consume handles parsed records in input order
+	consume := func(batch *recordBatch) {
+		parsed.Add(batch.stats)
+		for i, record := range batch.records {
+			entry, err := batch.entries[i], batch.errs[i]
+			if err != nil {
+				parsed.UnmatchedLines++
+				skip(&unmatched, record.line, record.text, SkipUnmatched)
+				logrus.WithError(err).WithFields(logrus.Fields{
+					"line_number": record.line,
+					"line":        record.text,
+				}).Debug("Failed to parse log line, skipping")
+				continue
+			}
+
+			if year != 0 && entry.Timestamp.Year() == 0 && !entry.Timestamp.IsZero() {
+				entry.Timestamp = withYear(entry.Timestamp, year)
+				// A jump back by months is the log wrapping around New Year
+				if !previous.IsZero() && entry.Timestamp.Before(previous.AddDate(0, -6, 0)) {
+					year++
+					entry.Timestamp = withYear(entry.Timestamp, year)
+				}
+				completed = append(completed, entry)
+			}
+
+			if !entry.Timestamp.IsZero() {
+				if !previous.IsZero() && entry.Timestamp.Before(previous) {
+					parsed.TimestampRegressions++
+					logrus.WithFields(logrus.Fields{
+						"line_number":        record.line,
+						"timestamp":          entry.Timestamp,
+						"previous_timestamp": previous,
+					}).Debug("Log timestamp went backwards")
+				}
+				previous = entry.Timestamp
+			}
+
+			if options.keepRaw {
+				entry.Raw = record.text
+			}
+			entry.Source, entry.Line = options.source, record.line
+			entries = append(entries, entry)
+			parsed.ParsedEntries++
+			if options.maxEntries > 0 && len(entries) > options.maxEntries {
+				tooMany.Store(true)
+				return
+			}
+		}
+	}
+	pipeline := newRecordPipeline(options.workers, parseLine, consume)
+
+	flushRecord := func() {
+		if len(record) == 0 {
+			return
+		}
+		stats.ContinuationLines += len(record) - 1
+		pipeline.add(lineRecord{text: strings.Join(record, "\n"), line: recordLine})
+		record = nil
+	}
+
+	for {
+		if tooMany.Load() {
+			break
+		}
+		line, oversized, err := reader.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			pipeline.close()
+			return nil, ParseStats{}, nil, fmt.Errorf("error reading file: %w", err)
+		}
+		stats.TotalLines++
+
+		if oversized {
+			stats.OversizedLines++
+			skip(&skipped, stats.TotalLines, "", SkipOversized)
+			logrus.WithFields(logrus.Fields{
+				"line_number":    stats.TotalLines,
+				"max_line_bytes": options.maxLineBytes,
+			}).Debug("Log line exceeds size limit, skipping")
+			continue
+		}
+
 		if strings.TrimSpace(line) == "" {
+			stats.EmptyLines++
 			continue // Skip empty lines
 		}
 
-		entry, err := p.Parse(line)
-		if err != nil {
-			skippedCount++
-			logrus.WithError(err).WithFields(logrus.Fields{
-				"line_number": lineCount,
-				"line":        line,
-			}).Debug("Failed to parse log line, skipping")
+		if options.multilineStart == nil {
+			pipeline.add(lineRecord{text: line, line: stats.TotalLines})
+			continue
+		}
+
+		if len(record) > 0 && !options.multilineStart.MatchString(line) {
+			// The size limit applies to whole records
+			if recordBytes+1+len(line) > options.maxLineBytes {
+				stats.OversizedLines++
+				skip(&skipped, stats.TotalLines, "", SkipOversized)
+				logrus.WithFields(logrus.Fields{
+					"line_number":    stats.TotalLines,
+					"max_line_bytes": options.maxLineBytes,
+				}).Debug("Multiline log entry exceeds size limit, skipping continuation line")
+				continue
+			}
+			record = append(record, line)
+			recordBytes += 1 + len(line)
 			continue
 		}
 
-		entries = append(entries, entry)
-		parsedCount++
+		flushRecord()
+		record = []string{line}
+		recordBytes = len(line)
+		recordLine = stats.TotalLines
+	}
+	flushRecord()
+	pipeline.close()
+	if tooMany.Load() {
+		return nil, ParseStats{}, nil, tooManyEntries(options.maxEntries)
+	}
+	stats.Add(parsed)
+
+	// Keep the first samples of both kinds in line order
+	skipped = append(skipped, unmatched...)
+	sort.SliceStable(skipped, func(i, j int) bool {
+		return skipped[i].Line < skipped[j].Line
+	})
+	if len(skipped) > MaxSkippedSamples {
+		skipped = skipped[:MaxSkippedSamples]
 	}
 
-	if err := scanner.Err(); err != nil {
-		logrus.WithError(err).WithField("filepath", filepath).Error("Error reading log file")
-		return nil, fmt.Errorf("error reading file: %w", err)
+	// Entries cannot be newer than the file they were written to, so the
+	// log started in the year before the file was last modified
+	if options.yearFromModTime && len(completed) > 0 &&
+		completed[len(completed)-1].Timestamp.After(options.modTime.Add(24*time.Hour)) {
+		logrus.WithField("mod_time", options.modTime).Debug("Log entries are newer than the file, assuming the previous year")
+		for _, entry := range completed {
+			entry.Timestamp = entry.Timestamp.AddDate(-1, 0, 0)
+		}
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"filepath":       filepath,
-		"total_lines":    lineCount,
-		"parsed_entries": parsedCount,
-		"skipped_lines":  skippedCount,
+		"total_lines":     stats.TotalLines,
+		"parsed_entries":  stats.ParsedEntries,
+		"skipped_lines":   stats.UnmatchedLines + stats.OversizedLines,
+		"parse_problems":  stats.Problems(),
+		"problem_summary": stats.Summary(),
 	}).Info("Log file parsing completed")
 
-	return entries, nil
+	return entries, stats, skipped, nil
+}
+
+// withYear returns t in the given year
+func withYear(t time.Time, year int) time.Time {
+	return time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
 }
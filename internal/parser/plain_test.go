@@ -1,8 +1,16 @@
 package parser
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 func TestPlainParser_Parse_SimpleFormat(t *testing.T) {
@@ -278,3 +286,447 @@ func TestPlainParser_CustomEventRegex(t *testing.T) {
 		t.Errorf("Parse() EventData[action] = %v, want 'click'", action)
 	}
 }
+
+func TestPlainParser_ParseReader(t *testing.T) {
+	parser := NewPlainParser()
+
+	entries, err := parser.ParseReader(strings.NewReader("login\n\n  \nlogout\n"))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "login" || entries[1].Message != "logout" {
+		t.Errorf("ParseReader() returned unexpected entries: %v", entries)
+	}
+}
+
+func TestPlainParser_ParseReader_Stats(t *testing.T) {
+	parser := NewPlainParserWithConfig("01-02 15:04:05.000", `Analytics: (.*)`, true,
+		`^(\d{2}-\d{2} \d{2}:\d{2}:\d{2}\.\d{3}|\S+ \S+)\s+(.*)$`)
+
+	input := strings.Join([]string{
+		`01-15 10:30:45.123 Analytics: {"event":"login"}`,
+		"",
+		"garbage",
+		"13-45 99:99:99.999 Analytics: {\"event\":\"logout\"}",
+		"01-15 10:30:46.000 Analytics: {\"event\":\"caf\xe9\"}",
+		"01-15 10:30:47.000 Analytics: {\"event\":",
+	}, "\r\n")
+
+	entries, err := parser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("ParseReader() expected 4 entries, got %d", len(entries))
+	}
+	if entries[2].EventData["event"] != "caf�" {
+		t.Errorf("Expected invalid UTF-8 to be replaced, got %q", entries[2].EventData["event"])
+	}
+
+	stats := parser.Stats()
+	expected := ParseStats{
+		TotalLines:        6,
+		ParsedEntries:     4,
+		EmptyLines:        1,
+		UnmatchedLines:    1,
+		InvalidUTF8Lines:  1,
+		InvalidTimestamps: 1,
+		InvalidJSON:       1,
+	}
+	if stats != expected {
+		t.Errorf("Stats() = %+v, want %+v", stats, expected)
+	}
+
+	// Stats accumulate across readers
+	if _, err := parser.ParseReader(strings.NewReader("01-15 10:30:48.000 login\n")); err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if parser.Stats().TotalLines != 7 || parser.Stats().ParsedEntries != 5 {
+		t.Errorf("Stats() should accumulate across readers, got %+v", parser.Stats())
+	}
+}
+
+func TestPlainParser_ParseReader_TimestampRegressions(t *testing.T) {
+	parser := NewPlainParserWithConfig("15:04:05", "", false, `^(\d{2}:\d{2}:\d{2})?\s*(.*)$`)
+
+	input := strings.Join([]string{
+		"10:00:05 login",
+		"10:00:01 clock changed",
+		"untimed",
+		"10:00:02 still behind",
+		"09:00:00 jumped back again",
+	}, "\n")
+
+	if _, err := parser.ParseReader(strings.NewReader(input)); err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if got := parser.Stats().TimestampRegressions; got != 2 {
+		t.Errorf("TimestampRegressions = %d, want 2", got)
+	}
+
+	// Each reader is a separate source
+	if _, err := parser.ParseReader(strings.NewReader("08:00:00 next file\n")); err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if got := parser.Stats().TimestampRegressions; got != 2 {
+		t.Errorf("TimestampRegressions = %d after a new reader, want 2", got)
+	}
+}
+
+func TestPlainParser_ParseReader_LongLines(t *testing.T) {
+	parser := NewPlainParser()
+	parser.maxLineBytes = 100
+
+	// Lines longer than bufio's default buffer must not abort parsing
+	longLine := strings.Repeat("x", 70000)
+	input := "login\n" + longLine + "\n" + strings.Repeat("y", 100) + "\nlogout"
+
+	entries, err := parser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Message != "login" || entries[2].Message != "logout" {
+		t.Errorf("ParseReader() returned unexpected entries: %d", len(entries))
+	}
+	if parser.Stats().OversizedLines != 1 {
+		t.Errorf("Expected 1 oversized line, got %+v", parser.Stats())
+	}
+
+	parser = NewPlainParser()
+	entries, err = parser.ParseReader(strings.NewReader(longLine))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 1 || len(entries[0].Message) != len(longLine) {
+		t.Errorf("Lines within the default limit should be parsed, got %d entries", len(entries))
+	}
+}
+
+func TestPlainParser_ParseReader_Multiline(t *testing.T) {
+	parser := NewPlainParserWithConfig("15:04:05", `Analytics: (\{.*\})`, true, `^(\d{2}:\d{2}:\d{2}) (.*)$`)
+	parser.SetMultilineStart(regexp.MustCompile(`^\d{2}:\d{2}:\d{2} `))
+
+	input := strings.Join([]string{
+		"  orphan continuation",
+		"10:00:00 Analytics: {",
+		`  "event": "login",`,
+		`  "user_id": "alice"`,
+		"}",
+		"",
+		"10:00:01 crash",
+		"\tat Main.run(Main.java:1)",
+		"10:00:02 Analytics: {\"event\":\"logout\"}",
+	}, "\n")
+
+	entries, err := parser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ParseReader() expected 3 entries, got %d", len(entries))
+	}
+
+	if entries[0].EventData["event"] != "login" || entries[0].EventData["user_id"] != "alice" {
+		t.Errorf("Pretty-printed JSON should be extracted, got %v", entries[0].EventData)
+	}
+	if want := "crash\n\tat Main.run(Main.java:1)"; entries[1].Message != want {
+		t.Errorf("Message = %q, want %q", entries[1].Message, want)
+	}
+	if entries[2].EventData["event"] != "logout" {
+		t.Errorf("Single line entries should still be parsed, got %v", entries[2].EventData)
+	}
+	for i, line := range []int{2, 7, 9} {
+		if entries[i].Line != line {
+			t.Errorf("entries[%d].Line = %d, want the first line of the entry %d", i, entries[i].Line, line)
+		}
+	}
+
+	expected := ParseStats{
+		TotalLines:        9,
+		ParsedEntries:     3,
+		EmptyLines:        1,
+		UnmatchedLines:    1,
+		ContinuationLines: 4,
+	}
+	if stats := parser.Stats(); stats != expected {
+		t.Errorf("Stats() = %+v, want %+v", stats, expected)
+	}
+}
+
+func TestPlainParser_ParseReader_MultilineLimit(t *testing.T) {
+	parser := NewPlainParser()
+	parser.maxLineBytes = 20
+	parser.SetMultilineStart(regexp.MustCompile(`^\S`))
+
+	entries, err := parser.ParseReader(strings.NewReader("start\n  continued\n  too much for the limit\nnext"))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Message != "start\n  continued" {
+		t.Errorf("ParseReader() returned unexpected entries: %+v", entries)
+	}
+	if stats := parser.Stats(); stats.OversizedLines != 1 || stats.ContinuationLines != 1 {
+		t.Errorf("Expected 1 oversized and 1 continuation line, got %+v", stats)
+	}
+}
+
+func TestPlainParser_ParseReader_AssumedYear(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	parser := NewPlainParserWithConfig("01-02 15:04:05", "", false, `^(\d{2}-\d{2} \d{2}:\d{2}:\d{2}) (.*)$`)
+	parser.SetTimezone(berlin)
+	parser.SetAssumedYear(2024, false)
+
+	input := "12-31 23:59:50 countdown\n01-01 00:00:05 new year\n01-01 00:00:10 fireworks"
+	entries, err := parser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+
+	expected := []time.Time{
+		time.Date(2024, 12, 31, 23, 59, 50, 0, berlin),
+		time.Date(2025, 1, 1, 0, 0, 5, 0, berlin),
+		time.Date(2025, 1, 1, 0, 0, 10, 0, berlin),
+	}
+	for i, want := range expected {
+		if !entries[i].Timestamp.Equal(want) {
+			t.Errorf("entry %d Timestamp = %v, want %v", i, entries[i].Timestamp, want)
+		}
+	}
+	if got := parser.Stats().TimestampRegressions; got != 0 {
+		t.Errorf("Wrapping around New Year is not a regression, got %d", got)
+	}
+}
+
+func TestPlainParser_ParseFile_YearFromModTime(t *testing.T) {
+	parser := NewPlainParserWithConfig("01-02 15:04:05", "", false, `^(\d{2}-\d{2} \d{2}:\d{2}:\d{2}) (.*)$`)
+	parser.SetAssumedYear(0, true)
+
+	tests := []struct {
+		name     string
+		content  string
+		modTime  time.Time
+		wantYear int
+	}{
+		{
+			name:     "same year",
+			content:  "03-01 10:00:00 login\n03-01 10:05:00 logout",
+			modTime:  time.Date(2025, 3, 1, 11, 0, 0, 0, time.UTC),
+			wantYear: 2025,
+		},
+		{
+			name:     "written last year",
+			content:  "12-30 10:00:00 login\n12-31 10:05:00 logout",
+			modTime:  time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+			wantYear: 2024,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, tt.modTime, tt.modTime); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := parser.ParseFile(path)
+			if err != nil {
+				t.Fatalf("ParseFile() unexpected error: %v", err)
+			}
+			for _, entry := range entries {
+				if entry.Timestamp.Year() != tt.wantYear {
+					t.Errorf("Timestamp = %v, want year %d", entry.Timestamp, tt.wantYear)
+				}
+			}
+		})
+	}
+}
+
+func TestLineReader_HugeLines(t *testing.T) {
+	// Far beyond bufio.Scanner's 64 KiB default and the limit
+	huge := strings.Repeat("x", 3*DefaultMaxLineBytes)
+	input := "first\r\n" + huge + "\n" + strings.Repeat("y", 100000) + "\nlast"
+
+	reader := NewLineReader(strings.NewReader(input), 200000)
+	expected := []struct {
+		length    int
+		oversized bool
+	}{
+		{length: len("first")},
+		{oversized: true},
+		{length: 100000},
+		{length: len("last")},
+	}
+	for i, want := range expected {
+		line, oversized, err := reader.ReadLine()
+		if err != nil {
+			t.Fatalf("ReadLine() %d unexpected error: %v", i, err)
+		}
+		if oversized != want.oversized || len(line) != want.length {
+			t.Errorf("ReadLine() %d = %d bytes, oversized %v, want %d bytes, oversized %v", i, len(line), oversized, want.length, want.oversized)
+		}
+	}
+	if _, _, err := reader.ReadLine(); err != io.EOF {
+		t.Errorf("ReadLine() at the end = %v, want io.EOF", err)
+	}
+}
+
+func TestSetMaxLineBytes(t *testing.T) {
+	payload := `{"event":"upload","blob":"` + strings.Repeat("a", 500000) + `"}`
+	input := "Analytics: " + payload + "\nAnalytics: {\"event\":\"done\"}"
+
+	tests := []struct {
+		name         string
+		maxLineBytes int
+		wantEntries  int
+	}{
+		{name: "default limit parses large payloads", wantEntries: 2},
+		{name: "lower limit skips them", maxLineBytes: 1000, wantEntries: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain := NewPlainParserWithConfig("", `Analytics: (.*)`, true, "")
+			logParser, _ := NewPropertyParser(plain, nil)
+			if tt.maxLineBytes > 0 {
+				SetMaxLineBytes(logParser, tt.maxLineBytes)
+			}
+
+			entries, err := logParser.ParseReader(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("ParseReader() unexpected error: %v", err)
+			}
+			if len(entries) != tt.wantEntries {
+				t.Fatalf("ParseReader() returned %d entries, want %d", len(entries), tt.wantEntries)
+			}
+			if entries[0].EventData == nil {
+				t.Error("Expected event data to be extracted")
+			}
+			if oversized := logParser.Stats().OversizedLines; oversized != 2-tt.wantEntries {
+				t.Errorf("OversizedLines = %d, want %d", oversized, 2-tt.wantEntries)
+			}
+		})
+	}
+}
+
+func TestSetKeepRaw(t *testing.T) {
+	input := "10:00:00 start\n  at Main.run\n\n10:00:01 done"
+
+	plain := NewPlainParserWithConfig("15:04:05", "", false, `^(\d{2}:\d{2}:\d{2}) (.*)$`)
+	plain.SetMultilineStart(regexp.MustCompile(`^\d{2}:\d{2}:\d{2} `))
+	logParser, _ := NewPropertyParser(plain, nil)
+
+	entries, err := logParser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if entries[0].Raw != "" {
+		t.Errorf("Raw = %q, want it empty by default", entries[0].Raw)
+	}
+
+	SetKeepRaw(logParser, true)
+	entries, err = logParser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ParseReader() returned %d entries, want 2", len(entries))
+	}
+	if entries[0].Raw != "10:00:00 start\n  at Main.run" || entries[1].Raw != "10:00:01 done" {
+		t.Errorf("Raw = %q and %q, want the lines of each entry", entries[0].Raw, entries[1].Raw)
+	}
+}
+
+func TestPlainParser_Parse_DeeplyNestedJSON(t *testing.T) {
+	parser := NewPlainParserWithConfig("", `Analytics: (.*)`, true, `^(.*)$`)
+	nested := strings.Repeat(`{"a":`, 20000) + "1" + strings.Repeat("}", 20000)
+
+	entry, err := parser.Parse("Analytics: " + nested)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if entry.EventData != nil {
+		t.Error("Parse() should not extract event data from JSON nested beyond the decoder limit")
+	}
+}
+
+func FuzzPlainParserParse(f *testing.F) {
+	f.Add(`01-15 10:30:45.123  1234  5678 I Analytics: {"event":"login"}`)
+	f.Add("13-45 99:99:99.999  0  0 X tag: {\"event\":")
+	f.Add("\xff\xfe\x00 Analytics: {\"event\":\"\xc3\x28\"}")
+	f.Add(strings.Repeat("[", 1000))
+
+	parser := NewPlainParserWithConfig("01-02 15:04:05.000", `.*Analytics: (.*)`, true,
+		`^(\d{2}-\d{2} \d{2}:\d{2}:\d{2}\.\d{3})\s+(\d+)\s+(\d+)\s+([A-Z])\s+([^:]+):\s*(.*)$`)
+
+	f.Fuzz(func(t *testing.T, line string) {
+		entry, err := parser.Parse(line)
+		if err != nil {
+			return
+		}
+		if !utf8.ValidString(entry.Message) {
+			t.Errorf("Parse(%q) returned invalid UTF-8 message %q", line, entry.Message)
+		}
+	})
+}
+
+func FuzzPlainParserParseReader(f *testing.F) {
+	f.Add("login\nlogout\n")
+	f.Add("a\r\n\r\n\xff\n")
+	f.Add(strings.Repeat("x", 300) + "\nend")
+	f.Add("start\n  continued\n\n  more\nnext")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		// Line counters must add up with and without multiline entries
+		for _, multilineStart := range []*regexp.Regexp{nil, regexp.MustCompile(`^\S`)} {
+			parser := NewPlainParser()
+			parser.maxLineBytes = 256
+			parser.SetMultilineStart(multilineStart)
+
+			entries, err := parser.ParseReader(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("ParseReader() unexpected error: %v", err)
+			}
+
+			stats := parser.Stats()
+			if stats.ParsedEntries != len(entries) {
+				t.Errorf("ParsedEntries = %d, but %d entries were returned", stats.ParsedEntries, len(entries))
+			}
+			if stats.ParsedEntries+stats.EmptyLines+stats.UnmatchedLines+stats.OversizedLines+stats.ContinuationLines != stats.TotalLines {
+				t.Errorf("Line counters do not add up: %+v", stats)
+			}
+		}
+	})
+}
+
+func TestSetMaxEntries(t *testing.T) {
+	input := strings.Repeat("Analytics: {\"event\":\"tick\"}\n", 3000)
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			plain := NewPlainParserWithConfig("", `Analytics: (.*)`, true, "")
+			plain.SetWorkers(workers)
+			logParser, _ := NewPropertyParser(plain, nil)
+
+			SetMaxEntries(logParser, 3000)
+			if _, err := logParser.ParseReader(strings.NewReader(input)); err != nil {
+				t.Fatalf("ParseReader() unexpected error at the limit: %v", err)
+			}
+
+			SetMaxEntries(logParser, 100)
+			entries, err := logParser.ParseReader(strings.NewReader(input))
+			if !errors.Is(err, ErrTooManyEntries) {
+				t.Fatalf("ParseReader() error = %v, want ErrTooManyEntries", err)
+			}
+			if entries != nil {
+				t.Errorf("ParseReader() returned %d entries past the limit", len(entries))
+			}
+		})
+	}
+}
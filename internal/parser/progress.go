@@ -0,0 +1,34 @@
+package parser
+
+import "io"
+
+// ProgressFunc receives the number of bytes of a log file read so far and the
+// size of the file. For compressed files both count compressed bytes.
+type ProgressFunc func(path string, read, size int64)
+
+// progress is notified while the built-in formats parse log files
+var progress ProgressFunc
+
+// SetProgress makes parsing log files report its progress to fn, called from
+// the goroutine reading the file, or stops reporting it when fn is nil
+func SetProgress(fn ProgressFunc) {
+	progress = fn
+}
+
+// progressReader reports the bytes read from a log file
+type progressReader struct {
+	reader io.Reader
+	path   string
+	read   int64
+	size   int64
+	report ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.report(r.path, r.read, r.size)
+	}
+	return n, err
+}
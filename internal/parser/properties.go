@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// propertyRegex extracts one event data property from log messages
+type propertyRegex struct {
+	name  string
+	regex *regexp.Regexp
+}
+
+// PropertyParser wraps a parser and adds properties extracted from the log
+// message with regular expressions to the event data of its entries, so
+// key=value text logs can be grouped and filtered like JSON events.
+// Properties already present in the event data are kept.
+type PropertyParser struct {
+	Parser
+	properties []propertyRegex
+}
+
+// NewPropertyParser wraps p with the property regexes, keyed by property
+// name. The first capture group of a regex is the property value, or the
+// whole match for regexes without groups.
+func NewPropertyParser(p Parser, regexes map[string]string) (*PropertyParser, error) {
+	properties := make([]propertyRegex, 0, len(regexes))
+	for name, pattern := range regexes {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid property regex for '%s': %w", name, err)
+		}
+		properties = append(properties, propertyRegex{name: name, regex: regex})
+	}
+	sort.Slice(properties, func(i, j int) bool {
+		return properties[i].name < properties[j].name
+	})
+
+	logrus.WithField("property_count", len(properties)).Debug("Creating property parser")
+	return &PropertyParser{Parser: p, properties: properties}, nil
+}
+
+func (p *PropertyParser) Parse(logLine string) (*LogEntry, error) {
+	entry, err := p.Parser.Parse(logLine)
+	if err != nil {
+		return nil, err
+	}
+	p.extract(entry)
+	return entry, nil
+}
+
+func (p *PropertyParser) ParseFile(filepath string) ([]*LogEntry, error) {
+	entries, err := p.Parser.ParseFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	p.extractAll(entries)
+	return entries, nil
+}
+
+func (p *PropertyParser) ParseReader(r io.Reader) ([]*LogEntry, error) {
+	entries, err := p.Parser.ParseReader(r)
+	if err != nil {
+		return nil, err
+	}
+	p.extractAll(entries)
+	return entries, nil
+}
+
+// SkippedLines returns the samples of lines skipped by the wrapped parser
+func (p *PropertyParser) SkippedLines() []SkippedLine {
+	return SkippedLines(p.Parser)
+}
+
+// SetWorkers sets the number of goroutines of the wrapped parser
+func (p *PropertyParser) SetWorkers(n int) {
+	SetWorkers(p.Parser, n)
+}
+
+// SetMaxLineBytes sets the line size limit of the wrapped parser
+func (p *PropertyParser) SetMaxLineBytes(n int) {
+	SetMaxLineBytes(p.Parser, n)
+}
+
+// SetMaxEntries sets the entry limit of the wrapped parser
+func (p *PropertyParser) SetMaxEntries(n int) {
+	SetMaxEntries(p.Parser, n)
+}
+
+// SetKeepRaw makes the wrapped parser keep the lines of each entry
+func (p *PropertyParser) SetKeepRaw(keep bool) {
+	SetKeepRaw(p.Parser, keep)
+}
+
+func (p *PropertyParser) extractAll(entries []*LogEntry) {
+	for _, entry := range entries {
+		p.extract(entry)
+	}
+}
+
+// extract adds the properties found in the message of entry to its event data
+func (p *PropertyParser) extract(entry *LogEntry) {
+	for _, property := range p.properties {
+		if _, exists := entry.EventData[property.name]; exists {
+			continue
+		}
+
+		matches := property.regex.FindStringSubmatch(entry.Message)
+		if matches == nil {
+			continue
+		}
+		value := matches[0]
+		if len(matches) > 1 {
+			value = matches[1]
+		}
+
+		if entry.EventData == nil {
+			entry.EventData = make(map[string]interface{})
+		}
+		entry.EventData[property.name] = value
+	}
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPropertyParser(t *testing.T) {
+	inner := NewPlainParserWithConfig("", `(\{.*\})`, true, "")
+	propertyParser, err := NewPropertyParser(inner, map[string]string{
+		"user_id": `uid=(\d+)`,
+		"screen":  `screen=(\w+)`,
+		"retry":   `retry`,
+	})
+	if err != nil {
+		t.Fatalf("NewPropertyParser() unexpected error: %v", err)
+	}
+
+	input := strings.Join([]string{
+		"login uid=42 screen=home",
+		"purchase retry uid=7",
+		`checkout {"event":"checkout","user_id":"alice"} uid=99`,
+		"logout",
+	}, "\n")
+
+	entries, err := propertyParser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("ParseReader() expected 4 entries, got %d", len(entries))
+	}
+
+	expected := []map[string]interface{}{
+		{"user_id": "42", "screen": "home"},
+		{"user_id": "7", "retry": "retry"},
+		// JSON properties are kept
+		{"event": "checkout", "user_id": "alice"},
+		nil,
+	}
+	for i, want := range expected {
+		got := entries[i].EventData
+		if len(got) != len(want) {
+			t.Errorf("entry %d EventData = %v, want %v", i, got, want)
+			continue
+		}
+		for key, value := range want {
+			if got[key] != value {
+				t.Errorf("entry %d EventData[%s] = %v, want %v", i, key, got[key], value)
+			}
+		}
+	}
+
+	if propertyParser.Stats().ParsedEntries != 4 {
+		t.Errorf("Stats() should come from the wrapped parser, got %+v", propertyParser.Stats())
+	}
+
+	entry, err := propertyParser.Parse("view uid=5")
+	if err != nil || entry.EventData["user_id"] != "5" {
+		t.Errorf("Parse() = %+v, %v, want user_id 5", entry, err)
+	}
+}
+
+func TestNewPropertyParser_InvalidRegex(t *testing.T) {
+	_, err := NewPropertyParser(NewPlainParser(), map[string]string{"user_id": "[invalid"})
+	if err == nil || !strings.Contains(err.Error(), "invalid property regex for 'user_id'") {
+		t.Errorf("NewPropertyParser() error = %v, want invalid regex error", err)
+	}
+}
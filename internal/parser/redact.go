@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultRedactionReplacement replaces the matches of redaction rules
+// without a replacement of their own
+const DefaultRedactionReplacement = "[REDACTED]"
+
+// RedactionRule replaces the matches of a regular expression, such as email
+// addresses or tokens. The replacement may refer to capture groups as $1.
+type RedactionRule struct {
+	Pattern     string
+	Replacement string
+}
+
+type redactionRegex struct {
+	regex       *regexp.Regexp
+	replacement string
+}
+
+// RedactingParser wraps a parser and scrubs sensitive text from the message,
+// event data and raw lines of its entries, and from samples of skipped
+// lines, so logs can be analyzed and results shared safely
+type RedactingParser struct {
+	Parser
+	rules []redactionRegex
+}
+
+// NewRedactingParser wraps p with the redaction rules, applied in order
+func NewRedactingParser(p Parser, rules []RedactionRule) (*RedactingParser, error) {
+	compiled := make([]redactionRegex, 0, len(rules))
+	for i, rule := range rules {
+		regex, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %d: %w", i+1, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = DefaultRedactionReplacement
+		}
+		compiled = append(compiled, redactionRegex{regex: regex, replacement: replacement})
+	}
+
+	logrus.WithField("rule_count", len(compiled)).Debug("Creating redacting parser")
+	return &RedactingParser{Parser: p, rules: compiled}, nil
+}
+
+func (p *RedactingParser) Parse(logLine string) (*LogEntry, error) {
+	entry, err := p.Parser.Parse(logLine)
+	if err != nil {
+		return nil, err
+	}
+	p.redactEntry(entry)
+	return entry, nil
+}
+
+func (p *RedactingParser) ParseFile(filepath string) ([]*LogEntry, error) {
+	entries, err := p.Parser.ParseFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	p.redactAll(entries)
+	return entries, nil
+}
+
+func (p *RedactingParser) ParseReader(r io.Reader) ([]*LogEntry, error) {
+	entries, err := p.Parser.ParseReader(r)
+	if err != nil {
+		return nil, err
+	}
+	p.redactAll(entries)
+	return entries, nil
+}
+
+// SkippedLines returns the samples of lines skipped by the wrapped parser,
+// redacted like entries
+func (p *RedactingParser) SkippedLines() []SkippedLine {
+	skipped := SkippedLines(p.Parser)
+	for i := range skipped {
+		skipped[i].Text = p.redact(skipped[i].Text)
+	}
+	return skipped
+}
+
+// SetWorkers sets the number of goroutines of the wrapped parser
+func (p *RedactingParser) SetWorkers(n int) {
+	SetWorkers(p.Parser, n)
+}
+
+// SetMaxLineBytes sets the line size limit of the wrapped parser
+func (p *RedactingParser) SetMaxLineBytes(n int) {
+	SetMaxLineBytes(p.Parser, n)
+}
+
+// SetMaxEntries sets the entry limit of the wrapped parser
+func (p *RedactingParser) SetMaxEntries(n int) {
+	SetMaxEntries(p.Parser, n)
+}
+
+// SetKeepRaw makes the wrapped parser keep the lines of each entry
+func (p *RedactingParser) SetKeepRaw(keep bool) {
+	SetKeepRaw(p.Parser, keep)
+}
+
+func (p *RedactingParser) redactAll(entries []*LogEntry) {
+	for _, entry := range entries {
+		p.redactEntry(entry)
+	}
+}
+
+func (p *RedactingParser) redactEntry(entry *LogEntry) {
+	entry.Message = p.redact(entry.Message)
+	entry.Raw = p.redact(entry.Raw)
+	for key, value := range entry.EventData {
+		entry.EventData[key] = p.redactValue(value)
+	}
+}
+
+// redactValue redacts the strings of an event data value, including those
+// nested in objects and arrays
+func (p *RedactingParser) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return p.redact(v)
+	case map[string]interface{}:
+		for key, nested := range v {
+			v[key] = p.redactValue(nested)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = p.redactValue(nested)
+		}
+	}
+	return value
+}
+
+func (p *RedactingParser) redact(s string) string {
+	for _, rule := range p.rules {
+		s = rule.regex.ReplaceAllString(s, rule.replacement)
+	}
+	return s
+}
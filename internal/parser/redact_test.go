@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactingParser(t *testing.T) {
+	inner := NewPlainParserWithConfig("", `(\{.*\})`, true, `^I (.*)$`)
+	SetKeepRaw(inner, true)
+	redactingParser, err := NewRedactingParser(inner, []RedactionRule{
+		{Pattern: `[\w.+-]+@[\w-]+\.[\w.]+`, Replacement: "<email>"},
+		{Pattern: `(token=)\w+`, Replacement: "${1}***"},
+		{Pattern: `\b\d{16}\b`},
+	})
+	if err != nil {
+		t.Fatalf("NewRedactingParser() unexpected error: %v", err)
+	}
+
+	input := strings.Join([]string{
+		`I login alice@example.com {"event":"login","email":"alice@example.com","meta":{"card":"4111111111111111"},"tags":["token=abc"]}`,
+		"E crash for bob@example.com",
+		"I refresh token=s3cr3t",
+	}, "\n")
+
+	entries, err := redactingParser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ParseReader() expected 2 entries, got %d", len(entries))
+	}
+
+	login := entries[0]
+	if strings.Contains(login.Message, "alice@") || strings.Contains(login.Raw, "alice@") {
+		t.Errorf("Expected the email to be redacted from the message and raw line, got %q and %q", login.Message, login.Raw)
+	}
+	if login.EventData["email"] != "<email>" || login.EventData["event"] != "login" {
+		t.Errorf("Expected event data strings to be redacted, got %v", login.EventData)
+	}
+	if card := login.EventData["meta"].(map[string]interface{})["card"]; card != DefaultRedactionReplacement {
+		t.Errorf("Expected nested values to be redacted with the default replacement, got %v", card)
+	}
+	if tag := login.EventData["tags"].([]interface{})[0]; tag != "token=***" {
+		t.Errorf("Expected array values to be redacted, got %v", tag)
+	}
+	if entries[1].Message != "refresh token=***" {
+		t.Errorf("Expected capture groups in replacements to expand, got %q", entries[1].Message)
+	}
+
+	skipped := redactingParser.SkippedLines()
+	if len(skipped) != 1 || skipped[0].Text != "E crash for <email>" {
+		t.Errorf("Expected samples of skipped lines to be redacted, got %+v", skipped)
+	}
+
+	entry, err := redactingParser.Parse("I ping carol@example.org")
+	if err != nil || entry.Message != "ping <email>" {
+		t.Errorf("Parse() = %+v, %v, want the email redacted", entry, err)
+	}
+}
+
+func TestNewRedactingParser_InvalidRegex(t *testing.T) {
+	_, err := NewRedactingParser(NewPlainParser(), []RedactionRule{{Pattern: "[invalid"}})
+	if err == nil || !strings.Contains(err.Error(), "invalid redaction pattern 1") {
+		t.Errorf("NewRedactingParser() error = %v, want invalid pattern error", err)
+	}
+}
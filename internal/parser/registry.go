@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Built-in log formats
+const (
+	PlainFormat         = "plain"
+	AndroidLogcatFormat = "android-logcat"
+	JSONLFormat         = "jsonl"
+	SyslogFormat        = "syslog"
+	// LogcatJSONFormat is the .logcat export of the Android Studio Logcat window
+	LogcatJSONFormat = "logcat-json"
+)
+
+// Android logcat threadtime layout used by the android-logcat format
+const (
+	logcatTimestampFormat = "01-02 15:04:05.000"
+	logcatLogLineRegex    = `^(\d{2}-\d{2} \d{2}:\d{2}:\d{2}\.\d{3})\s+(\d+)\s+(\d+)\s+([VDIWEFS])\s+([^:]+?)\s*:\s*(.*)$`
+)
+
+// FormatOptions are the parser config settings passed to a format factory.
+// Formats ignore the settings that do not apply to them.
+type FormatOptions struct {
+	TimestampFormat string
+	EventRegex      string
+	JSONExtraction  bool
+	LogLineRegex    string
+	Fields          JSONLFields
+	// MultilineStartRegex matches the first line of an entry; other lines
+	// are appended to the previous entry. Empty parses every line alone.
+	MultilineStartRegex string
+	// Location is the zone of timestamps without one, UTC when nil
+	Location *time.Location
+	// AssumeYear completes timestamps without a year, unless YearFromModTime
+	// infers it from the log file modification time
+	AssumeYear      int
+	YearFromModTime bool
+}
+
+// applyTo sets the line and timestamp options shared by the built-in formats
+func (o FormatOptions) applyTo(options *lineOptions) {
+	if o.MultilineStartRegex != "" {
+		options.SetMultilineStart(regexp.MustCompile(o.MultilineStartRegex))
+	}
+	options.SetTimezone(o.Location)
+	options.SetAssumedYear(o.AssumeYear, o.YearFromModTime)
+}
+
+// FormatFactory creates a parser for a log format
+type FormatFactory func(options FormatOptions) Parser
+
+var (
+	formatsMu sync.RWMutex
+	formats   = make(map[string]FormatFactory)
+)
+
+func init() {
+	RegisterFormat(PlainFormat, func(o FormatOptions) Parser {
+		p := NewPlainParserWithConfig(o.TimestampFormat, o.EventRegex, o.JSONExtraction, o.LogLineRegex)
+		o.applyTo(&p.lineOptions)
+		return p
+	})
+	RegisterFormat(AndroidLogcatFormat, func(o FormatOptions) Parser {
+		// The threadtime layout, unless the config overrides it
+		if o.TimestampFormat == "" {
+			o.TimestampFormat = logcatTimestampFormat
+		}
+		if o.LogLineRegex == "" {
+			o.LogLineRegex = logcatLogLineRegex
+		}
+		p := NewPlainParserWithConfig(o.TimestampFormat, o.EventRegex, o.JSONExtraction, o.LogLineRegex)
+		o.applyTo(&p.lineOptions)
+		return p
+	})
+	RegisterFormat(JSONLFormat, func(o FormatOptions) Parser {
+		p := NewJSONLParser(o.TimestampFormat, o.Fields)
+		o.applyTo(&p.lineOptions)
+		return p
+	})
+	RegisterFormat(SyslogFormat, func(o FormatOptions) Parser {
+		p := NewSyslogParser(o.JSONExtraction)
+		o.applyTo(&p.lineOptions)
+		return p
+	})
+	RegisterFormat(LogcatJSONFormat, func(o FormatOptions) Parser {
+		p := NewLogcatJSONParser(o.EventRegex, o.JSONExtraction)
+		o.applyTo(&p.lineOptions)
+		return p
+	})
+}
+
+// RegisterFormat makes a log format available to parser configs under name.
+// It panics if the name is empty or already registered.
+func RegisterFormat(name string, factory FormatFactory) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if name == "" || factory == nil {
+		panic("parser: RegisterFormat requires a name and a factory")
+	}
+	if _, exists := formats[name]; exists {
+		panic(fmt.Sprintf("parser: format '%s' is already registered", name))
+	}
+	formats[name] = factory
+}
+
+// Formats returns the names of the registered log formats, sorted
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewParserForFormat creates a parser for the registered format
+func NewParserForFormat(format string, options FormatOptions) (Parser, error) {
+	formatsMu.RLock()
+	factory, exists := formats[format]
+	formatsMu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown log format '%s' (valid: %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	logrus.WithField("format", format).Debug("Creating parser for log format")
+	return factory(options), nil
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	formats := strings.Join(Formats(), ",")
+	if formats != "android-logcat,jsonl,logcat-json,plain,syslog" {
+		t.Errorf("Formats() = %s, want the built-in formats sorted", formats)
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("test-format", func(o FormatOptions) Parser {
+		return NewPlainParserWithConfig("", o.EventRegex, false, "")
+	})
+	defer func() {
+		formatsMu.Lock()
+		delete(formats, "test-format")
+		formatsMu.Unlock()
+	}()
+
+	logParser, err := NewParserForFormat("test-format", FormatOptions{EventRegex: "^(.*)$"})
+	if err != nil {
+		t.Fatalf("NewParserForFormat() unexpected error: %v", err)
+	}
+	if _, ok := logParser.(*PlainParser); !ok {
+		t.Errorf("NewParserForFormat() = %T, want the parser of the registered factory", logParser)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterFormat() should panic for a duplicate name")
+		}
+	}()
+	RegisterFormat(PlainFormat, func(FormatOptions) Parser { return NewPlainParser() })
+}
+
+func TestNewParserForFormat_Unknown(t *testing.T) {
+	_, err := NewParserForFormat("xml", FormatOptions{})
+	if err == nil || !strings.Contains(err.Error(), "unknown log format 'xml'") {
+		t.Errorf("NewParserForFormat() error = %v, want unknown format error", err)
+	}
+}
+
+// FuzzFormats parses the input with every registered format, as a single line
+// and as a reader. Parsers must not panic, and the line counters of a reader
+// must add up, whatever the format.
+func FuzzFormats(f *testing.F) {
+	f.Add(`01-15 10:30:45.123  1234  5678 I Analytics: {"event":"login"}`)
+	f.Add(`{"timestamp":"2025-01-15T10:30:45Z","level":"info","message":"login","event":{"name":"login"}}`)
+	f.Add(`<134>1 2025-01-15T10:30:45.123Z host app 1234 ID47 [meta user="alice"] {"event":"login"}`)
+	f.Add(`<13>Jan 15 10:30:45 host app[1234]: {"event":"login"}`)
+	f.Add(`{"logcatMessages": [{"header": {"logLevel": "INFO", "pid": 1, "tag": "Analytics"}, "message": "{\"event\":\"login\"}"}, {"header": {"pid": "x"}}, 42]}`)
+	f.Add("login\n\n\xff\r\n" + strings.Repeat("x", 300))
+
+	options := FormatOptions{EventRegex: `Analytics: (.*)`, JSONExtraction: true}
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, format := range Formats() {
+			logParser, err := NewParserForFormat(format, options)
+			if err != nil {
+				t.Fatalf("NewParserForFormat(%s) unexpected error: %v", format, err)
+			}
+			SetMaxLineBytes(logParser, 256)
+
+			logParser.Parse(input)
+
+			entries, err := logParser.ParseReader(strings.NewReader(input))
+			if err != nil {
+				// Only a document format can reject its input as a whole
+				if format != LogcatJSONFormat {
+					t.Errorf("%s: ParseReader() unexpected error: %v", format, err)
+				}
+				continue
+			}
+			stats := logParser.Stats()
+			if stats.ParsedEntries != len(entries) {
+				t.Errorf("%s: ParsedEntries = %d, but %d entries were returned", format, stats.ParsedEntries, len(entries))
+			}
+			if stats.ParsedEntries+stats.EmptyLines+stats.UnmatchedLines+stats.OversizedLines+stats.ContinuationLines != stats.TotalLines {
+				t.Errorf("%s: line counters do not add up: %+v", format, stats)
+			}
+			if skipped := SkippedLines(logParser); len(skipped) > MaxSkippedSamples {
+				t.Errorf("%s: kept %d skipped line samples, more than %d", format, len(skipped), MaxSkippedSamples)
+			}
+		}
+	})
+}
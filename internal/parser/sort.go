@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SortByTimestamp orders entries by timestamp, for logs merged from several
+// buffers or files that interleave out of order. The sort is stable, so
+// entries with equal timestamps keep their order. Entries without a timestamp
+// stay right after the entry preceding them. It returns how often the
+// timestamp went backwards before sorting.
+func SortByTimestamp(entries []*LogEntry) int {
+	type keyedEntry struct {
+		entry *LogEntry
+		key   time.Time
+	}
+
+	keyed := make([]keyedEntry, len(entries))
+	var previous time.Time
+	regressions := 0
+	for i, entry := range entries {
+		if !entry.Timestamp.IsZero() {
+			if entry.Timestamp.Before(previous) {
+				regressions++
+			}
+			previous = entry.Timestamp
+		}
+		// Entries without a timestamp sort with the timestamp before them
+		keyed[i] = keyedEntry{entry: entry, key: previous}
+	}
+
+	if regressions == 0 {
+		return 0
+	}
+
+	sort.SliceStable(keyed, func(i, j int) bool {
+		return keyed[i].key.Before(keyed[j].key)
+	})
+	for i := range keyed {
+		entries[i] = keyed[i].entry
+	}
+
+	logrus.WithField("timestamp_regressions", regressions).Debug("Sorted entries by timestamp")
+	return regressions
+}
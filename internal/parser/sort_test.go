@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSortByTimestamp(t *testing.T) {
+	at := func(second int, message string) *LogEntry {
+		return &LogEntry{Timestamp: time.Date(2025, 1, 15, 10, 0, second, 0, time.UTC), Message: message}
+	}
+
+	tests := []struct {
+		name            string
+		entries         []*LogEntry
+		wantMessages    []string
+		wantRegressions int
+	}{
+		{
+			name:         "already sorted",
+			entries:      []*LogEntry{at(1, "a"), at(2, "b"), at(2, "c")},
+			wantMessages: []string{"a", "b", "c"},
+		},
+		{
+			name:            "interleaved buffers",
+			entries:         []*LogEntry{at(1, "main1"), at(4, "main2"), at(2, "system1"), at(5, "system2")},
+			wantMessages:    []string{"main1", "system1", "main2", "system2"},
+			wantRegressions: 1,
+		},
+		{
+			name:            "equal timestamps keep their order",
+			entries:         []*LogEntry{at(3, "a"), at(1, "b"), at(3, "c"), at(1, "d")},
+			wantMessages:    []string{"b", "d", "a", "c"},
+			wantRegressions: 2,
+		},
+		{
+			name:            "entries without timestamp follow their predecessor",
+			entries:         []*LogEntry{{Message: "header"}, at(5, "late"), {Message: "late detail"}, at(2, "early")},
+			wantMessages:    []string{"header", "early", "late", "late detail"},
+			wantRegressions: 1,
+		},
+		{
+			name:         "empty",
+			entries:      nil,
+			wantMessages: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regressions := SortByTimestamp(tt.entries)
+			if regressions != tt.wantRegressions {
+				t.Errorf("SortByTimestamp() = %d, want %d", regressions, tt.wantRegressions)
+			}
+
+			if len(tt.entries) != len(tt.wantMessages) {
+				t.Fatalf("got %d entries, want %d", len(tt.entries), len(tt.wantMessages))
+			}
+			for i, want := range tt.wantMessages {
+				if tt.entries[i].Message != want {
+					t.Errorf("entries[%d] = %q, want %q", i, tt.entries[i].Message, want)
+				}
+			}
+		})
+	}
+}
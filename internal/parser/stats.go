@@ -0,0 +1,166 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseStats reports how the lines of parsed logs were handled. Problem
+// counters record input that was skipped or repaired instead of failing the
+// whole parse.
+type ParseStats struct {
+	TotalLines    int `json:"total_lines"`
+	ParsedEntries int `json:"parsed_entries"`
+	EmptyLines    int `json:"empty_lines"`
+	// UnmatchedLines did not match the log line format
+	UnmatchedLines int `json:"unmatched_lines"`
+	// ContinuationLines were appended to the previous entry in multiline mode
+	ContinuationLines int `json:"continuation_lines"`
+	// OversizedLines were longer than the line size limit and skipped
+	OversizedLines int `json:"oversized_lines"`
+	// InvalidUTF8Lines had invalid UTF-8 sequences replaced with U+FFFD
+	InvalidUTF8Lines int `json:"invalid_utf8_lines"`
+	// InvalidTimestamps could not be parsed with the timestamp format
+	InvalidTimestamps int `json:"invalid_timestamps"`
+	// InvalidJSON matched the event regex but did not hold valid JSON
+	InvalidJSON int `json:"invalid_json"`
+	// TimestampRegressions are entries timestamped before the previous entry
+	// of the same file, e.g. after a device clock change
+	TimestampRegressions int `json:"timestamp_regressions"`
+}
+
+// Add accumulates other into s
+func (s *ParseStats) Add(other ParseStats) {
+	s.TotalLines += other.TotalLines
+	s.ParsedEntries += other.ParsedEntries
+	s.EmptyLines += other.EmptyLines
+	s.UnmatchedLines += other.UnmatchedLines
+	s.ContinuationLines += other.ContinuationLines
+	s.OversizedLines += other.OversizedLines
+	s.InvalidUTF8Lines += other.InvalidUTF8Lines
+	s.InvalidTimestamps += other.InvalidTimestamps
+	s.InvalidJSON += other.InvalidJSON
+	s.TimestampRegressions += other.TimestampRegressions
+}
+
+// Skipped returns the number of lines that could not be parsed into entries,
+// not counting empty lines
+func (s ParseStats) Skipped() int {
+	return s.UnmatchedLines + s.OversizedLines
+}
+
+// Problems returns the number of lines that were skipped or repaired because
+// of malformed input, or whose timestamp went backwards. Empty and unmatched
+// lines are expected in most logs and are not counted.
+func (s ParseStats) Problems() int {
+	return s.OversizedLines + s.InvalidUTF8Lines + s.InvalidTimestamps + s.InvalidJSON + s.TimestampRegressions
+}
+
+// Summary describes the problem counters in one line, e.g.
+// "2 oversized lines skipped, 1 invalid timestamp"
+func (s ParseStats) Summary() string {
+	var parts []string
+	if s.OversizedLines > 0 {
+		parts = append(parts, plural(s.OversizedLines, "oversized line")+" skipped")
+	}
+	if s.InvalidUTF8Lines > 0 {
+		parts = append(parts, plural(s.InvalidUTF8Lines, "line")+" with invalid UTF-8 repaired")
+	}
+	if s.InvalidTimestamps > 0 {
+		parts = append(parts, plural(s.InvalidTimestamps, "invalid timestamp"))
+	}
+	if s.InvalidJSON > 0 {
+		parts = append(parts, plural(s.InvalidJSON, "invalid JSON event"))
+	}
+	if s.TimestampRegressions > 0 {
+		parts = append(parts, plural(s.TimestampRegressions, "timestamp regression"))
+	}
+	if len(parts) == 0 {
+		return "no problems"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// MaxSkippedSamples is the number of skipped lines a parser keeps as samples
+const MaxSkippedSamples = 3
+
+// maxSampleBytes is the length skipped line samples are truncated to
+const maxSampleBytes = 200
+
+// Reasons for skipping a log line
+const (
+	SkipUnmatched = "unmatched"
+	SkipOversized = "oversized"
+)
+
+// SkippedLine is a sample of a log line that was skipped while parsing
+type SkippedLine struct {
+	// Source is the log file of the line, empty for other readers
+	Source string `json:"source,omitempty"`
+	Line   int    `json:"line"`
+	// Text is the line, truncated to a few hundred bytes; oversized lines
+	// have no text
+	Text   string `json:"text,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// String formats the sample as source:line: text
+func (l SkippedLine) String() string {
+	location := fmt.Sprintf("line %d", l.Line)
+	if l.Source != "" {
+		location = fmt.Sprintf("%s:%d", l.Source, l.Line)
+	}
+	if l.Reason == SkipOversized {
+		return location + ": (line too long)"
+	}
+	return location + ": " + l.Text
+}
+
+// newSkippedLine creates a sample of a skipped line
+func newSkippedLine(source string, line int, text, reason string) SkippedLine {
+	if len(text) > maxSampleBytes {
+		text = strings.ToValidUTF8(text[:maxSampleBytes], "") + "..."
+	}
+	return SkippedLine{Source: source, Line: line, Text: text, Reason: reason}
+}
+
+// SkipRecorder is implemented by parsers that keep samples of skipped lines
+type SkipRecorder interface {
+	// SkippedLines returns the first skipped lines of all readers parsed
+	SkippedLines() []SkippedLine
+}
+
+// SkippedLines returns the samples of lines skipped by p, or nil if p does
+// not keep them
+func SkippedLines(p Parser) []SkippedLine {
+	if recorder, ok := p.(SkipRecorder); ok {
+		return recorder.SkippedLines()
+	}
+	return nil
+}
+
+// skippedSamples keeps the first skipped lines of all readers parsed
+type skippedSamples struct {
+	samples []SkippedLine
+}
+
+// addSkipped keeps samples until MaxSkippedSamples are kept
+func (s *skippedSamples) addSkipped(samples []SkippedLine) {
+	for _, sample := range samples {
+		if len(s.samples) >= MaxSkippedSamples {
+			return
+		}
+		s.samples = append(s.samples, sample)
+	}
+}
+
+func (s *skippedSamples) SkippedLines() []SkippedLine {
+	return s.samples
+}
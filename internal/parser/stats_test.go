@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStats_Summary(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    ParseStats
+		problems int
+		want     string
+	}{
+		{
+			name:  "no problems",
+			stats: ParseStats{TotalLines: 10, ParsedEntries: 8, EmptyLines: 1, UnmatchedLines: 1},
+			want:  "no problems",
+		},
+		{
+			name:     "single problems",
+			stats:    ParseStats{OversizedLines: 1, InvalidTimestamps: 1},
+			problems: 2,
+			want:     "1 oversized line skipped, 1 invalid timestamp",
+		},
+		{
+			name:     "all problems",
+			stats:    ParseStats{OversizedLines: 2, InvalidUTF8Lines: 3, InvalidTimestamps: 4, InvalidJSON: 5, TimestampRegressions: 6},
+			problems: 20,
+			want:     "2 oversized lines skipped, 3 lines with invalid UTF-8 repaired, 4 invalid timestamps, 5 invalid JSON events, 6 timestamp regressions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.Problems(); got != tt.problems {
+				t.Errorf("Problems() = %d, want %d", got, tt.problems)
+			}
+			if got := tt.stats.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStats_Add(t *testing.T) {
+	stats := ParseStats{TotalLines: 2, ParsedEntries: 1, InvalidJSON: 1}
+	stats.Add(ParseStats{TotalLines: 3, ParsedEntries: 2, EmptyLines: 1, OversizedLines: 1})
+
+	expected := ParseStats{TotalLines: 5, ParsedEntries: 3, EmptyLines: 1, OversizedLines: 1, InvalidJSON: 1}
+	if stats != expected {
+		t.Errorf("Add() = %+v, want %+v", stats, expected)
+	}
+}
+
+func TestParser_SkippedLines(t *testing.T) {
+	parser := NewPlainParserWithConfig("", "", false, `^(\d+) (.*)$`)
+	parser.maxLineBytes = 300
+
+	input := strings.Join([]string{
+		"1 login",
+		"garbage",
+		strings.Repeat("x", 400),
+		"",
+		strings.Repeat("y", 250),
+		"more garbage",
+		"2 logout",
+	}, "\n")
+	if _, err := parser.ParseReader(strings.NewReader(input)); err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+
+	expected := []SkippedLine{
+		{Line: 2, Text: "garbage", Reason: SkipUnmatched},
+		{Line: 3, Reason: SkipOversized},
+		{Line: 5, Text: strings.Repeat("y", 200) + "...", Reason: SkipUnmatched},
+	}
+	samples := SkippedLines(parser)
+	if len(samples) != len(expected) {
+		t.Fatalf("SkippedLines() = %+v, want %+v", samples, expected)
+	}
+	for i, want := range expected {
+		if samples[i] != want {
+			t.Errorf("SkippedLines()[%d] = %+v, want %+v", i, samples[i], want)
+		}
+	}
+	if skipped := parser.Stats().Skipped(); skipped != 4 {
+		t.Errorf("Skipped() = %d, want 4", skipped)
+	}
+
+	if got := (SkippedLine{Source: "app.log", Line: 2, Text: "garbage"}).String(); got != "app.log:2: garbage" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (SkippedLine{Line: 3, Reason: SkipOversized}).String(); got != "line 3: (line too long)" {
+		t.Errorf("String() = %q", got)
+	}
+
+	wrapped, _ := NewPropertyParser(parser, nil)
+	if len(SkippedLines(wrapped)) != len(expected) {
+		t.Error("SkippedLines() should see through the property parser")
+	}
+}
@@ -0,0 +1,237 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// rfc5424Regex matches <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD [MSG]
+	rfc5424Regex = regexp.MustCompile(`^(?:<(\d{1,3})>)?1 (\S+) (\S+) (\S+) (\S+) (\S+) (-|(?:\[(?:[^\]\\"]|\\.|"(?:[^"\\]|\\.)*")*\])+)(?: (.*))?$`)
+	// rfc3164Regex matches <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG, with
+	// the priority and tag optional as in most syslog files
+	rfc3164Regex = regexp.MustCompile(`^(?:<(\d{1,3})>)?([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (\S+) (?:([^\s:\[]+)(?:\[(\d+)\])?: )?(.*)$`)
+	// sdElementRegex matches one structured data element
+	sdElementRegex = regexp.MustCompile(`\[([^\s\]]+)((?:\s+[^\s=\]]+="(?:[^"\\]|\\.)*")*)\s*\]`)
+	// sdParamRegex matches one structured data parameter
+	sdParamRegex = regexp.MustCompile(`([^\s=\]]+)="((?:[^"\\]|\\.)*)"`)
+)
+
+// rfc3164TimestampFormat is the BSD syslog timestamp, which has no year
+const rfc3164TimestampFormat = "Jan _2 15:04:05"
+
+// syslogLevels maps syslog severities to log levels
+var syslogLevels = [8]string{"F", "F", "F", "E", "W", "I", "I", "D"}
+
+// SyslogParser parses RFC 5424 and classic RFC 3164 (BSD) syslog lines. The
+// severity becomes the log level, the app name or tag the log tag, and
+// structured data parameters the event data.
+type SyslogParser struct {
+	lineOptions
+	skippedSamples
+	jsonExtraction bool
+	stats          ParseStats
+}
+
+// NewSyslogParser creates a syslog parser. With jsonExtraction, messages
+// holding a JSON object are merged into the event data.
+func NewSyslogParser(jsonExtraction bool) *SyslogParser {
+	logrus.WithField("json_extraction", jsonExtraction).Debug("Creating new syslog parser")
+
+	return &SyslogParser{
+		jsonExtraction: jsonExtraction,
+		lineOptions:    lineOptions{maxLineBytes: DefaultMaxLineBytes},
+	}
+}
+
+func (p *SyslogParser) Parse(logLine string) (*LogEntry, error) {
+	return p.parseSingle(logLine, p.parseLine)
+}
+
+// parseLine parses one syslog line, counting repaired input in stats
+func (p *SyslogParser) parseLine(logLine string, stats *ParseStats) (*LogEntry, error) {
+	logrus.WithField("log_line", logLine).Debug("Parsing syslog line")
+
+	if !utf8.ValidString(logLine) {
+		stats.InvalidUTF8Lines++
+		logLine = strings.ToValidUTF8(logLine, "\uFFFD")
+		logrus.Debug("Replaced invalid UTF-8 in log line")
+	}
+
+	// The BOM may precede the message of RFC 5424 lines
+	trimmedLine := strings.TrimSpace(strings.Replace(logLine, "\uFEFF", "", 1))
+	if trimmedLine == "" {
+		return nil, fmt.Errorf("empty log line")
+	}
+
+	// Continuation lines of multiline entries belong to the message
+	header, continuation, _ := strings.Cut(trimmedLine, "\n")
+
+	var entry *LogEntry
+	var err error
+	if matches := rfc5424Regex.FindStringSubmatch(header); matches != nil {
+		entry, err = p.parseRFC5424(matches, stats)
+	} else if matches := rfc3164Regex.FindStringSubmatch(header); matches != nil {
+		entry, err = p.parseRFC3164(matches, stats)
+	} else {
+		return nil, fmt.Errorf("invalid syslog line: %s", logLine)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if continuation != "" {
+		entry.Message += "\n" + continuation
+	}
+
+	if p.jsonExtraction {
+		p.extractEventData(entry, stats)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"timestamp": entry.Timestamp,
+		"level":     entry.Level,
+		"tag":       entry.Tag,
+		"has_event": entry.EventData != nil,
+	}).Debug("Syslog line parsed successfully")
+
+	return entry, nil
+}
+
+// parseRFC5424 builds an entry from the groups of rfc5424Regex
+func (p *SyslogParser) parseRFC5424(matches []string, stats *ParseStats) (*LogEntry, error) {
+	entry := &LogEntry{Message: matches[8]}
+
+	if err := setSyslogLevel(entry, matches[1]); err != nil {
+		return nil, err
+	}
+
+	if matches[2] != "-" {
+		if timestamp, err := time.Parse(time.RFC3339Nano, matches[2]); err == nil {
+			entry.Timestamp = timestamp
+		} else {
+			logrus.WithError(err).WithField("timestamp_str", matches[2]).Debug("Failed to parse timestamp")
+			stats.InvalidTimestamps++
+		}
+	}
+
+	if matches[4] != "-" {
+		entry.Tag = matches[4]
+	}
+	if pid, err := strconv.Atoi(matches[5]); err == nil {
+		entry.PID = pid
+	}
+
+	if matches[7] != "-" {
+		entry.EventData = parseStructuredData(matches[7])
+	}
+
+	return entry, nil
+}
+
+// parseRFC3164 builds an entry from the groups of rfc3164Regex
+func (p *SyslogParser) parseRFC3164(matches []string, stats *ParseStats) (*LogEntry, error) {
+	entry := &LogEntry{Tag: matches[4], Message: matches[6]}
+
+	if err := setSyslogLevel(entry, matches[1]); err != nil {
+		return nil, err
+	}
+
+	if timestamp, err := time.ParseInLocation(rfc3164TimestampFormat, matches[2], p.timezone()); err == nil {
+		entry.Timestamp = timestamp
+	} else {
+		logrus.WithError(err).WithField("timestamp_str", matches[2]).Debug("Failed to parse timestamp")
+		stats.InvalidTimestamps++
+	}
+
+	if pid, err := strconv.Atoi(matches[5]); err == nil {
+		entry.PID = pid
+	}
+
+	return entry, nil
+}
+
+// setSyslogLevel sets the level of entry from the severity of a PRI value
+func setSyslogLevel(entry *LogEntry, priority string) error {
+	if priority == "" {
+		return nil
+	}
+	value, err := strconv.Atoi(priority)
+	if err != nil || value > 191 {
+		return fmt.Errorf("invalid syslog priority: %s", priority)
+	}
+	entry.Level = syslogLevels[value%8]
+	return nil
+}
+
+// parseStructuredData merges the parameters of all structured data elements
+// into one map. Later elements override parameters of earlier ones.
+func parseStructuredData(sd string) map[string]interface{} {
+	data := make(map[string]interface{})
+	for _, element := range sdElementRegex.FindAllStringSubmatch(sd, -1) {
+		for _, param := range sdParamRegex.FindAllStringSubmatch(element[2], -1) {
+			data[param[1]] = unescapeSDValue(param[2])
+		}
+	}
+	return data
+}
+
+// unescapeSDValue removes the escaping of '"', '\' and ']' in a parameter value
+func unescapeSDValue(value string) string {
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\]`, `]`).Replace(value)
+}
+
+// extractEventData merges a JSON object message into the event data
+func (p *SyslogParser) extractEventData(entry *LogEntry, stats *ParseStats) {
+	message := strings.TrimSpace(entry.Message)
+	if !looksLikeJSON(message) {
+		return
+	}
+
+	var eventData map[string]interface{}
+	if err := json.Unmarshal([]byte(message), &eventData); err != nil {
+		logrus.WithField("json_str", message).Debug("Failed to parse message as JSON")
+		stats.InvalidJSON++
+		return
+	}
+
+	if entry.EventData == nil {
+		entry.EventData = eventData
+		return
+	}
+	for key, value := range eventData {
+		entry.EventData[key] = value
+	}
+}
+
+func (p *SyslogParser) ParseFile(filepath string) ([]*LogEntry, error) {
+	return parseFile(filepath, p.lineOptions, p.parseReader)
+}
+
+// ParseReader parses syslog lines from r, skipping lines that are not syslog
+// or exceed the line size limit
+func (p *SyslogParser) ParseReader(r io.Reader) ([]*LogEntry, error) {
+	return p.parseReader(r, p.lineOptions)
+}
+
+func (p *SyslogParser) parseReader(r io.Reader, options lineOptions) ([]*LogEntry, error) {
+	entries, stats, skipped, err := parseLines(r, options, p.parseLine)
+	if err != nil {
+		return nil, err
+	}
+	p.stats.Add(stats)
+	p.addSkipped(skipped)
+	return entries, nil
+}
+
+// Stats returns the statistics accumulated over all readers parsed so far
+func (p *SyslogParser) Stats() ParseStats {
+	return p.stats
+}
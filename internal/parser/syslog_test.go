@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogParser_Parse(t *testing.T) {
+	tests := []struct {
+		name           string
+		jsonExtraction bool
+		line           string
+		wantTimestamp  time.Time
+		wantLevel      string
+		wantTag        string
+		wantPID        int
+		wantMessage    string
+		wantEventData  map[string]interface{}
+		expectError    bool
+	}{
+		{
+			name:          "RFC 5424 with structured data",
+			line:          `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application"][examplePriority@32473 class="high"] An application event`,
+			wantTimestamp: time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
+			wantLevel:     "I",
+			wantTag:       "evntslog",
+			wantPID:       1234,
+			wantMessage:   "An application event",
+			wantEventData: map[string]interface{}{"iut": "3", "eventSource": "Application", "class": "high"},
+		},
+		{
+			name:          "RFC 5424 with escaped parameter and no message",
+			line:          `<11>1 2003-10-11T22:14:15Z host app - - [meta note="a \"quoted\" \] value"]`,
+			wantTimestamp: time.Date(2003, 10, 11, 22, 14, 15, 0, time.UTC),
+			wantLevel:     "E",
+			wantTag:       "app",
+			wantEventData: map[string]interface{}{"note": `a "quoted" ] value`},
+		},
+		{
+			name:           "RFC 5424 with JSON message",
+			jsonExtraction: true,
+			line:           `<14>1 2003-10-11T22:14:15Z host app - - [ctx user_id="alice"] {"event":"login"}`,
+			wantTimestamp:  time.Date(2003, 10, 11, 22, 14, 15, 0, time.UTC),
+			wantLevel:      "I",
+			wantTag:        "app",
+			wantMessage:    `{"event":"login"}`,
+			wantEventData:  map[string]interface{}{"user_id": "alice", "event": "login"},
+		},
+		{
+			name:          "RFC 3164 with priority and pid",
+			line:          `<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8`,
+			wantTimestamp: time.Date(0, 10, 11, 22, 14, 15, 0, time.UTC),
+			wantLevel:     "F",
+			wantTag:       "su",
+			wantPID:       230,
+			wantMessage:   "'su root' failed for lonvick on /dev/pts/8",
+		},
+		{
+			name:           "RFC 3164 file line without priority",
+			jsonExtraction: true,
+			line:           `Jan  5 08:00:01 web-1 checkout: {"event":"purchase","user_id":"bob"}`,
+			wantTimestamp:  time.Date(0, 1, 5, 8, 0, 1, 0, time.UTC),
+			wantTag:        "checkout",
+			wantMessage:    `{"event":"purchase","user_id":"bob"}`,
+			wantEventData:  map[string]interface{}{"event": "purchase", "user_id": "bob"},
+		},
+		{
+			name:        "not syslog",
+			line:        "just some text",
+			expectError: true,
+		},
+		{
+			name:        "invalid priority",
+			line:        "<999>Oct 11 22:14:15 host app: message",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := NewSyslogParser(tt.jsonExtraction).Parse(tt.line)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Parse() expected error, got entry %+v", entry)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if !entry.Timestamp.Equal(tt.wantTimestamp) {
+				t.Errorf("Timestamp = %v, want %v", entry.Timestamp, tt.wantTimestamp)
+			}
+			if entry.Level != tt.wantLevel {
+				t.Errorf("Level = %q, want %q", entry.Level, tt.wantLevel)
+			}
+			if entry.Tag != tt.wantTag {
+				t.Errorf("Tag = %q, want %q", entry.Tag, tt.wantTag)
+			}
+			if entry.PID != tt.wantPID {
+				t.Errorf("PID = %d, want %d", entry.PID, tt.wantPID)
+			}
+			if entry.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", entry.Message, tt.wantMessage)
+			}
+			if len(entry.EventData) != len(tt.wantEventData) {
+				t.Fatalf("EventData = %v, want %v", entry.EventData, tt.wantEventData)
+			}
+			for key, want := range tt.wantEventData {
+				if entry.EventData[key] != want {
+					t.Errorf("EventData[%s] = %v, want %v", key, entry.EventData[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSyslogParser_ParseReader_Stats(t *testing.T) {
+	parser := NewSyslogParser(true)
+
+	input := strings.Join([]string{
+		`<14>1 2025-01-15T10:30:45Z host app - - - {"event":"login"}`,
+		"",
+		"garbage",
+		`<14>1 yesterday host app - - - {"event":"action"}`,
+		`<14>1 2025-01-15T10:30:46Z host app - - - {"event":`,
+	}, "\n")
+
+	entries, err := parser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ParseReader() expected 3 entries, got %d", len(entries))
+	}
+
+	expected := ParseStats{
+		TotalLines:        5,
+		ParsedEntries:     3,
+		EmptyLines:        1,
+		UnmatchedLines:    1,
+		InvalidTimestamps: 1,
+		InvalidJSON:       1,
+	}
+	if stats := parser.Stats(); stats != expected {
+		t.Errorf("Stats() = %+v, want %+v", stats, expected)
+	}
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"sync"
+)
+
+// recordBatchSize is the number of records handed to a worker at once
+const recordBatchSize = 256
+
+// lineRecord is the input of one log entry: a line, or a multiline start line
+// joined with its continuation lines, and the number of its first line
+type lineRecord struct {
+	text string
+	line int
+}
+
+// recordBatch is a batch of records parsed by one worker. The counters that
+// parseLine updates are kept per batch, so workers never share them.
+type recordBatch struct {
+	records []lineRecord
+	entries []*LogEntry
+	errs    []error
+	stats   ParseStats
+	done    chan struct{}
+}
+
+// parse parses the records of the batch and marks it done
+func (b *recordBatch) parse(parseLine func(string, *ParseStats) (*LogEntry, error)) {
+	b.entries = make([]*LogEntry, len(b.records))
+	b.errs = make([]error, len(b.records))
+	for i, record := range b.records {
+		b.entries[i], b.errs[i] = parseLine(record.text, &b.stats)
+	}
+	close(b.done)
+}
+
+// recordPipeline parses records with a pool of workers and hands the parsed
+// batches to consume in input order, so entries keep the order of the log.
+// With a single worker records are parsed on the calling goroutine.
+type recordPipeline struct {
+	parseLine func(string, *ParseStats) (*LogEntry, error)
+	consume   func(*recordBatch)
+	workers   int
+	batch     *recordBatch
+	jobs      chan *recordBatch
+	ordered   chan *recordBatch
+	wg        sync.WaitGroup
+}
+
+func newRecordPipeline(workers int, parseLine func(string, *ParseStats) (*LogEntry, error), consume func(*recordBatch)) *recordPipeline {
+	p := &recordPipeline{parseLine: parseLine, consume: consume, workers: workers}
+	if workers <= 1 {
+		return p
+	}
+
+	p.jobs = make(chan *recordBatch, workers)
+	// Bounds the batches held in memory while an earlier one is parsed
+	p.ordered = make(chan *recordBatch, 2*workers)
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for batch := range p.jobs {
+				batch.parse(parseLine)
+			}
+		}()
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for batch := range p.ordered {
+			<-batch.done
+			consume(batch)
+		}
+	}()
+	return p
+}
+
+// add queues a record for parsing
+func (p *recordPipeline) add(record lineRecord) {
+	if p.batch == nil {
+		p.batch = &recordBatch{records: make([]lineRecord, 0, recordBatchSize), done: make(chan struct{})}
+	}
+	p.batch.records = append(p.batch.records, record)
+	if len(p.batch.records) == recordBatchSize {
+		p.dispatch()
+	}
+}
+
+// dispatch hands the current batch to the workers
+func (p *recordPipeline) dispatch() {
+	batch := p.batch
+	p.batch = nil
+	if batch == nil {
+		return
+	}
+
+	if p.workers <= 1 {
+		batch.parse(p.parseLine)
+		p.consume(batch)
+		return
+	}
+	p.ordered <- batch
+	p.jobs <- batch
+}
+
+// close parses the remaining records and waits until all batches are consumed
+func (p *recordPipeline) close() {
+	p.dispatch()
+	if p.workers <= 1 {
+		return
+	}
+	close(p.jobs)
+	close(p.ordered)
+	p.wg.Wait()
+}
+
+// SetWorkers makes p parse records with n goroutines if it supports parallel
+// parsing, as the built-in formats do
+func SetWorkers(p Parser, n int) {
+	if setter, ok := p.(interface{ SetWorkers(int) }); ok {
+		setter.SetWorkers(n)
+	}
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseReader_Workers(t *testing.T) {
+	// Enough records for several batches, with skipped lines, continuation
+	// lines and timestamp regressions spread over them
+	var lines []string
+	for i := 0; i < 5*recordBatchSize; i++ {
+		second := i % 60
+		if i%97 == 0 {
+			second = 0
+		}
+		lines = append(lines, fmt.Sprintf(`10:%02d:%02d Analytics: {"event":"e%d"}`, (i/60)%60, second, i))
+		switch {
+		case i%101 == 0:
+			lines = append(lines, "garbage")
+		case i%53 == 0:
+			lines = append(lines, "  continued")
+		case i%89 == 0:
+			lines = append(lines, "")
+		}
+	}
+	input := strings.Join(lines, "\n")
+
+	newParser := func(workers int) *PlainParser {
+		p := NewPlainParserWithConfig("15:04:05", `Analytics: (\{.*\})`, true, `^(\d{2}:\d{2}:\d{2}) (.*)$`)
+		p.SetMultilineStart(regexp.MustCompile(`^(\d{2}:\d{2}:\d{2}|garbage)`))
+		p.SetWorkers(workers)
+		return p
+	}
+
+	sequential := newParser(1)
+	want, err := sequential.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() unexpected error: %v", err)
+	}
+
+	for _, workers := range []int{2, 4, 16} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			parallel := newParser(workers)
+			got, err := parallel.ParseReader(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("ParseReader() unexpected error: %v", err)
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("ParseReader() returned %d entries, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if !reflect.DeepEqual(got[i], want[i]) {
+					t.Fatalf("entry %d = %+v, want %+v", i, got[i], want[i])
+				}
+			}
+			if parallel.Stats() != sequential.Stats() {
+				t.Errorf("Stats() = %+v, want %+v", parallel.Stats(), sequential.Stats())
+			}
+			if !reflect.DeepEqual(parallel.SkippedLines(), sequential.SkippedLines()) {
+				t.Errorf("SkippedLines() = %+v, want %+v", parallel.SkippedLines(), sequential.SkippedLines())
+			}
+		})
+	}
+
+	stats := sequential.Stats()
+	if stats.UnmatchedLines == 0 || stats.ContinuationLines == 0 || stats.TimestampRegressions == 0 || stats.EmptyLines == 0 {
+		t.Errorf("Test input should exercise all counters, got %+v", stats)
+	}
+}
+
+func TestSetWorkers(t *testing.T) {
+	plain := NewPlainParser()
+	properties, _ := NewPropertyParser(plain, map[string]string{"user_id": `uid=(\d+)`})
+
+	SetWorkers(properties, 4)
+	if plain.workers != 4 {
+		t.Errorf("SetWorkers() should reach the wrapped parser, got %d workers", plain.workers)
+	}
+}
@@ -0,0 +1,268 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// File names of the generated configs
+const (
+	ParserFile = "parser.yaml"
+	FunnelFile = "funnel.yaml"
+)
+
+// Preset is a starter parser config for a common log format, with a funnel
+// config for it and sample lines the configs are checked against
+type Preset struct {
+	Name        string
+	Description string
+	Parser      string
+	Funnel      string
+	// Samples are log lines in the preset's format, in funnel order
+	Samples []string
+}
+
+// sampleFunnel is the funnel of every preset; its steps match the sample events
+const sampleFunnel = `# Starter funnel: one step per event, in the order users reach them.
+# Step patterns are regular expressions matched against the "event" field of
+# JSON events, or against the log message when there is no event data.
+# Run it with: loglion funnel -p parser.yaml -f funnel.yaml -l <log file>
+
+name: "Onboarding"
+
+steps:
+  - name: "App Open"
+    event_pattern: "^app_open$"
+
+  - name: "Sign Up"
+    event_pattern: "^sign_up$"
+
+  - name: "Tutorial Complete"
+    event_pattern: "^tutorial_complete$"
+`
+
+// plainFunnel matches the events of the plain preset, which are log messages
+const plainFunnel = `# Starter funnel: one step per event, in the order users reach them.
+# Step patterns are regular expressions matched against the log message.
+# Run it with: loglion funnel -p parser.yaml -f funnel.yaml -l <log file>
+
+name: "Onboarding"
+
+steps:
+  - name: "App Open"
+    event_pattern: "app_open"
+
+  - name: "Sign Up"
+    event_pattern: "sign_up"
+
+  - name: "Tutorial Complete"
+    event_pattern: "tutorial_complete"
+`
+
+var presets = []Preset{
+	{
+		Name:        "android-logcat",
+		Description: "Android logcat in the threadtime layout (adb logcat -v threadtime)",
+		Parser: `# Android logcat parser configuration (adb logcat -v threadtime), e.g.
+# 05-01 10:00:00.000  1234  1234 I Analytics: {"event":"app_open"}
+
+format: android-logcat
+# JSON event data logged after the "Analytics:" tag; change it to your tag
+event_regex: ".*Analytics: (.*)"
+json_extraction: true
+`,
+		Funnel: sampleFunnel,
+		Samples: []string{
+			`05-01 10:00:00.000  1234  1234 I Analytics: {"event":"app_open"}`,
+			`05-01 10:00:05.000  1234  1234 I Analytics: {"event":"sign_up","method":"email"}`,
+			`05-01 10:01:00.000  1234  1234 I Analytics: {"event":"tutorial_complete"}`,
+		},
+	},
+	{
+		Name:        "ios-oslog",
+		Description: "iOS and macOS unified logging (log stream or log show, default style)",
+		Parser: `# iOS and macOS unified logging parser configuration (log stream or log
+# show in the default style), e.g.
+# 2024-05-01 10:00:00.000000+0200 0x1a2b3c   Default     0x0    1234   0    MyApp: [com.example.app:analytics] {"event":"app_open"}
+
+# Groups: timestamp, pid, thread and level (not captured), process, message
+timestamp_format: "2006-01-02 15:04:05.000000-0700"
+log_line_regex: "^(\\d{4}-\\d{2}-\\d{2} \\d{2}:\\d{2}:\\d{2}\\.\\d{6}[+-]\\d{4})\\s+0x[0-9a-f]+\\s+\\w+\\s+0x[0-9a-f]+\\s+(\\d+)()()\\s+\\d+\\s+([^:]+?):\\s*(.*)$"
+# JSON event data at the end of the message
+event_regex: "(\\{.*\\})\\s*$"
+json_extraction: true
+`,
+		Funnel: sampleFunnel,
+		Samples: []string{
+			`2024-05-01 10:00:00.000000+0200 0x1a2b3c   Default     0x0                  1234   0    MyApp: [com.example.app:analytics] {"event":"app_open"}`,
+			`2024-05-01 10:00:05.000000+0200 0x1a2b3c   Default     0x0                  1234   0    MyApp: [com.example.app:analytics] {"event":"sign_up","method":"email"}`,
+			`2024-05-01 10:01:00.000000+0200 0x1a2b3c   Info        0x0                  1234   0    MyApp: [com.example.app:analytics] {"event":"tutorial_complete"}`,
+		},
+	},
+	{
+		Name:        "plain",
+		Description: "Plain text, one event per line",
+		Parser: `# Plain text parser configuration: every line is an event, e.g.
+# app_open user=123
+
+# The whole line is the message step patterns are matched against
+log_line_regex: "^(.*)$"
+event_regex: "^(.*)$"
+json_extraction: false
+`,
+		Funnel: plainFunnel,
+		Samples: []string{
+			"app_open user=123",
+			"sign_up user=123 method=email",
+			"tutorial_complete user=123",
+		},
+	},
+	{
+		Name:        "jsonl",
+		Description: "JSON Lines, one JSON object per line",
+		Parser: `# JSON Lines parser configuration: every line is a JSON object, e.g.
+# {"timestamp":"2024-05-01T10:00:00Z","level":"info","event":"app_open"}
+
+format: jsonl
+# Timestamps are RFC 3339 unless timestamp_format is set
+fields:
+  timestamp: timestamp
+  level: level
+  message: message
+`,
+		Funnel: sampleFunnel,
+		Samples: []string{
+			`{"timestamp":"2024-05-01T10:00:00Z","level":"info","event":"app_open"}`,
+			`{"timestamp":"2024-05-01T10:00:05Z","level":"info","event":"sign_up","method":"email"}`,
+			`{"timestamp":"2024-05-01T10:01:00Z","level":"info","event":"tutorial_complete"}`,
+		},
+	},
+}
+
+// Presets returns the available presets
+func Presets() []Preset {
+	return presets
+}
+
+// Lookup returns the preset with the given name
+func Lookup(name string) (Preset, bool) {
+	for _, preset := range presets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// Names returns the names of the available presets
+func Names() []string {
+	names := make([]string, len(presets))
+	for i, preset := range presets {
+		names[i] = preset.Name
+	}
+	return names
+}
+
+// Write validates the configs of preset and writes them to dir as ParserFile
+// and FunnelFile, returning their paths. Existing files are only replaced
+// when force is set.
+func Write(preset Preset, dir string, force bool) ([]string, error) {
+	logrus.WithFields(logrus.Fields{
+		"preset": preset.Name,
+		"dir":    dir,
+		"force":  force,
+	}).Debug("Writing preset configs")
+
+	if err := Validate(preset); err != nil {
+		return nil, fmt.Errorf("preset %s is invalid: %w", preset.Name, err)
+	}
+
+	files := []struct {
+		path    string
+		content string
+	}{
+		{filepath.Join(dir, ParserFile), preset.Parser},
+		{filepath.Join(dir, FunnelFile), preset.Funnel},
+	}
+	if !force {
+		for _, file := range files {
+			if _, err := os.Stat(file.path); err == nil {
+				return nil, fmt.Errorf("%s already exists, use --force to overwrite it", file.path)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("failed to check %s: %w", file.path, err)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	var paths []string
+	for _, file := range files {
+		if err := os.WriteFile(file.path, []byte(file.content), 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file.path, err)
+		}
+		paths = append(paths, file.path)
+	}
+
+	logrus.WithField("files", paths).Info("Preset configs written")
+	return paths, nil
+}
+
+// Validate loads the configs of preset like the analysis commands do, parses
+// its samples and checks that they complete the funnel
+func Validate(preset Preset) error {
+	dir, err := os.MkdirTemp("", "loglion-init-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	parserPath := filepath.Join(dir, ParserFile)
+	funnelPath := filepath.Join(dir, FunnelFile)
+	if err := os.WriteFile(parserPath, []byte(preset.Parser), 0o644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(funnelPath, []byte(preset.Funnel), 0o644); err != nil {
+		return err
+	}
+
+	parserCfg, err := config.LoadParserConfig(parserPath)
+	if err != nil {
+		return err
+	}
+	funnelCfg, err := config.LoadFunnelConfig(funnelPath)
+	if err != nil {
+		return err
+	}
+	logParser, err := parserCfg.NewParser()
+	if err != nil {
+		return err
+	}
+
+	var entries []*parser.LogEntry
+	for _, sample := range preset.Samples {
+		entry, err := logParser.Parse(sample)
+		if err != nil {
+			return fmt.Errorf("failed to parse sample %q: %w", sample, err)
+		}
+		hasTimestamp := parserCfg.Format != parser.PlainFormat || parserCfg.TimestampFormat != ""
+		if hasTimestamp && entry.Timestamp.IsZero() {
+			return fmt.Errorf("no timestamp parsed from sample %q", sample)
+		}
+		entries = append(entries, entry)
+	}
+
+	result := analyzer.NewFunnelAnalyzer(funnelCfg).AnalyzeFunnel(entries, 0)
+	if !result.FunnelCompleted {
+		return fmt.Errorf("samples do not complete funnel %q", funnelCfg.Name)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPresetsAreValid(t *testing.T) {
+	for _, preset := range Presets() {
+		t.Run(preset.Name, func(t *testing.T) {
+			if err := Validate(preset); err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsBrokenPreset(t *testing.T) {
+	preset, _ := Lookup("plain")
+	preset.Samples = []string{"app_open", "tutorial_complete"}
+	if err := Validate(preset); err == nil || !strings.Contains(err.Error(), "do not complete") {
+		t.Errorf("Expected an incomplete funnel error, got %v", err)
+	}
+
+	preset, _ = Lookup("ios-oslog")
+	preset.Samples = []string{"not an oslog line"}
+	if err := Validate(preset); err == nil {
+		t.Error("Expected an error for a sample not in the preset's format")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"android-logcat", "ios-oslog", "plain", "jsonl"} {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("Expected preset %s", name)
+		}
+	}
+	if _, ok := Lookup("windows-event-log"); ok {
+		t.Error("Expected no preset for an unknown name")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "configs")
+	preset, _ := Lookup("jsonl")
+
+	paths, err := Write(preset, dir, false)
+	if err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != filepath.Join(dir, ParserFile) || paths[1] != filepath.Join(dir, FunnelFile) {
+		t.Fatalf("Unexpected paths %v", paths)
+	}
+	data, err := os.ReadFile(paths[0])
+	if err != nil || string(data) != preset.Parser {
+		t.Errorf("Expected the preset parser config, got %q (%v)", data, err)
+	}
+
+	// Existing files are kept unless forced
+	if _, err := Write(preset, dir, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("Expected an error for existing files, got %v", err)
+	}
+	plain, _ := Lookup("plain")
+	if _, err := Write(plain, dir, true); err != nil {
+		t.Fatalf("Write() with force unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(paths[0]); string(data) != plain.Parser {
+		t.Errorf("Expected the parser config to be overwritten, got %q", data)
+	}
+}
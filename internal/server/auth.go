@@ -0,0 +1,126 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Default rate limit of the API requests of every client
+const (
+	DefaultRateLimit = 10
+	DefaultRateBurst = 20
+)
+
+// maxIdleBuckets is the number of client buckets kept before full ones are
+// dropped
+const maxIdleBuckets = 10000
+
+// api wraps a handler of the REST API, which requires one of the tokens of
+// the options if any and is rate limited per client
+func (s *Server) api(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, authorized := s.authorize(r)
+
+		if s.limiter != nil {
+			if ok, retryAfter := s.limiter.allow(client); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				writeError(w, http.StatusTooManyRequests, fmt.Errorf("rate limit exceeded, retry later"))
+				return
+			}
+		}
+		if !authorized {
+			logrus.WithField("client", client).Debug("Rejected request without a valid token")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="loglion"`)
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("a valid bearer token is required"))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// authorize reports whether the request carries one of the tokens, or
+// whether no tokens are required, and names its client for rate limiting:
+// the token for authorized requests and the remote address otherwise
+func (s *Server) authorize(r *http.Request) (string, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if len(s.options.Tokens) == 0 {
+		return "addr:" + host, true
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if found {
+		for i, candidate := range s.options.Tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+				return "token:" + strconv.Itoa(i), true
+			}
+		}
+	}
+	return "addr:" + host, false
+}
+
+// rateLimiter is a token bucket per client, refilled at rate requests per
+// second up to burst
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), now: time.Now, buckets: map[string]*bucket{}}
+}
+
+// allow takes a token from the bucket of client, or returns how long until
+// one is available
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if len(l.buckets) >= maxIdleBuckets {
+		l.dropFullBuckets(now)
+	}
+	b, exists := l.buckets[client]
+	if !exists {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// dropFullBuckets forgets the clients that would have a full bucket by now,
+// which behave like new clients
+func (l *rateLimiter) dropFullBuckets(now time.Time) {
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
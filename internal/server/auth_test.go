@@ -0,0 +1,131 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+)
+
+func authorizedRequest(t *testing.T, method, url, token string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() unexpected error: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestServer_Tokens(t *testing.T) {
+	_, ts := newTestServerWithOptions(t, func(options *Options) {
+		options.Tokens = []string{"first-secret", "second-secret"}
+	})
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "first token", token: "first-secret", wantStatus: http.StatusOK},
+		{name: "second token", token: "second-secret", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := authorizedRequest(t, "GET", ts.URL+"/configs", tt.token)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("GET /configs returned status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Error("Expected a WWW-Authenticate header")
+			}
+		})
+	}
+
+	// The web UI asks for the token itself
+	if resp := authorizedRequest(t, "GET", ts.URL+"/", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("GET / returned status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if resp := authorizedRequest(t, "GET", ts.URL+"/runs", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /runs returned status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	_, ts := newTestServerWithOptions(t, func(options *Options) {
+		options.Tokens = []string{"first-secret", "second-secret"}
+		options.RateLimit = 0.01
+		options.RateBurst = 2
+	})
+
+	for i := 0; i < 2; i++ {
+		if resp := authorizedRequest(t, "GET", ts.URL+"/configs", "first-secret"); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Request %d returned status %d, want %d", i+1, resp.StatusCode, http.StatusOK)
+		}
+	}
+	resp := authorizedRequest(t, "GET", ts.URL+"/configs", "first-secret")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Request over the burst returned status %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+
+	// Every token has its own limit
+	if resp := authorizedRequest(t, "GET", ts.URL+"/configs", "second-secret"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Request with another token returned status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(2, 2)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow("alice"); !ok {
+			t.Fatalf("Request %d within the burst was rejected", i+1)
+		}
+	}
+	ok, retryAfter := limiter.allow("alice")
+	if ok || retryAfter != 500*time.Millisecond {
+		t.Errorf("allow() = %v, %v, want false, 500ms", ok, retryAfter)
+	}
+	if ok, _ := limiter.allow("bob"); !ok {
+		t.Error("Expected another client to have its own bucket")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := limiter.allow("alice"); !ok {
+		t.Error("Expected a request to be allowed once the bucket refilled")
+	}
+}
+
+func TestNew_InvalidAuthOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{name: "empty token", options: Options{Tokens: []string{""}}},
+		{name: "negative rate limit", options: Options{RateLimit: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.Parser = &config.ParserConfig{}
+			tt.options.DataDir = t.TempDir()
+			if _, err := New(tt.options); err == nil {
+				t.Error("New() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRuns is the number of analysis runs kept; older runs are dropped
+const maxRuns = 100
+
+// Kinds of analysis runs
+const (
+	FunnelRun = "funnel"
+	CountRun  = "count"
+)
+
+// Statuses of analysis runs
+const (
+	RunQueued    = "queued"
+	RunRunning   = "running"
+	RunDone      = "done"
+	RunCancelled = "cancelled"
+)
+
+// Run is an analysis of an uploaded log, kept so its results can be browsed
+// and downloaded later
+type Run struct {
+	ID      string    `json:"id"`
+	Kind    string    `json:"kind"`
+	Config  string    `json:"config"`
+	LogID   string    `json:"log_id"`
+	LogName string    `json:"log_name,omitempty"`
+	Created time.Time `json:"created"`
+	Status  string    `json:"status"`
+	// Partial is set when the run was cancelled before all entries were analyzed
+	Partial bool `json:"partial,omitempty"`
+
+	funnels []*analyzer.FunnelResult
+	count   *analyzer.CountResult
+	// result is the JSON output of the run
+	result string
+
+	// ctx is cancelled to stop the analysis, and done is closed once the run
+	// finished or was dropped
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// runResult is the outcome of the analysis of a run over entries log entries
+type runResult struct {
+	funnels   []*analyzer.FunnelResult
+	count     *analyzer.CountResult
+	partial   bool
+	formatted string
+	entries   int
+}
+
+//go:embed static
+var staticFiles embed.FS
+
+// staticHandler serves the web UI
+func staticHandler() http.Handler {
+	static, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServerFS(static)
+}
+
+// startRun adds a queued run of config over log, whose analysis is
+// cancelled with the parent context or with DELETE /runs/{id}. Runs dropped
+// from the history take their logs with them if KeepLogs is set.
+func (s *Server) startRun(parent context.Context, kind, config string, log *Log) (*Run, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(parent)
+	run := &Run{
+		ID:      id,
+		Kind:    kind,
+		Config:  config,
+		LogID:   log.ID,
+		LogName: log.Name,
+		Created: time.Now().UTC(),
+		Status:  RunQueued,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	if s.logs[log.ID] != log {
+		// Deleted since it was looked up
+		s.mu.Unlock()
+		cancel()
+		return nil, errLogDeleted
+	}
+	log.runs++
+	s.runs = append(s.runs, run)
+	var evicted []*Log
+	if len(s.runs) > maxRuns {
+		dropped := s.runs[:len(s.runs)-maxRuns]
+		s.runs = s.runs[len(s.runs)-maxRuns:]
+		evicted = s.evictLogs(dropped)
+	}
+	s.mu.Unlock()
+
+	for _, log := range evicted {
+		removeLogFile(log)
+	}
+	return run, nil
+}
+
+// evictLogs removes the logs of runs dropped from the run history that no
+// kept run refers to and returns them for their files to be deleted. Logs
+// without KeepLogs are deleted once analyzed instead. Called with s.mu held.
+func (s *Server) evictLogs(dropped []*Run) []*Log {
+	if !s.options.KeepLogs {
+		return nil
+	}
+	kept := map[string]bool{}
+	for _, run := range s.runs {
+		kept[run.LogID] = true
+	}
+
+	var evicted []*Log
+	for _, run := range dropped {
+		log, exists := s.logs[run.LogID]
+		if !exists || kept[run.LogID] || log.runs > 0 {
+			continue
+		}
+		delete(s.logs, run.LogID)
+		evicted = append(evicted, log)
+	}
+	return evicted
+}
+
+func (s *Server) setRunStatus(run *Run, status string) {
+	s.mu.Lock()
+	run.Status = status
+	s.mu.Unlock()
+}
+
+// finishRun keeps the result of run, marking it as cancelled if its analysis
+// stopped early
+func (s *Server) finishRun(run *Run, result *runResult) {
+	s.mu.Lock()
+	run.funnels = result.funnels
+	run.count = result.count
+	run.result = result.formatted
+	run.Partial = result.partial
+	run.Status = RunDone
+	if run.Partial {
+		run.Status = RunCancelled
+	} else {
+		s.updateMetrics(result)
+	}
+	s.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"id":      run.ID,
+		"kind":    run.Kind,
+		"config":  run.Config,
+		"partial": result.partial,
+	}).Info("Finished analysis run")
+	run.cancel()
+	close(run.done)
+}
+
+// updateMetrics serves the result of a finished run at /metrics, along with
+// the latest results of the other funnels. Called with s.mu held.
+func (s *Server) updateMetrics(result *runResult) {
+	s.entriesParsed += result.entries
+	for _, funnel := range result.funnels {
+		s.latestFunnels[funnel.FunnelName] = funnel
+	}
+	if result.count != nil {
+		s.latestCount = result.count
+	}
+
+	snapshot := &metrics.Snapshot{
+		EntriesParsed: s.entriesParsed,
+		Count:         s.latestCount,
+		UpdatedAt:     time.Now(),
+	}
+	for _, name := range sortedKeys(s.latestFunnels) {
+		snapshot.Funnels = append(snapshot.Funnels, s.latestFunnels[name])
+	}
+	s.metrics.Update(snapshot)
+}
+
+// dropRun removes a run that failed before producing a result
+func (s *Server) dropRun(run *Run) {
+	s.mu.Lock()
+	for i, candidate := range s.runs {
+		if candidate == run {
+			s.runs = append(s.runs[:i], s.runs[i+1:]...)
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	run.cancel()
+	close(run.done)
+}
+
+// findRun returns a copy of the run of the id path value, safe to read while
+// the run is in progress, or writes a not found error
+func (s *Server) findRun(w http.ResponseWriter, r *http.Request) (*Run, Run, bool) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, run := range s.runs {
+		if run.ID == id {
+			return run, *run, true
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("run '%s' not found", id))
+	return nil, Run{}, false
+}
+
+// handleListRuns lists the runs, latest first
+func (s *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	runs := make([]Run, len(s.runs))
+	for i, run := range s.runs {
+		runs[len(runs)-1-i] = *run
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string][]Run{"runs": runs})
+}
+
+// handleGetRun returns the results of a run as JSON, or as CSV with
+// ?format=csv. Queued runs and runs in progress are returned with 202
+// Accepted and without results.
+func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	_, run, ok := s.findRun(w, r)
+	if !ok {
+		return
+	}
+	if run.Status == RunQueued || run.Status == RunRunning {
+		writeJSON(w, http.StatusAccepted, run)
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		writeFormatted(w, run.result, nil)
+	case "csv":
+		data, err := run.csv()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%s.csv", run.Config, run.ID)))
+		w.Write(data)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported format '%s' (supported: json, csv)", format))
+	}
+}
+
+// handleCancelRun cancels a run in progress and returns its partial result
+// once the analysis stopped. Finished runs are returned unchanged, and queued
+// runs are dropped without a result.
+func (s *Server) handleCancelRun(w http.ResponseWriter, r *http.Request) {
+	run, _, ok := s.findRun(w, r)
+	if !ok {
+		return
+	}
+	run.cancel()
+	select {
+	case <-run.done:
+	case <-r.Context().Done():
+		return
+	}
+
+	s.mu.Lock()
+	result := run.result
+	s.mu.Unlock()
+	if result == "" {
+		writeError(w, http.StatusConflict, fmt.Errorf("run '%s' stopped before producing a result", run.ID))
+		return
+	}
+	logrus.WithField("id", run.ID).Info("Cancelled analysis run")
+	writeFormatted(w, result, nil)
+}
+
+// csv returns the step counts of funnel runs, with the drop-off from the
+// previous step, or the pattern counts of count runs
+func (r *Run) csv() ([]byte, error) {
+	var records [][]string
+	if r.Kind == FunnelRun {
+		records = append(records, []string{"funnel", "step", "events", "percentage", "drop_off_rate"})
+		for _, result := range r.funnels {
+			for i, step := range result.Steps {
+				dropOff := ""
+				for _, candidate := range result.DropOffs {
+					if i > 0 && candidate.From == result.Steps[i-1].Name && candidate.To == step.Name {
+						dropOff = formatFloat(candidate.DropOffRate)
+					}
+				}
+				records = append(records, []string{result.FunnelName, step.Name, strconv.Itoa(step.EventCount), formatFloat(step.Percentage), dropOff})
+			}
+		}
+	} else {
+		records = append(records, []string{"pattern", "count", "percentage"})
+		for _, pattern := range r.count.PatternCounts {
+			percentage := 0.0
+			if r.count.TotalEventsAnalyzed > 0 {
+				percentage = float64(pattern.Count) / float64(r.count.TotalEventsAnalyzed) * 100
+			}
+			records = append(records, []string{pattern.Pattern, strconv.Itoa(pattern.Count), formatFloat(percentage)})
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,582 @@
+// Package server implements the REST API of "loglion serve", which lets
+// clients upload logs and analyze them with the funnel and count configs the
+// server was started with.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/metrics"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultMaxUploadBytes is the size limit of uploaded logs
+const DefaultMaxUploadBytes = 256 << 20
+
+// Default limits of the log a job reads: compressed uploads are decompressed
+// up to DefaultMaxLogBytes, and parsing stops past DefaultMaxEntries entries
+const (
+	DefaultMaxLogBytes = 1 << 30
+	DefaultMaxEntries  = 2_000_000
+)
+
+// Default limits of the analyses run at once and waiting to run
+const (
+	DefaultMaxJobs   = 2
+	DefaultQueueSize = 16
+)
+
+// errQueueFull is returned when an analysis cannot wait for a free job slot
+var errQueueFull = errors.New("too many analyses are waiting to run, retry later")
+
+// errLogDeleted is returned when a log is deleted before a run over it starts
+var errLogDeleted = errors.New("log was deleted")
+
+// errLogTooLarge is returned when a log decompresses to more than the limit
+var errLogTooLarge = errors.New("log is too large")
+
+// CountConfig is a named set of count patterns, as read from a patterns file
+type CountConfig struct {
+	Patterns []string
+	Labels   []string
+}
+
+// Options configure a server
+type Options struct {
+	// Parser parses every uploaded log
+	Parser *config.ParserConfig
+	// Funnels and Counts are the analyses clients can run, by name
+	Funnels map[string][]*config.FunnelConfig
+	Counts  map[string]CountConfig
+	// DataDir is the directory uploaded logs are stored in
+	DataDir string
+	// MaxUploadBytes limits the size of uploaded logs; DefaultMaxUploadBytes
+	// if 0
+	MaxUploadBytes int64
+	// MaxJobs is the number of analyses run at once, each holding a parsed
+	// log in memory; DefaultMaxJobs if 0
+	MaxJobs int
+	// QueueSize is the number of analyses waiting for a job slot; further
+	// analyses are rejected. DefaultQueueSize if 0.
+	QueueSize int
+	// MaxLogBytes limits the decompressed size of a log read by a job;
+	// DefaultMaxLogBytes if 0
+	MaxLogBytes int64
+	// MaxEntries stops parsing logs with more entries, bounding the memory
+	// of a job together with MaxLogBytes; DefaultMaxEntries if 0
+	MaxEntries int
+	// KeepLogs keeps uploaded logs for further runs until the last run of a
+	// log is dropped from the run history, instead of deleting them once a
+	// run analyzed them
+	KeepLogs bool
+	// Tokens are the bearer tokens accepted by the REST API; the API is open
+	// if there are none
+	Tokens []string
+	// RateLimit is the number of API requests per second allowed per client,
+	// with bursts of up to RateBurst requests; unlimited if 0
+	RateLimit float64
+	RateBurst int
+}
+
+// Log is an uploaded log
+type Log struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name,omitempty"`
+	Size     int64     `json:"size"`
+	Uploaded time.Time `json:"uploaded"`
+	path     string
+	// runs is the number of runs in progress over the log, and analyzed is
+	// set once one of them read it
+	runs     int
+	analyzed bool
+}
+
+// Server serves the REST API
+type Server struct {
+	options Options
+	mux     *http.ServeMux
+
+	// slots holds a token for every analysis running
+	slots chan struct{}
+	// limiter limits the API requests of every client, if enabled
+	limiter *rateLimiter
+
+	mu   sync.Mutex
+	logs map[string]*Log
+	// runs holds the latest analysis runs, oldest first
+	runs []*Run
+	// queued is the number of analyses waiting for a slot
+	queued int
+
+	// metrics serves the latest results of every funnel and of the count
+	// configs at /metrics, updated as runs finish
+	metrics       *metrics.Exporter
+	latestFunnels map[string]*analyzer.FunnelResult
+	latestCount   *analyzer.CountResult
+	entriesParsed int
+
+	// beforeAnalyze is called with every run once its log is parsed, letting
+	// tests cancel runs in progress
+	beforeAnalyze func(run *Run)
+}
+
+// New creates a server for the options
+func New(options Options) (*Server, error) {
+	if options.Parser == nil {
+		return nil, fmt.Errorf("a parser config is required")
+	}
+	if options.DataDir == "" {
+		return nil, fmt.Errorf("a data directory is required")
+	}
+	if options.MaxUploadBytes == 0 {
+		options.MaxUploadBytes = DefaultMaxUploadBytes
+	}
+	if options.MaxLogBytes == 0 {
+		options.MaxLogBytes = DefaultMaxLogBytes
+	}
+	if options.MaxEntries == 0 {
+		options.MaxEntries = DefaultMaxEntries
+	}
+	if options.MaxJobs == 0 {
+		options.MaxJobs = DefaultMaxJobs
+	}
+	if options.QueueSize == 0 {
+		options.QueueSize = DefaultQueueSize
+	}
+	if options.MaxJobs < 0 || options.QueueSize < 0 || options.MaxLogBytes < 0 || options.MaxEntries < 0 {
+		return nil, fmt.Errorf("job and entry limits cannot be negative")
+	}
+	if options.RateLimit < 0 || options.RateBurst < 0 {
+		return nil, fmt.Errorf("rate limits cannot be negative")
+	}
+	if options.RateLimit > 0 && options.RateBurst == 0 {
+		options.RateBurst = int(math.Ceil(options.RateLimit))
+	}
+	for _, token := range options.Tokens {
+		if token == "" {
+			return nil, fmt.Errorf("tokens cannot be empty")
+		}
+	}
+	for name, count := range options.Counts {
+		if _, err := analyzer.NewCountAnalyzerWithOptions(count.Patterns, analyzer.CountOptions{Labels: count.Labels}); err != nil {
+			return nil, fmt.Errorf("invalid count config '%s': %w", name, err)
+		}
+	}
+
+	s := &Server{
+		options: options,
+		mux:     http.NewServeMux(),
+		slots:   make(chan struct{}, options.MaxJobs),
+		logs:    map[string]*Log{},
+		metrics: metrics.NewExporter(),
+
+		latestFunnels: map[string]*analyzer.FunnelResult{},
+	}
+	s.metrics.Update(&metrics.Snapshot{})
+	if options.RateLimit > 0 {
+		s.limiter = newRateLimiter(options.RateLimit, options.RateBurst)
+	}
+	s.mux.HandleFunc("GET /configs", s.api(s.handleConfigs))
+	s.mux.HandleFunc("GET /logs", s.api(s.handleListLogs))
+	s.mux.HandleFunc("POST /logs", s.api(s.handleUpload))
+	s.mux.HandleFunc("GET /logs/{id}", s.api(s.handleGetLog))
+	s.mux.HandleFunc("DELETE /logs/{id}", s.api(s.handleDeleteLog))
+	s.mux.HandleFunc("GET /logs/{id}/funnels/{name}", s.api(s.handleFunnel))
+	s.mux.HandleFunc("GET /logs/{id}/counts/{name}", s.api(s.handleCount))
+	s.mux.HandleFunc("GET /runs", s.api(s.handleListRuns))
+	s.mux.HandleFunc("GET /runs/{id}", s.api(s.handleGetRun))
+	s.mux.HandleFunc("DELETE /runs/{id}", s.api(s.handleCancelRun))
+	s.mux.HandleFunc("GET /metrics", s.api(s.metrics.ServeHTTP))
+	// The web UI itself holds no data and asks for a token when the API
+	// requires one
+	s.mux.Handle("GET /", staticHandler())
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logrus.WithFields(logrus.Fields{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	}).Debug("Handling request")
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleConfigs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string][]string{
+		"funnels": sortedKeys(s.options.Funnels),
+		"counts":  sortedKeys(s.options.Counts),
+	})
+}
+
+func (s *Server) handleListLogs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	logs := make([]*Log, 0, len(s.logs))
+	for _, log := range s.logs {
+		logs = append(logs, log)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(logs, func(i, j int) bool {
+		return logs[i].Uploaded.Before(logs[j].Uploaded)
+	})
+	writeJSON(w, http.StatusOK, map[string][]*Log{"logs": logs})
+}
+
+// handleUpload stores the request body as a log. The name query parameter
+// names the log, e.g. after the uploaded file.
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	id, err := newID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	path := filepath.Join(s.options.DataDir, id+".log")
+
+	file, err := os.Create(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to store log: %w", err))
+		return
+	}
+	size, err := io.Copy(file, http.MaxBytesReader(w, r.Body, s.options.MaxUploadBytes))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("log exceeds the upload limit of %d bytes", maxBytesErr.Limit))
+			return
+		}
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to read log: %w", err))
+		return
+	}
+
+	log := &Log{ID: id, Name: r.URL.Query().Get("name"), Size: size, Uploaded: time.Now().UTC(), path: path}
+	s.mu.Lock()
+	s.logs[id] = log
+	s.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"id":   id,
+		"name": log.Name,
+		"size": size,
+	}).Info("Stored uploaded log")
+	writeJSON(w, http.StatusCreated, log)
+}
+
+func (s *Server) handleGetLog(w http.ResponseWriter, r *http.Request) {
+	log, ok := s.findLog(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, log)
+}
+
+func (s *Server) handleDeleteLog(w http.ResponseWriter, r *http.Request) {
+	log, ok := s.findLog(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	delete(s.logs, log.ID)
+	s.mu.Unlock()
+
+	if err := os.Remove(log.path); err != nil && !os.IsNotExist(err) {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete log: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleFunnel(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	funnelCfgs, exists := s.options.Funnels[name]
+	if !exists {
+		writeError(w, http.StatusNotFound, fmt.Errorf("funnel config '%s' not found", name))
+		return
+	}
+
+	s.execute(w, r, FunnelRun, name, func(ctx context.Context, entries []*parser.LogEntry) (*runResult, error) {
+		result := &runResult{funnels: make([]*analyzer.FunnelResult, len(funnelCfgs))}
+		for i, funnelCfg := range funnelCfgs {
+			result.funnels[i] = analyzer.NewFunnelAnalyzer(funnelCfg).Analyze(ctx, entries)
+			result.partial = result.partial || result.funnels[i].Partial
+		}
+
+		formatter := output.NewFormatterWithOptions(output.JSONFormat, output.Options{})
+		var err error
+		if len(result.funnels) == 1 {
+			result.formatted, err = formatter.FormatFunnel(result.funnels[0])
+		} else {
+			result.formatted, err = formatter.FormatFunnels(result.funnels)
+		}
+		return result, err
+	})
+}
+
+func (s *Server) handleCount(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	count, exists := s.options.Counts[name]
+	if !exists {
+		writeError(w, http.StatusNotFound, fmt.Errorf("count config '%s' not found", name))
+		return
+	}
+
+	s.execute(w, r, CountRun, name, func(ctx context.Context, entries []*parser.LogEntry) (*runResult, error) {
+		countAnalyzer, err := analyzer.NewCountAnalyzerWithOptions(count.Patterns, analyzer.CountOptions{Labels: count.Labels})
+		if err != nil {
+			return nil, err
+		}
+		result := &runResult{count: countAnalyzer.AnalyzeCountContext(ctx, entries)}
+		result.partial = result.count.Partial
+		result.formatted, err = output.NewFormatterWithOptions(output.JSONFormat, output.Options{}).FormatCount(result.count)
+		return result, err
+	})
+}
+
+// execute runs analyze over the entries of the log of the id path value as a
+// run of config and writes its JSON result. The run waits in the queue for a
+// job slot and is listed while it is in progress; analyze stops early, with a
+// partial result, when the run is cancelled or the client disconnects.
+func (s *Server) execute(w http.ResponseWriter, r *http.Request, kind, config string, analyze func(ctx context.Context, entries []*parser.LogEntry) (*runResult, error)) {
+	log, ok := s.findLog(w, r)
+	if !ok {
+		return
+	}
+	run, err := s.startRun(r.Context(), kind, config, log)
+	if errors.Is(err, errLogDeleted) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("log '%s' not found", log.ID))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Location", "/runs/"+run.ID)
+
+	if err := s.acquireSlot(run); err != nil {
+		s.dropRun(run)
+		s.releaseLog(log, false)
+		if errors.Is(err, errQueueFull) {
+			w.Header().Set("Retry-After", "5")
+			writeError(w, http.StatusServiceUnavailable, err)
+		} else {
+			writeError(w, http.StatusConflict, fmt.Errorf("run cancelled before it started: %w", err))
+		}
+		return
+	}
+	defer func() { <-s.slots }()
+
+	entries, status, err := s.parseLog(log)
+	if err != nil {
+		s.dropRun(run)
+		s.releaseLog(log, true)
+		writeError(w, status, err)
+		return
+	}
+	if s.beforeAnalyze != nil {
+		s.beforeAnalyze(run)
+	}
+
+	result, err := analyze(run.ctx, entries)
+	s.releaseLog(log, true)
+	if err != nil {
+		s.dropRun(run)
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to format results: %w", err))
+		return
+	}
+	result.entries = len(entries)
+	s.finishRun(run, result)
+	writeFormatted(w, result.formatted, nil)
+}
+
+// acquireSlot waits for a job slot for run, unless the queue is full or the
+// run is cancelled while waiting
+func (s *Server) acquireSlot(run *Run) error {
+	select {
+	case s.slots <- struct{}{}:
+		s.setRunStatus(run, RunRunning)
+		return nil
+	default:
+	}
+
+	s.mu.Lock()
+	if s.queued >= s.options.QueueSize {
+		s.mu.Unlock()
+		return errQueueFull
+	}
+	s.queued++
+	s.mu.Unlock()
+	logrus.WithField("id", run.ID).Debug("Analysis run queued")
+
+	defer func() {
+		s.mu.Lock()
+		s.queued--
+		s.mu.Unlock()
+	}()
+	select {
+	case s.slots <- struct{}{}:
+		s.setRunStatus(run, RunRunning)
+		return nil
+	case <-run.ctx.Done():
+		return run.ctx.Err()
+	}
+}
+
+// findLog returns the log of the id path value, or writes a not found error
+func (s *Server) findLog(w http.ResponseWriter, r *http.Request) (*Log, bool) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	log, exists := s.logs[id]
+	s.mu.Unlock()
+	if !exists {
+		writeError(w, http.StatusNotFound, fmt.Errorf("log '%s' not found", id))
+	}
+	return log, exists
+}
+
+// releaseLog ends a run over log, which analyzed it unless the run was
+// rejected before reading it. Without KeepLogs the log is deleted once it was
+// analyzed and no other run over it is in progress.
+func (s *Server) releaseLog(log *Log, analyzed bool) {
+	s.mu.Lock()
+	log.runs--
+	log.analyzed = log.analyzed || analyzed
+	remove := !s.options.KeepLogs && log.analyzed && log.runs == 0 && s.logs[log.ID] == log
+	if remove {
+		delete(s.logs, log.ID)
+	}
+	s.mu.Unlock()
+
+	if remove {
+		removeLogFile(log)
+	}
+}
+
+// removeLogFile deletes the stored file of a log dropped by the server
+func removeLogFile(log *Log) {
+	if err := os.Remove(log.path); err != nil && !os.IsNotExist(err) {
+		logrus.WithError(err).WithField("id", log.ID).Warn("Failed to delete log")
+		return
+	}
+	logrus.WithField("id", log.ID).Debug("Deleted log")
+}
+
+// parseLog parses log with the parser config and applies its entry filter,
+// returning the response status of an error. Parsing stops as soon as the
+// decompressed log exceeds MaxLogBytes or MaxEntries, so a small compressed
+// upload cannot expand into unbounded memory.
+func (s *Server) parseLog(log *Log) ([]*parser.LogEntry, int, error) {
+	logParser, err := s.options.Parser.NewParser()
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to create log parser: %w", err)
+	}
+	parser.SetMaxEntries(logParser, s.options.MaxEntries)
+
+	file, err := parser.OpenLogFile(log.path)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, fmt.Errorf("failed to open log: %w", err)
+	}
+	defer file.Close()
+
+	entries, err := logParser.ParseReader(&limitedReader{reader: file, remaining: s.options.MaxLogBytes})
+	switch {
+	case errors.Is(err, errLogTooLarge):
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("log is larger than the limit of %d bytes when decompressed", s.options.MaxLogBytes)
+	case errors.Is(err, parser.ErrTooManyEntries):
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("log has more than the limit of %d entries", s.options.MaxEntries)
+	case err != nil:
+		return nil, http.StatusUnprocessableEntity, fmt.Errorf("failed to parse log: %w", err)
+	}
+	// Parsers from plugins may not support the entry limit
+	if len(entries) > s.options.MaxEntries {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("log has more than the limit of %d entries", s.options.MaxEntries)
+	}
+	return parser.FilterEntries(entries, s.options.Parser.EntryFilter()), http.StatusOK, nil
+}
+
+// limitedReader reads up to remaining bytes and fails with errLogTooLarge
+// once more are left, unlike io.LimitReader which ends the input silently
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, errLogTooLarge
+	}
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, errLogTooLarge
+	}
+	return n, err
+}
+
+func newID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+// writeFormatted writes JSON output of a formatter
+func writeFormatted(w http.ResponseWriter, formatted string, err error) {
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to format results: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, formatted)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	logrus.WithError(err).WithField("status", status).Debug("Request failed")
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
@@ -0,0 +1,413 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parfenovvs/loglion/internal/config"
+)
+
+const testLog = `{"event": "login"}
+{"event": "view"}
+{"event": "purchase"}
+{"event": "login"}
+`
+
+func newTestServer(t *testing.T, maxUploadBytes int64) *httptest.Server {
+	t.Helper()
+	_, ts := newTestServerWithOptions(t, func(options *Options) {
+		options.MaxUploadBytes = maxUploadBytes
+	})
+	return ts
+}
+
+// newTestServerWithOptions serves the test configs with options changed by
+// modify
+func newTestServerWithOptions(t *testing.T, modify func(options *Options)) (*Server, *httptest.Server) {
+	t.Helper()
+	parserCfg := &config.ParserConfig{JSONExtraction: true}
+	if err := parserCfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+	funnelCfg := &config.FunnelConfig{
+		Name: "Purchase",
+		Steps: []config.Step{
+			{Name: "Login", EventPattern: "login"},
+			{Name: "Purchase", EventPattern: "purchase"},
+		},
+	}
+	if err := funnelCfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	options := Options{
+		Parser:  parserCfg,
+		Funnels: map[string][]*config.FunnelConfig{"purchase": {funnelCfg}},
+		Counts:  map[string]CountConfig{"sessions": {Patterns: []string{"login", "view"}, Labels: []string{"Logins", ""}}},
+		DataDir: t.TempDir(),
+	}
+	modify(&options)
+	s, err := New(options)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+func request(t *testing.T, method, url, body string, wantStatus int) map[string]interface{} {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() unexpected error: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("%s %s returned status %d, want %d", method, url, resp.StatusCode, wantStatus)
+	}
+	decoded := map[string]interface{}{}
+	if resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+			t.Fatalf("Failed to decode response of %s %s: %v", method, url, err)
+		}
+	}
+	return decoded
+}
+
+func TestServer(t *testing.T) {
+	_, ts := newTestServerWithOptions(t, func(options *Options) {
+		options.KeepLogs = true
+	})
+
+	configs := request(t, "GET", ts.URL+"/configs", "", http.StatusOK)
+	if configs["funnels"].([]interface{})[0] != "purchase" || configs["counts"].([]interface{})[0] != "sessions" {
+		t.Errorf("Unexpected configs: %v", configs)
+	}
+
+	uploaded := request(t, "POST", ts.URL+"/logs?name=app.log", testLog, http.StatusCreated)
+	id := uploaded["id"].(string)
+	if uploaded["name"] != "app.log" || uploaded["size"] != float64(len(testLog)) {
+		t.Errorf("Unexpected uploaded log: %v", uploaded)
+	}
+
+	logs := request(t, "GET", ts.URL+"/logs", "", http.StatusOK)["logs"].([]interface{})
+	if len(logs) != 1 || logs[0].(map[string]interface{})["id"] != id {
+		t.Errorf("Expected the uploaded log in the list, got %v", logs)
+	}
+
+	funnel := request(t, "GET", ts.URL+"/logs/"+id+"/funnels/purchase", "", http.StatusOK)
+	if funnel["funnel_name"] != "Purchase" || funnel["conversions_found"] != float64(1) {
+		t.Errorf("Unexpected funnel result: %v", funnel)
+	}
+
+	count := request(t, "GET", ts.URL+"/logs/"+id+"/counts/sessions", "", http.StatusOK)
+	if count["total_events_analyzed"] != float64(4) {
+		t.Errorf("Unexpected count result: %v", count)
+	}
+
+	runs := request(t, "GET", ts.URL+"/runs", "", http.StatusOK)["runs"].([]interface{})
+	if len(runs) != 2 || runs[0].(map[string]interface{})["kind"] != CountRun || runs[1].(map[string]interface{})["config"] != "purchase" {
+		t.Errorf("Expected the count and funnel runs, latest first, got %v", runs)
+	}
+
+	request(t, "DELETE", ts.URL+"/logs/"+id, "", http.StatusNoContent)
+	request(t, "GET", ts.URL+"/logs/"+id, "", http.StatusNotFound)
+}
+
+func TestServer_Runs(t *testing.T) {
+	ts := newTestServer(t, 0)
+	id := request(t, "POST", ts.URL+"/logs", testLog, http.StatusCreated)["id"].(string)
+
+	resp, err := http.Get(ts.URL + "/logs/" + id + "/funnels/purchase")
+	if err != nil {
+		t.Fatalf("GET funnel failed: %v", err)
+	}
+	resp.Body.Close()
+	runURL := ts.URL + resp.Header.Get("Location")
+
+	if result := request(t, "GET", runURL, "", http.StatusOK); result["funnel_name"] != "Purchase" {
+		t.Errorf("Unexpected run result: %v", result)
+	}
+
+	resp, err = http.Get(runURL + "?format=csv")
+	if err != nil {
+		t.Fatalf("GET run CSV failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	expected := "funnel,step,events,percentage,drop_off_rate\nPurchase,Login,2,100,\nPurchase,Purchase,1,50,50\n"
+	if string(body) != expected {
+		t.Errorf("Run CSV = %q, want %q", body, expected)
+	}
+	if disposition := resp.Header.Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment; filename=\"purchase-") {
+		t.Errorf("Expected the CSV as an attachment, got %q", disposition)
+	}
+
+	request(t, "GET", runURL+"?format=xml", "", http.StatusBadRequest)
+	request(t, "GET", ts.URL+"/runs/unknown", "", http.StatusNotFound)
+}
+
+func TestServer_CancelRun(t *testing.T) {
+	s, ts := newTestServerWithOptions(t, func(options *Options) {})
+	started := make(chan *Run, 1)
+	s.beforeAnalyze = func(run *Run) {
+		started <- run
+		// Hold the analysis until the run is cancelled
+		<-run.ctx.Done()
+	}
+	id := request(t, "POST", ts.URL+"/logs", testLog, http.StatusCreated)["id"].(string)
+
+	analyzed := make(chan map[string]interface{}, 1)
+	go func() {
+		resp, err := http.Get(ts.URL + "/logs/" + id + "/funnels/purchase")
+		if err != nil {
+			analyzed <- nil
+			return
+		}
+		defer resp.Body.Close()
+		result := map[string]interface{}{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		analyzed <- result
+	}()
+	run := <-started
+
+	runs := request(t, "GET", ts.URL+"/runs", "", http.StatusOK)["runs"].([]interface{})
+	if len(runs) != 1 || runs[0].(map[string]interface{})["status"] != RunRunning {
+		t.Fatalf("Expected the run in progress, got %v", runs)
+	}
+	if inProgress := request(t, "GET", ts.URL+"/runs/"+run.ID, "", http.StatusAccepted); inProgress["id"] != run.ID {
+		t.Errorf("Expected the run without results, got %v", inProgress)
+	}
+
+	cancelled := request(t, "DELETE", ts.URL+"/runs/"+run.ID, "", http.StatusOK)
+	if cancelled["partial"] != true || cancelled["funnel_name"] != "Purchase" {
+		t.Errorf("Expected a partial funnel result, got %v", cancelled)
+	}
+	if result := <-analyzed; result["partial"] != true {
+		t.Errorf("Expected the analysis request to return the partial result, got %v", result)
+	}
+
+	runs = request(t, "GET", ts.URL+"/runs", "", http.StatusOK)["runs"].([]interface{})
+	if status := runs[0].(map[string]interface{})["status"]; status != RunCancelled {
+		t.Errorf("Expected the run to be cancelled, got status %v", status)
+	}
+	if result := request(t, "GET", ts.URL+"/runs/"+run.ID, "", http.StatusOK); result["partial"] != true {
+		t.Errorf("Expected the partial result of the cancelled run, got %v", result)
+	}
+	request(t, "DELETE", ts.URL+"/runs/unknown", "", http.StatusNotFound)
+}
+
+func TestServer_JobLimits(t *testing.T) {
+	s, ts := newTestServerWithOptions(t, func(options *Options) {
+		options.MaxJobs = 1
+		options.QueueSize = 1
+	})
+	started := make(chan *Run, 2)
+	release := make(chan struct{})
+	s.beforeAnalyze = func(run *Run) {
+		started <- run
+		<-release
+	}
+	id := request(t, "POST", ts.URL+"/logs", testLog, http.StatusCreated)["id"].(string)
+
+	statuses := make(chan int, 2)
+	analyze := func(path string) {
+		resp, err := http.Get(ts.URL + "/logs/" + id + path)
+		if err != nil {
+			statuses <- 0
+			return
+		}
+		resp.Body.Close()
+		statuses <- resp.StatusCode
+	}
+	go analyze("/funnels/purchase")
+	<-started
+	go analyze("/counts/sessions")
+
+	// The second analysis waits for the job slot of the first
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runs := request(t, "GET", ts.URL+"/runs", "", http.StatusOK)["runs"].([]interface{})
+		if len(runs) == 2 && runs[0].(map[string]interface{})["status"] == RunQueued {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the second run to be queued, got %v", runs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	full := request(t, "GET", ts.URL+"/logs/"+id+"/funnels/purchase", "", http.StatusServiceUnavailable)
+	if full["error"] != errQueueFull.Error() {
+		t.Errorf("Unexpected error: %v", full)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if status := <-statuses; status != http.StatusOK {
+			t.Errorf("Expected queued analyses to succeed, got status %d", status)
+		}
+	}
+	if runs := request(t, "GET", ts.URL+"/runs", "", http.StatusOK)["runs"].([]interface{}); len(runs) != 2 {
+		t.Errorf("Expected the rejected run to be dropped, got %v", runs)
+	}
+}
+
+func TestServer_MaxEntries(t *testing.T) {
+	_, ts := newTestServerWithOptions(t, func(options *Options) {
+		options.MaxEntries = 2
+	})
+	id := request(t, "POST", ts.URL+"/logs", testLog, http.StatusCreated)["id"].(string)
+
+	tooMany := request(t, "GET", ts.URL+"/logs/"+id+"/funnels/purchase", "", http.StatusRequestEntityTooLarge)
+	if tooMany["error"] != "log has more than the limit of 2 entries" {
+		t.Errorf("Unexpected error: %v", tooMany)
+	}
+}
+
+func TestServer_MaxLogBytes(t *testing.T) {
+	_, ts := newTestServerWithOptions(t, func(options *Options) {
+		options.MaxLogBytes = 1000
+	})
+	// A small upload that decompresses past the limit
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(strings.Repeat(testLog, 1000)))
+	writer.Close()
+	id := request(t, "POST", ts.URL+"/logs", compressed.String(), http.StatusCreated)["id"].(string)
+
+	tooLarge := request(t, "GET", ts.URL+"/logs/"+id+"/counts/sessions", "", http.StatusRequestEntityTooLarge)
+	if tooLarge["error"] != "log is larger than the limit of 1000 bytes when decompressed" {
+		t.Errorf("Unexpected error: %v", tooLarge)
+	}
+}
+
+func TestServer_DeletesAnalyzedLogs(t *testing.T) {
+	s, ts := newTestServerWithOptions(t, func(options *Options) {})
+	id := request(t, "POST", ts.URL+"/logs", testLog, http.StatusCreated)["id"].(string)
+	path := s.logs[id].path
+
+	request(t, "GET", ts.URL+"/logs/"+id+"/counts/sessions", "", http.StatusOK)
+	request(t, "GET", ts.URL+"/logs/"+id, "", http.StatusNotFound)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the analyzed log file to be deleted, got %v", err)
+	}
+}
+
+func TestServer_KeepLogs(t *testing.T) {
+	s, ts := newTestServerWithOptions(t, func(options *Options) {
+		options.KeepLogs = true
+	})
+	first := request(t, "POST", ts.URL+"/logs", testLog, http.StatusCreated)["id"].(string)
+	second := request(t, "POST", ts.URL+"/logs", testLog, http.StatusCreated)["id"].(string)
+	path := s.logs[first].path
+
+	request(t, "GET", ts.URL+"/logs/"+first+"/counts/sessions", "", http.StatusOK)
+	request(t, "GET", ts.URL+"/logs/"+first, "", http.StatusOK)
+
+	// The log goes once its only run is dropped from the run history
+	for i := 0; i < maxRuns; i++ {
+		request(t, "GET", ts.URL+"/logs/"+second+"/counts/sessions", "", http.StatusOK)
+	}
+	request(t, "GET", ts.URL+"/logs/"+first, "", http.StatusNotFound)
+	request(t, "GET", ts.URL+"/logs/"+second, "", http.StatusOK)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the evicted log file to be deleted, got %v", err)
+	}
+}
+
+func TestServer_WebUI(t *testing.T) {
+	ts := newTestServer(t, 0)
+
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s returned status %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	ts := newTestServer(t, 16)
+
+	tooLarge := request(t, "POST", ts.URL+"/logs", testLog, http.StatusRequestEntityTooLarge)
+	if tooLarge["error"] != "log exceeds the upload limit of 16 bytes" {
+		t.Errorf("Unexpected error: %v", tooLarge)
+	}
+
+	missing := request(t, "GET", ts.URL+"/logs/unknown/funnels/purchase", "", http.StatusNotFound)
+	if missing["error"] != "log 'unknown' not found" {
+		t.Errorf("Unexpected error: %v", missing)
+	}
+
+	id := request(t, "POST", ts.URL+"/logs", `{"event": "x"}`, http.StatusCreated)["id"].(string)
+	unknown := request(t, "GET", ts.URL+"/logs/"+id+"/counts/checkout", "", http.StatusNotFound)
+	if unknown["error"] != "count config 'checkout' not found" {
+		t.Errorf("Unexpected error: %v", unknown)
+	}
+}
+
+func TestNew_NegativeLimits(t *testing.T) {
+	_, err := New(Options{
+		Parser:  &config.ParserConfig{},
+		DataDir: t.TempDir(),
+		MaxJobs: -1,
+	})
+	if err == nil || !strings.Contains(err.Error(), "cannot be negative") {
+		t.Errorf("New() error = %v, want negative limit error", err)
+	}
+}
+
+func TestNew_InvalidCountConfig(t *testing.T) {
+	_, err := New(Options{
+		Parser:  &config.ParserConfig{},
+		Counts:  map[string]CountConfig{"broken": {Patterns: []string{"[invalid"}}},
+		DataDir: t.TempDir(),
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid count config 'broken'") {
+		t.Errorf("New() error = %v, want invalid count config error", err)
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	_, ts := newTestServerWithOptions(t, func(options *Options) {
+		options.KeepLogs = true
+	})
+	id := request(t, "POST", ts.URL+"/logs", testLog, http.StatusCreated)["id"].(string)
+	request(t, "GET", ts.URL+"/logs/"+id+"/funnels/purchase", "", http.StatusOK)
+	request(t, "GET", ts.URL+"/logs/"+id+"/counts/sessions", "", http.StatusOK)
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	for _, expected := range []string{
+		"loglion_entries_parsed 8\n",
+		`loglion_funnel_step_events{funnel="Purchase",step="Purchase"} 1`,
+		`loglion_pattern_matches{pattern="Logins"} 2`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", expected, body)
+		}
+	}
+}
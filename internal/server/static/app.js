@@ -0,0 +1,264 @@
+"use strict";
+
+// Web UI of loglion serve: uploads logs, runs the served configs and renders
+// the results of analysis runs. Paths are relative so the UI also works
+// behind a reverse proxy prefix.
+
+const statusLine = document.getElementById("status");
+
+function setStatus(message, isError) {
+  statusLine.textContent = message;
+  statusLine.className = isError ? "error" : "";
+}
+
+// token is the bearer token sent to the API, asked for when the server
+// requires one and kept for the browser session
+let token = sessionStorage.getItem("loglion-token") || "";
+
+async function authorizedFetch(path, options) {
+  for (;;) {
+    const headers = Object.assign({}, options && options.headers);
+    if (token) {
+      headers.Authorization = "Bearer " + token;
+    }
+    const response = await fetch(path, Object.assign({}, options, { headers }));
+    if (response.status !== 401) {
+      return response;
+    }
+    const entered = window.prompt("This server requires an access token:");
+    if (!entered) {
+      return response;
+    }
+    token = entered;
+    sessionStorage.setItem("loglion-token", token);
+  }
+}
+
+async function api(path, options) {
+  const response = await authorizedFetch(path, options);
+  const body = await response.json();
+  if (!response.ok) {
+    throw new Error(body.error || response.statusText);
+  }
+  return body;
+}
+
+function element(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text !== undefined) {
+    node.textContent = text;
+  }
+  if (className) {
+    node.className = className;
+  }
+  return node;
+}
+
+function link(text, href, download) {
+  const node = element("a", text);
+  node.href = href;
+  if (download) {
+    node.download = download;
+  }
+  return node;
+}
+
+// downloadLink downloads path as filename through the API, so the token is
+// sent along
+function downloadLink(text, path, filename) {
+  const node = link(text, path, filename);
+  node.addEventListener("click", async (event) => {
+    event.preventDefault();
+    try {
+      const response = await authorizedFetch(path);
+      if (!response.ok) {
+        throw new Error((await response.json()).error || response.statusText);
+      }
+      const url = URL.createObjectURL(await response.blob());
+      const anchor = link(text, url, filename);
+      anchor.click();
+      URL.revokeObjectURL(url);
+    } catch (err) {
+      setStatus(err.message, true);
+    }
+  });
+  return node;
+}
+
+function formatPercent(value) {
+  return value.toFixed(1) + "%";
+}
+
+async function loadConfigs() {
+  const configs = await api("configs");
+  const select = document.getElementById("run-config");
+  select.replaceChildren();
+  for (const [kind, names] of [["funnel", configs.funnels], ["count", configs.counts]]) {
+    for (const name of names) {
+      const option = element("option", kind + ": " + name);
+      option.value = kind + "s/" + encodeURIComponent(name);
+      select.append(option);
+    }
+  }
+}
+
+async function loadLogs(selectID) {
+  const { logs } = await api("logs");
+  const select = document.getElementById("run-log");
+  select.replaceChildren();
+  for (const log of logs.slice().reverse()) {
+    const option = element("option", (log.name || log.id) + " (" + log.size + " bytes)");
+    option.value = log.id;
+    select.append(option);
+  }
+  if (selectID) {
+    select.value = selectID;
+  }
+}
+
+async function loadRuns() {
+  const { runs } = await api("runs");
+  const list = document.getElementById("run-list");
+  list.replaceChildren();
+  document.getElementById("no-runs").hidden = runs.length > 0;
+
+  for (const run of runs) {
+    const row = element("tr");
+    row.append(element("td", new Date(run.created).toLocaleString()));
+    row.append(element("td", run.kind + ": " + run.config));
+    row.append(element("td", run.log_name || run.log_id));
+
+    const actions = element("td");
+    if (run.status === "queued" || run.status === "running") {
+      // Runs in progress have no results yet but can be cancelled
+      const cancel = link("Cancel", "#" + run.id);
+      cancel.addEventListener("click", (event) => {
+        event.preventDefault();
+        api("runs/" + run.id, { method: "DELETE" }).then(loadRuns).catch((err) => setStatus(err.message, true));
+      });
+      actions.append(element("span", run.status + " "), cancel);
+      row.append(actions);
+      list.append(row);
+      continue;
+    }
+    const view = link(run.status === "cancelled" ? "View (partial)" : "View", "#" + run.id);
+    view.addEventListener("click", (event) => {
+      event.preventDefault();
+      showRun(run).catch((err) => setStatus(err.message, true));
+    });
+    actions.append(view);
+    actions.append(downloadLink("JSON", "runs/" + run.id, run.config + "-" + run.id + ".json"));
+    actions.append(downloadLink("CSV", "runs/" + run.id + "?format=csv", run.config + "-" + run.id + ".csv"));
+    row.append(actions);
+    list.append(row);
+  }
+}
+
+// barChart renders rows of [label, value, caption] as horizontal bars scaled
+// to the largest value
+function barChart(rows) {
+  const chart = element("div", undefined, "chart");
+  const max = Math.max(1, ...rows.map((row) => row[1]));
+  for (const [label, value, caption] of rows) {
+    const row = element("div", undefined, "bar-row");
+    const name = element("div", label, "bar-label");
+    name.title = label;
+    const track = element("div", undefined, "bar-track");
+    const bar = element("div", undefined, "bar");
+    bar.style.width = (value / max * 100) + "%";
+    track.append(bar);
+    row.append(name, track, element("div", caption, "bar-value"));
+    chart.append(row);
+  }
+  return chart;
+}
+
+function table(header, rows) {
+  const node = element("table");
+  const head = element("tr");
+  header.forEach((title) => head.append(element("th", title)));
+  const thead = element("thead");
+  thead.append(head);
+  node.append(thead);
+  const body = element("tbody");
+  for (const cells of rows) {
+    const row = element("tr");
+    cells.forEach((cell, i) => row.append(element("td", String(cell), i > 0 ? "number" : "")));
+    body.append(row);
+  }
+  node.append(body);
+  return node;
+}
+
+function renderFunnel(result) {
+  const section = element("div");
+  section.append(element("h3", result.funnel_name + (result.partial ? " (partial)" : "")));
+  section.append(element("p", "Entered: " + result.entered_funnel + ", conversions: " + result.conversions_found +
+    ", events analyzed: " + result.total_events_analyzed));
+  section.append(barChart(result.steps.map((step) => [step.name, step.event_count, step.event_count + " (" + formatPercent(step.percentage) + ")"])));
+
+  if (result.drop_offs.length > 0) {
+    section.append(table(["Drop-off", "Lost", "Rate"], result.drop_offs.map((dropOff) =>
+      [dropOff.from + " → " + dropOff.to, dropOff.events_lost, formatPercent(dropOff.drop_off_rate)])));
+  }
+  return section;
+}
+
+function renderCount(result) {
+  const section = element("div");
+  section.append(element("p", "Events analyzed: " + result.total_events_analyzed));
+  section.append(barChart(result.pattern_counts.map((pattern) => [pattern.pattern, pattern.count, String(pattern.count)])));
+  return section;
+}
+
+async function showRun(run) {
+  const result = await api("runs/" + run.id);
+  const body = document.getElementById("report-body");
+  body.replaceChildren();
+  document.getElementById("report-title").textContent = run.kind + ": " + run.config + " on " + (run.log_name || run.log_id);
+
+  if (run.kind === "funnel") {
+    for (const funnel of result.funnels || [result]) {
+      body.append(renderFunnel(funnel));
+    }
+  } else {
+    body.append(renderCount(result));
+  }
+  document.getElementById("report").hidden = false;
+}
+
+document.getElementById("upload-form").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const file = document.getElementById("upload-file").files[0];
+  setStatus("Uploading " + file.name + "...");
+  try {
+    const log = await api("logs?name=" + encodeURIComponent(file.name), { method: "POST", body: file });
+    await loadLogs(log.id);
+    setStatus("Uploaded " + file.name);
+  } catch (err) {
+    setStatus(err.message, true);
+  }
+});
+
+document.getElementById("run-form").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const logID = document.getElementById("run-log").value;
+  const config = document.getElementById("run-config").value;
+  setStatus("Analyzing...");
+  try {
+    await api("logs/" + encodeURIComponent(logID) + "/" + config);
+    // The server deletes analyzed logs unless started with --keep-logs
+    await Promise.all([loadLogs(logID), loadRuns()]);
+    // Show the latest run of this log and config, the one just finished
+    const { runs } = await api("runs");
+    const run = runs.find((candidate) => candidate.log_id === logID && config === candidate.kind + "s/" + encodeURIComponent(candidate.config));
+    if (run) {
+      await showRun(run);
+    }
+    setStatus("");
+  } catch (err) {
+    setStatus(err.message, true);
+  }
+});
+
+Promise.all([loadConfigs(), loadLogs(), loadRuns()]).catch((err) => setStatus(err.message, true));
@@ -0,0 +1,90 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ADBOptions configures how adb logcat is invoked
+type ADBOptions struct {
+	// Binary is the adb executable to run (defaults to "adb" from PATH)
+	Binary string
+	// Serial selects a device when more than one is connected
+	Serial string
+	// Tags limits logcat output to the given tags (all other tags are silenced)
+	Tags []string
+}
+
+// ADBSource streams log lines from a running `adb logcat` process
+type ADBSource struct {
+	options ADBOptions
+	cmd     *exec.Cmd
+}
+
+func NewADBSource(options ADBOptions) *ADBSource {
+	if options.Binary == "" {
+		options.Binary = "adb"
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"binary": options.Binary,
+		"serial": options.Serial,
+		"tags":   options.Tags,
+	}).Debug("Creating new adb source")
+
+	return &ADBSource{options: options}
+}
+
+// Args returns the command line arguments passed to adb
+func (s *ADBSource) Args() []string {
+	var args []string
+	if s.options.Serial != "" {
+		args = append(args, "-s", s.options.Serial)
+	}
+
+	args = append(args, "logcat", "-v", "threadtime")
+
+	if len(s.options.Tags) > 0 {
+		for _, tag := range s.options.Tags {
+			args = append(args, tag+":V")
+		}
+		args = append(args, "*:S")
+	}
+
+	return args
+}
+
+// Start launches adb logcat and returns its standard output. The process is
+// killed when ctx is cancelled; call Wait to release its resources.
+func (s *ADBSource) Start(ctx context.Context) (io.ReadCloser, error) {
+	args := s.Args()
+	logrus.WithFields(logrus.Fields{
+		"binary": s.options.Binary,
+		"args":   args,
+	}).Info("Starting adb logcat")
+
+	s.cmd = exec.CommandContext(ctx, s.options.Binary, args...)
+	stdout, err := s.cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to adb output: %w", err)
+	}
+
+	if err := s.cmd.Start(); err != nil {
+		logrus.WithError(err).Error("Failed to start adb logcat")
+		return nil, fmt.Errorf("failed to start adb: %w", err)
+	}
+
+	return stdout, nil
+}
+
+// Wait waits for the adb process to exit
+func (s *ADBSource) Wait() error {
+	if s.cmd == nil {
+		return nil
+	}
+	return s.cmd.Wait()
+}
@@ -0,0 +1,73 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestADBSource_Args(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ADBOptions
+		want    []string
+	}{
+		{
+			name:    "defaults",
+			options: ADBOptions{},
+			want:    []string{"logcat", "-v", "threadtime"},
+		},
+		{
+			name:    "device_serial",
+			options: ADBOptions{Serial: "emulator-5554"},
+			want:    []string{"-s", "emulator-5554", "logcat", "-v", "threadtime"},
+		},
+		{
+			name:    "tag_filters",
+			options: ADBOptions{Tags: []string{"Analytics", "ActivityManager"}},
+			want:    []string{"logcat", "-v", "threadtime", "Analytics:V", "ActivityManager:V", "*:S"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewADBSource(tt.options).Args()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Args() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestADBSource_Start(t *testing.T) {
+	// echo stands in for adb and prints its arguments as a single line
+	src := NewADBSource(ADBOptions{Binary: "echo", Tags: []string{"Analytics"}})
+
+	stdout, err := src.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if err := src.Wait(); err != nil {
+		t.Errorf("Wait() unexpected error: %v", err)
+	}
+
+	if len(lines) != 1 || lines[0] != "logcat -v threadtime Analytics:V *:S" {
+		t.Errorf("Unexpected output lines: %v", lines)
+	}
+}
+
+func TestADBSource_StartMissingBinary(t *testing.T) {
+	src := NewADBSource(ADBOptions{Binary: "/nonexistent/adb"})
+
+	if _, err := src.Start(context.Background()); err == nil {
+		t.Error("Start() should fail when the adb binary does not exist")
+	}
+}
@@ -0,0 +1,85 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultPollInterval is how often a followed file is checked for new data
+const DefaultPollInterval = 250 * time.Millisecond
+
+// FileSource streams the lines of a log file and the lines appended to it
+// later, like tail -f, e.g. for logcat redirected to a file
+type FileSource struct {
+	path         string
+	pollInterval time.Duration
+}
+
+func NewFileSource(path string, pollInterval time.Duration) *FileSource {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"path":          path,
+		"poll_interval": pollInterval,
+	}).Debug("Creating new file source")
+
+	return &FileSource{path: path, pollInterval: pollInterval}
+}
+
+// Start returns a reader of the file's content followed by everything
+// appended to it until ctx is cancelled, when the reader returns io.EOF. A
+// file truncated while followed is read again from the start.
+func (s *FileSource) Start(ctx context.Context) (io.ReadCloser, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	logrus.WithField("path", s.path).Info("Following log file")
+
+	reader, writer := io.Pipe()
+	go func() {
+		defer file.Close()
+		writer.CloseWithError(s.follow(ctx, file, writer))
+	}()
+	return reader, nil
+}
+
+// follow copies file to w, polling for appended data once the end is reached
+func (s *FileSource) follow(ctx context.Context, file *os.File, w io.Writer) error {
+	var offset int64
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := io.Copy(w, file)
+		offset += n
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat log file: %w", err)
+		}
+		if info.Size() < offset {
+			logrus.WithField("path", s.path).Info("Log file was truncated, reading from the start")
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to rewind log file: %w", err)
+			}
+			offset = 0
+		}
+	}
+}
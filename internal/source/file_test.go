@@ -0,0 +1,78 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSource_Start(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logcat.txt")
+	if err := os.WriteFile(path, []byte("login\n"), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reader, err := NewFileSource(path, 10*time.Millisecond).Start(ctx)
+	if err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+	defer reader.Close()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	expectLine := func(want string) {
+		t.Helper()
+		select {
+		case line := <-lines:
+			if line != want {
+				t.Errorf("Expected line %q, got %q", want, line)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected line %q", want)
+		}
+	}
+
+	expectLine("login")
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	file.WriteString("purchase\n")
+	file.Close()
+	expectLine("purchase")
+
+	// A truncated file is read again from the start
+	if err := os.WriteFile(path, []byte("logout\n"), 0644); err != nil {
+		t.Fatalf("Failed to truncate log file: %v", err)
+	}
+	expectLine("logout")
+
+	cancel()
+	select {
+	case _, ok := <-lines:
+		if ok {
+			t.Error("Expected no more lines after cancelling")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the reader to end when the context is cancelled")
+	}
+}
+
+func TestFileSource_StartMissingFile(t *testing.T) {
+	_, err := NewFileSource(filepath.Join(t.TempDir(), "missing.txt"), 0).Start(context.Background())
+	if err == nil {
+		t.Error("Expected an error for a missing log file")
+	}
+}
@@ -0,0 +1,240 @@
+package suggest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// layout is a well-known log line format a suggestion can be based on. Log
+// line regexes keep the group order of the parser (timestamp, pid, tid,
+// level, tag, message), with empty groups for fields the format lacks.
+type layout struct {
+	name            string
+	timestampFormat string
+	logLineRegex    string
+}
+
+// layouts are tried in order, most specific first
+var layouts = []layout{
+	{
+		name:            "Android logcat (threadtime)",
+		timestampFormat: "01-02 15:04:05.000",
+		logLineRegex:    `^(\d{2}-\d{2} \d{2}:\d{2}:\d{2}\.\d{3})\s+(\d+)\s+(\d+)\s+([VDIWEFS])\s+([^:]+?)\s*:\s*(.*)$`,
+	},
+	{
+		name:            "ISO 8601 timestamp and level",
+		timestampFormat: "2006-01-02T15:04:05",
+		logLineRegex:    `^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?)\S*\s+()()\[?([A-Z]+)\]?\s+()(.*)$`,
+	},
+	{
+		name:            "Time of day and level",
+		timestampFormat: "15:04:05",
+		logLineRegex:    `^(\d{2}:\d{2}:\d{2}(?:\.\d+)?)\s+()()\[?([A-Z]+)\]?\s+()(.*)$`,
+	},
+	{
+		name:         "Whole line",
+		logLineRegex: `^(.*)$`,
+	},
+}
+
+// Suggestion is a candidate parser config for the sample lines
+type Suggestion struct {
+	Layout string
+	Config config.ParserConfig
+	// EventPattern is a step or count pattern matching the event
+	EventPattern string
+	// Matched is the number of samples the config parses with the event found
+	// in the event data or message
+	Matched int
+}
+
+// Result holds the sample lines found for an event and the configs suggested
+// for them, best first
+type Result struct {
+	Event       string
+	Samples     []string
+	Suggestions []Suggestion
+}
+
+// FindSamples returns up to limit lines of the files that contain event,
+// together with the total number of such lines
+func FindSamples(files []string, event string, limit int) ([]string, int, error) {
+	var samples []string
+	total := 0
+
+	for _, file := range files {
+		reader, err := parser.OpenLogFile(file)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to open file: %w", err)
+		}
+
+		// Lines over the size limit cannot be parsed anyway and are skipped
+		lines := parser.NewLineReader(reader, parser.DefaultMaxLineBytes)
+		for {
+			var line string
+			var oversized bool
+			line, oversized, err = lines.ReadLine()
+			if err != nil {
+				break
+			}
+			if oversized || !strings.Contains(line, event) {
+				continue
+			}
+			total++
+			if len(samples) < limit {
+				samples = append(samples, line)
+			}
+		}
+		reader.Close()
+		if err != io.EOF {
+			return nil, 0, fmt.Errorf("error reading file '%s': %w", file, err)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":         event,
+		"total_matches": total,
+		"samples":       len(samples),
+	}).Debug("Collected sample lines")
+
+	return samples, total, nil
+}
+
+// Suggest proposes parser configs for sample lines containing event. Only
+// configs that parse every sample with the event found are suggested.
+func Suggest(event string, samples []string) *Result {
+	result := &Result{Event: event, Samples: samples}
+	if len(samples) == 0 {
+		return result
+	}
+
+	eventRegex, jsonExtraction := suggestEventRegex(event, samples)
+
+	for _, l := range layouts {
+		suggestion := Suggestion{
+			Layout: l.name,
+			Config: config.ParserConfig{
+				TimestampFormat: l.timestampFormat,
+				EventRegex:      eventRegex,
+				JSONExtraction:  jsonExtraction,
+				LogLineRegex:    l.logLineRegex,
+			},
+		}
+		suggestion.Matched, suggestion.EventPattern = verify(suggestion.Config, event, samples)
+
+		logrus.WithFields(logrus.Fields{
+			"layout":  l.name,
+			"matched": suggestion.Matched,
+			"samples": len(samples),
+		}).Debug("Verified suggested parser config")
+
+		if suggestion.Matched == len(samples) {
+			result.Suggestions = append(result.Suggestions, suggestion)
+		}
+	}
+
+	return result
+}
+
+// verify parses the samples with cfg and returns how many of them parsed with
+// the event found, and a pattern matching the event in those entries
+func verify(cfg config.ParserConfig, event string, samples []string) (int, string) {
+	logParser := parser.NewParserWithConfig(cfg.TimestampFormat, cfg.EventRegex, cfg.JSONExtraction, cfg.LogLineRegex)
+
+	matched := 0
+	structured := true
+	for _, sample := range samples {
+		entry, err := logParser.Parse(sample)
+		if err != nil {
+			continue
+		}
+		if name, ok := entry.EventData["event"].(string); ok && name == event {
+			matched++
+			continue
+		}
+		if strings.Contains(entry.Message, event) {
+			matched++
+			structured = false
+		}
+	}
+
+	if structured {
+		// Every entry has the event as its "event" field
+		return matched, "^" + regexp.QuoteMeta(event) + "$"
+	}
+	return matched, regexp.QuoteMeta(event)
+}
+
+// suggestEventRegex returns the event_regex extracting the JSON object of the
+// samples, anchored on the token before it when all samples share one. Samples
+// without JSON get the default event regex and no JSON extraction.
+func suggestEventRegex(event string, samples []string) (string, bool) {
+	anchor := ""
+	eventBeforeJSON := true
+	wholeLine := true
+
+	for i, sample := range samples {
+		start := jsonStart(sample)
+		if start < 0 {
+			return "^(.*)$", false
+		}
+		if start > 0 {
+			wholeLine = false
+		}
+
+		tokens := strings.Fields(sample[:start])
+		if len(tokens) > 0 && tokens[len(tokens)-1] == event {
+			tokens = tokens[:len(tokens)-1]
+		} else {
+			eventBeforeJSON = false
+		}
+
+		token := ""
+		if len(tokens) > 0 {
+			token = tokens[len(tokens)-1]
+		}
+		if i == 0 {
+			anchor = token
+		} else if token != anchor {
+			anchor = ""
+		}
+	}
+
+	if wholeLine {
+		return "^(.*)$", true
+	}
+
+	var pattern strings.Builder
+	if anchor != "" {
+		pattern.WriteString(regexp.QuoteMeta(anchor))
+		pattern.WriteString(`\s+`)
+	}
+	if eventBeforeJSON {
+		pattern.WriteString(`\S+\s+`)
+	}
+	pattern.WriteString(`(\{.*\})`)
+	return pattern.String(), true
+}
+
+// jsonStart returns the index of the JSON object ending the line, or -1
+func jsonStart(line string) int {
+	trimmed := strings.TrimRight(line, " \t")
+	for i := strings.IndexByte(trimmed, '{'); i >= 0; {
+		if json.Valid([]byte(trimmed[i:])) {
+			return i
+		}
+		next := strings.IndexByte(trimmed[i+1:], '{')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return -1
+}
@@ -0,0 +1,145 @@
+package suggest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+func TestSuggestEventRegex(t *testing.T) {
+	tests := []struct {
+		name          string
+		event         string
+		samples       []string
+		expectedRegex string
+		expectedJSON  bool
+	}{
+		{
+			name:          "no JSON",
+			event:         "login",
+			samples:       []string{"01-15 10:30:15.100  1234  1250 I Analytics: login"},
+			expectedRegex: "^(.*)$",
+		},
+		{
+			name:          "whole line JSON",
+			event:         "add_cart",
+			samples:       []string{`{"event": "add_cart"}`, `{"event": "add_cart", "id": 2}`},
+			expectedRegex: "^(.*)$",
+			expectedJSON:  true,
+		},
+		{
+			name:  "event before JSON",
+			event: "purchase_completed",
+			samples: []string{
+				`01-15 10:30:15.100  1234  1250 I Analytics: purchase_completed {"amount": 5}`,
+				`01-15 10:31:15.100  1234  1251 I Analytics: purchase_completed {"amount": 7}`,
+			},
+			expectedRegex: `Analytics:\s+\S+\s+(\{.*\})`,
+			expectedJSON:  true,
+		},
+		{
+			name:          "event inside JSON with escaped anchor",
+			event:         "checkout",
+			samples:       []string{`12:00:01 INFO [Tracker] {"event": "checkout"}`},
+			expectedRegex: `\[Tracker\]\s+(\{.*\})`,
+			expectedJSON:  true,
+		},
+		{
+			name:  "samples without a common anchor",
+			event: "checkout",
+			samples: []string{
+				`12:00:01 INFO web {"event": "checkout"}`,
+				`12:00:02 INFO mobile {"event": "checkout"}`,
+			},
+			expectedRegex: `(\{.*\})`,
+			expectedJSON:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regex, jsonExtraction := suggestEventRegex(tt.event, tt.samples)
+			if regex != tt.expectedRegex {
+				t.Errorf("Expected event regex %q, got %q", tt.expectedRegex, regex)
+			}
+			if jsonExtraction != tt.expectedJSON {
+				t.Errorf("Expected json extraction %v, got %v", tt.expectedJSON, jsonExtraction)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	samples := []string{
+		`01-15 10:30:15.100  1234  1250 I Analytics: {"event": "purchase_completed", "amount": 5}`,
+		`01-15 10:31:15.100  1234  1251 I Analytics: {"event": "purchase_completed", "amount": 7}`,
+	}
+
+	result := Suggest("purchase_completed", samples)
+	if len(result.Suggestions) != 2 {
+		t.Fatalf("Expected 2 suggestions, got %d: %+v", len(result.Suggestions), result.Suggestions)
+	}
+
+	best := result.Suggestions[0]
+	if best.Layout != "Android logcat (threadtime)" {
+		t.Errorf("Expected logcat layout first, got %q", best.Layout)
+	}
+	if best.Config.TimestampFormat != "01-02 15:04:05.000" {
+		t.Errorf("Expected logcat timestamp format, got %q", best.Config.TimestampFormat)
+	}
+	if !best.Config.JSONExtraction || best.Config.EventRegex != `Analytics:\s+(\{.*\})` {
+		t.Errorf("Expected JSON extraction with anchored event regex, got %+v", best.Config)
+	}
+	if best.EventPattern != "^purchase_completed$" {
+		t.Errorf("Expected anchored event pattern, got %q", best.EventPattern)
+	}
+	if best.Matched != 2 {
+		t.Errorf("Expected 2 matched samples, got %d", best.Matched)
+	}
+
+	if result.Suggestions[1].Layout != "Whole line" {
+		t.Errorf("Expected whole line layout last, got %q", result.Suggestions[1].Layout)
+	}
+}
+
+func TestSuggestEscapesEventPattern(t *testing.T) {
+	result := Suggest("cart.add(1)", []string{"cart.add(1) done"})
+	if len(result.Suggestions) != 1 {
+		t.Fatalf("Expected 1 suggestion, got %d", len(result.Suggestions))
+	}
+	if pattern := result.Suggestions[0].EventPattern; pattern != `cart\.add\(1\)` {
+		t.Errorf("Expected escaped event pattern, got %q", pattern)
+	}
+}
+
+func TestFindSamples(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	// Lines over the size limit are skipped instead of ending the search
+	huge := "login " + strings.Repeat("x", parser.DefaultMaxLineBytes) + "\n"
+	if err := os.WriteFile(first, []byte("login a\r\n"+huge+"logout\nlogin b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("login c\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	samples, total, err := FindSamples([]string{first, second}, "login", 2)
+	if err != nil {
+		t.Fatalf("FindSamples() unexpected error: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("Expected 3 matching lines, got %d", total)
+	}
+	if len(samples) != 2 || samples[0] != "login a" || samples[1] != "login b" {
+		t.Errorf("Expected the first 2 matching lines without line endings, got %q", samples)
+	}
+
+	if _, _, err := FindSamples([]string{filepath.Join(dir, "missing.log")}, "login", 2); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
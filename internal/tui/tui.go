@@ -0,0 +1,279 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/parser"
+	"github.com/sirupsen/logrus"
+)
+
+// refreshInterval is how often new entries are analyzed and the dashboard redrawn
+const refreshInterval = 500 * time.Millisecond
+
+// maxRecentEvents is the number of recent matching events shown
+const maxRecentEvents = 10
+
+// progressWidth is the width of the bar of a step all attempts reached
+const progressWidth = 30
+
+// EntriesMsg delivers newly parsed log entries to the dashboard
+type EntriesMsg []*parser.LogEntry
+
+// SourceEndedMsg tells the dashboard that the log source ended, with the
+// error that ended it, if any. The dashboard keeps showing the final results.
+type SourceEndedMsg struct {
+	Err error
+}
+
+type tickMsg time.Time
+
+// Model is the dashboard of the live funnel analysis: the progress of each
+// step, the recent entries matching a step and the drop-offs between steps.
+// One funnel is shown at a time; the recent events can be filtered by step.
+type Model struct {
+	source  string
+	funnels []*config.FunnelConfig
+	entries []*parser.LogEntry
+	results []*analyzer.FunnelResult
+	// recent holds the latest matches of the selected funnel, oldest first,
+	// of every step (index 0) and of each step (index step+1)
+	recent [][]analyzer.StepMatch
+
+	// funnel is the index of the selected funnel
+	funnel int
+	// step is the index of the step the recent events are filtered by, -1 for all steps
+	step int
+
+	dirty bool
+	ended bool
+	err   error
+	width int
+}
+
+// New creates the dashboard of funnels analyzing entries from source, a
+// description of where the entries come from such as the followed file
+func New(source string, funnels []*config.FunnelConfig) *Model {
+	logrus.WithFields(logrus.Fields{
+		"source":       source,
+		"funnel_count": len(funnels),
+	}).Debug("Creating new dashboard")
+
+	m := &Model{source: source, funnels: funnels, step: -1}
+	m.analyze()
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tick()
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m, m.handleKey(msg.String())
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case EntriesMsg:
+		m.entries = append(m.entries, msg...)
+		m.dirty = true
+	case SourceEndedMsg:
+		m.ended = true
+		m.err = msg.Err
+		m.analyze()
+	case tickMsg:
+		if m.dirty {
+			m.analyze()
+		}
+		return m, tick()
+	}
+	return m, nil
+}
+
+// handleKey selects funnels and step filters, or quits
+func (m *Model) handleKey(key string) tea.Cmd {
+	steps := len(m.funnels[m.funnel].Steps)
+	switch key {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "tab", "shift+tab":
+		if key == "tab" {
+			m.funnel = (m.funnel + 1) % len(m.funnels)
+		} else {
+			m.funnel = (m.funnel + len(m.funnels) - 1) % len(m.funnels)
+		}
+		m.step = -1
+		m.analyze()
+	case "right", "l":
+		// Cycle through all steps (-1) and each step
+		m.step = (m.step+2)%(steps+1) - 1
+	case "left", "h":
+		m.step = (m.step+steps+1)%(steps+1) - 1
+	case "0", "esc":
+		m.step = -1
+	default:
+		if len(key) == 1 && key[0] >= '1' && key[0] <= '9' && int(key[0]-'0') <= steps {
+			m.step = int(key[0] - '1')
+		}
+	}
+	return nil
+}
+
+// analyze runs every funnel over the entries received so far and collects
+// the recent matches of the selected funnel
+func (m *Model) analyze() {
+	selected := m.funnels[m.funnel]
+	m.recent = make([][]analyzer.StepMatch, len(selected.Steps)+1)
+	m.results = make([]*analyzer.FunnelResult, len(m.funnels))
+
+	for i, funnelCfg := range m.funnels {
+		var options analyzer.FunnelOptions
+		if i == m.funnel {
+			options.Hooks.OnStepMatched = func(match analyzer.StepMatch) {
+				m.recent[0] = appendRecent(m.recent[0], match)
+				m.recent[match.StepIndex+1] = appendRecent(m.recent[match.StepIndex+1], match)
+			}
+		}
+		m.results[i] = analyzer.NewFunnelAnalyzerWithOptions(funnelCfg, options).AnalyzeFunnel(m.entries, 0)
+	}
+	m.dirty = false
+
+	logrus.WithField("entry_count", len(m.entries)).Debug("Dashboard refreshed")
+}
+
+// appendRecent appends match, keeping the latest maxRecentEvents matches
+func appendRecent(matches []analyzer.StepMatch, match analyzer.StepMatch) []analyzer.StepMatch {
+	matches = append(matches, match)
+	if len(matches) > maxRecentEvents {
+		matches = matches[len(matches)-maxRecentEvents:]
+	}
+	return matches
+}
+
+func (m *Model) View() string {
+	funnelCfg := m.funnels[m.funnel]
+	result := m.results[m.funnel]
+
+	var out strings.Builder
+	status := "live"
+	if m.ended {
+		status = "ended"
+	}
+	if m.err != nil {
+		status = fmt.Sprintf("ended: %v", m.err)
+	}
+	out.WriteString(fmt.Sprintf("🦁 LogLion — %s — %d entries (%s)\n", m.source, len(m.entries), status))
+	out.WriteString(fmt.Sprintf("Funnel %d/%d: %s\n\n", m.funnel+1, len(m.funnels), funnelCfg.Name))
+
+	out.WriteString("Steps:\n")
+	m.renderSteps(&out, funnelCfg, result)
+	completed := "not completed"
+	if result.FunnelCompleted {
+		completed = "completed"
+	}
+	out.WriteString(fmt.Sprintf("Conversion Rate: %.1f%% (%s)\n\n", result.ConversionRate(), completed))
+
+	filter := "all steps"
+	if m.step >= 0 {
+		filter = funnelCfg.Steps[m.step].Name
+	}
+	out.WriteString(fmt.Sprintf("Recent events (%s):\n", filter))
+	recent := m.recent[m.step+1]
+	if len(recent) == 0 {
+		out.WriteString("  (none yet)\n")
+	}
+	nameWidth := 0
+	for _, match := range recent {
+		nameWidth = max(nameWidth, len([]rune(match.Step)))
+	}
+	for i := len(recent) - 1; i >= 0; i-- {
+		match := recent[i]
+		out.WriteString(fmt.Sprintf("  %s  %s%s  %s\n",
+			formatTimestamp(match.Entry.Timestamp),
+			match.Step,
+			strings.Repeat(" ", nameWidth-len([]rune(match.Step))),
+			match.Entry.Message))
+	}
+
+	out.WriteString("\nDrop-offs:\n")
+	if len(result.DropOffs) == 0 {
+		out.WriteString("  (none)\n")
+	}
+	for _, dropOff := range result.DropOffs {
+		out.WriteString(fmt.Sprintf("  %s → %s: %d lost (%.1f%%)\n", dropOff.From, dropOff.To, dropOff.EventsLost, dropOff.DropOffRate))
+	}
+
+	out.WriteString("\n←/→ filter step · 1-9 select step · 0 all steps")
+	if len(m.funnels) > 1 {
+		out.WriteString(" · tab next funnel")
+	}
+	out.WriteString(" · q quit\n")
+
+	return m.fitWidth(out.String())
+}
+
+// renderSteps draws one progress bar per step, marking the filtered step.
+// Results without events have no steps, so counts are looked up by index.
+func (m *Model) renderSteps(out *strings.Builder, funnelCfg *config.FunnelConfig, result *analyzer.FunnelResult) {
+	nameWidth := 0
+	for _, step := range funnelCfg.Steps {
+		nameWidth = max(nameWidth, len([]rune(step.Name)))
+	}
+
+	for i, step := range funnelCfg.Steps {
+		var stepResult analyzer.StepResult
+		if i < len(result.Steps) {
+			stepResult = result.Steps[i]
+		}
+		marker := " "
+		if i == m.step {
+			marker = "▶"
+		}
+
+		percentage := min(stepResult.Percentage, 100.0)
+		barWidth := int(percentage/100.0*progressWidth + 0.5)
+		if barWidth == 0 && stepResult.EventCount > 0 {
+			barWidth = 1
+		}
+		out.WriteString(fmt.Sprintf("%s %d. %s%s │%s%s│ %d (%.1f%%)\n",
+			marker,
+			i+1,
+			step.Name,
+			strings.Repeat(" ", nameWidth-len([]rune(step.Name))),
+			strings.Repeat("█", barWidth),
+			strings.Repeat(" ", progressWidth-barWidth),
+			stepResult.EventCount,
+			stepResult.Percentage))
+	}
+}
+
+// fitWidth cuts lines longer than the terminal, which would otherwise wrap
+// and scroll the dashboard
+func (m *Model) fitWidth(view string) string {
+	if m.width <= 0 {
+		return view
+	}
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		if runes := []rune(line); len(runes) > m.width {
+			lines[i] = string(runes[:m.width-1]) + "…"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatTimestamp prints the time of day of a log entry with milliseconds
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return strings.Repeat(" ", len("15:04:05.000"))
+	}
+	return t.Format("15:04:05.000")
+}
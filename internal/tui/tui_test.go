@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/parfenovvs/loglion/internal/config"
+)
+
+func testFunnels() []*config.FunnelConfig {
+	return []*config.FunnelConfig{
+		{
+			Name: "Checkout",
+			Steps: []config.Step{
+				{Name: "View", EventPattern: "^view"},
+				{Name: "Buy", EventPattern: "^buy"},
+			},
+		},
+		{
+			Name:  "Login",
+			Steps: []config.Step{{Name: "Login", EventPattern: "^login"}},
+		},
+	}
+}
+
+func testEntries() EntriesMsg {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return EntriesMsg{
+		{Timestamp: base, Message: "view item=1"},
+		{Timestamp: base.Add(time.Second), Message: "buy item=1"},
+		{Timestamp: base.Add(2 * time.Second), Message: "view item=2"},
+		{Timestamp: base.Add(3 * time.Second), Message: "login"},
+	}
+}
+
+func update(t *testing.T, m *Model, msg tea.Msg) {
+	t.Helper()
+	if _, isKey := msg.(tea.KeyMsg); !isKey {
+		m.Update(msg)
+		return
+	}
+	if _, cmd := m.Update(msg); cmd != nil {
+		t.Fatalf("Unexpected command after key %v", msg)
+	}
+}
+
+func TestModelRefresh(t *testing.T) {
+	m := New("logcat.txt", testFunnels())
+	if view := m.View(); !strings.Contains(view, "0 entries (live)") || !strings.Contains(view, "(none yet)") {
+		t.Errorf("Expected an empty dashboard, got:\n%s", view)
+	}
+
+	// Entries are analyzed on the next refresh
+	update(t, m, testEntries())
+	if view := m.View(); !strings.Contains(view, "4 entries (live)") || !strings.Contains(view, "│ 0 (0.0%)") {
+		t.Errorf("Expected entries to be analyzed on refresh only, got:\n%s", view)
+	}
+	update(t, m, tickMsg(time.Now()))
+
+	view := m.View()
+	expected := []string{
+		"🦁 LogLion — logcat.txt — 4 entries (live)",
+		"Funnel 1/2: Checkout",
+		"1. View │",
+		"│ 2 (100.0%)",
+		"│ 1 (50.0%)",
+		"Recent events (all steps):",
+		"10:00:02.000  View  view item=2",
+		"View → Buy: 1 lost (50.0%)",
+		"tab next funnel",
+	}
+	for _, line := range expected {
+		if !strings.Contains(view, line) {
+			t.Errorf("View() should contain %q, got:\n%s", line, view)
+		}
+	}
+	// The newest event comes first
+	if strings.Index(view, "view item=2") > strings.Index(view, "buy item=1") {
+		t.Errorf("Expected recent events newest first, got:\n%s", view)
+	}
+
+	update(t, m, SourceEndedMsg{})
+	if !strings.Contains(m.View(), "4 entries (ended)") {
+		t.Errorf("Expected the source to be shown as ended, got:\n%s", m.View())
+	}
+}
+
+func TestModelKeys(t *testing.T) {
+	m := New("adb logcat", testFunnels())
+	update(t, m, testEntries())
+	update(t, m, tickMsg(time.Now()))
+
+	update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	view := m.View()
+	if !strings.Contains(view, "Recent events (Buy):") || !strings.Contains(view, "▶ 2. Buy") {
+		t.Errorf("Expected recent events of Buy, got:\n%s", view)
+	}
+	if strings.Contains(view, "view item=") {
+		t.Errorf("Expected no View events when filtering by Buy, got:\n%s", view)
+	}
+
+	update(t, m, tea.KeyMsg{Type: tea.KeyRight})
+	if !strings.Contains(m.View(), "Recent events (all steps):") {
+		t.Errorf("Expected right after the last step to show all steps, got:\n%s", m.View())
+	}
+	update(t, m, tea.KeyMsg{Type: tea.KeyLeft})
+	if !strings.Contains(m.View(), "Recent events (Buy):") {
+		t.Errorf("Expected left from all steps to select the last step, got:\n%s", m.View())
+	}
+	update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if !strings.Contains(m.View(), "Recent events (all steps):") {
+		t.Errorf("Expected esc to clear the step filter, got:\n%s", m.View())
+	}
+
+	update(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	view = m.View()
+	if !strings.Contains(view, "Funnel 2/2: Login") || !strings.Contains(view, "10:00:03.000  Login  login") {
+		t.Errorf("Expected tab to select the Login funnel, got:\n%s", view)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if cmd == nil {
+		t.Fatal("Expected q to quit")
+	}
+	if _, isQuit := cmd().(tea.QuitMsg); !isQuit {
+		t.Error("Expected q to quit")
+	}
+}
+
+func TestModelFitWidth(t *testing.T) {
+	m := New("logcat.txt", testFunnels())
+	update(t, m, tea.WindowSizeMsg{Width: 20, Height: 10})
+
+	for _, line := range strings.Split(m.View(), "\n") {
+		if len([]rune(line)) > 20 {
+			t.Errorf("Expected lines of at most 20 characters, got %q", line)
+		}
+	}
+}
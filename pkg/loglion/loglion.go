@@ -0,0 +1,239 @@
+// Package loglion is the public Go API of LogLion. It lets other tools parse
+// logs and run funnel and count analyses in-process instead of shelling out
+// to the CLI, using the same configs, results and output formats.
+//
+// The types are aliases of the CLI's implementation, so results can be passed
+// to the formatters of this package unchanged. The exported names of this
+// package are kept compatible across minor releases.
+//
+//	funnelCfg, err := loglion.LoadFunnelConfig("funnel.yaml")
+//	...
+//	result, err := loglion.AnalyzeFunnel(funnelCfg, file, loglion.Options{})
+//	...
+//	text, err := loglion.NewFormatter(loglion.TextFormat, loglion.OutputOptions{}).FormatFunnel(result)
+package loglion
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/parfenovvs/loglion/internal/analyzer"
+	"github.com/parfenovvs/loglion/internal/config"
+	"github.com/parfenovvs/loglion/internal/output"
+	"github.com/parfenovvs/loglion/internal/parser"
+)
+
+// Parsing
+type (
+	LogEntry      = parser.LogEntry
+	Parser        = parser.Parser
+	ParseStats    = parser.ParseStats
+	EntryFilter   = parser.EntryFilter
+	SkippedLine   = parser.SkippedLine
+	FormatOptions = parser.FormatOptions
+	FormatFactory = parser.FormatFactory
+	JSONLFields   = parser.JSONLFields
+	DedupeOptions = parser.DedupeOptions
+)
+
+// Configuration
+type (
+	ParserConfig = config.ParserConfig
+	FieldsConfig = config.FieldsConfig
+	FilterConfig = config.FilterConfig
+	FunnelConfig = config.FunnelConfig
+	Step         = config.Step
+	StepBranch   = config.StepBranch
+)
+
+// Analysis
+type (
+	FunnelResult     = analyzer.FunnelResult
+	StepResult       = analyzer.StepResult
+	StepSample       = analyzer.StepSample
+	NearMiss         = analyzer.NearMiss
+	Assertion        = analyzer.Assertion
+	DropOff          = analyzer.DropOff
+	FunnelConversion = analyzer.FunnelConversion
+	ConversionStep   = analyzer.ConversionStep
+	CountResult      = analyzer.CountResult
+	CountOptions     = analyzer.CountOptions
+	Hooks            = analyzer.Hooks
+	StepMatch        = analyzer.StepMatch
+	Conversion       = analyzer.Conversion
+)
+
+// Output
+type (
+	Formatter     = output.Formatter
+	OutputFormat  = output.OutputFormat
+	OutputOptions = output.Options
+)
+
+// Output formats
+const (
+	TextFormat = output.TextFormat
+	JSONFormat = output.JSONFormat
+	HTMLFormat = output.HTMLFormat
+)
+
+// Options control an analysis of the façade functions
+type Options struct {
+	// Context stops the analysis early, which then returns the partial
+	// result; context.Background() if nil
+	Context context.Context
+	// Parser configures how log lines are parsed. If nil, every line is read
+	// as a JSON event, or as a plain message if it is not JSON.
+	Parser *ParserConfig
+	// Filter selects the entries to analyze, in addition to the filter of
+	// the parser config
+	Filter EntryFilter
+	// Monotonicize clamps timestamps that go backwards to the latest earlier
+	// timestamp before analysis
+	Monotonicize bool
+	// SortByTimestamp orders entries by timestamp before analysis, for logs
+	// merged from several buffers
+	SortByTimestamp bool
+	// Dedupe drops events repeating an earlier event within a window, after
+	// filtering; nil keeps all events
+	Dedupe *DedupeOptions
+	// Limit is the maximum number of successful funnels to analyze, 0 for
+	// all. Only used by AnalyzeFunnel.
+	Limit int
+	// Mode overrides the mode of the funnel config, strict, loose or
+	// unordered, when set. Only used by AnalyzeFunnel.
+	Mode string
+	// Hooks are called while a funnel is analyzed. Only used by AnalyzeFunnel.
+	Hooks Hooks
+	// Samples keeps the first and last Samples entries that matched each
+	// funnel step in the step results. Only used by AnalyzeFunnel.
+	Samples int
+	// ConversionSteps lists the entry that matched each step in every
+	// conversion of the result. Only used by AnalyzeFunnel.
+	ConversionSteps bool
+	// Count configures count analyses. Only used by Count.
+	Count CountOptions
+}
+
+// LoadParserConfig loads and validates a parser config file
+func LoadParserConfig(path string) (*ParserConfig, error) {
+	return config.LoadParserConfig(path)
+}
+
+// LoadFunnelConfig loads and validates a funnel config file defining one funnel
+func LoadFunnelConfig(path string) (*FunnelConfig, error) {
+	return config.LoadFunnelConfig(path)
+}
+
+// LoadFunnelConfigs loads and validates all funnels of a funnel config file
+func LoadFunnelConfigs(path string) ([]*FunnelConfig, error) {
+	return config.LoadFunnelConfigs(path)
+}
+
+// RegisterFormat makes a custom log format available to parser configs under
+// name, so they can select it with "format: name". It panics if the name is
+// empty or already registered.
+func RegisterFormat(name string, factory FormatFactory) {
+	parser.RegisterFormat(name, factory)
+}
+
+// Formats returns the names of the registered log formats, sorted
+func Formats() []string {
+	return parser.Formats()
+}
+
+// NewParser validates cfg and creates the parser it describes
+func NewParser(cfg *ParserConfig) (Parser, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid parser config: %w", err)
+	}
+	return cfg.NewParser()
+}
+
+// ParseEntries parses the log read from r into entries, applying the parser
+// config filter and the filter, sorting and de-duplication of options
+func ParseEntries(r io.Reader, options Options) ([]*LogEntry, error) {
+	parserCfg := &ParserConfig{JSONExtraction: true}
+	if options.Parser != nil {
+		// Validation fills in defaults, which must not change the caller's config
+		copied := *options.Parser
+		parserCfg = &copied
+	}
+
+	logParser, err := NewParser(parserCfg)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := logParser.ParseReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse log: %w", err)
+	}
+
+	if options.Monotonicize {
+		parser.Monotonicize(entries)
+	}
+	if options.SortByTimestamp {
+		parser.SortByTimestamp(entries)
+	}
+	entries = parser.FilterEntries(entries, parserCfg.EntryFilter())
+	entries = parser.FilterEntries(entries, options.Filter)
+	if options.Dedupe != nil {
+		entries, _ = parser.DedupeEntries(entries, *options.Dedupe)
+	}
+	return entries, nil
+}
+
+// AnalyzeFunnel runs the funnel of cfg over the log read from r
+func AnalyzeFunnel(cfg *FunnelConfig, r io.Reader, options Options) (*FunnelResult, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid funnel config: %w", err)
+	}
+	switch options.Mode {
+	case "", config.ModeStrict, config.ModeLoose, config.ModeUnordered:
+	default:
+		return nil, fmt.Errorf("invalid mode '%s' (valid: %s, %s, %s)", options.Mode, config.ModeStrict, config.ModeLoose, config.ModeUnordered)
+	}
+
+	entries, err := ParseEntries(r, options)
+	if err != nil {
+		return nil, err
+	}
+
+	funnelAnalyzer := analyzer.NewFunnelAnalyzerWithOptions(cfg, analyzer.FunnelOptions{
+		Hooks:           options.Hooks,
+		Samples:         options.Samples,
+		ConversionSteps: options.ConversionSteps,
+		Limit:           options.Limit,
+		Mode:            options.Mode,
+	})
+	return funnelAnalyzer.Analyze(options.context(), entries), nil
+}
+
+// Count counts the entries of the log read from r that match each of the
+// event patterns
+func Count(patterns []string, r io.Reader, options Options) (*CountResult, error) {
+	countAnalyzer, err := analyzer.NewCountAnalyzerWithOptions(patterns, options.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ParseEntries(r, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return countAnalyzer.AnalyzeCountContext(options.context(), entries), nil
+}
+
+// NewFormatter creates a formatter rendering results like the CLI does
+func NewFormatter(format OutputFormat, options OutputOptions) Formatter {
+	return output.NewFormatterWithOptions(format, options)
+}
+
+func (o Options) context() context.Context {
+	if o.Context == nil {
+		return context.Background()
+	}
+	return o.Context
+}
@@ -0,0 +1,146 @@
+package loglion
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const testLog = `{"event":"login","user_id":"alice"}
+{"event":"view","user_id":"alice"}
+{"event":"purchase","user_id":"alice"}
+{"event":"login","user_id":"bob"}
+{"event":"view","user_id":"bob"}
+not json at all
+`
+
+func testFunnel() *FunnelConfig {
+	return &FunnelConfig{
+		Name: "Checkout",
+		Steps: []Step{
+			{Name: "Login", EventPattern: "login"},
+			{Name: "View", EventPattern: "view"},
+			{Name: "Purchase", EventPattern: "purchase"},
+		},
+	}
+}
+
+func TestAnalyzeFunnel(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name        string
+		cfg         *FunnelConfig
+		options     Options
+		wantCounts  []int
+		wantPartial bool
+		expectError bool
+	}{
+		{
+			name:       "default parser",
+			cfg:        testFunnel(),
+			wantCounts: []int{2, 2, 1},
+		},
+		{
+			name:       "plain parser with limit",
+			cfg:        testFunnel(),
+			options:    Options{Parser: &ParserConfig{Format: "plain", JSONExtraction: true}, Limit: 1},
+			wantCounts: []int{1, 1, 1},
+		},
+		{
+			name:        "invalid mode",
+			cfg:         testFunnel(),
+			options:     Options{Mode: "random"},
+			expectError: true,
+		},
+		{
+			name:        "cancelled context",
+			cfg:         testFunnel(),
+			options:     Options{Context: cancelled},
+			wantCounts:  []int{0, 0, 0},
+			wantPartial: true,
+		},
+		{
+			name:        "invalid funnel config",
+			cfg:         &FunnelConfig{Name: "Empty"},
+			expectError: true,
+		},
+		{
+			name:        "invalid parser config",
+			cfg:         testFunnel(),
+			options:     Options{Parser: &ParserConfig{Format: "nope"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := AnalyzeFunnel(tt.cfg, strings.NewReader(testLog), tt.options)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("AnalyzeFunnel() expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AnalyzeFunnel() unexpected error: %v", err)
+			}
+
+			if result.Partial != tt.wantPartial {
+				t.Errorf("Partial = %v, want %v", result.Partial, tt.wantPartial)
+			}
+			if len(result.Steps) != len(tt.wantCounts) {
+				t.Fatalf("got %d steps, want %d", len(result.Steps), len(tt.wantCounts))
+			}
+			for i, want := range tt.wantCounts {
+				if result.Steps[i].EventCount != want {
+					t.Errorf("Steps[%d].EventCount = %d, want %d", i, result.Steps[i].EventCount, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeFunnel_KeepsParserConfig(t *testing.T) {
+	parserCfg := &ParserConfig{JSONExtraction: true}
+	if _, err := AnalyzeFunnel(testFunnel(), strings.NewReader(testLog), Options{Parser: parserCfg}); err != nil {
+		t.Fatalf("AnalyzeFunnel() unexpected error: %v", err)
+	}
+	if parserCfg.Format != "" {
+		t.Errorf("Format = %q, want the caller's config unchanged", parserCfg.Format)
+	}
+}
+
+func TestCount(t *testing.T) {
+	result, err := Count([]string{"login", "purchase"}, strings.NewReader(testLog), Options{})
+	if err != nil {
+		t.Fatalf("Count() unexpected error: %v", err)
+	}
+
+	want := map[string]int{"login": 2, "purchase": 1}
+	for _, count := range result.PatternCounts {
+		if count.Count != want[count.Pattern] {
+			t.Errorf("count of %q = %d, want %d", count.Pattern, count.Count, want[count.Pattern])
+		}
+	}
+
+	if _, err := Count([]string{"("}, strings.NewReader(testLog), Options{}); err == nil {
+		t.Error("Count() expected error for an invalid pattern")
+	}
+}
+
+func TestNewFormatter(t *testing.T) {
+	result, err := AnalyzeFunnel(testFunnel(), strings.NewReader(testLog), Options{})
+	if err != nil {
+		t.Fatalf("AnalyzeFunnel() unexpected error: %v", err)
+	}
+
+	output, err := NewFormatter(JSONFormat, OutputOptions{}).FormatFunnel(result)
+	if err != nil {
+		t.Fatalf("FormatFunnel() unexpected error: %v", err)
+	}
+	if !strings.Contains(output, `"funnel_name": "Checkout"`) {
+		t.Errorf("FormatFunnel() = %s, want the funnel name", output)
+	}
+}
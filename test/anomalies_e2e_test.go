@@ -0,0 +1,96 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestAnomaliesCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name           string
+		args           []string
+		expected       []string
+		shouldFail     bool
+		expectedErrMsg []string
+	}{
+		{
+			name: "anomalies over rolling baseline",
+			args: []string{"anomalies", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--window", "1s", "--baseline", "2", "--factor", "2", "--min-count", "1", "login", "request"},
+			expected: []string{
+				"Anomaly Detection Complete",
+				"Windows of 1s: 8",
+				"Baseline: average of the previous 2 windows",
+				"1. 01-15 10:30:18.000 -> 01-15 10:30:19.000 login: 1 events, none before (spike)",
+				"2. 01-15 10:30:19.000 -> 01-15 10:30:20.000 request: 1 events, none before (spike)",
+			},
+		},
+		{
+			name: "no anomalies as JSON",
+			args: []string{"anomalies", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-o", "json", "error"},
+			expected: []string{
+				`"windows": 1`,
+				`"anomalies": []`,
+			},
+		},
+		{
+			name:       "anomalies with invalid factor",
+			args:       []string{"anomalies", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--factor", "1", "error"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error: factor must be greater than 1, got 1",
+			},
+		},
+		{
+			name:       "anomalies without patterns",
+			args:       []string{"anomalies", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error: at least 1 event pattern is required",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.CombinedOutput()
+			actual := string(output)
+
+			if tt.shouldFail {
+				if err == nil {
+					t.Fatalf("Expected command to fail, but it succeeded. Output:\n%s", actual)
+				}
+				for _, expectedErr := range tt.expectedErrMsg {
+					if !strings.Contains(actual, expectedErr) {
+						t.Errorf("Expected error output to contain %q, but it didn't. Output:\n%s", expectedErr, actual)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput:\n%s", err, actual)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
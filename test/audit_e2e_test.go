@@ -0,0 +1,98 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestAuditCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name           string
+		args           []string
+		expected       []string
+		shouldFail     bool
+		expectedErrMsg []string
+	}{
+		{
+			name: "audit events against a schema",
+			args: []string{"audit", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--schema", "sample/schemas/purchase.yaml"},
+			expected: []string{
+				"Event Audit Complete",
+				"Audited Events: 7",
+				"Invalid Events: 2",
+				"^purchase$: 2 occurrences, 2 invalid",
+				"1. sample/logs/users.txt:6 ^purchase$: amount: missing required property",
+				"3. sample/logs/users.txt:7 ^purchase$: user_id: missing required property",
+			},
+		},
+		{
+			name: "audit as JSON",
+			args: []string{"audit", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--schema", "sample/schemas/purchase.yaml", "-o", "json"},
+			expected: []string{
+				`"kind": "missing"`,
+				`"source": "sample/logs/users.txt"`,
+				`"line": 7`,
+			},
+		},
+		{
+			name:       "audit with fail-on-violation",
+			args:       []string{"audit", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--schema", "sample/schemas/purchase.yaml", "--fail-on-violation"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Audit failed: 2 of 7 events violate the schema",
+			},
+		},
+		{
+			name:       "audit without a schema",
+			args:       []string{"audit", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				`required flag(s) "schema" not set`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.CombinedOutput()
+			actual := string(output)
+
+			if tt.shouldFail {
+				if err == nil {
+					t.Fatalf("Expected command to fail, but it succeeded. Output:\n%s", actual)
+				}
+				for _, expectedErr := range tt.expectedErrMsg {
+					if !strings.Contains(actual, expectedErr) {
+						t.Errorf("Expected error output to contain %q, but it didn't. Output:\n%s", expectedErr, actual)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput:\n%s", err, actual)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConformanceCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	t.Run("corpus matches golden output", func(t *testing.T) {
+		cmd := exec.Command("./loglion_test", "conformance", "--corpus", "../conformance")
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("Command failed: %v\nOutput:\n%s", err, output)
+		}
+
+		for _, expected := range []string{"PASS logcat:", "PASS json:", "PASS jsonl:", "PASS syslog:", "PASS multiline:", "All 7 conformance cases passed"} {
+			if !strings.Contains(string(output), expected) {
+				t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("regression fails", func(t *testing.T) {
+		corpus := t.TempDir()
+		caseDir := filepath.Join(corpus, "simple")
+		if err := os.Mkdir(caseDir, 0755); err != nil {
+			t.Fatalf("Failed to create case directory: %v", err)
+		}
+		files := map[string]string{
+			"parser.yaml":   "event_regex: \"^(.*)$\"\njson_extraction: false\n",
+			"input.log":     "login\nlogout\n",
+			"golden.ndjson": "{\"message\":\"login\"}\n",
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(caseDir, name), []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", name, err)
+			}
+		}
+
+		cmd := exec.Command("./loglion_test", "conformance", "--corpus", corpus)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail. Output:\n%s", output)
+		}
+
+		for _, expected := range []string{"FAIL simple: 1 mismatched entries", "expected: (missing)", `actual:   {"message":"logout"}`} {
+			if !strings.Contains(string(output), expected) {
+				t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, output)
+			}
+		}
+	})
+}
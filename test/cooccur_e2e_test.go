@@ -0,0 +1,90 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCooccurCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name           string
+		args           []string
+		expected       []string
+		shouldFail     bool
+		expectedErrMsg []string
+	}{
+		{
+			name: "co-occurrence by session",
+			args: []string{"cooccur", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--session-key", "user_id", "view_product", "add_cart", "purchase"},
+			expected: []string{
+				"Co-occurrence Analysis Complete",
+				"Sessions by user_id: 3 (idle timeout 30m0s)",
+				"Entries Without user_id: 1",
+				"2. add_cart: 2 sessions (66.7%)",
+				"add_cart      100.0%        100.0%    50.0%",
+				"view_product  -             2         1",
+			},
+		},
+		{
+			name: "co-occurrence by time window as JSON",
+			args: []string{"cooccur", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--window", "5s", "-o", "json", "login", "request"},
+			expected: []string{
+				`"unit": "window"`,
+				`"window_seconds": 5`,
+				`"units": 2`,
+			},
+		},
+		{
+			name:       "co-occurrence with a single pattern",
+			args:       []string{"cooccur", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "purchase"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error: at least 2 event patterns are required, got 1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.CombinedOutput()
+			actual := string(output)
+
+			if tt.shouldFail {
+				if err == nil {
+					t.Fatalf("Expected command to fail, but it succeeded. Output:\n%s", actual)
+				}
+				for _, expectedErr := range tt.expectedErrMsg {
+					if !strings.Contains(actual, expectedErr) {
+						t.Errorf("Expected error output to contain %q, but it didn't. Output:\n%s", expectedErr, actual)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput:\n%s", err, actual)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
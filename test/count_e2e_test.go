@@ -3,6 +3,7 @@ package test
 import (
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -23,23 +24,167 @@ func TestCountCommandE2E(t *testing.T) {
 	tests := []struct {
 		name     string
 		args     []string
+		stdin    string
 		expected []string
 	}{
 		{
 			name: "count basic events with simple parser",
 			args: []string{"count", "--parser-config", "sample/parsers/simple.yaml", "--log", "sample/logs/simple.txt", "login", "logout"},
 			expected: []string{
-				"📊 Event Count Analysis Complete",
+				"Event Count Analysis Complete",
 				"Pattern Counts:",
 				"login:",
 				"logout:",
 			},
 		},
+		{
+			name:  "count events piped to stdin",
+			args:  []string{"count", "--parser-config", "sample/parsers/simple.yaml", "login", "logout"},
+			stdin: "sample/logs/simple.txt",
+			expected: []string{
+				"Event Count Analysis Complete",
+				"Total Events Analyzed: 8",
+				"login:",
+				"logout:",
+			},
+		},
+		{
+			name:  "count with stdin redirected from /dev/null",
+			args:  []string{"count", "--parser-config", "sample/parsers/simple.yaml", "--output", "json", "login"},
+			stdin: os.DevNull,
+			expected: []string{
+				`"total_events_analyzed": 0`,
+			},
+		},
+		{
+			name: "count with emoji forced on piped output",
+			args: []string{"count", "--ascii=false", "--parser-config", "sample/parsers/simple.yaml", "--log", "sample/logs/simple.txt", "login"},
+			expected: []string{
+				"📊 Event Count Analysis Complete",
+				"login:",
+			},
+		},
+		{
+			name: "count with level and pid filters",
+			args: []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--level", "I", "--pid", "1234", "login", "request"},
+			expected: []string{
+				"Total Events Analyzed: 5",
+				"Duration:",
+				"Events per Minute:",
+				"login: 1 matches",
+				"request: 1 matches",
+				"per minute",
+			},
+		},
+		{
+			name: "count with wide table output",
+			args: []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--wide", "login"},
+			expected: []string{
+				"#  Pattern  Matches  Share  Per Minute  First               Last",
+				"1  login          2  25.0%       15.58  01-15 10:30:15.100  01-15 10:30:18.400",
+			},
+		},
+		{
+			name: "count with parallel parsing",
+			args: []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--workers", "4", "--level", "I", "--pid", "1234", "login", "request"},
+			expected: []string{
+				"Total Events Analyzed: 5",
+				"login: 1 matches",
+				"request: 1 matches",
+			},
+		},
+		{
+			name: "count with tag filters",
+			args: []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--tag", "Analytics", "--tag", "chatty", "--exclude-tag", "chatty", "login"},
+			expected: []string{
+				"Total Events Analyzed: 5",
+				"login: 2 matches",
+			},
+		},
+		{
+			name: "count syslog events by user",
+			args: []string{"count", "-p", "sample/parsers/syslog.yaml", "-l", "sample/logs/syslog.txt", "--level", "I", "--group-by", "user_id", "login", "timed out"},
+			expected: []string{
+				"Total Events Analyzed: 4",
+				"login: 2 matches",
+				"timed out: 1 matches",
+				"alice    1      0          1",
+				"bob      1      0          1",
+			},
+		},
+		{
+			name: "count Android Studio logcat export by user",
+			args: []string{"count", "-p", "sample/parsers/logcat_json.yaml", "-l", "sample/logs/studio.logcat", "--tag", "Analytics", "--group-by", "user_id", "login", "logout"},
+			expected: []string{
+				"Total Events Analyzed: 5",
+				"login: 2 matches",
+				"logout: 1 matches",
+				"alice    1      1       2",
+			},
+		},
+		{
+			name: "count merges multiple log files",
+			args: []string{"count", "-p", "sample/parsers/simple.yaml", "-l", "sample/logs/simple.txt", "-l", "sample/logs/logcat.txt", "login"},
+			expected: []string{
+				"Total Events Analyzed: 16",
+				"login: 4 matches",
+			},
+		},
+		{
+			name: "count grouped by property",
+			args: []string{"count", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--group-by", "user_id", "view_product", "purchase"},
+			expected: []string{
+				"Matches by user_id:",
+				"user_id  view_product  purchase  Total",
+				"alice    1             1         2",
+				"Without user_id: 1 matching entries",
+			},
+		},
+		{
+			name: "count per session",
+			args: []string{"count", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--per-session", "--session-key", "user_id", "add_cart"},
+			expected: []string{
+				"Sessions by user_id: 3 (idle timeout 30m0s)",
+				"1. add_cart: 2 sessions (66.7%), 0.67 per session, 1 per matching session",
+				"Entries Without user_id: 1",
+			},
+		},
+		{
+			name: "count with patterns file",
+			args: []string{"count", "-p", "sample/parsers/simple.yaml", "-l", "sample/logs/simple.txt", "--patterns-file", "sample/patterns/simple.txt", "error"},
+			expected: []string{
+				"1. error: 1 matches",
+				"2. login: 2 matches",
+				"3. Sign out: 2 matches",
+				"4. Clicks: 1 matches",
+			},
+		},
+		{
+			name: "count distinct property values",
+			args: []string{"count", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--distinct", "user_id", "--distinct-top", "1", "view_product", "purchase"},
+			expected: []string{
+				"Distinct user_id:",
+				"1. view_product: 3 values",
+				"   alice: 1",
+				"2. purchase: 1 values (1 matches without user_id)",
+			},
+		},
+		{
+			name: "count aggregate of a numeric property by group",
+			args: []string{"count", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/purchases.txt", "--aggregate", "amount", "--group-by", "user_id", "purchase", "refund"},
+			expected: []string{
+				"Aggregate amount:",
+				"1. purchase: sum=65.50 avg=21.83 min=15.50 max=30 (3 values, 1 matches without a numeric amount)",
+				"2. refund: sum=5 avg=5 min=5 max=5 (1 values)",
+				"Sum of amount by user_id:",
+				"alice    50        5       55",
+			},
+		},
 		{
 			name: "count with short flags",
 			args: []string{"count", "-p", "sample/parsers/simple.yaml", "-l", "sample/logs/simple.txt", "action"},
 			expected: []string{
-				"📊 Event Count Analysis Complete",
+				"Event Count Analysis Complete",
 				"Pattern Counts:",
 				"action:",
 			},
@@ -48,7 +193,7 @@ func TestCountCommandE2E(t *testing.T) {
 			name: "count with structured parser",
 			args: []string{"count", "--parser-config", "sample/parsers/structured.yaml", "--log", "sample/logs/structured.txt", "login", "purchase"},
 			expected: []string{
-				"📊 Event Count Analysis Complete",
+				"Event Count Analysis Complete",
 				"Pattern Counts:",
 				"login:",
 				"purchase:",
@@ -58,7 +203,7 @@ func TestCountCommandE2E(t *testing.T) {
 			name: "count with regex patterns",
 			args: []string{"count", "--parser-config", "sample/parsers/structured.yaml", "--log", "sample/logs/structured.txt", "user_\\d+", "product_\\d+"},
 			expected: []string{
-				"📊 Event Count Analysis Complete",
+				"Event Count Analysis Complete",
 				"Pattern Counts:",
 				"user_\\d+:",
 				"product_\\d+:",
@@ -84,7 +229,7 @@ func TestCountCommandE2E(t *testing.T) {
 			name: "count multiple patterns with structured parser",
 			args: []string{"count", "--parser-config", "sample/parsers/structured.yaml", "--log", "sample/logs/structured.txt", "login", "purchase", "logout"},
 			expected: []string{
-				"📊 Event Count Analysis Complete",
+				"Event Count Analysis Complete",
 				"Pattern Counts:",
 				"login:",
 				"purchase:",
@@ -97,6 +242,14 @@ func TestCountCommandE2E(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			cmd := exec.Command("./loglion_test", tt.args...)
 			cmd.Dir = "."
+			if tt.stdin != "" {
+				stdin, err := os.Open(tt.stdin)
+				if err != nil {
+					t.Fatalf("Failed to open stdin file: %v", err)
+				}
+				defer stdin.Close()
+				cmd.Stdin = stdin
+			}
 
 			output, err := cmd.Output()
 			if err != nil {
@@ -137,7 +290,7 @@ func TestCountCommandErrorCasesE2E(t *testing.T) {
 			args:       []string{"count", "--parser-config", "sample/parsers/simple.yaml", "--log", "sample/logs/simple.txt"},
 			shouldFail: true,
 			expectedErrMsg: []string{
-				"requires at least 1 arg(s)",
+				"requires at least 1 arg(s) or --patterns-file",
 			},
 		},
 		{
@@ -150,12 +303,19 @@ func TestCountCommandErrorCasesE2E(t *testing.T) {
 			},
 		},
 		{
-			name:       "count with missing log file",
-			args:       []string{"count", "--parser-config", "sample/parsers/simple.yaml", "login"},
+			name:       "count watching stdin",
+			args:       []string{"count", "--parser-config", "sample/parsers/simple.yaml", "--watch", "login"},
 			shouldFail: true,
 			expectedErrMsg: []string{
-				"required flag(s)",
-				"log",
+				"--watch requires --log",
+			},
+		},
+		{
+			name:       "count with sankey output",
+			args:       []string{"count", "--parser-config", "sample/parsers/simple.yaml", "--log", "sample/logs/simple.txt", "-o", "sankey", "login"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"sankey output is only supported by funnel",
 			},
 		},
 		{
@@ -176,6 +336,14 @@ func TestCountCommandErrorCasesE2E(t *testing.T) {
 				"non-existent.txt",
 			},
 		},
+		{
+			name:       "count per session without session key",
+			args:       []string{"count", "--parser-config", "sample/parsers/simple.yaml", "--log", "sample/logs/simple.txt", "--per-session", "login"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"--per-session requires --session-key",
+			},
+		},
 		{
 			name:           "count with invalid output format",
 			args:           []string{"count", "--parser-config", "sample/parsers/simple.yaml", "--log", "sample/logs/simple.txt", "--output", "invalid", "login"},
@@ -366,7 +534,7 @@ func TestCountCommandVerboseFlagE2E(t *testing.T) {
 			name: "count with verbose flag",
 			args: []string{"--verbose", "count", "--parser-config", "sample/parsers/simple.yaml", "--log", "sample/logs/simple.txt", "login"},
 			expected: []string{
-				"📊 Event Count Analysis Complete",
+				"Event Count Analysis Complete",
 				"Pattern Counts:",
 				"login:",
 			},
@@ -375,7 +543,7 @@ func TestCountCommandVerboseFlagE2E(t *testing.T) {
 			name: "count with short verbose flag",
 			args: []string{"-v", "count", "-p", "sample/parsers/simple.yaml", "-l", "sample/logs/simple.txt", "logout"},
 			expected: []string{
-				"📊 Event Count Analysis Complete",
+				"Event Count Analysis Complete",
 				"Pattern Counts:",
 				"logout:",
 			},
@@ -401,3 +569,319 @@ func TestCountCommandVerboseFlagE2E(t *testing.T) {
 		})
 	}
 }
+
+func TestCountCommandParseStatsE2E(t *testing.T) {
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	logFile := filepath.Join(t.TempDir(), "malformed.txt")
+	content := "01-15 10:30:15.100  1234  1250 I Analytics: login\n" +
+		"13-45 99:99:99.999  1234  1250 I Analytics: login\n" +
+		"01-15 10:30:16.100  1234  1250 I Analytics: caf\xe9\n" +
+		"not a logcat line\n"
+	if err := os.WriteFile(logFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "warns about repaired lines",
+			args:     []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", logFile, "login"},
+			expected: "Warning: 1 line with invalid UTF-8 repaired, 1 invalid timestamp while parsing logs",
+		},
+		{
+			name:     "prints parse stats",
+			args:     []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", logFile, "--parse-stats", "login"},
+			expected: "Parse stats: 4 lines, 3 entries parsed, 0 empty, 1 unmatched; 1 line with invalid UTF-8 repaired, 1 invalid timestamp",
+		},
+		{
+			name:     "lists skipped lines",
+			args:     []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", logFile, "login"},
+			expected: "Skipped 1 log line(s) that could not be parsed (use --strict to fail instead):\n  " + logFile + ":4: not a logcat line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+
+			output, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Command failed: %v\nStderr: %s", err, stderr.String())
+			}
+			if !strings.Contains(string(output), "login: 2 matches") {
+				t.Errorf("Expected both login lines to be counted, got:\n%s", output)
+			}
+			if !strings.Contains(stderr.String(), tt.expected) {
+				t.Errorf("Expected stderr to contain %q, got:\n%s", tt.expected, stderr.String())
+			}
+		})
+	}
+}
+
+func TestCountCommandStrictE2E(t *testing.T) {
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	logFile := filepath.Join(t.TempDir(), "partial.txt")
+	content := "01-15 10:30:15.100  1234  1250 I Analytics: login\n" +
+		"--------- beginning of main\n" +
+		"01-15 10:30:16.100  1234  1250 I Analytics: login\n"
+	if err := os.WriteFile(logFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	cmd := exec.Command("./loglion_test", "count", "-p", "sample/parsers/logcat.yaml", "-l", logFile, "--strict", "login")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err == nil {
+		t.Fatalf("Expected --strict to fail on a skipped line, got:\n%s", output)
+	}
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		t.Errorf("Expected exit code 1, got: %v", err)
+	}
+	expected := "Error: 1 log line(s) could not be parsed (--strict):\n  " + logFile + ":2: --------- beginning of main"
+	if !strings.Contains(stderr.String(), expected) {
+		t.Errorf("Expected stderr to contain %q, got:\n%s", expected, stderr.String())
+	}
+	if strings.Contains(string(output), "login") {
+		t.Errorf("Expected no results with --strict, got:\n%s", output)
+	}
+
+	// Clean logs pass
+	cmd = exec.Command("./loglion_test", "count", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--strict", "login")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("Expected --strict to pass on a clean log, got: %v\n%s", err, output)
+	}
+}
+
+func TestCountCommandMaxLineBytesE2E(t *testing.T) {
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	// A JSON payload well beyond bufio.Scanner's 64 KiB default
+	logFile := filepath.Join(t.TempDir(), "large.txt")
+	content := "01-15 10:30:15.100  1234  1250 I Analytics: {\"event\":\"upload\",\"blob\":\"" + strings.Repeat("a", 200000) + "\"}\n" +
+		"01-15 10:30:16.100  1234  1250 I Analytics: {\"event\":\"upload\"}\n"
+	if err := os.WriteFile(logFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		expected       string
+		expectedStderr string
+	}{
+		{
+			name:     "parses large lines by default",
+			args:     []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", logFile, "upload"},
+			expected: "upload: 2 matches",
+		},
+		{
+			name:           "warns about lines over the limit",
+			args:           []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", logFile, "--max-line-bytes", "65536", "upload"},
+			expected:       "upload: 1 matches",
+			expectedStderr: "Warning: 1 line(s) longer than 65536 bytes were skipped (use --max-line-bytes to raise the limit)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+
+			output, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Command failed: %v\nStderr: %s", err, stderr.String())
+			}
+			if !strings.Contains(string(output), tt.expected) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.expected, output)
+			}
+			if tt.expectedStderr != "" && !strings.Contains(stderr.String(), tt.expectedStderr) {
+				t.Errorf("Expected stderr to contain %q, got:\n%s", tt.expectedStderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestCountCommandDuplicateFilesE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	// The same device log uploaded under another name
+	data, err := os.ReadFile("sample/logs/logcat.txt")
+	if err != nil {
+		t.Fatalf("Failed to read sample log: %v", err)
+	}
+	copyFile := filepath.Join(t.TempDir(), "pixel7-copy.txt")
+	if err := os.WriteFile(copyFile, data, 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		expected       string
+		expectedStderr string
+	}{
+		{
+			name:           "analyzes duplicates once",
+			args:           []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-l", copyFile, "login"},
+			expected:       "login: 2 matches",
+			expectedStderr: "Skipping duplicate log file " + copyFile + " (same content as sample/logs/logcat.txt)",
+		},
+		{
+			name:     "keeps duplicates",
+			args:     []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-l", copyFile, "--keep-duplicates", "login"},
+			expected: "login: 4 matches",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+
+			output, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Command failed: %v\nStderr: %s", err, stderr.String())
+			}
+			if !strings.Contains(string(output), tt.expected) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.expected, output)
+			}
+			if tt.expectedStderr != "" && !strings.Contains(stderr.String(), tt.expectedStderr) {
+				t.Errorf("Expected stderr to contain %q, got:\n%s", tt.expectedStderr, stderr.String())
+			}
+			if tt.expectedStderr == "" && strings.Contains(stderr.String(), "Skipping duplicate") {
+				t.Errorf("Expected no duplicate note, got:\n%s", stderr.String())
+			}
+		})
+	}
+}
+
+func TestCountCommandDedupeE2E(t *testing.T) {
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tempDir := t.TempDir()
+	parserFile := filepath.Join(tempDir, "parser.yaml")
+	parserContent := "format: android-logcat\nevent_regex: \"Analytics: (.*)\"\njson_extraction: true\n"
+	if err := os.WriteFile(parserFile, []byte(parserContent), 0644); err != nil {
+		t.Fatalf("Failed to write parser config: %v", err)
+	}
+
+	// The SDK retried the flush of order A
+	logFile := filepath.Join(tempDir, "retries.txt")
+	content := "01-15 10:30:15.100  1234  1250 I Analytics: {\"event\":\"purchase\",\"order_id\":\"A\"}\n" +
+		"01-15 10:30:15.400  1234  1250 I Analytics: {\"event\":\"purchase\",\"order_id\":\"A\"}\n" +
+		"01-15 10:30:15.500  1234  1250 I Analytics: {\"event\":\"purchase\",\"order_id\":\"B\"}\n" +
+		"01-15 10:30:20.000  1234  1250 I Analytics: {\"event\":\"purchase\",\"order_id\":\"A\"}\n"
+	if err := os.WriteFile(logFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		expectedOutput string
+		expectedStderr string
+		expectError    bool
+	}{
+		{
+			name:           "without dedupe",
+			args:           []string{"purchase"},
+			expectedOutput: `"count": 4`,
+		},
+		{
+			name:           "event only",
+			args:           []string{"--dedupe", "purchase"},
+			expectedOutput: `"count": 2`,
+			expectedStderr: "Removed 2 duplicate event(s) repeated within 1s (--dedupe)",
+		},
+		{
+			name:           "event and key property",
+			args:           []string{"--dedupe", "--dedupe-key", "order_id", "purchase"},
+			expectedOutput: `"count": 3`,
+			expectedStderr: "Removed 1 duplicate event(s)",
+		},
+		{
+			name:           "wider window",
+			args:           []string{"--dedupe", "--dedupe-key", "order_id", "--dedupe-window", "10s", "purchase"},
+			expectedOutput: `"count": 2`,
+		},
+		{
+			name:           "invalid window",
+			args:           []string{"--dedupe", "--dedupe-window", "0s", "purchase"},
+			expectedStderr: "Error: --dedupe-window must be positive, got 0s",
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"count", "-p", parserFile, "-l", logFile, "-o", "json"}, tt.args...)
+			cmd := exec.Command("./loglion_test", args...)
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+
+			output, err := cmd.Output()
+			if tt.expectError != (err != nil) {
+				t.Fatalf("Expected error %v, got %v\nOutput: %s\nStderr: %s", tt.expectError, err, output, stderr.String())
+			}
+			if !strings.Contains(string(output), tt.expectedOutput) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.expectedOutput, output)
+			}
+			if !strings.Contains(stderr.String(), tt.expectedStderr) {
+				t.Errorf("Expected stderr to contain %q, got:\n%s", tt.expectedStderr, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestDiffCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name: "funnel diff",
+			args: []string{"diff", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml",
+				"--baseline", "sample/logs/logcat.txt", "--candidate", "sample/logs/logcat_release.txt"},
+			expected: []string{
+				"Funnel Diff",
+				"Baseline: sample/logs/logcat.txt",
+				"Candidate: sample/logs/logcat_release.txt",
+				"Basic User Flow:",
+				"3. Logout  1 (100.0%)  0 (0.0%)    -1       -100.0pp",
+				"Completed  Yes         No",
+				"Action -> Logout  0.0%      100.0%     +100.0pp",
+				"Conversion Rate: 100.0% -> 0.0% (-100.0pp)",
+			},
+		},
+		{
+			name: "funnel diff with JSON output",
+			args: []string{"diff", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml",
+				"--baseline", "sample/logs/logcat.txt", "--candidate", "sample/logs/logcat_release.txt", "-o", "json"},
+			expected: []string{
+				`"candidate": "sample/logs/logcat_release.txt"`,
+				`"baseline_completed": true`,
+				`"candidate_completed": false`,
+				`"conversion_rate_delta": -100`,
+				`"count_delta": -1`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Command failed: %v", err)
+			}
+
+			actual := string(output)
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name           string
+		args           []string
+		expected       []string
+		shouldFail     bool
+		expectedErrMsg []string
+	}{
+		{
+			name: "discover sequences by session",
+			args: []string{"discover", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--session-key", "user_id", "--min-support", "50"},
+			expected: []string{
+				"Sequence Discovery Complete",
+				"Sessions by user_id: 3 (idle timeout 30m0s)",
+				"Entries Without user_id: 1",
+				"Minimum Support: 2 sessions",
+				"1. view_product -> add_cart: 2 sessions (66.7%)",
+			},
+		},
+		{
+			name: "discover sequences as funnel config",
+			args: []string{"discover", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--session-key", "user_id", "--min-support", "50", "-o", "yaml"},
+			expected: []string{
+				"# 1 candidate funnels from 3 sessions, occurring in at least 2 sessions each",
+				"- name: view_product → add_cart (2 sessions)",
+				"event_pattern: ^view_product$",
+				"group_by: user_id",
+			},
+		},
+		{
+			name:       "discover with invalid minimum support",
+			args:       []string{"discover", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--min-support", "0"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error: minimum support must be a percentage above 0 and up to 100, got 0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.CombinedOutput()
+			actual := string(output)
+
+			if tt.shouldFail {
+				if err == nil {
+					t.Fatalf("Expected command to fail, but it succeeded. Output:\n%s", actual)
+				}
+				for _, expectedErr := range tt.expectedErrMsg {
+					if !strings.Contains(actual, expectedErr) {
+						t.Errorf("Expected error output to contain %q, but it didn't. Output:\n%s", expectedErr, actual)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput:\n%s", err, actual)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
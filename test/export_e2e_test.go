@@ -0,0 +1,92 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestExportCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name           string
+		args           []string
+		expected       []string
+		shouldFail     bool
+		expectedErrMsg []string
+	}{
+		{
+			name: "export as CSV",
+			args: []string{"export", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--tag", "Analytics"},
+			expected: []string{
+				"timestamp,level,tag,pid,tid,event,message\n",
+				"0000-01-15T10:30:15.1Z,I,Analytics,1234,1250,login,login\n",
+				"0000-01-15T10:30:17.3Z,I,Analytics,1234,1250,action,action\n",
+			},
+		},
+		{
+			name: "export properties as CSV",
+			args: []string{"export", "-p", "sample/parsers/jsonl.yaml", "-l", "sample/logs/events.jsonl", "--properties", "user_id"},
+			expected: []string{
+				"timestamp,level,tag,pid,tid,event,message,user_id\n",
+				"2025-01-15T10:30:15Z,I,,,,view_product,product viewed,alice\n",
+				"2025-01-15T10:30:20Z,D,,,,cache refreshed,cache refreshed,\n",
+			},
+		},
+		{
+			name:     "export as Parquet",
+			args:     []string{"export", "-p", "sample/parsers/jsonl.yaml", "-l", "sample/logs/events.jsonl", "--format", "parquet"},
+			expected: []string{"PAR1", "view_product", "loglion"},
+		},
+		{
+			name:       "export with unsupported format",
+			args:       []string{"export", "-p", "sample/parsers/jsonl.yaml", "-l", "sample/logs/events.jsonl", "--format", "xlsx"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error: unsupported export format: xlsx (supported: csv, parquet)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.CombinedOutput()
+			actual := string(output)
+
+			if tt.shouldFail {
+				if err == nil {
+					t.Fatalf("Expected command to fail, but it succeeded. Output:\n%s", actual)
+				}
+				for _, expectedErr := range tt.expectedErrMsg {
+					if !strings.Contains(actual, expectedErr) {
+						t.Errorf("Expected error output to contain %q, but it didn't. Output:\n%s", expectedErr, actual)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput:\n%s", err, actual)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestExtractCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "raw lines of matching entries",
+			args:     []string{"extract", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "^login$"},
+			expected: "01-15 10:30:15.100  1234  1250 I Analytics: login\n01-15 10:30:18.400  5678  5700 I Analytics: login\n",
+		},
+		{
+			name: "context around matches",
+			args: []string{"extract", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-C", "1", "action", "chatty|expire"},
+			expected: "01-15 10:30:16.200  1234  1251 D Network: request started\n" +
+				"01-15 10:30:17.300  1234  1250 I Analytics: action\n" +
+				"01-15 10:30:18.400  5678  5700 I Analytics: login\n" +
+				"--\n" +
+				"01-15 10:30:21.700  1234  1250 I Analytics: logout\n" +
+				"01-15 10:30:22.800  5678  5700 I chatty: uid=10123 expire 3 lines\n",
+		},
+		{
+			name:     "funnel step",
+			args:     []string{"extract", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-f", "sample/funnels/basic.yaml", "--step", "Logout"},
+			expected: "01-15 10:30:21.700  1234  1250 I Analytics: logout\n",
+		},
+		{
+			name:     "redacted lines",
+			args:     []string{"extract", "-p", "sample/parsers/redacted.yaml", "-l", "sample/logs/users.txt", "^purchase$"},
+			expected: "{\"event\": \"purchase\", \"user_id\": \"<user>\"}\n{\"event\": \"purchase\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Command failed: %v", err)
+			}
+
+			if actual := string(output); actual != tt.expected {
+				t.Errorf("Expected output:\n%s\ngot:\n%s", tt.expected, actual)
+			}
+		})
+	}
+
+	t.Run("JSON output", func(t *testing.T) {
+		cmd := exec.Command("./loglion_test", "extract", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-o", "json", "error")
+		cmd.Dir = "."
+
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("Command failed: %v", err)
+		}
+		for _, expected := range []string{`"matches": 1`, `"level": "E"`, `"message": "error"`, `"raw": "01-15 10:30:20.600  1234  1250 E Analytics: error"`} {
+			if !strings.Contains(string(output), expected) {
+				t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("patterns and step together", func(t *testing.T) {
+		cmd := exec.Command("./loglion_test", "extract", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-f", "sample/funnels/basic.yaml", "--step", "Logout", "login")
+		cmd.Dir = "."
+
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Fatal("Expected command to fail")
+		}
+		if !strings.Contains(string(output), "event patterns cannot be combined with --step") {
+			t.Errorf("Unexpected error output:\n%s", output)
+		}
+	})
+}
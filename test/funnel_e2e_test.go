@@ -1,7 +1,9 @@
 package test
 
 import (
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -28,7 +30,7 @@ func TestFunnelCommandE2E(t *testing.T) {
 			name: "funnel basic flow with simple logs",
 			args: []string{"funnel", "--parser-config", "sample/parsers/simple.yaml", "--funnel-config", "sample/funnels/basic.yaml", "--log", "sample/logs/simple.txt"},
 			expected: []string{
-				"✅ Funnel Analysis Complete",
+				"PASS Funnel Analysis Complete",
 				"Funnel: Basic User Flow",
 				"Step Breakdown:",
 				"Login:",
@@ -37,11 +39,76 @@ func TestFunnelCommandE2E(t *testing.T) {
 				"Drop-off Analysis:",
 			},
 		},
+		{
+			name: "funnel with Android Studio logcat export",
+			args: []string{"funnel", "-p", "sample/parsers/logcat_json.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/studio.logcat"},
+			expected: []string{
+				"PASS Funnel Analysis Complete",
+				"Total Events Analyzed: 7",
+				"Conversions Found: 1",
+				"1. entries 1 -> 7, 2025-01-15 10:30:15.100 -> 2025-01-15 10:30:21.700 (6.6s)",
+			},
+		},
+		{
+			name: "funnel with combined config file",
+			args: []string{"funnel", "--config", "sample/configs/basic.yaml", "-l", "sample/logs/simple.txt"},
+			expected: []string{
+				"PASS Funnel Analysis Complete",
+				"Funnel: Basic User Flow",
+				"Logout:",
+			},
+		},
+		{
+			name: "funnel with config overrides",
+			args: []string{"funnel", "-p", "sample/parsers/simple.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/simple.txt", "--set", "funnel.name=Overridden Flow"},
+			expected: []string{
+				"PASS Funnel Analysis Complete",
+				"Funnel: Overridden Flow",
+			},
+		},
+		{
+			name: "funnel across multiple log files",
+			args: []string{"funnel", "-p", "sample/parsers/simple.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/simple.txt", "-l", "sample/logs/simple.txt"},
+			expected: []string{
+				"PASS Funnel Analysis Complete",
+				"Funnel: Basic User Flow",
+			},
+		},
+		{
+			name: "funnel with log glob pattern",
+			args: []string{"funnel", "-p", "sample/parsers/simple.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/simp*.txt"},
+			expected: []string{
+				"PASS Funnel Analysis Complete",
+				"Funnel: Basic User Flow",
+			},
+		},
+		{
+			name: "funnel with jsonl logs",
+			args: []string{"funnel", "-p", "sample/parsers/jsonl.yaml", "-f", "sample/funnels/purchase.yaml", "-l", "sample/logs/events.jsonl"},
+			expected: []string{
+				"PASS Funnel Analysis Complete",
+				"Total Events Analyzed: 4",
+				"3. Purchase: 1 events (100.0%)",
+				"- Purchase (total): n=1 min=10s",
+			},
+		},
+		{
+			name: "funnel grouped by user",
+			args: []string{"funnel", "-p", "sample/parsers/json.yaml", "-f", "sample/funnels/per_user.yaml", "-l", "sample/logs/users.txt"},
+			expected: []string{
+				"Funnel: Per-User Purchase Flow",
+				"Grouped By: user_id (3 groups, 1 completed, 2 dropped)",
+				"Entries Without user_id: 1",
+				"1. Product View: 3 groups (100.0%)",
+				"2. Add to Cart: 2 groups (66.7%)",
+				"3. Purchase: 1 groups (33.3%)",
+			},
+		},
 		{
 			name: "funnel with short flags",
 			args: []string{"funnel", "-p", "sample/parsers/simple.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/simple.txt"},
 			expected: []string{
-				"✅ Funnel Analysis Complete",
+				"PASS Funnel Analysis Complete",
 				"Funnel: Basic User Flow",
 				"Step Breakdown:",
 				"Login:",
@@ -49,11 +116,37 @@ func TestFunnelCommandE2E(t *testing.T) {
 				"Logout:",
 			},
 		},
+		{
+			name: "funnel with table output",
+			args: []string{"funnel", "-p", "sample/parsers/simple.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/simple.txt", "--borders", "--only", "steps"},
+			expected: []string{
+				"| # | Step   | Events | Conversion | Drop-off |",
+				"| 2 | Action |      1 |     100.0% |     0.0% |",
+				"+---+--------+--------+------------+----------+",
+			},
+		},
+		{
+			name: "funnel with template output",
+			args: []string{"funnel", "-p", "sample/parsers/simple.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/simple.txt", "-o", "template", "--template-file", "sample/templates/slack.tmpl"},
+			expected: []string{
+				":white_check_mark: *Basic User Flow*: 100.0% converted (1 conversions)\n",
+				"Login 1 → Action 1 → Logout 1\n",
+			},
+		},
+		{
+			name: "funnel with sankey output",
+			args: []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/logcat.txt", "-o", "sankey"},
+			expected: []string{
+				`"funnel_name": "Basic User Flow"`,
+				`"name": "Login",`,
+				`"kind": "step"`,
+			},
+		},
 		{
 			name: "funnel purchase flow with structured logs",
 			args: []string{"funnel", "--parser-config", "sample/parsers/structured.yaml", "--funnel-config", "sample/funnels/purchase.yaml", "--log", "sample/logs/structured.txt"},
 			expected: []string{
-				"✅ Funnel Analysis Complete",
+				"PASS Funnel Analysis Complete",
 				"Funnel: Purchase Flow",
 				"Step Breakdown:",
 				"Product View:",
@@ -85,16 +178,53 @@ func TestFunnelCommandE2E(t *testing.T) {
 				`"name": "Purchase"`,
 			},
 		},
+		{
+			name: "funnel with multiple funnels in one config",
+			args: []string{"funnel", "-p", "sample/parsers/simple.yaml", "-f", "sample/funnels/multi.yaml", "-l", "sample/logs/simple.txt"},
+			expected: []string{
+				"Multi-Funnel Report",
+				"Funnels Analyzed: 2",
+				"Funnel: Basic User Flow",
+				"Funnel: Purchase Flow",
+			},
+		},
 		{
 			name: "funnel with limit flag",
 			args: []string{"funnel", "--parser-config", "sample/parsers/simple.yaml", "--funnel-config", "sample/funnels/basic.yaml", "--log", "sample/logs/simple.txt", "--limit", "1"},
 			expected: []string{
-				"✅ Funnel Analysis Complete",
+				"PASS Funnel Analysis Complete",
 				"Funnel: Basic User Flow",
 				"Step Breakdown:",
 				"Login:",
 				"Action:",
 				"Logout:",
+				"Conversions Found: 1\n",
+				"Conversions:\n1. entries 1 -> 6\n",
+			},
+		},
+		{
+			name: "funnel in strict mode",
+			args: []string{"funnel", "-p", "sample/parsers/simple.yaml", "-f", "sample/funnels/strict.yaml", "-l", "sample/logs/simple.txt"},
+			expected: []string{
+				"Funnel: Strict User Flow",
+				"Conversions Found: 1\n",
+				"Conversions:\n1. entries 4 -> 6\n",
+			},
+		},
+		{
+			name: "funnel with conversion steps",
+			args: []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/logcat.txt", "--show-conversions"},
+			expected: []string{
+				"Conversions:\n1. entries 1 -> 7, 01-15 10:30:15.100 -> 01-15 10:30:21.700 (6.6s)\n   Login: #1 01-15 10:30:15.100 login\n",
+				"   Logout: #7 01-15 10:30:21.700 logout\n",
+			},
+		},
+		{
+			name: "funnel with matched entry samples",
+			args: []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/logcat.txt", "--show-samples", "1"},
+			expected: []string{
+				"1. Login: 1 events (100.0%)\n     #1 01-15 10:30:15.100 login\n",
+				"3. Logout: 1 events (100.0%)\n     #7 01-15 10:30:21.700 logout\n",
 			},
 		},
 	}
@@ -161,8 +291,7 @@ func TestFunnelCommandErrorCasesE2E(t *testing.T) {
 			args:       []string{"funnel", "--parser-config", "sample/parsers/simple.yaml", "--funnel-config", "sample/funnels/basic.yaml"},
 			shouldFail: true,
 			expectedErrMsg: []string{
-				"required flag(s)",
-				"log",
+				"at least one of the flags in the group [log label] is required",
 			},
 		},
 		{
@@ -198,6 +327,32 @@ func TestFunnelCommandErrorCasesE2E(t *testing.T) {
 			shouldFail:     false, // Invalid output format defaults to text format
 			expectedErrMsg: []string{},
 		},
+		{
+			name:       "funnel below minimum conversion rate",
+			args:       []string{"funnel", "-p", "sample/parsers/structured.yaml", "-f", "sample/funnels/purchase.yaml", "-l", "sample/logs/structured.txt", "--min-conversion-rate", "80"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Funnel Analysis Complete",
+				"Funnel check failed: conversion rate 50.0% is below the required 80.0%",
+			},
+		},
+		{
+			name:       "funnel failing a step assertion",
+			args:       []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/slo.yaml", "-l", "sample/logs/logcat_release.txt"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"3. Logout: 0 events (0.0%) FAIL assertion failed",
+				"Assertions: 1 passed, 1 failed",
+				"- PASS Login: min_count 1, got 1",
+				"Assertion failed in funnel 'Basic User Flow': Logout: min_conversion_pct 50.0%, got 0.0%",
+			},
+		},
+		{
+			name:           "funnel meeting its step assertions",
+			args:           []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/slo.yaml", "-l", "sample/logs/logcat.txt"},
+			shouldFail:     false,
+			expectedErrMsg: []string{"Assertions: 2 passed, 0 failed"},
+		},
 		{
 			name:           "funnel with invalid limit value",
 			args:           []string{"funnel", "--parser-config", "sample/parsers/simple.yaml", "--funnel-config", "sample/funnels/basic.yaml", "--log", "sample/logs/simple.txt", "--limit", "-1"},
@@ -329,7 +484,7 @@ func TestFunnelCommandVerboseFlagE2E(t *testing.T) {
 			name: "funnel with verbose flag",
 			args: []string{"--verbose", "funnel", "--parser-config", "sample/parsers/simple.yaml", "--funnel-config", "sample/funnels/basic.yaml", "--log", "sample/logs/simple.txt"},
 			expected: []string{
-				"✅ Funnel Analysis Complete",
+				"PASS Funnel Analysis Complete",
 				"Funnel: Basic User Flow",
 				"Step Breakdown:",
 				"Login:",
@@ -341,7 +496,7 @@ func TestFunnelCommandVerboseFlagE2E(t *testing.T) {
 			name: "funnel with short verbose flag",
 			args: []string{"-v", "funnel", "-p", "sample/parsers/simple.yaml", "-f", "sample/funnels/basic.yaml", "-l", "sample/logs/simple.txt"},
 			expected: []string{
-				"✅ Funnel Analysis Complete",
+				"PASS Funnel Analysis Complete",
 				"Funnel: Basic User Flow",
 				"Step Breakdown:",
 				"Login:",
@@ -369,4 +524,199 @@ func TestFunnelCommandVerboseFlagE2E(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestFunnelCommandProjectConfigE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	binary, _ := filepath.Abs("loglion_test")
+	sampleDir, _ := filepath.Abs("sample")
+
+	// Declare parser config and log defaults in a project file
+	projectDir := t.TempDir()
+	projectConfig := "funnel:\n" +
+		"  parser-config: " + filepath.Join(sampleDir, "parsers", "simple.yaml") + "\n" +
+		"  log: " + filepath.Join(sampleDir, "logs", "simple.txt") + "\n" +
+		"  output: json\n"
+	if err := os.WriteFile(filepath.Join(projectDir, ".loglion.yaml"), []byte(projectConfig), 0644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	funnelConfig := filepath.Join(sampleDir, "funnels", "basic.yaml")
+
+	t.Run("defaults from project file", func(t *testing.T) {
+		cmd := exec.Command(binary, "funnel", "-f", funnelConfig)
+		cmd.Dir = projectDir
+
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("Command failed: %v", err)
+		}
+		if !strings.Contains(string(output), `"funnel_name": "Basic User Flow"`) {
+			t.Errorf("Expected JSON output from project defaults, got:\n%s", output)
+		}
+	})
+
+	t.Run("command line overrides project file", func(t *testing.T) {
+		cmd := exec.Command(binary, "funnel", "-f", funnelConfig, "-o", "text")
+		cmd.Dir = projectDir
+
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("Command failed: %v", err)
+		}
+		if !strings.Contains(string(output), "Funnel: Basic User Flow") {
+			t.Errorf("Expected text output, got:\n%s", output)
+		}
+	})
+
+	t.Run("project file ignored with --no-project-config", func(t *testing.T) {
+		cmd := exec.Command(binary, "--no-project-config", "funnel", "-f", funnelConfig)
+		cmd.Dir = projectDir
+
+		if err := cmd.Run(); err == nil {
+			t.Error("Expected command to fail without required flags, but it succeeded")
+		}
+	})
+}
+
+func TestFunnelClockRegressionE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	// The device clock is set back between login and action
+	logFile := filepath.Join(t.TempDir(), "clock.txt")
+	content := "01-15 10:30:15.100  1234  1250 I Analytics: login\n" +
+		"01-15 10:30:05.000  1234  1250 I Analytics: action\n" +
+		"01-15 10:30:06.000  1234  1250 I Analytics: logout\n"
+	if err := os.WriteFile(logFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name: "reports regressions",
+			args: []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml", "-l", logFile, "-o", "json"},
+			expected: []string{
+				"Warning: 1 timestamp regression while parsing logs",
+				"use --monotonicize to clamp them",
+				`"min_seconds": -10.1`,
+			},
+		},
+		{
+			name: "clamps timestamps",
+			args: []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml", "-l", logFile, "-o", "json", "--monotonicize"},
+			expected: []string{
+				"Warning: 1 timestamp regression while parsing logs",
+				`"min_seconds": 0`,
+			},
+			notExpected: []string{"use --monotonicize", `"min_seconds": -`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(string(output), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			for _, notExpected := range tt.notExpected {
+				if strings.Contains(string(output), notExpected) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", notExpected, output)
+				}
+			}
+		})
+	}
+}
+
+func TestFunnelSortByTimestampE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	// The main and system buffers were captured to separate files
+	tempDir := t.TempDir()
+	mainLog := filepath.Join(tempDir, "main.txt")
+	systemLog := filepath.Join(tempDir, "system.txt")
+	mainContent := "01-15 10:30:00.000  1234  1250 I Analytics: login\n" +
+		"01-15 10:30:10.000  1234  1250 I Analytics: logout\n"
+	systemContent := "01-15 10:30:05.000  1234  1250 I Analytics: action\n"
+	if err := os.WriteFile(mainLog, []byte(mainContent), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+	if err := os.WriteFile(systemLog, []byte(systemContent), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "files in given order",
+			args:     []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml", "-l", mainLog, "-l", systemLog, "-o", "json"},
+			expected: []string{`"funnel_completed": false`},
+		},
+		{
+			name:     "sorted by timestamp",
+			args:     []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml", "-l", mainLog, "-l", systemLog, "-o", "json", "--sort-by-timestamp"},
+			expected: []string{`"funnel_completed": true`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(string(output), expected) {
+					t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestGapsCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name           string
+		args           []string
+		expected       []string
+		shouldFail     bool
+		expectedErrMsg []string
+	}{
+		{
+			name: "gaps between heartbeat events",
+			args: []string{"gaps", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--max-gap", "2s", "login"},
+			expected: []string{
+				"Gap Analysis Complete",
+				"Matching Events: 2",
+				"Gaps Longer Than 2s:",
+				"1. 01-15 10:30:15.100 -> 01-15 10:30:18.400: 3.3s",
+				"2. 01-15 10:30:18.400 -> 01-15 10:30:22.800: 4.4s (until the end of the log)",
+			},
+		},
+		{
+			name: "no gaps as JSON",
+			args: []string{"gaps", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--max-gap", "5s", "-o", "json", "login"},
+			expected: []string{
+				`"matching_events": 2`,
+				`"gaps": []`,
+				`"longest_gap_seconds": 4.4`,
+			},
+		},
+		{
+			name:       "gaps with fail-on-gap",
+			args:       []string{"gaps", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--max-gap", "2s", "--fail-on-gap", "login"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Gap check failed: 2 gaps longer than 2s",
+			},
+		},
+		{
+			name:       "gaps without a pattern",
+			args:       []string{"gaps", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error: accepts 1 arg(s), received 0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.CombinedOutput()
+			actual := string(output)
+
+			if tt.shouldFail {
+				if err == nil {
+					t.Fatalf("Expected command to fail, but it succeeded. Output:\n%s", actual)
+				}
+				for _, expectedErr := range tt.expectedErrMsg {
+					if !strings.Contains(actual, expectedErr) {
+						t.Errorf("Expected error output to contain %q, but it didn't. Output:\n%s", expectedErr, actual)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput:\n%s", err, actual)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestFunnelLabelsE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name        string
+		args        []string
+		expectError bool
+		expected    []string
+	}{
+		{
+			name: "compare labels",
+			args: []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml",
+				"--label", "device=pixel7:sample/logs/logcat.txt", "--label", "device=s23:sample/logs/simple.txt"},
+			expected: []string{
+				" Comparison by Label",
+				"Step       device=pixel7  device=s23",
+				"1. Login   1 (100.0%)     0 (0.0%)",
+				"Completed  Yes            No",
+				"Label: device=pixel7",
+				"Label: device=s23",
+			},
+		},
+		{
+			name: "compare labels as JSON",
+			args: []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml",
+				"--label", "device=pixel7:sample/logs/logcat.txt", "-o", "json"},
+			expected: []string{
+				`"label": "device=pixel7"`,
+				`"comparisons": [`,
+			},
+		},
+		{
+			name: "labels and log together",
+			args: []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml",
+				"--label", "device=pixel7:sample/logs/logcat.txt", "-l", "sample/logs/logcat.txt"},
+			expectError: true,
+			expected:    []string{"if any flags in the group [log label] are set none of the others can be"},
+		},
+		{
+			name: "invalid label",
+			args: []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-f", "sample/funnels/basic.yaml",
+				"--label", "pixel7:sample/logs/logcat.txt"},
+			expectError: true,
+			expected:    []string{"invalid label 'pixel7:sample/logs/logcat.txt' (expected key=value:pattern)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.CombinedOutput()
+			if tt.expectError && err == nil {
+				t.Fatalf("Expected command to fail, output:\n%s", output)
+			}
+			if !tt.expectError && err != nil {
+				t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+			}
+
+			actual := string(output)
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParserPluginE2E(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" && runtime.GOOS != "freebsd" {
+		t.Skipf("Go plugins are not supported on %s", runtime.GOOS)
+	}
+	cgo, err := exec.Command("go", "env", "CGO_ENABLED").Output()
+	if err != nil {
+		t.Fatalf("Failed to read CGO_ENABLED: %v", err)
+	}
+	if strings.TrimSpace(string(cgo)) != "1" {
+		t.Skip("Building parser plugins needs cgo")
+	}
+
+	// The plugin and the binary loading it must be built by the same toolchain
+	pluginCmd := exec.Command("go", "build", "-buildmode=plugin", "-o", "pipe_test.so", "./sample/plugins/pipe")
+	if output, err := pluginCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build plugin: %v\n%s", err, output)
+	}
+	buildCmd := exec.Command("go", "build", "-o", "loglion_plugin_test", "../main.go")
+	if output, err := buildCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build binary: %v\n%s", err, output)
+	}
+	staticCmd := exec.Command("go", "build", "-o", "loglion_static_test", "../main.go")
+	staticCmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+	if output, err := staticCmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build static binary: %v\n%s", err, output)
+	}
+	defer func() {
+		exec.Command("rm", "-f", "pipe_test.so", "loglion_plugin_test", "loglion_static_test").Run()
+	}()
+
+	args := []string{"count", "--parser-plugin", "./pipe_test.so", "-p", "sample/parsers/pipe.yaml", "-l", "sample/logs/pipe.txt", "login", "purchase"}
+
+	t.Run("cgo binary loads the plugin", func(t *testing.T) {
+		output, err := exec.Command("./loglion_plugin_test", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("Expected command to succeed, got %v. Output:\n%s", err, output)
+		}
+		for _, expected := range []string{"Total Events Analyzed: 4", "1. login: 2 matches (50.0%)", "2. purchase: 1 matches (25.0%)"} {
+			if !strings.Contains(string(output), expected) {
+				t.Errorf("Expected output to contain %q. Output:\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("static binary explains plugins need cgo", func(t *testing.T) {
+		output, err := exec.Command("./loglion_static_test", args...).CombinedOutput()
+		if err == nil {
+			t.Fatalf("Expected command to fail. Output:\n%s", output)
+		}
+		for _, expected := range []string{"parser plugins need a loglion binary built with cgo", "loglion.RegisterFormat"} {
+			if !strings.Contains(string(output), expected) {
+				t.Errorf("Expected output to contain %q. Output:\n%s", expected, output)
+			}
+		}
+	})
+}
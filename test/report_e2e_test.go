@@ -0,0 +1,116 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTMLReportE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name: "funnel HTML report",
+			args: []string{"funnel", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-f", "sample/funnels/basic.yaml", "-o", "html"},
+			expected: []string{
+				"<h1>Funnel Report: Basic User Flow</h1>",
+				`<dd class="status completed">Completed</dd>`,
+				"<td>1. Login</td>",
+				"<h3>Time to Convert (from first step)</h3>",
+			},
+		},
+		{
+			name: "count HTML report",
+			args: []string{"count", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-o", "html", "login", "logout"},
+			expected: []string{
+				"<h1>Event Count Report</h1>",
+				`<td>1. <code>login</code></td><td class="num">2</td>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(t.TempDir(), "report.html")
+			cmd := exec.Command("./loglion_test", append(tt.args, "--output-file", outputFile)...)
+			cmd.Dir = "."
+
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+			}
+			if strings.Contains(string(output), "<html") {
+				t.Errorf("Expected the report to be written to the output file only, got stdout:\n%s", output)
+			}
+
+			data, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("Failed to read report: %v", err)
+			}
+			report := string(data)
+			for _, expected := range tt.expected {
+				if !strings.Contains(report, expected) {
+					t.Errorf("Expected report to contain %q, but it didn't. Report:\n%s", expected, report)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiFormatOutputE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "result.json")
+	htmlFile := filepath.Join(dir, "report.html")
+
+	cmd := exec.Command("./loglion_test", "funnel", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt",
+		"-f", "sample/funnels/basic.yaml", "-o", "json="+jsonFile+",html="+htmlFile+",text=-")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(string(output), "Funnel Analysis Complete") {
+		t.Errorf("Expected text results on stdout, got:\n%s", output)
+	}
+
+	for path, expected := range map[string]string{
+		jsonFile: `"funnel_name": "Basic User Flow"`,
+		htmlFile: "<h1>Funnel Report: Basic User Flow</h1>",
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", filepath.Base(path), expected, data)
+		}
+	}
+}
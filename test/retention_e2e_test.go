@@ -0,0 +1,99 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRetentionCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name           string
+		args           []string
+		expected       []string
+		shouldFail     bool
+		expectedErrMsg []string
+	}{
+		{
+			name: "day-N retention",
+			args: []string{"retention", "-p", "sample/parsers/jsonl.yaml", "-l", "sample/logs/retention.jsonl", "--periods", "2", "signup", "app_open"},
+			expected: []string{
+				"Retention Analysis Complete",
+				"Users: 3",
+				"Day 0: 1/3 users (33.3%)",
+				"Day 1: 2/2 users (100.0%)",
+				"Day 2: 1/2 users (50.0%)",
+				"2025-01-15 00:00:00.000  2      50.0%  100.0%  50.0%",
+				"2025-01-16 00:00:00.000  1      0.0%   -       -",
+			},
+		},
+		{
+			name: "hour-N retention as JSON",
+			args: []string{"retention", "-p", "sample/parsers/jsonl.yaml", "-l", "sample/logs/retention.jsonl", "--period", "hour", "--periods", "1", "-o", "json", "signup", "app_open"},
+			expected: []string{
+				`"period_seconds": 3600`,
+				`"users": 3`,
+				`"retained_users": 1`,
+			},
+		},
+		{
+			name:       "retention with an invalid period",
+			args:       []string{"retention", "-p", "sample/parsers/jsonl.yaml", "-l", "sample/logs/retention.jsonl", "--period", "week", "signup", "app_open"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error: invalid period 'week' (valid: day, hour or a duration such as 30m)",
+			},
+		},
+		{
+			name:       "retention without a return pattern",
+			args:       []string{"retention", "-p", "sample/parsers/jsonl.yaml", "-l", "sample/logs/retention.jsonl", "signup"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error: accepts 2 arg(s), received 1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.CombinedOutput()
+			actual := string(output)
+
+			if tt.shouldFail {
+				if err == nil {
+					t.Fatalf("Expected command to fail, but it succeeded. Output:\n%s", actual)
+				}
+				for _, expectedErr := range tt.expectedErrMsg {
+					if !strings.Contains(actual, expectedErr) {
+						t.Errorf("Expected error output to contain %q, but it didn't. Output:\n%s", expectedErr, actual)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Command failed: %v\nOutput:\n%s", err, actual)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -32,8 +32,24 @@ func TestRootCommandE2E(t *testing.T) {
 				"Usage:",
 				"loglion [command]",
 				"Available Commands:",
+				"anomalies",
+				"audit",
+				"conformance",
+				"cooccur",
 				"count",
+				"diff",
+				"discover",
+				"extract",
 				"funnel",
+				"gaps",
+				"init",
+				"retention",
+				"sessions",
+				"stats",
+				"suggest",
+				"timeline",
+				"top",
+				"tui",
 				"validate",
 				"version",
 			},
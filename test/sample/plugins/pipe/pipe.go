@@ -0,0 +1,68 @@
+// Package main is a parser plugin for the e2e tests, built with
+// go build -buildmode=plugin. It parses lines like "event|key=value|...".
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/parfenovvs/loglion/pkg/loglion"
+)
+
+var Format = "pipe"
+
+func NewParser(options loglion.FormatOptions) loglion.Parser {
+	return &pipeParser{}
+}
+
+type pipeParser struct {
+	stats loglion.ParseStats
+}
+
+func (p *pipeParser) Parse(line string) (*loglion.LogEntry, error) {
+	fields := strings.Split(line, "|")
+	entry := &loglion.LogEntry{Message: fields[0], EventData: map[string]interface{}{"event": fields[0]}}
+	for _, field := range fields[1:] {
+		if key, value, ok := strings.Cut(field, "="); ok {
+			entry.EventData[key] = value
+		}
+	}
+	return entry, nil
+}
+
+func (p *pipeParser) ParseFile(path string) ([]*loglion.LogEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return p.ParseReader(file)
+}
+
+func (p *pipeParser) ParseReader(r io.Reader) ([]*loglion.LogEntry, error) {
+	var entries []*loglion.LogEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.stats.TotalLines++
+		if scanner.Text() == "" {
+			p.stats.EmptyLines++
+			continue
+		}
+		entry, err := p.Parse(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		p.stats.ParsedEntries++
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func (p *pipeParser) Stats() loglion.ParseStats {
+	return p.stats
+}
+
+// main is never called; it lets go build ./... build the package
+func main() {}
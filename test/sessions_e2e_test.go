@@ -0,0 +1,75 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSessionsCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name: "sessions by user",
+			args: []string{"sessions", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--session-key", "user_id"},
+			expected: []string{
+				"Session Analysis Complete",
+				"Session Key: user_id",
+				"Sessions: 3",
+				"Entries Without user_id: 1",
+				"Events: n=3 min=1 avg=2 p95=3 max=3",
+			},
+		},
+		{
+			name: "sessions split by idle timeout",
+			args: []string{"sessions", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--idle-timeout", "1s"},
+			expected: []string{
+				"Session Key: none (whole log)",
+				"Idle Timeout: 1s",
+				"Session Duration (seconds):",
+			},
+		},
+		{
+			name: "sessions with JSON output",
+			args: []string{"sessions", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "--session-key", "user_id", "-o", "json"},
+			expected: []string{
+				`"session_count": 3`,
+				`"unkeyed_entries": 1`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Command failed: %v", err)
+			}
+
+			actual := string(output)
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestStatsCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name: "log overview",
+			args: []string{"stats", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-n", "2"},
+			expected: []string{
+				"Log Overview",
+				"Total Lines: 8",
+				"Parsed Entries: 8",
+				"Skipped Lines: 0",
+				"Distinct Events: 7",
+				"First Timestamp: 01-15 10:30:15.100",
+				"Last Timestamp: 01-15 10:30:22.800",
+				"Time Span: 7.7s",
+				"Entries per Minute: 62.34",
+				"Tags (3 distinct):",
+				"... and 1 more",
+			},
+		},
+		{
+			name: "log overview with JSON output",
+			args: []string{"stats", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-o", "json"},
+			expected: []string{
+				`"total_lines": 8`,
+				`"span_seconds": 7.7`,
+				`"value": "Analytics"`,
+				`"distinct_tags": 3`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Command failed: %v", err)
+			}
+
+			actual := string(output)
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
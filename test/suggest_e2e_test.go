@@ -0,0 +1,76 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSuggestCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name        string
+		args        []string
+		expectError bool
+		expected    []string
+	}{
+		{
+			name: "suggest logcat config",
+			args: []string{"suggest", "-l", "sample/logs/logcat.txt", "-e", "login"},
+			expected: []string{
+				"# 2 lines contain 'login', suggestions are based on 2:",
+				"# Suggestion 1: Android logcat (threadtime)",
+				"timestamp_format: 01-02 15:04:05.000",
+				"# Suggestion 2: Whole line",
+			},
+		},
+		{
+			name: "suggest JSON lines config",
+			args: []string{"suggest", "-l", "sample/logs/users.txt", "-e", "add_cart", "--samples", "1"},
+			expected: []string{
+				"# 2 lines contain 'add_cart', suggestions are based on 1:",
+				`# Matches the event with event_pattern: "^add_cart$"`,
+				"json_extraction: true",
+			},
+		},
+		{
+			name:        "event not in log",
+			args:        []string{"suggest", "-l", "sample/logs/logcat.txt", "-e", "purchase_completed"},
+			expectError: true,
+			expected:    []string{"no log lines contain 'purchase_completed'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.CombinedOutput()
+			if tt.expectError && err == nil {
+				t.Fatalf("Expected command to fail, output:\n%s", output)
+			}
+			if !tt.expectError && err != nil {
+				t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+			}
+
+			actual := string(output)
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestTimelineCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name: "timeline of matching events",
+			args: []string{"timeline", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--interval", "2s", "login", "logout"},
+			expected: []string{
+				"Event Timeline",
+				"Patterns: login, logout",
+				"Matching Events: 3",
+				"Interval: 2s",
+				"  10:30:14 |" + strings.Repeat("#", 40) + " 1",
+				"  10:30:16 | 0",
+				"  10:30:20 |" + strings.Repeat("#", 40) + " 1",
+			},
+		},
+		{
+			name: "timeline with JSON output",
+			args: []string{"timeline", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "--interval", "5s", "-o", "json"},
+			expected: []string{
+				`"interval_seconds": 5`,
+				`"matching_events": 8`,
+				`"start": "0000-01-15T10:30:15Z"`,
+			},
+		},
+		{
+			name: "timeline of log without timestamps",
+			args: []string{"timeline", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt"},
+			expected: []string{
+				"Matching Events: 7",
+				"Without Timestamp: 7",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Command failed: %v", err)
+			}
+
+			actual := string(output)
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
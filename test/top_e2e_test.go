@@ -0,0 +1,74 @@
+package test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestTopCommandE2E(t *testing.T) {
+	// Build the binary first
+	buildCmd := exec.Command("go", "build", "-o", "loglion_test", "../main.go")
+	buildCmd.Dir = "."
+	if err := buildCmd.Run(); err != nil {
+		t.Fatalf("Failed to build binary: %v", err)
+	}
+
+	// Clean up binary after test
+	defer func() {
+		exec.Command("rm", "-f", "loglion_test").Run()
+	}()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name: "top events",
+			args: []string{"top", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "-n", "2"},
+			expected: []string{
+				"Top Events",
+				"Total Events Analyzed: 7",
+				"Distinct Events: 3",
+				"1. view_product: 3 (42.9%)",
+				"2. add_cart: 2 (28.6%)",
+				"... and 1 more",
+			},
+		},
+		{
+			name: "top messages of unstructured log",
+			args: []string{"top", "-p", "sample/parsers/logcat.yaml", "-l", "sample/logs/logcat.txt", "-n", "1"},
+			expected: []string{
+				"1. login: 2 (25.0%)",
+			},
+		},
+		{
+			name: "top events with JSON output",
+			args: []string{"top", "-p", "sample/parsers/json.yaml", "-l", "sample/logs/users.txt", "-o", "json"},
+			expected: []string{
+				`"distinct_events": 3`,
+				`"event": "view_product"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("./loglion_test", tt.args...)
+			cmd.Dir = "."
+
+			output, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("Command failed: %v", err)
+			}
+
+			actual := string(output)
+			for _, expected := range tt.expected {
+				if !strings.Contains(actual, expected) {
+					t.Errorf("Expected output to contain %q, but it didn't. Output:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
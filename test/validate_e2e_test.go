@@ -32,7 +32,7 @@ func TestValidateCommandE2E(t *testing.T) {
 			args: []string{"validate", "--parser-config", "../examples/simple/simple-parser.yaml"},
 			expected: []string{
 				"Validating parser config file: ../examples/simple/simple-parser.yaml",
-				"✅ Parser configuration is valid!",
+				"PASS Parser configuration is valid!",
 				"Event Regex:",
 				"JSON Extraction:",
 			},
@@ -42,7 +42,7 @@ func TestValidateCommandE2E(t *testing.T) {
 			args: []string{"validate", "--funnel-config", "../examples/simple/simple-funnel.yaml"},
 			expected: []string{
 				"Validating funnel config file: ../examples/simple/simple-funnel.yaml",
-				"✅ Funnel configuration is valid!",
+				"PASS Funnel configuration is valid!",
 				"Funnel:",
 				"Steps:",
 			},
@@ -52,21 +52,85 @@ func TestValidateCommandE2E(t *testing.T) {
 			args: []string{"validate", "--parser-config", "../examples/android/logcat-parser.yaml", "--funnel-config", "../examples/android/purchase-funnel.yaml"},
 			expected: []string{
 				"Validating parser config file: ../examples/android/logcat-parser.yaml",
-				"✅ Parser configuration is valid!",
+				"PASS Parser configuration is valid!",
 				"Validating funnel config file: ../examples/android/purchase-funnel.yaml",
-				"✅ Funnel configuration is valid!",
+				"PASS Funnel configuration is valid!",
 				"Event Regex:",
 				"JSON Extraction:",
 				"Funnel:",
 				"Steps:",
 			},
 		},
+		{
+			name: "validate warns about group_by without event data",
+			args: []string{"validate", "-p", "sample/parsers/simple.yaml", "-f", "sample/funnels/per_user.yaml"},
+			expected: []string{
+				"PASS Funnel configuration is valid!",
+				"WARN  funnel 'Per-User Purchase Flow': group_by 'user_id' never matches",
+				"1 warning(s)",
+			},
+		},
+		{
+			name: "validate combined config file",
+			args: []string{"validate", "--config", "sample/configs/basic.yaml"},
+			expected: []string{
+				"Validating parser config file: sample/configs/basic.yaml",
+				"PASS Parser configuration is valid!",
+				"Validating funnel config file: sample/configs/basic.yaml",
+				"PASS Funnel configuration is valid!",
+				"Funnel: Basic User Flow",
+			},
+		},
+		{
+			name:       "validate config file without sections",
+			args:       []string{"validate", "--config", "../examples/simple/simple-parser.yaml"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error loading config: config file '../examples/simple/simple-parser.yaml' has no parser or funnel section",
+			},
+		},
+		{
+			name: "validate funnel config step graph as dot",
+			args: []string{"validate", "--funnel-config", "../examples/simple/simple-funnel.yaml", "--graph", "dot"},
+			expected: []string{
+				`digraph "Simple Event Flow" {`,
+				`s0 [label="1. Event 1"];`,
+				"s0 -> s1;",
+				"s1 -> s2;",
+			},
+		},
+		{
+			name:       "validate with unsupported graph format",
+			args:       []string{"validate", "--funnel-config", "../examples/simple/simple-funnel.yaml", "--graph", "svg"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error: unsupported graph format 'svg' (supported: dot)",
+			},
+		},
+		{
+			name: "validate dry run against a log sample",
+			args: []string{"validate", "-p", "../examples/simple/simple-parser.yaml", "-f", "../examples/simple/simple-funnel.yaml", "--log", "../examples/simple/sample_simple.txt", "--lines", "3"},
+			expected: []string{
+				"Dry run against the first 3 lines of ../examples/simple/sample_simple.txt (3 entries):",
+				"1. Event 1: 1 hits",
+				"3. Event 3: 0 hits  WARN  never matches",
+				"WARN  1 step(s) never match the log sample",
+			},
+		},
+		{
+			name:       "validate dry run without parser config",
+			args:       []string{"validate", "-f", "../examples/simple/simple-funnel.yaml", "--log", "../examples/simple/sample_simple.txt"},
+			shouldFail: true,
+			expectedErrMsg: []string{
+				"Error: --log requires both a parser and a funnel config",
+			},
+		},
 		{
 			name:       "validate with no config files specified",
 			args:       []string{"validate"},
 			shouldFail: true,
 			expectedErrMsg: []string{
-				"Error: At least one of --parser-config or --funnel-config must be specified.",
+				"Error: At least one of --config, --parser-config or --funnel-config must be specified.",
 			},
 		},
 		{
@@ -74,7 +138,7 @@ func TestValidateCommandE2E(t *testing.T) {
 			args:       []string{"validate", "--parser-config", "non-existent.yaml"},
 			shouldFail: true,
 			expectedErrMsg: []string{
-				"❌ Parser configuration validation failed:",
+				"FAIL Parser configuration validation failed:",
 				"non-existent.yaml",
 			},
 		},
@@ -83,7 +147,7 @@ func TestValidateCommandE2E(t *testing.T) {
 			args:       []string{"validate", "--funnel-config", "non-existent.yaml"},
 			shouldFail: true,
 			expectedErrMsg: []string{
-				"❌ Funnel configuration validation failed:",
+				"FAIL Funnel configuration validation failed:",
 				"non-existent.yaml",
 			},
 		},
@@ -240,7 +304,7 @@ steps:
 			args:       []string{"validate", "--parser-config", tmpParserFile},
 			shouldFail: true,
 			expectedErrMsg: []string{
-				"❌ Parser configuration validation failed:",
+				"FAIL Parser configuration validation failed:",
 			},
 		},
 		{
@@ -248,7 +312,7 @@ steps:
 			args:       []string{"validate", "--funnel-config", tmpFunnelFile},
 			shouldFail: true,
 			expectedErrMsg: []string{
-				"❌ Funnel configuration validation failed:",
+				"FAIL Funnel configuration validation failed:",
 			},
 		},
 	}
@@ -298,7 +362,7 @@ func TestValidateCommandVerboseFlag(t *testing.T) {
 			args: []string{"--verbose", "validate", "--parser-config", "../examples/simple/simple-parser.yaml"},
 			expected: []string{
 				"Validating parser config file: ../examples/simple/simple-parser.yaml",
-				"✅ Parser configuration is valid!",
+				"PASS Parser configuration is valid!",
 			},
 		},
 		{
@@ -306,7 +370,7 @@ func TestValidateCommandVerboseFlag(t *testing.T) {
 			args: []string{"-v", "validate", "--funnel-config", "../examples/simple/simple-funnel.yaml"},
 			expected: []string{
 				"Validating funnel config file: ../examples/simple/simple-funnel.yaml",
-				"✅ Funnel configuration is valid!",
+				"PASS Funnel configuration is valid!",
 			},
 		},
 	}